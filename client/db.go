@@ -323,6 +323,21 @@ func (db *DB) Scan(begin, end interface{}, maxRows int64) ([]KeyValue, error) {
 	return r.Rows, err
 }
 
+// ReverseScan retrieves the rows between begin (inclusive) and end (exclusive)
+// in descending key order.
+//
+// The returned []KeyValue will contain up to maxRows elements, starting with
+// the last row before end.
+//
+// key can be either a byte slice, a string, a fmt.Stringer or an
+// encoding.BinaryMarshaler.
+func (db *DB) ReverseScan(begin, end interface{}, maxRows int64) ([]KeyValue, error) {
+	b := db.NewBatch()
+	b.ReverseScan(begin, end, maxRows)
+	r, err := runOneResult(db, b)
+	return r.Rows, err
+}
+
 // Del deletes one or more keys.
 //
 // key can be either a byte slice, a string, a fmt.Stringer or an
@@ -404,6 +419,20 @@ func (db *DB) Txn(retryable func(txn *Txn) error) error {
 	return newTxn(*db, 1 /* depth */).exec(retryable)
 }
 
+// NewTxn returns a transaction which is neither retried nor committed
+// automatically. The caller is responsible for ending the transaction using
+// Txn.Commit or Txn.Rollback. If txn is non-nil the returned transaction
+// resumes txn, which must have been retrieved using Txn.Proto from a
+// transaction started using the same cluster. This allows a transaction to
+// span several requests to a server.
+func (db *DB) NewTxn(txn *proto.Transaction) *Txn {
+	t := newTxn(*db, 1 /* depth */)
+	if txn != nil {
+		t.txn = *txn
+	}
+	return t
+}
+
 // send runs the specified calls synchronously in a single batch and
 // returns any errors.
 func (db *DB) send(calls ...proto.Call) (err error) {
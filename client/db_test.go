@@ -182,6 +182,30 @@ func ExampleDB_Scan() {
 	// 1: ab=2
 }
 
+func ExampleDB_ReverseScan() {
+	s, db := setup()
+	defer s.Stop()
+
+	b := &client.Batch{}
+	b.Put("aa", "1")
+	b.Put("ab", "2")
+	b.Put("bb", "3")
+	if err := db.Run(b); err != nil {
+		panic(err)
+	}
+	rows, err := db.ReverseScan("ab", "c", 100)
+	if err != nil {
+		panic(err)
+	}
+	for i, row := range rows {
+		fmt.Printf("%d: %s=%s\n", i, row.Key, row.ValueBytes())
+	}
+
+	// Output:
+	// 0: bb=3
+	// 1: ab=2
+}
+
 func ExampleDB_Del() {
 	s, db := setup()
 	defer s.Stop()
@@ -331,6 +355,10 @@ func TestCommonMethods(t *testing.T) {
 		key{dbType, "ListNamespaces"}:        {},
 		key{dbType, "ListTables"}:            {},
 		key{dbType, "NewBatch"}:              {},
+		key{dbType, "NewIterator"}:           {},
+		key{dbType, "NewStructIterator"}:     {},
+		key{dbType, "NewTxn"}:                {},
+		key{dbType, "ReadAt"}:                {},
 		key{dbType, "RenameTable"}:           {},
 		key{dbType, "Run"}:                   {},
 		key{dbType, "Txn"}:                   {},
@@ -338,6 +366,10 @@ func TestCommonMethods(t *testing.T) {
 		key{txnType, "DebugName"}:            {},
 		key{txnType, "InternalSetPriority"}:  {},
 		key{txnType, "NewBatch"}:             {},
+		key{txnType, "NewIterator"}:          {},
+		key{txnType, "NewStructIterator"}:    {},
+		key{txnType, "Proto"}:                {},
+		key{txnType, "Rollback"}:             {},
 		key{txnType, "Run"}:                  {},
 		key{txnType, "SetDebugName"}:         {},
 		key{txnType, "SetSnapshotIsolation"}: {},
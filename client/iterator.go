@@ -0,0 +1,301 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package client
+
+import (
+	"bytes"
+	"reflect"
+
+	"github.com/cockroachdb/cockroach/proto"
+)
+
+// DefaultIteratorChunkSize is the number of rows an iterator fetches at a
+// time if it is created with a chunk size of zero.
+const DefaultIteratorChunkSize = 1000
+
+// Iterator iterates over the rows between a begin (inclusive) and an end
+// (exclusive) key, fetching them from the cluster in chunks of a fixed number
+// of rows. All of the chunks are read at the same timestamp: an iterator
+// created by a Txn reads at the timestamp of the transaction and an iterator
+// created by a DB reads at the timestamp at which its first chunk was read.
+// An Iterator is not safe for concurrent use by multiple goroutines.
+//
+//   it := db.NewIterator("a", "b", 100)
+//   defer it.Close()
+//   for it.Next() {
+//     kv := it.KeyValue()
+//     ...
+//   }
+//   if err := it.Err(); err != nil {
+//     ...
+//   }
+type Iterator struct {
+	send       func(calls ...proto.Call) error
+	pin        bool // True if the iterator pins the timestamp of its reads
+	timestamp  proto.Timestamp
+	begin, end proto.Key
+	chunkSize  int64
+	rows       []proto.KeyValue
+	pos        int
+	done       bool
+	err        error
+}
+
+func newIterator(send func(calls ...proto.Call) error, pin bool,
+	begin, end interface{}, chunkSize int64) *Iterator {
+	it := &Iterator{send: send, pin: pin, chunkSize: chunkSize}
+	if it.chunkSize <= 0 {
+		it.chunkSize = DefaultIteratorChunkSize
+	}
+	b, err := marshalKey(begin)
+	if err != nil {
+		it.err = err
+		return it
+	}
+	e, err := marshalKey(end)
+	if err != nil {
+		it.err = err
+		return it
+	}
+	it.begin, it.end = proto.Key(b), proto.Key(e)
+	return it
+}
+
+// NewIterator returns an iterator over the rows between begin (inclusive) and
+// end (exclusive) which fetches chunkSize rows at a time. The timestamp at
+// which the first chunk is read is used to read all of the subsequent
+// chunks, so the iterator returns a consistent snapshot of the rows even
+// though it is not transactional.
+//
+// key can be either a byte slice, a string, a fmt.Stringer or an
+// encoding.BinaryMarshaler.
+func (db *DB) NewIterator(begin, end interface{}, chunkSize int64) *Iterator {
+	return newIterator(db.send, true, begin, end, chunkSize)
+}
+
+// NewIterator returns an iterator over the rows between begin (inclusive) and
+// end (exclusive) which fetches chunkSize rows at a time within the
+// transaction.
+//
+// key can be either a byte slice, a string, a fmt.Stringer or an
+// encoding.BinaryMarshaler.
+func (txn *Txn) NewIterator(begin, end interface{}, chunkSize int64) *Iterator {
+	return newIterator(txn.send, false, begin, end, chunkSize)
+}
+
+// Next advances the iterator to the next row, fetching the next chunk of rows
+// if necessary. It returns false when there are no more rows or an error
+// occurred, which is returned by Err.
+func (it *Iterator) Next() bool {
+	it.pos++
+	if it.pos < len(it.rows) {
+		return true
+	}
+	it.rows = nil
+	if it.err != nil || it.done {
+		return false
+	}
+	if err := it.fetch(); err != nil {
+		it.err = err
+		return false
+	}
+	it.pos = 0
+	return len(it.rows) > 0
+}
+
+// fetch reads the next chunk of rows.
+func (it *Iterator) fetch() error {
+	if !it.begin.Less(it.end) {
+		it.done = true
+		return nil
+	}
+	c := proto.ScanCall(it.begin, it.end, it.chunkSize)
+	if it.pin {
+		// The timestamp is zero for the first chunk, so that the range
+		// reading it chooses the timestamp from the cluster's clock instead
+		// of the client's, which may be behind or too far ahead of it. The
+		// remaining chunks are read at the timestamp of its reply.
+		c.Args.Header().Timestamp = it.timestamp
+	}
+	if err := it.send(c); err != nil {
+		return err
+	}
+	reply := c.Reply.(*proto.ScanResponse)
+	if it.pin && it.timestamp.Equal(proto.ZeroTimestamp) {
+		it.timestamp = reply.Header().Timestamp
+	}
+	it.rows = reply.Rows
+	if int64(len(it.rows)) < it.chunkSize {
+		it.done = true
+	} else {
+		it.begin = it.rows[len(it.rows)-1].Key.Next()
+	}
+	return nil
+}
+
+// KeyValue returns the current row. It must only be called after a call to
+// Next returned true.
+func (it *Iterator) KeyValue() KeyValue {
+	row := &it.rows[it.pos]
+	kv := KeyValue{Key: row.Key}
+	kv.setValue(&row.Value)
+	return kv
+}
+
+// Err returns the error, if any, encountered during iteration.
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// Close stops the iteration. No further chunks are fetched and Next returns
+// false. Closing an iterator before reaching its end is the way to stop a
+// scan early.
+func (it *Iterator) Close() {
+	it.rows = nil
+	it.done = true
+}
+
+// StructIterator iterates over the rows of the structured table bound to a
+// model type, fetching them from the cluster in chunks. A row which spans
+// several chunks is reassembled before it is returned by the iterator. A
+// StructIterator is not safe for concurrent use by multiple goroutines.
+//
+//   it := db.NewStructIterator(User{ID: 0}, User{ID: 1000}, 100)
+//   defer it.Close()
+//   for it.Next() {
+//     u := it.Struct().(*User)
+//     ...
+//   }
+//   if err := it.Err(); err != nil {
+//     ...
+//   }
+type StructIterator struct {
+	it         *Iterator
+	m          *model
+	modelT     reflect.Type
+	scanColIDs map[uint32]bool
+	result     reflect.Value
+	// True if it is positioned at the first column of the next row.
+	pending bool
+	err     error
+}
+
+func newStructIterator(db *DB, newIter func(begin, end interface{}) *Iterator,
+	start, end interface{}, columns []string) *StructIterator {
+	si := &StructIterator{}
+	si.modelT = reflect.Indirect(reflect.ValueOf(start)).Type()
+	m, err := db.getModel(si.modelT, false)
+	if err != nil {
+		si.err = err
+		return si
+	}
+	si.m = m
+	if si.scanColIDs, err = m.scanColumnIDs(columns); err != nil {
+		si.err = err
+		return si
+	}
+	startKey, endKey, err := m.encodeScanKeys(si.modelT, start, end)
+	if err != nil {
+		si.err = err
+		return si
+	}
+	si.it = newIter(startKey, endKey)
+	return si
+}
+
+// NewStructIterator returns an iterator over the rows of the structured table
+// identified by the type of start, fetching chunkSize columns at a time. The
+// start and end key types must be identical. The primary key columns within
+// start and end are used to identify which rows to scan. The type must have
+// previously been bound to a table using BindModel. If columns is empty all
+// of the columns in the table are scanned. As with DB.NewIterator, all of the
+// chunks are read at the same timestamp.
+func (db *DB) NewStructIterator(start, end interface{}, chunkSize int64,
+	columns ...string) *StructIterator {
+	return newStructIterator(db, func(begin, end interface{}) *Iterator {
+		return db.NewIterator(begin, end, chunkSize)
+	}, start, end, columns)
+}
+
+// NewStructIterator returns an iterator over the rows of the structured table
+// identified by the type of start within the transaction. See
+// DB.NewStructIterator.
+func (txn *Txn) NewStructIterator(start, end interface{}, chunkSize int64,
+	columns ...string) *StructIterator {
+	return newStructIterator(&txn.db, func(begin, end interface{}) *Iterator {
+		return txn.NewIterator(begin, end, chunkSize)
+	}, start, end, columns)
+}
+
+// Next advances the iterator to the next row, fetching further chunks of
+// columns as necessary. It returns false when there are no more rows or an
+// error occurred, which is returned by Err.
+func (si *StructIterator) Next() bool {
+	si.result = reflect.Value{}
+	if si.err != nil {
+		return false
+	}
+	if !si.pending && !si.it.Next() {
+		si.err = si.it.Err()
+		return false
+	}
+	si.pending = false
+
+	resultPtr := reflect.New(si.modelT)
+	result := resultPtr.Elem()
+	primaryKey, err := si.m.decodeColumn(&si.it.rows[si.it.pos], result, si.scanColIDs)
+	if err != nil {
+		si.err = err
+		return false
+	}
+	for si.it.Next() {
+		row := &si.it.rows[si.it.pos]
+		if !bytes.HasPrefix(row.Key, primaryKey) {
+			si.pending = true
+			break
+		}
+		if _, err := si.m.decodeColumn(row, result, si.scanColIDs); err != nil {
+			si.err = err
+			return false
+		}
+	}
+	if err := si.it.Err(); err != nil {
+		si.err = err
+		return false
+	}
+	si.result = resultPtr
+	return true
+}
+
+// Struct returns a pointer to a new value of the model type holding the
+// current row. It must only be called after a call to Next returned true.
+func (si *StructIterator) Struct() interface{} {
+	return si.result.Interface()
+}
+
+// Err returns the error, if any, encountered during iteration.
+func (si *StructIterator) Err() error {
+	return si.err
+}
+
+// Close stops the iteration. See Iterator.Close.
+func (si *StructIterator) Close() {
+	si.result = reflect.Value{}
+	si.pending = false
+	if si.it != nil {
+		si.it.Close()
+	}
+}
@@ -0,0 +1,258 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package client_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/client"
+	"github.com/cockroachdb/cockroach/util/leaktest"
+)
+
+// iterate returns the rows of the iterator formatted as key=value.
+func iterate(it *client.Iterator) ([]string, error) {
+	var rows []string
+	for it.Next() {
+		kv := it.KeyValue()
+		rows = append(rows, fmt.Sprintf("%s=%s", kv.Key, kv.ValueBytes()))
+	}
+	return rows, it.Err()
+}
+
+func TestIterator(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, db := setup()
+	defer s.Stop()
+
+	b := &client.Batch{}
+	for i := 0; i < 10; i++ {
+		b.Put(fmt.Sprintf("a%d", i), fmt.Sprintf("%d", i))
+	}
+	b.Put("b", "x")
+	if err := db.Run(b); err != nil {
+		t.Fatal(err)
+	}
+
+	var expected []string
+	for i := 0; i < 10; i++ {
+		expected = append(expected, fmt.Sprintf("a%d=%d", i, i))
+	}
+
+	// The span is scanned completely regardless of the chunk size, including
+	// when the number of rows is a multiple of the chunk size.
+	for _, chunkSize := range []int64{0, 1, 3, 5, 10, 100} {
+		rows, err := iterate(db.NewIterator("a", "b", chunkSize))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(expected, rows) {
+			t.Errorf("%d: expected %v, but got %v", chunkSize, expected, rows)
+		}
+	}
+
+	// An empty span.
+	if rows, err := iterate(db.NewIterator("c", "d", 3)); err != nil {
+		t.Fatal(err)
+	} else if len(rows) != 0 {
+		t.Errorf("expected no rows, but got %v", rows)
+	}
+
+	// Closing the iterator terminates the iteration early.
+	it := db.NewIterator("a", "b", 3)
+	for i := 0; i < 4; i++ {
+		if !it.Next() {
+			t.Fatalf("%d: unexpected end of iteration: %v", i, it.Err())
+		}
+	}
+	it.Close()
+	if it.Next() {
+		t.Errorf("expected the iteration to end after Close, but got %s", it.KeyValue())
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	// An invalid key is reported by Err.
+	it = db.NewIterator(1, "b", 3)
+	if it.Next() {
+		t.Fatal("expected the iteration to fail")
+	}
+	if err := it.Err(); !isError(err, "unable to marshal key") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestIteratorConsistentTimestamp(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, db := setup()
+	defer s.Stop()
+
+	b := &client.Batch{}
+	for i := 0; i < 6; i++ {
+		b.Put(fmt.Sprintf("a%d", i), "old")
+	}
+	if err := db.Run(b); err != nil {
+		t.Fatal(err)
+	}
+
+	// Read the first chunk, then modify the rows which have not been read
+	// yet. The remaining chunks are read at the timestamp of the first chunk
+	// and do not see the modifications.
+	it := db.NewIterator("a", "b", 2)
+	defer it.Close()
+	if !it.Next() {
+		t.Fatal(it.Err())
+	}
+	b = &client.Batch{}
+	b.Put("a4", "new")
+	b.Put("a41", "new")
+	b.Del("a5")
+	if err := db.Run(b); err != nil {
+		t.Fatal(err)
+	}
+	var rows []string
+	for ok := true; ok; ok = it.Next() {
+		kv := it.KeyValue()
+		rows = append(rows, fmt.Sprintf("%s=%s", kv.Key, kv.ValueBytes()))
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"a0=old", "a1=old", "a2=old", "a3=old", "a4=old", "a5=old"}
+	if !reflect.DeepEqual(expected, rows) {
+		t.Errorf("expected %v, but got %v", expected, rows)
+	}
+
+	// A new iterator sees the modifications.
+	rows, err := iterate(db.NewIterator("a", "b", 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []string{"a0=old", "a1=old", "a2=old", "a3=old", "a4=new", "a41=new"}
+	if !reflect.DeepEqual(expected, rows) {
+		t.Errorf("expected %v, but got %v", expected, rows)
+	}
+}
+
+func TestTxnIterator(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, db := setup()
+	defer s.Stop()
+
+	if err := db.Put("a0", "0"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The iterator sees the writes of its transaction.
+	err := db.Txn(func(txn *client.Txn) error {
+		b := &client.Batch{}
+		for i := 1; i < 5; i++ {
+			b.Put(fmt.Sprintf("a%d", i), fmt.Sprintf("%d", i))
+		}
+		if err := txn.Run(b); err != nil {
+			return err
+		}
+		rows, err := iterate(txn.NewIterator("a", "b", 2))
+		if err != nil {
+			return err
+		}
+		expected := []string{"a0=0", "a1=1", "a2=2", "a3=3", "a4=4"}
+		if !reflect.DeepEqual(expected, rows) {
+			t.Errorf("expected %v, but got %v", expected, rows)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStructIterator(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, db := setup()
+	defer s.Stop()
+
+	type User struct {
+		ID    int    `db:"id" roach:"primary key"`
+		Name  string `db:"name"`
+		Title string
+	}
+
+	if err := db.CreateNamespace("t"); err != nil {
+		t.Fatal(err)
+	}
+	schema, err := client.SchemaFromModel(User{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema.Name = "t.users"
+	if err := db.CreateTable(schema); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.BindModel("t.users", User{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var expected []*User
+	for i := 1; i <= 5; i++ {
+		u := &User{ID: i, Name: fmt.Sprintf("user%d", i), Title: fmt.Sprintf("title%d", i)}
+		if err := db.PutStruct(u); err != nil {
+			t.Fatal(err)
+		}
+		expected = append(expected, u)
+	}
+
+	// Each row is stored as several key/value pairs, so the chunks split
+	// rows. The rows are reassembled by the iterator.
+	for _, chunkSize := range []int64{1, 2, 3, 100} {
+		var result []*User
+		it := db.NewStructIterator(User{ID: 0}, User{ID: 1000}, chunkSize)
+		for it.Next() {
+			result = append(result, it.Struct().(*User))
+		}
+		if err := it.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(expected, result) {
+			t.Errorf("%d: expected %+v, but got %+v", chunkSize, expected, result)
+		}
+	}
+
+	// Scan the specified columns within a transaction and stop early.
+	if err := db.Txn(func(txn *client.Txn) error {
+		it := txn.NewStructIterator(&User{ID: 2}, &User{ID: 1000}, 1, "name")
+		defer it.Close()
+		if !it.Next() {
+			return it.Err()
+		}
+		if u, e := it.Struct().(*User), (&User{ID: 2, Name: "user2"}); !reflect.DeepEqual(e, u) {
+			t.Errorf("expected %+v, but got %+v", e, u)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	it := db.NewStructIterator(User{}, User{}, 1, "unknown")
+	if it.Next() {
+		t.Fatal("expected the iteration to fail")
+	}
+	if err := it.Err(); !isError(err, "unable to find column unknown") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package client
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/proto"
+	gogoproto "github.com/gogo/protobuf/proto"
+)
+
+// Snapshot is a read-only view of the database as of a fixed timestamp in
+// the past. All of the reads performed using a Snapshot observe the values
+// which were current at its timestamp, which makes the results of repeated
+// reads reproducible. A read fails if the timestamp is below the GC
+// threshold of a range it reads from, as the versions it would observe may
+// have been garbage collected. A Snapshot is safe for concurrent use by
+// multiple goroutines.
+//
+//   snap := db.ReadAt(ts)
+//   r, err := snap.Get("a")
+type Snapshot struct {
+	db        DB
+	timestamp proto.Timestamp
+}
+
+// ReadAt returns a read-only view of the database as of the specified
+// timestamp. A zero timestamp reads the current values.
+func (db *DB) ReadAt(timestamp proto.Timestamp) *Snapshot {
+	return &Snapshot{db: *db, timestamp: timestamp}
+}
+
+// Timestamp returns the timestamp at which the snapshot reads.
+func (s *Snapshot) Timestamp() proto.Timestamp {
+	return s.timestamp
+}
+
+// NewBatch creates and returns a new empty batch object for use with the
+// snapshot. Only read operations may be added to the batch.
+func (s *Snapshot) NewBatch() *Batch {
+	return &Batch{DB: &s.db}
+}
+
+// Get retrieves the value for a key as of the timestamp of the snapshot. See
+// DB.Get.
+//
+// key can be either a byte slice, a string, a fmt.Stringer or an
+// encoding.BinaryMarshaler.
+func (s *Snapshot) Get(key interface{}) (KeyValue, error) {
+	b := s.NewBatch()
+	b.Get(key)
+	return runOneRow(s, b)
+}
+
+// GetProto retrieves the value for a key as of the timestamp of the snapshot
+// and decodes the result as a proto message.
+//
+// key can be either a byte slice, a string, a fmt.Stringer or an
+// encoding.BinaryMarshaler.
+func (s *Snapshot) GetProto(key interface{}, msg gogoproto.Message) error {
+	r, err := s.Get(key)
+	if err != nil {
+		return err
+	}
+	return r.ValueProto(msg)
+}
+
+// Scan retrieves the rows between begin (inclusive) and end (exclusive) as of
+// the timestamp of the snapshot.
+//
+// The returned []KeyValue will contain up to maxRows elements.
+//
+// key can be either a byte slice, a string, a fmt.Stringer or an
+// encoding.BinaryMarshaler.
+func (s *Snapshot) Scan(begin, end interface{}, maxRows int64) ([]KeyValue, error) {
+	b := s.NewBatch()
+	b.Scan(begin, end, maxRows)
+	r, err := runOneResult(s, b)
+	return r.Rows, err
+}
+
+// ReverseScan retrieves the rows between begin (inclusive) and end (exclusive)
+// in descending key order as of the timestamp of the snapshot.
+//
+// The returned []KeyValue will contain up to maxRows elements, starting with
+// the last row before end.
+//
+// key can be either a byte slice, a string, a fmt.Stringer or an
+// encoding.BinaryMarshaler.
+func (s *Snapshot) ReverseScan(begin, end interface{}, maxRows int64) ([]KeyValue, error) {
+	b := s.NewBatch()
+	b.ReverseScan(begin, end, maxRows)
+	r, err := runOneResult(s, b)
+	return r.Rows, err
+}
+
+// GetStruct retrieves the specified columns of a row of a structured table as
+// of the timestamp of the snapshot. See Batch.GetStruct.
+func (s *Snapshot) GetStruct(obj interface{}, columns ...string) error {
+	b := s.NewBatch()
+	b.GetStruct(obj, columns...)
+	_, err := runOneResult(s, b)
+	return err
+}
+
+// ScanStruct scans the specified columns of the rows of a structured table as
+// of the timestamp of the snapshot. See Batch.ScanStruct.
+func (s *Snapshot) ScanStruct(dest, start, end interface{}, maxRows int64, columns ...string) error {
+	b := s.NewBatch()
+	b.ScanStruct(dest, start, end, maxRows, columns...)
+	_, err := runOneResult(s, b)
+	return err
+}
+
+// NewIterator returns an iterator over the rows between begin (inclusive) and
+// end (exclusive) as of the timestamp of the snapshot, which fetches
+// chunkSize rows at a time.
+//
+// key can be either a byte slice, a string, a fmt.Stringer or an
+// encoding.BinaryMarshaler.
+func (s *Snapshot) NewIterator(begin, end interface{}, chunkSize int64) *Iterator {
+	return newIterator(s.send, false, begin, end, chunkSize)
+}
+
+// Run executes the operations queued up within a batch as of the timestamp of
+// the snapshot. An error is returned without executing any of the operations
+// if the batch contains an operation which is not a read. See DB.Run.
+func (s *Snapshot) Run(b *Batch) error {
+	if err := b.prepare(); err != nil {
+		return err
+	}
+	if err := s.send(b.calls...); err != nil {
+		return err
+	}
+	return b.fillResults()
+}
+
+// send runs the specified calls at the timestamp of the snapshot.
+func (s *Snapshot) send(calls ...proto.Call) error {
+	for _, c := range calls {
+		if c.Err != nil {
+			continue
+		}
+		if !proto.IsReadOnly(c.Args) {
+			return fmt.Errorf("%s cannot be run on a read-only snapshot", c.Method())
+		}
+		c.Args.Header().Timestamp = s.timestamp
+	}
+	return s.db.send(calls...)
+}
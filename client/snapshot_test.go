@@ -0,0 +1,89 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package client_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/client"
+	"github.com/cockroachdb/cockroach/util/leaktest"
+)
+
+func TestSnapshot(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, db := setup()
+	defer s.Stop()
+
+	b := &client.Batch{}
+	b.Put("a", "1")
+	b.Put("b", "1")
+	if err := db.Run(b); err != nil {
+		t.Fatal(err)
+	}
+	snap := db.ReadAt(s.Clock().Now())
+
+	b = &client.Batch{}
+	b.Put("a", "2")
+	b.Put("c", "2")
+	b.Del("b")
+	if err := db.Run(b); err != nil {
+		t.Fatal(err)
+	}
+
+	// The snapshot observes the values as of its timestamp.
+	if r, err := snap.Get("a"); err != nil {
+		t.Fatal(err)
+	} else if v := string(r.ValueBytes()); v != "1" {
+		t.Errorf("expected 1, but got %s", v)
+	}
+	if r, err := db.Get("a"); err != nil {
+		t.Fatal(err)
+	} else if v := string(r.ValueBytes()); v != "2" {
+		t.Errorf("expected 2, but got %s", v)
+	}
+
+	expected := []string{"a=1", "b=1"}
+	rows, err := snap.Scan("a", "d", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result []string
+	for _, kv := range rows {
+		result = append(result, kv.String())
+	}
+	if !reflect.DeepEqual(expected, result) {
+		t.Errorf("expected %v, but got %v", expected, result)
+	}
+	if result, err = iterate(snap.NewIterator("a", "d", 1)); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(expected, result) {
+		t.Errorf("expected %v, but got %v", expected, result)
+	}
+
+	// Writes are rejected.
+	b = snap.NewBatch()
+	b.Get("a")
+	b.Put("d", "3")
+	if err := snap.Run(b); !isError(err, "Put cannot be run on a read-only snapshot") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if r, err := db.Get("d"); err != nil {
+		t.Fatal(err)
+	} else if r.Exists() {
+		t.Errorf("expected d to not exist, but got %s", r)
+	}
+}
@@ -204,6 +204,72 @@ func (m *model) encodeColumnKey(primaryKey []byte, colID uint32) []byte {
 	return roachencoding.EncodeUvarint(key, uint64(colID))
 }
 
+// decodeColumn decodes the primary key and the column of a single key/value
+// pair of the table into the model object v. Columns not present in
+// scanColIDs are skipped unless scanColIDs is nil. It returns the encoded
+// primary key.
+func (m *model) decodeColumn(kv *proto.KeyValue, v reflect.Value,
+	scanColIDs map[uint32]bool) ([]byte, error) {
+	remaining, err := m.decodePrimaryKey([]byte(kv.Key), v)
+	if err != nil {
+		return nil, err
+	}
+	primaryKey := []byte(kv.Key[:len(kv.Key)-len(remaining)])
+
+	_, colID := roachencoding.DecodeUvarint(remaining)
+	if scanColIDs != nil && !scanColIDs[uint32(colID)] {
+		return primaryKey, nil
+	}
+	col, ok := m.columnsByID[uint32(colID)]
+	if !ok {
+		return nil, fmt.Errorf("%s: unable to find column %d", m.name, colID)
+	}
+	if err := unmarshalValue(&kv.Value, v.FieldByIndex(col.field.Index)); err != nil {
+		return nil, err
+	}
+	return primaryKey, nil
+}
+
+// scanColumnIDs returns the IDs of the named columns, or nil if columns is
+// empty.
+func (m *model) scanColumnIDs(columns []string) (map[uint32]bool, error) {
+	if len(columns) == 0 {
+		return nil, nil
+	}
+	lowerStrings(columns)
+	scanColIDs := make(map[uint32]bool, len(columns))
+	for _, colName := range columns {
+		col, ok := m.columnsByName[colName]
+		if !ok {
+			return nil, fmt.Errorf("%s: unable to find column %s", m.name, colName)
+		}
+		scanColIDs[col.ID] = true
+	}
+	return scanColIDs, nil
+}
+
+// encodeScanKeys encodes the primary keys of the model objects start and end,
+// which must be of type modelT.
+func (m *model) encodeScanKeys(modelT reflect.Type, start, end interface{}) (proto.Key, proto.Key, error) {
+	startV := reflect.Indirect(reflect.ValueOf(start))
+	if modelT != startV.Type() {
+		return nil, nil, fmt.Errorf("incompatible start key type: %s != %s", modelT, startV.Type())
+	}
+	endV := reflect.Indirect(reflect.ValueOf(end))
+	if modelT != endV.Type() {
+		return nil, nil, fmt.Errorf("incompatible end key type: %s != %s", modelT, endV.Type())
+	}
+	startKey, err := m.encodePrimaryKey(startV)
+	if err != nil {
+		return nil, nil, err
+	}
+	endKey, err := m.encodePrimaryKey(endV)
+	if err != nil {
+		return nil, nil, err
+	}
+	return proto.Key(startKey), proto.Key(endKey), nil
+}
+
 // CreateNamespace creates a new namespace.
 //
 // TODO(pmattis): Is "namespace" the correct terminology? PostgreSQL and MySQL
@@ -355,6 +421,7 @@ func (db *DB) RenameTable(oldPath, newPath string) error {
 			return err
 		}
 		desc.Name = strings.ToLower(newPath)
+		desc.Version++
 		if err := structured.ValidateTableDesc(desc); err != nil {
 			return err
 		}
@@ -380,21 +447,31 @@ func (db *DB) DeleteTable(path string) error {
 		return fmt.Errorf("empty table name: %s", path)
 	}
 	nameKey := keys.MakeNameMetadataKey(nsID, name)
-	gr, err := db.Get(nameKey)
-	if err != nil {
-		return err
-	}
-	if !gr.Exists() {
-		return fmt.Errorf("unable to find table \"%s\"", path)
-	}
-	descKey := gr.ValueBytes()
 	desc := structured.TableDescriptor{}
-	if err := db.GetProto(descKey, &desc); err != nil {
+	err = db.Txn(func(txn *Txn) error {
+		gr, err := txn.Get(nameKey)
+		if err != nil {
+			return err
+		}
+		if !gr.Exists() {
+			return fmt.Errorf("unable to find table \"%s\"", path)
+		}
+		descKey := gr.ValueBytes()
+		if err := txn.GetProto(descKey, &desc); err != nil {
+			return err
+		}
+		b := &Batch{}
+		b.Del(nameKey, descKey)
+		return txn.Commit(b)
+	})
+	if err != nil {
 		return err
 	}
 
-	panic("TODO(pmattis): delete all of the tables rows")
-	// return db.Del(descKey)
+	// The table is no longer reachable once the metadata has been removed, so
+	// the table data can be deleted outside of the transaction.
+	prefix := keys.MakeTablePrefix(desc.ID)
+	return db.DelRange(prefix, prefix.PrefixEnd())
 }
 
 // ListTables lists the tables in the specified namespace.
@@ -442,7 +519,7 @@ func (db *DB) BindModel(name string, obj interface{}) error {
 	columnsByID := map[uint32]*column{}
 	for _, col := range desc.Columns {
 		f, ok := fields[col.Name]
-		if !ok {
+		if !ok || !col.IsPublic() {
 			continue
 		}
 		c := &column{
@@ -463,6 +540,9 @@ func (db *DB) BindModel(name string, obj interface{}) error {
 
 	var otherColumnNames []string
 	for _, col := range desc.Columns {
+		if !col.IsPublic() {
+			continue
+		}
 		if _, ok := isPrimaryKey[col.Name]; ok {
 			if _, ok2 := columnsByName[col.Name]; !ok2 {
 				return fmt.Errorf("primary key column \"%s\" not mapped", col.Name)
@@ -781,38 +861,13 @@ func (b *Batch) ScanStruct(dest, start, end interface{}, maxRows int64, columns
 		return
 	}
 
-	var scanColIDs map[uint32]bool
-	if len(columns) > 0 {
-		lowerStrings(columns)
-		scanColIDs = make(map[uint32]bool, len(columns))
-		for _, colName := range columns {
-			col, ok := m.columnsByName[colName]
-			if !ok {
-				b.initResult(0, 0, fmt.Errorf("%s: unable to find column %s", m.name, colName))
-				return
-			}
-			scanColIDs[col.ID] = true
-		}
-	}
-
-	startV := reflect.Indirect(reflect.ValueOf(start))
-	if modelT != startV.Type() {
-		b.initResult(0, 0, fmt.Errorf("incompatible start key type: %s != %s", modelT, startV.Type()))
-		return
-	}
-
-	endV := reflect.Indirect(reflect.ValueOf(end))
-	if modelT != endV.Type() {
-		b.initResult(0, 0, fmt.Errorf("incompatible end key type: %s != %s", modelT, endV.Type()))
-		return
-	}
-
-	startKey, err := m.encodePrimaryKey(startV)
+	scanColIDs, err := m.scanColumnIDs(columns)
 	if err != nil {
 		b.initResult(0, 0, err)
 		return
 	}
-	endKey, err := m.encodePrimaryKey(endV)
+
+	startKey, endKey, err := m.encodeScanKeys(modelT, start, end)
 	if err != nil {
 		b.initResult(0, 0, err)
 		return
@@ -821,7 +876,7 @@ func (b *Batch) ScanStruct(dest, start, end interface{}, maxRows int64, columns
 		log.Infof("Scan %q %q", startKey, endKey)
 	}
 
-	c := proto.ScanCall(proto.Key(startKey), proto.Key(endKey), maxRows)
+	c := proto.ScanCall(startKey, endKey, maxRows)
 	c.Post = func() error {
 		reply := c.Reply.(*proto.ScanResponse)
 		if len(reply.Rows) == 0 {
@@ -833,7 +888,7 @@ func (b *Batch) ScanStruct(dest, start, end interface{}, maxRows int64, columns
 		result := resultPtr.Elem()
 		zero := reflect.Zero(result.Type())
 
-		for _, row := range reply.Rows {
+		for i, row := range reply.Rows {
 			if primaryKey != nil && !bytes.HasPrefix(row.Key, primaryKey) {
 				if ptrResults {
 					sliceV = reflect.Append(sliceV, resultPtr)
@@ -849,24 +904,8 @@ func (b *Batch) ScanStruct(dest, start, end interface{}, maxRows int64, columns
 				}
 			}
 
-			remaining, err := m.decodePrimaryKey([]byte(row.Key), result)
-			if err != nil {
-				return err
-			}
-			primaryKey = []byte(row.Key[:len(row.Key)-len(remaining)])
-
-			_, colID := roachencoding.DecodeUvarint(remaining)
-			if err != nil {
-				return err
-			}
-			if scanColIDs != nil && !scanColIDs[uint32(colID)] {
-				continue
-			}
-			col, ok := m.columnsByID[uint32(colID)]
-			if !ok {
-				return fmt.Errorf("%s: unable to find column %d", m.name, colID)
-			}
-			if err := unmarshalValue(&row.Value, result.FieldByIndex(col.field.Index)); err != nil {
+			var err error
+			if primaryKey, err = m.decodeColumn(&reply.Rows[i], result, scanColIDs); err != nil {
 				return err
 			}
 		}
@@ -175,6 +175,62 @@ func TestRenameTable(t *testing.T) {
 	}
 }
 
+func TestDeleteTable(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, db := setup()
+	defer s.Stop()
+
+	type User struct {
+		ID   int    `db:"id" roach:"primary key"`
+		Name string `db:"name"`
+	}
+
+	if err := db.CreateNamespace("t"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Cannot delete a non-existent table.
+	if err := db.DeleteTable("t.users"); !isError(err, "unable to find table") {
+		t.Fatalf("expected failure, but found '%+v'", err)
+	}
+
+	schema, err := client.SchemaFromModel(User{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema.Name = "t.users"
+	if err := db.CreateTable(schema); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.BindModel("t.users", User{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.PutStruct(User{ID: 1, Name: "Peter"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.DeleteTable("t.users"); err != nil {
+		t.Fatal(err)
+	}
+
+	tables, err := db.ListTables("t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 0 {
+		t.Errorf("expected no tables, but got %+v", tables)
+	}
+
+	// The table data was deleted along with the table.
+	rows, err := db.Scan(keys.TableDataPrefix, keys.TableDataPrefix.PrefixEnd(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 0 {
+		t.Errorf("expected no table data, but got %+v", rows)
+	}
+}
+
 func TestListTables(t *testing.T) {
 	defer leaktest.AfterTest(t)
 	s, db := setup()
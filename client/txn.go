@@ -210,6 +210,21 @@ func (txn *Txn) Scan(begin, end interface{}, maxRows int64) ([]KeyValue, error)
 	return r.Rows, err
 }
 
+// ReverseScan retrieves the rows between begin (inclusive) and end (exclusive)
+// in descending key order.
+//
+// The returned []KeyValue will contain up to maxRows elements, starting with
+// the last row before end.
+//
+// key can be either a byte slice, a string, a fmt.Stringer or an
+// encoding.BinaryMarshaler.
+func (txn *Txn) ReverseScan(begin, end interface{}, maxRows int64) ([]KeyValue, error) {
+	b := txn.NewBatch()
+	b.ReverseScan(begin, end, maxRows)
+	r, err := runOneResult(txn, b)
+	return r.Rows, err
+}
+
 // Del deletes one or more keys.
 //
 // key can be either a byte slice, a string, a fmt.Stringer or an
@@ -268,6 +283,24 @@ func (txn *Txn) Commit(b *Batch) error {
 	return txn.Run(b)
 }
 
+// Rollback aborts the transaction, discarding any writes it performed. It is a
+// no-op if the transaction has not performed any operations.
+func (txn *Txn) Rollback() error {
+	if len(txn.txn.ID) == 0 {
+		return nil
+	}
+	return txn.send(proto.Call{
+		Args:  &proto.EndTransactionRequest{Commit: false},
+		Reply: &proto.EndTransactionResponse{},
+	})
+}
+
+// Proto returns the current state of the transaction. It can be passed to
+// DB.NewTxn in order to resume the transaction.
+func (txn *Txn) Proto() proto.Transaction {
+	return txn.txn
+}
+
 func (txn *Txn) exec(retryable func(txn *Txn) error) error {
 	// Run retryable in a retry loop until we encounter a success or
 	// error condition this loop isn't capable of handling.
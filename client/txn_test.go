@@ -296,3 +296,57 @@ func TestRunTransactionRetryOnErrors(t *testing.T) {
 		}
 	}
 }
+
+// TestResumeTransaction verifies that a transaction can be resumed using its
+// proto and that the resumed transaction can be committed or rolled back.
+func TestResumeTransaction(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	for _, commit := range []bool{true, false} {
+		var ids [][]byte
+		var ends []bool
+		db := newDB(newTestSender(func(call proto.Call) {
+			ids = append(ids, call.Args.Header().Txn.ID)
+			if et, ok := call.Args.(*proto.EndTransactionRequest); ok {
+				ends = append(ends, et.Commit)
+			}
+		}))
+
+		txn := db.NewTxn(nil)
+		if err := txn.Put("a", "b"); err != nil {
+			t.Fatal(err)
+		}
+		txnProto := txn.Proto()
+		if len(txnProto.ID) == 0 {
+			t.Fatalf("expected transaction to have been started")
+		}
+
+		txn = db.NewTxn(&txnProto)
+		var err error
+		if commit {
+			err = txn.Commit(&Batch{})
+		} else {
+			err = txn.Rollback()
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ids) != 2 || !reflect.DeepEqual(ids[0], ids[1]) {
+			t.Errorf("expected both requests to use the same transaction; got %q", ids)
+		}
+		if !reflect.DeepEqual([]bool{commit}, ends) {
+			t.Errorf("expected EndTransaction with commit=%t; got %v", commit, ends)
+		}
+	}
+}
+
+// TestRollbackUnstartedTransaction verifies that rolling back a transaction
+// which has not performed any operations does not send an EndTransaction.
+func TestRollbackUnstartedTransaction(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	db := newDB(newTestSender(func(call proto.Call) {
+		t.Errorf("unexpected call %s", call.Method())
+	}))
+	if err := db.NewTxn(nil).Rollback(); err != nil {
+		t.Fatal(err)
+	}
+}
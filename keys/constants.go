@@ -160,6 +160,9 @@ var (
 	NodeIDGenerator = MakeKey(SystemPrefix, proto.Key("node-idgen"))
 	// RaftIDGenerator is the global Raft consensus group ID generator sequence.
 	RaftIDGenerator = MakeKey(SystemPrefix, proto.Key("raft-idgen"))
+	// RowIDGenerator is the global row ID generator sequence used for the
+	// values of SERIAL columns.
+	RowIDGenerator = MakeKey(SystemPrefix, proto.Key("row-idgen"))
 	// SchemaPrefix specifies key prefixes for schema definitions.
 	SchemaPrefix = MakeKey(SystemPrefix, proto.Key("schema"))
 	// NameMetadataPrefix is the key prefix for all name metadata.
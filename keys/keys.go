@@ -73,6 +73,15 @@ func MakeDescMetadataKey(descID uint32) proto.Key {
 	return k
 }
 
+// MakeTablePrefix returns the key prefix shared by all of the data keys of the
+// table, including the keys of all of its indexes.
+func MakeTablePrefix(tableID uint32) proto.Key {
+	k := make([]byte, 0, len(TableDataPrefix)+encoding.MaxUvarintSize)
+	k = append(k, TableDataPrefix...)
+	k = encoding.EncodeUvarint(k, uint64(tableID))
+	return k
+}
+
 // indexKeyBufferWidth returns a likely cap on the width of the index key.
 // The buffer width can likely accomodate the encoded constant prefix, tableID,
 // indexID, and column values.
@@ -76,6 +76,36 @@ func TestKeyAddress(t *testing.T) {
 	}
 }
 
+func TestMakeTablePrefix(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	prefix := MakeTablePrefix(12)
+	expPrefix := MakeKey(TableDataPrefix, encoding.EncodeUvarint(nil, 12))
+	if !prefix.Equal(expPrefix) {
+		t.Errorf("prefix %q doesn't match expected %q", prefix, expPrefix)
+	}
+	// All of the keys of the table, and only those keys, are within the span
+	// of the prefix.
+	end := prefix.PrefixEnd()
+	for _, key := range []proto.Key{
+		MakeTableIndexKey(12, 0, []byte("foo")),
+		MakeTableIndexKey(12, 345, []byte("foo")),
+		MakeTableDataKey(12, 1, 6, []byte("bar")),
+	} {
+		if bytes.Compare(key, prefix) < 0 || bytes.Compare(key, end) >= 0 {
+			t.Errorf("key %q not within table span [%q, %q)", key, prefix, end)
+		}
+	}
+	for _, key := range []proto.Key{
+		MakeTableIndexKey(11, 345, []byte("foo")),
+		MakeTableIndexKey(13, 0, []byte("foo")),
+		MakeTableIndexKey(1200, 0, []byte("foo")),
+	} {
+		if bytes.Compare(key, prefix) >= 0 && bytes.Compare(key, end) < 0 {
+			t.Errorf("key %q unexpectedly within table span [%q, %q)", key, prefix, end)
+		}
+	}
+}
+
 func TestMakeTableIndexKey(t *testing.T) {
 	defer leaktest.AfterTest(t)
 	key := MakeTableIndexKey(12, 345, []byte("foo"), []byte("bar"))
@@ -60,6 +60,7 @@ var allPublicMethods = map[string]proto.Method{
 	proto.Delete.String():         proto.Delete,
 	proto.DeleteRange.String():    proto.DeleteRange,
 	proto.Scan.String():           proto.Scan,
+	proto.ReverseScan.String():    proto.ReverseScan,
 	proto.EndTransaction.String(): proto.EndTransaction,
 	proto.Batch.String():          proto.Batch,
 	proto.AdminSplit.String():     proto.AdminSplit,
@@ -88,6 +89,8 @@ func createArgsAndReply(method string) (proto.Request, proto.Response) {
 			return &proto.DeleteRangeRequest{}, &proto.DeleteRangeResponse{}
 		case proto.Scan:
 			return &proto.ScanRequest{}, &proto.ScanResponse{}
+		case proto.ReverseScan:
+			return &proto.ReverseScanRequest{}, &proto.ReverseScanResponse{}
 		case proto.EndTransaction:
 			return &proto.EndTransactionRequest{}, &proto.EndTransactionResponse{}
 		case proto.Batch:
@@ -221,6 +224,10 @@ func (s *rpcDBServer) Scan(args *proto.ScanRequest, reply *proto.ScanResponse) e
 	return s.executeCmd(args, reply)
 }
 
+func (s *rpcDBServer) ReverseScan(args *proto.ReverseScanRequest, reply *proto.ReverseScanResponse) error {
+	return s.executeCmd(args, reply)
+}
+
 func (s *rpcDBServer) EndTransaction(args *proto.EndTransactionRequest, reply *proto.EndTransactionResponse) error {
 	return s.executeCmd(args, reply)
 }
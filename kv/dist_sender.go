@@ -268,6 +268,10 @@ func (ds *DistSender) verifyPermissions(args proto.Request) error {
 // lookupOptions capture additional options to pass to InternalRangeLookup.
 type lookupOptions struct {
 	ignoreIntents bool
+	// inclusive looks up the range whose end key is the first one >= the
+	// key, instead of the range containing the key. See
+	// proto.InternalRangeLookupRequest.
+	inclusive bool
 }
 
 // internalRangeLookup dispatches an InternalRangeLookup request for the given
@@ -288,6 +292,7 @@ func (ds *DistSender) internalRangeLookup(key proto.Key, options lookupOptions,
 		},
 		MaxRanges:     ds.rangeLookupMaxRanges,
 		IgnoreIntents: options.ignoreIntents,
+		Inclusive:     options.inclusive,
 	}
 	reply := &proto.InternalRangeLookupResponse{}
 	replicas := newReplicaSlice(ds.gossip, desc)
@@ -344,8 +349,11 @@ func (ds *DistSender) getRangeDescriptors(key proto.Key, options lookupOptions)
 		}
 	} else {
 		// Look up desc from the cache, which will recursively call into
-		// ds.getRangeDescriptors if it is not cached.
-		desc, err = ds.rangeCache.LookupRangeDescriptor(metadataKey, options)
+		// ds.getRangeDescriptors if it is not cached. The range which
+		// contains metadataKey is needed even for an inclusive lookup.
+		metaOptions := options
+		metaOptions.inclusive = false
+		desc, err = ds.rangeCache.LookupRangeDescriptor(metadataKey, metaOptions)
 		if err != nil {
 			return nil, err
 		}
@@ -563,6 +571,12 @@ func (ds *DistSender) sendAttempt(desc *proto.RangeDescriptor, call proto.Call)
 // descriptors associated with it. First, the range descriptor for
 // call.Args.Key is looked up. If call.Args.EndKey exceeds that of the
 // returned descriptor, the next descriptor is obtained as well.
+//
+// For reverse requests, which traverse the ranges starting from the
+// end of their key span, the range descriptor for the key preceding
+// call.Args.EndKey is looked up first. If call.Args.Key precedes the
+// start of the returned descriptor, the descriptor of the preceding
+// range is obtained as the next descriptor.
 func (ds *DistSender) getDescriptors(call proto.Call) (*proto.RangeDescriptor, *proto.RangeDescriptor, error) {
 	// If this is an InternalPushTxn, set ignoreIntents option as
 	// necessary. This prevents a potential infinite loop; see the
@@ -572,15 +586,30 @@ func (ds *DistSender) getDescriptors(call proto.Call) (*proto.RangeDescriptor, *
 		options.ignoreIntents = pushArgs.RangeLookup
 	}
 
-	desc, err := ds.rangeCache.LookupRangeDescriptor(call.Args.Header().Key, options)
+	reverse := proto.IsReverse(call.Args)
+	key := call.Args.Header().Key
+	if reverse {
+		options.inclusive = true
+		key = call.Args.Header().EndKey
+	}
+	desc, err := ds.rangeCache.LookupRangeDescriptor(key, options)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	var descNext *proto.RangeDescriptor
-	// If the request accesses keys beyond the end of this range,
-	// get the descriptor of the adjacent range to address next.
-	if desc.EndKey.Less(call.Args.Header().EndKey) {
+	// If the request accesses keys beyond the end of this range (or,
+	// in reverse, before its start), get the descriptor of the
+	// adjacent range to address next.
+	var nextKey proto.Key
+	if reverse {
+		if call.Args.Header().Key.Less(desc.StartKey) {
+			nextKey = desc.StartKey
+		}
+	} else if desc.EndKey.Less(call.Args.Header().EndKey) {
+		nextKey = desc.EndKey
+	}
+	if nextKey != nil {
 		if _, ok := call.Reply.(proto.Combinable); !ok {
 			return nil, nil, util.Error("illegal cross-range operation")
 		}
@@ -595,7 +624,7 @@ func (ds *DistSender) getDescriptors(call proto.Call) (*proto.RangeDescriptor, *
 		// This next lookup is likely for free since we've read the
 		// previous descriptor and range lookups use cache
 		// prefetching.
-		descNext, err = ds.rangeCache.LookupRangeDescriptor(desc.EndKey, options)
+		descNext, err = ds.rangeCache.LookupRangeDescriptor(nextKey, options)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -616,7 +645,8 @@ func (ds *DistSender) getDescriptors(call proto.Call) (*proto.RangeDescriptor, *
 func (ds *DistSender) Send(_ context.Context, call proto.Call) {
 	args := call.Args
 	finalReply := call.Reply
-	endKey := args.Header().EndKey
+	key, endKey := args.Header().Key, args.Header().EndKey
+	reverse := proto.IsReverse(args)
 
 	// Verify permissions.
 	if err := ds.verifyPermissions(call.Args); err != nil {
@@ -672,11 +702,19 @@ func (ds *DistSender) Send(_ context.Context, call proto.Call) {
 			// touch it unless we have to (it is illegal to send EndKey on
 			// commands which do not operate on ranges).
 			if descNext != nil {
-				args.Header().EndKey = desc.EndKey
-				defer func() {
-					// "Untruncate" EndKey to original.
-					args.Header().EndKey = endKey
-				}()
+				if reverse {
+					args.Header().Key = desc.StartKey
+					defer func() {
+						// "Untruncate" Key to original.
+						args.Header().Key = key
+					}()
+				} else {
+					args.Header().EndKey = desc.EndKey
+					defer func() {
+						// "Untruncate" EndKey to original.
+						args.Header().EndKey = endKey
+					}()
+				}
 			}
 			return ds.sendAttempt(desc, call)
 		})
@@ -728,14 +766,25 @@ func (ds *DistSender) Send(_ context.Context, call proto.Call) {
 			// so it's a convenient place to clean up changes to the args in
 			// the case of multi-range requests.
 			// Reset original start key (the EndKey is taken care of without
-			// defer above).
-			defer func(k proto.Key) {
-				args.Header().Key = k
-			}(args.Header().Key)
+			// defer above). In reverse, the end key is reset instead.
+			if reverse {
+				defer func() {
+					args.Header().EndKey = endKey
+				}()
+			} else {
+				defer func() {
+					args.Header().Key = key
+				}()
+			}
 		}
 
-		// In next iteration, query next range.
-		args.Header().Key = descNext.StartKey
+		// In next iteration, query next range. In reverse, the next
+		// range precedes the current one.
+		if reverse {
+			args.Header().EndKey = descNext.EndKey
+		} else {
+			args.Header().Key = descNext.StartKey
+		}
 
 		// This is a multi-range request, make a new reply object for
 		// subsequent iterations of the loop.
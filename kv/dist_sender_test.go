@@ -456,7 +456,7 @@ func TestEvictCacheOnError(t *testing.T) {
 		if cur := ds.leaderCache.Lookup(1); reflect.DeepEqual(cur, &proto.Replica{}) && !tc.shouldClearLeader {
 			t.Errorf("%d: leader cache eviction: shouldClearLeader=%t, but value is %v", i, tc.shouldClearLeader, cur)
 		}
-		_, cachedDesc := ds.rangeCache.getCachedRangeDescriptor(call.Args.Header().Key)
+		_, cachedDesc := ds.rangeCache.getCachedRangeDescriptor(call.Args.Header().Key, false)
 		if cachedDesc == nil != tc.shouldClearReplica {
 			t.Errorf("%d: unexpected second replica lookup behaviour: wanted=%t", i, tc.shouldClearReplica)
 		}
@@ -802,3 +802,92 @@ func TestGetNodeDescriptor(t *testing.T) {
 		return util.Errorf("wanted NodeID 5, got %v", desc)
 	})
 }
+
+// TestMultiRangeReverseScan verifies that the DistSender sends a reverse
+// scan to the ranges it spans starting with the last one, truncates the
+// request to each range, stops once enough rows have been retrieved and
+// restores the original request afterwards.
+func TestMultiRangeReverseScan(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	g, s := makeTestGossip(t)
+	defer s()
+
+	var descs []proto.RangeDescriptor
+	for i, span := range [][2]string{{"a", "c"}, {"c", "f"}, {"f", "z"}} {
+		descs = append(descs, proto.RangeDescriptor{
+			RaftID:   proto.RaftID(i + 1),
+			StartKey: proto.Key(span[0]),
+			EndKey:   proto.Key(span[1]),
+			Replicas: testRangeDescriptor.Replicas,
+		})
+	}
+	data := []string{"a", "b", "c", "d", "e", "f", "g"}
+
+	var spans []string
+	var testFn rpcSendFn = func(_ rpc.Options, method string, addrs []net.Addr, getArgs func(addr net.Addr) interface{}, getReply func() interface{}, _ *rpc.Context) ([]interface{}, error) {
+		args := getArgs(testAddress).(*proto.ReverseScanRequest)
+		spans = append(spans, string(args.Key)+"-"+string(args.EndKey))
+		reply := getReply().(*proto.ReverseScanResponse)
+		for i := len(data) - 1; i >= 0; i-- {
+			key := proto.Key(data[i])
+			if args.MaxResults > 0 && int64(len(reply.Rows)) == args.MaxResults {
+				break
+			}
+			if !key.Less(args.Key) && key.Less(args.EndKey) {
+				reply.Rows = append(reply.Rows, proto.KeyValue{Key: key})
+			}
+		}
+		return []interface{}{reply}, nil
+	}
+	ctx := &DistSenderContext{
+		rpcSend: testFn,
+		rangeDescriptorDB: mockRangeDescriptorDB(func(key proto.Key, opts lookupOptions) ([]proto.RangeDescriptor, error) {
+			for _, desc := range descs {
+				if (opts.inclusive && desc.ContainsExclusiveEndKey(key)) ||
+					(!opts.inclusive && desc.ContainsKey(key)) {
+					return []proto.RangeDescriptor{desc}, nil
+				}
+			}
+			return nil, util.Errorf("no range for key %s", key)
+		}),
+	}
+	ds := NewDistSender(ctx, g)
+
+	testCases := []struct {
+		key, endKey string
+		max         int64
+		expSpans    []string
+		expKeys     string
+	}{
+		{"a", "z", 0, []string{"f-z", "c-f", "a-c"}, "gfedcba"},
+		// An end key at a range boundary starts with the range ending there.
+		{"b", "f", 0, []string{"c-f", "b-c"}, "edcb"},
+		{"d", "e", 0, []string{"d-e"}, "d"},
+		// The scan stops once enough rows have been retrieved.
+		{"a", "z", 3, []string{"f-z", "c-f"}, "gfe"},
+	}
+	for i, test := range testCases {
+		spans = nil
+		call := proto.ReverseScanCall(proto.Key(test.key), proto.Key(test.endKey), test.max)
+		args := call.Args.(*proto.ReverseScanRequest)
+		args.ReadConsistency = proto.INCONSISTENT
+		reply := call.Reply.(*proto.ReverseScanResponse)
+		ds.Send(context.Background(), call)
+		if err := reply.GoError(); err != nil {
+			t.Fatalf("%d: %s", i, err)
+		}
+		if !reflect.DeepEqual(test.expSpans, spans) {
+			t.Errorf("%d: expected spans %v; got %v", i, test.expSpans, spans)
+		}
+		var rows string
+		for _, kv := range reply.Rows {
+			rows += string(kv.Key)
+		}
+		if rows != test.expKeys {
+			t.Errorf("%d: expected keys %q; got %q", i, test.expKeys, rows)
+		}
+		if string(args.Key) != test.key || string(args.EndKey) != test.endKey || args.MaxResults != test.max {
+			t.Errorf("%d: request was not restored: %s-%s max=%d", i, args.Key, args.EndKey, args.MaxResults)
+		}
+	}
+}
@@ -114,7 +114,7 @@ func (rdc *rangeDescriptorCache) stringLocked() string {
 // the key's data, or an error if any occurred.
 func (rdc *rangeDescriptorCache) LookupRangeDescriptor(key proto.Key,
 	options lookupOptions) (*proto.RangeDescriptor, error) {
-	if _, r := rdc.getCachedRangeDescriptor(key); r != nil {
+	if _, r := rdc.getCachedRangeDescriptor(key, options.inclusive); r != nil {
 		return r, nil
 	}
 
@@ -173,7 +173,7 @@ func (rdc *rangeDescriptorCache) EvictCachedRangeDescriptor(descKey proto.Key, s
 	rdc.rangeCacheMu.Lock()
 	defer rdc.rangeCacheMu.Unlock()
 
-	rngKey, cachedDesc := rdc.getCachedRangeDescriptorLocked(descKey)
+	rngKey, cachedDesc := rdc.getCachedRangeDescriptorLocked(descKey, false)
 	// Note that we're doing a "compare-and-erase": If seenDesc is not nil,
 	// we want to clean the cache only if it equals the cached range
 	// descriptor as a pointer. If not, then likely some other caller
@@ -196,7 +196,7 @@ func (rdc *rangeDescriptorCache) EvictCachedRangeDescriptor(descKey proto.Key, s
 		// evict that key as well. This loop ends after the meta1 range, which
 		// returns KeyMin as its metadata key.
 		descKey = keys.RangeMetaKey(descKey)
-		rngKey, cachedDesc = rdc.getCachedRangeDescriptorLocked(descKey)
+		rngKey, cachedDesc = rdc.getCachedRangeDescriptorLocked(descKey, false)
 	}
 }
 
@@ -204,22 +204,27 @@ func (rdc *rangeDescriptorCache) EvictCachedRangeDescriptor(descKey proto.Key, s
 // the range which contains the given key, if present in the cache. It
 // acquires a read lock on rdc.rangeCacheMu before delegating to
 // getCachedRangeDescriptorLocked.
-func (rdc *rangeDescriptorCache) getCachedRangeDescriptor(key proto.Key) (
+func (rdc *rangeDescriptorCache) getCachedRangeDescriptor(key proto.Key, inclusive bool) (
 	rangeCacheKey, *proto.RangeDescriptor) {
 	rdc.rangeCacheMu.RLock()
 	defer rdc.rangeCacheMu.RUnlock()
-	return rdc.getCachedRangeDescriptorLocked(key)
+	return rdc.getCachedRangeDescriptorLocked(key, inclusive)
 }
 
 // getCachedRangeDescriptorLocked is a helper function to retrieve the
 // descriptor of the range which contains the given key, if present in the
-// cache. It is assumed that the caller holds a read lock on rdc.rangeCacheMu.
-func (rdc *rangeDescriptorCache) getCachedRangeDescriptorLocked(key proto.Key) (
+// cache. If inclusive is true, the key is treated as an inclusive end key
+// and the descriptor of the range which ends at or after it is retrieved.
+// It is assumed that the caller holds a read lock on rdc.rangeCacheMu.
+func (rdc *rangeDescriptorCache) getCachedRangeDescriptorLocked(key proto.Key, inclusive bool) (
 	rangeCacheKey, *proto.RangeDescriptor) {
 	// The cache is indexed using the end-key of the range, but the
 	// end-key is non-inclusive. If inclusive is false, we access the
 	// cache using key.Next().
-	metaKey := keys.RangeMetaKey(key.Next())
+	metaKey := keys.RangeMetaKey(key)
+	if !inclusive {
+		metaKey = keys.RangeMetaKey(key.Next())
+	}
 
 	k, v, ok := rdc.rangeCache.Ceil(rangeCacheKey(metaKey))
 	if !ok {
@@ -229,7 +234,11 @@ func (rdc *rangeDescriptorCache) getCachedRangeDescriptorLocked(key proto.Key) (
 	rd := v.(*proto.RangeDescriptor)
 
 	// Check that key actually belongs to range
-	if !rd.ContainsKey(keys.KeyAddress(key)) {
+	if inclusive {
+		if !rd.ContainsExclusiveEndKey(keys.KeyAddress(key)) {
+			return nil, nil
+		}
+	} else if !rd.ContainsKey(keys.KeyAddress(key)) {
 		return nil, nil
 	}
 	return metaEndKey, rd
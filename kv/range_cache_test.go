@@ -44,13 +44,17 @@ func (a testDescriptorNode) Compare(b llrb.Comparable) int {
 	return bytes.Compare(aKey, bKey)
 }
 
-func (db *testDescriptorDB) getDescriptor(key proto.Key) []proto.RangeDescriptor {
+func (db *testDescriptorDB) getDescriptor(key proto.Key, inclusive bool) []proto.RangeDescriptor {
 	log.Infof("getDescriptor: %s", key)
 	response := make([]proto.RangeDescriptor, 0, 3)
 	for i := 0; i < 3; i++ {
+		endKey := key.Next()
+		if inclusive && i == 0 {
+			endKey = key
+		}
 		v := db.data.Ceil(testDescriptorNode{
 			&proto.RangeDescriptor{
-				EndKey: key.Next(),
+				EndKey: endKey,
 			},
 		})
 		if v == nil {
@@ -76,9 +80,11 @@ func (db *testDescriptorDB) getRangeDescriptors(key proto.Key,
 	// Recursively call into cache as the real DB would, terminating recursion
 	// when a meta1key is encountered.
 	if len(metadataKey) > 0 && !bytes.HasPrefix(metadataKey, keys.Meta1Prefix) {
-		_, err = db.cache.LookupRangeDescriptor(metadataKey, options)
+		metaOptions := options
+		metaOptions.inclusive = false
+		_, err = db.cache.LookupRangeDescriptor(metadataKey, metaOptions)
 	}
-	return db.getDescriptor(key), err
+	return db.getDescriptor(key, options.inclusive), err
 }
 
 func (db *testDescriptorDB) splitRange(t *testing.T, key proto.Key) {
@@ -220,6 +226,42 @@ func TestRangeCache(t *testing.T) {
 
 }
 
+// TestRangeCacheInclusiveLookup verifies that an inclusive lookup of a
+// key returns the range which ends at or after the key, both when the
+// range is looked up and when it is cached.
+func TestRangeCacheInclusiveLookup(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	db := newTestDescriptorDB()
+	for _, char := range "bcdefg" {
+		db.splitRange(t, proto.Key(string(char)))
+	}
+	db.cache = newRangeDescriptorCache(db, 2<<10)
+
+	testCases := []struct {
+		key        proto.Key
+		start, end proto.Key
+		lookups    int
+	}{
+		{proto.Key("c"), proto.Key("b"), proto.Key("c"), 2},
+		// The range ending at "d" was prefetched with the previous lookup.
+		{proto.Key("d"), proto.Key("c"), proto.Key("d"), 0},
+		{proto.Key("cc"), proto.Key("c"), proto.Key("d"), 0},
+		{proto.Key("f"), proto.Key("e"), proto.Key("f"), 1},
+		{proto.KeyMax, proto.Key("g"), proto.KeyMax, 0},
+	}
+	for i, test := range testCases {
+		desc, err := db.cache.LookupRangeDescriptor(test.key, lookupOptions{inclusive: true})
+		if err != nil {
+			t.Fatalf("%d: unexpected error: %s", i, err)
+		}
+		if !desc.StartKey.Equal(test.start) || !desc.EndKey.Equal(test.end) {
+			t.Errorf("%d: expected range %s-%s for %s; got %s-%s", i, test.start, test.end,
+				test.key, desc.StartKey, desc.EndKey)
+		}
+		db.assertLookupCount(t, test.lookups, string(test.key))
+	}
+}
+
 // TestRangeCacheClearOverlapping verifies that existing, overlapping
 // cached entries are cleared when adding a new entry.
 func TestRangeCacheClearOverlapping(t *testing.T) {
@@ -244,12 +286,12 @@ func TestRangeCacheClearOverlapping(t *testing.T) {
 	}
 	cache.clearOverlappingCachedRangeDescriptors(proto.Key("b"), keys.RangeMetaKey(proto.Key("b")), minToADesc)
 	cache.rangeCache.Add(rangeCacheKey(keys.RangeMetaKey(proto.Key("b"))), minToADesc)
-	if _, desc := cache.getCachedRangeDescriptor(proto.Key("b")); desc != nil {
+	if _, desc := cache.getCachedRangeDescriptor(proto.Key("b"), false); desc != nil {
 		t.Errorf("descriptor unexpectedly non-nil: %s", desc)
 	}
 	cache.clearOverlappingCachedRangeDescriptors(proto.KeyMax, keys.RangeMetaKey(proto.KeyMax), aToMaxDesc)
 	cache.rangeCache.Add(rangeCacheKey(keys.RangeMetaKey(proto.KeyMax)), aToMaxDesc)
-	if _, desc := cache.getCachedRangeDescriptor(proto.Key("b")); desc != aToMaxDesc {
+	if _, desc := cache.getCachedRangeDescriptor(proto.Key("b"), false); desc != aToMaxDesc {
 		t.Errorf("expected descriptor %s; got %s", aToMaxDesc, desc)
 	}
 
@@ -257,7 +299,7 @@ func TestRangeCacheClearOverlapping(t *testing.T) {
 	cache.clearOverlappingCachedRangeDescriptors(proto.KeyMax, keys.RangeMetaKey(proto.KeyMax), defDesc)
 	cache.rangeCache.Add(rangeCacheKey(keys.RangeMetaKey(proto.KeyMax)), defDesc)
 	for _, key := range []proto.Key{proto.Key("a"), proto.Key("b")} {
-		if _, desc := cache.getCachedRangeDescriptor(key); desc != defDesc {
+		if _, desc := cache.getCachedRangeDescriptor(key, false); desc != defDesc {
 			t.Errorf("expected descriptor %s for key %s; got %s", defDesc, key, desc)
 		}
 	}
@@ -39,6 +39,7 @@ const (
 	isWrite
 	isTxnWrite
 	isRange
+	isReverse
 )
 
 // IsAdmin returns true if the request requires admin permissions.
@@ -74,6 +75,13 @@ func IsRange(args Request) bool {
 	return (args.flags() & isRange) != 0
 }
 
+// IsReverse returns true if the operation is range-based and
+// traverses its key range in descending key order, starting from the
+// end key.
+func IsReverse(args Request) bool {
+	return (args.flags() & isReverse) != 0
+}
+
 // Request is an interface for RPC requests.
 type Request interface {
 	gogoproto.Message
@@ -142,6 +150,15 @@ func (sr *ScanResponse) Combine(c Response) {
 	}
 }
 
+// Combine implements the Combinable interface for ReverseScanResponse.
+func (sr *ReverseScanResponse) Combine(c Response) {
+	otherSR := c.(*ReverseScanResponse)
+	if sr != nil {
+		sr.Rows = append(sr.Rows, otherSR.GetRows()...)
+		sr.Header().Combine(otherSR.Header())
+	}
+}
+
 // Combine implements the Combinable interface for DeleteRangeResponse.
 func (dr *DeleteRangeResponse) Combine(c Response) {
 	otherDR := c.(*DeleteRangeResponse)
@@ -252,6 +269,16 @@ func (sr *ScanResponse) Verify(req Request) error {
 	return nil
 }
 
+// Verify verifies the integrity of every value returned in the reverse scan.
+func (sr *ReverseScanResponse) Verify(req Request) error {
+	for _, kv := range sr.Rows {
+		if err := kv.Value.Verify(kv.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Add adds a request to the batch request. The batch inherits
 // the key range of the first request added to it.
 //
@@ -297,6 +324,16 @@ func (sr *ScanRequest) SetBound(bound int64) {
 	sr.MaxResults = bound
 }
 
+// GetBound returns the MaxResults field in ReverseScanRequest.
+func (sr *ReverseScanRequest) GetBound() int64 {
+	return sr.GetMaxResults()
+}
+
+// SetBound sets the MaxResults field in ReverseScanRequest.
+func (sr *ReverseScanRequest) SetBound(bound int64) {
+	sr.MaxResults = bound
+}
+
 // Countable is implemented by response types which have a number of
 // result rows, such as Scan.
 type Countable interface {
@@ -308,6 +345,11 @@ func (sr *ScanResponse) Count() int64 {
 	return int64(len(sr.Rows))
 }
 
+// Count returns the number of rows in ReverseScanResponse.
+func (sr *ReverseScanResponse) Count() int64 {
+	return int64(len(sr.Rows))
+}
+
 // Method implements the Request interface.
 func (*GetRequest) Method() Method { return Get }
 
@@ -329,6 +371,9 @@ func (*DeleteRangeRequest) Method() Method { return DeleteRange }
 // Method implements the Request interface.
 func (*ScanRequest) Method() Method { return Scan }
 
+// Method implements the Request interface.
+func (*ReverseScanRequest) Method() Method { return ReverseScan }
+
 // Method implements the Request interface.
 func (*EndTransactionRequest) Method() Method { return EndTransaction }
 
@@ -392,6 +437,9 @@ func (*DeleteRangeRequest) CreateReply() Response { return &DeleteRangeResponse{
 // CreateReply implements the Request interface.
 func (*ScanRequest) CreateReply() Response { return &ScanResponse{} }
 
+// CreateReply implements the Request interface.
+func (*ReverseScanRequest) CreateReply() Response { return &ReverseScanResponse{} }
+
 // CreateReply implements the Request interface.
 func (*EndTransactionRequest) CreateReply() Response { return &EndTransactionResponse{} }
 
@@ -443,6 +491,7 @@ func (*IncrementRequest) flags() int                  { return isRead | isWrite
 func (*DeleteRequest) flags() int                     { return isWrite | isTxnWrite }
 func (*DeleteRangeRequest) flags() int                { return isWrite | isTxnWrite | isRange }
 func (*ScanRequest) flags() int                       { return isRead | isRange }
+func (*ReverseScanRequest) flags() int                { return isRead | isRange | isReverse }
 func (*EndTransactionRequest) flags() int             { return isWrite }
 func (*BatchRequest) flags() int                      { return isWrite }
 func (*AdminSplitRequest) flags() int                 { return isAdmin }
@@ -34,6 +34,8 @@
 		DeleteRangeResponse
 		ScanRequest
 		ScanResponse
+		ReverseScanRequest
+		ReverseScanResponse
 		EndTransactionRequest
 		EndTransactionResponse
 		RequestUnion
@@ -542,6 +544,47 @@ func (m *ScanResponse) GetRows() []KeyValue {
 	return nil
 }
 
+// A ReverseScanRequest is the argument to the ReverseScan() method. It
+// specifies the start and end keys for the scan and the maximum number of
+// results. The rows are returned in descending order of their keys, starting
+// with the key immediately preceding the end key.
+type ReverseScanRequest struct {
+	RequestHeader `protobuf:"bytes,1,opt,name=header,embedded=header" json:"header"`
+	// Must be > 0.
+	MaxResults       int64  `protobuf:"varint,2,opt,name=max_results" json:"max_results"`
+	XXX_unrecognized []byte `json:"-"`
+}
+
+func (m *ReverseScanRequest) Reset()         { *m = ReverseScanRequest{} }
+func (m *ReverseScanRequest) String() string { return proto1.CompactTextString(m) }
+func (*ReverseScanRequest) ProtoMessage()    {}
+
+func (m *ReverseScanRequest) GetMaxResults() int64 {
+	if m != nil {
+		return m.MaxResults
+	}
+	return 0
+}
+
+// A ReverseScanResponse is the return value from the ReverseScan() method.
+type ReverseScanResponse struct {
+	ResponseHeader `protobuf:"bytes,1,opt,name=header,embedded=header" json:"header"`
+	// Empty if no rows were scanned.
+	Rows             []KeyValue `protobuf:"bytes,2,rep,name=rows" json:"rows"`
+	XXX_unrecognized []byte     `json:"-"`
+}
+
+func (m *ReverseScanResponse) Reset()         { *m = ReverseScanResponse{} }
+func (m *ReverseScanResponse) String() string { return proto1.CompactTextString(m) }
+func (*ReverseScanResponse) ProtoMessage()    {}
+
+func (m *ReverseScanResponse) GetRows() []KeyValue {
+	if m != nil {
+		return m.Rows
+	}
+	return nil
+}
+
 // An EndTransactionRequest is the argument to the EndTransaction() method. It
 // specifies whether to commit or roll back an extant transaction.
 type EndTransactionRequest struct {
@@ -614,6 +657,7 @@ type RequestUnion struct {
 	DeleteRange      *DeleteRangeRequest    `protobuf:"bytes,7,opt,name=delete_range" json:"delete_range,omitempty"`
 	Scan             *ScanRequest           `protobuf:"bytes,8,opt,name=scan" json:"scan,omitempty"`
 	EndTransaction   *EndTransactionRequest `protobuf:"bytes,9,opt,name=end_transaction" json:"end_transaction,omitempty"`
+	ReverseScan      *ReverseScanRequest    `protobuf:"bytes,10,opt,name=reverse_scan" json:"reverse_scan,omitempty"`
 	XXX_unrecognized []byte                 `json:"-"`
 }
 
@@ -677,6 +721,13 @@ func (m *RequestUnion) GetEndTransaction() *EndTransactionRequest {
 	return nil
 }
 
+func (m *RequestUnion) GetReverseScan() *ReverseScanRequest {
+	if m != nil {
+		return m.ReverseScan
+	}
+	return nil
+}
+
 // A ResponseUnion contains exactly one of the optional responses.
 // Values added here must be added to InternalResponseUnion as well.
 type ResponseUnion struct {
@@ -688,6 +739,7 @@ type ResponseUnion struct {
 	DeleteRange      *DeleteRangeResponse    `protobuf:"bytes,7,opt,name=delete_range" json:"delete_range,omitempty"`
 	Scan             *ScanResponse           `protobuf:"bytes,8,opt,name=scan" json:"scan,omitempty"`
 	EndTransaction   *EndTransactionResponse `protobuf:"bytes,9,opt,name=end_transaction" json:"end_transaction,omitempty"`
+	ReverseScan      *ReverseScanResponse    `protobuf:"bytes,10,opt,name=reverse_scan" json:"reverse_scan,omitempty"`
 	XXX_unrecognized []byte                  `json:"-"`
 }
 
@@ -751,6 +803,13 @@ func (m *ResponseUnion) GetEndTransaction() *EndTransactionResponse {
 	return nil
 }
 
+func (m *ResponseUnion) GetReverseScan() *ReverseScanResponse {
+	if m != nil {
+		return m.ReverseScan
+	}
+	return nil
+}
+
 // A BatchRequest contains one or more requests to be executed in
 // parallel, or if applicable (based on write-only commands and
 // range-locality), as a single update.
@@ -2459,6 +2518,180 @@ func (m *ScanResponse) Unmarshal(data []byte) error {
 
 	return nil
 }
+func (m *ReverseScanRequest) Unmarshal(data []byte) error {
+	l := len(data)
+	index := 0
+	for index < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if index >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := data[index]
+			index++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RequestHeader", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			postIndex := index + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.RequestHeader.Unmarshal(data[index:postIndex]); err != nil {
+				return err
+			}
+			index = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxResults", wireType)
+			}
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				m.MaxResults |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			var sizeOfWire int
+			for {
+				sizeOfWire++
+				wire >>= 7
+				if wire == 0 {
+					break
+				}
+			}
+			index -= sizeOfWire
+			skippy, err := github_com_gogo_protobuf_proto.Skip(data[index:])
+			if err != nil {
+				return err
+			}
+			if (index + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, data[index:index+skippy]...)
+			index += skippy
+		}
+	}
+
+	return nil
+}
+func (m *ReverseScanResponse) Unmarshal(data []byte) error {
+	l := len(data)
+	index := 0
+	for index < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if index >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := data[index]
+			index++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ResponseHeader", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			postIndex := index + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.ResponseHeader.Unmarshal(data[index:postIndex]); err != nil {
+				return err
+			}
+			index = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Rows", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			postIndex := index + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Rows = append(m.Rows, KeyValue{})
+			if err := m.Rows[len(m.Rows)-1].Unmarshal(data[index:postIndex]); err != nil {
+				return err
+			}
+			index = postIndex
+		default:
+			var sizeOfWire int
+			for {
+				sizeOfWire++
+				wire >>= 7
+				if wire == 0 {
+					break
+				}
+			}
+			index -= sizeOfWire
+			skippy, err := github_com_gogo_protobuf_proto.Skip(data[index:])
+			if err != nil {
+				return err
+			}
+			if (index + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, data[index:index+skippy]...)
+			index += skippy
+		}
+	}
+
+	return nil
+}
 func (m *EndTransactionRequest) Unmarshal(data []byte) error {
 	l := len(data)
 	index := 0
@@ -2910,6 +3143,33 @@ func (m *RequestUnion) Unmarshal(data []byte) error {
 				return err
 			}
 			index = postIndex
+		case 10:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ReverseScan", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			postIndex := index + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.ReverseScan == nil {
+				m.ReverseScan = &ReverseScanRequest{}
+			}
+			if err := m.ReverseScan.Unmarshal(data[index:postIndex]); err != nil {
+				return err
+			}
+			index = postIndex
 		default:
 			var sizeOfWire int
 			for {
@@ -3169,6 +3429,33 @@ func (m *ResponseUnion) Unmarshal(data []byte) error {
 				return err
 			}
 			index = postIndex
+		case 10:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ReverseScan", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			postIndex := index + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.ReverseScan == nil {
+				m.ReverseScan = &ReverseScanResponse{}
+			}
+			if err := m.ReverseScan.Unmarshal(data[index:postIndex]); err != nil {
+				return err
+			}
+			index = postIndex
 		default:
 			var sizeOfWire int
 			for {
@@ -3692,6 +3979,9 @@ func (this *RequestUnion) GetValue() interface{} {
 	if this.EndTransaction != nil {
 		return this.EndTransaction
 	}
+	if this.ReverseScan != nil {
+		return this.ReverseScan
+	}
 	return nil
 }
 
@@ -3713,6 +4003,8 @@ func (this *RequestUnion) SetValue(value interface{}) bool {
 		this.Scan = vt
 	case *EndTransactionRequest:
 		this.EndTransaction = vt
+	case *ReverseScanRequest:
+		this.ReverseScan = vt
 	default:
 		return false
 	}
@@ -3743,6 +4035,9 @@ func (this *ResponseUnion) GetValue() interface{} {
 	if this.EndTransaction != nil {
 		return this.EndTransaction
 	}
+	if this.ReverseScan != nil {
+		return this.ReverseScan
+	}
 	return nil
 }
 
@@ -3764,6 +4059,8 @@ func (this *ResponseUnion) SetValue(value interface{}) bool {
 		this.Scan = vt
 	case *EndTransactionResponse:
 		this.EndTransaction = vt
+	case *ReverseScanResponse:
+		this.ReverseScan = vt
 	default:
 		return false
 	}
@@ -4010,6 +4307,35 @@ func (m *ScanResponse) Size() (n int) {
 	return n
 }
 
+func (m *ReverseScanRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = m.RequestHeader.Size()
+	n += 1 + l + sovApi(uint64(l))
+	n += 1 + sovApi(uint64(m.MaxResults))
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ReverseScanResponse) Size() (n int) {
+	var l int
+	_ = l
+	l = m.ResponseHeader.Size()
+	n += 1 + l + sovApi(uint64(l))
+	if len(m.Rows) > 0 {
+		for _, e := range m.Rows {
+			l = e.Size()
+			n += 1 + l + sovApi(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
 func (m *EndTransactionRequest) Size() (n int) {
 	var l int
 	_ = l
@@ -4079,6 +4405,10 @@ func (m *RequestUnion) Size() (n int) {
 		l = m.EndTransaction.Size()
 		n += 1 + l + sovApi(uint64(l))
 	}
+	if m.ReverseScan != nil {
+		l = m.ReverseScan.Size()
+		n += 1 + l + sovApi(uint64(l))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -4120,6 +4450,10 @@ func (m *ResponseUnion) Size() (n int) {
 		l = m.EndTransaction.Size()
 		n += 1 + l + sovApi(uint64(l))
 	}
+	if m.ReverseScan != nil {
+		l = m.ReverseScan.Size()
+		n += 1 + l + sovApi(uint64(l))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -4848,6 +5182,79 @@ func (m *ScanResponse) MarshalTo(data []byte) (n int, err error) {
 	return i, nil
 }
 
+func (m *ReverseScanRequest) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *ReverseScanRequest) MarshalTo(data []byte) (n int, err error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	data[i] = 0xa
+	i++
+	i = encodeVarintApi(data, i, uint64(m.RequestHeader.Size()))
+	n51, err := m.RequestHeader.MarshalTo(data[i:])
+	if err != nil {
+		return 0, err
+	}
+	i += n51
+	data[i] = 0x10
+	i++
+	i = encodeVarintApi(data, i, uint64(m.MaxResults))
+	if m.XXX_unrecognized != nil {
+		i += copy(data[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *ReverseScanResponse) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *ReverseScanResponse) MarshalTo(data []byte) (n int, err error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	data[i] = 0xa
+	i++
+	i = encodeVarintApi(data, i, uint64(m.ResponseHeader.Size()))
+	n52, err := m.ResponseHeader.MarshalTo(data[i:])
+	if err != nil {
+		return 0, err
+	}
+	i += n52
+	if len(m.Rows) > 0 {
+		for _, msg := range m.Rows {
+			data[i] = 0x12
+			i++
+			i = encodeVarintApi(data, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(data[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(data[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
 func (m *EndTransactionRequest) Marshal() (data []byte, err error) {
 	size := m.Size()
 	data = make([]byte, size)
@@ -5030,6 +5437,16 @@ func (m *RequestUnion) MarshalTo(data []byte) (n int, err error) {
 		}
 		i += n36
 	}
+	if m.ReverseScan != nil {
+		data[i] = 0x52
+		i++
+		i = encodeVarintApi(data, i, uint64(m.ReverseScan.Size()))
+		n53, err := m.ReverseScan.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n53
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(data[i:], m.XXX_unrecognized)
 	}
@@ -5131,6 +5548,16 @@ func (m *ResponseUnion) MarshalTo(data []byte) (n int, err error) {
 		}
 		i += n44
 	}
+	if m.ReverseScan != nil {
+		data[i] = 0x52
+		i++
+		i = encodeVarintApi(data, i, uint64(m.ReverseScan.Size()))
+		n54, err := m.ReverseScan.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n54
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(data[i:], m.XXX_unrecognized)
 	}
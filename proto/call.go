@@ -161,3 +161,18 @@ func ScanCall(key, endKey Key, maxResults int64) Call {
 		Reply: &ScanResponse{},
 	}
 }
+
+// ReverseScanCall returns a Call object initialized to scan from end to start
+// keys with max results, in descending order of the keys.
+func ReverseScanCall(key, endKey Key, maxResults int64) Call {
+	return Call{
+		Args: &ReverseScanRequest{
+			RequestHeader: RequestHeader{
+				Key:    key,
+				EndKey: endKey,
+			},
+			MaxResults: maxResults,
+		},
+		Reply: &ReverseScanResponse{},
+	}
+}
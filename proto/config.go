@@ -115,6 +115,13 @@ func (r *RangeDescriptor) ContainsKey(key []byte) bool {
 	return bytes.Compare(key, r.StartKey) >= 0 && bytes.Compare(key, r.EndKey) < 0
 }
 
+// ContainsExclusiveEndKey returns whether this RangeDescriptor contains
+// the specified key when it is used as an exclusive end key, that is,
+// whether the key is in (StartKey, EndKey].
+func (r *RangeDescriptor) ContainsExclusiveEndKey(key []byte) bool {
+	return bytes.Compare(key, r.StartKey) > 0 && bytes.Compare(key, r.EndKey) <= 0
+}
+
 // ContainsKeyRange returns whether this RangeDescriptor contains the specified
 // key range from start (inclusive) to end (exclusive).
 func (r *RangeDescriptor) ContainsKeyRange(start, end []byte) bool {
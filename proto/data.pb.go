@@ -610,7 +610,10 @@ type GCMetadata struct {
 	// The oldest unresolved write intent in nanoseconds since epoch.
 	// Null if there are no unresolved write intents.
 	OldestIntentNanos *int64 `protobuf:"varint,2,opt,name=oldest_intent_nanos" json:"oldest_intent_nanos,omitempty"`
-	XXX_unrecognized  []byte `json:"-"`
+	// The GC threshold. Versions older than the threshold may have been
+	// garbage collected, so the range rejects reads below it.
+	Threshold        Timestamp `protobuf:"bytes,3,opt,name=threshold" json:"threshold"`
+	XXX_unrecognized []byte    `json:"-"`
 }
 
 func (m *GCMetadata) Reset()         { *m = GCMetadata{} }
@@ -631,6 +634,13 @@ func (m *GCMetadata) GetOldestIntentNanos() int64 {
 	return 0
 }
 
+func (m *GCMetadata) GetThreshold() Timestamp {
+	if m != nil {
+		return m.Threshold
+	}
+	return Timestamp{}
+}
+
 func init() {
 	proto1.RegisterEnum("cockroach.proto.ReplicaChangeType", ReplicaChangeType_name, ReplicaChangeType_value)
 	proto1.RegisterEnum("cockroach.proto.IsolationType", IsolationType_name, IsolationType_value)
@@ -2173,6 +2183,30 @@ func (m *GCMetadata) Unmarshal(data []byte) error {
 				}
 			}
 			m.OldestIntentNanos = &v
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Threshold", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			postIndex := index + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.Threshold.Unmarshal(data[index:postIndex]); err != nil {
+				return err
+			}
+			index = postIndex
 		default:
 			var sizeOfWire int
 			for {
@@ -2434,6 +2468,8 @@ func (m *GCMetadata) Size() (n int) {
 	if m.OldestIntentNanos != nil {
 		n += 1 + sovData(uint64(*m.OldestIntentNanos))
 	}
+	l = m.Threshold.Size()
+	n += 1 + l + sovData(uint64(l))
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -3025,6 +3061,14 @@ func (m *GCMetadata) MarshalTo(data []byte) (n int, err error) {
 		i++
 		i = encodeVarintData(data, i, uint64(*m.OldestIntentNanos))
 	}
+	data[i] = 0x1a
+	i++
+	i = encodeVarintData(data, i, uint64(m.Threshold.Size()))
+	n19, err := m.Threshold.MarshalTo(data[i:])
+	if err != nil {
+		return 0, err
+	}
+	i += n19
 	if m.XXX_unrecognized != nil {
 		i += copy(data[i:], m.XXX_unrecognized)
 	}
@@ -109,7 +109,12 @@ type InternalRangeLookupRequest struct {
 	// be false in general, except for the case where the lookup is
 	// already in service of pushing intents on meta records. Attempting
 	// to resolve intents in this case would lead to infinite recursion.
-	IgnoreIntents    bool   `protobuf:"varint,3,opt,name=ignore_intents" json:"ignore_intents"`
+	IgnoreIntents bool `protobuf:"varint,3,opt,name=ignore_intents" json:"ignore_intents"`
+	// Inclusive indicates that the key is looked up as an inclusive end
+	// key, returning the range for which start_key < key <= end_key. This
+	// is used to address the ranges of reverse scans, which are traversed
+	// starting from their end key.
+	Inclusive        bool   `protobuf:"varint,4,opt,name=inclusive" json:"inclusive"`
 	XXX_unrecognized []byte `json:"-"`
 }
 
@@ -131,6 +136,13 @@ func (m *InternalRangeLookupRequest) GetIgnoreIntents() bool {
 	return false
 }
 
+func (m *InternalRangeLookupRequest) GetInclusive() bool {
+	if m != nil {
+		return m.Inclusive
+	}
+	return false
+}
+
 // An InternalRangeLookupResponse is the return value from the
 // InternalRangeLookup() method. It returns metadata for the range
 // containing the requested key, optionally returning the metadata for
@@ -481,6 +493,7 @@ type InternalRequestUnion struct {
 	DeleteRange                *DeleteRangeRequest                `protobuf:"bytes,7,opt,name=delete_range" json:"delete_range,omitempty"`
 	Scan                       *ScanRequest                       `protobuf:"bytes,8,opt,name=scan" json:"scan,omitempty"`
 	EndTransaction             *EndTransactionRequest             `protobuf:"bytes,9,opt,name=end_transaction" json:"end_transaction,omitempty"`
+	ReverseScan                *ReverseScanRequest                `protobuf:"bytes,10,opt,name=reverse_scan" json:"reverse_scan,omitempty"`
 	InternalPushTxn            *InternalPushTxnRequest            `protobuf:"bytes,30,opt,name=internal_push_txn" json:"internal_push_txn,omitempty"`
 	InternalResolveIntent      *InternalResolveIntentRequest      `protobuf:"bytes,31,opt,name=internal_resolve_intent" json:"internal_resolve_intent,omitempty"`
 	InternalResolveIntentRange *InternalResolveIntentRangeRequest `protobuf:"bytes,32,opt,name=internal_resolve_intent_range" json:"internal_resolve_intent_range,omitempty"`
@@ -547,6 +560,13 @@ func (m *InternalRequestUnion) GetEndTransaction() *EndTransactionRequest {
 	return nil
 }
 
+func (m *InternalRequestUnion) GetReverseScan() *ReverseScanRequest {
+	if m != nil {
+		return m.ReverseScan
+	}
+	return nil
+}
+
 func (m *InternalRequestUnion) GetInternalPushTxn() *InternalPushTxnRequest {
 	if m != nil {
 		return m.InternalPushTxn
@@ -579,6 +599,7 @@ type InternalResponseUnion struct {
 	DeleteRange                *DeleteRangeResponse                `protobuf:"bytes,7,opt,name=delete_range" json:"delete_range,omitempty"`
 	Scan                       *ScanResponse                       `protobuf:"bytes,8,opt,name=scan" json:"scan,omitempty"`
 	EndTransaction             *EndTransactionResponse             `protobuf:"bytes,9,opt,name=end_transaction" json:"end_transaction,omitempty"`
+	ReverseScan                *ReverseScanResponse                `protobuf:"bytes,10,opt,name=reverse_scan" json:"reverse_scan,omitempty"`
 	InternalPushTxn            *InternalPushTxnResponse            `protobuf:"bytes,30,opt,name=internal_push_txn" json:"internal_push_txn,omitempty"`
 	InternalResolveIntent      *InternalResolveIntentResponse      `protobuf:"bytes,31,opt,name=internal_resolve_intent" json:"internal_resolve_intent,omitempty"`
 	InternalResolveIntentRange *InternalResolveIntentRangeResponse `protobuf:"bytes,32,opt,name=internal_resolve_intent_range" json:"internal_resolve_intent_range,omitempty"`
@@ -645,6 +666,13 @@ func (m *InternalResponseUnion) GetEndTransaction() *EndTransactionResponse {
 	return nil
 }
 
+func (m *InternalResponseUnion) GetReverseScan() *ReverseScanResponse {
+	if m != nil {
+		return m.ReverseScan
+	}
+	return nil
+}
+
 func (m *InternalResponseUnion) GetInternalPushTxn() *InternalPushTxnResponse {
 	if m != nil {
 		return m.InternalPushTxn
@@ -842,6 +870,7 @@ type InternalRaftCommandUnion struct {
 	DeleteRange    *DeleteRangeRequest    `protobuf:"bytes,7,opt,name=delete_range" json:"delete_range,omitempty"`
 	Scan           *ScanRequest           `protobuf:"bytes,8,opt,name=scan" json:"scan,omitempty"`
 	EndTransaction *EndTransactionRequest `protobuf:"bytes,9,opt,name=end_transaction" json:"end_transaction,omitempty"`
+	ReverseScan    *ReverseScanRequest    `protobuf:"bytes,10,opt,name=reverse_scan" json:"reverse_scan,omitempty"`
 	// Other requests. Allow a gap in tag numbers so the previous list can
 	// be copy/pasted from RequestUnion.
 	Batch                      *BatchRequest                      `protobuf:"bytes,30,opt,name=batch" json:"batch,omitempty"`
@@ -918,6 +947,13 @@ func (m *InternalRaftCommandUnion) GetEndTransaction() *EndTransactionRequest {
 	return nil
 }
 
+func (m *InternalRaftCommandUnion) GetReverseScan() *ReverseScanRequest {
+	if m != nil {
+		return m.ReverseScan
+	}
+	return nil
+}
+
 func (m *InternalRaftCommandUnion) GetBatch() *BatchRequest {
 	if m != nil {
 		return m.Batch
@@ -1338,6 +1374,23 @@ func (m *InternalRangeLookupRequest) Unmarshal(data []byte) error {
 				}
 			}
 			m.IgnoreIntents = bool(v != 0)
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Inclusive", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Inclusive = bool(v != 0)
 		default:
 			var sizeOfWire int
 			for {
@@ -3069,6 +3122,33 @@ func (m *InternalRequestUnion) Unmarshal(data []byte) error {
 				return err
 			}
 			index = postIndex
+		case 10:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ReverseScan", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			postIndex := index + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.ReverseScan == nil {
+				m.ReverseScan = &ReverseScanRequest{}
+			}
+			if err := m.ReverseScan.Unmarshal(data[index:postIndex]); err != nil {
+				return err
+			}
+			index = postIndex
 		case 30:
 			if wireType != 2 {
 				return fmt.Errorf("proto: wrong wireType = %d for field InternalPushTxn", wireType)
@@ -3409,6 +3489,33 @@ func (m *InternalResponseUnion) Unmarshal(data []byte) error {
 				return err
 			}
 			index = postIndex
+		case 10:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ReverseScan", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			postIndex := index + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.ReverseScan == nil {
+				m.ReverseScan = &ReverseScanResponse{}
+			}
+			if err := m.ReverseScan.Unmarshal(data[index:postIndex]); err != nil {
+				return err
+			}
+			index = postIndex
 		case 30:
 			if wireType != 2 {
 				return fmt.Errorf("proto: wrong wireType = %d for field InternalPushTxn", wireType)
@@ -4354,6 +4461,33 @@ func (m *InternalRaftCommandUnion) Unmarshal(data []byte) error {
 				return err
 			}
 			index = postIndex
+		case 10:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ReverseScan", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			postIndex := index + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.ReverseScan == nil {
+				m.ReverseScan = &ReverseScanRequest{}
+			}
+			if err := m.ReverseScan.Unmarshal(data[index:postIndex]); err != nil {
+				return err
+			}
+			index = postIndex
 		case 30:
 			if wireType != 2 {
 				return fmt.Errorf("proto: wrong wireType = %d for field Batch", wireType)
@@ -5398,6 +5532,9 @@ func (this *InternalRequestUnion) GetValue() interface{} {
 	if this.EndTransaction != nil {
 		return this.EndTransaction
 	}
+	if this.ReverseScan != nil {
+		return this.ReverseScan
+	}
 	if this.InternalPushTxn != nil {
 		return this.InternalPushTxn
 	}
@@ -5428,6 +5565,8 @@ func (this *InternalRequestUnion) SetValue(value interface{}) bool {
 		this.Scan = vt
 	case *EndTransactionRequest:
 		this.EndTransaction = vt
+	case *ReverseScanRequest:
+		this.ReverseScan = vt
 	case *InternalPushTxnRequest:
 		this.InternalPushTxn = vt
 	case *InternalResolveIntentRequest:
@@ -5464,6 +5603,9 @@ func (this *InternalResponseUnion) GetValue() interface{} {
 	if this.EndTransaction != nil {
 		return this.EndTransaction
 	}
+	if this.ReverseScan != nil {
+		return this.ReverseScan
+	}
 	if this.InternalPushTxn != nil {
 		return this.InternalPushTxn
 	}
@@ -5494,6 +5636,8 @@ func (this *InternalResponseUnion) SetValue(value interface{}) bool {
 		this.Scan = vt
 	case *EndTransactionResponse:
 		this.EndTransaction = vt
+	case *ReverseScanResponse:
+		this.ReverseScan = vt
 	case *InternalPushTxnResponse:
 		this.InternalPushTxn = vt
 	case *InternalResolveIntentResponse:
@@ -5611,6 +5755,9 @@ func (this *InternalRaftCommandUnion) GetValue() interface{} {
 	if this.EndTransaction != nil {
 		return this.EndTransaction
 	}
+	if this.ReverseScan != nil {
+		return this.ReverseScan
+	}
 	if this.Batch != nil {
 		return this.Batch
 	}
@@ -5665,6 +5812,8 @@ func (this *InternalRaftCommandUnion) SetValue(value interface{}) bool {
 		this.Scan = vt
 	case *EndTransactionRequest:
 		this.EndTransaction = vt
+	case *ReverseScanRequest:
+		this.ReverseScan = vt
 	case *BatchRequest:
 		this.Batch = vt
 	case *InternalRangeLookupRequest:
@@ -5699,6 +5848,7 @@ func (m *InternalRangeLookupRequest) Size() (n int) {
 	n += 1 + l + sovInternal(uint64(l))
 	n += 1 + sovInternal(uint64(m.MaxRanges))
 	n += 2
+	n += 2
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -5971,6 +6121,10 @@ func (m *InternalRequestUnion) Size() (n int) {
 		l = m.EndTransaction.Size()
 		n += 1 + l + sovInternal(uint64(l))
 	}
+	if m.ReverseScan != nil {
+		l = m.ReverseScan.Size()
+		n += 1 + l + sovInternal(uint64(l))
+	}
 	if m.InternalPushTxn != nil {
 		l = m.InternalPushTxn.Size()
 		n += 2 + l + sovInternal(uint64(l))
@@ -6024,6 +6178,10 @@ func (m *InternalResponseUnion) Size() (n int) {
 		l = m.EndTransaction.Size()
 		n += 1 + l + sovInternal(uint64(l))
 	}
+	if m.ReverseScan != nil {
+		l = m.ReverseScan.Size()
+		n += 1 + l + sovInternal(uint64(l))
+	}
 	if m.InternalPushTxn != nil {
 		l = m.InternalPushTxn.Size()
 		n += 2 + l + sovInternal(uint64(l))
@@ -6176,6 +6334,10 @@ func (m *InternalRaftCommandUnion) Size() (n int) {
 		l = m.EndTransaction.Size()
 		n += 1 + l + sovInternal(uint64(l))
 	}
+	if m.ReverseScan != nil {
+		l = m.ReverseScan.Size()
+		n += 1 + l + sovInternal(uint64(l))
+	}
 	if m.Batch != nil {
 		l = m.Batch.Size()
 		n += 2 + l + sovInternal(uint64(l))
@@ -6389,6 +6551,14 @@ func (m *InternalRangeLookupRequest) MarshalTo(data []byte) (n int, err error) {
 		data[i] = 0
 	}
 	i++
+	data[i] = 0x20
+	i++
+	if m.Inclusive {
+		data[i] = 1
+	} else {
+		data[i] = 0
+	}
+	i++
 	if m.XXX_unrecognized != nil {
 		i += copy(data[i:], m.XXX_unrecognized)
 	}
@@ -7106,6 +7276,16 @@ func (m *InternalRequestUnion) MarshalTo(data []byte) (n int, err error) {
 		}
 		i += n33
 	}
+	if m.ReverseScan != nil {
+		data[i] = 0x52
+		i++
+		i = encodeVarintInternal(data, i, uint64(m.ReverseScan.Size()))
+		n85, err := m.ReverseScan.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n85
+	}
 	if m.InternalPushTxn != nil {
 		data[i] = 0xf2
 		i++
@@ -7243,6 +7423,16 @@ func (m *InternalResponseUnion) MarshalTo(data []byte) (n int, err error) {
 		}
 		i += n44
 	}
+	if m.ReverseScan != nil {
+		data[i] = 0x52
+		i++
+		i = encodeVarintInternal(data, i, uint64(m.ReverseScan.Size()))
+		n86, err := m.ReverseScan.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n86
+	}
 	if m.InternalPushTxn != nil {
 		data[i] = 0xf2
 		i++
@@ -7627,6 +7817,16 @@ func (m *InternalRaftCommandUnion) MarshalTo(data []byte) (n int, err error) {
 		}
 		i += n71
 	}
+	if m.ReverseScan != nil {
+		data[i] = 0x52
+		i++
+		i = encodeVarintInternal(data, i, uint64(m.ReverseScan.Size()))
+		n87, err := m.ReverseScan.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n87
+	}
 	if m.Batch != nil {
 		data[i] = 0xf2
 		i++
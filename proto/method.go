@@ -106,4 +106,8 @@ const (
 	// InternalBatch implements batch processing of commands. This is a
 	// superset of the Batch method.
 	InternalBatch
+	// ReverseScan fetches the values for all keys which fall between
+	// args.RequestHeader.Key and args.RequestHeader.EndKey, with
+	// the latter endpoint excluded, in descending order of the keys.
+	ReverseScan
 )
@@ -124,10 +124,34 @@ func AuthenticationHook(insecureMode bool, tlsState *tls.ConnectionState) (
 			return nil
 		}
 
-		// The client certificate user must either be "node", or match the requested used.
-		if certUser == NodeUser || certUser == requestedUser {
-			return nil
-		}
-		return util.Errorf("requested user is %s, but certificate is for %s", requestedUser, certUser)
+		return verifyUser(certUser, requestedUser)
 	}, nil
 }
+
+// AuthenticateUser verifies that a client connection may act as the
+// requested user using the client certificate of the connection. Must be
+// called with the TLS state of the connection. Any user is accepted in
+// insecure mode.
+func AuthenticateUser(insecureMode bool, tlsState *tls.ConnectionState, requestedUser string) error {
+	if len(requestedUser) == 0 {
+		return util.Errorf("missing user")
+	}
+	if insecureMode {
+		return nil
+	}
+	certUser, err := GetCertificateUser(tlsState)
+	if err != nil {
+		return err
+	}
+	return verifyUser(certUser, requestedUser)
+}
+
+// verifyUser checks that the client certificate user is allowed to act as
+// the requested user.
+func verifyUser(certUser, requestedUser string) error {
+	// The client certificate user must either be "node", or match the requested used.
+	if certUser == NodeUser || certUser == requestedUser {
+		return nil
+	}
+	return util.Errorf("requested user is %s, but certificate is for %s", requestedUser, certUser)
+}
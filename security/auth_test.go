@@ -139,3 +139,33 @@ func TestAuthenticationHook(t *testing.T) {
 		}
 	}
 }
+
+func TestAuthenticateUser(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	testCases := []struct {
+		insecure bool
+		tls      *tls.ConnectionState
+		user     string
+		success  bool
+	}{
+		// Insecure mode, empty user.
+		{true, nil, "", false},
+		// Insecure mode, good user.
+		{true, nil, "foo", true},
+		// Secure mode, no TLS state.
+		{false, nil, "foo", false},
+		// Secure mode, user mismatch.
+		{false, makeFakeTLSState([]string{"foo"}, []int{1}), "bar", false},
+		// Secure mode, user mismatch, but client certificate is for the node user.
+		{false, makeFakeTLSState([]string{security.NodeUser}, []int{1}), "bar", true},
+		// Secure mode, matching users.
+		{false, makeFakeTLSState([]string{"foo"}, []int{1}), "foo", true},
+	}
+
+	for tcNum, tc := range testCases {
+		err := security.AuthenticateUser(tc.insecure, tc.tls, tc.user)
+		if (err == nil) != tc.success {
+			t.Fatalf("#%d: expected success=%t, got err=%v", tcNum, tc.success, err)
+		}
+	}
+}
@@ -72,6 +72,7 @@ func init() {
 		permCmd,
 		rangeCmd,
 		zoneCmd,
+		sqlShellCmd,
 
 		// Miscellaneous commands.
 		// TODO(pmattis): stats
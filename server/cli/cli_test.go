@@ -49,8 +49,12 @@ func newCLITest() cliTest {
 }
 
 func (c cliTest) Run(line string) {
-	a := strings.Fields(line)
+	c.RunWithArgs(strings.Fields(line))
+}
 
+// RunWithArgs runs the command with the given arguments, which unlike the
+// fields of the line passed to Run may contain spaces.
+func (c cliTest) RunWithArgs(a []string) {
 	var args []string
 	args = append(args, a[0])
 	args = append(args, fmt.Sprintf("--addr=%s", c.ServingAddr()))
@@ -59,7 +63,7 @@ func (c cliTest) Run(line string) {
 	args = append(args, a[1:]...)
 
 	fmt.Fprintf(os.Stderr, "%s\n", args)
-	fmt.Printf("%s\n", line)
+	fmt.Printf("%s\n", strings.Join(a, " "))
 	if err := Run(args); err != nil {
 		fmt.Printf("%s\n", err)
 	}
@@ -225,3 +229,62 @@ func ExampleGlogFlags() {
 	// kv --verbosity=0 scan
 	// kv --vmodule=foo=1 scan
 }
+
+func ExampleSQL() {
+	c := newCLITest()
+
+	c.RunWithArgs([]string{"sql", "-e", "create database t; create table t.f (x int primary key, y text)"})
+	c.RunWithArgs([]string{"sql", "-e", "insert into t.f values (42, 'a;b'), (43, null)"})
+	c.RunWithArgs([]string{"sql", "-e", "select * from t.f"})
+	c.RunWithArgs([]string{"sql", "--format=csv", "-e", "select * from t.f"})
+	c.RunWithArgs([]string{"sql", "--format=json", "-e", "select * from t.f"})
+	c.RunWithArgs([]string{"sql", "--format=table", "-e", "select * from t.g"})
+	c.RunWithArgs([]string{"sql", "export", "t.f"})
+	c.RunWithArgs([]string{"sql", "export", "--user=node", "t.f"})
+	c.RunWithArgs([]string{"sql", "--user=node", "-e", "select * from t.f where x = 42"})
+	c.RunWithArgs([]string{"sql", "--user=root", "-e", "select * from t.f where x = 43"})
+	c.Run("quit")
+
+	// Output:
+	// sql -e create database t; create table t.f (x int primary key, y text)
+	// OK
+	// OK
+	// sql -e insert into t.f values (42, 'a;b'), (43, null)
+	// OK
+	// sql -e select * from t.f
+	// +----+------+
+	// | x  | y    |
+	// +----+------+
+	// | 42 | a;b  |
+	// | 43 | NULL |
+	// +----+------+
+	// sql --format=csv -e select * from t.f
+	// x,y
+	// 42,a;b
+	// 43,\N
+	// sql --format=json -e select * from t.f
+	// {"x": 42, "y": "a;b"}
+	// {"x": 43, "y": null}
+	// sql --format=table -e select * from t.g
+	// Error: table "t.g" does not exist
+	// sql export t.f
+	// x,y
+	// 42,a;b
+	// 43,\N
+	// sql export --user=node t.f
+	// export failed: requires the root user, not node
+	// sql --user=node -e select * from t.f where x = 42
+	// +----+-----+
+	// | x  | y   |
+	// +----+-----+
+	// | 42 | a;b |
+	// +----+-----+
+	// sql --user=root -e select * from t.f where x = 43
+	// +----+------+
+	// | x  | y    |
+	// +----+------+
+	// | 43 | NULL |
+	// +----+------+
+	// quit
+	// node drained and shutdown: ok
+}
@@ -67,6 +67,14 @@ var flagUsage = map[string]string{
 	"certs": `
         Directory containing RSA key and x509 certs. This flag is required if
         --insecure=false.
+`,
+	"execute": `
+        Execute the semicolon-separated statements and exit instead of
+        running an interactive shell.
+`,
+	"format": `
+        The output format of query results: table, csv or json. NULL is
+        written as \N in csv, as by the export command.
 `,
 	"gossip": `
         A comma-separated list of gossip addresses or resolvers for gossip
@@ -102,6 +110,9 @@ var flagUsage = map[string]string{
 `,
 	"metrics-frequency": `
         Adjust the frequency at which the server records its own internal metrics.
+`,
+	"pgaddr": `
+        The host:port to bind for PostgreSQL wire protocol traffic.
 `,
 	"scan-interval": `
         Adjusts the target for the duration of a single scan through a store's
@@ -122,6 +133,10 @@ var flagUsage = map[string]string{
         200kiops, etc.). For example:
 
           --stores=hdd:7200rpm=/mnt/hda1,ssd=/mnt/ssd01,ssd=/mnt/ssd02,mem=1073741824.
+`,
+	"user": `
+        The user to connect as. Its client certificate is read from the
+        --certs directory unless --insecure is set.
 `,
 }
 
@@ -148,6 +163,7 @@ func initFlags(ctx *server.Context) {
 	if f := startCmd.Flags(); true {
 		// Server flags.
 		f.StringVar(&ctx.Addr, "addr", ctx.Addr, flagUsage["addr"])
+		f.StringVar(&ctx.PGAddr, "pgaddr", ctx.PGAddr, flagUsage["pgaddr"])
 		f.StringVar(&ctx.Attrs, "attrs", ctx.Attrs, flagUsage["attrs"])
 		f.StringVar(&ctx.Stores, "stores", ctx.Stores, flagUsage["stores"])
 		f.DurationVar(&ctx.MaxOffset, "max-offset", ctx.MaxOffset, flagUsage["max-offset"])
@@ -189,7 +205,15 @@ func initFlags(ctx *server.Context) {
 		cmd.MarkFlagRequired("key-size")
 	}
 
-	clientCmds := []*cobra.Command{kvCmd, rangeCmd, acctCmd, permCmd, zoneCmd, quitCmd}
+	if f := sqlShellCmd.Flags(); true {
+		f.StringVarP(&sqlExecute, "execute", "e", sqlExecute, flagUsage["execute"])
+		f.StringVar(&sqlFormat, "format", sqlFormat, flagUsage["format"])
+		// The user is a persistent flag so that it applies to the import
+		// and export commands as well.
+		sqlShellCmd.PersistentFlags().StringVar(&ctx.User, "user", ctx.User, flagUsage["user"])
+	}
+
+	clientCmds := []*cobra.Command{kvCmd, rangeCmd, acctCmd, permCmd, zoneCmd, sqlShellCmd, quitCmd}
 	for _, cmd := range clientCmds {
 		f := cmd.PersistentFlags()
 		f.StringVar(&ctx.Addr, "addr", ctx.Addr, flagUsage["addr"])
@@ -0,0 +1,225 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"unicode"
+)
+
+// The control characters handled by the line editor.
+const (
+	keyCtrlA     = 1
+	keyCtrlB     = 2
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyCtrlF     = 6
+	keyCtrlH     = 8
+	keyCtrlK     = 11
+	keyCtrlN     = 14
+	keyCtrlP     = 16
+	keyCtrlU     = 21
+	keyEscape    = 27
+	keyBackspace = 127
+)
+
+// errLineInterrupted is returned by the line editor when Ctrl-C is typed.
+var errLineInterrupted = errors.New("interrupted")
+
+// A lineEditor reads the lines typed on a terminal, which can be edited
+// before they are entered and replaced by the lines of a history:
+//
+//   Left, Right, Ctrl-B, Ctrl-F  move the cursor
+//   Home, End, Ctrl-A, Ctrl-E    move the cursor to the start or end of the line
+//   Backspace, Delete, Ctrl-D    delete the character before or at the cursor
+//   Ctrl-U, Ctrl-K               delete the line before or after the cursor
+//   Up, Down, Ctrl-P, Ctrl-N     recall the previous or next line of the history
+//   Ctrl-C                       abandon the line
+//   Ctrl-D on an empty line      end the input
+type lineEditor struct {
+	in  *os.File
+	rd  *bufio.Reader
+	out io.Writer
+}
+
+// newLineEditor returns a line editor reading from the terminal, or nil if in
+// is not a terminal.
+func newLineEditor(in *os.File, out io.Writer) *lineEditor {
+	if !isTerminal(int(in.Fd())) {
+		return nil
+	}
+	return &lineEditor{in: in, rd: bufio.NewReader(in), out: out}
+}
+
+// readLine displays the prompt and reads a line with the terminal in raw
+// mode. See edit.
+func (e *lineEditor) readLine(prompt string, history []string) (string, error) {
+	restore, err := makeRaw(int(e.in.Fd()))
+	if err != nil {
+		return "", err
+	}
+	defer restore()
+	return e.edit(prompt, history)
+}
+
+// edit displays the prompt and reads a line, whose characters are echoed as
+// they are typed. The lines of the history, the most recent of which is last,
+// can be recalled in place of the line. io.EOF is returned when Ctrl-D is
+// typed on an empty line and errLineInterrupted when Ctrl-C is typed.
+func (e *lineEditor) edit(prompt string, history []string) (string, error) {
+	var line []rune
+	pos := 0
+	// The index of the line of the history being displayed, which is
+	// len(history) for the new line. The new line is saved while the lines of
+	// the history are displayed.
+	hist := len(history)
+	var saved []rune
+
+	redraw := func() {
+		fmt.Fprintf(e.out, "\r%s%s\x1b[K", prompt, string(line))
+		if n := len(line) - pos; n > 0 {
+			fmt.Fprintf(e.out, "\x1b[%dD", n)
+		}
+	}
+	recall := func(i int) {
+		if i < 0 || i > len(history) || i == hist {
+			return
+		}
+		if hist == len(history) {
+			saved = line
+		}
+		hist = i
+		if hist == len(history) {
+			line = saved
+		} else {
+			line = []rune(history[hist])
+		}
+		pos = len(line)
+	}
+
+	fmt.Fprint(e.out, prompt)
+	for {
+		r, _, err := e.rd.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		switch r {
+		case '\r', '\n':
+			fmt.Fprint(e.out, "\n")
+			return string(line), nil
+		case keyCtrlC:
+			fmt.Fprint(e.out, "^C\n")
+			return "", errLineInterrupted
+		case keyCtrlD:
+			if len(line) == 0 {
+				return "", io.EOF
+			}
+			if pos < len(line) {
+				line = append(line[:pos:pos], line[pos+1:]...)
+			}
+		case keyBackspace, keyCtrlH:
+			if pos > 0 {
+				line = append(line[:pos-1:pos-1], line[pos:]...)
+				pos--
+			}
+		case keyCtrlA:
+			pos = 0
+		case keyCtrlE:
+			pos = len(line)
+		case keyCtrlB:
+			if pos > 0 {
+				pos--
+			}
+		case keyCtrlF:
+			if pos < len(line) {
+				pos++
+			}
+		case keyCtrlU:
+			line = append([]rune(nil), line[pos:]...)
+			pos = 0
+		case keyCtrlK:
+			line = line[:pos:pos]
+		case keyCtrlP:
+			recall(hist - 1)
+		case keyCtrlN:
+			recall(hist + 1)
+		case keyEscape:
+			key, err := e.readEscape()
+			if err != nil {
+				return "", err
+			}
+			switch key {
+			case "A":
+				recall(hist - 1)
+			case "B":
+				recall(hist + 1)
+			case "C":
+				if pos < len(line) {
+					pos++
+				}
+			case "D":
+				if pos > 0 {
+					pos--
+				}
+			case "H", "1~", "7~":
+				pos = 0
+			case "F", "4~", "8~":
+				pos = len(line)
+			case "3~":
+				if pos < len(line) {
+					line = append(line[:pos:pos], line[pos+1:]...)
+				}
+			}
+		default:
+			if !unicode.IsPrint(r) {
+				continue
+			}
+			line = append(line[:pos:pos], append([]rune{r}, line[pos:]...)...)
+			pos++
+		}
+		redraw()
+	}
+}
+
+// readEscape reads the rest of an escape sequence sent by a key such as an
+// arrow key, returning its parameters and final character, e.g. "A" for the
+// sequence ESC [ A of the up arrow and "3~" for the sequence ESC [ 3 ~ of the
+// delete key. An empty string is returned for any other sequence.
+func (e *lineEditor) readEscape() (string, error) {
+	r, _, err := e.rd.ReadRune()
+	if err != nil {
+		return "", err
+	}
+	if r != '[' && r != 'O' {
+		return "", nil
+	}
+	var key []rune
+	for {
+		r, _, err := e.rd.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		key = append(key, r)
+		if (r < '0' || r > '9') && r != ';' {
+			return string(key), nil
+		}
+	}
+}
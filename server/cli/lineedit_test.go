@@ -0,0 +1,74 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLineEditor(t *testing.T) {
+	history := []string{"SELECT 1;", "SELECT 2;"}
+	testData := []struct {
+		input    string
+		expected string
+		err      error
+	}{
+		{"abc\r", "abc", nil},
+		{"abc\n", "abc", nil},
+		{"abd\x7fc\r", "abc", nil},
+		{"bc\x01a\r", "abc", nil},
+		{"ac\x1b[Db\r", "abc", nil},
+		{"ab\x1b[D\x1b[D\x1b[Cx\r", "axb", nil},
+		{"abxc\x1b[D\x1b[D\x1b[3~\r", "abc", nil},
+		{"ab\x01\x04\r", "b", nil},
+		{"ab\x1b[Hc\x1b[Fd\r", "cabd", nil},
+		{"abc\x02\x15\r", "c", nil},
+		{"abc\x01\x0b\r", "", nil},
+		{"\x1b[A\r", "SELECT 2;", nil},
+		{"\x1b[A\x1b[A\r", "SELECT 1;", nil},
+		{"\x1b[A\x1b[A\x1b[A\x1b[B\r", "SELECT 2;", nil},
+		{"new\x1b[A\x1b[B\r", "new", nil},
+		{"\x10\x7f\x7f3;\r", "SELECT 3;", nil},
+		{"\x10\x10\x0e\x0e\r", "", nil},
+		{"\x04", "", io.EOF},
+		{"ab\x03", "", errLineInterrupted},
+		{"ab", "", io.EOF},
+	}
+	for i, d := range testData {
+		var out bytes.Buffer
+		e := &lineEditor{rd: bufio.NewReader(strings.NewReader(d.input)), out: &out}
+		line, err := e.edit("> ", history)
+		if err != d.err || line != d.expected {
+			t.Errorf("%d: %q: expected %q, %v, but found %q, %v",
+				i, d.input, d.expected, d.err, line, err)
+		}
+	}
+
+	// The line is redrawn after each key, with the cursor moved back to its
+	// position within the line.
+	var out bytes.Buffer
+	e := &lineEditor{rd: bufio.NewReader(strings.NewReader("ab\x02\r")), out: &out}
+	if _, err := e.edit("> ", nil); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "> \r> a\x1b[K\r> ab\x1b[K\r> ab\x1b[K\x1b[1D\n"; out.String() != expected {
+		t.Errorf("expected %q, but found %q", expected, out.String())
+	}
+}
@@ -0,0 +1,699 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/security"
+	"github.com/cockroachdb/cockroach/sql/sqlserver"
+
+	"github.com/spf13/cobra"
+
+	// Register the cockroach database/sql driver.
+	_ "github.com/cockroachdb/cockroach/sql/driver"
+)
+
+// The output formats of the sql shell.
+const (
+	formatTable = "table"
+	formatCSV   = "csv"
+	formatJSON  = "json"
+)
+
+// sqlExecute holds the statements of the --execute flag and sqlFormat the
+// output format.
+var (
+	sqlExecute string
+	sqlFormat  = formatTable
+)
+
+// historyFile is the name of the file in the home directory in which the
+// statements entered in the interactive shell are recorded.
+const historyFile = ".cockroachdb_history"
+
+// A sqlShellCmd command runs an interactive sql shell or executes the
+// statements given with --execute.
+var sqlShellCmd = &cobra.Command{
+	Use:   "sql [options]",
+	Short: "open a sql shell",
+	Long: `
+Open a sql shell running against the cockroach database at --addr as
+--user, using the certificate of the user in --certs.
+Statements are terminated by a semicolon and may span several lines.
+Statements are read from standard input if it is not a terminal. The
+--execute flag executes the given statements and exits instead.
+
+The statements entered are recorded in ~/` + historyFile + ` and
+can be recalled with the up and down arrow keys. The line being typed can
+be edited with the left and right arrow keys, Home, End, Backspace and
+Delete, as well as Ctrl-A, Ctrl-E, Ctrl-U and Ctrl-K. Ctrl-C abandons the
+statement being typed and Ctrl-D on an empty line exits the shell.
+Type \? in the shell for the list of meta commands.
+`,
+	Run: runTerm,
+}
+
+const sqlShellHelp = `\q              exit the shell
+\?              show this help
+\l              list the databases
+\d [<table>]    list the tables, or the columns of <table>
+\di <table>     list the indexes of <table>
+\c <database>   use <database> as the current database
+\format <fmt>   set the output format: table, csv or json
+\history        list the statements entered
+`
+
+// sqlShell holds the state of a sql shell: the connection to the server, on
+// which the session state such as the current database and the transaction
+// in progress is maintained, and the history of the statements entered.
+type sqlShell struct {
+	conn    driver.Conn
+	out     io.Writer
+	format  string
+	history []string
+	histOut io.Writer
+	// The line editor reading the statements typed on a terminal, if any.
+	editor *lineEditor
+}
+
+func makeSQLConn() driver.Conn {
+	dsn := Context.RequestScheme() + "://" + Context.User + "@" + Context.Addr +
+		"?certs=" + Context.Certs
+	db, err := sql.Open("cockroach", dsn)
+	if err != nil {
+		fmt.Fprintf(osStderr, "failed to initialize SQL client: %s\n", err)
+		osExit(1)
+		return nil
+	}
+	// The shell uses a single connection so that the session state is
+	// retained across statements.
+	conn, err := db.Driver().Open(dsn)
+	if err != nil {
+		fmt.Fprintf(osStderr, "failed to initialize SQL client: %s\n", err)
+		osExit(1)
+		return nil
+	}
+	return conn
+}
+
+func runTerm(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cmd.Usage()
+		return
+	}
+	switch sqlFormat {
+	case formatTable, formatCSV, formatJSON:
+	default:
+		fmt.Fprintf(osStderr, "invalid format %q\n", sqlFormat)
+		osExit(1)
+		return
+	}
+	conn := makeSQLConn()
+	if conn == nil {
+		return
+	}
+	defer conn.Close()
+	sh := &sqlShell{conn: conn, out: os.Stdout, format: sqlFormat}
+
+	if sqlExecute != "" {
+		stmts, rest := splitStatements(sqlExecute)
+		if rest = strings.TrimSpace(rest); rest != "" {
+			stmts = append(stmts, rest)
+		}
+		for _, stmt := range stmts {
+			if err := sh.execute(stmt); err != nil {
+				fmt.Fprintf(osStderr, "Error: %s\n", err)
+				osExit(1)
+				return
+			}
+		}
+		return
+	}
+
+	interactive := false
+	if fi, err := os.Stdin.Stat(); err == nil {
+		interactive = fi.Mode()&os.ModeCharDevice != 0
+	}
+	if interactive {
+		f := sh.openHistory()
+		if f != nil {
+			defer f.Close()
+		}
+		sh.editor = newLineEditor(os.Stdin, sh.out)
+	}
+	if err := sh.run(os.Stdin, interactive); err != nil {
+		fmt.Fprintf(osStderr, "Error: %s\n", err)
+		osExit(1)
+	}
+}
+
+// A sqlImportCmd command imports the rows of a CSV file into a table.
+var sqlImportCmd = &cobra.Command{
+	Use:   "import [options] <database>.<table> [<file>]",
+	Short: "import the rows of a CSV file into a table",
+	Long: `
+Imports the rows of the CSV file, or of the standard input if no file is
+given, into an existing table. The first line of the file names the columns
+of the values; the columns which are not named are set to their defaults.
+The field \N is NULL, as is an empty field of a column which does not hold
+strings; \\N is the string \N. The rows are written in batches, each in a
+transaction of its own, and the rows written before an error are kept.
+
+The rows are written directly rather than by SQL statements, so the
+privileges on the table are not checked: the import requires the
+certificate of the root user and cannot be run as another --user.
+`,
+	Run: runImport,
+}
+
+func runImport(cmd *cobra.Command, args []string) {
+	if len(args) < 1 || len(args) > 2 {
+		cmd.Usage()
+		return
+	}
+	in := io.Reader(os.Stdin)
+	if len(args) == 2 {
+		f, err := os.Open(args[1])
+		if err != nil {
+			fmt.Fprintf(osStderr, "import failed: %s\n", err)
+			osExit(1)
+			return
+		}
+		defer f.Close()
+		in = f
+	}
+	if err := checkRootUser(); err != nil {
+		fmt.Fprintf(osStderr, "import failed: %s\n", err)
+		osExit(1)
+		return
+	}
+	kvDB := makeDBClient()
+	if kvDB == nil {
+		return
+	}
+	count, err := sqlserver.ImportCSV(kvDB, args[0], bufio.NewReader(in))
+	if err != nil {
+		fmt.Fprintf(osStderr, "import failed after %d rows: %s\n", count, err)
+		osExit(1)
+		return
+	}
+	fmt.Printf("imported %d rows\n", count)
+}
+
+// A sqlExportCmd command exports the rows of a table to a CSV file.
+var sqlExportCmd = &cobra.Command{
+	Use:   "export [options] <database>.<table> [<file>]",
+	Short: "export the rows of a table to a CSV file",
+	Long: `
+Exports the rows of the table to the CSV file, or to the standard output if
+no file is given. The first line of the file names the columns. NULL is
+written as \N and the string \N as \\N. The rows are
+a consistent snapshot of the table, read within a single transaction.
+
+The rows are read directly rather than by a SQL query, so the privileges
+on the table are not checked: the export requires the certificate of the
+root user and cannot be run as another --user.
+`,
+	Run: runExport,
+}
+
+func runExport(cmd *cobra.Command, args []string) {
+	if len(args) < 1 || len(args) > 2 {
+		cmd.Usage()
+		return
+	}
+	out := io.Writer(os.Stdout)
+	if len(args) == 2 {
+		f, err := os.Create(args[1])
+		if err != nil {
+			fmt.Fprintf(osStderr, "export failed: %s\n", err)
+			osExit(1)
+			return
+		}
+		defer f.Close()
+		out = f
+	}
+	if err := checkRootUser(); err != nil {
+		fmt.Fprintf(osStderr, "export failed: %s\n", err)
+		osExit(1)
+		return
+	}
+	kvDB := makeDBClient()
+	if kvDB == nil {
+		return
+	}
+	w := bufio.NewWriter(out)
+	_, err := sqlserver.ExportCSV(kvDB, args[0], w)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		fmt.Fprintf(osStderr, "export failed: %s\n", err)
+		osExit(1)
+	}
+}
+
+// checkRootUser returns an error unless the commands are run as the root
+// user. The import and export commands access the rows of a table with the
+// KV client of the root user, bypassing the privileges of the table, which
+// must not be done on behalf of another user.
+func checkRootUser() error {
+	if Context.User != security.RootUser {
+		return fmt.Errorf("requires the %s user, not %s", security.RootUser, Context.User)
+	}
+	return nil
+}
+
+var sqlCmds = []*cobra.Command{
+	sqlImportCmd,
+	sqlExportCmd,
+}
+
+func init() {
+	sqlShellCmd.AddCommand(sqlCmds...)
+}
+
+// run reads the statements and meta commands from the input and executes
+// them. In interactive mode a prompt is displayed and errors are reported
+// without ending the shell; otherwise the first error is returned.
+func (sh *sqlShell) run(in io.Reader, interactive bool) error {
+	prompt := Context.User + "@" + Context.Addr + "> "
+	cont := strings.Repeat(" ", len(prompt)-3) + "-> "
+
+	scanner := bufio.NewScanner(in)
+	// readLine reads the next line of the input, returning io.EOF at its end.
+	// The line is read using the line editor if the input is a terminal.
+	readLine := func(prompt string) (string, error) {
+		if sh.editor != nil {
+			return sh.editor.readLine(prompt, sh.history)
+		}
+		if interactive {
+			fmt.Fprint(sh.out, prompt)
+		}
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return "", err
+			}
+			return "", io.EOF
+		}
+		return scanner.Text(), nil
+	}
+
+	var pending string
+	for {
+		p := prompt
+		if pending != "" {
+			p = cont
+		}
+		line, err := readLine(p)
+		if err == errLineInterrupted {
+			// The statement being entered is abandoned.
+			pending = ""
+			continue
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		// Meta commands are only recognized at the start of a statement.
+		if pending == "" && strings.HasPrefix(strings.TrimSpace(line), `\`) {
+			quit, err := sh.runMeta(strings.TrimSpace(line))
+			if err != nil {
+				if !interactive {
+					return err
+				}
+				fmt.Fprintf(osStderr, "Error: %s\n", err)
+			}
+			if quit {
+				return nil
+			}
+			continue
+		}
+
+		stmts, rest := splitStatements(pending + line + "\n")
+		pending = rest
+		if strings.TrimSpace(pending) == "" {
+			pending = ""
+		}
+		for _, stmt := range stmts {
+			if interactive {
+				sh.addHistory(stmt)
+			}
+			if err := sh.execute(stmt); err != nil {
+				if !interactive {
+					return err
+				}
+				fmt.Fprintf(osStderr, "Error: %s\n", err)
+			}
+		}
+	}
+	if interactive {
+		fmt.Fprintln(sh.out)
+	}
+	// A statement at the end of the input does not require a terminating
+	// semicolon.
+	if stmt := strings.TrimSpace(pending); stmt != "" {
+		return sh.execute(stmt)
+	}
+	return nil
+}
+
+// runMeta runs a meta command, returning true if the shell should exit. The
+// meta commands which display the schema are executed as the equivalent SHOW
+// statements.
+func (sh *sqlShell) runMeta(line string) (bool, error) {
+	fields := strings.Fields(line)
+	cmd, args := fields[0], fields[1:]
+	usage := func() error {
+		return fmt.Errorf("invalid arguments to %s; type \\? for help", cmd)
+	}
+	switch cmd {
+	case `\q`:
+		return true, nil
+	case `\?`:
+		fmt.Fprint(sh.out, sqlShellHelp)
+	case `\l`:
+		if len(args) != 0 {
+			return false, usage()
+		}
+		return false, sh.execute("SHOW DATABASES")
+	case `\d`:
+		switch len(args) {
+		case 0:
+			return false, sh.execute("SHOW TABLES")
+		case 1:
+			return false, sh.execute("SHOW COLUMNS FROM " + args[0])
+		}
+		return false, usage()
+	case `\di`:
+		if len(args) != 1 {
+			return false, usage()
+		}
+		return false, sh.execute("SHOW INDEX FROM " + args[0])
+	case `\c`:
+		if len(args) != 1 {
+			return false, usage()
+		}
+		return false, sh.execute("USE " + args[0])
+	case `\format`:
+		if len(args) != 1 {
+			return false, usage()
+		}
+		switch args[0] {
+		case formatTable, formatCSV, formatJSON:
+			sh.format = args[0]
+		default:
+			return false, fmt.Errorf("invalid format %q", args[0])
+		}
+	case `\history`:
+		for i, stmt := range sh.history {
+			fmt.Fprintf(sh.out, "%5d  %s\n", i+1, stmt)
+		}
+	default:
+		return false, fmt.Errorf("unknown command %s; type \\? for help", cmd)
+	}
+	return false, nil
+}
+
+// openHistory loads the history of the statements entered in previous
+// sessions and opens the history file for appending. Failing to access the
+// history file is not an error; the history is then not recorded.
+func (sh *sqlShell) openHistory() *os.File {
+	home := os.Getenv("HOME")
+	if home == "" {
+		return nil
+	}
+	path := filepath.Join(home, historyFile)
+	if data, err := ioutil.ReadFile(path); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if line != "" {
+				sh.history = append(sh.history, line)
+			}
+		}
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil
+	}
+	sh.histOut = f
+	return f
+}
+
+// addHistory records a statement in the history. Statements spanning
+// several lines are recorded on a single line, terminated by a semicolon so
+// that a statement recalled by the line editor is executed when entered.
+func (sh *sqlShell) addHistory(stmt string) {
+	stmt = strings.Join(strings.Fields(stmt), " ") + ";"
+	sh.history = append(sh.history, stmt)
+	if sh.histOut != nil {
+		fmt.Fprintln(sh.histOut, stmt)
+	}
+}
+
+// execute executes a statement and prints its results. Statements which do
+// not return any results print "OK".
+func (sh *sqlShell) execute(stmt string) error {
+	rows, err := sh.conn.(driver.Queryer).Query(stmt, nil)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	cols := rows.Columns()
+	if len(cols) == 0 {
+		fmt.Fprintln(sh.out, "OK")
+		return nil
+	}
+	var vals [][]driver.Value
+	for {
+		row := make([]driver.Value, len(cols))
+		if err := rows.Next(row); err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		vals = append(vals, row)
+	}
+	return printQueryOutput(sh.out, sh.format, cols, vals)
+}
+
+// printQueryOutput prints the columns and rows of a result in the given
+// format. The table format prints an ASCII table, the csv format a header
+// line with the column names followed by a line per row and the json format
+// a JSON object per row keyed by the column names.
+func printQueryOutput(w io.Writer, format string, cols []string, rows [][]driver.Value) error {
+	switch format {
+	case formatTable:
+		printTable(w, cols, rows)
+		return nil
+
+	case formatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(cols); err != nil {
+			return err
+		}
+		for _, row := range rows {
+			strs := make([]string, len(row))
+			for i, v := range row {
+				// NULL and strings are written as by the export command, so
+				// that NULL is distinguished from an empty string.
+				switch t := v.(type) {
+				case nil:
+					strs[i] = sqlserver.CSVNull
+				case string:
+					strs[i] = sqlserver.EscapeCSVString(t)
+				case []byte:
+					strs[i] = sqlserver.EscapeCSVString(string(t))
+				default:
+					strs[i] = formatValue(v)
+				}
+			}
+			if err := cw.Write(strs); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+
+	case formatJSON:
+		for _, row := range rows {
+			var buf bytes.Buffer
+			buf.WriteByte('{')
+			for i, v := range row {
+				if i > 0 {
+					buf.WriteString(", ")
+				}
+				key, err := json.Marshal(cols[i])
+				if err != nil {
+					return err
+				}
+				switch t := v.(type) {
+				case nil, int64, float64, bool:
+				default:
+					v = formatValue(t)
+				}
+				val, err := json.Marshal(v)
+				if err != nil {
+					return err
+				}
+				buf.Write(key)
+				buf.WriteString(": ")
+				buf.Write(val)
+			}
+			buf.WriteString("}\n")
+			if _, err := buf.WriteTo(w); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("invalid format %q", format)
+}
+
+// printTable prints the columns and rows as an ASCII table.
+func printTable(w io.Writer, cols []string, rows [][]driver.Value) {
+	strs := make([][]string, len(rows))
+	widths := make([]int, len(cols))
+	for i, col := range cols {
+		widths[i] = len(col)
+	}
+	for i, row := range rows {
+		strs[i] = make([]string, len(row))
+		for j, v := range row {
+			strs[i][j] = formatValue(v)
+			if n := len(strs[i][j]); n > widths[j] {
+				widths[j] = n
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	sep := func() {
+		for _, width := range widths {
+			buf.WriteString("+-")
+			buf.WriteString(strings.Repeat("-", width))
+			buf.WriteByte('-')
+		}
+		buf.WriteString("+\n")
+	}
+	line := func(vals []string) {
+		for i, v := range vals {
+			fmt.Fprintf(&buf, "| %-*s ", widths[i], v)
+		}
+		buf.WriteString("|\n")
+	}
+	sep()
+	line(cols)
+	sep()
+	for _, row := range strs {
+		line(row)
+	}
+	sep()
+	_, _ = buf.WriteTo(w)
+}
+
+// formatValue returns the textual form of a value.
+func formatValue(v driver.Value) string {
+	switch t := v.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		return strconv.FormatBool(t)
+	case int64:
+		return strconv.FormatInt(t, 10)
+	case float64:
+		return strconv.FormatFloat(t, 'g', -1, 64)
+	case []byte:
+		return string(t)
+	case string:
+		return t
+	case time.Time:
+		return t.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(v)
+}
+
+// splitStatements splits the input into the statements terminated by a
+// semicolon, which are returned without the semicolon and surrounding
+// whitespace. Semicolons within quoted strings, quoted identifiers and
+// comments do not terminate a statement. The remainder of the input
+// following the last statement is returned as well.
+func splitStatements(input string) ([]string, string) {
+	var stmts []string
+	start := 0
+	for i := 0; i < len(input); i++ {
+		switch ch := input[i]; ch {
+		case '\'', '"', '`':
+			// Skip to the closing quote. The quote character is escaped by
+			// doubling it or by a backslash.
+			for i++; i < len(input); i++ {
+				if input[i] == '\\' {
+					i++
+				} else if input[i] == ch {
+					if i+1 < len(input) && input[i+1] == ch {
+						i++
+					} else {
+						break
+					}
+				}
+			}
+		case '-', '/':
+			if i+1 >= len(input) {
+				break
+			}
+			switch {
+			case input[i+1] == ch:
+				// A comment up to the end of the line.
+				if j := strings.IndexByte(input[i:], '\n'); j >= 0 {
+					i += j
+				} else {
+					i = len(input)
+				}
+			case ch == '/' && input[i+1] == '*':
+				if j := strings.Index(input[i+2:], "*/"); j >= 0 {
+					i += j + 3
+				} else {
+					i = len(input)
+				}
+			}
+		case ';':
+			if stmt := strings.TrimSpace(input[start:i]); stmt != "" {
+				stmts = append(stmts, stmt)
+			}
+			start = i + 1
+		}
+	}
+	if start > len(input) {
+		start = len(input)
+	}
+	return stmts, input[start:]
+}
@@ -0,0 +1,103 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package cli
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSplitStatements(t *testing.T) {
+	testData := []struct {
+		input    string
+		expected []string
+		rest     string
+	}{
+		{``, nil, ``},
+		{`SELECT 1`, nil, `SELECT 1`},
+		{`SELECT 1;`, []string{`SELECT 1`}, ``},
+		{"SELECT 1; SELECT 2;\n", []string{`SELECT 1`, `SELECT 2`}, "\n"},
+		{"SELECT 1;\nSELECT\n2", []string{`SELECT 1`}, "\nSELECT\n2"},
+		{`;; SELECT 1;;`, []string{`SELECT 1`}, ``},
+		{`SELECT 'a;b'; SELECT 2`, []string{`SELECT 'a;b'`}, ` SELECT 2`},
+		{`SELECT 'a'';b';`, []string{`SELECT 'a'';b'`}, ``},
+		{`SELECT 'a\';b';`, []string{`SELECT 'a\';b'`}, ``},
+		{`SELECT "a;b", ` + "`c;d`;", []string{`SELECT "a;b", ` + "`c;d`"}, ``},
+		{`SELECT 'a;`, nil, `SELECT 'a;`},
+		{"SELECT 1 -- a;b\n;", []string{"SELECT 1 -- a;b"}, ``},
+		{"SELECT 1 // a;b\n;", []string{"SELECT 1 // a;b"}, ``},
+		{`SELECT 1 /* a;b */;`, []string{`SELECT 1 /* a;b */`}, ``},
+		{`SELECT 1 /* a;b`, nil, `SELECT 1 /* a;b`},
+		{`SELECT 4-1; SELECT 4/2;`, []string{`SELECT 4-1`, `SELECT 4/2`}, ``},
+	}
+	for i, d := range testData {
+		stmts, rest := splitStatements(d.input)
+		if !reflect.DeepEqual(d.expected, stmts) || d.rest != rest {
+			t.Errorf("%d: %q: expected %q, %q, but found %q, %q",
+				i, d.input, d.expected, d.rest, stmts, rest)
+		}
+	}
+}
+
+func TestPrintQueryOutput(t *testing.T) {
+	cols := []string{"a", "bb", "c"}
+	rows := [][]driver.Value{
+		{int64(1), "hello", nil},
+		{float64(1.5), []byte("x,y"), time.Date(2015, 8, 30, 3, 34, 45, 0, time.UTC)},
+		{true, `"q"`, int64(-3)},
+		{int64(0), `\N`, nil},
+	}
+	testData := []struct {
+		format   string
+		expected string
+	}{
+		{formatTable, `+------+-------+----------------------+
+| a    | bb    | c                    |
++------+-------+----------------------+
+| 1    | hello | NULL                 |
+| 1.5  | x,y   | 2015-08-30T03:34:45Z |
+| true | "q"   | -3                   |
+| 0    | \N    | NULL                 |
++------+-------+----------------------+
+`},
+		{formatCSV, `a,bb,c
+1,hello,\N
+1.5,"x,y",2015-08-30T03:34:45Z
+true,"""q""",-3
+0,\\N,\N
+`},
+		{formatJSON, `{"a": 1, "bb": "hello", "c": null}
+{"a": 1.5, "bb": "x,y", "c": "2015-08-30T03:34:45Z"}
+{"a": true, "bb": "\"q\"", "c": -3}
+{"a": 0, "bb": "\\N", "c": null}
+`},
+	}
+	for _, d := range testData {
+		var buf bytes.Buffer
+		if err := printQueryOutput(&buf, d.format, cols, rows); err != nil {
+			t.Fatal(err)
+		}
+		if s := buf.String(); d.expected != s {
+			t.Errorf("%s: expected\n%s\nbut found\n%s", d.format, d.expected, s)
+		}
+	}
+	if err := printQueryOutput(&bytes.Buffer{}, "xml", cols, rows); err == nil {
+		t.Errorf("expected an error for an invalid format")
+	}
+}
@@ -36,6 +36,7 @@ import (
 // Context defaults.
 const (
 	defaultAddr             = ":8080"
+	defaultPGAddr           = ":15432"
 	defaultMaxOffset        = 250 * time.Millisecond
 	defaultGossipInterval   = 2 * time.Second
 	defaultCacheSize        = 1 << 30 // GB
@@ -54,6 +55,9 @@ type Context struct {
 	// Addr is the host:port to bind for HTTP/RPC traffic.
 	Addr string
 
+	// PGAddr is the host:port to bind for PostgreSQL wire protocol traffic.
+	PGAddr string
+
 	// Stores is specified to enable durable key-value storage.
 	// Memory-backed key value stores may be optionally specified
 	// via mem=<integer byte size>.
@@ -126,6 +130,7 @@ type Context struct {
 func NewContext() *Context {
 	ctx := &Context{
 		Addr:             defaultAddr,
+		PGAddr:           defaultPGAddr,
 		MaxOffset:        defaultMaxOffset,
 		GossipInterval:   defaultGossipInterval,
 		CacheSize:        defaultCacheSize,
@@ -589,6 +589,10 @@ func (n *nodeServer) Scan(args *proto.ScanRequest, reply *proto.ScanResponse) er
 	return n.executeCmd(args, reply)
 }
 
+func (n *nodeServer) ReverseScan(args *proto.ReverseScanRequest, reply *proto.ReverseScanResponse) error {
+	return n.executeCmd(args, reply)
+}
+
 func (n *nodeServer) EndTransaction(args *proto.EndTransactionRequest, reply *proto.EndTransactionResponse) error {
 	return n.executeCmd(args, reply)
 }
@@ -36,6 +36,7 @@ import (
 	"github.com/cockroachdb/cockroach/rpc"
 	"github.com/cockroachdb/cockroach/security"
 	"github.com/cockroachdb/cockroach/server/status"
+	"github.com/cockroachdb/cockroach/sql/pgwire"
 	"github.com/cockroachdb/cockroach/sql/sqlserver"
 	"github.com/cockroachdb/cockroach/sql/sqlwire"
 	"github.com/cockroachdb/cockroach/storage"
@@ -64,6 +65,7 @@ type Server struct {
 	db            *client.DB
 	kvDB          *kv.DBServer
 	sqlServer     *sqlserver.Server
+	pgServer      *pgwire.Server
 	node          *Node
 	admin         *adminServer
 	status        *statusServer
@@ -133,7 +135,8 @@ func NewServer(ctx *Context, stopper *util.Stopper) (*Server, error) {
 	}
 
 	//
-	s.sqlServer = sqlserver.NewServer(s.db)
+	s.sqlServer = sqlserver.NewServer(&s.ctx.Context, s.db)
+	s.pgServer = pgwire.NewServer(&s.ctx.Context, s.sqlServer)
 
 	// TODO(bdarnell): make StoreConfig configurable.
 	nCtx := storage.StoreContext{
@@ -186,6 +189,11 @@ func (s *Server) Start(selfBootstrap bool) error {
 	s.tsDB.PollSource(runtime, s.ctx.MetricsFrequency, ts.Resolution10s, s.stopper)
 
 	log.Infof("starting %s server at %s", s.ctx.RequestScheme(), s.rpc.Addr())
+	pgAddr := util.MakeUnresolvedAddr("tcp", s.ctx.PGAddr)
+	if err := s.pgServer.Start(pgAddr, s.stopper); err != nil {
+		return util.Errorf("could not listen on %s: %s", s.ctx.PGAddr, err)
+	}
+	log.Infof("starting postgres server at %s", s.pgServer.Addr())
 	// TODO(spencer): go1.5 is supposed to allow shutdown of running http server.
 	s.initHTTP()
 	s.rpc.Serve(s)
@@ -209,9 +217,9 @@ func (s *Server) initHTTP() {
 	// present if required.
 	// TODO(marc): we should have one, but this may come with status-page user handling.
 	s.mux.HandleFunc(ts.URLPrefix, s.authenticateRequest(s.tsServer))
-	// The SQL wire format does not currently have a requested user.
-	// TODO(marc): we need do figure out how to do sql wire auth.
-	s.mux.HandleFunc(sqlwire.Endpoint, s.authenticateRequest(s.sqlServer))
+	// SQL handles its own authentication, verifying user certificates against
+	// the requested user.
+	s.mux.Handle(sqlwire.Endpoint, s.sqlServer)
 }
 
 // authenticateRequest is a simple wrapper around a http handler.
@@ -193,6 +193,7 @@ func TestPlainHTTPServer(t *testing.T) {
 	// Create a custom context. The default one has a default --certs value.
 	ctx := NewContext()
 	ctx.Addr = "127.0.0.1:0"
+	ctx.PGAddr = "127.0.0.1:0"
 	ctx.Insecure = true
 	// TestServer.Start does not override the context if set.
 	s := &TestServer{Ctx: ctx}
@@ -492,7 +493,7 @@ func TestSQLServer(t *testing.T) {
 		// Request with garbage payload.
 		{"Execute", []byte("garbage"), http.StatusBadRequest},
 		// Valid request.
-		{"Execute", body, http.StatusNotImplemented},
+		{"Execute", body, http.StatusOK},
 	}
 	for _, test := range testCases {
 		statusCode := sendURL(t, baseURL+test.command, test.body)
@@ -65,6 +65,7 @@ func NewTestContext() *Context {
 	// Start() to an available port.
 	// Call TestServer.ServingAddr() for the full address (including bound port).
 	ctx.Addr = "127.0.0.1:0"
+	ctx.PGAddr = "127.0.0.1:0"
 	// Set standard "node" user for intra-cluster traffic.
 	ctx.User = security.NodeUser
 	return ctx
@@ -174,6 +175,12 @@ func (ts *TestServer) ServingAddr() string {
 	return ts.rpc.Addr().String()
 }
 
+// PGAddr returns the address of the server's PostgreSQL wire protocol
+// listener.
+func (ts *TestServer) PGAddr() string {
+	return ts.pgServer.Addr().String()
+}
+
 // Stop stops the TestServer.
 func (ts *TestServer) Stop() {
 	ts.Server.Stop()
@@ -20,22 +20,44 @@ package driver
 import (
 	"database/sql"
 	"database/sql/driver"
+	"net/url"
 
-	"github.com/cockroachdb/cockroach/client"
+	"github.com/cockroachdb/cockroach/base"
 )
 
 func init() {
 	sql.Register("cockroach", &roachDriver{})
 }
 
-// roachDriver implements the database/sql/driver.Driver interface. Named
-// roachDriver so as not to conflict with the "driver" package name.
 type roachDriver struct{}
 
+// Open returns a new connection to the cockroach cluster specified by dsn.
+// The cluster is identified by a URL with the format:
+//
+//   (http|https)://[<user>@]<host>:<port>[?certs=<dir>]
+//
+// If not specified, the <user> field defaults to "root". The certs parameter
+// can be used to override the default directory to use for client
+// certificates. In tests, the directory "test_certs" uses the embedded test
+// certificates.
 func (d *roachDriver) Open(dsn string) (driver.Conn, error) {
-	db, err := client.Open(dsn)
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, err
+	}
+	ctx := &base.Context{}
+	ctx.InitDefaults()
+	if u.User != nil {
+		ctx.User = u.User.Username()
+	}
+	if dir := u.Query()["certs"]; len(dir) > 0 {
+		ctx.Certs = dir[0]
+	}
+	ctx.Insecure = (u.Scheme != "https")
+
+	sender, err := newHTTPSender(u.Host, ctx)
 	if err != nil {
 		return nil, err
 	}
-	return &conn{db: db}, nil
+	return &conn{sender: sender, user: ctx.User}, nil
 }
@@ -18,11 +18,23 @@
 package driver
 
 import (
+	"bytes"
 	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io/ioutil"
+	"os"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/cockroachdb/cockroach/client"
+	"github.com/cockroachdb/cockroach/keys"
 	"github.com/cockroachdb/cockroach/server"
+	"github.com/cockroachdb/cockroach/sql/sqlserver"
+	"github.com/cockroachdb/cockroach/sql/sqlwire"
+	"github.com/cockroachdb/cockroach/structured"
 	"github.com/cockroachdb/cockroach/testutils"
 	"github.com/cockroachdb/cockroach/util/leaktest"
 )
@@ -43,6 +55,59 @@ func cleanup(s *server.TestServer, db *sql.DB) {
 	s.Stop()
 }
 
+// kvClient returns a client for inspecting the key/value data of the test
+// server.
+func kvClient(t *testing.T, s *server.TestServer) *client.DB {
+	db, err := client.Open("https://root@" + s.ServingAddr() + "?certs=test_certs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// tableDataCount returns the number of table data keys.
+func tableDataCount(t *testing.T, db *client.DB) int {
+	kvs, err := db.Scan(keys.TableDataPrefix, keys.TableDataPrefix.PrefixEnd(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(kvs)
+}
+
+// readTableDesc reads the descriptor of the table directly, returning its key
+// along with the descriptor.
+func readTableDesc(t *testing.T, db *client.DB, database, table string) ([]byte, structured.TableDescriptor) {
+	gr, err := db.Get(keys.MakeNameMetadataKey(structured.RootNamespaceID, database))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gr, err = db.Get(keys.MakeNameMetadataKey(uint32(gr.ValueInt()), table)); err != nil {
+		t.Fatal(err)
+	}
+	descKey := gr.ValueBytes()
+	desc := structured.TableDescriptor{}
+	if err := db.GetProto(descKey, &desc); err != nil {
+		t.Fatal(err)
+	}
+	return descKey, desc
+}
+
+// setColumnState sets the state of a column directly in the descriptor of the
+// table.
+func setColumnState(t *testing.T, db *client.DB, database, table, column string,
+	state structured.ColumnDescriptor_State) {
+	descKey, desc := readTableDesc(t, db, database, table)
+	for i := range desc.Columns {
+		if desc.Columns[i].Name == column {
+			desc.Columns[i].State = state
+		}
+	}
+	desc.Version++
+	if err := db.Put(descKey, &desc); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func readAll(t *testing.T, rows *sql.Rows) [][]string {
 	cols, err := rows.Columns()
 	if err != nil {
@@ -292,3 +357,2751 @@ CREATE TABLE t.users (
 		t.Fatalf("expected %s, but got %s", expectedResults, results)
 	}
 }
+
+func TestShowCreateTable(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, db := setup(t)
+	defer cleanup(s, db)
+
+	if _, err := db.Exec("CREATE DATABASE t"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Query("SHOW CREATE TABLE t.users"); !isError(err, "table .* does not exist") {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`
+CREATE TABLE t.users (
+  id    INT PRIMARY KEY,
+  name  VARCHAR NOT NULL,
+  title VARCHAR DEFAULT 'none',
+  INDEX foo (name),
+  UNIQUE INDEX bar (id, name)
+)`); err != nil {
+		t.Fatal(err)
+	}
+
+	var name, create string
+	if err := db.QueryRow("SHOW CREATE TABLE t.users").Scan(&name, &create); err != nil {
+		t.Fatal(err)
+	}
+	const expected = `CREATE TABLE t.users (
+  id INT,
+  name CHAR NOT NULL,
+  title CHAR DEFAULT 'none',
+  PRIMARY KEY (id),
+  INDEX foo (name),
+  UNIQUE INDEX bar (id, name)
+)`
+	if name != "users" || create != expected {
+		t.Fatalf("expected users and\n%s\nbut got %s and\n%s", expected, name, create)
+	}
+
+	// The statement creates an identical table.
+	if _, err := db.Exec("DROP TABLE t.users"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(create); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.QueryRow("SHOW CREATE TABLE t.users").Scan(&name, &create); err != nil {
+		t.Fatal(err)
+	} else if create != expected {
+		t.Fatalf("expected\n%s\nbut got\n%s", expected, create)
+	}
+}
+
+func TestInformationSchema(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, db := setup(t)
+	defer cleanup(s, db)
+
+	for _, stmt := range []string{
+		"CREATE DATABASE t",
+		"CREATE DATABASE u",
+		"CREATE TABLE t.kv (k CHAR PRIMARY KEY, v INT NOT NULL, INDEX foo (v, k))",
+		"CREATE TABLE u.a (x INT PRIMARY KEY)",
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testData := []struct {
+		query    string
+		expected [][]string
+	}{
+		{"SELECT database_name FROM information_schema.databases",
+			[][]string{
+				{"database_name"},
+				{"t"},
+				{"u"},
+			},
+		},
+		{"SELECT table_schema, table_name, table_type, version FROM information_schema.tables",
+			[][]string{
+				{"table_schema", "table_name", "table_type", "version"},
+				{"t", "kv", "BASE TABLE", "0"},
+				{"u", "a", "BASE TABLE", "0"},
+			},
+		},
+		{`SELECT column_name, data_type, is_nullable, ordinal_position
+FROM information_schema.columns WHERE table_schema = 't' AND table_name = 'kv'`,
+			[][]string{
+				{"column_name", "data_type", "is_nullable", "ordinal_position"},
+				{"k", "CHAR", "1", "1"},
+				{"v", "INT", "0", "2"},
+			},
+		},
+		{`SELECT i.index_name, i.seq_in_index, i.column_name, c.data_type
+FROM information_schema.indexes AS i JOIN information_schema.columns AS c
+  ON c.table_schema = i.table_schema AND c.table_name = i.table_name AND c.column_name = i.column_name
+WHERE i.table_schema = 't' AND i.is_unique = 0`,
+			[][]string{
+				{"index_name", "seq_in_index", "column_name", "data_type"},
+				{"foo", "1", "v", "INT"},
+				{"foo", "2", "k", "CHAR"},
+			},
+		},
+		{`SELECT table_schema, COUNT(*) FROM information_schema.columns
+GROUP BY table_schema ORDER BY table_schema DESC`,
+			[][]string{
+				{"table_schema", "COUNT(*)"},
+				{"u", "1"},
+				{"t", "2"},
+			},
+		},
+	}
+	for _, d := range testData {
+		rows, err := db.Query(d.query)
+		if err != nil {
+			t.Fatalf("%s: %v", d.query, err)
+		}
+		results := readAll(t, rows)
+		if !reflect.DeepEqual(d.expected, results) {
+			t.Errorf("%s: expected %s, but got %s", d.query, d.expected, results)
+		}
+	}
+
+	// A lookup of a single table only reads that table.
+	rows, err := db.Query(`EXPLAIN SELECT * FROM information_schema.columns
+WHERE table_schema = 't' AND table_name = 'kv'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := readAll(t, rows)
+	if expected := "information_schema.columns@primary: table_schema = 't' AND table_name = 'kv'"; results[1][1] != expected {
+		t.Errorf("expected %s, but got %s", expected, results[1][1])
+	}
+
+	// The information_schema database is read-only.
+	for _, stmt := range []string{
+		"INSERT INTO information_schema.databases VALUES ('x', 1)",
+		"DELETE FROM information_schema.tables",
+		"DROP TABLE information_schema.columns",
+		"CREATE DATABASE information_schema",
+	} {
+		if _, err := db.Exec(stmt); err == nil {
+			t.Errorf("%s: expected error", stmt)
+		}
+	}
+	if _, err := db.Query("SELECT * FROM information_schema.foo"); !isError(err, "table .* does not exist") {
+		t.Fatal(err)
+	}
+}
+
+func TestInsert(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, db := setup(t)
+	defer cleanup(s, db)
+
+	const schema = `
+CREATE TABLE t.kv (
+  k CHAR PRIMARY KEY,
+  v INT,
+  f FLOAT NOT NULL
+)`
+
+	if _, err := db.Exec("CREATE DATABASE t"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := db.Exec("INSERT INTO t.kv VALUES ('a', 1, 1.5), ('b', NULL, -2)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		t.Fatal(err)
+	} else if n != 2 {
+		t.Fatalf("expected 2 rows affected, but got %d", n)
+	}
+	if _, err := db.Exec("INSERT INTO t.kv (k, f) VALUES (?, ?)", "c", 3.0); err != nil {
+		t.Fatal(err)
+	}
+
+	testData := []struct {
+		query string
+		err   string
+	}{
+		{"INSERT INTO t.kv VALUES ('a', 2, 2.5)", "duplicate key value"},
+		{"INSERT INTO t.kv (v, f) VALUES (1, 1.5)", "missing \"k\" primary key column"},
+		{"INSERT INTO t.kv (k, v) VALUES ('d', 1)", "missing value for not-null column \"f\""},
+		{"INSERT INTO t.kv (k, f) VALUES ('d', NULL)", "null value in column \"f\" violates not-null constraint"},
+		{"INSERT INTO t.kv (k, f) VALUES (NULL, 1)", "null value in column \"k\" violates not-null constraint"},
+		{"INSERT INTO t.kv VALUES ('d', 'x', 1)", "value type string doesn't match type INT of column \"v\""},
+		{"INSERT INTO t.kv VALUES ('d', 1)", "INSERT has 2 values but 3 columns"},
+		{"INSERT INTO t.kv (k, z) VALUES ('d', 1)", "column \"z\" does not exist"},
+	}
+	for _, d := range testData {
+		if _, err := db.Exec(d.query); !isError(err, d.err) {
+			t.Fatalf("%s: expected %s, but got %v", d.query, d.err, err)
+		}
+	}
+}
+
+func TestSerial(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, db := setup(t)
+	defer cleanup(s, db)
+
+	const schema = `
+CREATE TABLE t.kv (
+  id SERIAL PRIMARY KEY,
+  v CHAR,
+  n INT DEFAULT UNIQUE_ROWID()
+)`
+
+	if _, err := db.Exec("CREATE DATABASE t"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every row is assigned new IDs for the columns which are not specified.
+	if _, err := db.Exec("INSERT INTO t.kv (v) VALUES ('a'), ('b')"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("INSERT INTO t.kv (id, v) VALUES (100, 'c')"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("INSERT INTO t.kv (v, n) VALUES ('d', 0)"); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := db.Query("SELECT * FROM t.kv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := readAll(t, rows)
+	expectedResults := [][]string{
+		{"id", "v", "n"},
+		{"1", "a", "2"},
+		{"3", "b", "4"},
+		{"6", "d", "0"},
+		{"100", "c", "5"},
+	}
+	if !reflect.DeepEqual(expectedResults, results) {
+		t.Fatalf("expected %s, but got %s", expectedResults, results)
+	}
+
+	rows, err = db.Query("SHOW COLUMNS FROM t.kv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	results = readAll(t, rows)
+	expectedResults = [][]string{
+		{"Field", "Type", "Null"},
+		{"id", "INT", "false"},
+		{"v", "CHAR", "true"},
+		{"n", "INT", "true"},
+	}
+	if !reflect.DeepEqual(expectedResults, results) {
+		t.Fatalf("expected %s, but got %s", expectedResults, results)
+	}
+
+	// Existing rows are assigned new IDs when a SERIAL column is added.
+	if _, err := db.Exec("ALTER TABLE t.kv ADD COLUMN m SERIAL"); err != nil {
+		t.Fatal(err)
+	}
+	rows, err = db.Query("SELECT id, m FROM t.kv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	results = readAll(t, rows)
+	expectedResults = [][]string{
+		{"id", "m"},
+		{"1", "7"},
+		{"3", "8"},
+		{"6", "9"},
+		{"100", "10"},
+	}
+	if !reflect.DeepEqual(expectedResults, results) {
+		t.Fatalf("expected %s, but got %s", expectedResults, results)
+	}
+
+	testData := []struct {
+		query string
+		err   string
+	}{
+		{"INSERT INTO t.kv (id, v) VALUES (NULL, 'e')", "null value in column \"id\" violates not-null constraint"},
+		{"INSERT INTO t.kv (id, v) VALUES (1, 'e')", "duplicate key value"},
+		{"CREATE TABLE t.a (id SERIAL DEFAULT 1)", "SERIAL column \"id\" cannot have a default"},
+		{"CREATE TABLE t.a (id CHAR DEFAULT UNIQUE_ROWID())", "requires an INT column"},
+	}
+	for _, d := range testData {
+		if _, err := db.Exec(d.query); !isError(err, d.err) {
+			t.Fatalf("%s: expected %s, but got %v", d.query, d.err, err)
+		}
+	}
+}
+
+func TestSelect(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, db := setup(t)
+	defer cleanup(s, db)
+
+	const schema = `
+CREATE TABLE t.kv (
+  k CHAR,
+  n INT,
+  v INT,
+  PRIMARY KEY (k, n)
+)`
+
+	if _, err := db.Exec("CREATE DATABASE t"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO t.kv VALUES ('a', 1, 1), ('b', 2, NULL), ('b', 1, 7), ('c', 5, 3), ('d', 0, 2)`); err != nil {
+		t.Fatal(err)
+	}
+
+	testData := []struct {
+		query    string
+		args     []interface{}
+		expected [][]string
+	}{
+		{"SELECT * FROM t.kv WHERE v IS NOT NULL", nil, [][]string{
+			{"k", "n", "v"},
+			{"a", "1", "1"},
+			{"b", "1", "7"},
+			{"c", "5", "3"},
+			{"d", "0", "2"},
+		}},
+		{"SELECT k, v + 1 AS w FROM t.kv WHERE v IS NOT NULL ORDER BY v DESC", nil, [][]string{
+			{"k", "w"},
+			{"b", "8"},
+			{"c", "4"},
+			{"d", "3"},
+			{"a", "2"},
+		}},
+		{"SELECT n FROM t.kv WHERE k = 'b'", nil, [][]string{
+			{"n"},
+			{"1"},
+			{"2"},
+		}},
+		{"SELECT k, n FROM t.kv WHERE k = ? AND n > ?", []interface{}{"b", 1}, [][]string{
+			{"k", "n"},
+			{"b", "2"},
+		}},
+		{"SELECT k FROM t.kv WHERE k >= 'b' AND k < 'd'", nil, [][]string{
+			{"k"},
+			{"b"},
+			{"b"},
+			{"c"},
+		}},
+		{"SELECT k FROM t.kv WHERE v IN (1, 3) OR k LIKE 'd%'", nil, [][]string{
+			{"k"},
+			{"a"},
+			{"c"},
+			{"d"},
+		}},
+		{"SELECT k, n FROM t.kv ORDER BY v LIMIT 2 OFFSET 1", nil, [][]string{
+			{"k", "n"},
+			{"a", "1"},
+			{"d", "0"},
+		}},
+	}
+	for _, d := range testData {
+		rows, err := db.Query(d.query, d.args...)
+		if err != nil {
+			t.Fatalf("%s: %v", d.query, err)
+		}
+		results := readAll(t, rows)
+		if !reflect.DeepEqual(d.expected, results) {
+			t.Fatalf("%s: expected %s, but got %s", d.query, d.expected, results)
+		}
+	}
+}
+
+func TestSelectIndex(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, db := setup(t)
+	defer cleanup(s, db)
+
+	const schema = `
+CREATE TABLE t.kv (
+  k CHAR PRIMARY KEY,
+  v INT,
+  w INT,
+  INDEX foo (v),
+  UNIQUE INDEX bar (w)
+)`
+
+	if _, err := db.Exec("CREATE DATABASE t"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO t.kv VALUES ('a', 1, 10), ('b', 2, NULL), ('c', 2, 30), ('d', NULL, 40), ('e', 3, 50)`); err != nil {
+		t.Fatal(err)
+	}
+	// The rows are located using the index and then looked up in the primary
+	// index.
+	if _, err := db.Exec("UPDATE t.kv SET w = 60 WHERE v = 3"); err != nil {
+		t.Fatal(err)
+	}
+
+	testData := []struct {
+		query    string
+		args     []interface{}
+		expected [][]string
+	}{
+		{"SELECT k FROM t.kv WHERE v = ?", []interface{}{2}, [][]string{
+			{"k"},
+			{"b"},
+			{"c"},
+		}},
+		{"SELECT k, w FROM t.kv WHERE v = 3", nil, [][]string{
+			{"k", "w"},
+			{"e", "60"},
+		}},
+		{"SELECT k, w FROM t.kv WHERE w = 40", nil, [][]string{
+			{"k", "w"},
+			{"d", "40"},
+		}},
+		{"SELECT k FROM t.kv WHERE v < 3 ORDER BY k DESC", nil, [][]string{
+			{"k"},
+			{"c"},
+			{"b"},
+			{"a"},
+		}},
+		{"SELECT k FROM t.kv WHERE v > 1 AND v < 1", nil, [][]string{
+			{"k"},
+		}},
+		{"EXPLAIN SELECT k FROM t.kv WHERE v = 2", nil, [][]string{
+			{"Type", "Description"},
+			{"scan", "t.kv@foo: v = 2"},
+			{"filter", "v = 2"},
+			{"select", "k"},
+		}},
+		{"EXPLAIN SELECT k, w FROM t.kv WHERE v BETWEEN 1 AND 2 ORDER BY w LIMIT 1", nil, [][]string{
+			{"Type", "Description"},
+			{"scan", "t.kv@foo: v >= 1 AND v <= 2"},
+			{"index-join", "t.kv@primary"},
+			{"filter", "v BETWEEN 1 AND 2"},
+			{"sort", "ORDER BY w ASC"},
+			{"select", "k, w"},
+			{"limit", "LIMIT 1"},
+		}},
+		{"EXPLAIN SELECT * FROM t.kv WHERE k = 'a' AND w = 10", nil, [][]string{
+			{"Type", "Description"},
+			{"scan", "t.kv@primary: k = 'a'"},
+			{"filter", "k = 'a' AND w = 10"},
+			{"select", "k, v, w"},
+		}},
+		{"EXPLAIN DELETE FROM t.kv WHERE v > 2", nil, [][]string{
+			{"Type", "Description"},
+			{"scan", "t.kv@foo: v > 2"},
+			{"index-join", "t.kv@primary"},
+			{"filter", "v > 2"},
+			{"delete", "t.kv"},
+		}},
+	}
+	for _, d := range testData {
+		rows, err := db.Query(d.query, d.args...)
+		if err != nil {
+			t.Fatalf("%s: %v", d.query, err)
+		}
+		results := readAll(t, rows)
+		if !reflect.DeepEqual(d.expected, results) {
+			t.Fatalf("%s: expected %s, but got %s", d.query, d.expected, results)
+		}
+	}
+}
+
+func TestSelectIndexNulls(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, db := setup(t)
+	defer cleanup(s, db)
+
+	for _, stmt := range []string{
+		`CREATE DATABASE t`,
+		`CREATE TABLE t.kv (k CHAR PRIMARY KEY, v INT, w INT, INDEX foo (v, w))`,
+		`CREATE TABLE t.ids (id INT PRIMARY KEY)`,
+		`INSERT INTO t.kv VALUES ('a', 1, 10), ('b', 1, NULL), ('c', 2, NULL), ('d', NULL, 20)`,
+		`INSERT INTO t.ids VALUES (1), (2)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	// The rows containing a NULL value in an indexed column are not present in
+	// the index, so it is only used if every indexed column rejects NULL.
+	testData := []struct {
+		query    string
+		expected [][]string
+	}{
+		{"SELECT k FROM t.kv WHERE v = 1", [][]string{
+			{"k"},
+			{"a"},
+			{"b"},
+		}},
+		{"SELECT k FROM t.kv WHERE v = 1 AND w IS NOT NULL", [][]string{
+			{"k"},
+			{"a"},
+		}},
+		{"SELECT i.id, kv.k FROM t.ids AS i JOIN t.kv AS kv ON kv.v = i.id", [][]string{
+			{"id", "k"},
+			{"1", "a"},
+			{"1", "b"},
+			{"2", "c"},
+		}},
+		{"EXPLAIN SELECT k FROM t.kv WHERE v = 1", [][]string{
+			{"Type", "Description"},
+			{"scan", "t.kv@primary"},
+			{"filter", "v = 1"},
+			{"select", "k"},
+		}},
+		{"EXPLAIN SELECT k FROM t.kv WHERE v = 1 AND w IS NOT NULL", [][]string{
+			{"Type", "Description"},
+			{"scan", "t.kv@foo: v = 1"},
+			{"filter", "v = 1 AND w IS NOT NULL"},
+			{"select", "k"},
+		}},
+		{"EXPLAIN SELECT i.id, kv.k FROM t.ids AS i JOIN t.kv AS kv ON kv.v = i.id", [][]string{
+			{"Type", "Description"},
+			{"scan", "t.ids@primary"},
+			{"scan", "t.kv@primary"},
+			{"hash-join", "JOIN t.kv ON kv.v = i.id"},
+			{"select", "id, k"},
+		}},
+		{"EXPLAIN SELECT i.id, kv.k FROM t.ids AS i JOIN t.kv AS kv ON kv.v = i.id AND kv.w > 0", [][]string{
+			{"Type", "Description"},
+			{"scan", "t.ids@primary"},
+			{"lookup-join", "JOIN t.kv@foo ON kv.v = i.id"},
+			{"filter", "kv.w > 0"},
+			{"select", "id, k"},
+		}},
+	}
+	for _, d := range testData {
+		rows, err := db.Query(d.query)
+		if err != nil {
+			t.Fatalf("%s: %v", d.query, err)
+		}
+		results := readAll(t, rows)
+		if !reflect.DeepEqual(d.expected, results) {
+			t.Fatalf("%s: expected %s, but got %s", d.query, d.expected, results)
+		}
+	}
+}
+
+func TestSelectGroupBy(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, db := setup(t)
+	defer cleanup(s, db)
+
+	const schema = `
+CREATE TABLE t.kv (
+  k INT PRIMARY KEY,
+  g CHAR,
+  v INT,
+  f FLOAT
+)`
+
+	if _, err := db.Exec("CREATE DATABASE t"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO t.kv VALUES (1, 'a', 1, 0.5), (2, 'b', 2, 1.5), (3, 'a', 3, NULL), (4, 'c', NULL, 2.0), (5, 'b', 2, 0.5)`); err != nil {
+		t.Fatal(err)
+	}
+
+	testData := []struct {
+		query    string
+		args     []interface{}
+		expected [][]string
+	}{
+		{"SELECT COUNT(*), COUNT(v), SUM(v), MIN(v), MAX(v), AVG(v), SUM(f) FROM t.kv", nil, [][]string{
+			{"COUNT(*)", "COUNT(v)", "SUM(v)", "MIN(v)", "MAX(v)", "AVG(v)", "SUM(f)"},
+			{"5", "4", "8", "1", "3", "2", "4.5"},
+		}},
+		{"SELECT COUNT(*) AS n FROM t.kv WHERE k > 10", nil, [][]string{
+			{"n"},
+			{"0"},
+		}},
+		{"SELECT g, COUNT(*), SUM(v) FROM t.kv WHERE k < 4 GROUP BY g", nil, [][]string{
+			{"g", "COUNT(*)", "SUM(v)"},
+			{"a", "2", "4"},
+			{"b", "1", "2"},
+		}},
+		{"SELECT g, COUNT(DISTINCT v) FROM t.kv GROUP BY g HAVING COUNT(*) > 1 ORDER BY g DESC", nil, [][]string{
+			{"g", "COUNT(DISTINCT v)"},
+			{"b", "1"},
+			{"a", "2"},
+		}},
+		{"SELECT g FROM t.kv GROUP BY g HAVING MAX(v) >= ? ORDER BY SUM(k)", []interface{}{2}, [][]string{
+			{"g"},
+			{"a"},
+			{"b"},
+		}},
+		{"SELECT k % 2, MAX(k) FROM t.kv GROUP BY k % 2", nil, [][]string{
+			{"k%2", "MAX(k)"},
+			{"1", "5"},
+			{"0", "4"},
+		}},
+		{"SELECT DISTINCT g FROM t.kv ORDER BY g", nil, [][]string{
+			{"g"},
+			{"a"},
+			{"b"},
+			{"c"},
+		}},
+		{"SELECT DISTINCT g, v FROM t.kv WHERE v IS NOT NULL LIMIT 2 OFFSET 1", nil, [][]string{
+			{"g", "v"},
+			{"b", "2"},
+			{"a", "3"},
+		}},
+		{"EXPLAIN SELECT g, COUNT(*) FROM t.kv WHERE k > 1 GROUP BY g HAVING SUM(v) > 1", nil, [][]string{
+			{"Type", "Description"},
+			{"scan", "t.kv@primary: k > 1"},
+			{"filter", "k > 1"},
+			{"group", "GROUP BY g, COUNT(*), SUM(v)"},
+			{"filter", "SUM(v) > 1"},
+			{"select", "g, COUNT(*)"},
+		}},
+	}
+	for _, d := range testData {
+		rows, err := db.Query(d.query, d.args...)
+		if err != nil {
+			t.Fatalf("%s: %v", d.query, err)
+		}
+		results := readAll(t, rows)
+		if !reflect.DeepEqual(d.expected, results) {
+			t.Fatalf("%s: expected %s, but got %s", d.query, d.expected, results)
+		}
+	}
+
+	// The sum of integers fails if it overflows.
+	if _, err := db.Exec(`INSERT INTO t.kv VALUES (6, 'd', 9223372036854775807, NULL), (7, 'd', 1, NULL)`); err != nil {
+		t.Fatal(err)
+	}
+	var avg float64
+	if err := db.QueryRow(`SELECT AVG(v) FROM t.kv WHERE g = 'd'`).Scan(&avg); err != nil {
+		t.Fatal(err)
+	} else if avg != 4611686018427387904 {
+		t.Fatalf("expected 4611686018427387904, but got %f", avg)
+	}
+
+	for _, d := range []struct {
+		query       string
+		expectedErr string
+	}{
+		{"SELECT g, v FROM t.kv GROUP BY g", `column "v" must appear in the GROUP BY clause`},
+		{"SELECT * FROM t.kv WHERE COUNT(*) > 1", "aggregate function calls are not allowed here"},
+		{"SELECT SUM(MAX(v)) FROM t.kv", "aggregate function calls cannot be nested"},
+		{"SELECT SUM(g) FROM t.kv", "SUM requires a numeric argument"},
+		{"SELECT SUM(v) FROM t.kv WHERE g = 'd'", "integer out of range"},
+	} {
+		if _, err := db.Query(d.query); !isError(err, d.expectedErr) {
+			t.Fatalf("%s: expected %q, but got %v", d.query, d.expectedErr, err)
+		}
+	}
+}
+
+func TestSelectJoin(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, db := setup(t)
+	defer cleanup(s, db)
+
+	for _, stmt := range []string{
+		`CREATE DATABASE t`,
+		`CREATE TABLE t.users (id INT PRIMARY KEY, name TEXT)`,
+		`CREATE TABLE t.orders (id INT PRIMARY KEY, user_id INT, item TEXT, INDEX byuser (user_id))`,
+		`CREATE TABLE t.prices (id INT PRIMARY KEY, item TEXT, price INT)`,
+		`INSERT INTO t.users VALUES (1, 'alice'), (2, 'bob'), (3, 'carol')`,
+		`INSERT INTO t.orders VALUES (1, 1, 'apple'), (2, 1, 'pear'), (3, 2, 'apple'), (4, 4, 'fig')`,
+		`INSERT INTO t.prices VALUES (1, 'apple', 3), (2, 'pear', 5), (3, 'fig', 2)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	testData := []struct {
+		query    string
+		expected [][]string
+	}{
+		{"SELECT u.name, o.item FROM t.users AS u JOIN t.orders AS o ON u.id = o.user_id", [][]string{
+			{"name", "item"},
+			{"alice", "apple"},
+			{"alice", "pear"},
+			{"bob", "apple"},
+		}},
+		{"SELECT u.name, o.item FROM t.users AS u JOIN t.orders AS o ON u.id = o.user_id LIMIT 1 OFFSET 1", [][]string{
+			{"name", "item"},
+			{"alice", "pear"},
+		}},
+		{"SELECT u.name, COUNT(o.id) FROM t.users AS u LEFT JOIN t.orders AS o ON u.id = o.user_id GROUP BY u.name", [][]string{
+			{"name", "COUNT(o.id)"},
+			{"alice", "2"},
+			{"bob", "1"},
+			{"carol", "0"},
+		}},
+		{"SELECT u.name FROM t.users AS u LEFT JOIN t.orders AS o ON u.id = o.user_id WHERE o.id IS NULL", [][]string{
+			{"name"},
+			{"carol"},
+		}},
+		{"SELECT o.id, p.price FROM t.orders AS o JOIN t.prices AS p USING (item) ORDER BY o.id DESC", [][]string{
+			{"id", "price"},
+			{"4", "2"},
+			{"3", "3"},
+			{"2", "5"},
+			{"1", "3"},
+		}},
+		{"SELECT * FROM t.users, t.prices WHERE users.id = prices.id AND price > 2", [][]string{
+			{"id", "name", "id", "item", "price"},
+			{"1", "alice", "1", "apple", "3"},
+			{"2", "bob", "2", "pear", "5"},
+		}},
+		{"SELECT a.id, b.id FROM t.users AS a JOIN t.users AS b ON a.id < b.id", [][]string{
+			{"id", "id"},
+			{"1", "2"},
+			{"1", "3"},
+			{"2", "3"},
+		}},
+		{"SELECT u.name, p.price FROM t.users AS u JOIN t.orders AS o ON o.user_id = u.id JOIN t.prices AS p ON p.item = o.item WHERE u.name = 'alice'", [][]string{
+			{"name", "price"},
+			{"alice", "3"},
+			{"alice", "5"},
+		}},
+		{"EXPLAIN SELECT u.name, p.price FROM t.users AS u JOIN t.orders AS o ON o.user_id = u.id JOIN t.prices AS p ON p.item = o.item WHERE u.name = 'alice'", [][]string{
+			{"Type", "Description"},
+			{"scan", "t.users@primary"},
+			{"filter", "u.name = 'alice'"},
+			{"lookup-join", "JOIN t.orders@byuser ON o.user_id = u.id"},
+			{"scan", "t.prices@primary"},
+			{"hash-join", "JOIN t.prices ON p.item = o.item"},
+			{"select", "name, price"},
+		}},
+		{"EXPLAIN SELECT a.id, b.id FROM t.users AS a LEFT JOIN t.users AS b ON a.id < b.id AND b.name <> 'bob'", [][]string{
+			{"Type", "Description"},
+			{"scan", "t.users@primary"},
+			{"scan", "t.users@primary"},
+			{"filter", "b.name != 'bob'"},
+			{"nested-loop-join", "LEFT JOIN t.users ON a.id < b.id"},
+			{"select", "id, id"},
+		}},
+	}
+	for _, d := range testData {
+		rows, err := db.Query(d.query)
+		if err != nil {
+			t.Fatalf("%s: %v", d.query, err)
+		}
+		results := readAll(t, rows)
+		if !reflect.DeepEqual(d.expected, results) {
+			t.Fatalf("%s: expected %s, but got %s", d.query, d.expected, results)
+		}
+	}
+
+	for _, d := range []struct {
+		query       string
+		expectedErr string
+	}{
+		{"SELECT id FROM t.users, t.orders", `column reference "id" is ambiguous`},
+		{"SELECT * FROM t.users, t.users", `table name "users" specified more than once`},
+		{"SELECT * FROM t.users JOIN t.orders USING (name)", `column "name" specified in USING clause does not exist in right table`},
+		{"SELECT * FROM t.users RIGHT JOIN t.orders ON users.id = orders.user_id", "unsupported JOIN"},
+	} {
+		if _, err := db.Query(d.query); !isError(err, d.expectedErr) {
+			t.Fatalf("%s: expected %q, but got %v", d.query, d.expectedErr, err)
+		}
+	}
+}
+
+func TestSelectSubquery(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, db := setup(t)
+	defer cleanup(s, db)
+
+	for _, stmt := range []string{
+		`CREATE DATABASE t`,
+		`CREATE TABLE t.users (id INT PRIMARY KEY, name TEXT)`,
+		`CREATE TABLE t.orders (id INT PRIMARY KEY, user_id INT, item TEXT)`,
+		`CREATE TABLE t.prices (item TEXT PRIMARY KEY, price INT)`,
+		`INSERT INTO t.users VALUES (1, 'alice'), (2, 'bob'), (3, 'carol')`,
+		`INSERT INTO t.orders VALUES (1, 1, 'apple'), (2, 1, 'pear'), (3, 2, 'apple'), (4, 4, 'fig')`,
+		`INSERT INTO t.prices VALUES ('apple', 3), ('pear', 5), ('fig', 2)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	testData := []struct {
+		query    string
+		expected [][]string
+	}{
+		{"SELECT name FROM t.users WHERE id = (SELECT MAX(user_id) FROM t.orders WHERE item = 'apple')", [][]string{
+			{"name"},
+			{"bob"},
+		}},
+		{"SELECT name FROM t.users WHERE id IN (SELECT user_id FROM t.orders)", [][]string{
+			{"name"},
+			{"alice"},
+			{"bob"},
+		}},
+		{"SELECT name FROM t.users WHERE id NOT IN (SELECT user_id FROM t.orders)", [][]string{
+			{"name"},
+			{"carol"},
+		}},
+		{"SELECT name FROM t.users AS u WHERE EXISTS (SELECT id FROM t.orders AS o WHERE o.user_id = u.id AND o.item = 'pear')", [][]string{
+			{"name"},
+			{"alice"},
+		}},
+		{"SELECT name, (SELECT COUNT(*) FROM t.orders WHERE user_id = users.id) AS n FROM t.users", [][]string{
+			{"name", "n"},
+			{"alice", "2"},
+			{"bob", "1"},
+			{"carol", "0"},
+		}},
+		{"SELECT name FROM t.users AS u WHERE (SELECT COUNT(*) FROM t.orders AS o WHERE o.user_id = u.id AND o.item IN (SELECT item FROM t.prices WHERE price > 2)) = 2", [][]string{
+			{"name"},
+			{"alice"},
+		}},
+		{"SELECT item FROM t.orders UNION SELECT name FROM t.users", [][]string{
+			{"item"},
+			{"apple"},
+			{"pear"},
+			{"fig"},
+			{"alice"},
+			{"bob"},
+			{"carol"},
+		}},
+		{"SELECT id FROM t.users UNION ALL SELECT user_id FROM t.orders WHERE id > 2", [][]string{
+			{"id"},
+			{"1"},
+			{"2"},
+			{"3"},
+			{"2"},
+			{"4"},
+		}},
+		{"SELECT id FROM t.users INTERSECT SELECT user_id FROM t.orders", [][]string{
+			{"id"},
+			{"1"},
+			{"2"},
+		}},
+		{"SELECT id FROM t.users EXCEPT SELECT user_id FROM t.orders", [][]string{
+			{"id"},
+			{"3"},
+		}},
+		{"EXPLAIN SELECT id FROM t.users UNION SELECT user_id FROM t.orders", [][]string{
+			{"Type", "Description"},
+			{"scan", "t.users@primary"},
+			{"select", "id"},
+			{"scan", "t.orders@primary"},
+			{"select", "user_id"},
+			{"union", "UNION"},
+		}},
+	}
+	for _, d := range testData {
+		rows, err := db.Query(d.query)
+		if err != nil {
+			t.Fatalf("%s: %v", d.query, err)
+		}
+		results := readAll(t, rows)
+		if !reflect.DeepEqual(d.expected, results) {
+			t.Fatalf("%s: expected %s, but got %s", d.query, d.expected, results)
+		}
+	}
+
+	for _, d := range []struct {
+		query       string
+		expectedErr string
+	}{
+		{"SELECT name FROM t.users WHERE id = (SELECT id FROM t.users)", "more than one row returned by a subquery"},
+		{"SELECT name FROM t.users WHERE id IN (SELECT id, name FROM t.users)", "subquery must return only one column"},
+		{"SELECT id FROM t.users UNION SELECT id, name FROM t.users", "each UNION query must have the same number of columns"},
+	} {
+		if _, err := db.Query(d.query); !isError(err, d.expectedErr) {
+			t.Fatalf("%s: expected %q, but got %v", d.query, d.expectedErr, err)
+		}
+	}
+}
+
+func TestExpressions(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, db := setup(t)
+	defer cleanup(s, db)
+
+	for _, stmt := range []string{
+		`CREATE DATABASE t`,
+		`CREATE TABLE t.kv (k INT PRIMARY KEY, v TEXT, f FLOAT, d DATE)`,
+		`INSERT INTO t.kv VALUES (1, ' Apple ', 1.25, '1970-01-01'), (2, 'pear', -2.5, '1970-01-02'), (3, NULL, NULL, NULL)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	// Builtin functions are shared by UPDATE, SELECT and WHERE.
+	if _, err := db.Exec(`UPDATE t.kv SET v = UPPER(TRIM(v)) WHERE LENGTH(v) > ?`, 4); err != nil {
+		t.Fatal(err)
+	}
+
+	testData := []struct {
+		query    string
+		args     []interface{}
+		expected [][]string
+	}{
+		{"SELECT k, v, LOWER(v), LENGTH(v) FROM t.kv WHERE v IS NOT NULL", nil, [][]string{
+			{"k", "v", "LOWER(v)", "LENGTH(v)"},
+			{"1", "APPLE", "apple", "5"},
+			{"2", "pear", "pear", "4"},
+		}},
+		{"SELECT k, ABS(f), ROUND(f), CEIL(f), MOD(k, 2) FROM t.kv WHERE f IS NOT NULL", nil, [][]string{
+			{"k", "ABS(f)", "ROUND(f)", "CEIL(f)", "MOD(k, 2)"},
+			{"1", "1.25", "1", "2", "1"},
+			{"2", "2.5", "-3", "-2", "0"},
+		}},
+		{"SELECT k, COALESCE(v, 'none'), CONCAT(k, '-', SUBSTR(COALESCE(v, 'none'), 2, 2)) FROM t.kv", nil, [][]string{
+			{"k", "COALESCE(v, 'none')", "CONCAT(k, '-', SUBSTR(COALESCE(v, 'none'), 2, 2))"},
+			{"1", "APPLE", "1-PP"},
+			{"2", "pear", "2-ea"},
+			{"3", "none", "3-on"},
+		}},
+		{"SELECT k, YEAR(d), DAY(d) FROM t.kv WHERE d IS NOT NULL", nil, [][]string{
+			{"k", "YEAR(d)", "DAY(d)"},
+			{"1", "1970", "1"},
+			{"2", "1970", "2"},
+		}},
+		{"SELECT k, CASE WHEN f > 0 THEN 'pos' WHEN f < 0 THEN 'neg' ELSE 'unknown' END AS sign FROM t.kv", nil, [][]string{
+			{"k", "sign"},
+			{"1", "pos"},
+			{"2", "neg"},
+			{"3", "unknown"},
+		}},
+		// NULL comparisons are neither true nor false.
+		{"SELECT k FROM t.kv WHERE NOT (f > 0)", nil, [][]string{
+			{"k"},
+			{"2"},
+		}},
+		{"SELECT k FROM t.kv WHERE f > 0 OR v = 'pear'", nil, [][]string{
+			{"k"},
+			{"1"},
+			{"2"},
+		}},
+		{"SELECT k FROM t.kv WHERE k > ? - 1 AND UPPER(v) = ?", []interface{}{2, "PEAR"}, [][]string{
+			{"k"},
+			{"2"},
+		}},
+		// The constant sub-expressions of the WHERE clause are folded.
+		{"EXPLAIN SELECT k FROM t.kv WHERE k = 1 + 1 AND v = CONCAT('pe', 'ar')", nil, [][]string{
+			{"Type", "Description"},
+			{"scan", "t.kv@primary: k = 2"},
+			{"filter", "k = 2 AND v = 'pear'"},
+			{"select", "k"},
+		}},
+	}
+	for _, d := range testData {
+		rows, err := db.Query(d.query, d.args...)
+		if err != nil {
+			t.Fatalf("%s: %v", d.query, err)
+		}
+		results := readAll(t, rows)
+		if !reflect.DeepEqual(d.expected, results) {
+			t.Fatalf("%s: expected %s, but got %s", d.query, d.expected, results)
+		}
+	}
+
+	// Parenthesized expressions are evaluated by UPDATE, SELECT and WHERE.
+	if _, err := db.Exec(`UPDATE t.kv SET k = (k + 1) * 10 WHERE -(k) <= -(1 + 1)`); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.Query(`SELECT k, (k - 1) * 2, -(k) FROM t.kv WHERE (k + 1) * 2 > 60`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := readAll(t, rows)
+	expected := [][]string{
+		{"k", "(k-1)*2", "-(k)"},
+		{"30", "58", "-30"},
+		{"40", "78", "-40"},
+	}
+	if !reflect.DeepEqual(expected, results) {
+		t.Fatalf("expected %s, but got %s", expected, results)
+	}
+
+	// Type errors are reported even if there are no rows.
+	for _, d := range []struct {
+		query       string
+		expectedErr string
+	}{
+		{"SELECT k FROM t.kv WHERE k = 'a' AND k > 3", "cannot compare int and string"},
+		{"SELECT v + 1 FROM t.kv WHERE k > 3", `unsupported binary operator: string \+ int`},
+		{"SELECT LENGTH(k) FROM t.kv WHERE k > 3", "argument 1 of LENGTH must be of type string, but found int"},
+		{"SELECT SUBSTR(v) FROM t.kv", "wrong number of arguments to SUBSTR"},
+		{"SELECT FOO(k) FROM t.kv", "unknown function: FOO"},
+		{"UPDATE t.kv SET k = v WHERE k > 3", "value type string doesn't match type INT of column \"k\""},
+		{"DELETE FROM t.kv WHERE f = 'a'", "cannot compare float and string"},
+	} {
+		if _, err := db.Exec(d.query); !isError(err, d.expectedErr) {
+			t.Fatalf("%s: expected %q, but got %v", d.query, d.expectedErr, err)
+		}
+	}
+}
+
+func TestDateTime(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, db := setup(t)
+	defer cleanup(s, db)
+
+	for _, stmt := range []string{
+		`CREATE DATABASE t`,
+		`CREATE TABLE t.events (ts TIMESTAMP PRIMARY KEY, d DATE, tod TIME, INDEX byday (d))`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	// Times are stored in UTC with nanosecond precision. Strings are parsed
+	// when stored in a date or time column.
+	ts := time.Date(2015, 6, 1, 14, 30, 15, 123456789, time.FixedZone("", -7200))
+	if _, err := db.Exec(`INSERT INTO t.events VALUES (?, ?, ?), ('1969-07-20 20:17:40', '1969-07-20', '20:17:40')`,
+		ts, ts, ts); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO t.events VALUES ('yesterday', NULL, NULL)`); !isError(err,
+		`value "yesterday" is not valid for column "ts" of type TIMESTAMP`) {
+		t.Fatalf("expected error, but got %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO t.events VALUES (1, NULL, NULL)`); !isError(err,
+		`value type int64 doesn't match type TIMESTAMP of column "ts"`) {
+		t.Fatalf("expected error, but got %v", err)
+	}
+
+	var got, gotDate, gotTime time.Time
+	if err := db.QueryRow(`SELECT ts, d, tod FROM t.events WHERE ts > '1970-01-01'`).Scan(
+		&got, &gotDate, &gotTime); err != nil {
+		t.Fatal(err)
+	}
+	if expected := ts.UTC(); !got.Equal(expected) || got.Location() != time.UTC {
+		t.Errorf("expected %s, but got %s", expected, got)
+	}
+	if expected := time.Date(2015, 6, 1, 0, 0, 0, 0, time.UTC); !gotDate.Equal(expected) {
+		t.Errorf("expected %s, but got %s", expected, gotDate)
+	}
+	if expected := time.Date(0, 1, 1, 16, 30, 15, 123456789, time.UTC); !gotTime.Equal(expected) {
+		t.Errorf("expected %s, but got %s", expected, gotTime)
+	}
+
+	testData := []struct {
+		query    string
+		expected [][]string
+	}{
+		// Times before the unix epoch sort before later times.
+		{"SELECT ts, YEAR(d) FROM t.events", [][]string{
+			{"ts", "YEAR(d)"},
+			{"1969-07-20T20:17:40Z", "1969"},
+			{"2015-06-01T16:30:15.123456789Z", "2015"},
+		}},
+		{"SELECT d FROM t.events WHERE d >= '1970-01-01' AND d < NOW()", [][]string{
+			{"d"},
+			{"2015-06-01T00:00:00Z"},
+		}},
+		// The time of day is not a valid bound for a scan of a DATE column.
+		{"EXPLAIN SELECT d FROM t.events WHERE d < '2015-06-01 12:00'", [][]string{
+			{"Type", "Description"},
+			{"scan", "t.events@primary"},
+			{"filter", "d < '2015-06-01 12:00'"},
+			{"select", "d"},
+		}},
+		{"EXPLAIN SELECT d FROM t.events WHERE d >= '2015-06-01'", [][]string{
+			{"Type", "Description"},
+			{"scan", "t.events@byday: d >= '2015-06-01 00:00:00'"},
+			{"filter", "d >= '2015-06-01'"},
+			{"select", "d"},
+		}},
+	}
+	for _, d := range testData {
+		rows, err := db.Query(d.query)
+		if err != nil {
+			t.Fatalf("%s: %v", d.query, err)
+		}
+		results := readAll(t, rows)
+		if !reflect.DeepEqual(d.expected, results) {
+			t.Fatalf("%s: expected %s, but got %s", d.query, d.expected, results)
+		}
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, db := setup(t)
+	defer cleanup(s, db)
+
+	for _, stmt := range []string{
+		`CREATE DATABASE t`,
+		`CREATE TABLE t.prices (p DECIMAL(10,2) PRIMARY KEY, q DECIMAL)`,
+		// Values are rounded to the precision of the column: 1.005 is 1.01,
+		// whereas the nearest float is below 1.005.
+		`INSERT INTO t.prices VALUES ('0.10', 1), (1.005, 0.1), (-3, '123456789012345678901234567890.123456789')`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	if _, err := db.Exec(`INSERT INTO t.prices VALUES (123456789, 0)`); !isError(err,
+		`value 123456789.00 is out of range for column "p" of type DECIMAL\(10,2\)`) {
+		t.Fatalf("expected error, but got %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO t.prices VALUES ('abc', 0)`); !isError(err,
+		`value abc is not valid for column "p" of type DECIMAL\(10,2\)`) {
+		t.Fatalf("expected error, but got %v", err)
+	}
+
+	testData := []struct {
+		query    string
+		expected [][]string
+	}{
+		// Decimals are ordered by value and keep the scale of the column.
+		{"SELECT p, q FROM t.prices", [][]string{
+			{"p", "q"},
+			{"-3.00", "123456789012345678901234567890.123456789"},
+			{"0.10", "1"},
+			{"1.01", "0.1"},
+		}},
+		// Arithmetic is exact.
+		{"SELECT p * 3 AS x, q + 1 AS y FROM t.prices WHERE p < 0", [][]string{
+			{"x", "y"},
+			{"-9.00", "123456789012345678901234567891.123456789"},
+		}},
+		{"SELECT q FROM t.prices WHERE p = 0.1", [][]string{
+			{"q"},
+			{"1"},
+		}},
+		{"SELECT SUM(p) AS x, SUM(q) AS y FROM t.prices WHERE p > 0", [][]string{
+			{"x", "y"},
+			{"1.11", "1.1"},
+		}},
+	}
+	for _, d := range testData {
+		rows, err := db.Query(d.query)
+		if err != nil {
+			t.Fatalf("%s: %v", d.query, err)
+		}
+		results := readAll(t, rows)
+		if !reflect.DeepEqual(d.expected, results) {
+			t.Fatalf("%s: expected %s, but got %s", d.query, d.expected, results)
+		}
+	}
+}
+
+func TestChecksums(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, db := setup(t)
+	defer cleanup(s, db)
+
+	if _, err := db.Exec("CREATE DATABASE t"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("CREATE TABLE t.kv (k CHAR PRIMARY KEY, v INT)"); err != nil {
+		t.Fatal(err)
+	}
+	// Parameters are checksummed by the driver and results are verified.
+	if _, err := db.Exec("INSERT INTO t.kv VALUES (?, ?)", "a", 1); err != nil {
+		t.Fatal(err)
+	}
+	var v int64
+	if err := db.QueryRow("SELECT v FROM t.kv WHERE k = ?", "a").Scan(&v); err != nil {
+		t.Fatal(err)
+	} else if v != 1 {
+		t.Fatalf("expected 1, but found %d", v)
+	}
+
+	// A parameter corrupted after its checksum was computed is rejected by
+	// the server and a result corrupted after its checksum was computed is
+	// rejected by the driver.
+	cdb, err := sql.Open("cockroach-corrupt", "https://root@"+s.ServingAddr()+"?certs=test_certs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cdb.Close()
+	if _, err := cdb.Exec("INSERT INTO t.kv VALUES ('b', ?)", 2); err == nil {
+		t.Fatal("expected checksum error")
+	} else if cErr, ok := err.(*sqlwire.ChecksumError); !ok {
+		t.Fatalf("expected checksum error, but found %v", err)
+	} else if cErr.Datum.GetIval() != 3 || cErr.Actual == cErr.Expected {
+		t.Fatalf("unexpected checksum error %+v", cErr)
+	}
+	if err := db.QueryRow("SELECT v FROM t.kv WHERE k = 'b'").Scan(&v); err != sql.ErrNoRows {
+		t.Fatalf("expected no rows, but found %v", err)
+	}
+	if err := cdb.QueryRow("SELECT v FROM t.kv WHERE k = 'a'").Scan(&v); err == nil {
+		t.Fatal("expected checksum error")
+	} else if _, ok := err.(*sqlwire.ChecksumError); !ok {
+		t.Fatalf("expected checksum error, but found %v", err)
+	}
+}
+
+func init() {
+	sql.Register("cockroach-corrupt", corruptDriver{})
+}
+
+// corruptDriver opens connections which increment the integer parameters of
+// every request after their checksums were computed and the integer values
+// of every result after their checksums were computed.
+type corruptDriver struct {
+	roachDriver
+}
+
+func (d corruptDriver) Open(dsn string) (driver.Conn, error) {
+	c, err := d.roachDriver.Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+	c.(*conn).sender = corruptSender{c.(*conn).sender}
+	return c, nil
+}
+
+type corruptSender struct {
+	wrapped sender
+}
+
+func (s corruptSender) send(req *sqlwire.SQLRequest, resp *sqlwire.SQLResponse) error {
+	for _, cmd := range req.Cmds {
+		for _, d := range cmd.Params {
+			if d.Ival != nil {
+				*d.Ival++
+			}
+		}
+	}
+	if err := s.wrapped.send(req, resp); err != nil {
+		return err
+	}
+	for _, r := range resp.Results {
+		for _, d := range r.Values {
+			if d.Ival != nil {
+				*d.Ival++
+			}
+		}
+	}
+	return nil
+}
+
+func TestUpdate(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, db := setup(t)
+	defer cleanup(s, db)
+
+	const schema = `
+CREATE TABLE t.kv (
+  k CHAR PRIMARY KEY,
+  v INT,
+  w INT NOT NULL
+)`
+
+	if _, err := db.Exec("CREATE DATABASE t"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO t.kv VALUES ('a', 1, 10), ('b', 2, 20), ('c', 3, 30)`); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := db.Exec("UPDATE t.kv SET v = v + 10, w = ? WHERE k >= 'b'", 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		t.Fatal(err)
+	} else if n != 2 {
+		t.Fatalf("expected 2 rows affected, but got %d", n)
+	}
+
+	// Updating the primary key moves the row.
+	if _, err := db.Exec("UPDATE t.kv SET k = 'd' WHERE k = 'a'"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("UPDATE t.kv SET k = 'b' WHERE k = 'c'"); !isError(err, "duplicate key value") {
+		t.Fatalf("expected duplicate key error, but got %v", err)
+	}
+	if _, err := db.Exec("UPDATE t.kv SET w = NULL"); !isError(err, "null value in column \"w\"") {
+		t.Fatalf("expected not-null error, but got %v", err)
+	}
+	if _, err := db.Exec("UPDATE t.kv SET k = NULL WHERE k = 'b'"); !isError(err, "null value in column \"k\"") {
+		t.Fatalf("expected not-null error, but got %v", err)
+	}
+
+	rows, err := db.Query("SELECT * FROM t.kv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := readAll(t, rows)
+	expectedResults := [][]string{
+		{"k", "v", "w"},
+		{"b", "12", "7"},
+		{"c", "13", "7"},
+		{"d", "1", "10"},
+	}
+	if !reflect.DeepEqual(expectedResults, results) {
+		t.Fatalf("expected %s, but got %s", expectedResults, results)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, db := setup(t)
+	defer cleanup(s, db)
+
+	const schema = `
+CREATE TABLE t.kv (
+  k CHAR PRIMARY KEY,
+  v INT
+)`
+
+	if _, err := db.Exec("CREATE DATABASE t"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO t.kv VALUES ('a', 1), ('b', 2), ('c', 3), ('d', 4)`); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := db.Exec("DELETE FROM t.kv WHERE v > 2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		t.Fatal(err)
+	} else if n != 2 {
+		t.Fatalf("expected 2 rows affected, but got %d", n)
+	}
+
+	// A deleted row can be reinserted.
+	if _, err := db.Exec(`INSERT INTO t.kv VALUES ('c', 5)`); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := db.Query("SELECT * FROM t.kv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := readAll(t, rows)
+	expectedResults := [][]string{
+		{"k", "v"},
+		{"a", "1"},
+		{"b", "2"},
+		{"c", "5"},
+	}
+	if !reflect.DeepEqual(expectedResults, results) {
+		t.Fatalf("expected %s, but got %s", expectedResults, results)
+	}
+
+	res, err = db.Exec("DELETE FROM t.kv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		t.Fatal(err)
+	} else if n != 3 {
+		t.Fatalf("expected 3 rows affected, but got %d", n)
+	}
+}
+
+func TestUniqueIndex(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, db := setup(t)
+	defer cleanup(s, db)
+
+	const schema = `
+CREATE TABLE t.kv (
+  k CHAR PRIMARY KEY,
+  v INT,
+  UNIQUE INDEX foo (v)
+)`
+
+	if _, err := db.Exec("CREATE DATABASE t"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO t.kv VALUES ('a', 1), ('b', 2), ('c', NULL), ('d', NULL)`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := db.Exec(`INSERT INTO t.kv VALUES ('e', 1)`); !isError(err, "duplicate key value") {
+		t.Fatalf("expected duplicate key error, but got %v", err)
+	}
+	if _, err := db.Exec("UPDATE t.kv SET v = 2 WHERE k = 'a'"); !isError(err, "duplicate key value") {
+		t.Fatalf("expected duplicate key error, but got %v", err)
+	}
+
+	// Updating and deleting rows removes their old index entries.
+	if _, err := db.Exec("UPDATE t.kv SET v = 3 WHERE k = 'a'"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("DELETE FROM t.kv WHERE k = 'b'"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO t.kv VALUES ('e', 1), ('f', 2)`); err != nil {
+		t.Fatal(err)
+	}
+
+	// Changing the primary key of a row moves its index entry.
+	if _, err := db.Exec("UPDATE t.kv SET k = 'g' WHERE k = 'a'"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO t.kv VALUES ('h', 3)`); !isError(err, "duplicate key value") {
+		t.Fatalf("expected duplicate key error, but got %v", err)
+	}
+
+	rows, err := db.Query("SELECT * FROM t.kv WHERE v IS NOT NULL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := readAll(t, rows)
+	expectedResults := [][]string{
+		{"k", "v"},
+		{"e", "1"},
+		{"f", "2"},
+		{"g", "3"},
+	}
+	if !reflect.DeepEqual(expectedResults, results) {
+		t.Fatalf("expected %s, but got %s", expectedResults, results)
+	}
+}
+
+func TestCreateIndex(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, db := setup(t)
+	defer cleanup(s, db)
+
+	// Backfill the index using several transactions.
+	defer func(n int) { sqlserver.BackfillBatchSize = n }(sqlserver.BackfillBatchSize)
+	sqlserver.BackfillBatchSize = 2
+
+	const schema = `
+CREATE TABLE t.kv (
+  k CHAR PRIMARY KEY,
+  v INT,
+  w INT
+)`
+
+	if _, err := db.Exec("CREATE DATABASE t"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO t.kv VALUES ('a', 1, 1), ('b', 2, 1), ('c', 3, NULL), ('d', 4, 2), ('e', 5, NULL)`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := db.Exec("CREATE INDEX foo ON t.kv (x)"); !isError(err, "column \"x\" does not exist") {
+		t.Fatalf("expected unknown column error, but got %v", err)
+	}
+	if _, err := db.Exec("CREATE INDEX foo ON t.kv (w)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("CREATE INDEX foo ON t.kv (v)"); !isError(err, "duplicate index name") {
+		t.Fatalf("expected duplicate index error, but got %v", err)
+	}
+	// The backfill of a unique index fails if the existing rows contain
+	// duplicate values and the index is removed.
+	if _, err := db.Exec("CREATE UNIQUE INDEX bar ON t.kv (w)"); !isError(err, "duplicate key value violates unique constraint \"bar\"") {
+		t.Fatalf("expected duplicate key error, but got %v", err)
+	}
+	if _, err := db.Exec("CREATE UNIQUE INDEX bar ON t.kv (v)"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The backfilled index entries are checked by new writes.
+	if _, err := db.Exec(`INSERT INTO t.kv VALUES ('f', 5, 3)`); !isError(err, "duplicate key value") {
+		t.Fatalf("expected duplicate key error, but got %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO t.kv VALUES ('f', 6, 3)`); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := db.Query("SHOW INDEX FROM t.kv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := readAll(t, rows)
+	expectedResults := [][]string{
+		{"Table", "Name", "Unique", "Seq", "Column"},
+		{"kv", "primary", "true", "1", "k"},
+		{"kv", "foo", "false", "1", "w"},
+		{"kv", "bar", "true", "1", "v"},
+	}
+	if !reflect.DeepEqual(expectedResults, results) {
+		t.Fatalf("expected %s, but got %s", expectedResults, results)
+	}
+
+	if _, err := db.Exec("DROP INDEX baz ON t.kv"); !isError(err, "index \"baz\" does not exist") {
+		t.Fatalf("expected unknown index error, but got %v", err)
+	}
+	if _, err := db.Exec("DROP INDEX bar ON t.kv"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO t.kv VALUES ('g', 6, 4)`); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCreateIndexBackfill(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, db := setup(t)
+	defer cleanup(s, db)
+
+	defer func(n int) { sqlserver.BackfillBatchSize = n }(sqlserver.BackfillBatchSize)
+	sqlserver.BackfillBatchSize = 2
+
+	for _, stmt := range []string{
+		`CREATE DATABASE t`,
+		`CREATE TABLE t.kv (k CHAR PRIMARY KEY, v INT)`,
+		`INSERT INTO t.kv VALUES ('a', 1), ('b', 2), ('c', 3), ('d', 4), ('e', 5)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	// The index is not read while it is backfilled, but rows written
+	// concurrently with the backfill are added to it.
+	var backfillErr error
+	var batches int
+	defer func() { sqlserver.TestingIndexBackfillFilter = nil }()
+	sqlserver.TestingIndexBackfillFilter = func() {
+		if backfillErr != nil {
+			return
+		}
+		batches++
+		if batches == 1 {
+			if _, backfillErr = db.Exec(`INSERT INTO t.kv VALUES ('f', 6)`); backfillErr != nil {
+				return
+			}
+		}
+		var count int
+		if backfillErr = db.QueryRow(`SELECT COUNT(*) FROM t.kv WHERE v >= 1`).Scan(&count); backfillErr != nil {
+			return
+		}
+		if count != 6 {
+			backfillErr = fmt.Errorf("expected 6 rows during the backfill, but found %d", count)
+		}
+	}
+	if _, err := db.Exec("CREATE INDEX foo ON t.kv (v)"); err != nil {
+		t.Fatal(err)
+	}
+	sqlserver.TestingIndexBackfillFilter = nil
+	if backfillErr != nil {
+		t.Fatal(backfillErr)
+	}
+	if batches < 2 {
+		t.Fatalf("expected the backfill to use several batches, but found %d", batches)
+	}
+
+	// The index is used once the backfill completes and contains the rows
+	// written during the backfill.
+	rows, err := db.Query("EXPLAIN SELECT k FROM t.kv WHERE v >= 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results := readAll(t, rows); len(results) < 2 || results[1][1] != "t.kv@foo: v >= 1" {
+		t.Fatalf("expected a scan of foo, but got %s", results)
+	}
+	var k string
+	if err := db.QueryRow(`SELECT k FROM t.kv WHERE v = 6`).Scan(&k); err != nil {
+		t.Fatal(err)
+	} else if k != "f" {
+		t.Fatalf("expected f, but found %s", k)
+	}
+}
+
+func TestDropTable(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, db := setup(t)
+	defer cleanup(s, db)
+	kvDB := kvClient(t, s)
+
+	const schema = `
+CREATE TABLE t.kv (
+  k CHAR PRIMARY KEY,
+  v INT,
+  INDEX foo (v)
+)`
+
+	if _, err := db.Exec("CREATE DATABASE t"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO t.kv VALUES ('a', 1), ('b', 2)`); err != nil {
+		t.Fatal(err)
+	}
+	if n := tableDataCount(t, kvDB); n == 0 {
+		t.Fatal("expected table data")
+	}
+
+	if _, err := db.Exec("DROP TABLE t.kv"); err != nil {
+		t.Fatal(err)
+	}
+	if n := tableDataCount(t, kvDB); n != 0 {
+		t.Fatalf("expected table data to be deleted, but found %d keys", n)
+	}
+	if _, err := db.Query("SELECT * FROM t.kv"); !isError(err, "table .* does not exist") {
+		t.Fatalf("expected unknown table error, but got %v", err)
+	}
+	if _, err := db.Exec("DROP TABLE t.kv"); !isError(err, "table .* does not exist") {
+		t.Fatalf("expected unknown table error, but got %v", err)
+	}
+	if _, err := db.Exec("DROP TABLE IF EXISTS t.kv"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("DROP TABLE u.kv"); !isError(err, `database "u" does not exist`) {
+		t.Fatalf("expected unknown database error, but got %v", err)
+	}
+	if _, err := db.Exec("DROP TABLE IF EXISTS u.kv"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The table can be recreated and is empty.
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.Query("SELECT * FROM t.kv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := readAll(t, rows)
+	expectedResults := [][]string{
+		{"k", "v"},
+	}
+	if !reflect.DeepEqual(expectedResults, results) {
+		t.Fatalf("expected %s, but got %s", expectedResults, results)
+	}
+}
+
+func TestDropDatabase(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, db := setup(t)
+	defer cleanup(s, db)
+	kvDB := kvClient(t, s)
+
+	if _, err := db.Exec("CREATE DATABASE t"); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a", "b"} {
+		if _, err := db.Exec("CREATE TABLE t." + name + " (k INT PRIMARY KEY)"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.Exec("INSERT INTO t." + name + " VALUES (1), (2)"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := db.Exec("DROP DATABASE t"); err != nil {
+		t.Fatal(err)
+	}
+	if n := tableDataCount(t, kvDB); n != 0 {
+		t.Fatalf("expected table data to be deleted, but found %d keys", n)
+	}
+	if _, err := db.Query("SELECT * FROM t.a"); !isError(err, "database .* does not exist") {
+		t.Fatalf("expected unknown database error, but got %v", err)
+	}
+	if _, err := db.Exec("DROP DATABASE t"); !isError(err, "database .* does not exist") {
+		t.Fatalf("expected unknown database error, but got %v", err)
+	}
+	if _, err := db.Exec("DROP DATABASE IF EXISTS t"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The database can be recreated and does not contain the old tables.
+	if _, err := db.Exec("CREATE DATABASE t"); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.Query("SHOW TABLES FROM t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := readAll(t, rows)
+	expectedResults := [][]string{
+		{"tables"},
+	}
+	if !reflect.DeepEqual(expectedResults, results) {
+		t.Fatalf("expected %s, but got %s", expectedResults, results)
+	}
+}
+
+func TestTruncateTable(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, db := setup(t)
+	defer cleanup(s, db)
+	kvDB := kvClient(t, s)
+
+	const schema = `
+CREATE TABLE t.kv (
+  k CHAR PRIMARY KEY,
+  v INT,
+  UNIQUE INDEX foo (v)
+)`
+
+	if _, err := db.Exec("CREATE DATABASE t"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO t.kv VALUES ('a', 1), ('b', 2)`); err != nil {
+		t.Fatal(err)
+	}
+
+	// The data is deleted along with an update of the descriptor, which orders
+	// the deletion with respect to concurrent writes.
+	_, before := readTableDesc(t, kvDB, "t", "kv")
+	if _, err := db.Exec("TRUNCATE TABLE t.kv"); err != nil {
+		t.Fatal(err)
+	}
+	if n := tableDataCount(t, kvDB); n != 0 {
+		t.Fatalf("expected table data to be deleted, but found %d keys", n)
+	}
+	if _, after := readTableDesc(t, kvDB, "t", "kv"); after.Version != before.Version+1 {
+		t.Fatalf("expected version %d, but found %d", before.Version+1, after.Version)
+	}
+
+	// The schema is retained, including the (now empty) unique index.
+	if _, err := db.Exec(`INSERT INTO t.kv VALUES ('c', 1)`); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.Query("SELECT * FROM t.kv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := readAll(t, rows)
+	expectedResults := [][]string{
+		{"k", "v"},
+		{"c", "1"},
+	}
+	if !reflect.DeepEqual(expectedResults, results) {
+		t.Fatalf("expected %s, but got %s", expectedResults, results)
+	}
+}
+
+func TestAlterTableAddColumn(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, db := setup(t)
+	defer cleanup(s, db)
+
+	// Backfill the column using several transactions.
+	defer func(n int) { sqlserver.BackfillBatchSize = n }(sqlserver.BackfillBatchSize)
+	sqlserver.BackfillBatchSize = 2
+
+	const schema = `
+CREATE TABLE t.kv (
+  k CHAR PRIMARY KEY,
+  v INT
+)`
+
+	if _, err := db.Exec("CREATE DATABASE t"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO t.kv VALUES ('a', 1), ('b', 2), ('c', 3), ('d', 4), ('e', 5)`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := db.Exec("ALTER TABLE t.kv ADD COLUMN v INT"); !isError(err, "duplicate column name") {
+		t.Fatalf("expected duplicate column error, but got %v", err)
+	}
+	if _, err := db.Exec("ALTER TABLE t.kv ADD COLUMN w INT NOT NULL"); !isError(err, "column \"w\" contains null values") {
+		t.Fatalf("expected null values error, but got %v", err)
+	}
+	if _, err := db.Exec("ALTER TABLE t.kv ADD COLUMN w INT DEFAULT 'foo'"); !isError(err, "doesn't match type INT") {
+		t.Fatalf("expected type error, but got %v", err)
+	}
+	if _, err := db.Exec("ALTER TABLE t.kv ADD COLUMN w INT NOT NULL DEFAULT 7"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("ALTER TABLE t.kv ADD x CHAR DEFAULT 'x'"); err != nil {
+		t.Fatal(err)
+	}
+
+	// New rows use the defaults for the columns which are not specified.
+	if _, err := db.Exec(`INSERT INTO t.kv (k, v, x) VALUES ('f', 6, 'y')`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO t.kv (k, v, w) VALUES ('g', 7, NULL)`); !isError(err, "violates not-null constraint") {
+		t.Fatalf("expected not-null error, but got %v", err)
+	}
+
+	rows, err := db.Query("SELECT * FROM t.kv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := readAll(t, rows)
+	expectedResults := [][]string{
+		{"k", "v", "w", "x"},
+		{"a", "1", "7", "x"},
+		{"b", "2", "7", "x"},
+		{"c", "3", "7", "x"},
+		{"d", "4", "7", "x"},
+		{"e", "5", "7", "x"},
+		{"f", "6", "7", "y"},
+	}
+	if !reflect.DeepEqual(expectedResults, results) {
+		t.Fatalf("expected %s, but got %s", expectedResults, results)
+	}
+
+	// A column is write-only while it is being backfilled: it is not visible
+	// to statements, but rows written in the meantime contain its default
+	// value.
+	kvDB := kvClient(t, s)
+	setColumnState(t, kvDB, "t", "kv", "x", structured.ColumnDescriptor_WRITE_ONLY)
+	if _, err := db.Query("SELECT x FROM t.kv"); !isError(err, "column \"x\" does not exist") {
+		t.Fatalf("expected unknown column error, but got %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO t.kv (k, x) VALUES ('g', 'y')`); !isError(err, "column \"x\" does not exist") {
+		t.Fatalf("expected unknown column error, but got %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO t.kv VALUES ('g', 7, 8)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`UPDATE t.kv SET k = 'h', v = 9 WHERE k = 'f'`); err != nil {
+		t.Fatal(err)
+	}
+	if rows, err = db.Query("SELECT * FROM t.kv WHERE k > 'e'"); err != nil {
+		t.Fatal(err)
+	}
+	results = readAll(t, rows)
+	expectedResults = [][]string{
+		{"k", "v", "w"},
+		{"g", "7", "8"},
+		{"h", "9", "7"},
+	}
+	if !reflect.DeepEqual(expectedResults, results) {
+		t.Fatalf("expected %s, but got %s", expectedResults, results)
+	}
+
+	setColumnState(t, kvDB, "t", "kv", "x", structured.ColumnDescriptor_PUBLIC)
+	if rows, err = db.Query("SELECT * FROM t.kv WHERE k > 'e'"); err != nil {
+		t.Fatal(err)
+	}
+	results = readAll(t, rows)
+	expectedResults = [][]string{
+		{"k", "v", "w", "x"},
+		{"g", "7", "8", "x"},
+		{"h", "9", "7", "y"},
+	}
+	if !reflect.DeepEqual(expectedResults, results) {
+		t.Fatalf("expected %s, but got %s", expectedResults, results)
+	}
+}
+
+func TestAlterTableDropColumn(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, db := setup(t)
+	defer cleanup(s, db)
+	kvDB := kvClient(t, s)
+
+	// Delete the column data using several transactions.
+	defer func(n int) { sqlserver.BackfillBatchSize = n }(sqlserver.BackfillBatchSize)
+	sqlserver.BackfillBatchSize = 2
+
+	const schema = `
+CREATE TABLE t.kv (
+  k CHAR PRIMARY KEY,
+  v INT,
+  w INT,
+  INDEX foo (v)
+)`
+
+	if _, err := db.Exec("CREATE DATABASE t"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO t.kv VALUES ('a', 1, 1), ('b', 2, 2), ('c', 3, 3)`); err != nil {
+		t.Fatal(err)
+	}
+	// 3 rows of 3 columns plus 3 index entries.
+	if n := tableDataCount(t, kvDB); n != 12 {
+		t.Fatalf("expected 12 keys, but found %d", n)
+	}
+
+	if _, err := db.Exec("ALTER TABLE t.kv DROP COLUMN x"); !isError(err, "column \"x\" does not exist") {
+		t.Fatalf("expected unknown column error, but got %v", err)
+	}
+	if _, err := db.Exec("ALTER TABLE t.kv DROP COLUMN v"); !isError(err, "column \"v\" is referenced by index \"foo\"") {
+		t.Fatalf("expected index reference error, but got %v", err)
+	}
+	if _, err := db.Exec("ALTER TABLE t.kv DROP COLUMN w"); err != nil {
+		t.Fatal(err)
+	}
+	if n := tableDataCount(t, kvDB); n != 9 {
+		t.Fatalf("expected the data of the dropped column to be deleted, but found %d keys", n)
+	}
+
+	// A column added with the same name does not see the old values.
+	if _, err := db.Exec("ALTER TABLE t.kv ADD COLUMN w CHAR DEFAULT 'z'"); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.Query("SELECT * FROM t.kv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := readAll(t, rows)
+	expectedResults := [][]string{
+		{"k", "v", "w"},
+		{"a", "1", "z"},
+		{"b", "2", "z"},
+		{"c", "3", "z"},
+	}
+	if !reflect.DeepEqual(expectedResults, results) {
+		t.Fatalf("expected %s, but got %s", expectedResults, results)
+	}
+}
+
+func TestAlterTableRenameColumn(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, db := setup(t)
+	defer cleanup(s, db)
+
+	const schema = `
+CREATE TABLE t.kv (
+  k CHAR PRIMARY KEY,
+  v INT,
+  INDEX foo (v)
+)`
+
+	if _, err := db.Exec("CREATE DATABASE t"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO t.kv VALUES ('a', 1)`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := db.Exec("ALTER TABLE t.kv RENAME COLUMN v TO k"); !isError(err, "duplicate column name") {
+		t.Fatalf("expected duplicate column error, but got %v", err)
+	}
+	if _, err := db.Exec("ALTER TABLE t.kv RENAME COLUMN v TO w"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("UPDATE t.kv SET v = 2"); !isError(err, "column \"v\" does not exist") {
+		t.Fatalf("expected unknown column error, but got %v", err)
+	}
+	if _, err := db.Exec("UPDATE t.kv SET w = 2"); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := db.Query("SELECT k, w FROM t.kv WHERE w = 2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := readAll(t, rows)
+	expectedResults := [][]string{
+		{"k", "w"},
+		{"a", "2"},
+	}
+	if !reflect.DeepEqual(expectedResults, results) {
+		t.Fatalf("expected %s, but got %s", expectedResults, results)
+	}
+}
+
+func TestRenameTable(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, db := setup(t)
+	defer cleanup(s, db)
+
+	if _, err := db.Exec("CREATE DATABASE t"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("CREATE DATABASE u"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("CREATE TABLE t.kv (k CHAR PRIMARY KEY, v INT)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("CREATE TABLE t.other (k CHAR PRIMARY KEY)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO t.kv VALUES ('a', 1)`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := db.Exec("RENAME TABLE t.kv TO t.other"); !isError(err, "table \"t.other\" already exists") {
+		t.Fatalf("expected existing table error, but got %v", err)
+	}
+	if _, err := db.Exec("ALTER TABLE t.kv RENAME TO t.kv2"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("SELECT * FROM t.kv"); !isError(err, "table \"t.kv\" does not exist") {
+		t.Fatalf("expected unknown table error, but got %v", err)
+	}
+	// The table can be moved to another database.
+	if _, err := db.Exec("RENAME TABLE t.kv2 TO u.kv"); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := db.Query("SELECT * FROM u.kv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := readAll(t, rows)
+	expectedResults := [][]string{
+		{"k", "v"},
+		{"a", "1"},
+	}
+	if !reflect.DeepEqual(expectedResults, results) {
+		t.Fatalf("expected %s, but got %s", expectedResults, results)
+	}
+
+	rows, err = db.Query("SHOW TABLES FROM t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	results = readAll(t, rows)
+	expectedResults = [][]string{
+		{"tables"},
+		{"other"},
+	}
+	if !reflect.DeepEqual(expectedResults, results) {
+		t.Fatalf("expected %s, but got %s", expectedResults, results)
+	}
+}
+
+func TestViews(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, db := setup(t)
+	defer cleanup(s, db)
+
+	if _, err := db.Exec("CREATE DATABASE t"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("CREATE TABLE t.kv (k CHAR PRIMARY KEY, v INT)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO t.kv VALUES ('a', 1), ('b', 2), ('c', 3)`); err != nil {
+		t.Fatal(err)
+	}
+	// The table names of the query are qualified by the current database.
+	if _, err := db.Exec("USE t"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("CREATE VIEW big AS SELECT * FROM kv WHERE v > 1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("CREATE VIEW t.sums (total, n) AS SELECT SUM(v), COUNT(*) FROM t.kv"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("CREATE VIEW t.big AS SELECT k FROM t.kv"); !isError(err, `"big" already exists`) {
+		t.Fatalf("expected existing view error, but got %v", err)
+	}
+	if _, err := db.Exec("CREATE VIEW t.p AS SELECT k FROM t.kv WHERE v > $1", 1); !isError(err, "cannot contain placeholders") {
+		t.Fatalf("expected placeholder error, but got %v", err)
+	}
+	if _, err := db.Exec("CREATE VIEW t.p (a, b) AS SELECT k FROM t.kv"); !isError(err, "has 2 column names but its query returns 1 columns") {
+		t.Fatalf("expected column count error, but got %v", err)
+	}
+	// Columns added to the table later on are not part of the view.
+	if _, err := db.Exec("ALTER TABLE t.kv ADD COLUMN w INT"); err != nil {
+		t.Fatal(err)
+	}
+
+	testData := []struct {
+		query    string
+		expected [][]string
+	}{
+		{"SELECT * FROM t.big",
+			[][]string{
+				{"k", "v"},
+				{"b", "2"},
+				{"c", "3"},
+			}},
+		{"SELECT k FROM t.big WHERE v < 3",
+			[][]string{
+				{"k"},
+				{"b"},
+			}},
+		{"SELECT * FROM t.sums",
+			[][]string{
+				{"total", "n"},
+				{"6", "3"},
+			}},
+		{"SELECT b.k, kv.v FROM t.big AS b JOIN t.kv ON b.k = kv.k WHERE kv.v = 3",
+			[][]string{
+				{"k", "v"},
+				{"c", "3"},
+			}},
+		{"SHOW TABLES",
+			[][]string{
+				{"tables"},
+				{"big"},
+				{"kv"},
+				{"sums"},
+			}},
+		{"SELECT table_name, table_type FROM information_schema.tables WHERE table_schema = 't'",
+			[][]string{
+				{"table_name", "table_type"},
+				{"big", "VIEW"},
+				{"kv", "BASE TABLE"},
+				{"sums", "VIEW"},
+			}},
+		{"SHOW COLUMNS FROM t.sums",
+			[][]string{
+				{"Field", "Type", "Null"},
+				{"total", "INT", "true"},
+				{"n", "INT", "true"},
+			}},
+		{"SHOW CREATE TABLE t.big",
+			[][]string{
+				{"Table", "Create Table"},
+				{"big", "CREATE VIEW t.big (k, v) AS SELECT k, v FROM t.kv WHERE v > 1"},
+			}},
+	}
+	for _, d := range testData {
+		rows, err := db.Query(d.query)
+		if err != nil {
+			t.Fatalf("%s: %v", d.query, err)
+		}
+		results := readAll(t, rows)
+		if !reflect.DeepEqual(d.expected, results) {
+			t.Fatalf("%s: expected %s, but got %s", d.query, d.expected, results)
+		}
+	}
+
+	// Views are read-only and cannot be dropped as tables.
+	if _, err := db.Exec("INSERT INTO t.big VALUES ('d', 4)"); !isError(err, `cannot modify view "t.big"`) {
+		t.Fatalf("expected read-only view error, but got %v", err)
+	}
+	if _, err := db.Exec("DROP TABLE t.big"); !isError(err, `"t.big" is not a table`) {
+		t.Fatalf("expected not a table error, but got %v", err)
+	}
+	if _, err := db.Exec("DROP VIEW t.kv"); !isError(err, `"t.kv" is not a view`) {
+		t.Fatalf("expected not a view error, but got %v", err)
+	}
+
+	// A table or view cannot be dropped or renamed while views depend on it.
+	if _, err := db.Exec("DROP TABLE t.kv"); !isError(err, `cannot drop "t.kv" because view "t.(big|sums)" depends on it`) {
+		t.Fatalf("expected dependent view error, but got %v", err)
+	}
+	if _, err := db.Exec("RENAME TABLE t.kv TO t.kv2"); !isError(err, `cannot rename "t.kv" because view`) {
+		t.Fatalf("expected dependent view error, but got %v", err)
+	}
+	if _, err := db.Exec("ALTER TABLE t.kv DROP COLUMN v"); !isError(err, `cannot drop column "v" of "t.kv" because view`) {
+		t.Fatalf("expected dependent view error, but got %v", err)
+	}
+	if _, err := db.Exec("ALTER TABLE t.kv RENAME COLUMN v TO w"); !isError(err, `cannot rename column "v" of "t.kv" because view`) {
+		t.Fatalf("expected dependent view error, but got %v", err)
+	}
+	if _, err := db.Exec("CREATE VIEW t.small AS SELECT k FROM t.big WHERE v < 3"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("DROP VIEW t.big"); !isError(err, `cannot drop "t.big" because view "t.small" depends on it`) {
+		t.Fatalf("expected dependent view error, but got %v", err)
+	}
+	if _, err := db.Exec("ALTER VIEW t.big AS SELECT k FROM t.small"); !isError(err, `view "t.big" cannot depend on itself`) {
+		t.Fatalf("expected dependency cycle error, but got %v", err)
+	}
+
+	// Altering a view replaces its query and its dependencies.
+	if _, err := db.Exec("ALTER VIEW t.sums AS SELECT k FROM t.big"); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.Query("SELECT * FROM t.sums")
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := readAll(t, rows)
+	expectedResults := [][]string{
+		{"k"},
+		{"b"},
+		{"c"},
+	}
+	if !reflect.DeepEqual(expectedResults, results) {
+		t.Fatalf("expected %s, but got %s", expectedResults, results)
+	}
+	for _, stmt := range []string{"DROP VIEW t.small", "DROP VIEW t.sums"} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := db.Exec("DROP TABLE t.kv"); !isError(err, `cannot drop "t.kv" because view "t.big" depends on it`) {
+		t.Fatalf("expected dependent view error, but got %v", err)
+	}
+
+	// A database cannot be dropped while views of other databases depend on
+	// its tables.
+	if _, err := db.Exec("CREATE DATABASE u"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("CREATE VIEW u.v AS SELECT k FROM t.kv"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("DROP DATABASE t"); !isError(err, `cannot drop "t.kv" because view "u.v" depends on it`) {
+		t.Fatalf("expected dependent view error, but got %v", err)
+	}
+	for _, stmt := range []string{"DROP DATABASE u", "DROP VIEW t.big", "DROP VIEW IF EXISTS t.big", "DROP TABLE t.kv"} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+}
+
+func TestTransaction(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, db := setup(t)
+	defer cleanup(s, db)
+
+	const schema = `
+CREATE TABLE t.kv (
+  k CHAR PRIMARY KEY,
+  v INT
+)`
+
+	if _, err := db.Exec("CREATE DATABASE t"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO t.kv VALUES ('a', 1)`); err != nil {
+		t.Fatal(err)
+	}
+
+	selectAll := func(q interface {
+		Query(string, ...interface{}) (*sql.Rows, error)
+	}) [][]string {
+		rows, err := q.Query("SELECT * FROM t.kv")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return readAll(t, rows)
+	}
+	original := [][]string{
+		{"k", "v"},
+		{"a", "1"},
+	}
+	modified := [][]string{
+		{"k", "v"},
+		{"a", "2"},
+		{"b", "3"},
+	}
+
+	// Each statement of the transaction is a separate request to the server.
+	// The writes of the transaction are visible within the transaction but not
+	// to other connections, and are discarded on rollback.
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec(`UPDATE t.kv SET v = 2 WHERE k = 'a'`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec(`INSERT INTO t.kv VALUES ('b', 3)`); err != nil {
+		t.Fatal(err)
+	}
+	if results := selectAll(tx); !reflect.DeepEqual(modified, results) {
+		t.Fatalf("expected %s, but got %s", modified, results)
+	}
+	if results := selectAll(db); !reflect.DeepEqual(original, results) {
+		t.Fatalf("expected %s, but got %s", original, results)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if results := selectAll(db); !reflect.DeepEqual(original, results) {
+		t.Fatalf("expected %s, but got %s", original, results)
+	}
+
+	// The writes of a committed transaction are visible to other connections.
+	// The current database is part of the session state retained across
+	// requests.
+	if tx, err = db.Begin(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec("USE t"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec(`UPDATE kv SET v = 2 WHERE k = 'a'`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec(`INSERT INTO kv VALUES ('b', 3)`); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if results := selectAll(db); !reflect.DeepEqual(modified, results) {
+		t.Fatalf("expected %s, but got %s", modified, results)
+	}
+}
+
+func TestTransactionAbort(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, db := setup(t)
+	defer cleanup(s, db)
+
+	const schema = `
+CREATE TABLE t.kv (
+  k CHAR PRIMARY KEY,
+  v INT
+)`
+
+	if _, err := db.Exec("CREATE DATABASE t"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatal(err)
+	}
+
+	// A failed statement aborts the transaction, discarding its writes. The
+	// statements following the failure are rejected.
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec(`INSERT INTO t.kv VALUES ('a', 1)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec(`INSERT INTO t.kv VALUES ('a', 2)`); !isError(err, "duplicate key value") {
+		t.Fatalf("expected failure, but found %v", err)
+	}
+	if _, err := tx.Exec(`INSERT INTO t.kv VALUES ('b', 3)`); !isError(err, "current transaction is aborted") {
+		t.Fatalf("expected failure, but found %v", err)
+	}
+	if err := tx.Commit(); !isError(err, "current transaction is aborted") {
+		t.Fatalf("expected failure, but found %v", err)
+	}
+
+	rows, err := db.Query("SELECT * FROM t.kv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results := readAll(t, rows); len(results) != 1 {
+		t.Fatalf("expected no rows, but got %s", results[1:])
+	}
+
+	// Schema changes cannot be performed within a transaction.
+	if tx, err = db.Begin(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec("CREATE INDEX foo ON t.kv (v)"); !isError(err, "is not supported within a transaction") {
+		t.Fatalf("expected failure, but found %v", err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSessionVariables(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, db := setup(t)
+	defer cleanup(s, db)
+
+	for _, stmt := range []string{
+		`CREATE DATABASE t`,
+		`CREATE TABLE t.kv (k CHAR PRIMARY KEY, v TIMESTAMP)`,
+		`INSERT INTO t.kv VALUES ('a', '2015-06-01 12:00:00')`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	showAll := func() [][]string {
+		rows, err := db.Query("SHOW ALL")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return readAll(t, rows)
+	}
+	expected := [][]string{
+		{"Variable", "Value"},
+		{"database", ""},
+		{"default_transaction_isolation", "SERIALIZABLE"},
+		{"time_zone", "UTC"},
+		{"statement_timeout", "0"},
+	}
+	if results := showAll(); !reflect.DeepEqual(expected, results) {
+		t.Fatalf("expected %s, but got %s", expected, results)
+	}
+
+	// The variables are retained across requests.
+	for _, stmt := range []string{
+		`SET DATABASE = t`,
+		`SET default_transaction_isolation = 'snapshot', time_zone = 'America/New_York'`,
+		`SET statement_timeout = 5000`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	expected = [][]string{
+		{"Variable", "Value"},
+		{"database", "t"},
+		{"default_transaction_isolation", "SNAPSHOT"},
+		{"time_zone", "America/New_York"},
+		{"statement_timeout", "5s"},
+	}
+	if results := showAll(); !reflect.DeepEqual(expected, results) {
+		t.Fatalf("expected %s, but got %s", expected, results)
+	}
+	rows, err := db.Query("SHOW time_zone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results := readAll(t, rows); !reflect.DeepEqual(expected[3][1:], results[1]) {
+		t.Fatalf("expected %s, but got %s", expected[3][1:], results)
+	}
+
+	// Times are returned in the time zone of the session.
+	var ts time.Time
+	if err := db.QueryRow("SELECT v FROM kv WHERE k = 'a'").Scan(&ts); err != nil {
+		t.Fatal(err)
+	}
+	if ts.Location().String() != "America/New_York" || ts.Hour() != 8 {
+		t.Fatalf("expected 08:00 in America/New_York, but got %s", ts)
+	}
+
+	// Times without a time zone are in the time zone of the session.
+	if _, err := db.Exec(`INSERT INTO kv VALUES ('b', '2015-06-01 08:00:00')`); err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range []struct {
+		query string
+		args  []interface{}
+	}{
+		{`SELECT COUNT(*) FROM kv WHERE v = '2015-06-01 08:00:00'`, nil},
+		{`SELECT COUNT(*) FROM kv WHERE v = ?`, []interface{}{"2015-06-01 08:00:00"}},
+		{`SELECT COUNT(*) FROM kv WHERE v = '2015-06-01 12:00:00Z'`, nil},
+	} {
+		var count int
+		if err := db.QueryRow(d.query, d.args...).Scan(&count); err != nil {
+			t.Fatalf("%s: %v", d.query, err)
+		} else if count != 2 {
+			t.Fatalf("%s: expected 2 rows, but found %d", d.query, count)
+		}
+	}
+	// So are the times passed to builtin functions and compared with
+	// expressions other than a column, and the fields of times are extracted
+	// in the time zone of the session.
+	for _, d := range []struct {
+		query    string
+		expected int
+	}{
+		{`SELECT HOUR(v) FROM kv WHERE k = 'b'`, 8},
+		{`SELECT HOUR('2015-06-01 08:00:00') FROM kv WHERE k = 'b'`, 8},
+		{`SELECT COUNT(*) FROM kv WHERE GREATEST(v, v) = '2015-06-01 08:00:00'`, 2},
+	} {
+		var v int
+		if err := db.QueryRow(d.query).Scan(&v); err != nil {
+			t.Fatalf("%s: %v", d.query, err)
+		} else if v != d.expected {
+			t.Fatalf("%s: expected %d, but found %d", d.query, d.expected, v)
+		}
+	}
+	if _, err := db.Exec(`DELETE FROM kv WHERE k = 'b'`); err != nil {
+		t.Fatal(err)
+	}
+
+	// A variable is only set if all of the variables of the statement are
+	// valid.
+	for _, d := range []struct {
+		stmt string
+		err  string
+	}{
+		{`SET time_zone = 'UTC', foo = 1`, `unknown variable: "foo"`},
+		{`SET time_zone = 'Nowhere/Nothing'`, `unknown time zone "Nowhere/Nothing"`},
+		{`SET default_transaction_isolation = 'read committed'`, `invalid value`},
+		{`SET statement_timeout = -1`, `invalid value`},
+		{`SHOW foo`, `unknown variable: "foo"`},
+	} {
+		if _, err := db.Exec(d.stmt); !isError(err, d.err) {
+			t.Errorf("%s: expected %s, but found %v", d.stmt, d.err, err)
+		}
+	}
+	var zone string
+	if err := db.QueryRow("SHOW time_zone").Scan(&zone); err != nil {
+		t.Fatal(err)
+	} else if zone != "America/New_York" {
+		t.Fatalf("expected America/New_York, but found %s", zone)
+	}
+
+	// A statement which exceeds the statement timeout fails without
+	// performing its writes.
+	if _, err := db.Exec(`SET statement_timeout = '1ns'`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Query("SELECT * FROM kv"); !isError(err, "statement timeout") {
+		t.Fatalf("expected failure, but found %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO kv VALUES ('b', NOW())`); !isError(err, "statement timeout") {
+		t.Fatalf("expected failure, but found %v", err)
+	}
+	if _, err := db.Exec(`SET statement_timeout = 0`); err != nil {
+		t.Fatal(err)
+	}
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM kv").Scan(&count); err != nil {
+		t.Fatal(err)
+	} else if count != 1 {
+		t.Fatalf("expected 1 row, but found %d", count)
+	}
+}
+
+func TestImportExport(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, db := setup(t)
+	defer cleanup(s, db)
+	defer func(n int) { sqlserver.ImportBatchSize = n }(sqlserver.ImportBatchSize)
+	sqlserver.ImportBatchSize = 2
+
+	const schema = `
+CREATE TABLE t.%s (
+  k INT PRIMARY KEY,
+  v CHAR,
+  d DATE,
+  n INT DEFAULT 7,
+  UNIQUE INDEX byv (v)
+)`
+	for _, stmt := range []string{
+		`CREATE DATABASE t`,
+		strings.Replace(schema, "%s", "kv", 1),
+		strings.Replace(schema, "%s", "copy", 1),
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	// The header names the columns of the values. The columns which are not
+	// named are set to their defaults.
+	f, err := ioutil.TempFile("", "import")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString("v,k,d\nc,3,\n\"a, b\",1,2015-06-01\nd,2,2015-06-02\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	res, err := db.Exec(`IMPORT TABLE t.kv FROM CSV '` + f.Name() + `'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n != 3 {
+		t.Fatalf("expected 3 rows, but found %d, %v", n, err)
+	}
+
+	rows, err := db.Query(`SELECT k, v, n FROM t.kv WHERE v = 'd'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedRows := [][]string{
+		{"k", "v", "n"},
+		{"2", "d", "7"},
+	}
+	if results := readAll(t, rows); !reflect.DeepEqual(expectedRows, results) {
+		t.Fatalf("expected %s, but got %s", expectedRows, results)
+	}
+
+	// Importing the rows again violates the unique indexes.
+	if _, err := db.Exec(`IMPORT TABLE t.kv FROM CSV '` + f.Name() + `'`); !isError(err, "duplicate key value") {
+		t.Fatalf("expected failure, but found %v", err)
+	}
+
+	// The exported rows are ordered by primary key and imported unchanged.
+	// NULL is distinguished from the empty string and from the string \N.
+	for i, v := range []interface{}{"", nil, `\N`, `\\N`} {
+		if _, err := db.Exec(`INSERT INTO t.kv (k, v) VALUES (?, ?)`, 4+i, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	kvDB := kvClient(t, s)
+	var buf bytes.Buffer
+	if n, err := sqlserver.ExportCSV(kvDB, "t.kv", &buf); err != nil || n != 7 {
+		t.Fatalf("expected 7 rows, but found %d, %v", n, err)
+	}
+	const expected = `k,v,d,n
+1,"a, b",2015-06-01,7
+2,d,2015-06-02,7
+3,c,\N,7
+4,,\N,7
+5,\N,\N,7
+6,\\N,\N,7
+7,\\\N,\N,7
+`
+	if buf.String() != expected {
+		t.Fatalf("expected %q, but found %q", expected, buf.String())
+	}
+	if n, err := sqlserver.ImportCSV(kvDB, "t.copy", &buf); err != nil || n != 7 {
+		t.Fatalf("expected 7 rows, but found %d, %v", n, err)
+	}
+	for _, d := range []struct {
+		where string
+		args  []interface{}
+		k     int
+	}{
+		{`v = ''`, nil, 4},
+		{`v IS NULL`, nil, 5},
+		{`v = ?`, []interface{}{`\N`}, 6},
+	} {
+		var k int
+		if err := db.QueryRow(`SELECT k FROM t.copy WHERE k > 3 AND `+d.where, d.args...).Scan(&k); err != nil {
+			t.Fatalf("%s: %v", d.where, err)
+		} else if k != d.k {
+			t.Fatalf("%s: expected %d, but found %d", d.where, d.k, k)
+		}
+	}
+	buf.Reset()
+	if _, err := sqlserver.ExportCSV(kvDB, "t.copy", &buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != expected {
+		t.Fatalf("expected %q, but found %q", expected, buf.String())
+	}
+
+	for _, d := range []struct {
+		data string
+		err  string
+	}{
+		{"", "missing CSV header"},
+		{"k,x\n", `column "x" does not exist`},
+		{"k,v\n4\n", "wrong number of fields"},
+		{"k,v\nfour,e\n", `row 1: value "four" is not valid for column "k" of type INT`},
+		{"v\ne\n", `missing "k" primary key column`},
+	} {
+		if _, err := sqlserver.ImportCSV(kvDB, "t.copy", strings.NewReader(d.data)); !isError(err, d.err) {
+			t.Errorf("%q: expected %s, but found %v", d.data, d.err, err)
+		}
+	}
+}
+
+func TestAsOfSystemTime(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, db := setup(t)
+	defer cleanup(s, db)
+
+	for _, stmt := range []string{
+		`CREATE DATABASE t`,
+		`CREATE TABLE t.kv (k CHAR PRIMARY KEY, v CHAR)`,
+		`INSERT INTO t.kv VALUES ('a', 'x'), ('b', 'x')`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	before := time.Now()
+	for _, stmt := range []string{
+		`UPDATE t.kv SET v = 'y' WHERE k = 'a'`,
+		`DELETE FROM t.kv WHERE k = 'b'`,
+		`INSERT INTO t.kv VALUES ('c', 'y')`,
+		`ALTER TABLE t.kv ADD COLUMN w INT DEFAULT 1`,
+		`CREATE TABLE t.new (k INT PRIMARY KEY)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	// The rows are read as of the specified time, which is either a time or a
+	// number of nanoseconds since the Unix epoch. The schema of the table is
+	// also read as of that time, so the column added later is not returned.
+	expected := [][]string{
+		{"k", "v"},
+		{"a", "x"},
+		{"b", "x"},
+	}
+	for _, arg := range []interface{}{
+		before.UnixNano(),
+		before.UTC().Format("2006-01-02 15:04:05.999999999"),
+	} {
+		rows, err := db.Query(`SELECT * FROM t.kv AS OF SYSTEM TIME $1`, arg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if results := readAll(t, rows); !reflect.DeepEqual(expected, results) {
+			t.Errorf("%v: expected %s, but got %s", arg, expected, results)
+		}
+	}
+	rows, err := db.Query(`SELECT * FROM t.kv`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = [][]string{
+		{"k", "v", "w"},
+		{"a", "y", "1"},
+		{"c", "y", "1"},
+	}
+	if results := readAll(t, rows); !reflect.DeepEqual(expected, results) {
+		t.Errorf("expected %s, but got %s", expected, results)
+	}
+
+	ts := before.UnixNano()
+	for _, d := range []struct {
+		query string
+		arg   interface{}
+		err   string
+	}{
+		{`SELECT * FROM t.kv AS OF SYSTEM TIME $1`, time.Now().Add(time.Hour).UnixNano(), "is in the future"},
+		{`SELECT * FROM t.kv AS OF SYSTEM TIME $1`, "yesterday", "invalid AS OF SYSTEM TIME"},
+		{`SELECT * FROM t.kv AS OF SYSTEM TIME $1`, 0, "invalid AS OF SYSTEM TIME"},
+		{`SELECT w FROM t.kv AS OF SYSTEM TIME $1`, ts, "column \"w\" does not exist"},
+		{`SELECT * FROM t.new AS OF SYSTEM TIME $1`, ts, "table \"t.new\" does not exist"},
+		{`SELECT k FROM t.kv AS OF SYSTEM TIME $1 UNION SELECT k FROM t.kv`, ts, "must specify the same AS OF SYSTEM TIME"},
+		{`SELECT k FROM t.kv WHERE k IN (SELECT k FROM t.kv AS OF SYSTEM TIME $1)`, ts, "cannot be used in a subquery"},
+	} {
+		if _, err := db.Query(d.query, d.arg); !isError(err, d.err) {
+			t.Errorf("%s: expected %s, but found %v", d.query, d.err, err)
+		}
+	}
+	if _, err := db.Exec(`CREATE VIEW t.v AS SELECT k FROM t.kv AS OF SYSTEM TIME 1`); !isError(err, "cannot be used in a view") {
+		t.Errorf("expected failure, but found %v", err)
+	}
+
+	// A historical read cannot observe the writes of a transaction.
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Query(`SELECT * FROM t.kv AS OF SYSTEM TIME $1`, ts); !isError(err, "cannot be used within a transaction") {
+		t.Errorf("expected failure, but found %v", err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -20,6 +20,9 @@ package driver
 import (
 	"database/sql/driver"
 	"io"
+	"time"
+
+	"github.com/cockroachdb/cockroach/sql/sqlwire"
 )
 
 type row []driver.Value
@@ -30,19 +33,31 @@ type rows struct {
 	pos     int // Current iteration index into rows.
 }
 
-// newSingleColumnRows returns a rows structure initialized with a single
-// column of values using the specified column name and values. This is a
-// convenience routine used by operations which return only a single column.
-func newSingleColumnRows(column string, vals []string) *rows {
-	r := make([]row, len(vals))
-	for i, v := range vals {
-		r[i] = row{v}
-	}
-	return &rows{
-		columns: []string{column},
-		rows:    r,
+// newRows converts the result set of the response into a rows object. Times
+// are converted to the location, unless it is nil.
+func newRows(resp *sqlwire.SQLResponse, loc *time.Location) (*rows, error) {
+	r := &rows{
+		columns: resp.Columns,
+		rows:    make([]row, len(resp.Results)),
 		pos:     -1,
 	}
+	for i, result := range resp.Results {
+		r.rows[i] = make(row, len(result.Values))
+		for j, d := range result.Values {
+			if err := d.VerifyChecksum(); err != nil {
+				return nil, err
+			}
+			v, err := d.Value()
+			if err != nil {
+				return nil, err
+			}
+			if t, ok := v.(time.Time); ok && loc != nil && d.Timeval != nil {
+				v = t.In(loc)
+			}
+			r.rows[i][j] = v
+		}
+	}
+	return r, nil
 }
 
 func (r *rows) Columns() []string {
@@ -0,0 +1,137 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package driver
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/cockroachdb/cockroach/base"
+	"github.com/cockroachdb/cockroach/sql/sqlwire"
+	"github.com/cockroachdb/cockroach/util"
+	"github.com/cockroachdb/cockroach/util/retry"
+	gogoproto "github.com/gogo/protobuf/proto"
+)
+
+// defaultRetryOptions sets the retry options for handling retryable errors and
+// connection I/O errors. The attempts are bounded, giving up after about 20
+// seconds, so that a retry reaches the server while it still retains the
+// response of the request in its response cache.
+var defaultRetryOptions = retry.Options{
+	Backoff:     50 * time.Millisecond,
+	MaxBackoff:  5 * time.Second,
+	Constant:    2,
+	MaxAttempts: 10,
+	UseV1Info:   true,
+}
+
+// sender sends a SQL request to a Cockroach node and unmarshals its reply
+// into resp.
+type sender interface {
+	send(req *sqlwire.SQLRequest, resp *sqlwire.SQLResponse) error
+}
+
+// httpSender sends SQL requests to a Cockroach node via HTTP.
+type httpSender struct {
+	server  string        // The host:port address of the Cockroach gateway node
+	client  *http.Client  // The HTTP client
+	context *base.Context // The base context: needed for client setup.
+}
+
+// newHTTPSender returns a new instance of httpSender.
+func newHTTPSender(server string, ctx *base.Context) (*httpSender, error) {
+	client, err := ctx.GetHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	return &httpSender{
+		server:  server,
+		client:  client,
+		context: ctx,
+	}, nil
+}
+
+// send posts the request using the HTTP client. The request is
+// protobuf-serialized and written as the POST body. HTTP response codes which
+// are retryable and errors sending the request are retried with backoff. The
+// retried request carries the same client command ID, so the server replies
+// with the cached response if the request was executed. Once the retry
+// attempts are exhausted, the last error is returned.
+//
+// On success, the response body is unmarshalled into resp.
+func (s *httpSender) send(req *sqlwire.SQLRequest, resp *sqlwire.SQLResponse) error {
+	retryOpts := defaultRetryOptions
+	retryOpts.Tag = fmt.Sprintf("%s %s", s.context.RequestScheme(), req.Method())
+
+	// Marshal the args into a request body.
+	body, err := gogoproto.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	url := s.context.RequestScheme() + "://" + s.server + sqlwire.Endpoint + req.Method().String()
+
+	var lastErr error
+	err = retry.WithBackoff(retryOpts, func() (retry.Status, error) {
+		httpReq, err := http.NewRequest("POST", url, bytes.NewReader(body))
+		if err != nil {
+			return retry.Break, err
+		}
+		httpReq.Header.Add(util.ContentTypeHeader, util.ProtoContentType)
+		httpReq.Header.Add(util.AcceptHeader, util.ProtoContentType)
+
+		httpResp, err := s.client.Do(httpReq)
+		if err != nil {
+			lastErr = err
+			return retry.Continue, err
+		}
+
+		defer httpResp.Body.Close()
+
+		switch httpResp.StatusCode {
+		case http.StatusOK:
+			// We're cool.
+		case http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			// Retry on service unavailable and request timeout.
+			lastErr = errors.New(httpResp.Status)
+			return retry.Continue, lastErr
+		default:
+			// Can't recover from all other errors.
+			return retry.Break, errors.New(httpResp.Status)
+		}
+
+		b, err := ioutil.ReadAll(httpResp.Body)
+		if err != nil {
+			lastErr = err
+			return retry.Continue, err
+		}
+
+		if err := gogoproto.Unmarshal(b, resp); err != nil {
+			lastErr = err
+			return retry.Continue, err
+		}
+
+		return retry.Break, nil
+	})
+	if _, ok := err.(*retry.MaxAttemptsError); ok {
+		return util.Errorf("%s: %s", err, lastErr)
+	}
+	return err
+}
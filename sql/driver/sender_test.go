@@ -0,0 +1,64 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+//
+
+package driver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/sql/sqlwire"
+	"github.com/cockroachdb/cockroach/testutils"
+	"github.com/cockroachdb/cockroach/util/leaktest"
+	"github.com/cockroachdb/cockroach/util/retry"
+)
+
+// TestSenderRetryLimit verifies that a request which keeps failing with a
+// retryable error is not retried indefinitely.
+func TestSenderRetryLimit(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	defer func(opts retry.Options) { defaultRetryOptions = opts }(defaultRetryOptions)
+	defaultRetryOptions.Backoff = time.Millisecond
+	defaultRetryOptions.MaxBackoff = time.Millisecond
+
+	var count int32
+	httpServer := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&count, 1)
+		http.Error(w, "manufactured error", http.StatusServiceUnavailable)
+	}))
+	tlsConfig, err := testutils.NewNodeTestBaseContext().GetServerTLSConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpServer.TLS = tlsConfig
+	httpServer.StartTLS()
+	defer httpServer.Close()
+
+	s, err := newHTTPSender(httpServer.Listener.Addr().String(), testutils.NewRootTestBaseContext())
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &sqlwire.SQLRequest{}
+	if err := s.send(req, &sqlwire.SQLResponse{}); !testutils.IsError(err, "503 Service Unavailable") {
+		t.Fatalf("expected the request to fail, but got %v", err)
+	}
+	if c := atomic.LoadInt32(&count); c != int32(defaultRetryOptions.MaxAttempts) {
+		t.Errorf("expected %d attempts, but found %d", defaultRetryOptions.MaxAttempts, c)
+	}
+}
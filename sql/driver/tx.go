@@ -17,16 +17,16 @@
 
 package driver
 
-import "fmt"
-
 type tx struct {
 	conn *conn
 }
 
 func (t *tx) Commit() error {
-	return fmt.Errorf("TODO(pmattis): unimplemented")
+	_, err := t.conn.send("COMMIT TRANSACTION", nil)
+	return err
 }
 
 func (t *tx) Rollback() error {
-	return fmt.Errorf("TODO(pmattis): unimplemented")
+	_, err := t.conn.send("ROLLBACK TRANSACTION", nil)
+	return err
 }
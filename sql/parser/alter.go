@@ -22,20 +22,66 @@ import "fmt"
 func (*AlterTable) statement() {}
 func (*AlterView) statement()  {}
 
-// AlterTable represents a ALTER TABLE statement.
+// AlterTable represents an ALTER TABLE statement.
 type AlterTable struct {
-	Name string
+	Name *TableName
+	Cmd  AlterTableCmd
 }
 
 func (node *AlterTable) String() string {
-	return fmt.Sprintf("ALTER TABLE %s", node.Name)
+	return fmt.Sprintf("ALTER TABLE %s %s", node.Name, node.Cmd)
 }
 
-// AlterView represents a ALTER VIEW statement.
-type AlterView struct {
+// AlterTableCmd represents a table modification operation within an ALTER
+// TABLE statement.
+type AlterTableCmd interface {
+	fmt.Stringer
+	// Placeholder function to ensure that only desired types
+	// (AlterTable*) conform to the AlterTableCmd interface.
+	alterTableCmd()
+}
+
+func (*AlterTableAddColumn) alterTableCmd()    {}
+func (*AlterTableDropColumn) alterTableCmd()   {}
+func (*AlterTableRenameColumn) alterTableCmd() {}
+
+// AlterTableAddColumn represents an ADD COLUMN command.
+type AlterTableAddColumn struct {
+	Column *ColumnTableDef
+}
+
+func (node *AlterTableAddColumn) String() string {
+	return fmt.Sprintf("ADD COLUMN %s", node.Column)
+}
+
+// AlterTableDropColumn represents a DROP COLUMN command.
+type AlterTableDropColumn struct {
 	Name string
 }
 
+func (node *AlterTableDropColumn) String() string {
+	return fmt.Sprintf("DROP COLUMN %s", node.Name)
+}
+
+// AlterTableRenameColumn represents a RENAME COLUMN command.
+type AlterTableRenameColumn struct {
+	Name    string
+	NewName string
+}
+
+func (node *AlterTableRenameColumn) String() string {
+	return fmt.Sprintf("RENAME COLUMN %s TO %s", node.Name, node.NewName)
+}
+
+// AlterView represents an ALTER VIEW statement, which replaces the select
+// statement of the view.
+type AlterView struct {
+	Name    *TableName
+	Columns []string
+	Select  SelectStatement
+}
+
 func (node *AlterView) String() string {
-	return fmt.Sprintf("ALTER VIEW %s", node.Name)
+	return fmt.Sprintf("ALTER VIEW %s%s AS %v",
+		node.Name, viewColumnsString(node.Columns), node.Select)
 }
@@ -24,11 +24,37 @@ import (
 // Parse parses the sql and returns a Statement, which is the AST
 // representation of the query.
 func Parse(sql string) (Statement, error) {
+	stmt, _, err := ParseWithNumArgs(sql)
+	return stmt, err
+}
+
+// ParseWithNumArgs parses the sql and returns the statement along with the
+// number of positional arguments ("?" or "$n") referenced by it. An argument
+// "$n" references the first n arguments.
+func ParseWithNumArgs(sql string) (Statement, int, error) {
 	tokenizer := newStringTokenizer(sql)
 	if yyParse(tokenizer) != 0 {
-		return nil, errors.New(tokenizer.lastError)
+		return nil, 0, errors.New(tokenizer.lastError)
 	}
-	return tokenizer.parseTree, nil
+	return tokenizer.parseTree, tokenizer.numArgs, nil
+}
+
+// ParseExpr parses a SQL scalar expression such as the DEFAULT expression of
+// a column.
+func ParseExpr(expr string) (Expr, error) {
+	// The grammar only accepts expressions within statements, so parse the
+	// expression as the sole target of a SELECT.
+	stmt, err := Parse(fmt.Sprintf("SELECT %s FROM t", expr))
+	if err != nil {
+		return nil, err
+	}
+	if sel, ok := stmt.(*Select); ok && len(sel.Exprs) == 1 {
+		if e, ok := sel.Exprs[0].(*NonStarExpr); ok && e.As == "" &&
+			sel.String() == fmt.Sprintf("SELECT %s FROM t", e.Expr) {
+			return e.Expr, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid expression: %s", expr)
 }
 
 // Statement represents a statement.
@@ -61,6 +87,7 @@ type Select struct {
 	Distinct string
 	Exprs    SelectExprs
 	From     TableExprs
+	AsOf     *AsOf
 	Where    *Where
 	GroupBy  GroupBy
 	Having   *Where
@@ -81,9 +108,9 @@ const (
 )
 
 func (node *Select) String() string {
-	return fmt.Sprintf("SELECT %v%s%v FROM %v%v%v%v%v%v%s",
+	return fmt.Sprintf("SELECT %v%s%v FROM %v%v%v%v%v%v%v%s",
 		node.Comments, node.Distinct, node.Exprs,
-		node.From, node.Where,
+		node.From, node.AsOf, node.Where,
 		node.GroupBy, node.Having, node.OrderBy,
 		node.Limit, node.Lock)
 }
@@ -877,6 +904,19 @@ func (node *Limit) String() string {
 	return buf.String()
 }
 
+// AsOf represents an AS OF SYSTEM TIME clause, which specifies the
+// timestamp at which the tables of a SELECT statement are read.
+type AsOf struct {
+	Expr ValExpr
+}
+
+func (node *AsOf) String() string {
+	if node == nil {
+		return ""
+	}
+	return fmt.Sprintf(" AS OF SYSTEM TIME %v", node.Expr)
+}
+
 // UpdateExprs represents a list of update expressions.
 type UpdateExprs []*UpdateExpr
 
@@ -1,8 +1,9 @@
 CREATE TABLE a (b INT)
-CREATE INDEX b ON a
-ALTER TABLE A foo#ALTER TABLE A
-ALTER TABLE A rename to B#RENAME TABLE A B
-RENAME TABLE A to B#RENAME TABLE A B
+CREATE INDEX B ON A (C)#CREATE INDEX b ON a (c)
+ALTER TABLE A ADD COLUMN B INT#ALTER TABLE a ADD COLUMN b INT
+ALTER TABLE A rename to B#RENAME TABLE a TO b
+ALTER TABLE A RENAME COLUMN B TO C#ALTER TABLE a RENAME COLUMN b TO c
+RENAME TABLE A to B#RENAME TABLE a TO b
 DROP TABLE b
 DROP INDEX b ON A#DROP INDEX b ON a
 SELECT a FROM B
@@ -21,6 +22,6 @@ SELECT values(B, C) FROM b#SELECT VALUES(b, c) FROM b
 SELECT * FROM b USE INDEX (A)#SELECT * FROM b USE INDEX (a)
 INSERT INTO A(A, B) VALUES (1, 2)#INSERT INTO A(a, b) VALUES (1, 2)
 create table A (b int)#CREATE TABLE a (b INT)
-CREATE VIEW A#CREATE VIEW a
-ALTER VIEW A#ALTER VIEW a
+CREATE VIEW A (B) AS SELECT C FROM D#CREATE VIEW a (b) AS SELECT c FROM D
+ALTER VIEW A (B) AS SELECT C FROM D#ALTER VIEW a (b) AS SELECT c FROM D
 DROP VIEW A#DROP VIEW a
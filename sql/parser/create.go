@@ -46,9 +46,10 @@ func (node *CreateDatabase) String() string {
 
 // CreateIndex represents a CREATE INDEX statement.
 type CreateIndex struct {
-	Name      string
-	TableName string
-	Unique    bool
+	Name    string
+	Table   *TableName
+	Unique  bool
+	Columns []string
 }
 
 func (node *CreateIndex) String() string {
@@ -57,7 +58,8 @@ func (node *CreateIndex) String() string {
 	if node.Unique {
 		buf.WriteString("UNIQUE ")
 	}
-	fmt.Fprintf(&buf, "INDEX %s ON %s", node.Name, node.TableName)
+	fmt.Fprintf(&buf, "INDEX %s ON %s (%s)",
+		node.Name, node.Table, strings.Join(node.Columns, ", "))
 	return buf.String()
 }
 
@@ -102,6 +104,7 @@ type ColumnTableDef struct {
 	Name       string
 	Type       ColumnType
 	Nullable   Nullability
+	Default    ValExpr
 	PrimaryKey bool
 	Unique     bool
 }
@@ -115,6 +118,9 @@ func (node *ColumnTableDef) String() string {
 	case NotNull:
 		buf.WriteString(" NOT NULL")
 	}
+	if node.Default != nil {
+		fmt.Fprintf(&buf, " DEFAULT %s", node.Default)
+	}
 	if node.PrimaryKey {
 		buf.WriteString(" PRIMARY KEY")
 	} else if node.Unique {
@@ -163,11 +169,22 @@ func (node *CreateTable) String() string {
 	return buf.String()
 }
 
-// CreateView represents a CREATE VIEW statement.
+// CreateView represents a CREATE VIEW statement. The optional column names
+// rename the columns of the select statement.
 type CreateView struct {
-	Name string
+	Name    *TableName
+	Columns []string
+	Select  SelectStatement
 }
 
 func (node *CreateView) String() string {
-	return fmt.Sprintf("CREATE VIEW %s", node.Name)
+	return fmt.Sprintf("CREATE VIEW %s%s AS %v",
+		node.Name, viewColumnsString(node.Columns), node.Select)
+}
+
+func viewColumnsString(columns []string) string {
+	if len(columns) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%s)", strings.Join(columns, ", "))
 }
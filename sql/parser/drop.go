@@ -45,17 +45,17 @@ func (node *DropDatabase) String() string {
 
 // DropIndex represents a DROP INDEX statement.
 type DropIndex struct {
-	Name      string
-	TableName string
+	Name  string
+	Table *TableName
 }
 
 func (node *DropIndex) String() string {
-	return fmt.Sprintf("DROP INDEX %s ON %s", node.Name, node.TableName)
+	return fmt.Sprintf("DROP INDEX %s ON %s", node.Name, node.Table)
 }
 
 // DropTable represents a DROP TABLE statement.
 type DropTable struct {
-	Name     string
+	Name     *TableName
 	IfExists bool
 }
 
@@ -65,13 +65,13 @@ func (node *DropTable) String() string {
 	if node.IfExists {
 		buf.WriteString("IF EXISTS ")
 	}
-	buf.WriteString(node.Name)
+	fmt.Fprintf(&buf, "%s", node.Name)
 	return buf.String()
 }
 
-// DropView represents a DROP TABLE statement.
+// DropView represents a DROP VIEW statement.
 type DropView struct {
-	Name     string
+	Name     *TableName
 	IfExists bool
 }
 
@@ -81,6 +81,6 @@ func (node *DropView) String() string {
 	if node.IfExists {
 		buf.WriteString("IF EXISTS ")
 	}
-	buf.WriteString(node.Name)
+	fmt.Fprintf(&buf, "%s", node.Name)
 	return buf.String()
 }
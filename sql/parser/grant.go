@@ -0,0 +1,72 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+func (*Grant) statement()  {}
+func (*Revoke) statement() {}
+
+// TargetList represents the databases or the tables on which privileges are
+// granted or revoked.
+type TargetList struct {
+	Databases []string
+	Tables    []*TableName
+}
+
+func (tl TargetList) String() string {
+	if tl.Databases != nil {
+		return "DATABASE " + strings.Join(tl.Databases, ", ")
+	}
+	var buf bytes.Buffer
+	buf.WriteString("TABLE ")
+	for i, t := range tl.Tables {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		fmt.Fprintf(&buf, "%s", t)
+	}
+	return buf.String()
+}
+
+// Grant represents a GRANT statement. The privileges are named by their
+// keywords, e.g. "SELECT" or "ALL".
+type Grant struct {
+	Privileges []string
+	Targets    TargetList
+	Grantees   []string
+}
+
+func (node *Grant) String() string {
+	return fmt.Sprintf("GRANT %s ON %s TO %s", strings.Join(node.Privileges, ", "),
+		node.Targets, strings.Join(node.Grantees, ", "))
+}
+
+// Revoke represents a REVOKE statement.
+type Revoke struct {
+	Privileges []string
+	Targets    TargetList
+	Grantees   []string
+}
+
+func (node *Revoke) String() string {
+	return fmt.Sprintf("REVOKE %s ON %s FROM %s", strings.Join(node.Privileges, ", "),
+		node.Targets, strings.Join(node.Grantees, ", "))
+}
@@ -0,0 +1,31 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package parser
+
+import "fmt"
+
+func (*Import) statement() {}
+
+// Import represents an IMPORT TABLE statement, which loads the rows of a CSV
+// file into a table.
+type Import struct {
+	Table *TableName
+	Path  string
+}
+
+func (node *Import) String() string {
+	return fmt.Sprintf("IMPORT TABLE %s FROM CSV %s", node.Table, StrVal(node.Path))
+}
@@ -9,3 +9,10 @@ CREATE TABLE a (b INT2)#syntax error at position 23 near INT2
 CREATE TABLE a (b INT UNIQUE NULL)#syntax error at position 34 near NULL
 CREATE TABLE a (b INT UNIQUE NOT NULL)#syntax error at position 33 near NOT
 CREATE TABLE a (b INT UNIQUE PRIMARY KEY)#syntax error at position 37 near PRIMARY
+SELECT $ FROM t#syntax error at position 9 near $
+SELECT $0 FROM t#syntax error at position 10 near $0
+EXPLAIN INSERT INTO a VALUES (1)#syntax error at position 15 near INSERT
+GRANT TRUNCATE ON a TO b#syntax error at position 15 near TRUNCATE
+IMPORT TABLE a FROM CSV b#syntax error at position 26 near b
+SELECT 1 FROM t AS OF TIME 1#syntax error at position 27 near TIME
+SELECT 1 FROM t AS OF SYSTEM 1#syntax error at position 31 near 1
@@ -56,6 +56,7 @@ SELECT /* NATURAL JOIN */ 1 FROM t1 NATURAL JOIN t2
 SELECT /* JOIN ON */ 1 FROM t1 JOIN t2 ON a = b
 SELECT /* JOIN USING */ 1 FROM t1 JOIN t2 USING (a)
 SELECT /* s.t */ 1 FROM s.t
+SELECT /* keyword after period */ t.columns FROM information_schema.tables AS t
 SELECT /* SELECT IN FROM */ 1 FROM (SELECT 1 FROM t)
 SELECT /* WHERE */ 1 FROM t WHERE a = b
 SELECT /* AND */ 1 FROM t WHERE a = b AND a = c
@@ -118,6 +119,8 @@ SELECT /* value argument */ :a FROM t
 SELECT /* value argument with dot */ :a.b FROM t
 SELECT /* positional argument */ ? FROM t#SELECT /* positional argument */ :v1 FROM t
 SELECT /* multiple positional arguments */ ?, ? FROM t#SELECT /* multiple positional arguments */ :v1, :v2 FROM t
+SELECT /* numbered positional arguments */ $2, $1 FROM t#SELECT /* numbered positional arguments */ :v2, :v1 FROM t
+SELECT /* trailing semicolon */ a FROM t;#SELECT /* trailing semicolon */ a FROM t
 SELECT /* NULL */ NULL FROM t
 SELECT /* octal */ 010 FROM t
 SELECT /* hex */ 0xf0 FROM t
@@ -131,6 +134,10 @@ SELECT /* ORDER BY DESC */ 1 FROM t ORDER BY a DESC
 SELECT /* LIMIT a */ 1 FROM t LIMIT a
 SELECT /* LIMIT a,b */ 1 FROM t LIMIT a, b
 SELECT /* LIMIT a OFFSET b */ 1 FROM t LIMIT a OFFSET b#SELECT /* LIMIT a OFFSET b */ 1 FROM t LIMIT b, a
+SELECT /* AS OF SYSTEM TIME */ 1 FROM t AS OF SYSTEM TIME '2015-08-01 12:00:00'
+SELECT /* AS OF SYSTEM TIME lowercase */ 1 FROM t as of system time 1438430400000000000#SELECT /* AS OF SYSTEM TIME lowercase */ 1 FROM t AS OF SYSTEM TIME 1438430400000000000
+SELECT /* AS OF SYSTEM TIME alias */ 1 FROM t AS u AS OF SYSTEM TIME $1 WHERE a = 1 LIMIT 1#SELECT /* AS OF SYSTEM TIME alias */ 1 FROM t AS u AS OF SYSTEM TIME :v1 WHERE a = 1 LIMIT 1
+SELECT /* AS OF SYSTEM TIME join */ 1 FROM t, u AS OF SYSTEM TIME '2015-08-01' UNION SELECT 1 FROM v AS OF SYSTEM TIME '2015-08-01'
 INSERT /* simple */ INTO a VALUES (1)
 INSERT /* a.b */ INTO a.b VALUES (1)
 INSERT /* multi-value */ INTO a VALUES (1, 2)
@@ -156,39 +163,53 @@ DELETE /* ORDER */ FROM a ORDER BY b DESC
 DELETE /* LIMIT */ FROM a LIMIT b
 SET /* simple */ a = 3
 SET /* list */ a = 3, b = 4
+SET time_zone = 'UTC'
+SET database = a
 USE /* list */ a
-ALTER IGNORE TABLE a ADD foo#ALTER TABLE a
-ALTER TABLE a ADD foo#ALTER TABLE a
-ALTER TABLE a ALTER foo#ALTER TABLE a
-ALTER TABLE a CHANGE foo#ALTER TABLE a
-ALTER TABLE a MODIFY foo#ALTER TABLE a
-ALTER TABLE a DROP foo#ALTER TABLE a
-ALTER TABLE a DISABLE foo#ALTER TABLE a
-ALTER TABLE a ENABLE foo#ALTER TABLE a
-ALTER TABLE a ORDER foo#ALTER TABLE a
-ALTER TABLE a DEFAULT foo#ALTER TABLE a
-ALTER TABLE a DISCARD foo#ALTER TABLE a
-ALTER TABLE a IMPORT foo#ALTER TABLE a
-ALTER TABLE a RENAME b#RENAME TABLE a b
-ALTER TABLE a RENAME to b#RENAME TABLE a b
+ALTER IGNORE TABLE a ADD foo INT#ALTER TABLE a ADD COLUMN foo INT
+ALTER TABLE a ADD COLUMN foo INT
+ALTER TABLE a.b ADD COLUMN foo INT NOT NULL DEFAULT 1
+ALTER TABLE a ADD COLUMN foo CHAR DEFAULT 'bar' UNIQUE
+ALTER TABLE a DROP foo#ALTER TABLE a DROP COLUMN foo
+ALTER TABLE a DROP COLUMN foo
+ALTER TABLE a RENAME COLUMN foo TO bar
+ALTER TABLE a RENAME b#RENAME TABLE a TO b
+ALTER TABLE a RENAME to b#RENAME TABLE a TO b
+ALTER TABLE a.b RENAME TO c.d#RENAME TABLE a.b TO c.d
+RENAME TABLE a TO b
 CREATE DATABASE a
 CREATE DATABASE IF NOT EXISTS a
 CREATE TABLE a (b INT)
+CREATE TABLE a (b INT NOT NULL DEFAULT 1+2 PRIMARY KEY, c CHAR NULL DEFAULT 'd')
+CREATE TABLE a (b SERIAL PRIMARY KEY, c INT DEFAULT unique_rowid())#CREATE TABLE a (b SERIAL PRIMARY KEY, c INT DEFAULT UNIQUE_ROWID())
 CREATE TABLE a.b (b INT)
 CREATE TABLE IF NOT EXISTS a (b INT)
-CREATE INDEX a ON b
-CREATE UNIQUE INDEX a ON b
-CREATE UNIQUE INDEX a using foo ON b#CREATE UNIQUE INDEX a ON b
-CREATE VIEW a#CREATE VIEW a
-ALTER VIEW a#ALTER VIEW a
+CREATE INDEX a ON b (c)
+CREATE UNIQUE INDEX a ON b (c, d)
+CREATE UNIQUE INDEX a using foo ON b.c (d)#CREATE UNIQUE INDEX a ON b.c (d)
+CREATE VIEW a AS SELECT b FROM c
+CREATE VIEW a.b AS SELECT c, d FROM e.f WHERE c > 1
+CREATE VIEW a (b, c) AS SELECT d, e FROM f
+CREATE VIEW a AS SELECT b FROM c UNION SELECT d FROM e
+ALTER VIEW a AS SELECT b FROM c
+ALTER VIEW a.b (c) AS SELECT d FROM e
 DROP DATABASE a
 DROP DATABASE IF EXISTS a
 DROP VIEW a
 DROP VIEW IF EXISTS a
+DROP VIEW a.b
+DROP VIEW IF EXISTS a.b
 DROP TABLE a
 DROP TABLE IF EXISTS a
+DROP TABLE a.b
+DROP TABLE IF EXISTS a.b
 DROP INDEX b ON a
+DROP INDEX b ON a.c
 TRUNCATE TABLE a
+TRUNCATE TABLE a.b
+SHOW a
+SHOW database
+SHOW all
 SHOW DATABASES
 SHOW TABLES
 SHOW TABLES FROM a
@@ -198,6 +219,11 @@ SHOW FULL COLUMNS FROM a
 SHOW FULL COLUMNS FROM a.b
 SHOW INDEX FROM a
 SHOW INDEX FROM a.b
+SHOW CREATE TABLE a
+SHOW CREATE TABLE a.b
+EXPLAIN SELECT a FROM b WHERE c = 1
+EXPLAIN UPDATE a SET b = 1 WHERE c > 2
+EXPLAIN DELETE FROM a WHERE b = 1
 CREATE TABLE a (b INT, c TEXT, PRIMARY KEY (b, c))
 CREATE TABLE a (b INT, c TEXT, INDEX d (b, c))
 CREATE TABLE a (b INT, UNIQUE INDEX c (b))
@@ -208,3 +234,19 @@ CREATE TABLE a (b INT PRIMARY KEY)
 CREATE TABLE a (b INT NULL)
 CREATE TABLE a (b INT NOT NULL)
 CREATE TABLE a (b INT NULL PRIMARY KEY)
+BEGIN#BEGIN TRANSACTION
+BEGIN TRANSACTION
+START TRANSACTION#BEGIN TRANSACTION
+COMMIT#COMMIT TRANSACTION
+COMMIT TRANSACTION
+ROLLBACK#ROLLBACK TRANSACTION
+ROLLBACK TRANSACTION
+GRANT SELECT ON a TO b#GRANT SELECT ON TABLE a TO b
+GRANT SELECT, INSERT ON TABLE a.b, c TO d, e
+GRANT ALL ON DATABASE a TO b
+GRANT UPDATE, DELETE ON DATABASE a, b TO c
+REVOKE ALL ON TABLE a.b FROM c
+REVOKE SELECT ON a FROM b, c#REVOKE SELECT ON TABLE a FROM b, c
+REVOKE INSERT ON DATABASE a FROM b
+IMPORT TABLE a FROM CSV '/tmp/a.csv'
+IMPORT TABLE a.b FROM CSV 'b.csv'
@@ -79,3 +79,54 @@ func TestParse(t *testing.T) {
 		}
 	}
 }
+
+func TestParseExpr(t *testing.T) {
+	testData := []struct {
+		expr     string
+		expected string
+	}{
+		{`1`, `1`},
+		{`'foo'`, `'foo'`},
+		{`1 + a`, `1+a`},
+		{`NULL`, `NULL`},
+		{`a AS b`, `invalid expression: a AS b`},
+		{`1, 2`, `invalid expression: 1, 2`},
+		{`*`, `invalid expression: *`},
+		{`1 +`, `syntax error at position 16 near FROM`},
+	}
+	for _, d := range testData {
+		e, err := ParseExpr(d.expr)
+		var out string
+		if err != nil {
+			out = err.Error()
+		} else {
+			out = fmt.Sprint(e)
+		}
+		if out != d.expected {
+			t.Errorf("%s: expected %q, but found %q", d.expr, d.expected, out)
+		}
+	}
+}
+
+func TestParseWithNumArgs(t *testing.T) {
+	testData := []struct {
+		sql     string
+		numArgs int
+	}{
+		{`SELECT a FROM t`, 0},
+		{`SELECT a FROM t WHERE a = ?`, 1},
+		{`SELECT ?, ? FROM t WHERE a = ?`, 3},
+		{`SELECT $1 FROM t WHERE a = $1`, 1},
+		{`SELECT a FROM t WHERE a = $3 AND b = $1`, 3},
+		{`INSERT INTO t VALUES (:a, ?)`, 1},
+	}
+	for _, d := range testData {
+		_, numArgs, err := ParseWithNumArgs(d.sql)
+		if err != nil {
+			t.Fatalf("%s: %s", d.sql, err)
+		}
+		if d.numArgs != numArgs {
+			t.Errorf("%s: expected %d arguments, but found %d", d.sql, d.numArgs, numArgs)
+		}
+	}
+}
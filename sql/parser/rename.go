@@ -22,11 +22,12 @@ import "fmt"
 func (*RenameTable) statement() {}
 
 // RenameTable represents a TRUNCATE TABLE statement.
+// RenameTable represents a RENAME TABLE statement.
 type RenameTable struct {
-	Name    string
-	NewName string
+	Name    *TableName
+	NewName *TableName
 }
 
 func (node *RenameTable) String() string {
-	return fmt.Sprintf("RENAME TABLE %s %s", node.Name, node.NewName)
+	return fmt.Sprintf("RENAME TABLE %s TO %s", node.Name, node.NewName)
 }
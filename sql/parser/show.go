@@ -22,10 +22,22 @@ import (
 	"fmt"
 )
 
-func (*ShowColumns) statement()   {}
-func (*ShowDatabases) statement() {}
-func (*ShowIndex) statement()     {}
-func (*ShowTables) statement()    {}
+func (*Show) statement()            {}
+func (*ShowColumns) statement()     {}
+func (*ShowCreateTable) statement() {}
+func (*ShowDatabases) statement()   {}
+func (*ShowIndex) statement()       {}
+func (*ShowTables) statement()      {}
+
+// Show represents a SHOW statement of a session variable. The name is "all"
+// for SHOW ALL.
+type Show struct {
+	Name string
+}
+
+func (node *Show) String() string {
+	return fmt.Sprintf("SHOW %s", node.Name)
+}
 
 // ShowColumns represents a SHOW [FULL] COLUMNS statement.
 type ShowColumns struct {
@@ -43,6 +55,15 @@ func (node *ShowColumns) String() string {
 	return buf.String()
 }
 
+// ShowCreateTable represents a SHOW CREATE TABLE statement.
+type ShowCreateTable struct {
+	Name *TableName
+}
+
+func (node *ShowCreateTable) String() string {
+	return fmt.Sprintf("SHOW CREATE TABLE %s", node.Name)
+}
+
 // ShowDatabases represents a SHOW DATABASES statement.
 type ShowDatabases struct {
 }
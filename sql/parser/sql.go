@@ -48,6 +48,8 @@ type yySymType struct {
 	tableExpr   TableExpr
 	smTableExpr SimpleTableExpr
 	tableName   *TableName
+	tableNames  []*TableName
+	targetList  TargetList
 	indexHints  *IndexHints
 	expr        Expr
 	boolExpr    BoolExpr
@@ -62,11 +64,14 @@ type yySymType struct {
 	orderBy     OrderBy
 	order       *Order
 	limit       *Limit
+	asOf        *AsOf
 	insRows     InsertRows
 	updateExprs UpdateExprs
 	updateExpr  *UpdateExpr
 	tableDefs   TableDefs
 	tableDef    TableDef
+	columnDef   *ColumnTableDef
+	alterCmd    AlterTableCmd
 	columnType  ColumnType
 	intVal      int
 	intVal2     [2]int
@@ -111,83 +116,97 @@ const tokSmallInt = 57380
 const tokMediumInt = 57381
 const tokBigInt = 57382
 const tokInteger = 57383
-const tokReal = 57384
-const tokDouble = 57385
-const tokFloat = 57386
-const tokDecimal = 57387
-const tokNumeric = 57388
-const tokDate = 57389
-const tokTime = 57390
-const tokDateTime = 57391
-const tokTimestamp = 57392
-const tokChar = 57393
-const tokVarChar = 57394
-const tokBinary = 57395
-const tokVarBinary = 57396
-const tokText = 57397
-const tokTinyText = 57398
-const tokMediumText = 57399
-const tokLongText = 57400
-const tokBlob = 57401
-const tokTinyBlob = 57402
-const tokMediumBlob = 57403
-const tokLongBlob = 57404
-const tokBit = 57405
-const tokEnum = 57406
-const tokID = 57407
-const tokString = 57408
-const tokNumber = 57409
-const tokValueArg = 57410
-const tokComment = 57411
-const tokLE = 57412
-const tokGE = 57413
-const tokNE = 57414
-const tokNullSafeEqual = 57415
-const tokUnion = 57416
-const tokMinus = 57417
-const tokExcept = 57418
-const tokIntersect = 57419
-const tokJoin = 57420
-const tokStraightJoin = 57421
-const tokLeft = 57422
-const tokRight = 57423
-const tokInner = 57424
-const tokOuter = 57425
-const tokCross = 57426
-const tokNatural = 57427
-const tokUse = 57428
-const tokForce = 57429
-const tokOn = 57430
-const tokUsing = 57431
-const tokAnd = 57432
-const tokOr = 57433
-const tokNot = 57434
-const tokUnary = 57435
-const tokCase = 57436
-const tokWhen = 57437
-const tokThen = 57438
-const tokElse = 57439
-const tokEnd = 57440
-const tokCreate = 57441
-const tokAlter = 57442
-const tokDrop = 57443
-const tokRename = 57444
-const tokTruncate = 57445
-const tokShow = 57446
-const tokDatabase = 57447
-const tokDatabases = 57448
-const tokTable = 57449
-const tokTables = 57450
-const tokIndex = 57451
-const tokView = 57452
-const tokColumns = 57453
-const tokFull = 57454
-const tokTo = 57455
-const tokIgnore = 57456
-const tokIf = 57457
-const tokUnique = 57458
-const tokUnsigned = 57459
-const tokPrimary = 57460
+const tokSerial = 57384
+const tokReal = 57385
+const tokDouble = 57386
+const tokFloat = 57387
+const tokDecimal = 57388
+const tokNumeric = 57389
+const tokDate = 57390
+const tokTime = 57391
+const tokDateTime = 57392
+const tokTimestamp = 57393
+const tokChar = 57394
+const tokVarChar = 57395
+const tokBinary = 57396
+const tokVarBinary = 57397
+const tokText = 57398
+const tokTinyText = 57399
+const tokMediumText = 57400
+const tokLongText = 57401
+const tokBlob = 57402
+const tokTinyBlob = 57403
+const tokMediumBlob = 57404
+const tokLongBlob = 57405
+const tokBit = 57406
+const tokEnum = 57407
+const tokID = 57408
+const tokString = 57409
+const tokNumber = 57410
+const tokValueArg = 57411
+const tokComment = 57412
+const tokLE = 57413
+const tokGE = 57414
+const tokNE = 57415
+const tokNullSafeEqual = 57416
+const tokUnion = 57417
+const tokMinus = 57418
+const tokExcept = 57419
+const tokIntersect = 57420
+const tokJoin = 57421
+const tokStraightJoin = 57422
+const tokLeft = 57423
+const tokRight = 57424
+const tokInner = 57425
+const tokOuter = 57426
+const tokCross = 57427
+const tokNatural = 57428
+const tokUse = 57429
+const tokForce = 57430
+const tokOn = 57431
+const tokUsing = 57432
+const tokAnd = 57433
+const tokOr = 57434
+const tokNot = 57435
+const tokUnary = 57436
+const tokCase = 57437
+const tokWhen = 57438
+const tokThen = 57439
+const tokElse = 57440
+const tokEnd = 57441
+const tokCreate = 57442
+const tokAlter = 57443
+const tokAdd = 57444
+const tokDrop = 57445
+const tokRename = 57446
+const tokTruncate = 57447
+const tokShow = 57448
+const tokExplain = 57449
+const tokDatabase = 57450
+const tokDatabases = 57451
+const tokTable = 57452
+const tokTables = 57453
+const tokIndex = 57454
+const tokView = 57455
+const tokColumn = 57456
+const tokColumns = 57457
+const tokFull = 57458
+const tokTo = 57459
+const tokIgnore = 57460
+const tokIf = 57461
+const tokUnique = 57462
+const tokUnsigned = 57463
+const tokPrimary = 57464
+const tokBegin = 57465
+const tokStart = 57466
+const tokTransaction = 57467
+const tokCommit = 57468
+const tokRollback = 57469
+const tokGrant = 57470
+const tokRevoke = 57471
+const tokImport = 57472
+const tokCSV = 57473
+const tokAsOf = 57474
 
 var yyToknames = []string{
 	"tokLexError",
@@ -228,6 +247,7 @@ var yyToknames = []string{
 	"tokMediumInt",
 	"tokBigInt",
 	"tokInteger",
+	"tokSerial",
 	"tokReal",
 	"tokDouble",
 	"tokFloat",
@@ -302,16 +322,19 @@ var yyToknames = []string{
 	"tokEnd",
 	"tokCreate",
 	"tokAlter",
+	"tokAdd",
 	"tokDrop",
 	"tokRename",
 	"tokTruncate",
 	"tokShow",
+	"tokExplain",
 	"tokDatabase",
 	"tokDatabases",
 	"tokTable",
 	"tokTables",
 	"tokIndex",
 	"tokView",
+	"tokColumn",
 	"tokColumns",
 	"tokFull",
 	"tokTo",
@@ -320,6 +343,16 @@ var yyToknames = []string{
 	"tokUnique",
 	"tokUnsigned",
 	"tokPrimary",
+	"tokBegin",
+	"tokStart",
+	"tokTransaction",
+	"tokCommit",
+	"tokRollback",
+	"tokGrant",
+	"tokRevoke",
+	"tokImport",
+	"tokCSV",
+	"tokAsOf",
 }
 var yyStatenames = []string{}
 
@@ -334,335 +367,386 @@ var yyExca = []int{
 	-2, 0,
 }
 
-const yyNprod = 272
+const yyNprod = 319
 const yyPrivate = 57344
 
 var yyTokenNames []string
 var yyStates []string
 
-const yyLast = 685
+const yyLast = 828
 
 var yyAct = []int{
 
-	107, 438, 392, 315, 445, 178, 67, 180, 262, 105,
-	440, 265, 138, 399, 270, 115, 104, 256, 219, 307,
-	469, 98, 131, 196, 181, 3, 93, 491, 94, 154,
-	155, 480, 469, 114, 464, 469, 120, 313, 70, 72,
-	28, 29, 30, 31, 435, 469, 441, 82, 469, 73,
-	469, 85, 58, 469, 69, 149, 90, 282, 283, 284,
-	285, 286, 319, 287, 288, 89, 99, 246, 313, 149,
-	149, 490, 71, 111, 112, 113, 130, 132, 132, 278,
-	132, 184, 137, 489, 139, 118, 488, 248, 487, 143,
-	80, 142, 146, 147, 68, 249, 483, 151, 141, 472,
-	133, 470, 135, 78, 468, 422, 382, 378, 182, 116,
-	117, 257, 183, 318, 50, 48, 121, 273, 51, 312,
-	302, 300, 421, 177, 179, 70, 187, 190, 70, 420,
-	200, 194, 358, 81, 199, 132, 84, 119, 247, 53,
-	52, 69, 383, 436, 69, 434, 215, 47, 257, 44,
-	305, 293, 46, 225, 200, 99, 201, 202, 48, 216,
-	217, 428, 229, 153, 223, 234, 235, 230, 238, 239,
-	240, 241, 242, 243, 244, 245, 227, 228, 224, 110,
-	374, 376, 129, 39, 114, 40, 41, 120, 42, 43,
-	250, 99, 99, 70, 70, 136, 57, 125, 54, 261,
-	55, 56, 268, 206, 271, 397, 236, 275, 308, 69,
-	263, 274, 252, 254, 154, 155, 260, 375, 264, 267,
-	276, 145, 198, 97, 111, 112, 113, 73, 427, 385,
-	154, 155, 102, 167, 168, 169, 118, 223, 295, 296,
-	292, 294, 279, 419, 162, 163, 164, 165, 166, 167,
-	168, 169, 127, 127, 299, 418, 101, 416, 417, 99,
-	116, 117, 95, 308, 248, 212, 226, 121, 372, 310,
-	371, 370, 197, 304, 197, 360, 314, 398, 237, 306,
-	301, 311, 368, 148, 366, 210, 467, 369, 119, 367,
-	465, 253, 15, 110, 429, 387, 223, 223, 114, 364,
-	365, 120, 15, 381, 28, 29, 30, 31, 110, 192,
-	222, 384, 128, 114, 70, 457, 120, 213, 389, 221,
-	271, 456, 390, 393, 165, 166, 167, 168, 169, 455,
-	388, 394, 454, 448, 395, 184, 401, 97, 111, 112,
-	113, 411, 409, 406, 407, 280, 102, 127, 122, 193,
-	118, 408, 71, 111, 112, 113, 400, 149, 405, 410,
-	403, 102, 222, 203, 188, 118, 186, 209, 211, 208,
-	101, 221, 185, 412, 116, 117, 95, 439, 73, 291,
-	71, 121, 152, 379, 377, 101, 423, 361, 214, 116,
-	117, 424, 195, 140, 65, 110, 121, 426, 87, 86,
-	114, 485, 119, 120, 124, 443, 251, 444, 463, 446,
-	446, 462, 446, 442, 447, 64, 449, 119, 79, 486,
-	425, 250, 359, 452, 290, 450, 386, 73, 63, 88,
-	437, 298, 461, 459, 460, 393, 451, 493, 453, 71,
-	111, 112, 113, 204, 15, 16, 17, 18, 102, 446,
-	15, 144, 118, 471, 473, 70, 446, 446, 446, 70,
-	475, 476, 477, 474, 15, 478, 83, 479, 272, 481,
-	482, 263, 101, 19, 259, 69, 116, 117, 231, 123,
-	232, 233, 126, 121, 61, 114, 91, 92, 120, 59,
-	316, 45, 415, 266, 494, 495, 157, 161, 159, 160,
-	317, 414, 32, 363, 119, 197, 134, 77, 380, 431,
-	432, 162, 163, 164, 165, 166, 167, 168, 169, 34,
-	35, 36, 37, 38, 71, 111, 112, 113, 76, 75,
-	66, 20, 492, 184, 458, 15, 33, 118, 433, 396,
-	332, 331, 330, 329, 173, 174, 175, 176, 324, 170,
-	171, 172, 323, 22, 23, 26, 24, 25, 21, 322,
-	320, 116, 117, 282, 283, 284, 285, 286, 121, 287,
-	288, 269, 158, 162, 163, 164, 165, 166, 167, 168,
-	169, 162, 163, 164, 165, 166, 167, 168, 169, 119,
-	297, 404, 402, 162, 163, 164, 165, 166, 167, 168,
-	169, 162, 163, 164, 165, 166, 167, 168, 169, 334,
-	466, 335, 336, 337, 338, 339, 340, 341, 342, 343,
-	344, 345, 325, 326, 327, 328, 346, 347, 348, 349,
-	350, 351, 352, 353, 354, 355, 356, 357, 321, 333,
-	74, 205, 277, 207, 49, 191, 484, 430, 391, 413,
-	362, 303, 189, 255, 109, 106, 108, 309, 103, 258,
-	156, 100, 373, 220, 281, 218, 96, 289, 150, 60,
-	27, 62, 14, 13, 12, 11, 10, 9, 8, 7,
-	6, 5, 4, 2, 1,
+	153, 520, 391, 329, 482, 326, 473, 265, 107, 237,
+	235, 522, 333, 161, 151, 275, 283, 334, 253, 139,
+	383, 320, 150, 346, 109, 238, 3, 211, 212, 56,
+	342, 201, 140, 577, 144, 570, 342, 342, 198, 358,
+	359, 360, 361, 362, 389, 363, 364, 342, 342, 342,
+	45, 46, 47, 48, 551, 111, 113, 342, 43, 342,
+	80, 281, 355, 206, 84, 395, 85, 389, 206, 124,
+	98, 65, 206, 124, 342, 124, 124, 110, 132, 310,
+	312, 91, 69, 197, 66, 523, 548, 68, 118, 131,
+	121, 347, 185, 494, 70, 576, 344, 126, 124, 345,
+	123, 575, 574, 459, 127, 72, 128, 129, 276, 573,
+	73, 145, 566, 557, 556, 112, 177, 124, 124, 313,
+	124, 124, 555, 184, 545, 356, 188, 124, 463, 138,
+	394, 124, 388, 378, 124, 196, 124, 376, 124, 341,
+	70, 191, 336, 208, 504, 311, 503, 502, 178, 179,
+	396, 181, 182, 122, 455, 457, 65, 119, 189, 239,
+	97, 75, 192, 240, 79, 195, 76, 74, 77, 78,
+	244, 111, 108, 464, 111, 211, 212, 258, 247, 251,
+	321, 124, 234, 236, 369, 256, 257, 266, 267, 549,
+	466, 547, 124, 110, 456, 124, 110, 321, 255, 381,
+	124, 266, 270, 271, 269, 210, 187, 58, 279, 176,
+	289, 258, 259, 200, 171, 199, 183, 145, 202, 287,
+	190, 203, 104, 273, 293, 263, 274, 298, 299, 294,
+	302, 303, 304, 305, 306, 307, 308, 309, 278, 288,
+	222, 223, 224, 225, 226, 480, 291, 292, 65, 300,
+	111, 111, 314, 145, 145, 65, 211, 212, 325, 498,
+	499, 337, 316, 318, 339, 224, 225, 226, 173, 384,
+	328, 338, 110, 327, 324, 194, 137, 266, 124, 384,
+	135, 266, 501, 500, 350, 453, 449, 290, 352, 331,
+	340, 450, 447, 452, 451, 348, 173, 448, 349, 287,
+	312, 554, 368, 539, 64, 552, 511, 371, 372, 351,
+	370, 468, 57, 59, 254, 60, 61, 342, 481, 62,
+	63, 277, 301, 375, 136, 175, 169, 174, 145, 172,
+	538, 205, 390, 286, 510, 537, 130, 377, 386, 124,
+	536, 380, 285, 438, 124, 440, 387, 337, 249, 442,
+	515, 317, 241, 156, 493, 492, 382, 445, 160, 491,
+	168, 166, 483, 488, 441, 45, 46, 47, 48, 287,
+	437, 287, 444, 443, 446, 439, 513, 514, 22, 358,
+	359, 360, 361, 362, 462, 363, 364, 486, 173, 250,
+	111, 478, 465, 470, 260, 245, 337, 477, 143, 157,
+	158, 159, 509, 471, 474, 484, 206, 148, 476, 243,
+	475, 164, 469, 489, 490, 133, 134, 242, 219, 220,
+	221, 222, 223, 224, 225, 226, 186, 521, 120, 367,
+	353, 147, 209, 65, 112, 162, 163, 141, 460, 286,
+	458, 252, 167, 105, 497, 6, 88, 579, 285, 219,
+	220, 221, 222, 223, 224, 225, 226, 170, 518, 568,
+	22, 23, 24, 25, 83, 580, 165, 567, 507, 5,
+	397, 467, 505, 103, 519, 366, 374, 506, 65, 266,
+	82, 315, 22, 508, 335, 49, 516, 96, 525, 26,
+	526, 582, 266, 266, 266, 530, 261, 524, 125, 527,
+	528, 529, 86, 87, 81, 193, 323, 272, 534, 51,
+	52, 53, 54, 55, 67, 264, 266, 101, 543, 99,
+	541, 542, 474, 544, 295, 392, 296, 297, 533, 550,
+	532, 393, 330, 535, 496, 558, 254, 111, 266, 266,
+	266, 111, 561, 560, 314, 562, 563, 564, 27, 565,
+	92, 93, 94, 95, 569, 280, 571, 156, 204, 327,
+	180, 572, 160, 110, 22, 166, 22, 559, 24, 25,
+	29, 30, 117, 33, 31, 32, 28, 34, 116, 115,
+	156, 106, 581, 583, 584, 160, 540, 22, 166, 50,
+	42, 546, 479, 35, 36, 411, 37, 38, 39, 40,
+	41, 410, 143, 157, 158, 159, 409, 408, 403, 402,
+	400, 148, 398, 461, 517, 164, 219, 220, 221, 222,
+	223, 224, 225, 226, 268, 112, 157, 158, 159, 332,
+	92, 93, 94, 95, 148, 147, 22, 156, 164, 162,
+	163, 141, 160, 89, 373, 166, 167, 219, 220, 221,
+	222, 223, 224, 225, 226, 487, 485, 160, 147, 553,
+	166, 114, 162, 163, 262, 343, 71, 248, 578, 167,
+	165, 219, 220, 221, 222, 223, 224, 225, 226, 354,
+	512, 472, 112, 157, 158, 159, 531, 495, 379, 160,
+	246, 148, 166, 165, 319, 164, 155, 112, 157, 158,
+	159, 152, 214, 218, 216, 217, 241, 154, 385, 149,
+	164, 322, 213, 146, 454, 147, 90, 284, 357, 162,
+	163, 282, 142, 365, 207, 100, 167, 44, 102, 112,
+	157, 158, 159, 21, 162, 163, 20, 19, 241, 18,
+	17, 167, 164, 16, 15, 14, 13, 12, 11, 10,
+	165, 230, 231, 232, 233, 9, 227, 228, 229, 8,
+	7, 4, 2, 1, 0, 165, 162, 163, 0, 0,
+	0, 0, 0, 167, 0, 0, 0, 0, 0, 215,
+	219, 220, 221, 222, 223, 224, 225, 226, 0, 0,
+	0, 0, 0, 0, 0, 0, 413, 165, 414, 415,
+	416, 417, 418, 419, 401, 420, 421, 422, 423, 424,
+	404, 405, 406, 407, 425, 426, 427, 428, 429, 430,
+	431, 432, 433, 434, 435, 436, 399, 412,
 }
 var yyPact = []int{
 
-	439, -1000, -1000, 225, -1000, -1000, -1000, -1000, -1000, -1000,
+	455, -1000, -90, 285, -1000, -1000, -1000, -1000, -1000, -1000,
 	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, 62, 27, -11, 18, 17, 76, 530, 471, -1000,
-	-1000, -1000, 465, -1000, 398, 329, 521, 315, 313, -1000,
-	520, 519, 498, -23, -40, 9, 313, -40, -1000, 14,
-	313, -1000, 334, 333, -65, 313, -65, -65, -1000, -1000,
-	158, -1000, 279, 329, 370, 90, 329, 170, -1000, 237,
-	-1000, 75, -1000, -1000, -1000, 313, 313, 313, 497, 313,
-	97, 313, -1000, 313, 328, -1000, -37, -1000, 313, 430,
-	127, 313, 313, 274, -1000, -1000, 362, 56, 134, 474,
-	-1000, 374, 287, -1000, -1000, -1000, 7, 298, 292, -1000,
-	290, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, 7, -1000, 275, 315, 327, 495, 315, 7, 313,
-	-1000, -1000, 49, -1000, 313, 289, 422, 108, -1000, -1000,
-	252, -1000, 323, -1000, -1000, 313, -1000, -1000, 245, 158,
-	-1000, -1000, 313, 162, 374, 374, 7, 261, 456, 7,
-	7, 180, 7, 7, 7, 7, 7, 7, 7, 7,
-	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, 474, -67,
-	4, -39, 474, -1000, 459, 272, 158, -1000, 530, 1,
-	502, 445, 315, 315, 264, -1000, 480, 374, -1000, 502,
-	-1000, 313, -1000, -16, -1000, 117, 313, -1000, -49, -1000,
-	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, 262, 479,
-	359, 297, 44, -1000, -1000, -1000, -1000, -1000, -1000, 502,
-	-1000, 261, 7, 7, 502, 494, -1000, 405, 222, 222,
-	222, 129, 129, -1000, -1000, -1000, -1000, -1000, 7, -1000,
-	502, -1000, -13, 158, -14, 38, -1000, 374, 114, 261,
-	225, 169, -15, -1000, 480, 475, 486, 134, -1000, -21,
-	-1000, 575, 8, 390, 313, -1000, -1000, 322, -1000, 492,
-	245, 245, -1000, -1000, 200, 198, 187, 186, 184, 88,
-	-1000, 319, -27, 318, -1000, 502, 412, 7, -1000, 502,
-	-1000, -28, -1000, 29, -1000, 7, 118, -1000, 395, 212,
-	-1000, -1000, -1000, 315, 475, -1000, 7, 7, -1000, -16,
-	179, 282, 282, 286, 284, -1000, -1000, -1000, -1000, 282,
-	282, -1000, -1000, 277, 268, -1000, -1000, -1000, -1000, -1000,
+	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, 189, -41,
+	-23, 42, 36, 41, 561, -76, -74, -76, -76, 625,
+	625, 35, -1000, -1000, 582, 501, -1000, -1000, -1000, 498,
+	-1000, 443, 377, 572, 49, 367, -1000, -1000, -1000, -1000,
+	570, 569, 563, -42, 32, -1000, -44, 26, 367, -44,
+	-1000, -28, 367, -1000, 367, 367, -45, 367, -45, -45,
+	285, -1000, -1000, -1000, -1000, -1000, -1000, -1000, 185, -1000,
+	240, -1000, -1000, -1000, -1000, -1000, 181, 367, -1000, -1000,
+	536, -1000, 290, 377, 423, 106, 377, 212, 251, -1000,
+	249, -1000, 101, -1000, -1000, 367, 367, 367, 551, 367,
+	367, 117, 367, 351, 98, 367, 367, 351, 9, -1000,
+	367, 484, 180, 367, 367, 90, 545, 90, 549, 322,
+	-1000, -1000, 412, 97, 159, 680, -1000, 616, 559, -1000,
+	-1000, -1000, 663, 342, 334, -1000, 320, -1000, -1000, -1000,
+	-1000, -1000, -1000, -1000, -1000, -1000, -1000, 663, -1000, 314,
+	368, 375, 526, 368, 663, 663, 367, -1000, -1000, -1000,
+	367, -1000, 319, 475, 129, 495, 367, 367, -1000, 85,
+	487, 367, -1000, -1000, 367, -1000, -1000, -24, 237, 367,
+	367, -1000, -1000, 546, -85, 267, 536, -1000, -1000, 367,
+	182, 616, 616, 663, 277, 502, 663, 663, 223, 663,
+	663, 663, 663, 663, 663, 663, 663, -1000, -1000, -1000,
+	-1000, -1000, -1000, -1000, -1000, 680, -70, -4, -30, 680,
+	-1000, 631, 332, 536, -1000, 582, 69, 571, 477, 368,
+	368, 304, -1000, 519, 616, -1000, 571, 571, -1000, -1000,
+	5, -1000, 176, 367, 582, -10, -1000, -1000, -1000, -33,
+	-38, -38, 582, -1000, -1000, -1000, 367, 367, 237, 233,
+	367, 363, -22, 294, 409, 373, 76, -1000, -1000, -1000,
+	-1000, -1000, -1000, 571, -1000, 277, 663, 663, 571, 547,
+	-1000, 450, 137, 137, 137, 160, 160, -1000, -1000, -1000,
+	-1000, -1000, 663, -1000, 571, -1000, -12, 536, -16, 86,
+	-1000, 616, 174, 277, 285, 184, -17, -1000, 519, 510,
+	517, 159, -19, -1000, -1000, 23, 438, 762, 367, -1000,
+	285, -1000, 367, 367, 367, -1000, 367, -1000, 367, 285,
+	233, -1000, 233, -1000, 526, 267, 367, 267, -1000, -1000,
+	207, 201, 209, 208, 200, 61, -1000, 374, -46, 372,
+	-1000, 571, 516, 663, -1000, 571, -1000, -21, -1000, 59,
+	-1000, 663, 78, -1000, 440, 227, -1000, -1000, -1000, 368,
+	510, -1000, 663, 663, -1000, 5, 367, 316, 219, 287,
+	287, -1000, 312, 288, -1000, -1000, -1000, -1000, 287, 287,
+	-1000, -1000, 284, 280, -1000, -1000, -1000, -1000, -1000, -1000,
 	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, 313, 267,
-	-1000, -1000, 489, 478, 479, 163, -1000, 171, -1000, 159,
-	-1000, -1000, -1000, -1000, 5, -2, -19, -1000, -1000, -1000,
-	7, 502, -1000, -1000, 502, 7, 388, 261, -1000, -1000,
-	145, 211, -1000, 482, -1000, -1000, 12, -1000, 404, -1000,
-	310, -86, -86, 310, -1000, 310, -1000, -1000, 313, 313,
-	259, 313, -1000, 480, 374, 7, 374, 258, -1000, -1000,
-	255, 247, 241, 502, 502, 527, -1000, 7, 7, 7,
-	-1000, -1000, -1000, -1000, 379, -1000, 376, -1000, -100, -1000,
-	-1000, -1000, -1000, 207, 203, -30, -1000, -33, 313, -35,
-	475, 134, 181, 134, 315, 313, 313, 313, 315, 502,
-	502, -1000, -1000, -1000, -1000, 310, -103, 310, -1000, 313,
-	-1000, -38, -1000, 384, -46, -48, -51, -63, 170, -107,
-	-1000, -1000, -1000, -1000, -1000, 525, 415, -1000, -1000, -1000,
-	-1000, -1000, -1000, 313, 313, -1000,
+	-1000, -1000, -1000, -1000, -1000, -1000, -1000, 279, -1000, -1000,
+	-39, -1000, -1000, 523, 294, 395, 164, -1000, 198, -1000,
+	197, -1000, -1000, -1000, -1000, 20, 19, 17, -1000, -1000,
+	-1000, 663, 571, -1000, -1000, 571, 663, 436, 277, -1000,
+	-1000, 318, 222, -1000, 349, -1000, -1000, 275, 367, 425,
+	-1000, 448, -1000, 359, -51, -51, 359, -1000, 359, -1000,
+	-1000, 367, 367, 367, 367, 518, 514, 663, 616, 265,
+	-1000, -1000, 260, 255, 228, 571, 571, 579, -1000, 663,
+	663, 663, -1000, -1000, -1000, 367, -25, 54, 663, -1000,
+	-95, -1000, -1000, -1000, -1000, 221, 217, -27, -35, -36,
+	-1000, 519, 616, 663, 571, 159, 368, 367, 367, 367,
+	368, 571, 571, -1000, -37, -1000, -1000, 435, -1000, 427,
+	571, -1000, 359, -114, 359, -1000, -1000, -1000, 510, 159,
+	216, -40, -47, -48, -54, 212, -1000, -1000, -1000, -116,
+	-1000, -1000, 430, -1000, -1000, -1000, -1000, -1000, -1000, 575,
+	469, -1000, 367, 367, -1000,
 }
 var yyPgo = []int{
 
-	0, 684, 683, 24, 682, 681, 680, 679, 678, 677,
-	676, 675, 674, 673, 672, 502, 671, 670, 669, 26,
-	28, 668, 667, 666, 665, 18, 664, 663, 415, 22,
-	662, 4, 23, 21, 661, 660, 659, 658, 5, 9,
-	7, 657, 656, 15, 655, 16, 654, 653, 17, 652,
-	651, 650, 649, 11, 648, 2, 647, 3, 646, 645,
-	8, 19, 6, 94, 644, 643, 642, 641, 10, 429,
-	418, 468, 640, 13, 1, 610, 592, 591, 0, 571,
-	14, 560, 559, 552, 548, 543, 542, 541, 540, 539,
-	538, 12, 536,
+	0, 763, 762, 25, 761, 469, 445, 760, 759, 755,
+	749, 748, 747, 746, 745, 744, 743, 740, 739, 737,
+	736, 733, 485, 728, 727, 725, 19, 32, 724, 723,
+	722, 721, 16, 718, 717, 222, 31, 38, 83, 446,
+	716, 81, 714, 7, 92, 18, 34, 713, 712, 711,
+	709, 10, 14, 9, 708, 707, 13, 701, 22, 696,
+	694, 21, 690, 688, 687, 686, 3, 681, 6, 680,
+	2, 679, 668, 667, 5, 20, 8, 24, 666, 665,
+	664, 23, 464, 11, 336, 428, 484, 661, 4, 1,
+	659, 656, 655, 0, 629, 12, 17, 624, 614, 612,
+	610, 609, 608, 607, 606, 601, 595, 592, 591, 15,
+	590, 589,
 }
 var yyR1 = []int{
 
-	0, 1, 2, 2, 2, 2, 2, 2, 2, 2,
-	2, 2, 2, 2, 3, 3, 4, 4, 5, 6,
-	7, 8, 9, 9, 9, 9, 9, 10, 10, 10,
-	10, 79, 79, 80, 80, 80, 81, 81, 81, 81,
-	81, 81, 81, 81, 81, 81, 81, 81, 81, 81,
-	82, 82, 82, 82, 82, 82, 83, 83, 83, 84,
-	84, 85, 85, 86, 86, 87, 87, 87, 87, 88,
-	88, 88, 88, 89, 89, 89, 90, 90, 90, 90,
-	90, 11, 11, 11, 12, 13, 14, 14, 14, 14,
-	92, 15, 16, 16, 17, 17, 17, 17, 17, 18,
-	18, 19, 19, 20, 20, 20, 23, 23, 21, 21,
-	21, 24, 24, 25, 25, 25, 25, 25, 22, 22,
-	22, 26, 26, 26, 26, 26, 26, 26, 26, 26,
-	27, 27, 27, 28, 28, 29, 29, 30, 30, 30,
-	30, 31, 31, 32, 32, 33, 33, 33, 33, 33,
-	34, 34, 34, 34, 34, 34, 34, 34, 34, 34,
-	35, 35, 35, 35, 35, 35, 35, 36, 36, 41,
-	41, 39, 39, 43, 40, 40, 38, 38, 38, 38,
-	38, 38, 38, 38, 38, 38, 38, 38, 38, 38,
-	38, 38, 38, 42, 42, 44, 44, 44, 46, 49,
-	49, 47, 47, 48, 50, 50, 45, 45, 37, 37,
-	37, 37, 51, 51, 52, 52, 53, 53, 54, 54,
-	55, 56, 56, 56, 57, 57, 57, 57, 58, 58,
-	58, 59, 59, 60, 60, 61, 61, 62, 62, 63,
-	69, 69, 70, 70, 64, 64, 65, 65, 65, 65,
-	65, 66, 66, 71, 71, 73, 73, 74, 76, 76,
-	77, 77, 75, 75, 68, 68, 67, 67, 72, 72,
-	78, 91,
+	0, 1, 110, 110, 2, 2, 2, 2, 2, 2,
+	2, 2, 2, 2, 2, 2, 2, 2, 2, 2,
+	2, 2, 2, 3, 3, 4, 4, 5, 6, 7,
+	7, 8, 9, 9, 9, 9, 9, 9, 9, 9,
+	9, 10, 10, 10, 10, 94, 94, 95, 95, 95,
+	96, 99, 99, 99, 99, 99, 99, 99, 99, 99,
+	99, 99, 99, 99, 99, 99, 100, 100, 100, 100,
+	100, 100, 101, 101, 101, 102, 102, 103, 103, 104,
+	104, 105, 105, 105, 105, 106, 106, 106, 106, 107,
+	107, 107, 98, 98, 108, 108, 108, 108, 108, 11,
+	11, 11, 97, 97, 97, 12, 13, 15, 15, 15,
+	16, 16, 17, 18, 21, 19, 20, 39, 39, 40,
+	40, 41, 41, 41, 41, 38, 38, 38, 14, 14,
+	14, 14, 111, 22, 23, 23, 24, 24, 24, 24,
+	24, 25, 25, 26, 26, 27, 27, 27, 30, 30,
+	28, 28, 28, 31, 31, 32, 32, 32, 32, 32,
+	29, 29, 29, 33, 33, 33, 33, 33, 33, 33,
+	33, 33, 34, 34, 34, 35, 35, 36, 36, 37,
+	37, 42, 42, 42, 42, 44, 44, 43, 43, 45,
+	45, 46, 46, 46, 46, 46, 47, 47, 47, 47,
+	47, 47, 47, 47, 47, 47, 48, 48, 48, 48,
+	48, 48, 48, 49, 49, 54, 54, 52, 52, 56,
+	53, 53, 51, 51, 51, 51, 51, 51, 51, 51,
+	51, 51, 51, 51, 51, 51, 51, 51, 51, 55,
+	55, 57, 57, 57, 59, 62, 62, 60, 60, 61,
+	63, 63, 58, 58, 50, 50, 50, 50, 64, 64,
+	65, 65, 66, 66, 67, 67, 68, 69, 69, 69,
+	70, 70, 70, 70, 71, 71, 72, 72, 72, 73,
+	73, 74, 74, 75, 75, 76, 76, 77, 84, 84,
+	85, 85, 78, 78, 81, 81, 79, 79, 82, 82,
+	86, 86, 88, 88, 89, 91, 91, 92, 92, 90,
+	90, 83, 83, 80, 80, 87, 87, 93, 109,
 }
 var yyR2 = []int{
 
-	0, 1, 1, 1, 1, 1, 1, 1, 1, 1,
-	1, 1, 1, 1, 12, 3, 7, 7, 8, 7,
-	3, 3, 2, 3, 4, 4, 5, 8, 8, 4,
-	4, 1, 3, 4, 6, 5, 2, 3, 3, 2,
-	1, 1, 1, 1, 2, 2, 1, 1, 4, 4,
+	0, 2, 0, 1, 1, 1, 1, 1, 1, 1,
 	1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
+	1, 1, 1, 13, 3, 7, 7, 8, 7, 3,
+	5, 3, 2, 2, 2, 2, 3, 4, 4, 5,
+	4, 8, 10, 6, 4, 1, 3, 1, 6, 5,
+	5, 2, 3, 1, 3, 2, 1, 1, 1, 1,
+	2, 2, 1, 1, 4, 4, 1, 1, 1, 1,
 	1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
-	1, 1, 1, 0, 1, 2, 0, 2, 1, 1,
-	2, 6, 7, 4, 5, 3, 4, 5, 5, 5,
-	0, 2, 0, 2, 1, 2, 1, 1, 1, 0,
-	1, 1, 3, 1, 2, 3, 1, 1, 0, 1,
-	2, 1, 3, 3, 3, 3, 5, 7, 0, 1,
-	2, 1, 1, 2, 3, 2, 3, 2, 2, 2,
-	1, 3, 1, 1, 3, 1, 3, 0, 5, 5,
-	5, 1, 3, 0, 2, 1, 3, 3, 2, 3,
-	3, 3, 4, 3, 4, 5, 6, 3, 4, 2,
-	1, 1, 1, 1, 1, 1, 1, 2, 1, 1,
-	3, 3, 1, 3, 1, 3, 1, 1, 1, 3,
-	3, 3, 3, 3, 3, 3, 3, 2, 3, 4,
-	5, 4, 1, 1, 1, 1, 1, 1, 5, 0,
-	1, 1, 2, 4, 0, 2, 1, 3, 1, 1,
-	1, 1, 0, 3, 0, 2, 0, 3, 1, 3,
-	2, 0, 1, 1, 0, 2, 4, 4, 0, 2,
-	4, 0, 3, 1, 3, 0, 5, 1, 3, 3,
-	0, 2, 0, 3, 0, 1, 1, 1, 1, 1,
-	1, 0, 1, 0, 1, 0, 3, 1, 0, 5,
-	0, 4, 0, 2, 0, 1, 0, 2, 0, 2,
-	1, 0,
+	1, 1, 1, 1, 1, 1, 1, 1, 1, 0,
+	1, 2, 0, 2, 0, 2, 1, 1, 2, 5,
+	7, 6, 3, 3, 5, 5, 3, 2, 2, 2,
+	2, 2, 2, 2, 6, 6, 6, 1, 1, 1,
+	3, 1, 1, 1, 1, 1, 2, 2, 4, 5,
+	4, 5, 0, 2, 0, 2, 1, 2, 1, 1,
+	1, 0, 1, 1, 3, 1, 2, 3, 1, 1,
+	0, 1, 2, 1, 3, 3, 3, 3, 5, 7,
+	0, 1, 2, 1, 1, 2, 3, 2, 3, 2,
+	2, 2, 1, 3, 1, 1, 3, 1, 3, 1,
+	3, 0, 5, 5, 5, 0, 3, 1, 3, 0,
+	2, 1, 3, 3, 2, 3, 3, 3, 4, 3,
+	4, 5, 6, 3, 4, 2, 1, 1, 1, 1,
+	1, 1, 1, 2, 1, 1, 3, 3, 1, 3,
+	1, 3, 1, 1, 1, 3, 3, 3, 3, 3,
+	3, 3, 3, 2, 3, 4, 5, 4, 1, 1,
+	1, 1, 1, 1, 5, 0, 1, 1, 2, 4,
+	0, 2, 1, 3, 1, 1, 1, 1, 0, 3,
+	0, 2, 0, 3, 1, 3, 2, 0, 1, 1,
+	0, 2, 4, 4, 0, 4, 0, 2, 4, 0,
+	3, 1, 3, 0, 5, 1, 3, 3, 0, 2,
+	0, 3, 0, 1, 0, 1, 0, 1, 0, 1,
+	0, 1, 0, 3, 1, 0, 5, 0, 4, 0,
+	2, 0, 1, 0, 2, 0, 2, 1, 0,
 }
 var yyChk = []int{
 
 	-1000, -1, -2, -3, -4, -5, -6, -7, -8, -9,
-	-10, -11, -12, -13, -14, 5, 6, 7, 8, 34,
-	92, 119, 114, 115, 117, 118, 116, -17, 79, 80,
-	81, 82, -15, -92, -15, -15, -15, -15, -15, 121,
-	123, 124, 126, 127, 122, -71, 125, 120, 131, -64,
-	125, 129, 122, 122, 122, 124, 125, 120, -3, 18,
-	-18, 19, -16, 30, -28, 65, 9, -62, -63, -45,
-	-78, 65, -78, 65, -72, 9, 9, 9, 126, -70,
-	130, 124, -78, -70, 122, -78, 65, 65, -69, 130,
-	-78, -69, -69, -19, -20, 104, -23, 65, -33, -38,
-	-34, 98, 74, -37, -45, -39, -44, -78, -42, -46,
-	21, 66, 67, 68, 26, -43, 102, 103, 78, 130,
-	29, 109, 69, -28, 34, 107, -28, 83, 75, 107,
-	-78, -29, -78, -29, 9, -29, 98, -78, -91, -78,
-	65, -91, 128, -78, 21, 94, -78, -78, 9, 83,
-	-21, -78, 20, 107, 96, 97, -35, 22, 98, 24,
-	25, 23, 99, 100, 101, 102, 103, 104, 105, 106,
-	75, 76, 77, 70, 71, 72, 73, -33, -38, -33,
-	-40, -3, -38, -38, 74, 74, 74, -43, 74, -49,
-	-38, -59, 34, 74, -62, 65, -32, 10, -63, -38,
-	-78, 107, -29, 74, 21, -67, 95, -65, 117, 115,
-	33, 116, 13, 65, 65, -78, -91, -91, -24, -25,
-	-27, 74, 65, -43, -20, -78, 104, -33, -33, -38,
-	-39, 22, 24, 25, -38, -38, 26, 98, -38, -38,
-	-38, -38, -38, -38, -38, -38, 134, 134, 83, 134,
-	-38, 134, -19, 19, -19, -47, -48, 110, -36, 29,
-	-3, -62, -60, -45, -32, -53, 13, -33, -78, -79,
-	-80, -78, -71, 133, 94, -78, -91, -66, 128, -32,
-	83, -26, 84, 85, 86, 87, 88, 90, 91, -22,
-	65, 20, -25, 107, -39, -38, -38, 96, 26, -38,
-	134, -19, 134, -50, -48, 112, -33, -61, 94, -41,
-	-39, -61, 134, 83, -53, -57, 15, 14, 134, 83,
-	-81, 63, -82, -83, -84, 47, 48, 49, 50, -85,
-	-86, -87, -88, 64, 34, 36, 37, 38, 39, 40,
-	41, 42, 43, 44, 45, 46, 51, 52, 53, 54,
-	55, 56, 57, 58, 59, 60, 61, 62, 124, 32,
-	-78, 65, -51, 11, -25, -25, 84, 89, 84, 89,
-	84, 84, 84, -30, 92, 129, 93, 65, 134, 65,
-	96, -38, 134, 113, -38, 111, 31, 83, -45, -57,
-	-38, -54, -55, -38, -91, -80, -89, 26, 98, -73,
-	74, -73, -76, 74, -77, 74, -73, -73, 74, 74,
-	-78, 74, -91, -52, 12, 14, 94, 95, 84, 84,
-	124, 124, 124, -38, -38, 32, -39, 83, 16, 83,
-	-56, 27, 28, -90, 133, 32, 131, 26, -74, 67,
-	-68, 132, -68, -74, -74, -31, -78, -31, 74, -31,
-	-53, -33, -40, -33, 74, 74, 74, 74, 7, -38,
-	-38, -55, 32, 32, 134, 83, -75, 83, 134, 83,
-	134, -31, 134, -57, -60, -31, -31, -31, -62, -74,
-	134, -74, -78, 134, -58, 17, 35, 134, 134, 134,
-	134, 134, 7, 22, -78, -78,
+	-10, -11, -12, -13, -14, -15, -16, -17, -18, -19,
+	-20, -21, 5, 6, 7, 8, 34, 93, 121, 115,
+	116, 119, 120, 118, 122, 138, 139, 141, 142, 143,
+	144, 145, -110, 148, -24, 80, 81, 82, 83, -22,
+	-111, -22, -22, -22, -22, -22, -93, 123, 18, 124,
+	126, 127, 130, 131, 115, 66, 125, -86, 128, 123,
+	135, -78, 128, 133, 125, 125, 125, 127, 128, 123,
+	-3, -5, -6, -82, 140, 140, -82, -82, -39, 18,
+	-40, -41, 5, 6, 7, 8, -39, 125, -3, 18,
+	-25, 19, -23, 30, -35, 66, 9, -76, 123, -77,
+	-58, -93, 66, -93, -87, 9, 9, 9, 130, 125,
+	-85, 134, 127, -36, -93, -85, 125, -36, -36, -36,
+	-84, 134, -93, -84, -84, 95, 84, 95, -36, -26,
+	-27, 105, -30, 66, -46, -51, -47, 99, 75, -50,
+	-58, -52, -57, -93, -55, -59, 21, 67, 68, 69,
+	26, -56, 103, 104, 79, 134, 29, 110, 70, -35,
+	34, 108, -35, 84, 76, 76, 108, -93, -36, -36,
+	9, -36, -36, 99, -93, -44, 75, 108, -93, -36,
+	-44, 132, -36, 21, 95, -36, -93, -38, -37, 125,
+	123, -36, -41, -38, 9, 9, 84, -28, -93, 20,
+	108, 97, 98, -48, 22, 99, 24, 25, 23, 100,
+	101, 102, 103, 104, 105, 106, 107, 76, 77, 78,
+	71, 72, 73, 74, -46, -51, -46, -53, -3, -51,
+	-51, 75, 75, 75, -56, 75, -62, -51, -73, 34,
+	75, -76, 66, -45, 10, -77, -51, -51, -93, -36,
+	75, 21, -80, 96, 20, -43, -93, -93, -97, 119,
+	117, 118, 20, -36, -36, -109, 132, 84, -37, -43,
+	9, 146, -31, -32, -34, 75, 66, -56, -27, -93,
+	105, -46, -46, -51, -52, 22, 24, 25, -51, -51,
+	26, 99, -51, -51, -51, -51, -51, -51, -51, -51,
+	149, 149, 84, 149, -51, 149, -26, 19, -26, -60,
+	-61, 111, -49, 29, -3, -76, -74, -58, -45, -66,
+	13, -46, -94, -95, -96, -86, 137, -93, 95, -93,
+	-3, 149, 84, -79, 129, 132, -81, 129, -81, -3,
+	-43, -36, -43, 67, -71, 84, 147, -33, 85, 86,
+	87, 88, 89, 91, 92, -29, 66, 20, -32, 108,
+	-52, -51, -51, 97, 26, -51, 149, -26, 149, -63,
+	-61, 113, -46, -75, 95, -54, -52, -75, 149, 84,
+	-66, -70, 15, 14, 149, 84, 127, 32, -99, 64,
+	-100, 42, -101, -102, 48, 49, 50, 51, -103, -104,
+	-105, -106, 65, 34, 36, 37, 38, 39, 40, 41,
+	43, 44, 45, 46, 47, 52, 53, 54, 55, 56,
+	57, 58, 59, 60, 61, 62, 63, -36, -93, -36,
+	-93, -96, -93, -45, -32, -93, -32, 85, 90, 85,
+	90, 85, 85, 85, -42, 93, 133, 94, 66, 149,
+	66, 97, -51, 149, 114, -51, 112, 31, 84, -58,
+	-70, -51, -67, -68, -51, -109, -95, -93, 75, -107,
+	26, 99, -88, 75, -88, -91, 75, -92, 75, -88,
+	-88, 75, 75, 75, 132, -64, 11, 49, 95, 96,
+	85, 85, 127, 127, 127, -51, -51, 32, -52, 84,
+	16, 84, -69, 27, 28, 75, -43, -98, 33, 26,
+	-89, 68, -83, 136, -83, -89, -89, -43, -43, -43,
+	-93, -65, 12, 14, -51, -46, 75, 75, 75, 75,
+	7, -51, -51, -68, -43, 149, -108, 137, 32, 135,
+	-51, 149, 84, -90, 84, 149, 149, 149, -66, -46,
+	-53, -74, -43, -43, -43, -76, 149, 32, 32, -89,
+	149, -89, -70, 149, 149, 149, 149, 149, -72, 17,
+	35, 7, 22, -93, -93,
 }
 var yyDef = []int{
 
-	0, -2, 1, 2, 3, 4, 5, 6, 7, 8,
-	9, 10, 11, 12, 13, 90, 90, 90, 90, 90,
-	90, 0, 253, 244, 0, 0, 0, 0, 94, 96,
-	97, 98, 99, 92, 0, 0, 0, 0, 0, 22,
-	268, 0, 0, 0, 242, 0, 0, 242, 254, 0,
-	0, 245, 0, 0, 240, 0, 240, 240, 15, 95,
-	0, 100, 91, 0, 0, 133, 0, 20, 237, 0,
-	206, 270, 21, 270, 23, 0, 0, 0, 0, 0,
-	0, 0, 271, 0, 0, 271, 0, 85, 0, 0,
-	0, 0, 0, 0, 101, 103, 108, 270, 106, 107,
-	145, 0, 0, 176, 177, 178, 0, 206, 0, 192,
-	0, 208, 209, 210, 211, 172, 195, 196, 197, 193,
-	194, 199, 93, 231, 0, 0, 143, 0, 0, 0,
-	269, 24, 135, 25, 0, 0, 0, 266, 29, 30,
-	0, 83, 0, 86, 241, 0, 271, 271, 0, 0,
-	104, 109, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, -2, 2, 4, 5, 6, 7, 8, 9, 10,
+	11, 12, 13, 14, 15, 16, 17, 18, 19, 20,
+	21, 22, 132, 132, 132, 132, 132, 132, 0, 300,
+	292, 0, 0, 0, 0, 298, 0, 298, 298, 0,
+	0, 0, 1, 3, 0, 136, 138, 139, 140, 141,
+	134, 0, 0, 0, 0, 0, 32, 33, 34, 35,
+	315, 0, 0, 0, 0, 317, 290, 0, 0, 290,
+	301, 0, 0, 293, 0, 0, 288, 0, 288, 288,
+	107, 108, 109, 110, 299, 111, 112, 113, 0, 117,
+	118, 119, 121, 122, 123, 124, 0, 0, 24, 137,
+	0, 142, 133, 0, 0, 175, 0, 29, 0, 285,
+	0, 252, 317, 31, 36, 0, 0, 0, 0, 0,
+	0, 0, 0, 185, 177, 0, 0, 185, 0, 106,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
-	160, 161, 162, 163, 164, 165, 166, 148, 0, 0,
-	0, 0, 174, 187, 0, 0, 0, 159, 0, 0,
-	200, 0, 0, 0, 143, 134, 216, 0, 238, 239,
-	207, 0, 26, 253, 243, 0, 0, 271, 251, 246,
-	247, 248, 249, 250, 84, 87, 88, 89, 143, 111,
-	118, 0, 130, 132, 102, 110, 105, 146, 147, 150,
-	151, 0, 0, 0, 153, 0, 157, 0, 179, 180,
-	181, 182, 183, 184, 185, 186, 149, 171, 0, 173,
-	174, 188, 0, 0, 0, 204, 201, 0, 235, 0,
-	168, 235, 0, 233, 216, 224, 0, 144, 136, 0,
-	31, 0, 0, 0, 0, 267, 81, 0, 252, 212,
-	0, 0, 121, 122, 0, 0, 0, 0, 0, 137,
-	119, 0, 0, 0, 152, 154, 0, 0, 158, 175,
-	189, 0, 191, 0, 202, 0, 0, 16, 0, 167,
-	169, 17, 232, 0, 224, 19, 0, 0, 271, 253,
-	73, 255, 255, 258, 260, 40, 41, 42, 43, 255,
-	255, 46, 47, 0, 0, 50, 51, 52, 53, 54,
-	55, 56, 57, 58, 59, 60, 61, 62, 63, 64,
-	65, 66, 67, 68, 69, 70, 71, 72, 0, 0,
-	271, 82, 214, 0, 112, 115, 123, 0, 125, 0,
-	127, 128, 129, 113, 0, 0, 0, 120, 114, 131,
-	0, 155, 190, 198, 205, 0, 0, 0, 234, 18,
-	225, 217, 218, 221, 27, 32, 76, 74, 0, 36,
-	0, 264, 264, 0, 39, 0, 44, 45, 0, 0,
-	0, 0, 28, 216, 0, 0, 0, 0, 124, 126,
-	0, 0, 0, 156, 203, 0, 170, 0, 0, 0,
-	220, 222, 223, 33, 0, 78, 79, 75, 0, 257,
-	37, 265, 38, 0, 262, 0, 141, 0, 0, 0,
-	224, 215, 213, 116, 0, 0, 0, 0, 0, 226,
-	227, 219, 77, 80, 256, 0, 0, 0, 48, 0,
-	49, 0, 35, 228, 0, 0, 0, 0, 236, 0,
-	261, 263, 142, 34, 14, 0, 0, 117, 138, 139,
-	140, 259, 229, 0, 0, 230,
+	143, 145, 150, 317, 148, 149, 191, 0, 0, 222,
+	223, 224, 0, 252, 0, 238, 0, 254, 255, 256,
+	257, 218, 241, 242, 243, 239, 240, 245, 135, 279,
+	0, 0, 189, 0, 0, 0, 0, 316, 37, 38,
+	0, 40, 0, 0, 313, 0, 0, 0, 44, 0,
+	0, 0, 128, 289, 0, 130, 318, 0, 125, 0,
+	0, 179, 120, 0, 0, 0, 0, 146, 151, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 206, 207, 208,
+	209, 210, 211, 212, 194, 0, 0, 0, 0, 220,
+	233, 0, 0, 0, 205, 0, 0, 246, 0, 0,
+	0, 189, 176, 262, 0, 286, 30, 287, 253, 39,
+	300, 291, 0, 0, 0, 0, 187, 178, 99, 296,
+	294, 294, 0, 105, 129, 131, 0, 0, 126, 127,
+	0, 0, 274, 153, 160, 0, 172, 174, 144, 152,
+	147, 192, 193, 196, 197, 0, 0, 0, 199, 0,
+	203, 0, 225, 226, 227, 228, 229, 230, 231, 232,
+	195, 217, 0, 219, 220, 234, 0, 0, 0, 250,
+	247, 0, 283, 0, 214, 283, 0, 281, 262, 270,
+	0, 190, 0, 45, 47, 0, 0, 0, 0, 314,
+	43, 186, 0, 0, 0, 297, 0, 295, 0, 101,
+	115, 180, 116, 114, 189, 0, 0, 0, 163, 164,
+	0, 0, 0, 0, 0, 181, 161, 0, 0, 0,
+	198, 200, 0, 0, 204, 221, 235, 0, 237, 0,
+	248, 0, 0, 25, 0, 213, 215, 26, 280, 0,
+	270, 28, 0, 0, 318, 300, 0, 0, 89, 302,
+	302, 53, 305, 307, 56, 57, 58, 59, 302, 302,
+	62, 63, 0, 0, 66, 67, 68, 69, 70, 71,
+	72, 73, 74, 75, 76, 77, 78, 79, 80, 81,
+	82, 83, 84, 85, 86, 87, 88, 0, 188, 100,
+	0, 102, 103, 258, 154, 0, 157, 165, 0, 167,
+	0, 169, 170, 171, 155, 0, 0, 0, 162, 156,
+	173, 0, 201, 236, 244, 251, 0, 0, 0, 282,
+	27, 271, 263, 264, 267, 41, 46, 0, 0, 92,
+	90, 0, 51, 0, 311, 311, 0, 55, 0, 60,
+	61, 0, 0, 0, 0, 260, 0, 0, 0, 0,
+	166, 168, 0, 0, 0, 202, 249, 0, 216, 0,
+	0, 0, 266, 268, 269, 0, 0, 94, 0, 91,
+	0, 304, 52, 312, 54, 0, 309, 0, 0, 0,
+	104, 262, 0, 0, 275, 158, 0, 0, 0, 0,
+	0, 272, 273, 265, 0, 49, 50, 0, 96, 97,
+	93, 303, 0, 0, 0, 64, 65, 42, 270, 261,
+	259, 0, 0, 0, 0, 284, 48, 95, 98, 0,
+	308, 310, 276, 159, 182, 183, 184, 306, 23, 0,
+	0, 277, 0, 0, 278,
 }
 var yyTok1 = []int{
 
 	1, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	3, 3, 3, 3, 3, 3, 3, 106, 99, 3,
-	74, 134, 104, 102, 83, 103, 107, 105, 3, 3,
-	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	76, 75, 77, 3, 3, 3, 3, 3, 3, 3,
+	3, 3, 3, 3, 3, 3, 3, 107, 100, 3,
+	75, 149, 105, 103, 84, 104, 108, 106, 3, 3,
+	3, 3, 3, 3, 3, 3, 3, 3, 3, 148,
+	77, 76, 78, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	3, 3, 3, 3, 101, 3, 3, 3, 3, 3,
+	3, 3, 3, 3, 102, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	3, 3, 3, 3, 100, 3, 78,
+	3, 3, 3, 3, 101, 3, 79,
 }
 var yyTok2 = []int{
 
@@ -673,11 +757,13 @@ var yyTok2 = []int{
 	42, 43, 44, 45, 46, 47, 48, 49, 50, 51,
 	52, 53, 54, 55, 56, 57, 58, 59, 60, 61,
 	62, 63, 64, 65, 66, 67, 68, 69, 70, 71,
-	72, 73, 79, 80, 81, 82, 84, 85, 86, 87,
+	72, 73, 74, 80, 81, 82, 83, 85, 86, 87,
 	88, 89, 90, 91, 92, 93, 94, 95, 96, 97,
-	98, 108, 109, 110, 111, 112, 113, 114, 115, 116,
+	98, 99, 109, 110, 111, 112, 113, 114, 115, 116,
 	117, 118, 119, 120, 121, 122, 123, 124, 125, 126,
-	127, 128, 129, 130, 131, 132, 133,
+	127, 128, 129, 130, 131, 132, 133, 134, 135, 136,
+	137, 138, 139, 140, 141, 142, 143, 144, 145, 146,
+	147,
 }
 var yyTok3 = []int{
 	0,
@@ -909,19 +995,23 @@ yydefault:
 	switch yynt {
 
 	case 1:
-		//line sql.y:173
+		//line sql.y:202
 		{
-			setParseTree(yylex, yyS[yypt-0].statement)
+			setParseTree(yylex, yyS[yypt-1].statement)
 		}
 	case 2:
-		//line sql.y:179
+		//line sql.y:207
 		{
-			yyVAL.statement = yyS[yypt-0].selStmt
 		}
 	case 3:
-		yyVAL.statement = yyS[yypt-0].statement
+		//line sql.y:209
+		{
+		}
 	case 4:
-		yyVAL.statement = yyS[yypt-0].statement
+		//line sql.y:213
+		{
+			yyVAL.statement = yyS[yypt-0].selStmt
+		}
 	case 5:
 		yyVAL.statement = yyS[yypt-0].statement
 	case 6:
@@ -941,877 +1031,1077 @@ yydefault:
 	case 13:
 		yyVAL.statement = yyS[yypt-0].statement
 	case 14:
-		//line sql.y:196
-		{
-			yyVAL.selStmt = &Select{Comments: Comments(yyS[yypt-10].str2), Distinct: yyS[yypt-9].str, Exprs: yyS[yypt-8].selectExprs, From: yyS[yypt-6].tableExprs, Where: NewWhere(astWhere, yyS[yypt-5].boolExpr), GroupBy: GroupBy(yyS[yypt-4].valExprs), Having: NewWhere(astHaving, yyS[yypt-3].boolExpr), OrderBy: yyS[yypt-2].orderBy, Limit: yyS[yypt-1].limit, Lock: yyS[yypt-0].str}
-		}
+		yyVAL.statement = yyS[yypt-0].statement
 	case 15:
-		//line sql.y:200
-		{
-			yyVAL.selStmt = &Union{Type: yyS[yypt-1].str, Left: yyS[yypt-2].selStmt, Right: yyS[yypt-0].selStmt}
-		}
+		yyVAL.statement = yyS[yypt-0].statement
 	case 16:
-		//line sql.y:206
-		{
-			yyVAL.statement = &Insert{Comments: Comments(yyS[yypt-5].str2), Table: yyS[yypt-3].tableName, Columns: yyS[yypt-2].columns, Rows: yyS[yypt-1].insRows, OnDup: OnDup(yyS[yypt-0].updateExprs)}
-		}
+		yyVAL.statement = yyS[yypt-0].statement
 	case 17:
-		//line sql.y:210
-		{
-			cols := make(Columns, 0, len(yyS[yypt-1].updateExprs))
-			vals := make(ValTuple, 0, len(yyS[yypt-1].updateExprs))
-			for _, col := range yyS[yypt-1].updateExprs {
-				cols = append(cols, &NonStarExpr{Expr: col.Name})
-				vals = append(vals, col.Expr)
-			}
-			yyVAL.statement = &Insert{Comments: Comments(yyS[yypt-5].str2), Table: yyS[yypt-3].tableName, Columns: cols, Rows: Values{vals}, OnDup: OnDup(yyS[yypt-0].updateExprs)}
-		}
+		yyVAL.statement = yyS[yypt-0].statement
 	case 18:
-		//line sql.y:222
-		{
-			yyVAL.statement = &Update{Comments: Comments(yyS[yypt-6].str2), Table: yyS[yypt-5].tableName, Exprs: yyS[yypt-3].updateExprs, Where: NewWhere(astWhere, yyS[yypt-2].boolExpr), OrderBy: yyS[yypt-1].orderBy, Limit: yyS[yypt-0].limit}
-		}
+		yyVAL.statement = yyS[yypt-0].statement
 	case 19:
-		//line sql.y:228
-		{
-			yyVAL.statement = &Delete{Comments: Comments(yyS[yypt-5].str2), Table: yyS[yypt-3].tableName, Where: NewWhere(astWhere, yyS[yypt-2].boolExpr), OrderBy: yyS[yypt-1].orderBy, Limit: yyS[yypt-0].limit}
-		}
+		yyVAL.statement = yyS[yypt-0].statement
 	case 20:
-		//line sql.y:234
-		{
-			yyVAL.statement = &Set{Comments: Comments(yyS[yypt-1].str2), Exprs: yyS[yypt-0].updateExprs}
-		}
+		yyVAL.statement = yyS[yypt-0].statement
 	case 21:
-		//line sql.y:240
-		{
-			yyVAL.statement = &Use{Comments: Comments(yyS[yypt-1].str2), Name: yyS[yypt-0].str}
-		}
+		yyVAL.statement = yyS[yypt-0].statement
 	case 22:
-		//line sql.y:246
-		{
-			yyVAL.statement = &ShowDatabases{}
-		}
+		yyVAL.statement = yyS[yypt-0].statement
 	case 23:
-		//line sql.y:250
+		//line sql.y:237
 		{
-			yyVAL.statement = &ShowTables{Name: yyS[yypt-0].str}
+			yyVAL.selStmt = &Select{Comments: Comments(yyS[yypt-11].str2), Distinct: yyS[yypt-10].str, Exprs: yyS[yypt-9].selectExprs, From: yyS[yypt-7].tableExprs, AsOf: yyS[yypt-6].asOf, Where: NewWhere(astWhere, yyS[yypt-5].boolExpr), GroupBy: GroupBy(yyS[yypt-4].valExprs), Having: NewWhere(astHaving, yyS[yypt-3].boolExpr), OrderBy: yyS[yypt-2].orderBy, Limit: yyS[yypt-1].limit, Lock: yyS[yypt-0].str}
 		}
 	case 24:
-		//line sql.y:254
+		//line sql.y:241
 		{
-			yyVAL.statement = &ShowIndex{Name: yyS[yypt-0].tableName}
+			yyVAL.selStmt = &Union{Type: yyS[yypt-1].str, Left: yyS[yypt-2].selStmt, Right: yyS[yypt-0].selStmt}
 		}
 	case 25:
-		//line sql.y:258
+		//line sql.y:247
 		{
-			yyVAL.statement = &ShowColumns{Name: yyS[yypt-0].tableName}
+			yyVAL.statement = &Insert{Comments: Comments(yyS[yypt-5].str2), Table: yyS[yypt-3].tableName, Columns: yyS[yypt-2].columns, Rows: yyS[yypt-1].insRows, OnDup: OnDup(yyS[yypt-0].updateExprs)}
 		}
 	case 26:
-		//line sql.y:262
+		//line sql.y:251
 		{
-			yyVAL.statement = &ShowColumns{Name: yyS[yypt-0].tableName, Full: true}
+			cols := make(Columns, 0, len(yyS[yypt-1].updateExprs))
+			vals := make(ValTuple, 0, len(yyS[yypt-1].updateExprs))
+			for _, col := range yyS[yypt-1].updateExprs {
+				cols = append(cols, &NonStarExpr{Expr: col.Name})
+				vals = append(vals, col.Expr)
+			}
+			yyVAL.statement = &Insert{Comments: Comments(yyS[yypt-5].str2), Table: yyS[yypt-3].tableName, Columns: cols, Rows: Values{vals}, OnDup: OnDup(yyS[yypt-0].updateExprs)}
 		}
 	case 27:
-		//line sql.y:268
+		//line sql.y:263
 		{
-			yyVAL.statement = &CreateTable{IfNotExists: yyS[yypt-5].boolVal, Name: yyS[yypt-4].tableName, Defs: yyS[yypt-2].tableDefs}
+			yyVAL.statement = &Update{Comments: Comments(yyS[yypt-6].str2), Table: yyS[yypt-5].tableName, Exprs: yyS[yypt-3].updateExprs, Where: NewWhere(astWhere, yyS[yypt-2].boolExpr), OrderBy: yyS[yypt-1].orderBy, Limit: yyS[yypt-0].limit}
 		}
 	case 28:
-		//line sql.y:272
+		//line sql.y:269
 		{
-			yyVAL.statement = &CreateIndex{Name: yyS[yypt-4].str, TableName: yyS[yypt-1].str, Unique: yyS[yypt-6].boolVal}
+			yyVAL.statement = &Delete{Comments: Comments(yyS[yypt-5].str2), Table: yyS[yypt-3].tableName, Where: NewWhere(astWhere, yyS[yypt-2].boolExpr), OrderBy: yyS[yypt-1].orderBy, Limit: yyS[yypt-0].limit}
 		}
 	case 29:
-		//line sql.y:276
+		//line sql.y:275
 		{
-			yyVAL.statement = &CreateView{Name: yyS[yypt-1].str}
+			yyVAL.statement = &Set{Comments: Comments(yyS[yypt-1].str2), Exprs: yyS[yypt-0].updateExprs}
 		}
 	case 30:
-		//line sql.y:280
+		//line sql.y:279
 		{
-			yyVAL.statement = &CreateDatabase{IfNotExists: yyS[yypt-1].boolVal, Name: yyS[yypt-0].str}
+			yyVAL.statement = &Set{Comments: Comments(yyS[yypt-3].str2), Exprs: UpdateExprs{{Name: &ColName{Name: "database"}, Expr: yyS[yypt-0].valExpr}}}
 		}
 	case 31:
-		//line sql.y:286
+		//line sql.y:285
 		{
-			yyVAL.tableDefs = TableDefs{yyS[yypt-0].tableDef}
+			yyVAL.statement = &Use{Comments: Comments(yyS[yypt-1].str2), Name: yyS[yypt-0].str}
 		}
 	case 32:
-		//line sql.y:290
+		//line sql.y:291
 		{
-			yyVAL.tableDefs = append(yyVAL.tableDefs, yyS[yypt-0].tableDef)
+			yyVAL.statement = &Show{Name: yyS[yypt-0].str}
 		}
 	case 33:
-		//line sql.y:296
+		//line sql.y:295
 		{
-			yyVAL.tableDef = &ColumnTableDef{Name: yyS[yypt-3].str, Type: yyS[yypt-2].columnType, Nullable: Nullability(yyS[yypt-1].intVal), PrimaryKey: yyS[yypt-0].intVal == 1, Unique: yyS[yypt-0].intVal == 2}
+			yyVAL.statement = &Show{Name: "database"}
 		}
 	case 34:
-		//line sql.y:300
+		//line sql.y:299
 		{
-			yyVAL.tableDef = &IndexTableDef{Name: yyS[yypt-3].str, Unique: yyS[yypt-5].boolVal, Columns: yyS[yypt-1].str2}
+			yyVAL.statement = &Show{Name: "all"}
 		}
 	case 35:
-		//line sql.y:304
+		//line sql.y:303
 		{
-			yyVAL.tableDef = &IndexTableDef{Name: "primary", PrimaryKey: true, Unique: true, Columns: yyS[yypt-1].str2}
+			yyVAL.statement = &ShowDatabases{}
 		}
 	case 36:
-		//line sql.y:310
+		//line sql.y:307
 		{
-			yyVAL.columnType = &BitType{N: yyS[yypt-0].intVal}
+			yyVAL.statement = &ShowTables{Name: yyS[yypt-0].str}
 		}
 	case 37:
-		//line sql.y:312
+		//line sql.y:311
 		{
-			yyVAL.columnType = &IntType{Name: yyS[yypt-2].str, N: yyS[yypt-1].intVal, Unsigned: yyS[yypt-0].boolVal}
+			yyVAL.statement = &ShowIndex{Name: yyS[yypt-0].tableName}
 		}
 	case 38:
-		//line sql.y:314
+		//line sql.y:315
 		{
-			yyVAL.columnType = &FloatType{Name: yyS[yypt-2].str, N: yyS[yypt-1].intVal2[0], Prec: yyS[yypt-1].intVal2[1], Unsigned: yyS[yypt-0].boolVal}
+			yyVAL.statement = &ShowColumns{Name: yyS[yypt-0].tableName}
 		}
 	case 39:
-		//line sql.y:316
+		//line sql.y:319
 		{
-			yyVAL.columnType = &DecimalType{Name: yyS[yypt-1].str, N: yyS[yypt-0].intVal2[0], Prec: yyS[yypt-0].intVal2[1]}
+			yyVAL.statement = &ShowColumns{Name: yyS[yypt-0].tableName, Full: true}
 		}
 	case 40:
-		//line sql.y:318
+		//line sql.y:323
 		{
-			yyVAL.columnType = &DateType{}
+			yyVAL.statement = &ShowCreateTable{Name: yyS[yypt-0].tableName}
 		}
 	case 41:
-		//line sql.y:320
+		//line sql.y:329
 		{
-			yyVAL.columnType = &TimeType{}
+			yyVAL.statement = &CreateTable{IfNotExists: yyS[yypt-5].boolVal, Name: yyS[yypt-4].tableName, Defs: yyS[yypt-2].tableDefs}
 		}
 	case 42:
-		//line sql.y:322
+		//line sql.y:333
 		{
-			yyVAL.columnType = &DateTimeType{}
+			yyVAL.statement = &CreateIndex{Name: yyS[yypt-6].str, Table: yyS[yypt-3].tableName, Unique: yyS[yypt-8].boolVal, Columns: yyS[yypt-1].str2}
 		}
 	case 43:
-		//line sql.y:324
+		//line sql.y:337
 		{
-			yyVAL.columnType = &TimestampType{}
+			yyVAL.statement = &CreateView{Name: yyS[yypt-3].tableName, Columns: yyS[yypt-2].str2, Select: yyS[yypt-0].selStmt}
 		}
 	case 44:
-		//line sql.y:326
+		//line sql.y:341
 		{
-			yyVAL.columnType = &CharType{Name: yyS[yypt-1].str, N: yyS[yypt-0].intVal}
+			yyVAL.statement = &CreateDatabase{IfNotExists: yyS[yypt-1].boolVal, Name: yyS[yypt-0].str}
 		}
 	case 45:
-		//line sql.y:328
+		//line sql.y:347
 		{
-			yyVAL.columnType = &BinaryType{Name: yyS[yypt-1].str, N: yyS[yypt-0].intVal}
+			yyVAL.tableDefs = TableDefs{yyS[yypt-0].tableDef}
 		}
 	case 46:
-		//line sql.y:330
+		//line sql.y:351
 		{
-			yyVAL.columnType = &TextType{Name: yyS[yypt-0].str}
+			yyVAL.tableDefs = append(yyVAL.tableDefs, yyS[yypt-0].tableDef)
 		}
 	case 47:
-		//line sql.y:332
+		//line sql.y:357
 		{
-			yyVAL.columnType = &BlobType{Name: yyS[yypt-0].str}
+			yyVAL.tableDef = yyS[yypt-0].columnDef
 		}
 	case 48:
-		//line sql.y:334
+		//line sql.y:361
 		{
-			yyVAL.columnType = &EnumType{Vals: yyS[yypt-1].str2}
+			yyVAL.tableDef = &IndexTableDef{Name: yyS[yypt-3].str, Unique: yyS[yypt-5].boolVal, Columns: yyS[yypt-1].str2}
 		}
 	case 49:
-		//line sql.y:336
+		//line sql.y:365
 		{
-			yyVAL.columnType = &SetType{Vals: yyS[yypt-1].str2}
+			yyVAL.tableDef = &IndexTableDef{Name: "primary", PrimaryKey: true, Unique: true, Columns: yyS[yypt-1].str2}
 		}
 	case 50:
-		//line sql.y:340
+		//line sql.y:371
 		{
-			yyVAL.str = astInt
+			yyVAL.columnDef = &ColumnTableDef{Name: yyS[yypt-4].str, Type: yyS[yypt-3].columnType, Nullable: Nullability(yyS[yypt-2].intVal), Default: yyS[yypt-1].valExpr, PrimaryKey: yyS[yypt-0].intVal == 1, Unique: yyS[yypt-0].intVal == 2}
 		}
 	case 51:
-		//line sql.y:342
+		//line sql.y:377
 		{
-			yyVAL.str = astTinyInt
+			yyVAL.columnType = &BitType{N: yyS[yypt-0].intVal}
 		}
 	case 52:
-		//line sql.y:344
+		//line sql.y:379
 		{
-			yyVAL.str = astSmallInt
+			yyVAL.columnType = &IntType{Name: yyS[yypt-2].str, N: yyS[yypt-1].intVal, Unsigned: yyS[yypt-0].boolVal}
 		}
 	case 53:
-		//line sql.y:346
+		//line sql.y:381
 		{
-			yyVAL.str = astMediumInt
+			yyVAL.columnType = &SerialType{}
 		}
 	case 54:
-		//line sql.y:348
+		//line sql.y:383
 		{
-			yyVAL.str = astBigInt
+			yyVAL.columnType = &FloatType{Name: yyS[yypt-2].str, N: yyS[yypt-1].intVal2[0], Prec: yyS[yypt-1].intVal2[1], Unsigned: yyS[yypt-0].boolVal}
 		}
 	case 55:
-		//line sql.y:350
+		//line sql.y:385
 		{
-			yyVAL.str = astInteger
+			yyVAL.columnType = &DecimalType{Name: yyS[yypt-1].str, N: yyS[yypt-0].intVal2[0], Prec: yyS[yypt-0].intVal2[1]}
 		}
 	case 56:
-		//line sql.y:354
+		//line sql.y:387
 		{
-			yyVAL.str = astReal
+			yyVAL.columnType = &DateType{}
 		}
 	case 57:
-		//line sql.y:356
+		//line sql.y:389
 		{
-			yyVAL.str = astDouble
+			yyVAL.columnType = &TimeType{}
 		}
 	case 58:
-		//line sql.y:358
+		//line sql.y:391
 		{
-			yyVAL.str = astFloat
+			yyVAL.columnType = &DateTimeType{}
 		}
 	case 59:
-		//line sql.y:362
+		//line sql.y:393
 		{
-			yyVAL.str = astDecimal
+			yyVAL.columnType = &TimestampType{}
 		}
 	case 60:
-		//line sql.y:364
+		//line sql.y:395
 		{
-			yyVAL.str = astNumeric
+			yyVAL.columnType = &CharType{Name: yyS[yypt-1].str, N: yyS[yypt-0].intVal}
 		}
 	case 61:
-		//line sql.y:368
+		//line sql.y:397
 		{
-			yyVAL.str = astChar
+			yyVAL.columnType = &BinaryType{Name: yyS[yypt-1].str, N: yyS[yypt-0].intVal}
 		}
 	case 62:
-		//line sql.y:370
+		//line sql.y:399
 		{
-			yyVAL.str = astVarChar
+			yyVAL.columnType = &TextType{Name: yyS[yypt-0].str}
 		}
 	case 63:
-		//line sql.y:374
+		//line sql.y:401
 		{
-			yyVAL.str = astBinary
+			yyVAL.columnType = &BlobType{Name: yyS[yypt-0].str}
 		}
 	case 64:
-		//line sql.y:376
+		//line sql.y:403
 		{
-			yyVAL.str = astVarBinary
+			yyVAL.columnType = &EnumType{Vals: yyS[yypt-1].str2}
 		}
 	case 65:
-		//line sql.y:380
+		//line sql.y:405
 		{
-			yyVAL.str = astText
+			yyVAL.columnType = &SetType{Vals: yyS[yypt-1].str2}
 		}
 	case 66:
-		//line sql.y:382
+		//line sql.y:409
 		{
-			yyVAL.str = astTinyText
+			yyVAL.str = astInt
 		}
 	case 67:
-		//line sql.y:384
+		//line sql.y:411
 		{
-			yyVAL.str = astMediumText
+			yyVAL.str = astTinyInt
 		}
 	case 68:
-		//line sql.y:386
+		//line sql.y:413
 		{
-			yyVAL.str = astLongText
+			yyVAL.str = astSmallInt
 		}
 	case 69:
-		//line sql.y:390
+		//line sql.y:415
 		{
-			yyVAL.str = astBlob
+			yyVAL.str = astMediumInt
 		}
 	case 70:
-		//line sql.y:392
+		//line sql.y:417
 		{
-			yyVAL.str = astTinyBlob
+			yyVAL.str = astBigInt
 		}
 	case 71:
-		//line sql.y:394
+		//line sql.y:419
 		{
-			yyVAL.str = astMediumBlob
+			yyVAL.str = astInteger
 		}
 	case 72:
-		//line sql.y:396
+		//line sql.y:423
 		{
-			yyVAL.str = astLongBlob
+			yyVAL.str = astReal
 		}
 	case 73:
-		//line sql.y:399
+		//line sql.y:425
 		{
-			yyVAL.intVal = int(SilentNull)
+			yyVAL.str = astDouble
 		}
 	case 74:
-		//line sql.y:401
+		//line sql.y:427
 		{
-			yyVAL.intVal = int(Null)
+			yyVAL.str = astFloat
 		}
 	case 75:
-		//line sql.y:403
+		//line sql.y:431
 		{
-			yyVAL.intVal = int(NotNull)
+			yyVAL.str = astDecimal
 		}
 	case 76:
-		//line sql.y:406
+		//line sql.y:433
 		{
-			yyVAL.intVal = 0
+			yyVAL.str = astNumeric
 		}
 	case 77:
-		//line sql.y:408
+		//line sql.y:437
 		{
-			yyVAL.intVal = 1
+			yyVAL.str = astChar
 		}
 	case 78:
-		//line sql.y:410
+		//line sql.y:439
 		{
-			yyVAL.intVal = 1
+			yyVAL.str = astVarChar
 		}
 	case 79:
-		//line sql.y:412
+		//line sql.y:443
 		{
-			yyVAL.intVal = 2
+			yyVAL.str = astBinary
 		}
 	case 80:
-		//line sql.y:414
+		//line sql.y:445
 		{
-			yyVAL.intVal = 2
+			yyVAL.str = astVarBinary
 		}
 	case 81:
-		//line sql.y:418
+		//line sql.y:449
 		{
-			yyVAL.statement = &AlterTable{Name: yyS[yypt-2].str}
+			yyVAL.str = astText
 		}
 	case 82:
-		//line sql.y:422
+		//line sql.y:451
 		{
-			// Change this to a rename statement
-			yyVAL.statement = &RenameTable{Name: yyS[yypt-3].str, NewName: yyS[yypt-0].str}
+			yyVAL.str = astTinyText
 		}
 	case 83:
-		//line sql.y:427
+		//line sql.y:453
 		{
-			yyVAL.statement = &AlterView{Name: yyS[yypt-1].str}
+			yyVAL.str = astMediumText
 		}
 	case 84:
-		//line sql.y:433
+		//line sql.y:455
 		{
-			yyVAL.statement = &RenameTable{Name: yyS[yypt-2].str, NewName: yyS[yypt-0].str}
+			yyVAL.str = astLongText
 		}
 	case 85:
-		//line sql.y:439
+		//line sql.y:459
 		{
-			yyVAL.statement = &TruncateTable{Name: yyS[yypt-0].str}
+			yyVAL.str = astBlob
 		}
 	case 86:
-		//line sql.y:445
+		//line sql.y:461
 		{
-			yyVAL.statement = &DropTable{Name: yyS[yypt-0].str, IfExists: yyS[yypt-1].boolVal}
+			yyVAL.str = astTinyBlob
 		}
 	case 87:
-		//line sql.y:449
+		//line sql.y:463
 		{
-			yyVAL.statement = &DropIndex{Name: yyS[yypt-2].str, TableName: yyS[yypt-0].str}
+			yyVAL.str = astMediumBlob
 		}
 	case 88:
-		//line sql.y:453
+		//line sql.y:465
 		{
-			yyVAL.statement = &DropView{Name: yyS[yypt-1].str, IfExists: yyS[yypt-2].boolVal}
+			yyVAL.str = astLongBlob
 		}
 	case 89:
-		//line sql.y:457
+		//line sql.y:468
 		{
-			yyVAL.statement = &DropDatabase{Name: yyS[yypt-1].str, IfExists: yyS[yypt-2].boolVal}
+			yyVAL.intVal = int(SilentNull)
 		}
 	case 90:
-		//line sql.y:462
+		//line sql.y:470
 		{
-			setAllowComments(yylex, true)
+			yyVAL.intVal = int(Null)
 		}
 	case 91:
-		//line sql.y:466
+		//line sql.y:472
 		{
-			yyVAL.str2 = yyS[yypt-0].str2
-			setAllowComments(yylex, false)
+			yyVAL.intVal = int(NotNull)
 		}
 	case 92:
-		//line sql.y:472
+		//line sql.y:475
 		{
-			yyVAL.str2 = nil
+			yyVAL.valExpr = nil
 		}
 	case 93:
-		//line sql.y:476
+		//line sql.y:477
 		{
-			yyVAL.str2 = append(yyS[yypt-1].str2, yyS[yypt-0].str)
+			yyVAL.valExpr = yyS[yypt-0].valExpr
 		}
 	case 94:
-		//line sql.y:482
+		//line sql.y:480
 		{
-			yyVAL.str = astUnion
+			yyVAL.intVal = 0
 		}
 	case 95:
-		//line sql.y:486
+		//line sql.y:482
 		{
-			yyVAL.str = astUnionAll
+			yyVAL.intVal = 1
 		}
 	case 96:
-		//line sql.y:490
+		//line sql.y:484
 		{
-			yyVAL.str = astSetMinus
+			yyVAL.intVal = 1
 		}
 	case 97:
-		//line sql.y:494
+		//line sql.y:486
 		{
-			yyVAL.str = astExcept
+			yyVAL.intVal = 2
 		}
 	case 98:
-		//line sql.y:498
+		//line sql.y:488
 		{
-			yyVAL.str = astIntersect
+			yyVAL.intVal = 2
 		}
 	case 99:
-		//line sql.y:503
+		//line sql.y:492
 		{
-			yyVAL.str = ""
+			yyVAL.statement = &AlterTable{Name: yyS[yypt-1].tableName, Cmd: yyS[yypt-0].alterCmd}
 		}
 	case 100:
-		//line sql.y:507
+		//line sql.y:496
 		{
-			yyVAL.str = astDistinct
+			// Change this to a rename statement
+			yyVAL.statement = &RenameTable{Name: yyS[yypt-3].tableName, NewName: yyS[yypt-0].tableName}
 		}
 	case 101:
-		//line sql.y:513
+		//line sql.y:501
 		{
-			yyVAL.selectExprs = SelectExprs{yyS[yypt-0].selectExpr}
+			yyVAL.statement = &AlterView{Name: yyS[yypt-3].tableName, Columns: yyS[yypt-2].str2, Select: yyS[yypt-0].selStmt}
 		}
 	case 102:
-		//line sql.y:517
+		//line sql.y:507
 		{
-			yyVAL.selectExprs = append(yyVAL.selectExprs, yyS[yypt-0].selectExpr)
+			yyVAL.alterCmd = &AlterTableAddColumn{Column: yyS[yypt-0].columnDef}
 		}
 	case 103:
-		//line sql.y:523
+		//line sql.y:511
 		{
-			yyVAL.selectExpr = &StarExpr{}
+			yyVAL.alterCmd = &AlterTableDropColumn{Name: yyS[yypt-0].str}
 		}
 	case 104:
-		//line sql.y:527
+		//line sql.y:515
 		{
-			yyVAL.selectExpr = &NonStarExpr{Expr: yyS[yypt-1].expr, As: yyS[yypt-0].str}
+			yyVAL.alterCmd = &AlterTableRenameColumn{Name: yyS[yypt-2].str, NewName: yyS[yypt-0].str}
 		}
 	case 105:
-		//line sql.y:531
+		//line sql.y:521
 		{
-			yyVAL.selectExpr = &StarExpr{TableName: yyS[yypt-2].str}
+			yyVAL.statement = &RenameTable{Name: yyS[yypt-2].tableName, NewName: yyS[yypt-0].tableName}
 		}
 	case 106:
-		//line sql.y:537
+		//line sql.y:527
 		{
-			yyVAL.expr = yyS[yypt-0].boolExpr
+			yyVAL.statement = &TruncateTable{Name: yyS[yypt-0].tableName}
 		}
 	case 107:
-		//line sql.y:541
+		//line sql.y:533
 		{
-			yyVAL.expr = yyS[yypt-0].valExpr
+			yyVAL.statement = &Explain{Statement: yyS[yypt-0].selStmt}
 		}
 	case 108:
-		//line sql.y:546
+		//line sql.y:537
 		{
-			yyVAL.str = ""
+			yyVAL.statement = &Explain{Statement: yyS[yypt-0].statement}
 		}
 	case 109:
-		//line sql.y:550
+		//line sql.y:541
 		{
-			yyVAL.str = yyS[yypt-0].str
+			yyVAL.statement = &Explain{Statement: yyS[yypt-0].statement}
 		}
 	case 110:
-		//line sql.y:554
+		//line sql.y:547
 		{
-			yyVAL.str = yyS[yypt-0].str
+			yyVAL.statement = &BeginTransaction{}
 		}
 	case 111:
-		//line sql.y:560
+		//line sql.y:551
 		{
-			yyVAL.tableExprs = TableExprs{yyS[yypt-0].tableExpr}
+			yyVAL.statement = &BeginTransaction{}
 		}
 	case 112:
-		//line sql.y:564
+		//line sql.y:557
 		{
-			yyVAL.tableExprs = append(yyVAL.tableExprs, yyS[yypt-0].tableExpr)
+			yyVAL.statement = &CommitTransaction{}
 		}
 	case 113:
-		//line sql.y:570
+		//line sql.y:563
 		{
-			yyVAL.tableExpr = &AliasedTableExpr{Expr: yyS[yypt-2].smTableExpr, As: yyS[yypt-1].str, Hints: yyS[yypt-0].indexHints}
+			yyVAL.statement = &RollbackTransaction{}
 		}
 	case 114:
-		//line sql.y:574
+		//line sql.y:569
 		{
-			yyVAL.tableExpr = &ParenTableExpr{Expr: yyS[yypt-1].tableExpr}
+			yyVAL.statement = &Import{Table: yyS[yypt-3].tableName, Path: yyS[yypt-0].str}
 		}
 	case 115:
-		//line sql.y:578
+		//line sql.y:575
 		{
-			yyVAL.tableExpr = &JoinTableExpr{LeftExpr: yyS[yypt-2].tableExpr, Join: yyS[yypt-1].str, RightExpr: yyS[yypt-0].tableExpr}
+			yyVAL.statement = &Grant{Privileges: yyS[yypt-4].str2, Targets: yyS[yypt-2].targetList, Grantees: yyS[yypt-0].str2}
 		}
 	case 116:
-		//line sql.y:582
+		//line sql.y:581
 		{
-			yyVAL.tableExpr = &JoinTableExpr{LeftExpr: yyS[yypt-4].tableExpr, Join: yyS[yypt-3].str, RightExpr: yyS[yypt-2].tableExpr, Cond: &OnJoinCond{yyS[yypt-0].boolExpr}}
+			yyVAL.statement = &Revoke{Privileges: yyS[yypt-4].str2, Targets: yyS[yypt-2].targetList, Grantees: yyS[yypt-0].str2}
 		}
 	case 117:
-		//line sql.y:586
+		//line sql.y:587
 		{
-			yyVAL.tableExpr = &JoinTableExpr{LeftExpr: yyS[yypt-6].tableExpr, Join: yyS[yypt-5].str, RightExpr: yyS[yypt-4].tableExpr, Cond: &UsingJoinCond{yyS[yypt-1].columns}}
+			yyVAL.str2 = []string{"ALL"}
 		}
 	case 118:
-		//line sql.y:591
-		{
-			yyVAL.str = ""
-		}
+		yyVAL.str2 = yyS[yypt-0].str2
 	case 119:
-		//line sql.y:595
+		//line sql.y:594
 		{
-			yyVAL.str = yyS[yypt-0].str
+			yyVAL.str2 = []string{yyS[yypt-0].str}
 		}
 	case 120:
-		//line sql.y:599
+		//line sql.y:598
 		{
-			yyVAL.str = yyS[yypt-0].str
+			yyVAL.str2 = append(yyS[yypt-2].str2, yyS[yypt-0].str)
 		}
 	case 121:
-		//line sql.y:605
+		//line sql.y:604
 		{
-			yyVAL.str = astJoin
+			yyVAL.str = "SELECT"
 		}
 	case 122:
-		//line sql.y:609
+		//line sql.y:608
 		{
-			yyVAL.str = astStraightJoin
+			yyVAL.str = "INSERT"
 		}
 	case 123:
-		//line sql.y:613
+		//line sql.y:612
 		{
-			yyVAL.str = astLeftJoin
+			yyVAL.str = "UPDATE"
 		}
 	case 124:
-		//line sql.y:617
+		//line sql.y:616
 		{
-			yyVAL.str = astLeftJoin
+			yyVAL.str = "DELETE"
 		}
 	case 125:
-		//line sql.y:621
+		//line sql.y:622
 		{
-			yyVAL.str = astRightJoin
+			yyVAL.targetList = TargetList{Tables: yyS[yypt-0].tableNames}
 		}
 	case 126:
-		//line sql.y:625
+		//line sql.y:626
 		{
-			yyVAL.str = astRightJoin
+			yyVAL.targetList = TargetList{Tables: yyS[yypt-0].tableNames}
 		}
 	case 127:
-		//line sql.y:629
+		//line sql.y:630
 		{
-			yyVAL.str = astJoin
+			yyVAL.targetList = TargetList{Databases: yyS[yypt-0].str2}
 		}
 	case 128:
-		//line sql.y:633
+		//line sql.y:636
 		{
-			yyVAL.str = astCrossJoin
+			yyVAL.statement = &DropTable{Name: yyS[yypt-0].tableName, IfExists: yyS[yypt-1].boolVal}
 		}
 	case 129:
-		//line sql.y:637
+		//line sql.y:640
 		{
-			yyVAL.str = astNaturalJoin
+			yyVAL.statement = &DropIndex{Name: yyS[yypt-2].str, Table: yyS[yypt-0].tableName}
 		}
 	case 130:
-		//line sql.y:643
+		//line sql.y:644
 		{
-			yyVAL.smTableExpr = &TableName{Name: yyS[yypt-0].str}
+			yyVAL.statement = &DropView{Name: yyS[yypt-0].tableName, IfExists: yyS[yypt-1].boolVal}
 		}
 	case 131:
-		//line sql.y:647
+		//line sql.y:648
 		{
-			yyVAL.smTableExpr = &TableName{Qualifier: yyS[yypt-2].str, Name: yyS[yypt-0].str}
+			yyVAL.statement = &DropDatabase{Name: yyS[yypt-1].str, IfExists: yyS[yypt-2].boolVal}
 		}
 	case 132:
-		//line sql.y:651
+		//line sql.y:653
 		{
-			yyVAL.smTableExpr = yyS[yypt-0].subquery
+			setAllowComments(yylex, true)
 		}
 	case 133:
 		//line sql.y:657
 		{
-			yyVAL.tableName = &TableName{Name: yyS[yypt-0].str}
+			yyVAL.str2 = yyS[yypt-0].str2
+			setAllowComments(yylex, false)
 		}
 	case 134:
-		//line sql.y:661
+		//line sql.y:663
 		{
-			yyVAL.tableName = &TableName{Qualifier: yyS[yypt-2].str, Name: yyS[yypt-0].str}
+			yyVAL.str2 = nil
 		}
 	case 135:
 		//line sql.y:667
 		{
-			yyVAL.tableName = &TableName{Name: yyS[yypt-0].str}
+			yyVAL.str2 = append(yyS[yypt-1].str2, yyS[yypt-0].str)
 		}
 	case 136:
-		//line sql.y:671
+		//line sql.y:673
 		{
-			yyVAL.tableName = &TableName{Qualifier: yyS[yypt-2].str, Name: yyS[yypt-0].str}
+			yyVAL.str = astUnion
 		}
 	case 137:
-		//line sql.y:676
+		//line sql.y:677
 		{
-			yyVAL.indexHints = nil
+			yyVAL.str = astUnionAll
 		}
 	case 138:
-		//line sql.y:680
+		//line sql.y:681
 		{
-			yyVAL.indexHints = &IndexHints{Type: astUse, Indexes: yyS[yypt-1].str2}
+			yyVAL.str = astSetMinus
 		}
 	case 139:
-		//line sql.y:684
+		//line sql.y:685
 		{
-			yyVAL.indexHints = &IndexHints{Type: astIgnore, Indexes: yyS[yypt-1].str2}
+			yyVAL.str = astExcept
 		}
 	case 140:
-		//line sql.y:688
+		//line sql.y:689
 		{
-			yyVAL.indexHints = &IndexHints{Type: astForce, Indexes: yyS[yypt-1].str2}
+			yyVAL.str = astIntersect
 		}
 	case 141:
 		//line sql.y:694
 		{
-			yyVAL.str2 = []string{yyS[yypt-0].str}
+			yyVAL.str = ""
 		}
 	case 142:
 		//line sql.y:698
 		{
-			yyVAL.str2 = append(yyS[yypt-2].str2, yyS[yypt-0].str)
+			yyVAL.str = astDistinct
 		}
 	case 143:
-		//line sql.y:703
+		//line sql.y:704
 		{
-			yyVAL.boolExpr = nil
+			yyVAL.selectExprs = SelectExprs{yyS[yypt-0].selectExpr}
 		}
 	case 144:
-		//line sql.y:707
+		//line sql.y:708
 		{
-			yyVAL.boolExpr = yyS[yypt-0].boolExpr
+			yyVAL.selectExprs = append(yyVAL.selectExprs, yyS[yypt-0].selectExpr)
 		}
 	case 145:
-		yyVAL.boolExpr = yyS[yypt-0].boolExpr
-	case 146:
 		//line sql.y:714
 		{
-			yyVAL.boolExpr = &AndExpr{Op: string(yyS[yypt-1].str), Left: yyS[yypt-2].boolExpr, Right: yyS[yypt-0].boolExpr}
+			yyVAL.selectExpr = &StarExpr{}
 		}
-	case 147:
+	case 146:
 		//line sql.y:718
 		{
-			yyVAL.boolExpr = &OrExpr{Op: string(yyS[yypt-1].str), Left: yyS[yypt-2].boolExpr, Right: yyS[yypt-0].boolExpr}
+			yyVAL.selectExpr = &NonStarExpr{Expr: yyS[yypt-1].expr, As: yyS[yypt-0].str}
 		}
-	case 148:
+	case 147:
 		//line sql.y:722
 		{
-			yyVAL.boolExpr = &NotExpr{Op: string(yyS[yypt-1].str), Expr: yyS[yypt-0].boolExpr}
+			yyVAL.selectExpr = &StarExpr{TableName: yyS[yypt-2].str}
+		}
+	case 148:
+		//line sql.y:728
+		{
+			yyVAL.expr = yyS[yypt-0].boolExpr
 		}
 	case 149:
-		//line sql.y:726
+		//line sql.y:732
 		{
-			yyVAL.boolExpr = &ParenBoolExpr{Expr: yyS[yypt-1].boolExpr}
+			yyVAL.expr = yyS[yypt-0].valExpr
 		}
 	case 150:
-		//line sql.y:732
+		//line sql.y:737
 		{
-			yyVAL.boolExpr = &ComparisonExpr{Left: yyS[yypt-2].valExpr, Operator: yyS[yypt-1].str, Right: yyS[yypt-0].valExpr}
+			yyVAL.str = ""
 		}
 	case 151:
-		//line sql.y:736
+		//line sql.y:741
 		{
-			yyVAL.boolExpr = &ComparisonExpr{Left: yyS[yypt-2].valExpr, Operator: astIn, Right: yyS[yypt-0].tuple}
+			yyVAL.str = yyS[yypt-0].str
 		}
 	case 152:
-		//line sql.y:740
+		//line sql.y:745
 		{
-			yyVAL.boolExpr = &ComparisonExpr{Left: yyS[yypt-3].valExpr, Operator: astNotIn, Right: yyS[yypt-0].tuple}
+			yyVAL.str = yyS[yypt-0].str
 		}
 	case 153:
-		//line sql.y:744
+		//line sql.y:751
 		{
-			yyVAL.boolExpr = &ComparisonExpr{Left: yyS[yypt-2].valExpr, Operator: astLike, Right: yyS[yypt-0].valExpr}
+			yyVAL.tableExprs = TableExprs{yyS[yypt-0].tableExpr}
 		}
 	case 154:
-		//line sql.y:748
+		//line sql.y:755
 		{
-			yyVAL.boolExpr = &ComparisonExpr{Left: yyS[yypt-3].valExpr, Operator: astNotLike, Right: yyS[yypt-0].valExpr}
+			yyVAL.tableExprs = append(yyVAL.tableExprs, yyS[yypt-0].tableExpr)
 		}
 	case 155:
-		//line sql.y:752
+		//line sql.y:761
 		{
-			yyVAL.boolExpr = &RangeCond{Left: yyS[yypt-4].valExpr, Operator: astBetween, From: yyS[yypt-2].valExpr, To: yyS[yypt-0].valExpr}
+			yyVAL.tableExpr = &AliasedTableExpr{Expr: yyS[yypt-2].smTableExpr, As: yyS[yypt-1].str, Hints: yyS[yypt-0].indexHints}
 		}
 	case 156:
-		//line sql.y:756
+		//line sql.y:765
 		{
-			yyVAL.boolExpr = &RangeCond{Left: yyS[yypt-5].valExpr, Operator: astNotBetween, From: yyS[yypt-2].valExpr, To: yyS[yypt-0].valExpr}
+			yyVAL.tableExpr = &ParenTableExpr{Expr: yyS[yypt-1].tableExpr}
 		}
 	case 157:
-		//line sql.y:760
+		//line sql.y:769
 		{
-			yyVAL.boolExpr = &NullCheck{Operator: astNull, Expr: yyS[yypt-2].valExpr}
+			yyVAL.tableExpr = &JoinTableExpr{LeftExpr: yyS[yypt-2].tableExpr, Join: yyS[yypt-1].str, RightExpr: yyS[yypt-0].tableExpr}
 		}
 	case 158:
-		//line sql.y:764
+		//line sql.y:773
 		{
-			yyVAL.boolExpr = &NullCheck{Operator: astNotNull, Expr: yyS[yypt-3].valExpr}
+			yyVAL.tableExpr = &JoinTableExpr{LeftExpr: yyS[yypt-4].tableExpr, Join: yyS[yypt-3].str, RightExpr: yyS[yypt-2].tableExpr, Cond: &OnJoinCond{yyS[yypt-0].boolExpr}}
 		}
 	case 159:
-		//line sql.y:768
+		//line sql.y:777
 		{
-			yyVAL.boolExpr = &ExistsExpr{Subquery: yyS[yypt-0].subquery}
+			yyVAL.tableExpr = &JoinTableExpr{LeftExpr: yyS[yypt-6].tableExpr, Join: yyS[yypt-5].str, RightExpr: yyS[yypt-4].tableExpr, Cond: &UsingJoinCond{yyS[yypt-1].columns}}
 		}
 	case 160:
-		//line sql.y:774
+		//line sql.y:782
 		{
-			yyVAL.str = astEQ
+			yyVAL.str = ""
 		}
 	case 161:
-		//line sql.y:778
+		//line sql.y:786
 		{
-			yyVAL.str = astLT
+			yyVAL.str = yyS[yypt-0].str
 		}
 	case 162:
-		//line sql.y:782
+		//line sql.y:790
 		{
-			yyVAL.str = astGT
+			yyVAL.str = yyS[yypt-0].str
 		}
 	case 163:
-		//line sql.y:786
+		//line sql.y:796
 		{
-			yyVAL.str = astLE
+			yyVAL.str = astJoin
 		}
 	case 164:
-		//line sql.y:790
+		//line sql.y:800
 		{
-			yyVAL.str = astGE
+			yyVAL.str = astStraightJoin
 		}
 	case 165:
-		//line sql.y:794
+		//line sql.y:804
 		{
-			yyVAL.str = astNE
+			yyVAL.str = astLeftJoin
 		}
 	case 166:
-		//line sql.y:798
+		//line sql.y:808
 		{
-			yyVAL.str = astNSE
+			yyVAL.str = astLeftJoin
 		}
 	case 167:
-		//line sql.y:804
+		//line sql.y:812
 		{
-			yyVAL.insRows = yyS[yypt-0].values
+			yyVAL.str = astRightJoin
 		}
 	case 168:
-		//line sql.y:808
+		//line sql.y:816
 		{
-			yyVAL.insRows = yyS[yypt-0].selStmt
+			yyVAL.str = astRightJoin
 		}
 	case 169:
-		//line sql.y:814
+		//line sql.y:820
 		{
-			yyVAL.values = Values{yyS[yypt-0].tuple}
+			yyVAL.str = astJoin
 		}
 	case 170:
-		//line sql.y:818
+		//line sql.y:824
 		{
-			yyVAL.values = append(yyS[yypt-2].values, yyS[yypt-0].tuple)
+			yyVAL.str = astCrossJoin
 		}
 	case 171:
-		//line sql.y:824
+		//line sql.y:828
 		{
-			yyVAL.tuple = ValTuple(yyS[yypt-1].valExprs)
+			yyVAL.str = astNaturalJoin
 		}
 	case 172:
-		//line sql.y:828
+		//line sql.y:834
 		{
-			yyVAL.tuple = yyS[yypt-0].subquery
+			yyVAL.smTableExpr = &TableName{Name: yyS[yypt-0].str}
 		}
 	case 173:
-		//line sql.y:834
+		//line sql.y:838
 		{
-			yyVAL.subquery = &Subquery{yyS[yypt-1].selStmt}
+			yyVAL.smTableExpr = &TableName{Qualifier: yyS[yypt-2].str, Name: yyS[yypt-0].str}
 		}
 	case 174:
-		//line sql.y:840
+		//line sql.y:842
 		{
-			yyVAL.valExprs = ValExprs{yyS[yypt-0].valExpr}
+			yyVAL.smTableExpr = yyS[yypt-0].subquery
 		}
 	case 175:
-		//line sql.y:844
+		//line sql.y:848
 		{
-			yyVAL.valExprs = append(yyS[yypt-2].valExprs, yyS[yypt-0].valExpr)
+			yyVAL.tableName = &TableName{Name: yyS[yypt-0].str}
 		}
 	case 176:
-		//line sql.y:850
+		//line sql.y:852
 		{
-			yyVAL.valExpr = yyS[yypt-0].valExpr
+			yyVAL.tableName = &TableName{Qualifier: yyS[yypt-2].str, Name: yyS[yypt-0].str}
 		}
 	case 177:
-		//line sql.y:854
+		//line sql.y:858
 		{
-			yyVAL.valExpr = yyS[yypt-0].colName
+			yyVAL.tableName = &TableName{Name: yyS[yypt-0].str}
 		}
 	case 178:
-		//line sql.y:858
+		//line sql.y:862
 		{
-			yyVAL.valExpr = yyS[yypt-0].tuple
+			yyVAL.tableName = &TableName{Qualifier: yyS[yypt-2].str, Name: yyS[yypt-0].str}
 		}
 	case 179:
-		//line sql.y:862
+		//line sql.y:868
 		{
-			yyVAL.valExpr = &BinaryExpr{Left: yyS[yypt-2].valExpr, Operator: astBitand, Right: yyS[yypt-0].valExpr}
+			yyVAL.tableNames = []*TableName{yyS[yypt-0].tableName}
 		}
 	case 180:
-		//line sql.y:866
+		//line sql.y:872
 		{
-			yyVAL.valExpr = &BinaryExpr{Left: yyS[yypt-2].valExpr, Operator: astBitor, Right: yyS[yypt-0].valExpr}
+			yyVAL.tableNames = append(yyS[yypt-2].tableNames, yyS[yypt-0].tableName)
 		}
 	case 181:
-		//line sql.y:870
+		//line sql.y:877
 		{
-			yyVAL.valExpr = &BinaryExpr{Left: yyS[yypt-2].valExpr, Operator: astBitxor, Right: yyS[yypt-0].valExpr}
+			yyVAL.indexHints = nil
 		}
 	case 182:
-		//line sql.y:874
+		//line sql.y:881
 		{
-			yyVAL.valExpr = &BinaryExpr{Left: yyS[yypt-2].valExpr, Operator: astPlus, Right: yyS[yypt-0].valExpr}
+			yyVAL.indexHints = &IndexHints{Type: astUse, Indexes: yyS[yypt-1].str2}
 		}
 	case 183:
-		//line sql.y:878
+		//line sql.y:885
 		{
-			yyVAL.valExpr = &BinaryExpr{Left: yyS[yypt-2].valExpr, Operator: astMinus, Right: yyS[yypt-0].valExpr}
+			yyVAL.indexHints = &IndexHints{Type: astIgnore, Indexes: yyS[yypt-1].str2}
 		}
 	case 184:
-		//line sql.y:882
+		//line sql.y:889
 		{
-			yyVAL.valExpr = &BinaryExpr{Left: yyS[yypt-2].valExpr, Operator: astMult, Right: yyS[yypt-0].valExpr}
+			yyVAL.indexHints = &IndexHints{Type: astForce, Indexes: yyS[yypt-1].str2}
 		}
 	case 185:
-		//line sql.y:886
+		//line sql.y:894
 		{
-			yyVAL.valExpr = &BinaryExpr{Left: yyS[yypt-2].valExpr, Operator: astDiv, Right: yyS[yypt-0].valExpr}
+			yyVAL.str2 = nil
 		}
 	case 186:
-		//line sql.y:890
+		//line sql.y:898
 		{
-			yyVAL.valExpr = &BinaryExpr{Left: yyS[yypt-2].valExpr, Operator: astMod, Right: yyS[yypt-0].valExpr}
+			yyVAL.str2 = yyS[yypt-1].str2
 		}
 	case 187:
-		//line sql.y:894
+		//line sql.y:904
+		{
+			yyVAL.str2 = []string{yyS[yypt-0].str}
+		}
+	case 188:
+		//line sql.y:908
+		{
+			yyVAL.str2 = append(yyS[yypt-2].str2, yyS[yypt-0].str)
+		}
+	case 189:
+		//line sql.y:913
+		{
+			yyVAL.boolExpr = nil
+		}
+	case 190:
+		//line sql.y:917
+		{
+			yyVAL.boolExpr = yyS[yypt-0].boolExpr
+		}
+	case 191:
+		yyVAL.boolExpr = yyS[yypt-0].boolExpr
+	case 192:
+		//line sql.y:924
+		{
+			yyVAL.boolExpr = &AndExpr{Op: string(yyS[yypt-1].str), Left: yyS[yypt-2].boolExpr, Right: yyS[yypt-0].boolExpr}
+		}
+	case 193:
+		//line sql.y:928
+		{
+			yyVAL.boolExpr = &OrExpr{Op: string(yyS[yypt-1].str), Left: yyS[yypt-2].boolExpr, Right: yyS[yypt-0].boolExpr}
+		}
+	case 194:
+		//line sql.y:932
+		{
+			yyVAL.boolExpr = &NotExpr{Op: string(yyS[yypt-1].str), Expr: yyS[yypt-0].boolExpr}
+		}
+	case 195:
+		//line sql.y:936
+		{
+			yyVAL.boolExpr = &ParenBoolExpr{Expr: yyS[yypt-1].boolExpr}
+		}
+	case 196:
+		//line sql.y:942
+		{
+			yyVAL.boolExpr = &ComparisonExpr{Left: yyS[yypt-2].valExpr, Operator: yyS[yypt-1].str, Right: yyS[yypt-0].valExpr}
+		}
+	case 197:
+		//line sql.y:946
+		{
+			yyVAL.boolExpr = &ComparisonExpr{Left: yyS[yypt-2].valExpr, Operator: astIn, Right: yyS[yypt-0].tuple}
+		}
+	case 198:
+		//line sql.y:950
+		{
+			yyVAL.boolExpr = &ComparisonExpr{Left: yyS[yypt-3].valExpr, Operator: astNotIn, Right: yyS[yypt-0].tuple}
+		}
+	case 199:
+		//line sql.y:954
+		{
+			yyVAL.boolExpr = &ComparisonExpr{Left: yyS[yypt-2].valExpr, Operator: astLike, Right: yyS[yypt-0].valExpr}
+		}
+	case 200:
+		//line sql.y:958
+		{
+			yyVAL.boolExpr = &ComparisonExpr{Left: yyS[yypt-3].valExpr, Operator: astNotLike, Right: yyS[yypt-0].valExpr}
+		}
+	case 201:
+		//line sql.y:962
+		{
+			yyVAL.boolExpr = &RangeCond{Left: yyS[yypt-4].valExpr, Operator: astBetween, From: yyS[yypt-2].valExpr, To: yyS[yypt-0].valExpr}
+		}
+	case 202:
+		//line sql.y:966
+		{
+			yyVAL.boolExpr = &RangeCond{Left: yyS[yypt-5].valExpr, Operator: astNotBetween, From: yyS[yypt-2].valExpr, To: yyS[yypt-0].valExpr}
+		}
+	case 203:
+		//line sql.y:970
+		{
+			yyVAL.boolExpr = &NullCheck{Operator: astNull, Expr: yyS[yypt-2].valExpr}
+		}
+	case 204:
+		//line sql.y:974
+		{
+			yyVAL.boolExpr = &NullCheck{Operator: astNotNull, Expr: yyS[yypt-3].valExpr}
+		}
+	case 205:
+		//line sql.y:978
+		{
+			yyVAL.boolExpr = &ExistsExpr{Subquery: yyS[yypt-0].subquery}
+		}
+	case 206:
+		//line sql.y:984
+		{
+			yyVAL.str = astEQ
+		}
+	case 207:
+		//line sql.y:988
+		{
+			yyVAL.str = astLT
+		}
+	case 208:
+		//line sql.y:992
+		{
+			yyVAL.str = astGT
+		}
+	case 209:
+		//line sql.y:996
+		{
+			yyVAL.str = astLE
+		}
+	case 210:
+		//line sql.y:1000
+		{
+			yyVAL.str = astGE
+		}
+	case 211:
+		//line sql.y:1004
+		{
+			yyVAL.str = astNE
+		}
+	case 212:
+		//line sql.y:1008
+		{
+			yyVAL.str = astNSE
+		}
+	case 213:
+		//line sql.y:1014
+		{
+			yyVAL.insRows = yyS[yypt-0].values
+		}
+	case 214:
+		//line sql.y:1018
+		{
+			yyVAL.insRows = yyS[yypt-0].selStmt
+		}
+	case 215:
+		//line sql.y:1024
+		{
+			yyVAL.values = Values{yyS[yypt-0].tuple}
+		}
+	case 216:
+		//line sql.y:1028
+		{
+			yyVAL.values = append(yyS[yypt-2].values, yyS[yypt-0].tuple)
+		}
+	case 217:
+		//line sql.y:1034
+		{
+			yyVAL.tuple = ValTuple(yyS[yypt-1].valExprs)
+		}
+	case 218:
+		//line sql.y:1038
+		{
+			yyVAL.tuple = yyS[yypt-0].subquery
+		}
+	case 219:
+		//line sql.y:1044
+		{
+			yyVAL.subquery = &Subquery{yyS[yypt-1].selStmt}
+		}
+	case 220:
+		//line sql.y:1050
+		{
+			yyVAL.valExprs = ValExprs{yyS[yypt-0].valExpr}
+		}
+	case 221:
+		//line sql.y:1054
+		{
+			yyVAL.valExprs = append(yyS[yypt-2].valExprs, yyS[yypt-0].valExpr)
+		}
+	case 222:
+		//line sql.y:1060
+		{
+			yyVAL.valExpr = yyS[yypt-0].valExpr
+		}
+	case 223:
+		//line sql.y:1064
+		{
+			yyVAL.valExpr = yyS[yypt-0].colName
+		}
+	case 224:
+		//line sql.y:1068
+		{
+			yyVAL.valExpr = yyS[yypt-0].tuple
+		}
+	case 225:
+		//line sql.y:1072
+		{
+			yyVAL.valExpr = &BinaryExpr{Left: yyS[yypt-2].valExpr, Operator: astBitand, Right: yyS[yypt-0].valExpr}
+		}
+	case 226:
+		//line sql.y:1076
+		{
+			yyVAL.valExpr = &BinaryExpr{Left: yyS[yypt-2].valExpr, Operator: astBitor, Right: yyS[yypt-0].valExpr}
+		}
+	case 227:
+		//line sql.y:1080
+		{
+			yyVAL.valExpr = &BinaryExpr{Left: yyS[yypt-2].valExpr, Operator: astBitxor, Right: yyS[yypt-0].valExpr}
+		}
+	case 228:
+		//line sql.y:1084
+		{
+			yyVAL.valExpr = &BinaryExpr{Left: yyS[yypt-2].valExpr, Operator: astPlus, Right: yyS[yypt-0].valExpr}
+		}
+	case 229:
+		//line sql.y:1088
+		{
+			yyVAL.valExpr = &BinaryExpr{Left: yyS[yypt-2].valExpr, Operator: astMinus, Right: yyS[yypt-0].valExpr}
+		}
+	case 230:
+		//line sql.y:1092
+		{
+			yyVAL.valExpr = &BinaryExpr{Left: yyS[yypt-2].valExpr, Operator: astMult, Right: yyS[yypt-0].valExpr}
+		}
+	case 231:
+		//line sql.y:1096
+		{
+			yyVAL.valExpr = &BinaryExpr{Left: yyS[yypt-2].valExpr, Operator: astDiv, Right: yyS[yypt-0].valExpr}
+		}
+	case 232:
+		//line sql.y:1100
+		{
+			yyVAL.valExpr = &BinaryExpr{Left: yyS[yypt-2].valExpr, Operator: astMod, Right: yyS[yypt-0].valExpr}
+		}
+	case 233:
+		//line sql.y:1104
 		{
 			if num, ok := yyS[yypt-0].valExpr.(NumVal); ok {
 				switch yyS[yypt-1].byt {
@@ -1826,218 +2116,232 @@ yydefault:
 				yyVAL.valExpr = &UnaryExpr{Operator: yyS[yypt-1].byt, Expr: yyS[yypt-0].valExpr}
 			}
 		}
-	case 188:
-		//line sql.y:909
+	case 234:
+		//line sql.y:1119
 		{
 			yyVAL.valExpr = &FuncExpr{Name: strings.ToUpper(yyS[yypt-2].str)}
 		}
-	case 189:
-		//line sql.y:913
+	case 235:
+		//line sql.y:1123
 		{
 			yyVAL.valExpr = &FuncExpr{Name: strings.ToUpper(yyS[yypt-3].str), Exprs: yyS[yypt-1].selectExprs}
 		}
-	case 190:
-		//line sql.y:917
+	case 236:
+		//line sql.y:1127
 		{
 			yyVAL.valExpr = &FuncExpr{Name: strings.ToUpper(yyS[yypt-4].str), Distinct: true, Exprs: yyS[yypt-1].selectExprs}
 		}
-	case 191:
-		//line sql.y:921
+	case 237:
+		//line sql.y:1131
 		{
 			yyVAL.valExpr = &FuncExpr{Name: strings.ToUpper(yyS[yypt-3].str), Exprs: yyS[yypt-1].selectExprs}
 		}
-	case 192:
-		//line sql.y:925
+	case 238:
+		//line sql.y:1135
 		{
 			yyVAL.valExpr = yyS[yypt-0].caseExpr
 		}
-	case 193:
-		//line sql.y:931
+	case 239:
+		//line sql.y:1141
 		{
 			yyVAL.str = "IF"
 		}
-	case 194:
-		//line sql.y:935
+	case 240:
+		//line sql.y:1145
 		{
 			yyVAL.str = "VALUES"
 		}
-	case 195:
-		//line sql.y:941
+	case 241:
+		//line sql.y:1151
 		{
 			yyVAL.byt = astUnaryPlus
 		}
-	case 196:
-		//line sql.y:945
+	case 242:
+		//line sql.y:1155
 		{
 			yyVAL.byt = astUnaryMinus
 		}
-	case 197:
-		//line sql.y:949
+	case 243:
+		//line sql.y:1159
 		{
 			yyVAL.byt = astTilda
 		}
-	case 198:
-		//line sql.y:955
+	case 244:
+		//line sql.y:1165
 		{
 			yyVAL.caseExpr = &CaseExpr{Expr: yyS[yypt-3].valExpr, Whens: yyS[yypt-2].whens, Else: yyS[yypt-1].valExpr}
 		}
-	case 199:
-		//line sql.y:960
+	case 245:
+		//line sql.y:1170
 		{
 			yyVAL.valExpr = nil
 		}
-	case 200:
-		//line sql.y:964
+	case 246:
+		//line sql.y:1174
 		{
 			yyVAL.valExpr = yyS[yypt-0].valExpr
 		}
-	case 201:
-		//line sql.y:970
+	case 247:
+		//line sql.y:1180
 		{
 			yyVAL.whens = []*When{yyS[yypt-0].when}
 		}
-	case 202:
-		//line sql.y:974
+	case 248:
+		//line sql.y:1184
 		{
 			yyVAL.whens = append(yyS[yypt-1].whens, yyS[yypt-0].when)
 		}
-	case 203:
-		//line sql.y:980
+	case 249:
+		//line sql.y:1190
 		{
 			yyVAL.when = &When{Cond: yyS[yypt-2].boolExpr, Val: yyS[yypt-0].valExpr}
 		}
-	case 204:
-		//line sql.y:985
+	case 250:
+		//line sql.y:1195
 		{
 			yyVAL.valExpr = nil
 		}
-	case 205:
-		//line sql.y:989
+	case 251:
+		//line sql.y:1199
 		{
 			yyVAL.valExpr = yyS[yypt-0].valExpr
 		}
-	case 206:
-		//line sql.y:995
+	case 252:
+		//line sql.y:1205
 		{
 			yyVAL.colName = &ColName{Name: yyS[yypt-0].str}
 		}
-	case 207:
-		//line sql.y:999
+	case 253:
+		//line sql.y:1209
 		{
 			yyVAL.colName = &ColName{Qualifier: yyS[yypt-2].str, Name: yyS[yypt-0].str}
 		}
-	case 208:
-		//line sql.y:1005
+	case 254:
+		//line sql.y:1215
 		{
 			yyVAL.valExpr = StrVal(yyS[yypt-0].str)
 		}
-	case 209:
-		//line sql.y:1009
+	case 255:
+		//line sql.y:1219
 		{
 			yyVAL.valExpr = NumVal(yyS[yypt-0].str)
 		}
-	case 210:
-		//line sql.y:1013
+	case 256:
+		//line sql.y:1223
 		{
 			yyVAL.valExpr = ValArg(yyS[yypt-0].str)
 		}
-	case 211:
-		//line sql.y:1017
+	case 257:
+		//line sql.y:1227
 		{
 			yyVAL.valExpr = &NullVal{}
 		}
-	case 212:
-		//line sql.y:1022
+	case 258:
+		//line sql.y:1232
 		{
 			yyVAL.valExprs = nil
 		}
-	case 213:
-		//line sql.y:1026
+	case 259:
+		//line sql.y:1236
 		{
 			yyVAL.valExprs = yyS[yypt-0].valExprs
 		}
-	case 214:
-		//line sql.y:1031
+	case 260:
+		//line sql.y:1241
 		{
 			yyVAL.boolExpr = nil
 		}
-	case 215:
-		//line sql.y:1035
+	case 261:
+		//line sql.y:1245
 		{
 			yyVAL.boolExpr = yyS[yypt-0].boolExpr
 		}
-	case 216:
-		//line sql.y:1040
+	case 262:
+		//line sql.y:1250
 		{
 			yyVAL.orderBy = nil
 		}
-	case 217:
-		//line sql.y:1044
+	case 263:
+		//line sql.y:1254
 		{
 			yyVAL.orderBy = yyS[yypt-0].orderBy
 		}
-	case 218:
-		//line sql.y:1050
+	case 264:
+		//line sql.y:1260
 		{
 			yyVAL.orderBy = OrderBy{yyS[yypt-0].order}
 		}
-	case 219:
-		//line sql.y:1054
+	case 265:
+		//line sql.y:1264
 		{
 			yyVAL.orderBy = append(yyS[yypt-2].orderBy, yyS[yypt-0].order)
 		}
-	case 220:
-		//line sql.y:1060
+	case 266:
+		//line sql.y:1270
 		{
 			yyVAL.order = &Order{Expr: yyS[yypt-1].valExpr, Direction: yyS[yypt-0].str}
 		}
-	case 221:
-		//line sql.y:1065
+	case 267:
+		//line sql.y:1275
 		{
 			yyVAL.str = astAsc
 		}
-	case 222:
-		//line sql.y:1069
+	case 268:
+		//line sql.y:1279
 		{
 			yyVAL.str = astAsc
 		}
-	case 223:
-		//line sql.y:1073
+	case 269:
+		//line sql.y:1283
 		{
 			yyVAL.str = astDesc
 		}
-	case 224:
-		//line sql.y:1078
+	case 270:
+		//line sql.y:1288
 		{
 			yyVAL.limit = nil
 		}
-	case 225:
-		//line sql.y:1082
+	case 271:
+		//line sql.y:1292
 		{
 			yyVAL.limit = &Limit{Rowcount: yyS[yypt-0].valExpr}
 		}
-	case 226:
-		//line sql.y:1086
+	case 272:
+		//line sql.y:1296
 		{
 			yyVAL.limit = &Limit{Offset: yyS[yypt-2].valExpr, Rowcount: yyS[yypt-0].valExpr}
 		}
-	case 227:
-		//line sql.y:1090
+	case 273:
+		//line sql.y:1300
 		{
 			yyVAL.limit = &Limit{Offset: yyS[yypt-0].valExpr, Rowcount: yyS[yypt-2].valExpr}
 		}
-	case 228:
-		//line sql.y:1095
+	case 274:
+		//line sql.y:1305
+		{
+			yyVAL.asOf = nil
+		}
+	case 275:
+		//line sql.y:1309
+		{
+			if yyS[yypt-2].str != "system" {
+				yylex.Error("expecting system")
+				return 1
+			}
+			yyVAL.asOf = &AsOf{Expr: yyS[yypt-0].valExpr}
+		}
+	case 276:
+		//line sql.y:1318
 		{
 			yyVAL.str = ""
 		}
-	case 229:
-		//line sql.y:1099
+	case 277:
+		//line sql.y:1322
 		{
 			yyVAL.str = astForUpdate
 		}
-	case 230:
-		//line sql.y:1103
+	case 278:
+		//line sql.y:1326
 		{
 			if yyS[yypt-1].str != "share" {
 				yylex.Error("expecting share")
@@ -2049,138 +2353,133 @@ yydefault:
 			}
 			yyVAL.str = astShareMode
 		}
-	case 231:
-		//line sql.y:1116
+	case 279:
+		//line sql.y:1339
 		{
 			yyVAL.columns = nil
 		}
-	case 232:
-		//line sql.y:1120
+	case 280:
+		//line sql.y:1343
 		{
 			yyVAL.columns = yyS[yypt-1].columns
 		}
-	case 233:
-		//line sql.y:1126
+	case 281:
+		//line sql.y:1349
 		{
 			yyVAL.columns = Columns{&NonStarExpr{Expr: yyS[yypt-0].colName}}
 		}
-	case 234:
-		//line sql.y:1130
+	case 282:
+		//line sql.y:1353
 		{
 			yyVAL.columns = append(yyVAL.columns, &NonStarExpr{Expr: yyS[yypt-0].colName})
 		}
-	case 235:
-		//line sql.y:1135
+	case 283:
+		//line sql.y:1358
 		{
 			yyVAL.updateExprs = nil
 		}
-	case 236:
-		//line sql.y:1139
+	case 284:
+		//line sql.y:1362
 		{
 			yyVAL.updateExprs = yyS[yypt-0].updateExprs
 		}
-	case 237:
-		//line sql.y:1145
+	case 285:
+		//line sql.y:1368
 		{
 			yyVAL.updateExprs = UpdateExprs{yyS[yypt-0].updateExpr}
 		}
-	case 238:
-		//line sql.y:1149
+	case 286:
+		//line sql.y:1372
 		{
 			yyVAL.updateExprs = append(yyS[yypt-2].updateExprs, yyS[yypt-0].updateExpr)
 		}
-	case 239:
-		//line sql.y:1155
+	case 287:
+		//line sql.y:1378
 		{
 			yyVAL.updateExpr = &UpdateExpr{Name: yyS[yypt-2].colName, Expr: yyS[yypt-0].valExpr}
 		}
-	case 240:
-		//line sql.y:1160
+	case 288:
+		//line sql.y:1383
 		{
 			yyVAL.boolVal = false
 		}
-	case 241:
-		//line sql.y:1162
+	case 289:
+		//line sql.y:1385
 		{
 			yyVAL.boolVal = true
 		}
-	case 242:
-		//line sql.y:1165
+	case 290:
+		//line sql.y:1388
 		{
 			yyVAL.boolVal = false
 		}
-	case 243:
-		//line sql.y:1167
+	case 291:
+		//line sql.y:1390
 		{
 			yyVAL.boolVal = true
 		}
-	case 244:
-		//line sql.y:1170
+	case 292:
+		//line sql.y:1393
 		{
 			yyVAL.empty = struct{}{}
 		}
-	case 245:
-		//line sql.y:1172
+	case 293:
+		//line sql.y:1395
 		{
 			yyVAL.empty = struct{}{}
 		}
-	case 246:
-		//line sql.y:1176
+	case 294:
+		//line sql.y:1398
 		{
 			yyVAL.empty = struct{}{}
 		}
-	case 247:
-		//line sql.y:1178
+	case 295:
+		//line sql.y:1400
 		{
 			yyVAL.empty = struct{}{}
 		}
-	case 248:
-		//line sql.y:1180
+	case 296:
+		//line sql.y:1403
 		{
 			yyVAL.empty = struct{}{}
 		}
-	case 249:
-		//line sql.y:1182
+	case 297:
+		//line sql.y:1405
 		{
 			yyVAL.empty = struct{}{}
 		}
-	case 250:
-		//line sql.y:1184
-		{
-			yyVAL.empty = struct{}{}
-		}
-	case 251:
-		//line sql.y:1187
+	case 298:
+		//line sql.y:1408
 		{
 			yyVAL.empty = struct{}{}
 		}
-	case 252:
-		//line sql.y:1189
+	case 299:
+		//line sql.y:1410
 		{
 			yyVAL.empty = struct{}{}
 		}
-	case 253:
-		//line sql.y:1192
+	case 300:
+		//line sql.y:1413
 		{
 			yyVAL.boolVal = false
 		}
-	case 254:
-		//line sql.y:1194
+	case 301:
+		//line sql.y:1415
 		{
 			yyVAL.boolVal = true
 		}
-	case 255:
-		//line sql.y:1197
+	case 302:
+		//line sql.y:1418
 		{
 			yyVAL.intVal = 0
 		}
-	case 256:
-		//line sql.y:1199
+	case 303:
+		//line sql.y:1420
 		{
 			yyVAL.intVal = yyS[yypt-1].intVal
 		}
-	case 257:
-		//line sql.y:1203
+	case 304:
+		//line sql.y:1424
 		{
 			i, ok := parseInt(yylex, yyS[yypt-0].str)
 			if !ok {
@@ -2188,73 +2487,73 @@ yydefault:
 			}
 			yyVAL.intVal = i
 		}
-	case 258:
-		//line sql.y:1212
+	case 305:
+		//line sql.y:1433
 		{
 			yyVAL.intVal2[0], yyVAL.intVal2[1] = 0, 0
 		}
-	case 259:
-		//line sql.y:1214
+	case 306:
+		//line sql.y:1435
 		{
 			yyVAL.intVal2[0], yyVAL.intVal2[1] = yyS[yypt-3].intVal, yyS[yypt-1].intVal
 		}
-	case 260:
-		//line sql.y:1217
+	case 307:
+		//line sql.y:1438
 		{
 			yyVAL.intVal2[0], yyVAL.intVal2[1] = 0, 0
 		}
-	case 261:
-		//line sql.y:1219
+	case 308:
+		//line sql.y:1440
 		{
 			yyVAL.intVal2[0], yyVAL.intVal2[1] = yyS[yypt-2].intVal, yyS[yypt-1].intVal
 		}
-	case 262:
-		//line sql.y:1222
+	case 309:
+		//line sql.y:1443
 		{
 			yyVAL.intVal = 0
 		}
-	case 263:
-		//line sql.y:1224
+	case 310:
+		//line sql.y:1445
 		{
 			yyVAL.intVal = yyS[yypt-0].intVal
 		}
-	case 264:
-		//line sql.y:1227
+	case 311:
+		//line sql.y:1448
 		{
 			yyVAL.boolVal = false
 		}
-	case 265:
-		//line sql.y:1229
+	case 312:
+		//line sql.y:1450
 		{
 			yyVAL.boolVal = true
 		}
-	case 266:
-		//line sql.y:1232
+	case 313:
+		//line sql.y:1453
 		{
 			yyVAL.empty = struct{}{}
 		}
-	case 267:
-		//line sql.y:1234
+	case 314:
+		//line sql.y:1455
 		{
 			yyVAL.empty = struct{}{}
 		}
-	case 268:
-		//line sql.y:1237
+	case 315:
+		//line sql.y:1458
 		{
 			yyVAL.str = ""
 		}
-	case 269:
-		//line sql.y:1239
+	case 316:
+		//line sql.y:1460
 		{
 			yyVAL.str = yyS[yypt-0].str
 		}
-	case 270:
-		//line sql.y:1243
+	case 317:
+		//line sql.y:1464
 		{
 			yyVAL.str = strings.ToLower(yyS[yypt-0].str)
 		}
-	case 271:
-		//line sql.y:1246
+	case 318:
+		//line sql.y:1467
 		{
 			forceEOF(yylex)
 		}
@@ -48,6 +48,8 @@ func parseInt(yylex yyLexer, s string) (int, bool) {
   tableExpr   TableExpr
   smTableExpr SimpleTableExpr
   tableName   *TableName
+  tableNames  []*TableName
+  targetList  TargetList
   indexHints  *IndexHints
   expr        Expr
   boolExpr    BoolExpr
@@ -62,11 +64,14 @@ func parseInt(yylex yyLexer, s string) (int, bool) {
   orderBy     OrderBy
   order       *Order
   limit       *Limit
+  asOf        *AsOf
   insRows     InsertRows
   updateExprs UpdateExprs
   updateExpr  *UpdateExpr
   tableDefs   TableDefs
   tableDef    TableDef
+  columnDef   *ColumnTableDef
+  alterCmd    AlterTableCmd
   columnType  ColumnType
   intVal      int
   intVal2     [2]int
@@ -76,7 +81,7 @@ func parseInt(yylex yyLexer, s string) (int, bool) {
 %token tokLexError
 %token <empty> tokSelect tokInsert tokUpdate tokDelete tokFrom tokWhere tokGroup tokHaving tokOrder tokBy tokLimit tokOffset tokFor
 %token <empty> tokAll tokDistinct tokAs tokExists tokIn tokIs tokLike tokBetween tokNull tokAsc tokDesc tokValues tokInto tokDuplicate tokKey tokDefault tokSet tokLock
-%token tokInt tokTinyInt tokSmallInt tokMediumInt tokBigInt tokInteger
+%token tokInt tokTinyInt tokSmallInt tokMediumInt tokBigInt tokInteger tokSerial
 %token tokReal tokDouble tokFloat tokDecimal tokNumeric
 %token tokDate tokTime tokDateTime tokTimestamp
 %token tokChar tokVarChar tokBinary tokVarBinary
@@ -103,8 +108,20 @@ func parseInt(yylex yyLexer, s string) (int, bool) {
 %left <empty> tokEnd
 
 // DDL Tokens
-%token <empty> tokCreate tokAlter tokDrop tokRename tokTruncate tokShow
-%token <empty> tokDatabase tokDatabases tokTable tokTables tokIndex tokView tokColumns tokFull tokTo tokIgnore tokIf tokUnique tokUnsigned tokPrimary
+%token <empty> tokCreate tokAlter tokAdd tokDrop tokRename tokTruncate tokShow tokExplain
+%token <empty> tokDatabase tokDatabases tokTable tokTables tokIndex tokView tokColumn tokColumns tokFull tokTo tokIgnore tokIf tokUnique tokUnsigned tokPrimary
+
+// Transaction Tokens
+%token <empty> tokBegin tokStart tokTransaction tokCommit tokRollback
+
+// Privilege Tokens
+%token <empty> tokGrant tokRevoke
+
+// Bulk Tokens
+%token <empty> tokImport tokCSV
+
+// Historical Read Tokens
+%token <empty> tokAsOf
 
 %start any_command
 
@@ -112,6 +129,10 @@ func parseInt(yylex yyLexer, s string) (int, bool) {
 %type <selStmt> select_statement
 %type <statement> insert_statement update_statement delete_statement set_statement use_statement show_statement
 %type <statement> create_statement alter_statement rename_statement truncate_statement drop_statement
+%type <statement> explain_statement
+%type <statement> begin_statement commit_statement rollback_statement
+%type <statement> grant_statement revoke_statement
+%type <statement> import_statement
 %type <str2> comment_opt comment_list
 %type <str> union_op
 %type <str> distinct_opt
@@ -124,8 +145,12 @@ func parseInt(yylex yyLexer, s string) (int, bool) {
 %type <str> join_type
 %type <smTableExpr> simple_table_expression
 %type <tableName> dml_table_expression ddl_table_expression
+%type <tableNames> ddl_table_expression_list
+%type <targetList> privilege_target
+%type <str2> privilege_list privilege_name_list
+%type <str> privilege_name
 %type <indexHints> index_hint_list
-%type <str2> index_list
+%type <str2> index_list view_column_list_opt
 %type <boolExpr> where_expression_opt
 %type <boolExpr> boolean_expression condition
 %type <str> compare
@@ -148,12 +173,13 @@ func parseInt(yylex yyLexer, s string) (int, bool) {
 %type <order> order
 %type <str> asc_desc_opt
 %type <limit> limit_opt
+%type <asOf> as_of_opt
 %type <str> lock_opt
 %type <columns> column_list_opt column_list
 %type <updateExprs> on_dup_opt
 %type <updateExprs> update_list
 %type <updateExpr> update_expression
-%type <empty> ignore_opt non_rename_operation to_opt using_opt
+%type <empty> ignore_opt to_opt using_opt column_opt transaction_opt
 %type <boolVal> unsigned_opt if_exists_opt if_not_exists_opt unique_opt
 %type <str> from_opt
 %type <intVal> int_opt int_val precision_opt
@@ -161,6 +187,9 @@ func parseInt(yylex yyLexer, s string) (int, bool) {
 %type <str> sql_id
 %type <tableDefs> table_def_list
 %type <tableDef> table_def
+%type <columnDef> column_def
+%type <alterCmd> alter_table_cmd
+%type <valExpr> default_opt
 %type <columnType> column_type
 %type <str> int_type float_type decimal_type char_type binary_type text_type blob_type
 %type <intVal> column_null_opt column_constraint_opt
@@ -169,11 +198,16 @@ func parseInt(yylex yyLexer, s string) (int, bool) {
 %%
 
 any_command:
-  command
+  command semicolon_opt
   {
     setParseTree(yylex, $1)
   }
 
+semicolon_opt:
+  {}
+| ';'
+  {}
+
 command:
   select_statement
   {
@@ -190,11 +224,18 @@ command:
 | rename_statement
 | truncate_statement
 | drop_statement
+| explain_statement
+| begin_statement
+| commit_statement
+| rollback_statement
+| grant_statement
+| revoke_statement
+| import_statement
 
 select_statement:
-  tokSelect comment_opt distinct_opt select_expression_list tokFrom table_expression_list where_expression_opt group_by_opt having_opt order_by_opt limit_opt lock_opt
+  tokSelect comment_opt distinct_opt select_expression_list tokFrom table_expression_list as_of_opt where_expression_opt group_by_opt having_opt order_by_opt limit_opt lock_opt
   {
-    $$ = &Select{Comments: Comments($2), Distinct: $3, Exprs: $4, From: $6, Where: NewWhere(astWhere, $7), GroupBy: GroupBy($8), Having: NewWhere(astHaving, $9), OrderBy: $10, Limit: $11, Lock: $12}
+    $$ = &Select{Comments: Comments($2), Distinct: $3, Exprs: $4, From: $6, AsOf: $7, Where: NewWhere(astWhere, $8), GroupBy: GroupBy($9), Having: NewWhere(astHaving, $10), OrderBy: $11, Limit: $12, Lock: $13}
   }
 | select_statement union_op select_statement %prec tokUnion
   {
@@ -234,6 +275,10 @@ set_statement:
   {
     $$ = &Set{Comments: Comments($2), Exprs: $3}
   }
+| tokSet comment_opt tokDatabase '=' value_expression
+  {
+    $$ = &Set{Comments: Comments($2), Exprs: UpdateExprs{{Name: &ColName{Name: "database"}, Expr: $5}}}
+  }
 
 use_statement:
   tokUse comment_opt sql_id
@@ -242,7 +287,19 @@ use_statement:
   }
 
 show_statement:
-  tokShow tokDatabases
+  tokShow sql_id
+  {
+    $$ = &Show{Name: $2}
+  }
+| tokShow tokDatabase
+  {
+    $$ = &Show{Name: "database"}
+  }
+| tokShow tokAll
+  {
+    $$ = &Show{Name: "all"}
+  }
+| tokShow tokDatabases
   {
     $$ = &ShowDatabases{}
   }
@@ -262,19 +319,23 @@ show_statement:
   {
     $$ = &ShowColumns{Name: $5, Full: true}
   }
+| tokShow tokCreate tokTable ddl_table_expression
+  {
+    $$ = &ShowCreateTable{Name: $4}
+  }
 
 create_statement:
   tokCreate tokTable if_not_exists_opt ddl_table_expression '(' table_def_list ')' force_eof
   {
     $$ = &CreateTable{IfNotExists: $3, Name: $4, Defs: $6}
   }
-| tokCreate unique_opt tokIndex sql_id using_opt tokOn sql_id force_eof
+| tokCreate unique_opt tokIndex sql_id using_opt tokOn ddl_table_expression '(' index_list ')'
   {
-    $$ = &CreateIndex{Name: $4, TableName: $7, Unique: $2}
+    $$ = &CreateIndex{Name: $4, Table: $7, Unique: $2, Columns: $9}
   }
-| tokCreate tokView sql_id force_eof
+| tokCreate tokView ddl_table_expression view_column_list_opt tokAs select_statement
   {
-    $$ = &CreateView{Name: $3}
+    $$ = &CreateView{Name: $3, Columns: $4, Select: $6}
   }
 | tokCreate tokDatabase if_not_exists_opt sql_id
   {
@@ -292,9 +353,9 @@ table_def_list:
   }
 
 table_def:
-  sql_id column_type column_null_opt column_constraint_opt
+  column_def
   {
-    $$ = &ColumnTableDef{Name: $1, Type: $2, Nullable: Nullability($3), PrimaryKey: $4 == 1, Unique: $4 == 2}
+    $$ = $1
   }
 | unique_opt tokIndex sql_id '(' index_list ')'
   {
@@ -305,11 +366,19 @@ table_def:
     $$ = &IndexTableDef{Name: "primary", PrimaryKey: true, Unique: true, Columns: $4}
   }
 
+column_def:
+  sql_id column_type column_null_opt default_opt column_constraint_opt
+  {
+    $$ = &ColumnTableDef{Name: $1, Type: $2, Nullable: Nullability($3), Default: $4, PrimaryKey: $5 == 1, Unique: $5 == 2}
+  }
+
 column_type:
   tokBit int_opt
   { $$ = &BitType{N: $2} }
 | int_type int_opt unsigned_opt
   { $$ = &IntType{Name: $1, N: $2, Unsigned: $3} }
+| tokSerial
+  { $$ = &SerialType{} }
 | float_type float_opt unsigned_opt
   { $$ = &FloatType{Name: $1, N: $2[0], Prec: $2[1], Unsigned: $3} }
 | decimal_type decimal_opt
@@ -402,6 +471,11 @@ column_null_opt:
 | tokNot tokNull
   { $$ = int(NotNull) }
 
+default_opt:
+  { $$ = nil }
+| tokDefault value_expression
+  { $$ = $2 }
+
 column_constraint_opt:
   { $$ = 0 }
 | tokPrimary tokKey
@@ -414,42 +488,159 @@ column_constraint_opt:
   { $$ = 2 }
 
 alter_statement:
-  tokAlter ignore_opt tokTable tokID non_rename_operation force_eof
+  tokAlter ignore_opt tokTable ddl_table_expression alter_table_cmd
   {
-    $$ = &AlterTable{Name: $4}
+    $$ = &AlterTable{Name: $4, Cmd: $5}
   }
-| tokAlter ignore_opt tokTable tokID tokRename to_opt tokID
+| tokAlter ignore_opt tokTable ddl_table_expression tokRename to_opt ddl_table_expression
   {
     // Change this to a rename statement
     $$ = &RenameTable{Name: $4, NewName: $7}
   }
-| tokAlter tokView sql_id force_eof
+| tokAlter tokView ddl_table_expression view_column_list_opt tokAs select_statement
+  {
+    $$ = &AlterView{Name: $3, Columns: $4, Select: $6}
+  }
+
+alter_table_cmd:
+  tokAdd column_opt column_def
+  {
+    $$ = &AlterTableAddColumn{Column: $3}
+  }
+| tokDrop column_opt sql_id
+  {
+    $$ = &AlterTableDropColumn{Name: $3}
+  }
+| tokRename tokColumn sql_id tokTo sql_id
   {
-    $$ = &AlterView{Name: $3}
+    $$ = &AlterTableRenameColumn{Name: $3, NewName: $5}
   }
 
 rename_statement:
-  tokRename tokTable tokID tokTo tokID
+  tokRename tokTable ddl_table_expression tokTo ddl_table_expression
   {
     $$ = &RenameTable{Name: $3, NewName: $5}
   }
 
 truncate_statement:
-  tokTruncate tokTable tokID
+  tokTruncate tokTable ddl_table_expression
   {
     $$ = &TruncateTable{Name: $3}
   }
 
+explain_statement:
+  tokExplain select_statement
+  {
+    $$ = &Explain{Statement: $2}
+  }
+| tokExplain update_statement
+  {
+    $$ = &Explain{Statement: $2}
+  }
+| tokExplain delete_statement
+  {
+    $$ = &Explain{Statement: $2}
+  }
+
+begin_statement:
+  tokBegin transaction_opt
+  {
+    $$ = &BeginTransaction{}
+  }
+| tokStart tokTransaction
+  {
+    $$ = &BeginTransaction{}
+  }
+
+commit_statement:
+  tokCommit transaction_opt
+  {
+    $$ = &CommitTransaction{}
+  }
+
+rollback_statement:
+  tokRollback transaction_opt
+  {
+    $$ = &RollbackTransaction{}
+  }
+
+import_statement:
+  tokImport tokTable ddl_table_expression tokFrom tokCSV tokString
+  {
+    $$ = &Import{Table: $3, Path: $6}
+  }
+
+grant_statement:
+  tokGrant privilege_list tokOn privilege_target tokTo index_list
+  {
+    $$ = &Grant{Privileges: $2, Targets: $4, Grantees: $6}
+  }
+
+revoke_statement:
+  tokRevoke privilege_list tokOn privilege_target tokFrom index_list
+  {
+    $$ = &Revoke{Privileges: $2, Targets: $4, Grantees: $6}
+  }
+
+privilege_list:
+  tokAll
+  {
+    $$ = []string{"ALL"}
+  }
+| privilege_name_list
+
+privilege_name_list:
+  privilege_name
+  {
+    $$ = []string{$1}
+  }
+| privilege_name_list ',' privilege_name
+  {
+    $$ = append($1, $3)
+  }
+
+privilege_name:
+  tokSelect
+  {
+    $$ = "SELECT"
+  }
+| tokInsert
+  {
+    $$ = "INSERT"
+  }
+| tokUpdate
+  {
+    $$ = "UPDATE"
+  }
+| tokDelete
+  {
+    $$ = "DELETE"
+  }
+
+privilege_target:
+  ddl_table_expression_list
+  {
+    $$ = TargetList{Tables: $1}
+  }
+| tokTable ddl_table_expression_list
+  {
+    $$ = TargetList{Tables: $2}
+  }
+| tokDatabase index_list
+  {
+    $$ = TargetList{Databases: $2}
+  }
+
 drop_statement:
-  tokDrop tokTable if_exists_opt sql_id
+  tokDrop tokTable if_exists_opt ddl_table_expression
   {
     $$ = &DropTable{Name: $4, IfExists: $3}
   }
-| tokDrop tokIndex sql_id tokOn sql_id
+| tokDrop tokIndex sql_id tokOn ddl_table_expression
   {
-    $$ = &DropIndex{Name: $3, TableName: $5}
+    $$ = &DropIndex{Name: $3, Table: $5}
   }
-| tokDrop tokView if_exists_opt sql_id force_eof
+| tokDrop tokView if_exists_opt ddl_table_expression
   {
     $$ = &DropView{Name: $4, IfExists: $3}
   }
@@ -672,6 +863,16 @@ sql_id
     $$ = &TableName{Qualifier: $1, Name: $3}
   }
 
+ddl_table_expression_list:
+  ddl_table_expression
+  {
+    $$ = []*TableName{$1}
+  }
+| ddl_table_expression_list ',' ddl_table_expression
+  {
+    $$ = append($1, $3)
+  }
+
 index_hint_list:
   {
     $$ = nil
@@ -689,6 +890,15 @@ index_hint_list:
     $$ = &IndexHints{Type: astForce, Indexes: $4}
   }
 
+view_column_list_opt:
+  {
+    $$ = nil
+  }
+| '(' index_list ')'
+  {
+    $$ = $2
+  }
+
 index_list:
   sql_id
   {
@@ -1091,6 +1301,19 @@ limit_opt:
     $$ = &Limit{Offset: $4, Rowcount: $2}
   }
 
+as_of_opt:
+  {
+    $$ = nil
+  }
+| tokAsOf sql_id tokTime value_expression
+  {
+    if $2 != "system" {
+      yylex.Error("expecting system")
+      return 1
+    }
+    $$ = &AsOf{Expr: $4}
+  }
+
 lock_opt:
   {
     $$ = ""
@@ -1171,16 +1394,9 @@ ignore_opt:
 | tokIgnore
   { $$ = struct{}{} }
 
-non_rename_operation:
-  tokAlter
-  { $$ = struct{}{} }
-| tokDefault
+column_opt:
   { $$ = struct{}{} }
-| tokDrop
-  { $$ = struct{}{} }
-| tokOrder
-  { $$ = struct{}{} }
-| tokID
+| tokColumn
   { $$ = struct{}{} }
 
 to_opt:
@@ -1188,6 +1404,11 @@ to_opt:
 | tokTo
   { $$ = struct{}{} }
 
+transaction_opt:
+  { $$ = struct{}{} }
+| tokTransaction
+  { $$ = struct{}{} }
+
 unique_opt:
   { $$ = false }
 | tokUnique
@@ -22,7 +22,14 @@ type tokenizer struct {
 	errorToken    []byte
 	lastError     string
 	posVarIndex   int
-	parseTree     Statement
+	numArgs       int // The number of positional arguments referenced.
+	// Set if the previous token was a period. A word following a period in a
+	// qualified name is an identifier even if it is a keyword.
+	afterPeriod bool
+	// The token scanned ahead of the current one, if peekTyp is non-zero.
+	peekTyp   int
+	peekVal   []byte
+	parseTree Statement
 }
 
 // newStringTokenizer creates a new Tokenizer for the sql string.
@@ -30,6 +37,10 @@ func newStringTokenizer(sql string) *tokenizer {
 	return &tokenizer{inStream: strings.NewReader(sql)}
 }
 
+// maxPositionalArg is the largest index of a "$n" positional argument. The
+// PostgreSQL protocol limits the number of arguments to 65535.
+const maxPositionalArg = 1<<16 - 1
+
 // TODO(pmattis): Get the full list of keywords and reserved words.
 var keywords = map[string]int{
 	"SELECT": tokSelect,
@@ -92,16 +103,19 @@ var keywords = map[string]int{
 
 	"CREATE":    tokCreate,
 	"ALTER":     tokAlter,
+	"ADD":       tokAdd,
 	"RENAME":    tokRename,
 	"DROP":      tokDrop,
 	"TRUNCATE":  tokTruncate,
 	"SHOW":      tokShow,
+	"EXPLAIN":   tokExplain,
 	"TABLE":     tokTable,
 	"TABLES":    tokTables,
 	"DATABASE":  tokDatabase,
 	"DATABASES": tokDatabases,
 	"INDEX":     tokIndex,
 	"VIEW":      tokView,
+	"COLUMN":    tokColumn,
 	"COLUMNS":   tokColumns,
 	"FULL":      tokFull,
 	"TO":        tokTo,
@@ -110,6 +124,18 @@ var keywords = map[string]int{
 	"UNIQUE":    tokUnique,
 	"USING":     tokUsing,
 
+	"BEGIN":       tokBegin,
+	"START":       tokStart,
+	"TRANSACTION": tokTransaction,
+	"COMMIT":      tokCommit,
+	"ROLLBACK":    tokRollback,
+
+	"GRANT":  tokGrant,
+	"REVOKE": tokRevoke,
+
+	"IMPORT": tokImport,
+	"CSV":    tokCSV,
+
 	"BIT":        tokBit,
 	"INT":        tokInt,
 	"TINYINT":    tokTinyInt,
@@ -117,6 +143,7 @@ var keywords = map[string]int{
 	"MEDIUMINT":  tokMediumInt,
 	"BIGINT":     tokBigInt,
 	"INTEGER":    tokInteger,
+	"SERIAL":     tokSerial,
 	"REAL":       tokReal,
 	"DOUBLE":     tokDouble,
 	"FLOAT":      tokFloat,
@@ -146,12 +173,24 @@ var keywords = map[string]int{
 // Lex returns the next token form the Tokenizer.
 // This function is used by go yacc.
 func (tkn *tokenizer) Lex(lval *yySymType) int {
-	typ, val := tkn.Scan()
-	for typ == tokComment {
-		if tkn.allowComments {
-			break
+	var typ int
+	var val []byte
+	if tkn.peekTyp != 0 {
+		typ, val = tkn.peekTyp, tkn.peekVal
+		tkn.peekTyp, tkn.peekVal = 0, nil
+	} else {
+		typ, val = tkn.scanToken()
+	}
+	if typ == tokAs {
+		// AS followed by OF starts an AS OF SYSTEM TIME clause rather than an
+		// alias named "of". Telling the two apart requires more lookahead than
+		// the grammar has, so the two words are returned as a single token.
+		peekTyp, peekVal := tkn.scanToken()
+		if peekTyp == tokID && strings.EqualFold(string(peekVal), "of") {
+			typ, val = tokAsOf, []byte("AS OF")
+		} else {
+			tkn.peekTyp, tkn.peekVal = peekTyp, peekVal
 		}
-		typ, val = tkn.Scan()
 	}
 	switch typ {
 	case tokID, tokString, tokNumber, tokValueArg, tokComment, tokAnd, tokOr, tokNot:
@@ -161,6 +200,19 @@ func (tkn *tokenizer) Lex(lval *yySymType) int {
 	return typ
 }
 
+// scanToken returns the next token, skipping comments unless they are
+// allowed.
+func (tkn *tokenizer) scanToken() (int, []byte) {
+	typ, val := tkn.Scan()
+	for typ == tokComment {
+		if tkn.allowComments {
+			break
+		}
+		typ, val = tkn.Scan()
+	}
+	return typ, val
+}
+
 // Error is called by go yacc if there's a parsing error.
 func (tkn *tokenizer) Error(err string) {
 	buf := bytes.NewBuffer(make([]byte, 0, 32))
@@ -183,9 +235,11 @@ func (tkn *tokenizer) Scan() (int, []byte) {
 		tkn.next()
 	}
 	tkn.skipBlank()
+	afterPeriod := tkn.afterPeriod
+	tkn.afterPeriod = false
 	switch ch := tkn.lastChar; {
 	case isLetter(ch):
-		return tkn.scanIdentifier()
+		return tkn.scanIdentifier(afterPeriod)
 	case isDigit(ch):
 		return tkn.scanNumber(false)
 	case ch == ':':
@@ -211,13 +265,19 @@ func (tkn *tokenizer) Scan() (int, []byte) {
 			return int(ch), nil
 		case '?':
 			tkn.posVarIndex++
+			if tkn.posVarIndex > tkn.numArgs {
+				tkn.numArgs = tkn.posVarIndex
+			}
 			buf := new(bytes.Buffer)
 			fmt.Fprintf(buf, ":v%d", tkn.posVarIndex)
 			return tokValueArg, buf.Bytes()
+		case '$':
+			return tkn.scanPositionalArg()
 		case '.':
 			if isDigit(tkn.lastChar) {
 				return tkn.scanNumber(true)
 			}
+			tkn.afterPeriod = true
 			return int(ch), nil
 		case '/':
 			switch tkn.lastChar {
@@ -283,12 +343,15 @@ func (tkn *tokenizer) skipBlank() {
 	}
 }
 
-func (tkn *tokenizer) scanIdentifier() (int, []byte) {
+func (tkn *tokenizer) scanIdentifier(afterPeriod bool) (int, []byte) {
 	buffer := bytes.NewBuffer(make([]byte, 0, 8))
 	buffer.WriteByte(byte(tkn.lastChar))
 	for tkn.next(); isLetter(tkn.lastChar) || isDigit(tkn.lastChar); tkn.next() {
 		buffer.WriteByte(byte(tkn.lastChar))
 	}
+	if afterPeriod {
+		return tokID, buffer.Bytes()
+	}
 	uppered := bytes.ToUpper(buffer.Bytes())
 	if keywordID, found := keywords[string(uppered)]; found {
 		return keywordID, uppered
@@ -308,6 +371,30 @@ func (tkn *tokenizer) scanBindVar() (int, []byte) {
 	return tokValueArg, buffer.Bytes()
 }
 
+// scanPositionalArg scans a PostgreSQL style positional argument ("$1", "$2",
+// ...) which is converted into the same form as a "?" positional argument.
+func (tkn *tokenizer) scanPositionalArg() (int, []byte) {
+	buffer := bytes.NewBuffer(make([]byte, 0, 8))
+	buffer.WriteByte('$')
+	n := 0
+	for ; isDigit(tkn.lastChar); tkn.next() {
+		buffer.WriteByte(byte(tkn.lastChar))
+		n = n*10 + digitVal(tkn.lastChar)
+		if n > maxPositionalArg {
+			return tokLexError, buffer.Bytes()
+		}
+	}
+	if n == 0 {
+		return tokLexError, buffer.Bytes()
+	}
+	if n > tkn.numArgs {
+		tkn.numArgs = n
+	}
+	buffer.Reset()
+	fmt.Fprintf(buffer, ":v%d", n)
+	return tokValueArg, buffer.Bytes()
+}
+
 func (tkn *tokenizer) scanMantissa(base int, buffer *bytes.Buffer) {
 	for digitVal(tkn.lastChar) < base {
 		tkn.consumeNext(buffer)
@@ -23,7 +23,7 @@ func (*TruncateTable) statement() {}
 
 // TruncateTable represents a TRUNCATE TABLE statement.
 type TruncateTable struct {
-	Name string
+	Name *TableName
 }
 
 func (node *TruncateTable) String() string {
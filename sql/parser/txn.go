@@ -0,0 +1,41 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package parser
+
+func (*BeginTransaction) statement()    {}
+func (*CommitTransaction) statement()   {}
+func (*RollbackTransaction) statement() {}
+
+// BeginTransaction represents a BEGIN statement.
+type BeginTransaction struct{}
+
+func (node *BeginTransaction) String() string {
+	return "BEGIN TRANSACTION"
+}
+
+// CommitTransaction represents a COMMIT statement.
+type CommitTransaction struct{}
+
+func (node *CommitTransaction) String() string {
+	return "COMMIT TRANSACTION"
+}
+
+// RollbackTransaction represents a ROLLBACK statement.
+type RollbackTransaction struct{}
+
+func (node *RollbackTransaction) String() string {
+	return "ROLLBACK TRANSACTION"
+}
@@ -93,6 +93,17 @@ func (node *IntType) String() string {
 	return buf.String()
 }
 
+// SerialType represents a SERIAL type: an INT which is NOT NULL and defaults
+// to a unique row ID.
+type SerialType struct {
+}
+
+func (*SerialType) columnType() {}
+
+func (node *SerialType) String() string {
+	return "SERIAL"
+}
+
 // FloatType represents a REAL, DOUBLE or FLOAT type.
 type FloatType struct {
 	Name     string
@@ -0,0 +1,157 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package pgwire
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/cockroachdb/cockroach/util"
+)
+
+// maxMessageSize is the largest message accepted from a client.
+const maxMessageSize = 1 << 24
+
+// readBuffer holds the body of the last message read from a client. The get*
+// methods consume the body from the front.
+type readBuffer struct {
+	msg []byte
+	tmp [4]byte
+}
+
+// readUntypedMsg reads a length-prefixed message. The startup message and the
+// SSL request are the only messages sent without a type byte.
+func (b *readBuffer) readUntypedMsg(rd io.Reader) error {
+	if _, err := io.ReadFull(rd, b.tmp[:]); err != nil {
+		return err
+	}
+	size := int(binary.BigEndian.Uint32(b.tmp[:]))
+	// The length includes itself.
+	size -= 4
+	if size < 0 || size > maxMessageSize {
+		return util.Errorf("message size %d out of range", size)
+	}
+	if cap(b.msg) < size {
+		b.msg = make([]byte, size)
+	}
+	b.msg = b.msg[:size]
+	_, err := io.ReadFull(rd, b.msg)
+	return err
+}
+
+// readTypedMsg reads a message, returning its type.
+func (b *readBuffer) readTypedMsg(rd io.Reader) (clientMessageType, error) {
+	var typ [1]byte
+	if _, err := io.ReadFull(rd, typ[:]); err != nil {
+		return 0, err
+	}
+	return clientMessageType(typ[0]), b.readUntypedMsg(rd)
+}
+
+// getString consumes a null-terminated string.
+func (b *readBuffer) getString() (string, error) {
+	pos := bytes.IndexByte(b.msg, 0)
+	if pos == -1 {
+		return "", util.Errorf("NUL terminator not found")
+	}
+	s := string(b.msg[:pos])
+	b.msg = b.msg[pos+1:]
+	return s, nil
+}
+
+// getBytes consumes n bytes.
+func (b *readBuffer) getBytes(n int) ([]byte, error) {
+	if n < 0 || len(b.msg) < n {
+		return nil, util.Errorf("insufficient data: %d", len(b.msg))
+	}
+	v := b.msg[:n]
+	b.msg = b.msg[n:]
+	return v, nil
+}
+
+// getInt16 consumes a big-endian 16-bit integer.
+func (b *readBuffer) getInt16() (int16, error) {
+	if len(b.msg) < 2 {
+		return 0, util.Errorf("insufficient data: %d", len(b.msg))
+	}
+	v := int16(binary.BigEndian.Uint16(b.msg[:2]))
+	b.msg = b.msg[2:]
+	return v, nil
+}
+
+// getCount consumes the big-endian 16-bit count of a list whose elements
+// occupy at least elemSize bytes each. A count which is negative or larger
+// than the rest of the message can hold is rejected.
+func (b *readBuffer) getCount(elemSize int) (int, error) {
+	v, err := b.getInt16()
+	if err != nil {
+		return 0, err
+	}
+	n := int(v)
+	if n < 0 || n*elemSize > len(b.msg) {
+		return 0, util.Errorf("invalid count %d with %d bytes remaining", n, len(b.msg))
+	}
+	return n, nil
+}
+
+// getInt32 consumes a big-endian 32-bit integer.
+func (b *readBuffer) getInt32() (int32, error) {
+	if len(b.msg) < 4 {
+		return 0, util.Errorf("insufficient data: %d", len(b.msg))
+	}
+	v := int32(binary.BigEndian.Uint32(b.msg[:4]))
+	b.msg = b.msg[4:]
+	return v, nil
+}
+
+// writeBuffer accumulates the body of a message sent to the client. The body
+// is prefixed with the message type and length by finishMsg.
+type writeBuffer struct {
+	bytes.Buffer
+	putbuf [8]byte
+}
+
+// writeString writes a null-terminated string.
+func (b *writeBuffer) writeString(s string) {
+	b.WriteString(s)
+	b.WriteByte(0)
+}
+
+func (b *writeBuffer) putInt16(v int16) {
+	binary.BigEndian.PutUint16(b.putbuf[:], uint16(v))
+	b.Write(b.putbuf[:2])
+}
+
+func (b *writeBuffer) putInt32(v int32) {
+	binary.BigEndian.PutUint32(b.putbuf[:], uint32(v))
+	b.Write(b.putbuf[:4])
+}
+
+// finishMsg writes the accumulated message to w and resets the buffer.
+func (b *writeBuffer) finishMsg(w io.Writer, typ serverMessageType) error {
+	defer b.Reset()
+	if b.Len()+4 > maxMessageSize {
+		return util.Errorf("message size %d too large", b.Len())
+	}
+	b.putbuf[0] = byte(typ)
+	binary.BigEndian.PutUint32(b.putbuf[1:], uint32(b.Len()+4))
+	if _, err := w.Write(b.putbuf[:5]); err != nil {
+		return err
+	}
+	_, err := b.WriteTo(w)
+	return err
+}
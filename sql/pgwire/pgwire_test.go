@@ -0,0 +1,665 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package pgwire_test
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+
+	"github.com/cockroachdb/cockroach/security"
+	"github.com/cockroachdb/cockroach/security/securitytest"
+	"github.com/cockroachdb/cockroach/server"
+	"github.com/cockroachdb/cockroach/testutils"
+	"github.com/cockroachdb/cockroach/util/leaktest"
+)
+
+var isError = testutils.IsError
+
+// tempCerts writes the embedded test certificates to a temporary directory,
+// which must be removed by the caller.
+func tempCerts(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "pgwire_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := securitytest.RestoreAssets(dir, security.EmbeddedCertsDir); err != nil {
+		t.Fatal(err)
+	}
+	// lib/pq refuses to use private keys which are accessible by other users.
+	keys, err := filepath.Glob(filepath.Join(dir, security.EmbeddedCertsDir, "*.key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range keys {
+		if err := os.Chmod(key, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// pgURL returns a URL for connecting to the server as the specified user,
+// using the client certificate of certUser.
+func pgURL(addr, certsDir, user, certUser string) string {
+	dir := filepath.Join(certsDir, security.EmbeddedCertsDir)
+	options := url.Values{}
+	options.Add("sslmode", "verify-ca")
+	options.Add("sslrootcert", filepath.Join(dir, "ca.crt"))
+	options.Add("sslcert", filepath.Join(dir, certUser+".client.crt"))
+	options.Add("sslkey", filepath.Join(dir, certUser+".client.key"))
+	return fmt.Sprintf("postgres://%s@%s/?%s", user, addr, options.Encode())
+}
+
+func setup(t *testing.T) (*server.TestServer, string, *sql.DB) {
+	s := server.StartTestServer(nil)
+	certsDir := tempCerts(t)
+	db, err := sql.Open("postgres", pgURL(s.PGAddr(), certsDir, security.RootUser, security.RootUser))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, certsDir, db
+}
+
+func cleanup(s *server.TestServer, certsDir string, db *sql.DB) {
+	_ = db.Close()
+	s.Stop()
+	_ = os.RemoveAll(certsDir)
+}
+
+func TestPGWireAuth(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, certsDir, db := setup(t)
+	defer cleanup(s, certsDir, db)
+
+	if err := db.Ping(); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		url         string
+		expectedErr string
+	}{
+		// The client certificate does not match the requested user.
+		{pgURL(s.PGAddr(), certsDir, "foo", security.RootUser),
+			"requested user is foo, but certificate is for root"},
+		// The node certificate may be used by any user.
+		{pgURL(s.PGAddr(), certsDir, "foo", security.NodeUser), ""},
+		// The connection must use TLS.
+		{fmt.Sprintf("postgres://root@%s/?sslmode=disable", s.PGAddr()),
+			"request is not using TLS"},
+	}
+	for i, tc := range testCases {
+		func() {
+			db, err := sql.Open("postgres", tc.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			err = db.Ping()
+			if tc.expectedErr == "" {
+				if err != nil {
+					t.Errorf("%d: unexpected error: %s", i, err)
+				}
+			} else if !isError(err, tc.expectedErr) {
+				t.Errorf("%d: expected %q, but found %v", i, tc.expectedErr, err)
+			}
+		}()
+	}
+}
+
+func TestPGWireSimpleQuery(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, certsDir, db := setup(t)
+	defer cleanup(s, certsDir, db)
+
+	for _, stmt := range []string{
+		`CREATE DATABASE t`,
+		`CREATE TABLE t.kv (k INT PRIMARY KEY, v TEXT, f FLOAT, b BLOB, ts TIMESTAMP)`,
+		`INSERT INTO t.kv VALUES (1, 'a', 1.5, 'xyz', '2015-06-01 12:30:00.25'), (2, 'b', NULL, NULL, NULL)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %s", stmt, err)
+		}
+	}
+
+	// The values are decoded by the client according to the types reported by
+	// the server.
+	rows, err := db.Query(`SELECT k, v, f, k = 1, b, ts FROM t.kv`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var results [][]interface{}
+	for rows.Next() {
+		vals := make([]interface{}, 6)
+		ptrs := make([]interface{}, len(vals))
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			t.Fatal(err)
+		}
+		if ts, ok := vals[5].(time.Time); ok {
+			vals[5] = ts.UTC()
+		}
+		results = append(results, vals)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	expected := [][]interface{}{
+		{int64(1), "a", 1.5, true, []byte("xyz"),
+			time.Date(2015, 6, 1, 12, 30, 0, 250000000, time.UTC)},
+		{int64(2), "b", nil, false, nil, nil},
+	}
+	if !reflect.DeepEqual(expected, results) {
+		t.Fatalf("expected %v, but found %v", expected, results)
+	}
+
+	// Timestamps are sent in the time zone of the session.
+	if _, err := db.Exec(`SET time_zone = 'Asia/Kolkata'`); err != nil {
+		t.Fatal(err)
+	}
+	var ts time.Time
+	if err := db.QueryRow(`SELECT ts FROM t.kv WHERE k = 1`).Scan(&ts); err != nil {
+		t.Fatal(err)
+	}
+	if _, offset := ts.Zone(); offset != 5*3600+1800 || !ts.Equal(expected[0][5].(time.Time)) {
+		t.Fatalf("expected %s at +05:30, but found %s", expected[0][5], ts)
+	}
+
+	if _, err := db.Exec(`SELECT * FROM t.foo`); !isError(err, `table "t.foo" does not exist`) {
+		t.Fatalf("expected error, but found %v", err)
+	}
+	if _, err := db.Exec(`SELECT FROM`); !isError(err, "syntax error") {
+		t.Fatalf("expected syntax error, but found %v", err)
+	}
+}
+
+func TestPGWirePreparedStatement(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, certsDir, db := setup(t)
+	defer cleanup(s, certsDir, db)
+
+	for _, stmt := range []string{
+		`CREATE DATABASE t`,
+		`CREATE TABLE t.kv (k INT PRIMARY KEY, v TEXT)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %s", stmt, err)
+		}
+	}
+
+	insert, err := db.Prepare(`INSERT INTO t.kv VALUES ($1, $2)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer insert.Close()
+	for i, v := range []string{"a", "b", "c"} {
+		res, err := insert.Exec(i+1, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			t.Fatal(err)
+		} else if n != 1 {
+			t.Fatalf("expected 1 row affected, but found %d", n)
+		}
+	}
+	if _, err := insert.Exec(1); !isError(err, "expected 2 arguments, got 1") {
+		t.Fatalf("expected error, but found %v", err)
+	}
+	if _, err := insert.Exec(1, "d"); !isError(err, "duplicate key") {
+		t.Fatalf("expected error, but found %v", err)
+	}
+
+	res, err := db.Exec(`UPDATE t.kv SET v = $1 WHERE k >= $2`, "z", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		t.Fatal(err)
+	} else if n != 2 {
+		t.Fatalf("expected 2 rows affected, but found %d", n)
+	}
+
+	rows, err := db.Query(`SELECT k, v FROM t.kv WHERE k > $1`, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var results []string
+	for rows.Next() {
+		var k int
+		var v string
+		if err := rows.Scan(&k, &v); err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, fmt.Sprintf("%d:%s", k, v))
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"2:z", "3:z"}; !reflect.DeepEqual(expected, results) {
+		t.Fatalf("expected %v, but found %v", expected, results)
+	}
+
+	// The arguments are typed using the columns they are assigned to or
+	// compared with, so a numeric-looking string is stored in a TEXT column
+	// as is.
+	for i, v := range []string{"123", "0123", "1.50"} {
+		if _, err := insert.Exec(i+4, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	results = nil
+	rows2, err := db.Query(`SELECT k, v FROM t.kv WHERE k BETWEEN $1 AND $2 AND v <> $3`, 4, 6, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows2.Close()
+	for rows2.Next() {
+		var k int
+		var v string
+		if err := rows2.Scan(&k, &v); err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, fmt.Sprintf("%d:%s", k, v))
+	}
+	if err := rows2.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"4:123", "5:0123", "6:1.50"}; !reflect.DeepEqual(expected, results) {
+		t.Fatalf("expected %v, but found %v", expected, results)
+	}
+	var v string
+	if err := db.QueryRow(`SELECT v FROM t.kv WHERE v = $1`, "0123").Scan(&v); err != nil {
+		t.Fatal(err)
+	} else if v != "0123" {
+		t.Fatalf("expected 0123, but found %s", v)
+	}
+}
+
+// rawConn is a connection speaking the frontend side of the protocol, used to
+// send sequences of extended query messages which database/sql never sends.
+type rawConn struct {
+	conn net.Conn
+	rd   *bufio.Reader
+}
+
+// dialRaw opens a TLS connection to the server as the root user.
+func dialRaw(t *testing.T, addr string) *rawConn {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &rawConn{conn: conn, rd: bufio.NewReader(conn)}
+	c.send(t, 0, int32(80877103))
+	if b, err := c.rd.ReadByte(); err != nil {
+		t.Fatal(err)
+	} else if b != 'S' {
+		t.Fatalf("expected the server to accept TLS, but found %q", b)
+	}
+	tlsConfig, err := security.LoadClientTLSConfig(security.EmbeddedCertsDir, security.RootUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tlsConfig.ServerName = host
+	c.conn = tls.Client(conn, tlsConfig)
+	c.rd = bufio.NewReader(c.conn)
+	c.send(t, 0, int32(196608), "user", security.RootUser, "")
+	c.recv(t, "")
+	return c
+}
+
+// send sends a message of the type holding the fields, which are either
+// strings or fixed-size integers. A type of 0 sends an untyped message.
+func (c *rawConn) send(t *testing.T, typ byte, fields ...interface{}) {
+	var body bytes.Buffer
+	for _, f := range fields {
+		if s, ok := f.(string); ok {
+			body.WriteString(s)
+			body.WriteByte(0)
+		} else if err := binary.Write(&body, binary.BigEndian, f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var msg bytes.Buffer
+	if typ != 0 {
+		msg.WriteByte(typ)
+	}
+	if err := binary.Write(&msg, binary.BigEndian, int32(body.Len()+4)); err != nil {
+		t.Fatal(err)
+	}
+	msg.Write(body.Bytes())
+	if _, err := c.conn.Write(msg.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// recv reads messages until a ReadyForQuery message, failing unless the
+// types of the messages read before it are the expected ones. The messages
+// sent during the startup of the connection are ignored if expected is
+// empty.
+func (c *rawConn) recv(t *testing.T, expected string) {
+	var types []byte
+	for {
+		typ, err := c.rd.ReadByte()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var n int32
+		if err := binary.Read(c.rd, binary.BigEndian, &n); err != nil {
+			t.Fatal(err)
+		}
+		body := make([]byte, n-4)
+		if _, err := io.ReadFull(c.rd, body); err != nil {
+			t.Fatal(err)
+		}
+		if typ == 'E' {
+			t.Fatalf("unexpected error: %q", body)
+		}
+		if typ == 'Z' {
+			break
+		}
+		types = append(types, typ)
+	}
+	if expected != "" && string(types) != expected {
+		t.Fatalf("expected messages %q, but found %q", expected, types)
+	}
+}
+
+// TestPGWireDescribePortal verifies that describing a portal does not
+// execute its statement, which is only executed by an Execute message.
+func TestPGWireDescribePortal(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, certsDir, db := setup(t)
+	defer cleanup(s, certsDir, db)
+
+	for _, stmt := range []string{
+		`CREATE DATABASE t`,
+		`CREATE TABLE t.kv (k INT PRIMARY KEY, v TEXT)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %s", stmt, err)
+		}
+	}
+	countRows := func() int {
+		rows, err := db.Query(`SELECT k FROM t.kv`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		n := 0
+		for rows.Next() {
+			n++
+		}
+		if err := rows.Err(); err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	c := dialRaw(t, s.PGAddr())
+	defer c.conn.Close()
+
+	// Parse, Bind, Describe the portal and Sync.
+	c.send(t, 'P', "", `INSERT INTO t.kv VALUES (1, 'a')`, int16(0))
+	c.send(t, 'B', "", "", int16(0), int16(0), int16(0))
+	c.send(t, 'D', byte('P'), "")
+	c.send(t, 'S')
+	c.recv(t, "12n")
+	if n := countRows(); n != 0 {
+		t.Fatalf("expected no rows after describing the portal, but found %d", n)
+	}
+
+	// Bind, Execute and Sync.
+	c.send(t, 'B', "", "", int16(0), int16(0), int16(0))
+	c.send(t, 'E', "", int32(0))
+	c.send(t, 'S')
+	c.recv(t, "2C")
+	if n := countRows(); n != 1 {
+		t.Fatalf("expected 1 row after executing the portal, but found %d", n)
+	}
+
+	// The result columns of a query are described before it is executed.
+	c.send(t, 'P', "", `SELECT k, v FROM t.kv`, int16(0))
+	c.send(t, 'B', "", "", int16(0), int16(0), int16(0))
+	c.send(t, 'D', byte('P'), "")
+	c.send(t, 'E', "", int32(0))
+	c.send(t, 'S')
+	c.recv(t, "12TDC")
+}
+
+func TestPGWireTransaction(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, certsDir, db := setup(t)
+	defer cleanup(s, certsDir, db)
+
+	for _, stmt := range []string{
+		`CREATE DATABASE t`,
+		`CREATE TABLE t.kv (k INT PRIMARY KEY, v TEXT)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %s", stmt, err)
+		}
+	}
+
+	// Rolling back discards the writes of the transaction.
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec(`INSERT INTO t.kv VALUES ($1, $2)`, 1, "a"); err != nil {
+		t.Fatal(err)
+	}
+	var v string
+	if err := tx.QueryRow(`SELECT v FROM t.kv WHERE k = $1`, 1).Scan(&v); err != nil {
+		t.Fatal(err)
+	} else if v != "a" {
+		t.Fatalf("expected a, but found %s", v)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.QueryRow(`SELECT v FROM t.kv WHERE k = 1`).Scan(&v); err != sql.ErrNoRows {
+		t.Fatalf("expected no rows, but found %v", err)
+	}
+
+	// A failed statement aborts the transaction.
+	if tx, err = db.Begin(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec(`INSERT INTO t.kv VALUES (1, 'a'), (1, 'b')`); !isError(err, "duplicate key") {
+		t.Fatalf("expected error, but found %v", err)
+	}
+	if _, err := tx.Exec(`INSERT INTO t.kv VALUES (2, 'b')`); !isError(err, "current transaction is aborted") {
+		t.Fatalf("expected error, but found %v", err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Committing makes the writes visible.
+	if tx, err = db.Begin(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec(`INSERT INTO t.kv VALUES (3, 'c')`); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.QueryRow(`SELECT v FROM t.kv WHERE k = 3`).Scan(&v); err != nil {
+		t.Fatal(err)
+	} else if v != "c" {
+		t.Fatalf("expected c, but found %s", v)
+	}
+}
+
+func TestPGWirePrivileges(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	s, certsDir, db := setup(t)
+	defer cleanup(s, certsDir, db)
+
+	// The node certificate allows the connection to act as foo.
+	foo, err := sql.Open("postgres", pgURL(s.PGAddr(), certsDir, "foo", security.NodeUser))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer foo.Close()
+
+	exec := func(db *sql.DB, stmt, expectedErr string) {
+		_, err := db.Exec(stmt)
+		if expectedErr == "" {
+			if err != nil {
+				t.Fatalf("%s: %s", stmt, err)
+			}
+		} else if !isError(err, expectedErr) {
+			t.Fatalf("%s: expected %q, but found %v", stmt, expectedErr, err)
+		}
+	}
+	exec(db, `CREATE DATABASE t`, "")
+	exec(db, `CREATE TABLE t.kv (k CHAR PRIMARY KEY, v INT)`, "")
+	exec(db, `INSERT INTO t.kv VALUES ('a', 1)`, "")
+	exec(db, `CREATE VIEW t.keys AS SELECT k FROM t.kv`, "")
+
+	// The tables listed by the information_schema database are those on
+	// which the user holds any privilege.
+	readTables := func(conn *sql.DB) []string {
+		rows, err := conn.Query(`SELECT table_schema, table_name FROM information_schema.tables`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		var results []string
+		for rows.Next() {
+			var database, table string
+			if err := rows.Scan(&database, &table); err != nil {
+				t.Fatal(err)
+			}
+			results = append(results, database+"."+table)
+		}
+		return results
+	}
+
+	// foo holds no privileges.
+	exec(foo, `SELECT * FROM t.kv`, `user foo does not have SELECT privilege on table "t.kv"`)
+	exec(foo, `CREATE TABLE t.foo (k INT PRIMARY KEY)`,
+		`user foo does not have ALL privilege on database "t"`)
+	for _, stmt := range []string{`SHOW COLUMNS FROM t.kv`, `SHOW INDEX FROM t.kv`, `SHOW CREATE TABLE t.kv`} {
+		exec(foo, stmt, `user foo does not have any privilege on table "t.kv"`)
+	}
+	if r := readTables(foo); len(r) != 0 {
+		t.Fatalf("expected no tables, but found %s", r)
+	}
+
+	// Privileges granted on a table.
+	exec(db, `GRANT SELECT ON t.kv TO foo`, "")
+	exec(foo, `SELECT * FROM t.kv`, "")
+	exec(foo, `SHOW COLUMNS FROM t.kv`, "")
+	if r, e := readTables(foo), []string{"t.kv"}; !reflect.DeepEqual(e, r) {
+		t.Fatalf("expected %s, but found %s", e, r)
+	}
+	exec(foo, `INSERT INTO t.kv VALUES ('b', 2)`,
+		`user foo does not have INSERT privilege on table "t.kv"`)
+	exec(foo, `GRANT SELECT ON t.kv TO bar`, `user foo does not have ALL privilege on table "t.kv"`)
+
+	// Privileges granted on a database apply to its tables.
+	exec(db, `GRANT INSERT, UPDATE ON DATABASE t TO foo`, "")
+	exec(foo, `INSERT INTO t.kv VALUES ('b', 2)`, "")
+	if r, e := readTables(foo), []string{"t.keys", "t.kv"}; !reflect.DeepEqual(e, r) {
+		t.Fatalf("expected %s, but found %s", e, r)
+	}
+	exec(foo, `UPDATE t.kv SET v = 3 WHERE k = 'b'`, "")
+	exec(foo, `DELETE FROM t.kv WHERE k = 'b'`,
+		`user foo does not have DELETE privilege on table "t.kv"`)
+	exec(foo, `DROP TABLE t.kv`, `user foo does not have ALL privilege on table "t.kv"`)
+
+	// A view may be read without privileges on the tables it references.
+	exec(db, `REVOKE SELECT ON t.kv FROM foo`, "")
+	exec(foo, `SELECT k FROM t.keys`, `user foo does not have SELECT privilege on table "t.keys"`)
+	exec(db, `GRANT SELECT ON TABLE t.keys TO foo`, "")
+	exec(foo, `SELECT k FROM t.keys`, "")
+	exec(foo, `SELECT k FROM t.kv`, `user foo does not have SELECT privilege on table "t.kv"`)
+
+	// A historical read is checked against the privileges granted now, so a
+	// revoked privilege cannot be used by reading as of a time it was held.
+	exec(db, `GRANT SELECT ON t.kv TO foo`, "")
+	asOf := fmt.Sprintf(`SELECT * FROM t.kv AS OF SYSTEM TIME %d`, time.Now().UnixNano())
+	exec(foo, asOf, "")
+	exec(db, `REVOKE SELECT ON t.kv FROM foo`, "")
+	exec(foo, asOf, `user foo does not have SELECT privilege on table "t.kv"`)
+	exec(db, asOf, "")
+
+	// The privileges are listed by the information_schema database.
+	readPrivileges := func(query string) []string {
+		rows, err := db.Query(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		var results []string
+		for rows.Next() {
+			var user, priv string
+			if err := rows.Scan(&user, &priv); err != nil {
+				t.Fatal(err)
+			}
+			results = append(results, user+" "+priv)
+		}
+		return results
+	}
+	if r, e := readPrivileges(`SELECT grantee, privilege_type FROM information_schema.schema_privileges
+WHERE table_schema = 't'`), []string{"foo INSERT", "foo UPDATE", "root ALL"}; !reflect.DeepEqual(e, r) {
+		t.Fatalf("expected %s, but found %s", e, r)
+	}
+	if r, e := readPrivileges(`SELECT grantee, privilege_type FROM information_schema.table_privileges
+WHERE table_schema = 't' AND table_name = 'keys'`), []string{"foo SELECT", "root ALL"}; !reflect.DeepEqual(e, r) {
+		t.Fatalf("expected %s, but found %s", e, r)
+	}
+
+	// Revoking the privileges of the database.
+	exec(db, `REVOKE ALL ON DATABASE t FROM foo`, "")
+	exec(foo, `INSERT INTO t.kv VALUES ('c', 3)`,
+		`user foo does not have INSERT privilege on table "t.kv"`)
+
+	// The creator of a database holds ALL on it.
+	exec(foo, `CREATE DATABASE f`, "")
+	exec(foo, `CREATE TABLE f.kv (k CHAR PRIMARY KEY, v INT)`, "")
+	exec(foo, `INSERT INTO f.kv VALUES ('a', 1)`, "")
+	exec(foo, `GRANT SELECT ON DATABASE f TO bar`, "")
+	exec(foo, `DROP DATABASE f`, "")
+	exec(foo, `DROP DATABASE t`, `user foo does not have ALL privilege on database "t"`)
+}
@@ -0,0 +1,196 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package pgwire
+
+import (
+	"crypto/tls"
+	"io"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/cockroachdb/cockroach/base"
+	"github.com/cockroachdb/cockroach/sql/sqlserver"
+	"github.com/cockroachdb/cockroach/util"
+	"github.com/cockroachdb/cockroach/util/log"
+)
+
+// The protocol versions sent by clients in the first message of a
+// connection.
+const (
+	// version30 is version 3.0 of the PostgreSQL frontend/backend protocol.
+	version30 = 196608
+	// versionSSL is sent by clients requesting the connection to be encrypted
+	// using TLS before the startup message is sent.
+	versionSSL = 80877103
+)
+
+// Server implements the server side of the PostgreSQL frontend/backend
+// protocol, allowing PostgreSQL clients to execute statements using the same
+// executor as the SQL API served over HTTP. Clients are authenticated using
+// their TLS client certificates unless running in insecure mode.
+type Server struct {
+	context  *base.Context
+	executor *sqlserver.Server
+
+	mu       sync.Mutex // Protects the fields below.
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	closed   bool
+}
+
+// NewServer creates a Server which executes statements using the executor.
+func NewServer(context *base.Context, executor *sqlserver.Server) *Server {
+	return &Server{
+		context:  context,
+		executor: executor,
+		conns:    make(map[net.Conn]struct{}),
+	}
+}
+
+// Start listens on the address and serves client connections until the
+// stopper is stopped. After this method returns, the socket has been bound.
+// Use Server.Addr() to ascertain the server address.
+func (s *Server) Start(addr net.Addr, stopper *util.Stopper) error {
+	ln, err := net.Listen(addr.Network(), addr.String())
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.listener = ln
+	s.mu.Unlock()
+
+	stopper.RunWorker(func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				if !isClosedConnError(err) {
+					log.Error(err)
+				}
+				return
+			}
+			if !s.addConn(conn) {
+				conn.Close()
+				return
+			}
+			go func() {
+				defer s.removeConn(conn)
+				if err := s.serveConn(conn); err != nil && !isClosedConnError(err) {
+					log.Infof("pgwire connection from %s: %s", conn.RemoteAddr(), err)
+				}
+			}()
+		}
+	})
+
+	stopper.RunWorker(func() {
+		<-stopper.ShouldStop()
+		s.Close()
+	})
+	return nil
+}
+
+// isClosedConnError returns true if the error results from the connection
+// being closed, either by the client or by the server shutting down.
+func isClosedConnError(err error) bool {
+	return err == io.EOF || strings.HasSuffix(err.Error(), "use of closed network connection")
+}
+
+// Addr returns the address the server is listening on.
+func (s *Server) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.listener.Addr()
+}
+
+// Close closes the listener and all client connections.
+func (s *Server) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	s.closed = true
+	for conn := range s.conns {
+		conn.Close()
+	}
+}
+
+// addConn tracks the connection so that it is closed along with the server,
+// returning false if the server has already been closed.
+func (s *Server) addConn(conn net.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	s.conns[conn] = struct{}{}
+	return true
+}
+
+func (s *Server) removeConn(conn net.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	conn.Close()
+	delete(s.conns, conn)
+}
+
+// serveConn serves a single client connection. The connection is upgraded
+// to TLS if requested by the client before the startup message is read.
+func (s *Server) serveConn(conn net.Conn) error {
+	var buf readBuffer
+	if err := buf.readUntypedMsg(conn); err != nil {
+		return err
+	}
+	version, err := buf.getInt32()
+	if err != nil {
+		return err
+	}
+	if version == versionSSL {
+		tlsConfig, err := s.context.GetServerTLSConfig()
+		if err != nil {
+			return err
+		}
+		if tlsConfig == nil {
+			// Insecure mode: refuse the TLS upgrade. The client may continue
+			// without encryption.
+			if _, err := conn.Write([]byte{'N'}); err != nil {
+				return err
+			}
+		} else {
+			if _, err := conn.Write([]byte{'S'}); err != nil {
+				return err
+			}
+			tlsConn := tls.Server(conn, tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return err
+			}
+			conn = tlsConn
+		}
+		if err := buf.readUntypedMsg(conn); err != nil {
+			return err
+		}
+		if version, err = buf.getInt32(); err != nil {
+			return err
+		}
+	}
+	if version != version30 {
+		return util.Errorf("unsupported protocol version: %d", version)
+	}
+
+	c := newV3Conn(conn, s.executor)
+	defer c.close()
+	return c.serve(s.context.Insecure, &buf)
+}
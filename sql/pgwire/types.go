@@ -0,0 +1,389 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package pgwire
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/hex"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/sql/sqlwire"
+	"github.com/cockroachdb/cockroach/structured"
+	"github.com/cockroachdb/cockroach/util"
+	"github.com/cockroachdb/cockroach/util/decimal"
+)
+
+// oid is a PostgreSQL type identifier. See the pg_type system catalog.
+type oid uint32
+
+const (
+	oidBool        oid = 16
+	oidBytea       oid = 17
+	oidInt8        oid = 20
+	oidInt2        oid = 21
+	oidInt4        oid = 23
+	oidText        oid = 25
+	oidFloat4      oid = 700
+	oidFloat8      oid = 701
+	oidUnknown     oid = 705
+	oidVarchar     oid = 1043
+	oidDate        oid = 1082
+	oidTimestamp   oid = 1114
+	oidTimestampTZ oid = 1184
+	oidNumeric     oid = 1700
+)
+
+// pgEpoch is the epoch of the binary encodings of dates and timestamps.
+var pgEpoch = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+const (
+	secondsPerDay = 24 * 60 * 60
+	// The text formats of timestamps and dates. The minutes of the zone
+	// offset are only written if they are not zero.
+	timestampFormat        = "2006-01-02 15:04:05.999999999-07"
+	timestampMinutesFormat = "2006-01-02 15:04:05.999999999-07:00"
+	dateFormat             = "2006-01-02"
+)
+
+// typeSize returns the size of the values of the type in bytes, or -1 for
+// variable length types.
+func (o oid) typeSize() int16 {
+	switch o {
+	case oidBool:
+		return 1
+	case oidInt2:
+		return 2
+	case oidInt4, oidFloat4, oidDate:
+		return 4
+	case oidInt8, oidFloat8, oidTimestamp, oidTimestampTZ:
+		return 8
+	}
+	return -1
+}
+
+// formatCode is the encoding of a value: text or binary.
+type formatCode int16
+
+const (
+	formatText   formatCode = 0
+	formatBinary formatCode = 1
+)
+
+// datumOid returns the type of the datum.
+func datumOid(d *sqlwire.Datum) oid {
+	switch {
+	case d.Bval != nil:
+		return oidBool
+	case d.Ival != nil:
+		return oidInt8
+	case d.Dval != nil:
+		return oidFloat8
+	case d.Blobval != nil:
+		return oidBytea
+	case d.Dateval != nil:
+		return oidDate
+	case d.Timeval != nil:
+		return oidTimestampTZ
+	case d.Decimalval != nil:
+		return oidNumeric
+	}
+	return oidText
+}
+
+// columnOids returns the types of the columns of the results, determined by
+// the first non-NULL value of every column. Columns containing only NULL
+// values are reported as text.
+func columnOids(resp *sqlwire.SQLResponse) []oid {
+	oids := make([]oid, len(resp.Columns))
+	for i := range oids {
+		oids[i] = oidText
+		for _, result := range resp.Results {
+			if d := result.Values[i]; !d.IsNull() {
+				oids[i] = datumOid(d)
+				break
+			}
+		}
+	}
+	return oids
+}
+
+// writeTextDatum writes the datum in the text format, prefixed by its length.
+// NULL is written as a length of -1. Timestamps are written in the location.
+func (b *writeBuffer) writeTextDatum(d *sqlwire.Datum, loc *time.Location) {
+	var s string
+	switch {
+	case d.Bval != nil:
+		s = "f"
+		if *d.Bval {
+			s = "t"
+		}
+	case d.Ival != nil:
+		s = strconv.FormatInt(*d.Ival, 10)
+	case d.Dval != nil:
+		switch f := *d.Dval; {
+		case math.IsNaN(f):
+			s = "NaN"
+		case math.IsInf(f, 1):
+			s = "Infinity"
+		case math.IsInf(f, -1):
+			s = "-Infinity"
+		default:
+			s = strconv.FormatFloat(f, 'g', -1, 64)
+		}
+	case d.Blobval != nil:
+		s = `\x` + hex.EncodeToString(d.Blobval)
+	case d.Strval != nil:
+		s = *d.Strval
+	case d.Dateval != nil:
+		s = time.Unix(*d.Dateval*secondsPerDay, 0).UTC().Format(dateFormat)
+	case d.Timeval != nil:
+		t := time.Unix(d.Timeval.Sec, int64(d.Timeval.Nsec)).In(loc)
+		format := timestampFormat
+		if _, offset := t.Zone(); offset%3600 != 0 {
+			format = timestampMinutesFormat
+		}
+		s = t.Format(format)
+	case d.Decimalval != nil:
+		s = *d.Decimalval
+	default:
+		b.putInt32(-1)
+		return
+	}
+	b.putInt32(int32(len(s)))
+	b.WriteString(s)
+}
+
+// writeBinaryDatum writes the datum in the binary format, prefixed by its
+// length. NULL is written as a length of -1.
+func (b *writeBuffer) writeBinaryDatum(d *sqlwire.Datum) {
+	switch {
+	case d.Bval != nil:
+		b.putInt32(1)
+		if *d.Bval {
+			b.WriteByte(1)
+		} else {
+			b.WriteByte(0)
+		}
+	case d.Ival != nil:
+		b.putInt32(8)
+		binary.BigEndian.PutUint64(b.putbuf[:], uint64(*d.Ival))
+		b.Write(b.putbuf[:8])
+	case d.Dval != nil:
+		b.putInt32(8)
+		binary.BigEndian.PutUint64(b.putbuf[:], math.Float64bits(*d.Dval))
+		b.Write(b.putbuf[:8])
+	case d.Blobval != nil:
+		b.putInt32(int32(len(d.Blobval)))
+		b.Write(d.Blobval)
+	case d.Strval != nil:
+		b.putInt32(int32(len(*d.Strval)))
+		b.WriteString(*d.Strval)
+	case d.Dateval != nil:
+		b.putInt32(4)
+		days := *d.Dateval - pgEpoch.Unix()/secondsPerDay
+		binary.BigEndian.PutUint32(b.putbuf[:], uint32(days))
+		b.Write(b.putbuf[:4])
+	case d.Timeval != nil:
+		// Timestamps are transmitted as the number of microseconds since the
+		// epoch, losing the nanoseconds.
+		b.putInt32(8)
+		usec := (d.Timeval.Sec-pgEpoch.Unix())*1000000 + int64(d.Timeval.Nsec/1000)
+		binary.BigEndian.PutUint64(b.putbuf[:], uint64(usec))
+		b.Write(b.putbuf[:8])
+	case d.Decimalval != nil:
+		buf := encodeBinaryNumeric(*d.Decimalval)
+		b.putInt32(int32(len(buf)))
+		b.Write(buf)
+	default:
+		b.putInt32(-1)
+	}
+}
+
+// encodeBinaryNumeric encodes a decimal value in the binary format of a
+// numeric: the number of base-10000 digits, the weight of the first digit,
+// the sign and the number of decimal digits after the decimal point, each a
+// 16-bit integer, followed by the base-10000 digits.
+func encodeBinaryNumeric(s string) []byte {
+	const (
+		numericPos = 0x0000
+		numericNeg = 0x4000
+	)
+	sign := numericPos
+	if strings.HasPrefix(s, "-") {
+		sign = numericNeg
+		s = s[1:]
+	}
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, fracPart = s[:i], s[i+1:]
+	}
+	dscale := len(fracPart)
+
+	// Pad the integer and fractional parts to a multiple of 4 digits so that
+	// they can be split into base-10000 digits.
+	if n := len(intPart) % 4; n != 0 {
+		intPart = strings.Repeat("0", 4-n) + intPart
+	}
+	if n := len(fracPart) % 4; n != 0 {
+		fracPart += strings.Repeat("0", 4-n)
+	}
+	all := intPart + fracPart
+	digits := make([]int16, 0, len(all)/4)
+	for i := 0; i < len(all); i += 4 {
+		d, _ := strconv.Atoi(all[i : i+4])
+		digits = append(digits, int16(d))
+	}
+	weight := len(intPart)/4 - 1
+
+	// Strip the leading and trailing zero digits.
+	for len(digits) > 0 && digits[0] == 0 {
+		digits = digits[1:]
+		weight--
+	}
+	for len(digits) > 0 && digits[len(digits)-1] == 0 {
+		digits = digits[:len(digits)-1]
+	}
+	if len(digits) == 0 {
+		weight, sign = 0, numericPos
+	}
+
+	buf := make([]byte, 8+2*len(digits))
+	binary.BigEndian.PutUint16(buf[0:], uint16(len(digits)))
+	binary.BigEndian.PutUint16(buf[2:], uint16(weight))
+	binary.BigEndian.PutUint16(buf[4:], uint16(sign))
+	binary.BigEndian.PutUint16(buf[6:], uint16(dscale))
+	for i, d := range digits {
+		binary.BigEndian.PutUint16(buf[8+2*i:], uint16(d))
+	}
+	return buf
+}
+
+// columnTypeOid returns the type used to decode the arguments for a column of
+// the specified type. Dates and times are decoded as text, which is parsed by
+// the statement using the formats it supports.
+func columnTypeOid(typ structured.ColumnType) oid {
+	switch typ.Kind {
+	case structured.ColumnType_BIT, structured.ColumnType_INT:
+		return oidInt8
+	case structured.ColumnType_FLOAT:
+		return oidFloat8
+	case structured.ColumnType_DECIMAL:
+		return oidNumeric
+	case structured.ColumnType_BINARY, structured.ColumnType_BLOB:
+		return oidBytea
+	}
+	return oidText
+}
+
+// decodeParam decodes the value of an argument of the specified type and
+// format.
+func decodeParam(b []byte, typ oid, code formatCode) (*sqlwire.Datum, error) {
+	var v driver.Value
+	var err error
+	switch code {
+	case formatText:
+		v, err = decodeTextParam(string(b), typ)
+	case formatBinary:
+		v, err = decodeBinaryParam(b, typ)
+	default:
+		err = util.Errorf("unsupported format code: %d", code)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return sqlwire.MakeDatum(v)
+}
+
+func decodeTextParam(s string, typ oid) (driver.Value, error) {
+	switch typ {
+	case oidBool:
+		switch strings.ToLower(s) {
+		case "t", "true", "y", "yes", "on", "1":
+			return true, nil
+		case "f", "false", "n", "no", "off", "0":
+			return false, nil
+		}
+		return nil, util.Errorf("invalid input syntax for type boolean: %q", s)
+	case oidInt2, oidInt4, oidInt8:
+		return strconv.ParseInt(s, 10, 64)
+	case oidFloat4, oidFloat8:
+		return strconv.ParseFloat(s, 64)
+	case oidNumeric:
+		return decimal.Parse(s)
+	case oidDate:
+		return time.Parse(dateFormat, s)
+	case oidTimestamp, oidTimestampTZ:
+		for _, format := range []string{
+			"2006-01-02 15:04:05.999999999Z07:00",
+			"2006-01-02 15:04:05.999999999-07",
+			"2006-01-02 15:04:05.999999999",
+		} {
+			if t, err := time.Parse(format, s); err == nil {
+				return t.UTC(), nil
+			}
+		}
+		return nil, util.Errorf("invalid input syntax for type timestamp: %q", s)
+	case oidBytea:
+		if strings.HasPrefix(s, `\x`) {
+			return hex.DecodeString(s[2:])
+		}
+		return []byte(s), nil
+	case oidText, oidVarchar:
+		return s, nil
+	case 0, oidUnknown:
+		// The type of the argument could not be inferred from the statement.
+		// The value is passed as a string, which is converted to a date or a
+		// time when necessary.
+		return s, nil
+	}
+	return nil, util.Errorf("unsupported argument type: %d", typ)
+}
+
+func decodeBinaryParam(b []byte, typ oid) (driver.Value, error) {
+	if size := typ.typeSize(); size != -1 && len(b) != int(size) {
+		return nil, util.Errorf("invalid binary value length %d for type %d", len(b), typ)
+	}
+	switch typ {
+	case oidBool:
+		return b[0] != 0, nil
+	case oidInt2:
+		return int64(int16(binary.BigEndian.Uint16(b))), nil
+	case oidInt4:
+		return int64(int32(binary.BigEndian.Uint32(b))), nil
+	case oidInt8:
+		return int64(binary.BigEndian.Uint64(b)), nil
+	case oidFloat4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), nil
+	case oidFloat8:
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+	case oidDate:
+		days := int64(int32(binary.BigEndian.Uint32(b)))
+		return pgEpoch.AddDate(0, 0, int(days)), nil
+	case oidTimestamp, oidTimestampTZ:
+		usec := int64(binary.BigEndian.Uint64(b))
+		return time.Unix(pgEpoch.Unix()+usec/1000000, (usec%1000000)*1000).UTC(), nil
+	case oidText, oidVarchar:
+		return string(b), nil
+	case 0, oidUnknown, oidBytea:
+		// The message buffer is reused, so the value must be copied.
+		return append([]byte(nil), b...), nil
+	}
+	return nil, util.Errorf("unsupported argument type: %d", typ)
+}
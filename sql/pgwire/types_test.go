@@ -0,0 +1,46 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package pgwire
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/util/leaktest"
+)
+
+func TestEncodeBinaryNumeric(t *testing.T) {
+	defer leaktest.AfterTest(t)
+
+	testData := []struct {
+		s        string
+		expected []byte
+	}{
+		{"0", []byte{0, 0, 0, 0, 0, 0, 0, 0}},
+		{"0.00", []byte{0, 0, 0, 0, 0, 0, 0, 2}},
+		// 12.345 is 12 + 3450/10000.
+		{"12.345", []byte{0, 2, 0, 0, 0, 0, 0, 3, 0, 12, 0x0d, 0x7a}},
+		// -10000 is 1*10000^1.
+		{"-10000", []byte{0, 1, 0, 1, 0x40, 0, 0, 0, 0, 1}},
+		// 0.0001 is 1*10000^-1.
+		{"0.0001", []byte{0, 1, 0xff, 0xff, 0, 0, 0, 4, 0, 1}},
+	}
+	for _, d := range testData {
+		if b := encodeBinaryNumeric(d.s); !bytes.Equal(d.expected, b) {
+			t.Errorf("%s: expected [% x], but found [% x]", d.s, d.expected, b)
+		}
+	}
+}
@@ -0,0 +1,814 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package pgwire
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/proto"
+	"github.com/cockroachdb/cockroach/security"
+	"github.com/cockroachdb/cockroach/sql/parser"
+	"github.com/cockroachdb/cockroach/sql/sqlserver"
+	"github.com/cockroachdb/cockroach/sql/sqlwire"
+	"github.com/cockroachdb/cockroach/util"
+	"github.com/cockroachdb/cockroach/util/log"
+	gogoproto "github.com/gogo/protobuf/proto"
+)
+
+// clientMessageType is the type byte of a message sent by a client.
+type clientMessageType byte
+
+// serverMessageType is the type byte of a message sent by the server.
+type serverMessageType byte
+
+// http://www.postgresql.org/docs/9.4/static/protocol-message-formats.html
+const (
+	clientMsgBind        clientMessageType = 'B'
+	clientMsgClose       clientMessageType = 'C'
+	clientMsgDescribe    clientMessageType = 'D'
+	clientMsgExecute     clientMessageType = 'E'
+	clientMsgFlush       clientMessageType = 'H'
+	clientMsgParse       clientMessageType = 'P'
+	clientMsgSimpleQuery clientMessageType = 'Q'
+	clientMsgSync        clientMessageType = 'S'
+	clientMsgTerminate   clientMessageType = 'X'
+
+	serverMsgAuth                 serverMessageType = 'R'
+	serverMsgBindComplete         serverMessageType = '2'
+	serverMsgCloseComplete        serverMessageType = '3'
+	serverMsgCommandComplete      serverMessageType = 'C'
+	serverMsgDataRow              serverMessageType = 'D'
+	serverMsgEmptyQuery           serverMessageType = 'I'
+	serverMsgErrorResponse        serverMessageType = 'E'
+	serverMsgNoData               serverMessageType = 'n'
+	serverMsgParameterDescription serverMessageType = 't'
+	serverMsgParameterStatus      serverMessageType = 'S'
+	serverMsgParseComplete        serverMessageType = '1'
+	serverMsgPortalSuspended      serverMessageType = 's'
+	serverMsgReady                serverMessageType = 'Z'
+	serverMsgRowDescription       serverMessageType = 'T'
+)
+
+// The error codes sent to the client. See
+// http://www.postgresql.org/docs/9.4/static/errcodes-appendix.html.
+const (
+	errCodeInternal             = "XX000"
+	errCodeInvalidAuthorization = "28000"
+	errCodeProtocolViolation    = "08P01"
+	errCodeSyntax               = "42601"
+)
+
+// parameterStatus are the run-time parameters reported to the client after
+// it has been authenticated.
+var parameterStatus = []struct{ name, value string }{
+	{"client_encoding", "UTF8"},
+	{"DateStyle", "ISO"},
+	{"integer_datetimes", "on"},
+	{"server_version", "9.5.0"},
+	// String literals interpret backslash escapes.
+	{"standard_conforming_strings", "off"},
+}
+
+// preparedStatement is a statement created by a Parse message.
+type preparedStatement struct {
+	query string
+	// The parsed statement, nil for an empty query.
+	stmt parser.Statement
+	// The types of the arguments. Arguments whose type was not specified by
+	// the client have type 0.
+	inTypes []oid
+}
+
+// preparedPortal is a statement bound to its arguments by a Bind message.
+type preparedPortal struct {
+	stmt   *preparedStatement
+	params []*sqlwire.Datum
+	// The format codes requested for the result columns. See resolveFormats.
+	outFormats []formatCode
+	// True if the portal was described by a Describe message, which describes
+	// the result columns as text. Their values are then sent as text.
+	described bool
+	// The results of the statement. The statement is executed by the first
+	// Execute message.
+	resp *sqlwire.SQLResponse
+	// The number of rows sent to the client by previous Execute messages.
+	sent int
+}
+
+// v3Conn serves a single client connection using version 3.0 of the
+// protocol. The session state and the transaction in progress returned by the
+// executor are reflected back in the next request, in the same way as
+// sql/driver reflects them back to the SQL API.
+type v3Conn struct {
+	conn     net.Conn
+	rd       *bufio.Reader
+	wr       *bufio.Writer
+	executor *sqlserver.Server
+	readBuf  readBuffer
+	writeBuf writeBuffer
+
+	// The authenticated user the statements are executed as.
+	user    string
+	session []byte
+	txn     []byte
+	// The time zone of the session in which timestamps are sent.
+	location *time.Location
+
+	preparedStatements map[string]*preparedStatement
+	preparedPortals    map[string]*preparedPortal
+
+	// Set after an error occurred while processing an extended query
+	// message. All messages are ignored until the next Sync message.
+	ignoreTillSync bool
+}
+
+func newV3Conn(conn net.Conn, executor *sqlserver.Server) *v3Conn {
+	return &v3Conn{
+		conn:               conn,
+		rd:                 bufio.NewReader(conn),
+		wr:                 bufio.NewWriter(conn),
+		executor:           executor,
+		location:           time.UTC,
+		preparedStatements: make(map[string]*preparedStatement),
+		preparedPortals:    make(map[string]*preparedPortal),
+	}
+}
+
+// close rolls back the transaction in progress, if any. A transaction which
+// is not rolled back is eventually aborted by its coordinator.
+func (c *v3Conn) close() {
+	if c.txn != nil {
+		if _, err := c.execute("ROLLBACK TRANSACTION", nil); err != nil {
+			log.Warningf("unable to roll back transaction: %s", err)
+		}
+	}
+}
+
+// serve authenticates the client using the parameters of the startup message
+// and then processes messages until the client terminates the connection.
+func (c *v3Conn) serve(insecure bool, startup *readBuffer) error {
+	params := map[string]string{}
+	for {
+		key, err := startup.getString()
+		if err != nil {
+			return err
+		}
+		if key == "" {
+			break
+		}
+		if params[key], err = startup.getString(); err != nil {
+			return err
+		}
+	}
+
+	var tlsState *tls.ConnectionState
+	if tlsConn, ok := c.conn.(*tls.Conn); ok {
+		state := tlsConn.ConnectionState()
+		tlsState = &state
+	}
+	if err := security.AuthenticateUser(insecure, tlsState, params["user"]); err != nil {
+		if err := c.sendErrorWithCode(errCodeInvalidAuthorization, err); err != nil {
+			return err
+		}
+		return c.wr.Flush()
+	}
+	c.user = params["user"]
+	if database := params["database"]; database != "" {
+		var err error
+		if c.session, err = gogoproto.Marshal(&sqlwire.Session{Database: database}); err != nil {
+			return err
+		}
+	}
+
+	c.writeBuf.putInt32(0) // AuthenticationOk
+	if err := c.writeBuf.finishMsg(c.wr, serverMsgAuth); err != nil {
+		return err
+	}
+	for _, p := range parameterStatus {
+		c.writeBuf.writeString(p.name)
+		c.writeBuf.writeString(p.value)
+		if err := c.writeBuf.finishMsg(c.wr, serverMsgParameterStatus); err != nil {
+			return err
+		}
+	}
+	if err := c.sendReadyForQuery(); err != nil {
+		return err
+	}
+
+	for {
+		typ, err := c.readBuf.readTypedMsg(c.rd)
+		if err != nil {
+			return err
+		}
+		if c.ignoreTillSync && typ != clientMsgSync {
+			continue
+		}
+		switch typ {
+		case clientMsgSync:
+			c.ignoreTillSync = false
+			err = c.sendReadyForQuery()
+		case clientMsgSimpleQuery:
+			err = c.handleSimpleQuery()
+		case clientMsgTerminate:
+			return nil
+		case clientMsgParse:
+			err = c.handleParse()
+		case clientMsgBind:
+			err = c.handleBind()
+		case clientMsgDescribe:
+			err = c.handleDescribe()
+		case clientMsgExecute:
+			err = c.handleExecute()
+		case clientMsgClose:
+			err = c.handleClose()
+		case clientMsgFlush:
+			err = c.wr.Flush()
+		default:
+			err = c.sendExtendedError(errCodeProtocolViolation,
+				util.Errorf("unrecognized client message type %q", byte(typ)))
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// updateLocation loads the time zone of the session state returned by the
+// executor if it has changed.
+func (c *v3Conn) updateLocation() error {
+	var state sqlwire.Session
+	if err := gogoproto.Unmarshal(c.session, &state); err != nil {
+		return err
+	}
+	name := state.TimeZone
+	if name == "" {
+		name = "UTC"
+	}
+	if c.location.String() == name {
+		return nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return err
+	}
+	c.location = loc
+	return nil
+}
+
+// execute executes the query using the session state of the connection,
+// returning the error of a query which failed.
+func (c *v3Conn) execute(query string, params []*sqlwire.Datum) (*sqlwire.SQLResponse, error) {
+	req := &sqlwire.SQLRequest{
+		SQLRequestHeader: sqlwire.SQLRequestHeader{
+			Session: c.session,
+			Txn:     c.txn,
+			User:    c.user,
+		},
+		Cmds: []*sqlwire.SQLRequest_Cmd{{Sql: &query, Params: params}},
+	}
+	resp := &sqlwire.SQLResponse{}
+	if err := c.executor.Execute(req, resp); err != nil {
+		return nil, err
+	}
+	// The session state and transaction are returned even if the statement
+	// failed.
+	if resp.Settings != nil {
+		c.session = resp.Settings
+		if err := c.updateLocation(); err != nil {
+			return nil, err
+		}
+	}
+	c.txn = resp.Txn
+	if err := resp.GoError(); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// handleSimpleQuery parses and executes the query, sending its results using
+// the text format. Only a single statement is supported.
+func (c *v3Conn) handleSimpleQuery() error {
+	query, err := c.readBuf.getString()
+	if err != nil {
+		return err
+	}
+	if isEmptyQuery(query) {
+		if err := c.writeBuf.finishMsg(c.wr, serverMsgEmptyQuery); err != nil {
+			return err
+		}
+		return c.sendReadyForQuery()
+	}
+
+	if err := c.runQuery(query); err != nil {
+		return err
+	}
+	return c.sendReadyForQuery()
+}
+
+func (c *v3Conn) runQuery(query string) error {
+	stmt, err := parser.Parse(query)
+	if err != nil {
+		return c.sendErrorWithCode(errCodeSyntax, err)
+	}
+	resp, err := c.execute(query, nil)
+	if err != nil {
+		return c.sendError(err)
+	}
+	if resp.Columns != nil {
+		if err := c.sendRowDescription(resp.Columns, columnOids(resp), nil); err != nil {
+			return err
+		}
+	}
+	if err := c.sendDataRows(resp.Results, nil); err != nil {
+		return err
+	}
+	return c.sendCommandComplete(stmt, resp, int64(len(resp.Results)))
+}
+
+func (c *v3Conn) handleParse() error {
+	name, err := c.readBuf.getString()
+	if err != nil {
+		return err
+	}
+	query, err := c.readBuf.getString()
+	if err != nil {
+		return err
+	}
+	numTypes, err := c.readBuf.getCount(4)
+	if err != nil {
+		return c.sendExtendedError(errCodeProtocolViolation, err)
+	}
+	types := make([]oid, numTypes)
+	for i := range types {
+		t, err := c.readBuf.getInt32()
+		if err != nil {
+			return err
+		}
+		types[i] = oid(t)
+	}
+
+	if _, ok := c.preparedStatements[name]; ok && name != "" {
+		return c.sendExtendedError(errCodeProtocolViolation,
+			util.Errorf("prepared statement %q already exists", name))
+	}
+	ps := &preparedStatement{query: query}
+	numArgs := 0
+	if !isEmptyQuery(query) {
+		if ps.stmt, numArgs, err = parser.ParseWithNumArgs(query); err != nil {
+			return c.sendExtendedError(errCodeSyntax, err)
+		}
+	}
+	if numArgs < len(types) {
+		numArgs = len(types)
+	}
+	ps.inTypes = make([]oid, numArgs)
+	copy(ps.inTypes, types)
+	if ps.stmt != nil {
+		// The types of the arguments which were not specified by the client are
+		// inferred from the statement. An error, such as a reference to a table
+		// which does not exist, is reported when the statement is executed.
+		h := &sqlwire.SQLRequestHeader{Session: c.session, Txn: c.txn, User: c.user}
+		if argTypes, err := c.executor.DescribeArgs(h, ps.stmt, numArgs); err == nil {
+			for i, t := range argTypes {
+				if ps.inTypes[i] == 0 && t != nil {
+					ps.inTypes[i] = columnTypeOid(*t)
+				}
+			}
+		}
+	}
+	c.preparedStatements[name] = ps
+	return c.writeBuf.finishMsg(c.wr, serverMsgParseComplete)
+}
+
+func (c *v3Conn) handleBind() error {
+	portalName, err := c.readBuf.getString()
+	if err != nil {
+		return err
+	}
+	stmtName, err := c.readBuf.getString()
+	if err != nil {
+		return err
+	}
+	paramFormats, err := c.readFormatCodes()
+	if err != nil {
+		return c.sendExtendedError(errCodeProtocolViolation, err)
+	}
+	// Each parameter is prefixed by its 4 byte length.
+	numParams, err := c.readBuf.getCount(4)
+	if err != nil {
+		return c.sendExtendedError(errCodeProtocolViolation, err)
+	}
+	paramValues := make([][]byte, numParams)
+	for i := range paramValues {
+		n, err := c.readBuf.getInt32()
+		if err != nil {
+			return err
+		}
+		if n == -1 {
+			// NULL.
+			continue
+		}
+		if paramValues[i], err = c.readBuf.getBytes(int(n)); err != nil {
+			return err
+		}
+	}
+	outFormats, err := c.readFormatCodes()
+	if err != nil {
+		return c.sendExtendedError(errCodeProtocolViolation, err)
+	}
+
+	ps, ok := c.preparedStatements[stmtName]
+	if !ok {
+		return c.sendExtendedError(errCodeProtocolViolation,
+			util.Errorf("unknown prepared statement %q", stmtName))
+	}
+	if _, ok := c.preparedPortals[portalName]; ok && portalName != "" {
+		return c.sendExtendedError(errCodeProtocolViolation,
+			util.Errorf("portal %q already exists", portalName))
+	}
+	if len(paramValues) != len(ps.inTypes) {
+		return c.sendExtendedError(errCodeProtocolViolation,
+			util.Errorf("expected %d arguments, got %d", len(ps.inTypes), len(paramValues)))
+	}
+	formats, err := resolveFormats(paramFormats, len(paramValues))
+	if err != nil {
+		return c.sendExtendedError(errCodeProtocolViolation, err)
+	}
+	params := make([]*sqlwire.Datum, len(paramValues))
+	for i, b := range paramValues {
+		if b == nil {
+			params[i] = &sqlwire.Datum{}
+			continue
+		}
+		if params[i], err = decodeParam(b, ps.inTypes[i], formats[i]); err != nil {
+			return c.sendExtendedError(errCodeProtocolViolation,
+				util.Errorf("argument %d: %s", i+1, err))
+		}
+	}
+
+	c.preparedPortals[portalName] = &preparedPortal{
+		stmt:       ps,
+		params:     params,
+		outFormats: outFormats,
+	}
+	return c.writeBuf.finishMsg(c.wr, serverMsgBindComplete)
+}
+
+func (c *v3Conn) handleDescribe() error {
+	typ, err := c.readBuf.getBytes(1)
+	if err != nil {
+		return err
+	}
+	name, err := c.readBuf.getString()
+	if err != nil {
+		return err
+	}
+
+	switch typ[0] {
+	case 'S':
+		ps, ok := c.preparedStatements[name]
+		if !ok {
+			return c.sendExtendedError(errCodeProtocolViolation,
+				util.Errorf("unknown prepared statement %q", name))
+		}
+		c.writeBuf.putInt16(int16(len(ps.inTypes)))
+		for _, t := range ps.inTypes {
+			if t == 0 {
+				t = oidText
+			}
+			c.writeBuf.putInt32(int32(t))
+		}
+		if err := c.writeBuf.finishMsg(c.wr, serverMsgParameterDescription); err != nil {
+			return err
+		}
+		if ps.stmt == nil {
+			return c.writeBuf.finishMsg(c.wr, serverMsgNoData)
+		}
+		h := &sqlwire.SQLRequestHeader{Session: c.session, Txn: c.txn, User: c.user}
+		columns, err := c.executor.Describe(h, ps.stmt)
+		if err != nil {
+			return c.sendExtendedError(errCodeInternal, err)
+		}
+		if columns == nil {
+			return c.writeBuf.finishMsg(c.wr, serverMsgNoData)
+		}
+		// TODO: The types of the result columns are not known until
+		// the statement is executed. Describe the columns as text, which is
+		// how their values are sent unless the client requests otherwise.
+		oids := make([]oid, len(columns))
+		for i := range oids {
+			oids[i] = oidText
+		}
+		return c.sendRowDescription(columns, oids, nil)
+
+	case 'P':
+		p, ok := c.preparedPortals[name]
+		if !ok {
+			return c.sendExtendedError(errCodeProtocolViolation,
+				util.Errorf("unknown portal %q", name))
+		}
+		if p.stmt.stmt == nil {
+			return c.writeBuf.finishMsg(c.wr, serverMsgNoData)
+		}
+		// The statement is not executed until the portal is executed, so the
+		// result columns are described as for a prepared statement.
+		h := &sqlwire.SQLRequestHeader{Session: c.session, Txn: c.txn, User: c.user}
+		columns, err := c.executor.Describe(h, p.stmt.stmt)
+		if err != nil {
+			return c.sendExtendedError(errCodeInternal, err)
+		}
+		if columns == nil {
+			return c.writeBuf.finishMsg(c.wr, serverMsgNoData)
+		}
+		formats, err := resolveFormats(p.outFormats, len(columns))
+		if err != nil {
+			return c.sendExtendedError(errCodeProtocolViolation, err)
+		}
+		oids := make([]oid, len(columns))
+		for i := range oids {
+			oids[i] = oidText
+		}
+		p.described = true
+		return c.sendRowDescription(columns, oids, formats)
+
+	default:
+		return c.sendExtendedError(errCodeProtocolViolation,
+			util.Errorf("invalid describe type %q", typ[0]))
+	}
+}
+
+func (c *v3Conn) handleExecute() error {
+	name, err := c.readBuf.getString()
+	if err != nil {
+		return err
+	}
+	limit, err := c.readBuf.getInt32()
+	if err != nil {
+		return err
+	}
+
+	p, ok := c.preparedPortals[name]
+	if !ok {
+		return c.sendExtendedError(errCodeProtocolViolation,
+			util.Errorf("unknown portal %q", name))
+	}
+	if p.stmt.stmt == nil {
+		return c.writeBuf.finishMsg(c.wr, serverMsgEmptyQuery)
+	}
+	if err := c.executePortal(p); err != nil {
+		return c.sendExtendedError(errCodeInternal, err)
+	}
+	formats, err := resolveFormats(p.outFormats, len(p.resp.Columns))
+	if err != nil {
+		return c.sendExtendedError(errCodeProtocolViolation, err)
+	}
+	if p.described {
+		// The binary format of a value described as text is its text format.
+		formats = nil
+	}
+
+	// A limit of 0 requests all of the remaining rows.
+	results := p.resp.Results[p.sent:]
+	if limit > 0 && int(limit) < len(results) {
+		results = results[:limit]
+	}
+	if err := c.sendDataRows(results, formats); err != nil {
+		return err
+	}
+	p.sent += len(results)
+	if p.sent < len(p.resp.Results) {
+		return c.writeBuf.finishMsg(c.wr, serverMsgPortalSuspended)
+	}
+	return c.sendCommandComplete(p.stmt.stmt, p.resp, int64(len(results)))
+}
+
+// executePortal executes the statement of the portal unless the statement
+// has already been executed.
+func (c *v3Conn) executePortal(p *preparedPortal) error {
+	if p.resp != nil {
+		return nil
+	}
+	resp, err := c.execute(p.stmt.query, p.params)
+	if err != nil {
+		return err
+	}
+	p.resp = resp
+	return nil
+}
+
+func (c *v3Conn) handleClose() error {
+	typ, err := c.readBuf.getBytes(1)
+	if err != nil {
+		return err
+	}
+	name, err := c.readBuf.getString()
+	if err != nil {
+		return err
+	}
+	switch typ[0] {
+	case 'S':
+		delete(c.preparedStatements, name)
+	case 'P':
+		delete(c.preparedPortals, name)
+	default:
+		return c.sendExtendedError(errCodeProtocolViolation,
+			util.Errorf("invalid close type %q", typ[0]))
+	}
+	return c.writeBuf.finishMsg(c.wr, serverMsgCloseComplete)
+}
+
+// readFormatCodes reads a list of format codes.
+func (c *v3Conn) readFormatCodes() ([]formatCode, error) {
+	n, err := c.readBuf.getCount(2)
+	if err != nil {
+		return nil, err
+	}
+	codes := make([]formatCode, n)
+	for i := range codes {
+		code, err := c.readBuf.getInt16()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = formatCode(code)
+	}
+	return codes, nil
+}
+
+// resolveFormats returns the format codes of n values. No format codes
+// specifies the text format for all of the values and a single format code
+// applies to all of the values.
+func resolveFormats(codes []formatCode, n int) ([]formatCode, error) {
+	formats := make([]formatCode, n)
+	switch len(codes) {
+	case 0:
+	case 1:
+		for i := range formats {
+			formats[i] = codes[0]
+		}
+	case n:
+		copy(formats, codes)
+	default:
+		return nil, util.Errorf("expected 0, 1 or %d format codes, got %d", n, len(codes))
+	}
+	for _, code := range formats {
+		if code != formatText && code != formatBinary {
+			return nil, util.Errorf("unsupported format code: %d", code)
+		}
+	}
+	return formats, nil
+}
+
+// sendRowDescription describes the result columns. A nil formats sends all
+// of the columns using the text format.
+func (c *v3Conn) sendRowDescription(columns []string, oids []oid, formats []formatCode) error {
+	c.writeBuf.putInt16(int16(len(columns)))
+	for i, name := range columns {
+		c.writeBuf.writeString(name)
+		c.writeBuf.putInt32(0) // Table OID.
+		c.writeBuf.putInt16(0) // Column attribute number.
+		c.writeBuf.putInt32(int32(oids[i]))
+		c.writeBuf.putInt16(oids[i].typeSize())
+		c.writeBuf.putInt32(-1) // Type modifier.
+		if formats == nil {
+			c.writeBuf.putInt16(int16(formatText))
+		} else {
+			c.writeBuf.putInt16(int16(formats[i]))
+		}
+	}
+	return c.writeBuf.finishMsg(c.wr, serverMsgRowDescription)
+}
+
+// sendDataRows sends the rows using the format of each column. A nil formats
+// sends all of the columns using the text format.
+func (c *v3Conn) sendDataRows(results []*sqlwire.Result, formats []formatCode) error {
+	for _, result := range results {
+		c.writeBuf.putInt16(int16(len(result.Values)))
+		for i, d := range result.Values {
+			if formats != nil && formats[i] == formatBinary {
+				c.writeBuf.writeBinaryDatum(d)
+			} else {
+				c.writeBuf.writeTextDatum(d, c.location)
+			}
+		}
+		if err := c.writeBuf.finishMsg(c.wr, serverMsgDataRow); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sendCommandComplete sends the command tag of the statement. Statements
+// which return rows report the number of rows sent.
+func (c *v3Conn) sendCommandComplete(stmt parser.Statement, resp *sqlwire.SQLResponse, rowsSent int64) error {
+	var tag string
+	switch stmt.(type) {
+	case *parser.Select:
+		tag = fmt.Sprintf("SELECT %d", rowsSent)
+	case *parser.Insert:
+		// The OID of the inserted row is always 0.
+		tag = fmt.Sprintf("INSERT 0 %d", resp.RowsAffected)
+	case *parser.Update:
+		tag = fmt.Sprintf("UPDATE %d", resp.RowsAffected)
+	case *parser.Delete:
+		tag = fmt.Sprintf("DELETE %d", resp.RowsAffected)
+	case *parser.BeginTransaction:
+		tag = "BEGIN"
+	case *parser.CommitTransaction:
+		tag = "COMMIT"
+	case *parser.RollbackTransaction:
+		tag = "ROLLBACK"
+	case *parser.CreateDatabase:
+		tag = "CREATE DATABASE"
+	case *parser.CreateIndex:
+		tag = "CREATE INDEX"
+	case *parser.CreateTable:
+		tag = "CREATE TABLE"
+	case *parser.DropDatabase:
+		tag = "DROP DATABASE"
+	case *parser.DropIndex:
+		tag = "DROP INDEX"
+	case *parser.DropTable:
+		tag = "DROP TABLE"
+	case *parser.AlterTable, *parser.RenameTable:
+		tag = "ALTER TABLE"
+	case *parser.TruncateTable:
+		tag = "TRUNCATE TABLE"
+	case *parser.Use:
+		tag = "SET"
+	case *parser.ShowColumns, *parser.ShowCreateTable, *parser.ShowDatabases,
+		*parser.ShowIndex, *parser.ShowTables:
+		tag = "SHOW"
+	default:
+		tag = fmt.Sprintf("SELECT %d", rowsSent)
+	}
+	c.writeBuf.writeString(tag)
+	return c.writeBuf.finishMsg(c.wr, serverMsgCommandComplete)
+}
+
+// sendReadyForQuery reports the status of the transaction of the session and
+// flushes the messages queued for the client.
+func (c *v3Conn) sendReadyForQuery() error {
+	status := byte('I')
+	if c.txn != nil {
+		var txn proto.Transaction
+		if err := gogoproto.Unmarshal(c.txn, &txn); err != nil {
+			return err
+		}
+		status = 'T'
+		if txn.Status == proto.ABORTED {
+			status = 'E'
+		}
+	}
+	c.writeBuf.WriteByte(status)
+	if err := c.writeBuf.finishMsg(c.wr, serverMsgReady); err != nil {
+		return err
+	}
+	return c.wr.Flush()
+}
+
+// sendError sends the error to the client.
+func (c *v3Conn) sendError(err error) error {
+	return c.sendErrorWithCode(errCodeInternal, err)
+}
+
+func (c *v3Conn) sendErrorWithCode(code string, err error) error {
+	c.writeBuf.WriteByte('S')
+	c.writeBuf.writeString("ERROR")
+	c.writeBuf.WriteByte('C')
+	c.writeBuf.writeString(code)
+	c.writeBuf.WriteByte('M')
+	c.writeBuf.writeString(err.Error())
+	c.writeBuf.WriteByte(0)
+	return c.writeBuf.finishMsg(c.wr, serverMsgErrorResponse)
+}
+
+// sendExtendedError sends the error to the client after processing an
+// extended query message. The following messages are ignored until the next
+// Sync message.
+func (c *v3Conn) sendExtendedError(code string, err error) error {
+	c.ignoreTillSync = true
+	return c.sendErrorWithCode(code, err)
+}
+
+// isEmptyQuery returns true if the query contains no statement.
+func isEmptyQuery(query string) bool {
+	return strings.Trim(query, " \t\r\n;") == ""
+}
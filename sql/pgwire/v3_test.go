@@ -0,0 +1,97 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package pgwire
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/util/leaktest"
+)
+
+// TestV3InvalidCounts verifies that Parse and Bind messages holding a
+// negative count, or a count larger than the rest of the message can hold,
+// are rejected with a protocol violation.
+func TestV3InvalidCounts(t *testing.T) {
+	defer leaktest.AfterTest(t)
+
+	var parseNeg, parseLarge, bindNegFormats, bindNegParams, bindLargeParams writeBuffer
+	parseNeg.writeString("")
+	parseNeg.writeString("SELECT 1")
+	parseNeg.putInt16(-1)
+	parseLarge.writeString("")
+	parseLarge.writeString("SELECT $1")
+	parseLarge.putInt16(10000)
+	parseLarge.putInt32(0)
+	bindNegFormats.writeString("")
+	bindNegFormats.writeString("")
+	bindNegFormats.putInt16(-5)
+	bindNegParams.writeString("")
+	bindNegParams.writeString("")
+	bindNegParams.putInt16(0)
+	bindNegParams.putInt16(-32768)
+	bindLargeParams.writeString("")
+	bindLargeParams.writeString("")
+	bindLargeParams.putInt16(0)
+	bindLargeParams.putInt16(2)
+	bindLargeParams.putInt32(-1)
+
+	testCases := []struct {
+		typ clientMessageType
+		msg []byte
+	}{
+		{clientMsgParse, parseNeg.Bytes()},
+		{clientMsgParse, parseLarge.Bytes()},
+		{clientMsgBind, bindNegFormats.Bytes()},
+		{clientMsgBind, bindNegParams.Bytes()},
+		{clientMsgBind, bindLargeParams.Bytes()},
+	}
+	for i, tc := range testCases {
+		var out bytes.Buffer
+		c := &v3Conn{
+			wr:                 bufio.NewWriter(&out),
+			preparedStatements: make(map[string]*preparedStatement),
+			preparedPortals:    make(map[string]*preparedPortal),
+		}
+		c.readBuf.msg = tc.msg
+		var err error
+		switch tc.typ {
+		case clientMsgParse:
+			err = c.handleParse()
+		case clientMsgBind:
+			err = c.handleBind()
+		}
+		if err != nil {
+			t.Fatalf("%d: unexpected error: %s", i, err)
+		}
+		if err := c.wr.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		if !c.ignoreTillSync {
+			t.Errorf("%d: expected messages to be ignored until the next Sync", i)
+		}
+		resp := out.Bytes()
+		if len(resp) == 0 || serverMessageType(resp[0]) != serverMsgErrorResponse {
+			t.Errorf("%d: expected an error response, got %q", i, resp)
+		} else if !bytes.Contains(resp, []byte(errCodeProtocolViolation)) {
+			t.Errorf("%d: expected a protocol violation, got %q", i, resp)
+		}
+		if len(c.preparedStatements) != 0 || len(c.preparedPortals) != 0 {
+			t.Errorf("%d: unexpected prepared statements or portals", i)
+		}
+	}
+}
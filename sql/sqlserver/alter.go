@@ -0,0 +1,321 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package sqlserver
+
+import (
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/client"
+	"github.com/cockroachdb/cockroach/keys"
+	"github.com/cockroachdb/cockroach/proto"
+	"github.com/cockroachdb/cockroach/sql/parser"
+	"github.com/cockroachdb/cockroach/structured"
+	"github.com/cockroachdb/cockroach/util/log"
+)
+
+// AlterTable executes an ALTER TABLE statement. Every modification of the
+// table descriptor increments its version. Statements which modify the rows of
+// the table re-read the descriptor within their transaction and recompute
+// their plan if the version has changed, so rows are never written using a
+// stale schema.
+func (s *session) AlterTable(p *parser.AlterTable, args []driver.Value) (*rows, error) {
+	desc, err := s.getTableDesc(p.Name, structured.AllPrivilege)
+	if err != nil {
+		return nil, err
+	}
+
+	switch cmd := p.Cmd.(type) {
+	case *parser.AlterTableAddColumn:
+		err = addColumn(s.db, desc, cmd.Column)
+	case *parser.AlterTableDropColumn:
+		err = dropColumn(s.db, desc, cmd.Name)
+	case *parser.AlterTableRenameColumn:
+		err = updateTableDesc(s.db, desc, func(txn *client.Txn, b *client.Batch) error {
+			col, err := desc.FindColumnByName(cmd.Name)
+			if err != nil {
+				return err
+			}
+			op := fmt.Sprintf("rename column \"%s\" of", col.Name)
+			if err := checkNoDependents(txn, desc, op, nil); err != nil {
+				return err
+			}
+			col.Name = cmd.NewName
+			return nil
+		})
+	default:
+		return nil, fmt.Errorf("unsupported ALTER TABLE command: %s", cmd)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rows{}, nil
+}
+
+// RenameTable executes a RENAME TABLE statement. The table may be moved to a
+// different database, which requires ALL on that database.
+func (s *session) RenameTable(p *parser.RenameTable, args []driver.Value) (*rows, error) {
+	desc, err := s.getTableDesc(p.Name, structured.AllPrivilege)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.normalizeTableName(p.NewName); err != nil {
+		return nil, err
+	}
+	dbID, err := s.lookupDatabase(p.Name.Qualifier)
+	if err != nil {
+		return nil, err
+	}
+	newDBDesc, err := s.getDatabaseDesc(p.NewName.Qualifier)
+	if err != nil {
+		return nil, err
+	}
+	if newDBDesc.ID != dbID {
+		if err := s.checkDatabasePrivilege(newDBDesc, structured.AllPrivilege); err != nil {
+			return nil, err
+		}
+	}
+	newDBID := newDBDesc.ID
+
+	nameKey := keys.MakeNameMetadataKey(dbID, p.Name.Name)
+	newNameKey := keys.MakeNameMetadataKey(newDBID, p.NewName.Name)
+	descKey := keys.MakeDescMetadataKey(desc.ID)
+	err = updateTableDesc(s.db, desc, func(txn *client.Txn, b *client.Batch) error {
+		if err := checkNoDependents(txn, desc, "rename", nil); err != nil {
+			return err
+		}
+		desc.Name = p.NewName.String()
+		// If the new name already exists the conditional put will fail causing
+		// the transaction to fail.
+		b.CPut(newNameKey, descKey, nil)
+		b.Del(nameKey)
+		return nil
+	})
+	if err != nil {
+		if _, ok := err.(*proto.ConditionFailedError); ok {
+			return nil, fmt.Errorf("table \"%s\" already exists", p.NewName)
+		}
+		return nil, err
+	}
+	return &rows{}, nil
+}
+
+// addColumn adds the column to the table descriptor and then populates the
+// existing rows with the column's default value. The backfill is performed in
+// a series of small transactions in order to avoid blocking writers. A column
+// with a default value is added in the WRITE_ONLY state: rows written
+// concurrently with the backfill use the new descriptor and contain the
+// default value for the column, but the column is not visible to statements
+// until the backfill completes and it is made public.
+func addColumn(db *client.DB, desc *structured.TableDescriptor, def *parser.ColumnTableDef) error {
+	if def.PrimaryKey || def.Unique {
+		return fmt.Errorf("a new column cannot be a PRIMARY KEY or UNIQUE: \"%s\"", def.Name)
+	}
+	column, err := makeColumn(def)
+	if err != nil {
+		return err
+	}
+
+	var col structured.ColumnDescriptor
+	err = updateTableDesc(db, desc, func(txn *client.Txn, b *client.Batch) error {
+		col = structured.ColumnDescriptor{
+			ID:     desc.NextColumnID,
+			Column: column,
+		}
+		if col.DefaultExpr != nil {
+			col.State = structured.ColumnDescriptor_WRITE_ONLY
+		}
+		desc.NextColumnID++
+		desc.Columns = append(desc.Columns, col)
+
+		if !col.Nullable && col.DefaultExpr == nil {
+			// The column can only be added if the table is empty.
+			prefix := proto.Key(encodeIndexKeyPrefix(desc.ID, desc.Indexes[0].ID))
+			kvs, err := txn.Scan(prefix, prefix.PrefixEnd(), 1)
+			if err != nil {
+				return err
+			}
+			if len(kvs) > 0 {
+				return fmt.Errorf("column \"%s\" contains null values", col.Name)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if col.DefaultExpr == nil {
+		return nil
+	}
+	if err := backfillColumn(db, desc, col); err != nil {
+		// Remove the partially populated column.
+		dropErr := removeColumn(db, desc, func(txn *client.Txn) (*structured.ColumnDescriptor, error) {
+			return desc.FindColumnByID(col.ID)
+		})
+		if dropErr != nil {
+			log.Warningf("unable to remove column \"%s\": %s", col.Name, dropErr)
+		}
+		return err
+	}
+	return updateTableDesc(db, desc, func(txn *client.Txn, b *client.Batch) error {
+		c, err := desc.FindColumnByID(col.ID)
+		if err != nil {
+			return err
+		}
+		c.State = structured.ColumnDescriptor_PUBLIC
+		return nil
+	})
+}
+
+// backfillColumn writes the default value of the column to the existing rows
+// which do not contain a value for the column. The column is write-only, so
+// every row written since it was added contains its default value and a row
+// without a value has not been written since.
+func backfillColumn(db *client.DB, desc *structured.TableDescriptor,
+	col structured.ColumnDescriptor) error {
+	// A column whose default is a unique row ID is assigned a new ID for every
+	// row instead of a constant value.
+	rowIDs := hasUniqueRowIDDefault(col)
+	var val driver.Value
+	if !rowIDs {
+		var err error
+		if val, err = evalDefaultExpr(col); err != nil || val == nil {
+			return err
+		}
+	}
+	return forEachRowBatch(db, desc, func(txn *client.Txn, tableRows []tableRow) error {
+		i, ok := columnIndexMap(desc)[col.ID]
+		if !ok {
+			return fmt.Errorf("column \"%s\" was dropped", col.Name)
+		}
+		var id int64
+		if rowIDs {
+			n := 0
+			for _, row := range tableRows {
+				if row.vals[i] == nil {
+					n++
+				}
+			}
+			if n == 0 {
+				return nil
+			}
+			var err error
+			if id, err = allocateRowIDs(db, n); err != nil {
+				return err
+			}
+		}
+		b := &client.Batch{}
+		for _, row := range tableRows {
+			if row.vals[i] != nil {
+				continue
+			}
+			if rowIDs {
+				val = id
+				id++
+			}
+			key := encodeColumnKey(col, row.key)
+			if log.V(2) {
+				log.Infof("Put %q -> %v", key, val)
+			}
+			b.Put(key, marshalColumnValue(val))
+		}
+		return txn.Commit(b)
+	})
+}
+
+// fillWriteOnlyColumns sets the write-only columns of a row which do not
+// contain a value to their default values, so that a row rewritten while a
+// column is being backfilled contains a value for it. The unique row IDs of
+// SERIAL columns are allocated using allocIDs.
+func fillWriteOnlyColumns(desc *structured.TableDescriptor, vals []driver.Value,
+	allocIDs func(n int) (int64, error)) error {
+	for i, col := range desc.Columns {
+		if col.IsPublic() || vals[i] != nil {
+			continue
+		}
+		var err error
+		if hasUniqueRowIDDefault(col) {
+			vals[i], err = allocIDs(1)
+		} else {
+			vals[i], err = evalDefaultExpr(col)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropColumn removes the public column with the specified name from the
+// table. See removeColumn. A column cannot be dropped while views depend on
+// the table.
+func dropColumn(db *client.DB, desc *structured.TableDescriptor, name string) error {
+	return removeColumn(db, desc, func(txn *client.Txn) (*structured.ColumnDescriptor, error) {
+		col, err := desc.FindColumnByName(name)
+		if err != nil {
+			return nil, err
+		}
+		op := fmt.Sprintf("drop column \"%s\" of", col.Name)
+		if err := checkNoDependents(txn, desc, op, nil); err != nil {
+			return nil, err
+		}
+		return col, nil
+	})
+}
+
+// removeColumn removes the column returned by find from the table descriptor
+// and then deletes the column's values from every row. Rows are decoded using
+// the columns of the descriptor, so the values of the column are ignored once
+// the descriptor has been updated.
+func removeColumn(db *client.DB, desc *structured.TableDescriptor,
+	find func(txn *client.Txn) (*structured.ColumnDescriptor, error)) error {
+	var col structured.ColumnDescriptor
+	err := updateTableDesc(db, desc, func(txn *client.Txn, b *client.Batch) error {
+		c, err := find(txn)
+		if err != nil {
+			return err
+		}
+		col = *c
+		for _, index := range desc.Indexes {
+			if index.ContainsColumnID(col.ID) {
+				return fmt.Errorf("column \"%s\" is referenced by index \"%s\"", col.Name, index.Name)
+			}
+		}
+		for i := range desc.Columns {
+			if desc.Columns[i].ID == col.ID {
+				desc.Columns = append(desc.Columns[:i], desc.Columns[i+1:]...)
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return forEachRowBatch(db, desc, func(txn *client.Txn, tableRows []tableRow) error {
+		b := &client.Batch{}
+		for _, row := range tableRows {
+			key := encodeColumnKey(col, row.key)
+			if log.V(2) {
+				log.Infof("Del %q", key)
+			}
+			b.Del(key)
+		}
+		return txn.Commit(b)
+	})
+}
@@ -0,0 +1,99 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package sqlserver
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/cockroach/proto"
+	"github.com/cockroachdb/cockroach/sql/parser"
+)
+
+var (
+	errAsOfInTxn    = errors.New("AS OF SYSTEM TIME cannot be used within a transaction")
+	errAsOfSubquery = errors.New("AS OF SYSTEM TIME cannot be used in a subquery")
+	errAsOfView     = errors.New("AS OF SYSTEM TIME cannot be used in a view")
+	errAsOfUnion    = errors.New("all of the SELECT statements of a UNION must specify the same AS OF SYSTEM TIME")
+)
+
+// evalAsOf returns the timestamp specified by the AS OF SYSTEM TIME clauses of
+// the statement, or nil if the statement reads the current values. A UNION
+// is read at a single timestamp, so either none or all of its SELECT
+// statements must specify the same timestamp. A time without a time zone is in
+// the location.
+func evalAsOf(p parser.SelectStatement, args []driver.Value, loc *time.Location) (*proto.Timestamp, error) {
+	switch t := p.(type) {
+	case *parser.Select:
+		if t.AsOf == nil {
+			return nil, nil
+		}
+		return evalAsOfExpr(t.AsOf.Expr, args, loc)
+	case *parser.Union:
+		left, err := evalAsOf(t.Left, args, loc)
+		if err != nil {
+			return nil, err
+		}
+		right, err := evalAsOf(t.Right, args, loc)
+		if err != nil {
+			return nil, err
+		}
+		if (left == nil) != (right == nil) || (left != nil && !left.Equal(*right)) {
+			return nil, errAsOfUnion
+		}
+		return left, nil
+	}
+	return nil, nil
+}
+
+// evalAsOfExpr evaluates the timestamp of an AS OF SYSTEM TIME clause, which
+// is either a time or an integer number of nanoseconds since the Unix epoch.
+// The timestamp must be in the past.
+func evalAsOfExpr(e parser.ValExpr, args []driver.Value, loc *time.Location) (*proto.Timestamp, error) {
+	v, err := evalConstExpr(e, args, loc)
+	if err != nil {
+		return nil, err
+	}
+	var wallTime int64
+	if i, ok := v.(int64); ok {
+		wallTime = i
+	} else if t, ok := toTimeIn(v, loc); ok {
+		wallTime = t.UnixNano()
+	} else {
+		return nil, fmt.Errorf("invalid AS OF SYSTEM TIME: %v", v)
+	}
+	if wallTime <= 0 {
+		return nil, fmt.Errorf("invalid AS OF SYSTEM TIME: %v", v)
+	}
+	if wallTime > time.Now().UnixNano() {
+		return nil, fmt.Errorf("AS OF SYSTEM TIME %v is in the future", v)
+	}
+	return &proto.Timestamp{WallTime: wallTime}, nil
+}
+
+// hasAsOf returns true if any of the SELECT statements of the statement has
+// an AS OF SYSTEM TIME clause.
+func hasAsOf(p parser.SelectStatement) bool {
+	switch t := p.(type) {
+	case *parser.Select:
+		return t.AsOf != nil
+	case *parser.Union:
+		return hasAsOf(t.Left) || hasAsOf(t.Right)
+	}
+	return false
+}
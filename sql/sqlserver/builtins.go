@@ -0,0 +1,625 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package sqlserver
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cockroachdb/cockroach/sql/parser"
+	"github.com/cockroachdb/cockroach/util/decimal"
+)
+
+// builtin is a function which can be called by any expression, such as the
+// output columns of a SELECT statement, the assignments of an UPDATE
+// statement and WHERE clauses.
+type builtin struct {
+	// The types of the arguments. The last argument of a variadic function may
+	// be repeated any number of times, and the last optional arguments may be
+	// omitted.
+	args     []exprType
+	optional int
+	variadic bool
+	// The type of the result. typeNumeric indicates a decimal if any of the
+	// arguments is a decimal, a float if any of the arguments is a float and
+	// an int otherwise, and typeAny indicates the
+	// common type of the arguments (see commonType).
+	ret exprType
+	// Set if the function is called with NULL arguments. Otherwise the result
+	// is NULL if any of the arguments is NULL.
+	nullable bool
+	// The arguments passed to fn have been converted to the types of the
+	// signature: a float argument is a float64, a string argument is a string
+	// and so on.
+	fn func(args []driver.Value) (driver.Value, error)
+}
+
+// maxRepeatLength is the largest number of bytes of the result of REPEAT.
+const maxRepeatLength = 16 << 20
+
+// builtins are the builtin functions. The parser upper cases the names of
+// functions.
+var builtins = map[string]builtin{
+	// String functions.
+	"LENGTH": {
+		args: []exprType{typeString},
+		ret:  typeInt,
+		fn: func(args []driver.Value) (driver.Value, error) {
+			return int64(utf8.RuneCountInString(args[0].(string))), nil
+		},
+	},
+	"LOWER": stringBuiltin(strings.ToLower),
+	"UPPER": stringBuiltin(strings.ToUpper),
+	"TRIM": stringBuiltin(func(s string) string {
+		return strings.Trim(s, " ")
+	}),
+	"LTRIM": stringBuiltin(func(s string) string {
+		return strings.TrimLeft(s, " ")
+	}),
+	"RTRIM": stringBuiltin(func(s string) string {
+		return strings.TrimRight(s, " ")
+	}),
+	"REVERSE": stringBuiltin(func(s string) string {
+		runes := []rune(s)
+		for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+			runes[i], runes[j] = runes[j], runes[i]
+		}
+		return string(runes)
+	}),
+	"CONCAT": {
+		args:     []exprType{typeAny},
+		variadic: true,
+		ret:      typeString,
+		fn: func(args []driver.Value) (driver.Value, error) {
+			var parts []string
+			for _, v := range args {
+				parts = append(parts, formatValue(v))
+			}
+			return strings.Join(parts, ""), nil
+		},
+	},
+	"SUBSTR":    substringBuiltin,
+	"SUBSTRING": substringBuiltin,
+	"REPLACE": {
+		args: []exprType{typeString, typeString, typeString},
+		ret:  typeString,
+		fn: func(args []driver.Value) (driver.Value, error) {
+			return strings.Replace(args[0].(string), args[1].(string), args[2].(string), -1), nil
+		},
+	},
+	"REPEAT": {
+		args: []exprType{typeString, typeInt},
+		ret:  typeString,
+		fn: func(args []driver.Value) (driver.Value, error) {
+			s := args[0].(string)
+			n := args[1].(int64)
+			if n <= 0 || s == "" {
+				return "", nil
+			}
+			// Dividing rather than multiplying avoids overflowing.
+			if n > maxRepeatLength/int64(len(s)) {
+				return nil, fmt.Errorf("REPEAT result exceeds %d bytes", maxRepeatLength)
+			}
+			return strings.Repeat(s, int(n)), nil
+		},
+	},
+
+	// Math functions.
+	"ABS": {
+		args: []exprType{typeNumeric},
+		ret:  typeNumeric,
+		fn: func(args []driver.Value) (driver.Value, error) {
+			switch t := args[0].(type) {
+			case int64:
+				if t == math.MinInt64 {
+					return nil, fmt.Errorf("integer out of range: ABS(%d)", t)
+				}
+				if t < 0 {
+					return -t, nil
+				}
+				return t, nil
+			case float64:
+				return math.Abs(t), nil
+			case *decimal.Decimal:
+				return t.Abs(), nil
+			}
+			return nil, fmt.Errorf("numeric argument required: %T", args[0])
+		},
+	},
+	"CEIL":    numericBuiltin(math.Ceil, (*decimal.Decimal).Ceil),
+	"CEILING": numericBuiltin(math.Ceil, (*decimal.Decimal).Ceil),
+	"FLOOR":   numericBuiltin(math.Floor, (*decimal.Decimal).Floor),
+	"ROUND": {
+		args:     []exprType{typeNumeric, typeInt},
+		optional: 1,
+		ret:      typeNumeric,
+		fn: func(args []driver.Value) (driver.Value, error) {
+			var digits int64
+			if len(args) > 1 {
+				digits = args[1].(int64)
+			}
+			switch t := args[0].(type) {
+			case int64:
+				if digits >= 0 {
+					return t, nil
+				}
+				return int64(round(float64(t), digits)), nil
+			case float64:
+				return round(t, digits), nil
+			case *decimal.Decimal:
+				if digits > decimal.MaxScale {
+					digits = decimal.MaxScale
+				} else if digits < -2*decimal.MaxIntDigits {
+					digits = -2 * decimal.MaxIntDigits
+				}
+				return t.Round(int32(digits)), nil
+			}
+			return nil, fmt.Errorf("ROUND requires a numeric argument: %T", args[0])
+		},
+	},
+	"SIGN": {
+		args: []exprType{typeNumeric},
+		ret:  typeInt,
+		fn: func(args []driver.Value) (driver.Value, error) {
+			switch t := args[0].(type) {
+			case int64:
+				return int64(compareInts(t, 0)), nil
+			case float64:
+				return int64(compareFloats(t, 0)), nil
+			case *decimal.Decimal:
+				return int64(t.Sign()), nil
+			}
+			return nil, fmt.Errorf("SIGN requires a numeric argument: %T", args[0])
+		},
+	},
+	"MOD": {
+		args: []exprType{typeNumeric, typeNumeric},
+		ret:  typeNumeric,
+		fn: func(args []driver.Value) (driver.Value, error) {
+			return evalBinaryOp('%', args[0], args[1])
+		},
+	},
+	"SQRT": floatBuiltin(func(f float64) (float64, error) {
+		if f < 0 {
+			return 0, fmt.Errorf("cannot take square root of a negative number")
+		}
+		return math.Sqrt(f), nil
+	}),
+	"EXP": floatBuiltin(func(f float64) (float64, error) {
+		return math.Exp(f), nil
+	}),
+	"LN": floatBuiltin(func(f float64) (float64, error) {
+		if f <= 0 {
+			return 0, fmt.Errorf("cannot take logarithm of a non-positive number")
+		}
+		return math.Log(f), nil
+	}),
+	"POW":   powBuiltin,
+	"POWER": powBuiltin,
+	"PI": {
+		ret: typeFloat,
+		fn: func(args []driver.Value) (driver.Value, error) {
+			return math.Pi, nil
+		},
+	},
+
+	// Comparison and NULL functions.
+	"COALESCE": {
+		args:     []exprType{typeAny},
+		variadic: true,
+		ret:      typeAny,
+		nullable: true,
+		fn: func(args []driver.Value) (driver.Value, error) {
+			for _, v := range args {
+				if v != nil {
+					return v, nil
+				}
+			}
+			return nil, nil
+		},
+	},
+	"IFNULL": {
+		args:     []exprType{typeAny, typeAny},
+		ret:      typeAny,
+		nullable: true,
+		fn: func(args []driver.Value) (driver.Value, error) {
+			if args[0] != nil {
+				return args[0], nil
+			}
+			return args[1], nil
+		},
+	},
+	"NULLIF": {
+		args:     []exprType{typeAny, typeAny},
+		ret:      typeAny,
+		nullable: true,
+		fn: func(args []driver.Value) (driver.Value, error) {
+			if args[0] == nil || args[1] == nil {
+				return args[0], nil
+			}
+			c, err := compareValues(args[0], args[1])
+			if err != nil {
+				return nil, err
+			}
+			if c == 0 {
+				return nil, nil
+			}
+			return args[0], nil
+		},
+	},
+	"GREATEST": extremumBuiltin(1),
+	"LEAST":    extremumBuiltin(-1),
+
+	// Date and time functions.
+	"NOW": {
+		ret: typeTime,
+		fn: func(args []driver.Value) (driver.Value, error) {
+			return time.Now().UTC(), nil
+		},
+	},
+	"YEAR": timeBuiltin(func(t time.Time) int64 {
+		return int64(t.Year())
+	}),
+	"MONTH": timeBuiltin(func(t time.Time) int64 {
+		return int64(t.Month())
+	}),
+	"DAY": timeBuiltin(func(t time.Time) int64 {
+		return int64(t.Day())
+	}),
+	"HOUR": timeBuiltin(func(t time.Time) int64 {
+		return int64(t.Hour())
+	}),
+	"MINUTE": timeBuiltin(func(t time.Time) int64 {
+		return int64(t.Minute())
+	}),
+	"SECOND": timeBuiltin(func(t time.Time) int64 {
+		return int64(t.Second())
+	}),
+}
+
+var substringBuiltin = builtin{
+	args:     []exprType{typeString, typeInt, typeInt},
+	optional: 1,
+	ret:      typeString,
+	fn: func(args []driver.Value) (driver.Value, error) {
+		// The positions of the characters start at 1. Positions before the
+		// start of the string count towards the length of the substring,
+		// which holds the characters at the positions [start, end).
+		runes := []rune(args[0].(string))
+		start := args[1].(int64)
+		end := int64(len(runes)) + 1
+		if len(args) > 2 {
+			n := args[2].(int64)
+			if n < 0 {
+				return nil, fmt.Errorf("negative substring length not allowed: %d", n)
+			}
+			// start+n may overflow, unlike end-n.
+			if start < end-n {
+				end = start + n
+			}
+		}
+		if start < 1 {
+			start = 1
+		}
+		if start >= end {
+			return "", nil
+		}
+		return string(runes[start-1 : end-1]), nil
+	},
+}
+
+var powBuiltin = builtin{
+	args: []exprType{typeFloat, typeFloat},
+	ret:  typeFloat,
+	fn: func(args []driver.Value) (driver.Value, error) {
+		return math.Pow(args[0].(float64), args[1].(float64)), nil
+	},
+}
+
+// stringBuiltin returns a builtin function of a single string argument
+// returning a string.
+func stringBuiltin(f func(string) string) builtin {
+	return builtin{
+		args: []exprType{typeString},
+		ret:  typeString,
+		fn: func(args []driver.Value) (driver.Value, error) {
+			return f(args[0].(string)), nil
+		},
+	}
+}
+
+// numericBuiltin returns a builtin function of a single numeric argument
+// returning a number of the same type. An integer is returned unchanged.
+func numericBuiltin(ff func(float64) float64, fd func(*decimal.Decimal) *decimal.Decimal) builtin {
+	return builtin{
+		args: []exprType{typeNumeric},
+		ret:  typeNumeric,
+		fn: func(args []driver.Value) (driver.Value, error) {
+			switch t := args[0].(type) {
+			case int64:
+				return t, nil
+			case float64:
+				return ff(t), nil
+			case *decimal.Decimal:
+				return fd(t), nil
+			}
+			return nil, fmt.Errorf("numeric argument required: %T", args[0])
+		},
+	}
+}
+
+// floatBuiltin returns a builtin function of a single float argument
+// returning a float.
+func floatBuiltin(f func(float64) (float64, error)) builtin {
+	return builtin{
+		args: []exprType{typeFloat},
+		ret:  typeFloat,
+		fn: func(args []driver.Value) (driver.Value, error) {
+			return f(args[0].(float64))
+		},
+	}
+}
+
+// timeBuiltin returns a builtin function extracting a field of a date or
+// time in the time zone of the session, in which the argument is passed (see
+// convertArg).
+func timeBuiltin(f func(time.Time) int64) builtin {
+	return builtin{
+		args: []exprType{typeTime},
+		ret:  typeInt,
+		fn: func(args []driver.Value) (driver.Value, error) {
+			return f(args[0].(time.Time)), nil
+		},
+	}
+}
+
+// extremumBuiltin returns a builtin function returning the greatest (dir > 0)
+// or the least (dir < 0) of its arguments.
+func extremumBuiltin(dir int) builtin {
+	return builtin{
+		args:     []exprType{typeAny},
+		variadic: true,
+		ret:      typeAny,
+		fn: func(args []driver.Value) (driver.Value, error) {
+			result := args[0]
+			for _, v := range args[1:] {
+				c, err := compareValues(v, result)
+				if err != nil {
+					return nil, err
+				}
+				if c*dir > 0 {
+					result = v
+				}
+			}
+			return result, nil
+		},
+	}
+}
+
+// round rounds f to the number of decimal digits, rounding halfway values
+// away from zero. A negative number of digits rounds to the left of the
+// decimal point.
+func round(f float64, digits int64) float64 {
+	p := math.Pow(10, float64(digits))
+	return math.Trunc(f*p+math.Copysign(0.5, f)) / p
+}
+
+// formatValue formats a non-NULL value as a string.
+func formatValue(v driver.Value) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case []byte:
+		return string(t)
+	case int64:
+		return strconv.FormatInt(t, 10)
+	case float64:
+		return strconv.FormatFloat(t, 'g', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// checkArgCount checks the number of arguments of a call of the function.
+func (b builtin) checkArgCount(f *parser.FuncExpr) error {
+	n := len(f.Exprs)
+	min, max := len(b.args)-b.optional, len(b.args)
+	if b.variadic {
+		max = -1
+	}
+	if n < min || (max >= 0 && n > max) {
+		return fmt.Errorf("wrong number of arguments to %s: %s", f.Name, f)
+	}
+	return nil
+}
+
+// argType returns the type of the i-th argument.
+func (b builtin) argType(i int) exprType {
+	if i >= len(b.args) {
+		return b.args[len(b.args)-1]
+	}
+	return b.args[i]
+}
+
+// resultType returns the type of the result of a call with arguments of the
+// types.
+func (b builtin) resultType(argTypes []exprType) (exprType, bool) {
+	switch b.ret {
+	case typeNumeric:
+		result := typeInt
+		for i, t := range argTypes {
+			if b.argType(i) != typeNumeric {
+				continue
+			}
+			switch t {
+			case typeAny:
+				return typeAny, true
+			case typeDecimal:
+				result = typeDecimal
+			case typeFloat:
+				if result != typeDecimal {
+					result = typeFloat
+				}
+			}
+		}
+		return result, true
+	case typeAny:
+		result := typeAny
+		for _, t := range argTypes {
+			var ok bool
+			if result, ok = commonType(result, t); !ok {
+				return typeAny, false
+			}
+		}
+		return result, true
+	}
+	return b.ret, true
+}
+
+// assignable returns true if a value of type t can be passed as an argument
+// of type want (see convertArg).
+func assignable(want, t exprType) bool {
+	switch {
+	case want == typeAny || t == typeAny || want == t:
+		return true
+	case want == typeNumeric || want == typeFloat:
+		return t.isNumeric()
+	case want == typeString || want == typeBytes:
+		return t == typeString || t == typeBytes
+	case want == typeTime:
+		return t == typeTime || t == typeString
+	}
+	return false
+}
+
+// convertArg converts a non-NULL argument to the type of the signature. A time
+// is converted to the location, in which a string without a time zone is
+// interpreted.
+func convertArg(want exprType, v driver.Value, loc *time.Location) (driver.Value, bool) {
+	switch want {
+	case typeAny:
+		return v, true
+	case typeNumeric:
+		return v, valueType(v).isNumeric()
+	case typeFloat:
+		f, ok := toFloat(v)
+		return f, ok
+	case typeTime:
+		t, ok := toTimeIn(v, loc)
+		return t.In(loc), ok
+	case typeString:
+		switch t := v.(type) {
+		case string:
+			return t, true
+		case []byte:
+			return string(t), true
+		}
+		return nil, false
+	case typeBytes:
+		switch t := v.(type) {
+		case []byte:
+			return t, true
+		case string:
+			return []byte(t), true
+		}
+		return nil, false
+	}
+	return v, valueType(v) == want
+}
+
+// funcArgs returns the arguments of a function call.
+func funcArgs(f *parser.FuncExpr) ([]parser.Expr, error) {
+	if f.Distinct {
+		return nil, fmt.Errorf("DISTINCT specified, but %s is not an aggregate function", f.Name)
+	}
+	exprs := make([]parser.Expr, len(f.Exprs))
+	for i, arg := range f.Exprs {
+		nse, ok := arg.(*parser.NonStarExpr)
+		if !ok {
+			return nil, fmt.Errorf("invalid argument: %s", f)
+		}
+		exprs[i] = nse.Expr
+	}
+	return exprs, nil
+}
+
+// lookupBuiltin returns the builtin function called by the function call,
+// checking the number of arguments.
+func lookupBuiltin(f *parser.FuncExpr) (builtin, []parser.Expr, error) {
+	b, ok := builtins[f.Name]
+	if !ok {
+		return builtin{}, nil, fmt.Errorf("unknown function: %s", f.Name)
+	}
+	exprs, err := funcArgs(f)
+	if err != nil {
+		return builtin{}, nil, err
+	}
+	return b, exprs, b.checkArgCount(f)
+}
+
+// evalFuncExpr evaluates a call of a builtin function.
+func evalFuncExpr(f *parser.FuncExpr, env env, args []driver.Value, loc *time.Location) (driver.Value, error) {
+	b, exprs, err := lookupBuiltin(f)
+	if err != nil {
+		return nil, err
+	}
+	vals := make([]driver.Value, len(exprs))
+	for i, e := range exprs {
+		v, err := evalExpr(e, env, args, loc)
+		if err != nil {
+			return nil, err
+		}
+		if v == nil {
+			if !b.nullable {
+				return nil, nil
+			}
+			continue
+		}
+		var ok bool
+		if vals[i], ok = convertArg(b.argType(i), v, loc); !ok {
+			return nil, fmt.Errorf("argument %d of %s must be of type %s, but found %T",
+				i+1, f.Name, b.argType(i), v)
+		}
+	}
+	return b.fn(vals)
+}
+
+// typeCheckFuncExpr determines the type of a call of a builtin function.
+func typeCheckFuncExpr(f *parser.FuncExpr, tables joinEnv, args []driver.Value) (exprType, error) {
+	b, exprs, err := lookupBuiltin(f)
+	if err != nil {
+		return typeAny, err
+	}
+	argTypes := make([]exprType, len(exprs))
+	for i, e := range exprs {
+		if argTypes[i], err = typeCheckExpr(e, tables, args); err != nil {
+			return typeAny, err
+		}
+		if !assignable(b.argType(i), argTypes[i]) {
+			return typeAny, fmt.Errorf("argument %d of %s must be of type %s, but found %s",
+				i+1, f.Name, b.argType(i), argTypes[i])
+		}
+	}
+	t, ok := b.resultType(argTypes)
+	if !ok {
+		return typeAny, fmt.Errorf("argument types of %s cannot be matched: %s", f.Name, f)
+	}
+	return t, nil
+}
@@ -0,0 +1,448 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package sqlserver
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/client"
+	"github.com/cockroachdb/cockroach/keys"
+	"github.com/cockroachdb/cockroach/proto"
+	"github.com/cockroachdb/cockroach/security"
+	"github.com/cockroachdb/cockroach/sql/parser"
+	"github.com/cockroachdb/cockroach/structured"
+	"github.com/cockroachdb/cockroach/util/decimal"
+)
+
+// The rows of a table are imported from and exported to CSV files whose first
+// line holds the names of the columns. NULL is written as the field \N, so
+// that it is distinguished from an empty string; a string of the form \N,
+// \\N, ... is written with an additional leading backslash. An empty field of
+// a column which is not a string is imported as NULL as well. Times are
+// written in RFC 3339 format, dates as YYYY-MM-DD and times of day as
+// HH:MM:SS.
+
+// ImportBatchSize is the number of rows of a CSV file which are read and then
+// written before the next rows are read. The rows of a batch are written using
+// a transaction per range.
+// This is exported for testing purposes only.
+var ImportBatchSize = 1000
+
+// exportBatchSize is the number of rows read by each scan of a table being
+// exported.
+const exportBatchSize = 1000
+
+// CSVNull is the field of a CSV row which holds NULL.
+const CSVNull = `\N`
+
+// rangeLookupBatchSize is the number of range descriptors read by each scan of
+// the range metadata.
+const rangeLookupBatchSize = 100
+
+// Import executes an IMPORT TABLE statement. The file is read from the file
+// system of the node executing the statement, which only the root user is
+// allowed to do.
+func (s *session) Import(p *parser.Import, args []driver.Value) (*rows, error) {
+	if !isSuperuser(s.user) {
+		return nil, fmt.Errorf("user %s is not allowed to import from a file", s.user)
+	}
+	desc, err := s.getTableDesc(p.Table, structured.InsertPrivilege)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	count, err := importCSV(s.db, desc, f)
+	if err != nil {
+		return nil, err
+	}
+	return &rows{rowsAffected: count}, nil
+}
+
+// ImportCSV imports the rows of the CSV data into the table named
+// "database.table", returning the number of rows imported. The rows are
+// written as the root user. The rows of the batches written before an error
+// is encountered are not removed. The privileges on the table are not
+// checked, so db must be a client of the root user and the caller must only
+// import on behalf of the root user.
+func ImportCSV(db *client.DB, table string, r io.Reader) (int, error) {
+	name, err := parseQualifiedTableName(table)
+	if err != nil {
+		return 0, err
+	}
+	s := &session{db: db, user: security.RootUser}
+	desc, err := s.getTableDesc(name, structured.InsertPrivilege)
+	if err != nil {
+		return 0, err
+	}
+	return importCSV(db, desc, r)
+}
+
+// ExportCSV writes the rows of the table named "database.table" to w as CSV,
+// returning the number of rows exported. The rows are read within a single
+// transaction as the root user, so that they are a consistent snapshot of the
+// table at the timestamp of the transaction. The rows are written as they are
+// read. As with ImportCSV, the privileges on the table are not checked.
+func ExportCSV(db *client.DB, table string, w io.Writer) (int, error) {
+	name, err := parseQualifiedTableName(table)
+	if err != nil {
+		return 0, err
+	}
+	if isInformationSchema(name.Qualifier) {
+		return 0, fmt.Errorf("cannot export virtual table \"%s\"", name)
+	}
+	s := &session{db: db, user: security.RootUser}
+	desc, err := s.readTableDesc(name)
+	if err != nil {
+		return 0, err
+	}
+	if desc.IsView() {
+		return 0, fmt.Errorf("cannot export view \"%s\"", name)
+	}
+	return exportCSV(db, desc, w)
+}
+
+// parseQualifiedTableName parses a table name of the form "database.table".
+func parseQualifiedTableName(table string) (*parser.TableName, error) {
+	parts := strings.Split(table, ".")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("invalid table name \"%s\": expected <database>.<table>", table)
+	}
+	return &parser.TableName{
+		Qualifier: strings.ToLower(parts[0]),
+		Name:      strings.ToLower(parts[1]),
+	}, nil
+}
+
+// importCSV imports the rows of the CSV data into the table. The rows are
+// read in batches of ImportBatchSize rows, which are converted to the column
+// types and completed with the default values exactly as for INSERT. The rows
+// of a batch are then grouped by the range holding their primary key and the
+// rows of each group are written, along with their index entries, using a
+// transaction of their own.
+func importCSV(db *client.DB, desc *structured.TableDescriptor, r io.Reader) (int, error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err == io.EOF {
+		return 0, fmt.Errorf("missing CSV header")
+	} else if err != nil {
+		return 0, err
+	}
+	var columns parser.Columns
+	for _, name := range header {
+		columns = append(columns, &parser.NonStarExpr{Expr: &parser.ColName{Name: name}})
+	}
+	cols, err := processColumns(desc, columns)
+	if err != nil {
+		return 0, err
+	}
+
+	prefix := proto.Key(encodeIndexKeyPrefix(desc.ID, desc.Indexes[0].ID))
+	splits, err := rangeSplitKeys(db, prefix, prefix.PrefixEnd())
+	if err != nil {
+		return 0, err
+	}
+	allocIDs := func(n int) (int64, error) {
+		return allocateRowIDs(db, n)
+	}
+
+	count := 0
+	for {
+		values, err := readCSVRows(cr, cols, count+1)
+		if err != nil {
+			return count, err
+		}
+		if len(values) == 0 {
+			return count, nil
+		}
+		tableRows, err := makeInsertRows(desc, columns, values, allocIDs)
+		if err != nil {
+			return count, fmt.Errorf("rows %d-%d: %s", count+1, count+len(values), err)
+		}
+		if err := writeRowsByRange(db, desc, splits, tableRows); err != nil {
+			return count, err
+		}
+		count += len(tableRows)
+	}
+}
+
+// readCSVRows reads the next ImportBatchSize rows of the CSV data, converting
+// the fields to values of the types of the columns. The rows are numbered
+// from first in errors.
+func readCSVRows(cr *csv.Reader, cols []structured.ColumnDescriptor, first int) ([]row, error) {
+	var values []row
+	for len(values) < ImportBatchSize {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if len(record) != len(cols) {
+			return nil, fmt.Errorf("row %d: expected %d values, but found %d",
+				first+len(values), len(cols), len(record))
+		}
+		vals := make(row, len(record))
+		for i, field := range record {
+			if vals[i], err = parseCSVValue(cols[i], field); err != nil {
+				return nil, fmt.Errorf("row %d: %s", first+len(values), err)
+			}
+		}
+		values = append(values, vals)
+	}
+	return values, nil
+}
+
+// parseCSVValue converts a field of a CSV row to a value which is checked
+// against the type of the column by checkColumnValue. Numbers are parsed;
+// the other values are converted from strings by checkColumnValue.
+func parseCSVValue(col structured.ColumnDescriptor, field string) (driver.Value, error) {
+	if field == CSVNull {
+		return nil, nil
+	}
+	if isStringColumn(col) {
+		if isCSVNullForm(field) {
+			return field[1:], nil
+		}
+		return field, nil
+	}
+	if field == "" {
+		return nil, nil
+	}
+	switch col.Type.Kind {
+	case structured.ColumnType_BIT, structured.ColumnType_INT:
+		i, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("value %q is not valid for column \"%s\" of type %s",
+				field, col.Name, col.Type.SQLString())
+		}
+		return i, nil
+	case structured.ColumnType_FLOAT:
+		f, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, fmt.Errorf("value %q is not valid for column \"%s\" of type %s",
+				field, col.Name, col.Type.SQLString())
+		}
+		return f, nil
+	case structured.ColumnType_DECIMAL:
+		d, err := decimal.Parse(field)
+		if err != nil {
+			return nil, fmt.Errorf("value %q is not valid for column \"%s\" of type %s",
+				field, col.Name, col.Type.SQLString())
+		}
+		return d, nil
+	}
+	return field, nil
+}
+
+// rangeSplitKeys returns the start keys of the ranges holding the span
+// [start, end), except for the first range, in increasing order. They are
+// read from the range metadata and only used to group writes by range, so
+// that it does not matter if the ranges are split or merged concurrently.
+func rangeSplitKeys(db *client.DB, start, end proto.Key) ([]proto.Key, error) {
+	// The range metadata is keyed by the end key of each range. The ranges
+	// holding the span are those ending after its start, up to and including
+	// the first range ending at or after its end.
+	var splits []proto.Key
+	metaStart := keys.RangeMetaKey(start.Next())
+	metaEnd := keys.Meta2Prefix.PrefixEnd()
+	for {
+		kvs, err := db.Scan(metaStart, metaEnd, rangeLookupBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, kv := range kvs {
+			var rangeDesc proto.RangeDescriptor
+			if err := kv.ValueProto(&rangeDesc); err != nil {
+				return nil, err
+			}
+			if !rangeDesc.EndKey.Less(end) {
+				return splits, nil
+			}
+			splits = append(splits, rangeDesc.EndKey)
+		}
+		if len(kvs) < rangeLookupBatchSize {
+			return splits, nil
+		}
+		metaStart = proto.Key(kvs[len(kvs)-1].Key).Next()
+	}
+}
+
+// writeRowsByRange writes the rows using a transaction for each of the ranges
+// delimited by the split keys which holds the primary key of any of the rows.
+// The import fails if the schema of the table has changed since the rows were
+// made.
+func writeRowsByRange(db *client.DB, desc *structured.TableDescriptor, splits []proto.Key,
+	tableRows [][]driver.Value) error {
+	colMap := columnIndexMap(desc)
+	primaryIndex := desc.Indexes[0]
+	prefix := encodeIndexKeyPrefix(desc.ID, primaryIndex.ID)
+	groups := make([][][]driver.Value, len(splits)+1)
+	for _, vals := range tableRows {
+		key, err := encodeIndexKey(primaryIndex, colMap, vals, prefix)
+		if err != nil {
+			return err
+		}
+		i := sort.Search(len(splits), func(i int) bool {
+			return bytes.Compare(key, splits[i]) < 0
+		})
+		groups[i] = append(groups[i], vals)
+	}
+
+	version := desc.Version
+	for _, group := range groups {
+		if len(group) == 0 {
+			continue
+		}
+		err := db.Txn(func(txn *client.Txn) error {
+			if err := refreshTableDesc(txn, desc); err != nil {
+				return err
+			}
+			if desc.Version != version {
+				return fmt.Errorf("table \"%s\" was modified during the import", desc.Name)
+			}
+			b := &client.Batch{}
+			for _, vals := range group {
+				if err := insertRow(b, desc, vals); err != nil {
+					return err
+				}
+			}
+			return txn.Commit(b)
+		})
+		if err != nil {
+			return convertBatchError(desc, err)
+		}
+	}
+	return nil
+}
+
+// exportCSV writes the rows of the table to w as CSV. The table is scanned in
+// batches within a single transaction which is not retried, since the rows
+// of the batches read before a retry would already have been written.
+func exportCSV(db *client.DB, desc *structured.TableDescriptor, w io.Writer) (int, error) {
+	txn := db.NewTxn(nil)
+	if err := refreshTableDesc(txn, desc); err != nil {
+		abortTxn(txn)
+		return 0, err
+	}
+
+	cw := csv.NewWriter(w)
+	var header []string
+	for _, col := range desc.Columns {
+		if col.IsPublic() {
+			header = append(header, col.Name)
+		}
+	}
+	if err := cw.Write(header); err != nil {
+		abortTxn(txn)
+		return 0, err
+	}
+
+	count := 0
+	prefix := proto.Key(encodeIndexKeyPrefix(desc.ID, desc.Indexes[0].ID))
+	for start, end := prefix, prefix.PrefixEnd(); start != nil; {
+		tableRows, next, err := scanRowBatch(txn, desc, start, end, exportBatchSize)
+		if err != nil {
+			abortTxn(txn)
+			return count, err
+		}
+		for _, r := range tableRows {
+			record := make([]string, 0, len(header))
+			for i, v := range r.vals {
+				if desc.Columns[i].IsPublic() {
+					record = append(record, formatCSVValue(desc.Columns[i], v))
+				}
+			}
+			if err := cw.Write(record); err != nil {
+				abortTxn(txn)
+				return count, err
+			}
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			abortTxn(txn)
+			return count, err
+		}
+		count += len(tableRows)
+		start = next
+	}
+	return count, txn.Commit(&client.Batch{})
+}
+
+// formatCSVValue formats a value of the column as a field of a CSV row which
+// is parsed back by parseCSVValue.
+func formatCSVValue(col structured.ColumnDescriptor, v driver.Value) string {
+	switch t := v.(type) {
+	case nil:
+		return CSVNull
+	case int64:
+		return strconv.FormatInt(t, 10)
+	case float64:
+		return strconv.FormatFloat(t, 'g', -1, 64)
+	case string:
+		return EscapeCSVString(t)
+	case []byte:
+		return EscapeCSVString(string(t))
+	case time.Time:
+		switch col.Type.Kind {
+		case structured.ColumnType_DATE:
+			return t.Format("2006-01-02")
+		case structured.ColumnType_TIME:
+			return t.Format("15:04:05.999999999")
+		}
+		return t.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(v)
+}
+
+// isStringColumn returns true if the values of the column are strings, for
+// which an empty field is an empty string rather than NULL.
+func isStringColumn(col structured.ColumnDescriptor) bool {
+	switch col.Type.Kind {
+	case structured.ColumnType_CHAR, structured.ColumnType_BINARY,
+		structured.ColumnType_TEXT, structured.ColumnType_BLOB,
+		structured.ColumnType_ENUM, structured.ColumnType_SET:
+		return true
+	}
+	return false
+}
+
+// isCSVNullForm returns true if the string is \N preceded by any number of
+// backslashes. Such a string is written with an additional leading
+// backslash, so that it is not parsed back as NULL.
+func isCSVNullForm(s string) bool {
+	return len(s) > 1 && strings.TrimLeft(s, `\`) == "N"
+}
+
+// EscapeCSVString formats a string as a field of a CSV row.
+func EscapeCSVString(s string) string {
+	if isCSVNullForm(s) {
+		return `\` + s
+	}
+	return s
+}
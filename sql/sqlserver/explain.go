@@ -0,0 +1,147 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package sqlserver
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/sql/parser"
+	"github.com/cockroachdb/cockroach/structured"
+)
+
+// The result columns of EXPLAIN.
+var explainColumns = []string{"Type", "Description"}
+
+// Explain executes an EXPLAIN statement, returning the steps which would be
+// performed to execute the statement in the order they are performed. The
+// statement itself is not executed.
+func (s *session) Explain(p *parser.Explain, args []driver.Value) (*rows, error) {
+	var steps []row
+	switch t := p.Statement.(type) {
+	case parser.SelectStatement:
+		var err error
+		if steps, err = s.explainSelect(t, args); err != nil {
+			return nil, err
+		}
+
+	case *parser.Update:
+		plan, err := s.planTable(t.Table, t.Where, structured.UpdatePrivilege, args)
+		if err != nil {
+			return nil, err
+		}
+		steps = explainSelectRows(plan, whereExpr(t.Where), t.OrderBy, t.Limit)
+		exprs := make([]string, len(t.Exprs))
+		for i, e := range t.Exprs {
+			exprs[i] = e.String()
+		}
+		steps = append(steps, row{"update", fmt.Sprintf("%s SET %s", plan.desc.Name, strings.Join(exprs, ", "))})
+
+	case *parser.Delete:
+		plan, err := s.planTable(t.Table, t.Where, structured.DeletePrivilege, args)
+		if err != nil {
+			return nil, err
+		}
+		steps = explainSelectRows(plan, whereExpr(t.Where), t.OrderBy, t.Limit)
+		steps = append(steps, row{"delete", plan.desc.Name})
+
+	default:
+		return nil, fmt.Errorf("unsupported EXPLAIN: %s", p.Statement)
+	}
+	return &rows{columns: explainColumns, rows: steps}, nil
+}
+
+// explainSelect returns the steps performed to execute a SELECT statement or
+// a UNION of SELECT statements.
+func (s *session) explainSelect(stmt parser.SelectStatement, args []driver.Value) ([]row, error) {
+	if u, ok := stmt.(*parser.Union); ok {
+		left, err := s.explainSelect(u.Left, args)
+		if err != nil {
+			return nil, err
+		}
+		right, err := s.explainSelect(u.Right, args)
+		if err != nil {
+			return nil, err
+		}
+		return append(append(left, right...), row{"union", u.Type}), nil
+	}
+	t, ok := stmt.(*parser.Select)
+	if !ok {
+		return nil, fmt.Errorf("unsupported SELECT: %T %s", stmt, stmt)
+	}
+	plan, err := s.planSelect(t, args)
+	if err != nil {
+		return nil, err
+	}
+	steps := plan.join.explain()
+	if plan.grouped {
+		var group []string
+		if t.GroupBy != nil {
+			group = append(group, strings.TrimSpace(t.GroupBy.String()))
+		}
+		for _, f := range plan.aggs {
+			group = append(group, f.String())
+		}
+		steps = append(steps, row{"group", strings.Join(group, ", ")})
+	}
+	if t.Having != nil {
+		steps = append(steps, row{"filter", fmt.Sprintf("%s", t.Having.Expr)})
+	}
+	if t.OrderBy != nil {
+		steps = append(steps, row{"sort", strings.TrimSpace(t.OrderBy.String())})
+	}
+	names := make([]string, len(plan.outputs))
+	for i, o := range plan.outputs {
+		names[i] = o.name
+	}
+	steps = append(steps, row{"select", strings.Join(names, ", ")})
+	if t.Distinct != "" {
+		steps = append(steps, row{"distinct", ""})
+	}
+	if t.Limit != nil {
+		steps = append(steps, row{"limit", strings.TrimSpace(t.Limit.String())})
+	}
+	return steps, nil
+}
+
+// planTable chooses the index used by an UPDATE or DELETE statement to
+// retrieve the rows of the table matching the WHERE clause. The statement
+// requires the privilege on the table.
+func (s *session) planTable(name *parser.TableName, where *parser.Where,
+	priv structured.Privilege, args []driver.Value) (*scanPlan, error) {
+	desc, err := s.getTableDesc(name, priv)
+	if err != nil {
+		return nil, err
+	}
+	return makeScanPlan(desc, name.Name, splitAndExpr(whereExpr(where), nil), nil, nil, args, s.timeLocation())
+}
+
+// explainSelectRows returns the steps performed by selectRows.
+func explainSelectRows(plan *scanPlan, where parser.Expr, orderBy parser.OrderBy,
+	limit *parser.Limit) []row {
+	steps := plan.explain()
+	if where != nil {
+		steps = append(steps, row{"filter", fmt.Sprintf("%s", where)})
+	}
+	if orderBy != nil {
+		steps = append(steps, row{"sort", strings.TrimSpace(orderBy.String())})
+	}
+	if limit != nil {
+		steps = append(steps, row{"limit", strings.TrimSpace(limit.String())})
+	}
+	return steps
+}
@@ -0,0 +1,980 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package sqlserver
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/cockroachdb/cockroach/sql/parser"
+	"github.com/cockroachdb/cockroach/util/decimal"
+)
+
+// evalArg returns the driver argument referenced by the placeholder. The
+// parser numbers positional placeholders starting at 1 (":v1", ":v2", ...).
+// The argument is returned unchanged: a time argument remains a time.Time,
+// the representation of dates and times.
+func evalArg(v parser.ValArg, args []driver.Value) (driver.Value, error) {
+	i, err := argIndex(v)
+	if err != nil {
+		return nil, err
+	}
+	if i >= len(args) {
+		return nil, fmt.Errorf("placeholder %s out of range: %d args", v, len(args))
+	}
+	return args[i], nil
+}
+
+// argIndex returns the position of the argument referenced by the placeholder.
+func argIndex(v parser.ValArg) (int, error) {
+	s := string(v)
+	if len(s) < 3 || s[:2] != ":v" {
+		return 0, fmt.Errorf("unsupported placeholder: %s", v)
+	}
+	i, err := strconv.Atoi(s[2:])
+	if err != nil || i < 1 {
+		return 0, fmt.Errorf("invalid placeholder: %s", v)
+	}
+	return i - 1, nil
+}
+
+// evalNumVal converts a numeric literal to an int64 if possible and a float64
+// otherwise.
+func evalNumVal(v parser.NumVal) (driver.Value, error) {
+	if i, err := strconv.ParseInt(string(v), 0, 64); err == nil {
+		return i, nil
+	}
+	f, err := strconv.ParseFloat(string(v), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number: %s", v)
+	}
+	return f, nil
+}
+
+// An env provides the values of column references during expression
+// evaluation.
+type env interface {
+	get(name *parser.ColName) (driver.Value, error)
+}
+
+// A substituter is an env which provides the values of entire expressions,
+// such as the aggregate functions of a SELECT statement with GROUP BY.
+type substituter interface {
+	// substitute returns the value of the expression and true if the env
+	// provides the value of the expression.
+	substitute(e parser.Expr) (driver.Value, bool, error)
+}
+
+// evalConstExpr evaluates an expression which does not reference any
+// columns, such as the values in an INSERT statement.
+func evalConstExpr(e parser.Expr, args []driver.Value, loc *time.Location) (driver.Value, error) {
+	return evalExpr(e, nil, args, loc)
+}
+
+// evalBoolExpr evaluates a boolean expression, returning true only if the
+// expression evaluates to true. A NULL result is treated as false.
+func evalBoolExpr(e parser.Expr, env env, args []driver.Value, loc *time.Location) (bool, error) {
+	v, err := evalTruth(e, env, args, loc)
+	if err != nil || v == nil {
+		return false, err
+	}
+	return v.(bool), nil
+}
+
+// evalExpr evaluates the expression using the column values provided by env.
+// A nil env indicates that column references are not allowed. SQL NULL is
+// represented by a nil value and boolean expressions follow SQL's three-valued
+// logic. Strings without a time zone which are used as times, and the fields
+// extracted from times, are in the time zone loc of the session.
+func evalExpr(e parser.Expr, env env, args []driver.Value, loc *time.Location) (driver.Value, error) {
+	if s, ok := env.(substituter); ok {
+		if v, ok, err := s.substitute(e); ok || err != nil {
+			return v, err
+		}
+	}
+
+	switch t := e.(type) {
+	case parser.StrVal:
+		return string(t), nil
+	case parser.BytesVal:
+		return []byte(t), nil
+	case parser.NumVal:
+		return evalNumVal(t)
+	case parser.ValArg:
+		return evalArg(t, args)
+	case *parser.NullVal:
+		return nil, nil
+
+	case *parser.ColName:
+		if env == nil {
+			return nil, fmt.Errorf("column reference not allowed: %s", t)
+		}
+		return env.get(t)
+
+	case *parser.ParenBoolExpr:
+		return evalExpr(t.Expr, env, args, loc)
+
+	case parser.ValTuple:
+		// A parenthesized value expression, such as "(1 + 2)", is a tuple of
+		// one element.
+		if len(t) == 1 {
+			return evalExpr(t[0], env, args, loc)
+		}
+
+	case *parser.AndExpr:
+		left, err := evalTruth(t.Left, env, args, loc)
+		if err != nil {
+			return nil, err
+		}
+		if left != nil && !left.(bool) {
+			return false, nil
+		}
+		right, err := evalTruth(t.Right, env, args, loc)
+		if err != nil {
+			return nil, err
+		}
+		if right != nil && !right.(bool) {
+			return false, nil
+		}
+		if left == nil || right == nil {
+			return nil, nil
+		}
+		return true, nil
+
+	case *parser.OrExpr:
+		left, err := evalTruth(t.Left, env, args, loc)
+		if err != nil {
+			return nil, err
+		}
+		if left != nil && left.(bool) {
+			return true, nil
+		}
+		right, err := evalTruth(t.Right, env, args, loc)
+		if err != nil {
+			return nil, err
+		}
+		if right != nil && right.(bool) {
+			return true, nil
+		}
+		if left == nil || right == nil {
+			return nil, nil
+		}
+		return false, nil
+
+	case *parser.NotExpr:
+		v, err := evalTruth(t.Expr, env, args, loc)
+		if err != nil || v == nil {
+			return nil, err
+		}
+		return !v.(bool), nil
+
+	case *parser.ComparisonExpr:
+		return evalComparisonExpr(t, env, args, loc)
+
+	case *parser.RangeCond:
+		v, err := evalExpr(t.Left, env, args, loc)
+		if err != nil {
+			return nil, err
+		}
+		from, err := evalExpr(t.From, env, args, loc)
+		if err != nil {
+			return nil, err
+		}
+		to, err := evalExpr(t.To, env, args, loc)
+		if err != nil {
+			return nil, err
+		}
+		if v == nil || from == nil || to == nil {
+			return nil, nil
+		}
+		c1, err := compareValuesIn(v, from, loc)
+		if err != nil {
+			return nil, err
+		}
+		c2, err := compareValuesIn(v, to, loc)
+		if err != nil {
+			return nil, err
+		}
+		between := c1 >= 0 && c2 <= 0
+		if t.Operator == "NOT BETWEEN" {
+			return !between, nil
+		}
+		return between, nil
+
+	case *parser.NullCheck:
+		v, err := evalExpr(t.Expr, env, args, loc)
+		if err != nil {
+			return nil, err
+		}
+		if t.Operator == "NOT NULL" {
+			return v != nil, nil
+		}
+		return v == nil, nil
+
+	case *parser.UnaryExpr:
+		v, err := evalExpr(t.Expr, env, args, loc)
+		if err != nil || v == nil {
+			return nil, err
+		}
+		switch t.Operator {
+		case '+':
+			switch v.(type) {
+			case int64, float64, *decimal.Decimal:
+				return v, nil
+			}
+		case '-':
+			switch n := v.(type) {
+			case int64:
+				if n == math.MinInt64 {
+					return nil, fmt.Errorf("integer out of range: -(%d)", n)
+				}
+				return -n, nil
+			case float64:
+				return -n, nil
+			case *decimal.Decimal:
+				return n.Neg(), nil
+			}
+		case '~':
+			if n, ok := v.(int64); ok {
+				return ^n, nil
+			}
+		}
+		return nil, fmt.Errorf("unsupported unary operator: %c%T", t.Operator, v)
+
+	case *parser.BinaryExpr:
+		left, err := evalExpr(t.Left, env, args, loc)
+		if err != nil {
+			return nil, err
+		}
+		right, err := evalExpr(t.Right, env, args, loc)
+		if err != nil {
+			return nil, err
+		}
+		if left == nil || right == nil {
+			return nil, nil
+		}
+		return evalBinaryOp(t.Operator, left, right)
+
+	case *parser.FuncExpr:
+		if isAggregate(t) {
+			return nil, fmt.Errorf("aggregate function calls are not allowed here: %s", t)
+		}
+		return evalFuncExpr(t, env, args, loc)
+
+	case *parser.CaseExpr:
+		return evalCaseExpr(t, env, args, loc)
+	}
+	return nil, fmt.Errorf("unsupported expression: %T %s", e, e)
+}
+
+// evalCaseExpr evaluates a CASE expression. The value of the first WHEN
+// clause whose condition is true is returned. If the CASE expression has an
+// operand the conditions are instead compared to the operand, and a NULL
+// never matches. The result is NULL if no WHEN clause matches and there is no
+// ELSE clause.
+func evalCaseExpr(e *parser.CaseExpr, env env, args []driver.Value, loc *time.Location) (driver.Value, error) {
+	var operand driver.Value
+	if e.Expr != nil {
+		var err error
+		if operand, err = evalExpr(e.Expr, env, args, loc); err != nil {
+			return nil, err
+		}
+	}
+	for _, w := range e.Whens {
+		var match bool
+		if e.Expr == nil {
+			var err error
+			if match, err = evalBoolExpr(w.Cond, env, args, loc); err != nil {
+				return nil, err
+			}
+		} else {
+			v, err := evalExpr(w.Cond, env, args, loc)
+			if err != nil {
+				return nil, err
+			}
+			if operand != nil && v != nil {
+				c, err := compareValuesIn(operand, v, loc)
+				if err != nil {
+					return nil, err
+				}
+				match = c == 0
+			}
+		}
+		if match {
+			return evalExpr(w.Val, env, args, loc)
+		}
+	}
+	if e.Else != nil {
+		return evalExpr(e.Else, env, args, loc)
+	}
+	return nil, nil
+}
+
+// evalIn evaluates "<left> IN (<vals>)" or "<left> NOT IN (<vals>)". The
+// result is NULL if no values match and one of the values is NULL.
+func evalIn(op string, left driver.Value, vals []driver.Value, loc *time.Location) (driver.Value, error) {
+	if left == nil {
+		return nil, nil
+	}
+	var sawNull bool
+	for _, v := range vals {
+		if v == nil {
+			sawNull = true
+			continue
+		}
+		c, err := compareValuesIn(left, v, loc)
+		if err != nil {
+			return nil, err
+		}
+		if c == 0 {
+			return op == "IN", nil
+		}
+	}
+	if sawNull {
+		return nil, nil
+	}
+	return op != "IN", nil
+}
+
+// walkExpr calls fn for the expression and, if fn returns true, for each of
+// its sub-expressions. False is returned if the expression contains a
+// construct whose sub-expressions are unknown, such as a subquery.
+func walkExpr(e parser.Expr, fn func(parser.Expr) bool) bool {
+	if e == nil || !fn(e) {
+		return true
+	}
+	switch t := e.(type) {
+	case parser.StrVal, parser.BytesVal, parser.NumVal, parser.ValArg,
+		*parser.NullVal, *parser.ColName:
+		return true
+	case parser.ValTuple:
+		for _, v := range t {
+			if !walkExpr(v, fn) {
+				return false
+			}
+		}
+		return true
+	case *parser.ParenBoolExpr:
+		return walkExpr(t.Expr, fn)
+	case *parser.AndExpr:
+		return walkExpr(t.Left, fn) && walkExpr(t.Right, fn)
+	case *parser.OrExpr:
+		return walkExpr(t.Left, fn) && walkExpr(t.Right, fn)
+	case *parser.NotExpr:
+		return walkExpr(t.Expr, fn)
+	case *parser.ComparisonExpr:
+		return walkExpr(t.Left, fn) && walkExpr(t.Right, fn)
+	case *parser.RangeCond:
+		return walkExpr(t.Left, fn) && walkExpr(t.From, fn) && walkExpr(t.To, fn)
+	case *parser.NullCheck:
+		return walkExpr(t.Expr, fn)
+	case *parser.UnaryExpr:
+		return walkExpr(t.Expr, fn)
+	case *parser.BinaryExpr:
+		return walkExpr(t.Left, fn) && walkExpr(t.Right, fn)
+	case *parser.FuncExpr:
+		for _, arg := range t.Exprs {
+			if nse, ok := arg.(*parser.NonStarExpr); ok && !walkExpr(nse.Expr, fn) {
+				return false
+			}
+		}
+		return true
+	case *parser.CaseExpr:
+		if !walkExpr(t.Expr, fn) {
+			return false
+		}
+		for _, w := range t.Whens {
+			if !walkExpr(w.Cond, fn) || !walkExpr(w.Val, fn) {
+				return false
+			}
+		}
+		return walkExpr(t.Else, fn)
+	}
+	return false
+}
+
+// isConstExpr returns true if the expression does not reference any columns.
+func isConstExpr(e parser.Expr) bool {
+	isConst := true
+	known := walkExpr(e, func(e parser.Expr) bool {
+		if _, ok := e.(*parser.ColName); ok {
+			isConst = false
+		}
+		return isConst
+	})
+	return known && isConst
+}
+
+// foldConstExpr returns the expression with its constant sub-expressions
+// replaced by their values so that they are only evaluated once, such as
+// "v > 1 + 1" which becomes "v > 2". Placeholders are replaced by their
+// arguments. Boolean values are not folded as there are no boolean literals.
+// A sub-expression whose evaluation fails, such as "1 / 0", is left as is so
+// that the error is only reported if it is evaluated for a row. A tuple is
+// not replaced by a value, as the list of an IN expression must remain a
+// tuple, but its elements are folded.
+func foldConstExpr(e parser.Expr, args []driver.Value, loc *time.Location) parser.Expr {
+	if e == nil {
+		return nil
+	}
+	_, isBool := e.(parser.BoolExpr)
+	_, isTuple := e.(parser.ValTuple)
+	if !isBool && !isTuple && isConstExpr(e) {
+		if v, err := evalConstExpr(e, args, loc); err == nil {
+			if lit, ok := literalExpr(v); ok {
+				return lit
+			}
+		}
+	}
+
+	switch t := e.(type) {
+	case parser.ValTuple:
+		tuple := make(parser.ValTuple, len(t))
+		for i, v := range t {
+			tuple[i] = foldValExpr(v, args, loc)
+		}
+		return tuple
+	case *parser.ParenBoolExpr:
+		return &parser.ParenBoolExpr{Expr: foldBoolExpr(t.Expr, args, loc)}
+	case *parser.AndExpr:
+		return &parser.AndExpr{Op: t.Op, Left: foldBoolExpr(t.Left, args, loc), Right: foldBoolExpr(t.Right, args, loc)}
+	case *parser.OrExpr:
+		return &parser.OrExpr{Op: t.Op, Left: foldBoolExpr(t.Left, args, loc), Right: foldBoolExpr(t.Right, args, loc)}
+	case *parser.NotExpr:
+		return &parser.NotExpr{Op: t.Op, Expr: foldBoolExpr(t.Expr, args, loc)}
+	case *parser.ComparisonExpr:
+		return &parser.ComparisonExpr{
+			Operator: t.Operator,
+			Left:     foldValExpr(t.Left, args, loc),
+			Right:    foldValExpr(t.Right, args, loc),
+		}
+	case *parser.RangeCond:
+		return &parser.RangeCond{
+			Operator: t.Operator,
+			Left:     foldValExpr(t.Left, args, loc),
+			From:     foldValExpr(t.From, args, loc),
+			To:       foldValExpr(t.To, args, loc),
+		}
+	case *parser.NullCheck:
+		return &parser.NullCheck{Operator: t.Operator, Expr: foldValExpr(t.Expr, args, loc)}
+	case *parser.UnaryExpr:
+		return &parser.UnaryExpr{Operator: t.Operator, Expr: foldConstExpr(t.Expr, args, loc)}
+	case *parser.BinaryExpr:
+		return &parser.BinaryExpr{
+			Operator: t.Operator,
+			Left:     foldConstExpr(t.Left, args, loc),
+			Right:    foldConstExpr(t.Right, args, loc),
+		}
+	case *parser.FuncExpr:
+		if isAggregate(t) {
+			return t
+		}
+		f := &parser.FuncExpr{Name: t.Name, Distinct: t.Distinct, Exprs: make(parser.SelectExprs, len(t.Exprs))}
+		for i, arg := range t.Exprs {
+			if nse, ok := arg.(*parser.NonStarExpr); ok {
+				arg = &parser.NonStarExpr{Expr: foldConstExpr(nse.Expr, args, loc), As: nse.As}
+			}
+			f.Exprs[i] = arg
+		}
+		return f
+	case *parser.CaseExpr:
+		c := &parser.CaseExpr{Expr: foldValExpr(t.Expr, args, loc), Else: foldValExpr(t.Else, args, loc)}
+		for _, w := range t.Whens {
+			c.Whens = append(c.Whens, &parser.When{
+				Cond: foldBoolExpr(w.Cond, args, loc),
+				Val:  foldValExpr(w.Val, args, loc),
+			})
+		}
+		return c
+	}
+	return e
+}
+
+// foldBoolExpr folds the constant sub-expressions of a boolean expression,
+// whose value is never folded.
+func foldBoolExpr(e parser.BoolExpr, args []driver.Value, loc *time.Location) parser.BoolExpr {
+	if e == nil {
+		return nil
+	}
+	return foldConstExpr(e, args, loc).(parser.BoolExpr)
+}
+
+// foldValExpr folds the constant sub-expressions of a value expression.
+func foldValExpr(e parser.ValExpr, args []driver.Value, loc *time.Location) parser.ValExpr {
+	if e == nil {
+		return nil
+	}
+	return foldConstExpr(e, args, loc).(parser.ValExpr)
+}
+
+// literalExpr returns the literal expression for a value. False is returned
+// if the value has no literal representation.
+func literalExpr(v driver.Value) (parser.ValExpr, bool) {
+	switch t := v.(type) {
+	case nil:
+		return &parser.NullVal{}, true
+	case int64:
+		return parser.NumVal(strconv.FormatInt(t, 10)), true
+	case float64:
+		if math.IsNaN(t) || math.IsInf(t, 0) {
+			return nil, false
+		}
+		s := strconv.FormatFloat(t, 'g', -1, 64)
+		if _, err := strconv.ParseInt(s, 0, 64); err == nil {
+			// The literal must not be evaluated as an integer.
+			s += ".0"
+		}
+		return parser.NumVal(s), true
+	case string:
+		return parser.StrVal(t), true
+	case []byte:
+		return parser.BytesVal(t), true
+	}
+	return nil, false
+}
+
+// evalTruth evaluates a boolean expression, returning true, false or nil
+// (NULL).
+func evalTruth(e parser.Expr, env env, args []driver.Value, loc *time.Location) (driver.Value, error) {
+	v, err := evalExpr(e, env, args, loc)
+	if err != nil {
+		return nil, err
+	}
+	switch t := v.(type) {
+	case nil, bool:
+		return t, nil
+	case int64:
+		return t != 0, nil
+	}
+	return nil, fmt.Errorf("expected boolean expression, but found %T: %s", v, e)
+}
+
+func evalComparisonExpr(e *parser.ComparisonExpr, env env, args []driver.Value, loc *time.Location) (driver.Value, error) {
+	left, err := evalExpr(e.Left, env, args, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	switch e.Operator {
+	case "IN", "NOT IN":
+		tuple, ok := e.Right.(parser.ValTuple)
+		if !ok {
+			return nil, fmt.Errorf("unsupported IN expression: %T %s", e.Right, e.Right)
+		}
+		vals := make([]driver.Value, len(tuple))
+		for i, expr := range tuple {
+			if vals[i], err = evalExpr(expr, env, args, loc); err != nil {
+				return nil, err
+			}
+		}
+		return evalIn(e.Operator, left, vals, loc)
+	}
+
+	right, err := evalExpr(e.Right, env, args, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.Operator == "<=>" {
+		// The null-safe equality operator treats NULL as a comparable value.
+		if left == nil || right == nil {
+			return left == nil && right == nil, nil
+		}
+	} else if left == nil || right == nil {
+		return nil, nil
+	}
+
+	switch e.Operator {
+	case "LIKE", "NOT LIKE":
+		pattern, ok := right.(string)
+		if !ok {
+			return nil, fmt.Errorf("LIKE pattern must be a string: %T", right)
+		}
+		s, ok := left.(string)
+		if !ok {
+			return nil, fmt.Errorf("LIKE requires a string operand: %T", left)
+		}
+		re, err := likeToRegexp(pattern)
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString(s) == (e.Operator == "LIKE"), nil
+	}
+
+	c, err := compareValuesIn(left, right, loc)
+	if err != nil {
+		return nil, err
+	}
+	switch e.Operator {
+	case "=", "<=>":
+		return c == 0, nil
+	case "!=":
+		return c != 0, nil
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	case ">=":
+		return c >= 0, nil
+	}
+	return nil, fmt.Errorf("unsupported comparison operator: %s", e.Operator)
+}
+
+// likeToRegexp converts a LIKE pattern into an anchored regular expression.
+func likeToRegexp(pattern string) (*regexp.Regexp, error) {
+	var buf bytes.Buffer
+	buf.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '%':
+			buf.WriteString(".*")
+		case '_':
+			buf.WriteString(".")
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+			}
+			buf.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		default:
+			buf.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	buf.WriteString("$")
+	return regexp.Compile(buf.String())
+}
+
+// compareValues compares two non-NULL values, returning -1, 0 or +1.
+// Integers, floats and decimals are comparable with each other as are strings
+// and byte slices. Times are comparable with strings which can be parsed as a
+// time (see toTime).
+func compareValues(a, b driver.Value) (int, error) {
+	return compareValuesIn(a, b, time.UTC)
+}
+
+// compareValuesIn compares two non-NULL values like compareValues, but a
+// string without a time zone which is compared with a time is in the
+// location.
+func compareValuesIn(a, b driver.Value, loc *time.Location) (int, error) {
+	switch at := a.(type) {
+	case int64:
+		switch bt := b.(type) {
+		case int64:
+			return compareInts(at, bt), nil
+		case float64:
+			return compareFloats(float64(at), bt), nil
+		case *decimal.Decimal:
+			c, _ := compareDecimal(bt, at)
+			return -c, nil
+		}
+	case float64:
+		switch bt := b.(type) {
+		case int64:
+			return compareFloats(at, float64(bt)), nil
+		case float64:
+			return compareFloats(at, bt), nil
+		case *decimal.Decimal:
+			c, _ := compareDecimal(bt, at)
+			return -c, nil
+		}
+	case *decimal.Decimal:
+		if c, ok := compareDecimal(at, b); ok {
+			return c, nil
+		}
+	case bool:
+		if bt, ok := b.(bool); ok {
+			return compareInts(boolToInt(at), boolToInt(bt)), nil
+		}
+	case string:
+		switch bt := b.(type) {
+		case string:
+			return compareStrings(at, bt), nil
+		case []byte:
+			return bytes.Compare([]byte(at), bt), nil
+		case time.Time:
+			if t, ok := toTimeIn(at, loc); ok {
+				return compareTimes(t, bt), nil
+			}
+		}
+	case time.Time:
+		if bt, ok := toTimeIn(b, loc); ok {
+			return compareTimes(at, bt), nil
+		}
+	case []byte:
+		switch bt := b.(type) {
+		case string:
+			return bytes.Compare(at, []byte(bt)), nil
+		case []byte:
+			return bytes.Compare(at, bt), nil
+		}
+	}
+	return 0, fmt.Errorf("unable to compare %T and %T", a, b)
+}
+
+func compareInts(a, b int64) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+func compareStrings(a, b string) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+func compareFloats(a, b float64) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+// compareDecimal compares a decimal with a number, returning false if b is
+// not a number. A float is compared using the shortest decimal representation
+// which converts back to the same float, so that the decimal 1.1 is equal to
+// the float 1.1.
+func compareDecimal(a *decimal.Decimal, b driver.Value) (int, bool) {
+	switch bt := b.(type) {
+	case *decimal.Decimal:
+		return a.Cmp(bt), true
+	case int64:
+		return a.Cmp(decimal.NewFromInt(bt)), true
+	case float64:
+		if d, err := decimal.NewFromFloat(bt); err == nil {
+			return a.Cmp(d), true
+		}
+		// NaN and infinities have no decimal value.
+		return compareFloats(a.Float64(), bt), true
+	}
+	return 0, false
+}
+
+func compareTimes(a, b time.Time) int {
+	if a.Before(b) {
+		return -1
+	} else if a.After(b) {
+		return 1
+	}
+	return 0
+}
+
+func boolToInt(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func evalBinaryOp(op byte, left, right driver.Value) (driver.Value, error) {
+	_, ldec := left.(*decimal.Decimal)
+	_, rdec := right.(*decimal.Decimal)
+	if ldec || rdec {
+		return evalDecimalOp(op, left, right)
+	}
+
+	if l, ok := left.(int64); ok {
+		if r, ok := right.(int64); ok {
+			switch op {
+			case '&':
+				return l & r, nil
+			case '|':
+				return l | r, nil
+			case '^':
+				return l ^ r, nil
+			case '+':
+				sum := l + r
+				if (sum > l) != (r > 0) {
+					return nil, errIntOverflow(op, l, r)
+				}
+				return sum, nil
+			case '-':
+				diff := l - r
+				if (diff < l) != (r > 0) {
+					return nil, errIntOverflow(op, l, r)
+				}
+				return diff, nil
+			case '*':
+				prod := l * r
+				if l != 0 && (prod/l != r || (l == -1 && r == math.MinInt64)) {
+					return nil, errIntOverflow(op, l, r)
+				}
+				return prod, nil
+			case '/':
+				if r == 0 {
+					return nil, fmt.Errorf("division by zero")
+				}
+				if l == math.MinInt64 && r == -1 {
+					return nil, errIntOverflow(op, l, r)
+				}
+				return l / r, nil
+			case '%':
+				if r == 0 {
+					return nil, fmt.Errorf("division by zero")
+				}
+				return l % r, nil
+			}
+			return nil, fmt.Errorf("unsupported binary operator: %c", op)
+		}
+	}
+
+	l, lok := toFloat(left)
+	r, rok := toFloat(right)
+	if !lok || !rok {
+		return nil, fmt.Errorf("unsupported binary operator: %T %c %T", left, op, right)
+	}
+	switch op {
+	case '+':
+		return l + r, nil
+	case '-':
+		return l - r, nil
+	case '*':
+		return l * r, nil
+	case '/':
+		if r == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return l / r, nil
+	case '%':
+		if r == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return math.Mod(l, r), nil
+	}
+	return nil, fmt.Errorf("unsupported binary operator: %T %c %T", left, op, right)
+}
+
+// errIntOverflow returns the error for an integer operation whose result is
+// out of range.
+func errIntOverflow(op byte, l, r int64) error {
+	return fmt.Errorf("integer out of range: %d %c %d", l, op, r)
+}
+
+// evalDecimalOp evaluates a binary operator whose operands are numbers, at
+// least one of which is a decimal. The result is an exact decimal.
+func evalDecimalOp(op byte, left, right driver.Value) (driver.Value, error) {
+	for _, v := range []driver.Value{left, right} {
+		switch v.(type) {
+		case int64, float64, *decimal.Decimal:
+		default:
+			return nil, fmt.Errorf("unsupported binary operator: %T %c %T", left, op, right)
+		}
+	}
+	l, err := toDecimal(left)
+	if err != nil {
+		return nil, err
+	}
+	r, err := toDecimal(right)
+	if err != nil {
+		return nil, err
+	}
+	var d *decimal.Decimal
+	switch op {
+	case '+':
+		d = l.Add(r)
+	case '-':
+		d = l.Sub(r)
+	case '*':
+		d = l.Mul(r)
+	case '/':
+		if d, err = l.Quo(r); err != nil {
+			return nil, err
+		}
+	case '%':
+		if d, err = l.Rem(r); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported binary operator: %T %c %T", left, op, right)
+	}
+	if d.IntDigits() > decimal.MaxIntDigits {
+		return nil, fmt.Errorf("decimal value out of range")
+	}
+	return d, nil
+}
+
+// toDecimal converts a number or a string in decimal notation to a decimal.
+func toDecimal(v driver.Value) (*decimal.Decimal, error) {
+	switch t := v.(type) {
+	case *decimal.Decimal:
+		return t, nil
+	case int64:
+		return decimal.NewFromInt(t), nil
+	case float64:
+		return decimal.NewFromFloat(t)
+	case string:
+		return decimal.Parse(t)
+	}
+	return nil, fmt.Errorf("unable to convert %T to a decimal", v)
+}
+
+func toFloat(v driver.Value) (float64, bool) {
+	switch t := v.(type) {
+	case int64:
+		return float64(t), true
+	case float64:
+		return t, true
+	case *decimal.Decimal:
+		return t.Float64(), true
+	}
+	return 0, false
+}
+
+// timeFormats are the formats of the strings which can be converted to a
+// time. A time without a date is on January 1 of year 0.
+var timeFormats = []string{
+	"2006-01-02",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	time.RFC3339Nano,
+	"15:04:05.999999999",
+}
+
+// toTime converts a time or a string in one of the timeFormats to a time in
+// UTC. A string without a time zone is in UTC.
+func toTime(v driver.Value) (time.Time, bool) {
+	return toTimeIn(v, time.UTC)
+}
+
+// toTimeIn converts a time or a string in one of the timeFormats to a time in
+// UTC. A string without a time zone is in the location.
+func toTimeIn(v driver.Value, loc *time.Location) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t.UTC(), true
+	case string:
+		for _, format := range timeFormats {
+			if r, err := time.ParseInLocation(format, t, loc); err == nil {
+				return r.UTC(), true
+			}
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,279 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package sqlserver
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/sql/parser"
+	"github.com/cockroachdb/cockroach/structured"
+	"github.com/cockroachdb/cockroach/testutils"
+	"github.com/cockroachdb/cockroach/util/leaktest"
+)
+
+var isError = testutils.IsError
+
+func TestEvalExpr(t *testing.T) {
+	defer leaktest.AfterTest(t)
+
+	args := []driver.Value{int64(3), "abc", nil, time.Unix(0, 7)}
+	testData := []struct {
+		expr     string
+		expected driver.Value
+	}{
+		// Three-valued logic.
+		{`1 = NULL`, nil},
+		{`1 = NULL AND 1 = 2`, false},
+		{`1 = NULL AND 1 = 1`, nil},
+		{`1 = NULL OR 1 = 1`, true},
+		{`1 = NULL OR 1 = 2`, nil},
+		{`NOT (1 = NULL)`, nil},
+		{`1 IN (2, NULL)`, nil},
+		{`1 NOT IN (2, 3)`, true},
+		{`NULL IS NULL`, true},
+		{`NULL <=> NULL`, true},
+		// Parentheses.
+		{`(1 + 2) * 3`, int64(9)},
+		{`-(1)`, int64(-1)},
+		{`2 * (3 - (4 + 1))`, int64(-4)},
+		{`(1) IN (1)`, true},
+		// Placeholders.
+		{`? + 1`, int64(4)},
+		{`$2`, "abc"},
+		{`$3 IS NULL`, true},
+		{`$4`, time.Unix(0, 7)},
+		// CASE.
+		{`CASE WHEN 1 = 2 THEN 'a' WHEN 2 = 2 THEN 'b' END`, "b"},
+		{`CASE WHEN 1 = 2 THEN 'a' ELSE 'c' END`, "c"},
+		{`CASE WHEN 1 = NULL THEN 'a' END`, nil},
+		// Builtin functions.
+		{`LENGTH('héllo')`, int64(5)},
+		{`UPPER('abc')`, "ABC"},
+		{`LOWER(NULL)`, nil},
+		{`TRIM('  a b  ')`, "a b"},
+		{`CONCAT('a', 1, 2.5)`, "a12.5"},
+		{`SUBSTR('hello', 2)`, "ello"},
+		{`SUBSTRING('hello', 0, 3)`, "he"},
+		{`SUBSTRING('hello', 2, 9223372036854775807)`, "ello"},
+		{`SUBSTRING('hello', -9223372036854775807 - 1)`, "hello"},
+		{`REPLACE('banana', 'an', 'o')`, "booa"},
+		{`REVERSE('abc')`, "cba"},
+		{`REPEAT('ab', 2)`, "abab"},
+		{`ABS(-3)`, int64(3)},
+		{`ABS(-3.5)`, 3.5},
+		{`FLOOR(2.5)`, 2.0},
+		{`ROUND(2.345, 2)`, 2.35},
+		{`ROUND(1250, -2)`, int64(1300)},
+		{`SIGN(-0.5)`, int64(-1)},
+		{`MOD(7, 3)`, int64(1)},
+		{`SQRT(16)`, 4.0},
+		{`POW(2, 10)`, 1024.0},
+		{`COALESCE(NULL, NULL, 3)`, int64(3)},
+		{`IFNULL(NULL, 'x')`, "x"},
+		{`NULLIF(1, 1)`, nil},
+		{`NULLIF(1, 2)`, int64(1)},
+		{`GREATEST(1, 5, 3)`, int64(5)},
+		{`LEAST('b', 'a')`, "a"},
+		{`YEAR($4)`, int64(1970)},
+		{`MONTH('1970-02-10')`, int64(2)},
+		{`HOUR('2015-06-01 12:30:00')`, int64(12)},
+		// Times are comparable with strings.
+		{`$4 > '1970-01-01'`, true},
+		{`'1970-01-02' <= $4`, false},
+	}
+	for _, d := range testData {
+		expr, err := parser.ParseExpr(d.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", d.expr, err)
+		}
+		v, err := evalConstExpr(expr, args, time.UTC)
+		if err != nil {
+			t.Fatalf("%s: %v", d.expr, err)
+		}
+		if !reflect.DeepEqual(d.expected, v) {
+			t.Errorf("%s: expected %v (%T), but found %v (%T)", d.expr, d.expected, d.expected, v, v)
+		}
+	}
+
+	for _, d := range []struct {
+		expr        string
+		expectedErr string
+	}{
+		{`LENGTH(1)`, "argument 1 of LENGTH must be of type string, but found int64"},
+		{`SUBSTR('a', 1, -1)`, "negative substring length not allowed"},
+		{`REPEAT('ab', 9223372036854775807)`, "REPEAT result exceeds"},
+		{`ABS(-9223372036854775807 - 1)`, "integer out of range"},
+		{`9223372036854775807 + 1`, "integer out of range"},
+		{`-9223372036854775807 - 2`, "integer out of range"},
+		{`4611686018427387904 * 2`, "integer out of range"},
+		{`-1 * (-9223372036854775807 - 1)`, "integer out of range"},
+		{`(-9223372036854775807 - 1) / -1`, "integer out of range"},
+		{`-(-9223372036854775807 - 1)`, "integer out of range"},
+		{`SQRT(-1)`, "cannot take square root of a negative number"},
+		{`FOO(1)`, "unknown function: FOO"},
+		{`ABS(1, 2)`, "wrong number of arguments to ABS"},
+		{`SUM(1)`, "aggregate function calls are not allowed here"},
+		{`YEAR(0)`, "argument 1 of YEAR must be of type time, but found int64"},
+		{`$4 = 'abc'`, "unable to compare time.Time and string"},
+	} {
+		expr, err := parser.ParseExpr(d.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", d.expr, err)
+		}
+		if _, err := evalConstExpr(expr, args, time.UTC); !isError(err, d.expectedErr) {
+			t.Errorf("%s: expected %q, but found %v", d.expr, d.expectedErr, err)
+		}
+	}
+}
+
+// TestEvalExprLocation verifies that strings without a time zone which are
+// used as times, and the fields extracted from times, are in the time zone of
+// the session.
+func TestEvalExprLocation(t *testing.T) {
+	defer leaktest.AfterTest(t)
+
+	loc := time.FixedZone("UTC-5", -5*60*60)
+	args := []driver.Value{time.Date(2015, 1, 1, 3, 0, 0, 0, time.UTC)}
+	testData := []struct {
+		expr     string
+		expected driver.Value
+	}{
+		{`DAY($1)`, int64(31)},
+		{`HOUR($1)`, int64(22)},
+		{`HOUR('2015-01-01 03:00:00')`, int64(3)},
+		{`DAY('2015-01-01 03:00:00Z')`, int64(31)},
+		{`$1 = '2014-12-31 22:00:00'`, true},
+		{`$1 IN ('2015-01-01 03:00:00')`, false},
+		{`$1 BETWEEN '2014-12-31' AND '2015-01-01'`, true},
+	}
+	for _, d := range testData {
+		expr, err := parser.ParseExpr(d.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", d.expr, err)
+		}
+		v, err := evalConstExpr(expr, args, loc)
+		if err != nil {
+			t.Fatalf("%s: %v", d.expr, err)
+		}
+		if !reflect.DeepEqual(d.expected, v) {
+			t.Errorf("%s: expected %v (%T), but found %v (%T)", d.expr, d.expected, d.expected, v, v)
+		}
+	}
+}
+
+func TestFoldConstExpr(t *testing.T) {
+	defer leaktest.AfterTest(t)
+
+	args := []driver.Value{int64(3), "abc"}
+	testData := []struct {
+		expr     string
+		expected string
+	}{
+		{`k = 1 + 2`, `k = 3`},
+		{`k = ? * 2`, `k = 6`},
+		{`v = UPPER($2)`, `v = 'ABC'`},
+		{`k > 1.5 * 2`, `k > 3.0`},
+		{`k = 1 / 0`, `k = 1/0`},
+		{`k = 1 AND 1 = 1`, `k = 1 AND 1 = 1`},
+		{`k IN (1 + 1, NULLIF(1, 1))`, `k IN (2, NULL)`},
+		{`k IN (1 + 1)`, `k IN (2)`},
+		{`k = (1 + 2) * 3`, `k = 9`},
+		{`k = -(k + 1)`, `k = -(k+1)`},
+		{`LENGTH(v) + 1 > 2 * 2`, `LENGTH(v)+1 > 4`},
+		{`CASE WHEN k = 1 THEN 1 + 1 END = 2`, `CASE WHEN k = 1 THEN 2 END = 2`},
+	}
+	for _, d := range testData {
+		expr, err := parser.ParseExpr(d.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", d.expr, err)
+		}
+		if s := fmt.Sprint(foldConstExpr(expr, args, time.UTC)); s != d.expected {
+			t.Errorf("%s: expected %s, but found %s", d.expr, d.expected, s)
+		}
+	}
+}
+
+func TestTypeCheckExpr(t *testing.T) {
+	defer leaktest.AfterTest(t)
+
+	stmt, err := parser.Parse(`CREATE TABLE a (k INT PRIMARY KEY, v TEXT, f FLOAT, b BLOB, d DATE)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema, err := makeSchema(stmt.(*parser.CreateTable))
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc := structured.TableDescFromSchema(schema)
+	tables := joinEnv{{desc: &desc, alias: "a"}}
+
+	testData := []struct {
+		expr        string
+		expected    exprType
+		expectedErr string
+	}{
+		{`k + 1`, typeInt, ""},
+		{`k + f`, typeFloat, ""},
+		{`(k + 1) * 2`, typeInt, ""},
+		{`k + ?`, typeAny, ""},
+		{`v = b`, typeBool, ""},
+		{`CASE WHEN k = 1 THEN k ELSE f END`, typeFloat, ""},
+		{`COALESCE(v, b)`, typeString, ""},
+		{`ABS(k)`, typeInt, ""},
+		{`ROUND(f, 1)`, typeFloat, ""},
+		{`SQRT(k)`, typeFloat, ""},
+		{`AVG(k)`, typeFloat, ""},
+		{`d > '2015-06-01'`, typeBool, ""},
+		{`YEAR(d)`, typeInt, ""},
+		{`GREATEST(d, NOW())`, typeTime, ""},
+		{`x + 1`, typeAny, ""},
+		{`k = v`, typeAny, "cannot compare int and string"},
+		{`v - 1`, typeAny, "unsupported binary operator: string - int"},
+		{`k & f`, typeAny, "unsupported binary operator: int & float"},
+		{`f LIKE 'a%'`, typeAny, "LIKE requires a string operand"},
+		{`k IN (1, 'a')`, typeAny, "cannot compare int and string"},
+		{`CASE WHEN k = 1 THEN k ELSE v END`, typeAny, "CASE types int and string cannot be matched"},
+		{`UPPER(k)`, typeAny, "argument 1 of UPPER must be of type string, but found int"},
+		{`COALESCE(k, v)`, typeAny, "argument types of COALESCE cannot be matched"},
+		{`SUM(v)`, typeAny, "SUM requires a numeric argument"},
+		{`-v`, typeAny, "unsupported unary operator: -string"},
+		{`-(v)`, typeAny, "unsupported unary operator: -string"},
+		{`d + 1`, typeAny, "unsupported binary operator: time \\+ int"},
+		{`d = k`, typeAny, "cannot compare time and int"},
+	}
+	for _, d := range testData {
+		expr, err := parser.ParseExpr(d.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", d.expr, err)
+		}
+		typ, err := typeCheckExpr(expr, tables, nil)
+		if d.expectedErr != "" {
+			if !isError(err, d.expectedErr) {
+				t.Errorf("%s: expected %q, but found %v", d.expr, d.expectedErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", d.expr, err)
+		} else if typ != d.expected {
+			t.Errorf("%s: expected %s, but found %s", d.expr, d.expected, typ)
+		}
+	}
+}
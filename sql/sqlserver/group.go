@@ -0,0 +1,413 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package sqlserver
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/sql/parser"
+	"github.com/cockroachdb/cockroach/util/decimal"
+)
+
+// aggregateFuncs are the supported aggregate functions. The parser upper
+// cases the names of functions.
+var aggregateFuncs = map[string]struct{}{
+	"AVG":   {},
+	"COUNT": {},
+	"MAX":   {},
+	"MIN":   {},
+	"SUM":   {},
+}
+
+// isAggregate returns true if the function call is a call of an aggregate
+// function.
+func isAggregate(f *parser.FuncExpr) bool {
+	_, ok := aggregateFuncs[f.Name]
+	return ok
+}
+
+// findAggregates appends the aggregate function calls within the expression
+// to aggs.
+func findAggregates(e parser.Expr, aggs []*parser.FuncExpr) ([]*parser.FuncExpr, error) {
+	var err error
+	walkExpr(e, func(e parser.Expr) bool {
+		f, ok := e.(*parser.FuncExpr)
+		if !ok || !isAggregate(f) || err != nil {
+			return err == nil
+		}
+		for _, arg := range f.Exprs {
+			nse, ok := arg.(*parser.NonStarExpr)
+			if !ok {
+				continue
+			}
+			if nested, _ := findAggregates(nse.Expr, nil); len(nested) > 0 {
+				err = fmt.Errorf("aggregate function calls cannot be nested: %s", f)
+				return false
+			}
+		}
+		aggs = append(aggs, f)
+		return false
+	})
+	return aggs, err
+}
+
+// selectAggregates returns the aggregate function calls used by the output
+// columns, the HAVING clause and the ORDER BY clause of a SELECT statement,
+// along with whether the rows of the statement are grouped.
+func selectAggregates(p *parser.Select, outputs []selectOutput) ([]*parser.FuncExpr, bool, error) {
+	var exprs []parser.Expr
+	for _, o := range outputs {
+		exprs = append(exprs, o.expr)
+	}
+	exprs = append(exprs, whereExpr(p.Having))
+	for _, o := range p.OrderBy {
+		exprs = append(exprs, o.Expr)
+	}
+
+	var aggs []*parser.FuncExpr
+	for _, e := range exprs {
+		var err error
+		if aggs, err = findAggregates(e, aggs); err != nil {
+			return nil, false, err
+		}
+	}
+	return aggs, len(aggs) > 0 || len(p.GroupBy) > 0 || p.Having != nil, nil
+}
+
+// groupEnv implements the env interface for a group of rows sharing the same
+// values of the GROUP BY expressions. Column references are only allowed for
+// the columns of the GROUP BY clause, and are resolved using the first row of
+// the group.
+type groupEnv struct {
+	first     env      // The first row of the group, nil if there are no rows.
+	groupBy   []string // The GROUP BY expressions, formatted.
+	groupCols []string // The names of the columns in the GROUP BY clause.
+	keys      []driver.Value
+	aggs      map[*parser.FuncExpr]driver.Value
+}
+
+func (e *groupEnv) get(name *parser.ColName) (driver.Value, error) {
+	for _, c := range e.groupCols {
+		if strings.EqualFold(c, name.Name) {
+			return e.first.get(name)
+		}
+	}
+	return nil, fmt.Errorf("column \"%s\" must appear in the GROUP BY clause or be used in an aggregate function", name)
+}
+
+// substitute provides the values of the aggregate function calls and of the
+// GROUP BY expressions.
+func (e *groupEnv) substitute(expr parser.Expr) (driver.Value, bool, error) {
+	switch t := expr.(type) {
+	case *parser.FuncExpr:
+		if v, ok := e.aggs[t]; ok {
+			return v, true, nil
+		}
+	case *parser.ColName, parser.StrVal, parser.BytesVal, parser.NumVal,
+		parser.ValArg, *parser.NullVal:
+		return nil, false, nil
+	}
+	if len(e.groupBy) == 0 {
+		return nil, false, nil
+	}
+	s := fmt.Sprintf("%s", expr)
+	for i, g := range e.groupBy {
+		if g == s {
+			return e.keys[i], true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// groupRows groups the rows by the values of the GROUP BY expressions and
+// computes the aggregate functions for each group, returning the groups in
+// the order of their first row. Without a GROUP BY clause all of the rows,
+// even if there are none, form a single group. The aggregate functions are
+// computed as the rows are grouped, so that the rows of a group are not
+// retained.
+func groupRows(rowEnvs []env, groupBy parser.GroupBy, aggs []*parser.FuncExpr,
+	args []driver.Value, loc *time.Location) ([]env, error) {
+	groupByStrs := make([]string, len(groupBy))
+	var groupCols []string
+	for i, g := range groupBy {
+		groupByStrs[i] = fmt.Sprintf("%s", g)
+		if c, ok := g.(*parser.ColName); ok {
+			groupCols = append(groupCols, c.Name)
+		}
+	}
+	newGroup := func(first env, keys []driver.Value) (*groupEnv, []*aggregate, error) {
+		g := &groupEnv{
+			first:     first,
+			groupBy:   groupByStrs,
+			groupCols: groupCols,
+			keys:      keys,
+		}
+		accs := make([]*aggregate, len(aggs))
+		for i, f := range aggs {
+			var err error
+			if accs[i], err = newAggregate(f); err != nil {
+				return nil, nil, err
+			}
+		}
+		return g, accs, nil
+	}
+
+	var groups []*groupEnv
+	var groupAggs [][]*aggregate
+	groupIdx := map[string]int{}
+	for _, e := range rowEnvs {
+		keys := make([]driver.Value, len(groupBy))
+		var encoded []byte
+		for j, g := range groupBy {
+			var err error
+			if keys[j], err = evalExpr(g, e, args, loc); err != nil {
+				return nil, err
+			}
+			if encoded, err = encodeGroupKey(encoded, keys[j]); err != nil {
+				return nil, err
+			}
+		}
+		idx, ok := groupIdx[string(encoded)]
+		if !ok {
+			g, accs, err := newGroup(e, keys)
+			if err != nil {
+				return nil, err
+			}
+			idx = len(groups)
+			groupIdx[string(encoded)] = idx
+			groups = append(groups, g)
+			groupAggs = append(groupAggs, accs)
+		}
+		for _, a := range groupAggs[idx] {
+			if err := a.add(e, args, loc); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(groups) == 0 && len(groupBy) == 0 {
+		g, accs, err := newGroup(nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, g)
+		groupAggs = append(groupAggs, accs)
+	}
+
+	envs := make([]env, len(groups))
+	for i, g := range groups {
+		g.aggs = make(map[*parser.FuncExpr]driver.Value, len(aggs))
+		for _, a := range groupAggs[i] {
+			v, err := a.result()
+			if err != nil {
+				return nil, err
+			}
+			g.aggs[a.f] = v
+		}
+		envs[i] = g
+	}
+	return envs, nil
+}
+
+// aggregate computes the value of an aggregate function call over the rows of
+// a group, which are added one at a time. NULL values are ignored, and the
+// result of all of the functions except COUNT is NULL if there are no values.
+type aggregate struct {
+	f     *parser.FuncExpr
+	arg   parser.Expr // The argument, nil for COUNT(*).
+	count int64       // The number of values.
+	// seen holds the encoded values which have been added, only for DISTINCT.
+	seen map[string]struct{}
+
+	// The state of MIN and MAX.
+	extreme driver.Value
+
+	// The state of SUM and AVG. The sum of integers is an integer, unless it
+	// overflows. Decimals are summed exactly; the sum is a decimal if any of
+	// the values is a decimal.
+	isum     int64
+	fsum     float64
+	dsum     *decimal.Decimal
+	isFloat  bool
+	overflow bool
+}
+
+// newAggregate checks the argument of the aggregate function call and
+// returns an aggregate with no values.
+func newAggregate(f *parser.FuncExpr) (*aggregate, error) {
+	if len(f.Exprs) != 1 {
+		return nil, fmt.Errorf("%s requires a single argument: %s", f.Name, f)
+	}
+	a := &aggregate{f: f}
+	switch t := f.Exprs[0].(type) {
+	case *parser.StarExpr:
+		if f.Name != "COUNT" || f.Distinct || t.TableName != "" {
+			return nil, fmt.Errorf("invalid argument: %s", f)
+		}
+	case *parser.NonStarExpr:
+		a.arg = t.Expr
+	}
+	switch f.Name {
+	case "COUNT", "MIN", "MAX", "SUM", "AVG":
+	default:
+		return nil, fmt.Errorf("unknown aggregate function: %s", f.Name)
+	}
+	if f.Distinct {
+		a.seen = map[string]struct{}{}
+	}
+	return a, nil
+}
+
+// add adds the value of the argument for a row.
+func (a *aggregate) add(e env, args []driver.Value, loc *time.Location) error {
+	if a.arg == nil {
+		a.count++
+		return nil
+	}
+	v, err := evalExpr(a.arg, e, args, loc)
+	if err != nil {
+		return err
+	}
+	if v == nil {
+		return nil
+	}
+	if a.seen != nil {
+		key, err := encodeGroupKey(nil, v)
+		if err != nil {
+			return err
+		}
+		if _, ok := a.seen[string(key)]; ok {
+			return nil
+		}
+		a.seen[string(key)] = struct{}{}
+	}
+	a.count++
+
+	switch a.f.Name {
+	case "MIN", "MAX":
+		if a.extreme == nil {
+			a.extreme = v
+			return nil
+		}
+		c, err := compareValues(v, a.extreme)
+		if err != nil {
+			return err
+		}
+		if (c < 0) == (a.f.Name == "MIN") && c != 0 {
+			a.extreme = v
+		}
+
+	case "SUM", "AVG":
+		switch t := v.(type) {
+		case int64:
+			sum := a.isum + t
+			if (sum > a.isum) != (t > 0) {
+				a.overflow = true
+			}
+			a.isum = sum
+			a.fsum += float64(t)
+		case float64:
+			a.isFloat = true
+			a.fsum += t
+		case *decimal.Decimal:
+			if a.dsum == nil {
+				a.dsum = t
+			} else {
+				a.dsum = a.dsum.Add(t)
+			}
+		default:
+			return fmt.Errorf("%s requires a numeric argument: %T", a.f.Name, v)
+		}
+	}
+	return nil
+}
+
+// result returns the value of the aggregate function over the values added.
+func (a *aggregate) result() (driver.Value, error) {
+	if a.f.Name == "COUNT" {
+		return a.count, nil
+	}
+	if a.count == 0 {
+		return nil, nil
+	}
+	switch a.f.Name {
+	case "MIN", "MAX":
+		return a.extreme, nil
+	}
+	if a.dsum != nil {
+		return a.decimalResult()
+	}
+	if a.f.Name == "AVG" {
+		return a.fsum / float64(a.count), nil
+	}
+	if a.isFloat {
+		return a.fsum, nil
+	}
+	if a.overflow {
+		return nil, fmt.Errorf("integer out of range: %s", a.f)
+	}
+	return a.isum, nil
+}
+
+// decimalResult returns the value of SUM or AVG over values of which at least
+// one is a decimal.
+func (a *aggregate) decimalResult() (driver.Value, error) {
+	sum := a.dsum
+	if a.isFloat {
+		f, err := decimal.NewFromFloat(a.fsum)
+		if err != nil {
+			return nil, err
+		}
+		sum = sum.Add(f)
+	}
+	if a.overflow {
+		return nil, fmt.Errorf("integer out of range: %s", a.f)
+	}
+	if a.isum != 0 {
+		sum = sum.Add(decimal.NewFromInt(a.isum))
+	}
+	if a.f.Name == "AVG" {
+		return sum.Quo(decimal.NewFromInt(a.count))
+	}
+	return sum, nil
+}
+
+// encodeGroupKey appends an encoding of the value to b such that two values
+// have the same encoding only if they are equal and of the same type.
+func encodeGroupKey(b []byte, v driver.Value) ([]byte, error) {
+	var tag byte
+	switch v.(type) {
+	case nil:
+		return append(b, 0), nil
+	case bool:
+		tag = 1
+	case int64:
+		tag = 2
+	case float64:
+		tag = 3
+	case string:
+		tag = 4
+	case []byte:
+		tag = 5
+	case time.Time:
+		tag = 6
+	case *decimal.Decimal:
+		tag = 7
+	}
+	return encodeTableKey(append(b, tag), v)
+}
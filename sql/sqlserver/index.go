@@ -0,0 +1,201 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package sqlserver
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/client"
+	"github.com/cockroachdb/cockroach/proto"
+	"github.com/cockroachdb/cockroach/sql/parser"
+	"github.com/cockroachdb/cockroach/structured"
+	"github.com/cockroachdb/cockroach/util/log"
+)
+
+// BackfillBatchSize is the number of rows processed by each of the
+// transactions performing a schema change, such as the backfill of a new index
+// or column.
+// This is exported for testing purposes only.
+var BackfillBatchSize = 100
+
+// TestingIndexBackfillFilter may be set in tests to run after each batch of
+// rows has been added to an index being created.
+var TestingIndexBackfillFilter func()
+
+// CreateIndex executes a CREATE INDEX statement. The index is added to the
+// table descriptor in the WRITE_ONLY state before it is populated so that
+// statements which modify the table maintain the index while the existing rows
+// are backfilled, without reading from it. The backfill is performed in a
+// series of small transactions in order to avoid blocking writers, after
+// which the index is made public.
+func (s *session) CreateIndex(p *parser.CreateIndex, args []driver.Value) (*rows, error) {
+	desc, err := s.getTableDesc(p.Table, structured.AllPrivilege)
+	if err != nil {
+		return nil, err
+	}
+
+	index := structured.IndexDescriptor{
+		Index: structured.Index{
+			Name:   p.Name,
+			Unique: p.Unique,
+		},
+		State: structured.IndexDescriptor_WRITE_ONLY,
+	}
+	for _, name := range p.Columns {
+		col, err := desc.FindColumnByName(name)
+		if err != nil {
+			return nil, err
+		}
+		index.ColumnIDs = append(index.ColumnIDs, col.ID)
+	}
+
+	err = updateTableDesc(s.db, desc, func(txn *client.Txn, b *client.Batch) error {
+		index.ID = desc.NextIndexID
+		desc.NextIndexID++
+		desc.Indexes = append(desc.Indexes, index)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := backfillIndex(s.db, desc, index); err != nil {
+		// Remove the partially populated index.
+		if dropErr := dropIndex(s.db, desc.ID, index.ID); dropErr != nil {
+			log.Warningf("unable to remove index \"%s\": %s", index.Name, dropErr)
+		}
+		return nil, err
+	}
+
+	err = updateTableDesc(s.db, desc, func(txn *client.Txn, b *client.Batch) error {
+		idx, err := desc.FindIndexByID(index.ID)
+		if err != nil {
+			return err
+		}
+		idx.State = structured.IndexDescriptor_PUBLIC
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &rows{}, nil
+}
+
+// DropIndex executes a DROP INDEX statement.
+func (s *session) DropIndex(p *parser.DropIndex, args []driver.Value) (*rows, error) {
+	desc, err := s.getTableDesc(p.Table, structured.AllPrivilege)
+	if err != nil {
+		return nil, err
+	}
+	index, err := desc.FindIndexByName(p.Name)
+	if err != nil {
+		return nil, err
+	}
+	if index.ID == desc.Indexes[0].ID {
+		return nil, fmt.Errorf("cannot drop primary index \"%s\"", index.Name)
+	}
+	if err := dropIndex(s.db, desc.ID, index.ID); err != nil {
+		return nil, err
+	}
+	return &rows{}, nil
+}
+
+// backfillIndex adds the existing rows of the table to the index. Rows
+// written concurrently with the backfill maintain the index themselves, so the
+// entries for a unique index are only required to be absent or to refer to the
+// same row.
+func backfillIndex(db *client.DB, desc *structured.TableDescriptor,
+	index structured.IndexDescriptor) error {
+	return forEachRowBatch(db, desc, func(txn *client.Txn, tableRows []tableRow) error {
+		colMap := columnIndexMap(desc)
+		var indexKeys, primaryKeys [][]byte
+		for _, row := range tableRows {
+			key, ok, err := encodeSecondaryIndexKey(desc, index, colMap, row.vals)
+			if err != nil {
+				return err
+			}
+			if ok {
+				indexKeys = append(indexKeys, key)
+				primaryKeys = append(primaryKeys, row.key)
+			}
+		}
+
+		if index.Unique && len(indexKeys) > 0 {
+			dupErr := fmt.Errorf("duplicate key value violates unique constraint \"%s\"", index.Name)
+			seen := map[string]struct{}{}
+			b := &client.Batch{}
+			for _, key := range indexKeys {
+				if _, ok := seen[string(key)]; ok {
+					return dupErr
+				}
+				seen[string(key)] = struct{}{}
+				b.Get(key)
+			}
+			if err := txn.Run(b); err != nil {
+				return err
+			}
+			for i, result := range b.Results {
+				existing := result.Rows[0]
+				if existing.Exists() && !bytes.Equal(existing.ValueBytes(), primaryKeys[i]) {
+					return dupErr
+				}
+			}
+		}
+
+		b := &client.Batch{}
+		for i, key := range indexKeys {
+			if log.V(2) {
+				log.Infof("Put %q -> %q", key, primaryKeys[i])
+			}
+			b.Put(key, primaryKeys[i])
+		}
+		if err := txn.Commit(b); err != nil {
+			return err
+		}
+		if TestingIndexBackfillFilter != nil {
+			TestingIndexBackfillFilter()
+		}
+		return nil
+	})
+}
+
+// dropIndex removes the index from the table descriptor and deletes the index
+// data. Statements which modify the table read the descriptor within their
+// transaction, so no entries are added to the index once the descriptor has
+// been updated.
+func dropIndex(db *client.DB, tableID, indexID uint32) error {
+	desc := &structured.TableDescriptor{ID: tableID}
+	err := updateTableDesc(db, desc, func(txn *client.Txn, b *client.Batch) error {
+		for i := range desc.Indexes {
+			if desc.Indexes[i].ID == indexID {
+				desc.Indexes = append(desc.Indexes[:i], desc.Indexes[i+1:]...)
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	prefix := proto.Key(encodeIndexKeyPrefix(tableID, indexID))
+	if log.V(2) {
+		log.Infof("DelRange %q - %q", prefix, prefix.PrefixEnd())
+	}
+	return db.DelRange(prefix, prefix.PrefixEnd())
+}
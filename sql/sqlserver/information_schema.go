@@ -0,0 +1,424 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package sqlserver
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/client"
+	"github.com/cockroachdb/cockroach/keys"
+	"github.com/cockroachdb/cockroach/proto"
+	"github.com/cockroachdb/cockroach/sql/parser"
+	"github.com/cockroachdb/cockroach/structured"
+)
+
+// informationSchemaName is the name of the virtual database containing the
+// tables of the system catalog.
+const informationSchemaName = "information_schema"
+
+// A virtualTable is a read-only table of the information_schema database. Its
+// rows are not stored but generated from the databases and table descriptors
+// whenever the table is scanned. The catalog describes the databases created
+// by users; the information_schema database itself is not listed. Only the
+// databases and tables on which the user holds a privilege are listed (see
+// filterCatalog).
+type virtualTable struct {
+	desc structured.TableDescriptor
+	// Set if the rows are generated from the table descriptors and not only
+	// from the databases.
+	needTables bool
+	populate   func(dbs []catalogDatabase, addRow func(vals ...driver.Value))
+}
+
+// The virtual tables are defined using CREATE TABLE statements. The primary
+// key of every table orders its rows and allows a scan to be restricted to a
+// single database or table.
+var informationSchema = []struct {
+	schema     string
+	needTables bool
+	populate   func(dbs []catalogDatabase, addRow func(vals ...driver.Value))
+}{
+	{`CREATE TABLE information_schema.databases (
+		database_name TEXT PRIMARY KEY,
+		database_id INT
+	)`, false, populateDatabases},
+	{`CREATE TABLE information_schema.tables (
+		table_schema TEXT,
+		table_name TEXT,
+		table_type TEXT,
+		table_id INT,
+		version INT,
+		PRIMARY KEY (table_schema, table_name)
+	)`, true, populateTables},
+	{`CREATE TABLE information_schema.columns (
+		table_schema TEXT,
+		table_name TEXT,
+		ordinal_position INT,
+		column_name TEXT,
+		column_id INT,
+		data_type TEXT,
+		is_nullable BIT(1),
+		column_default TEXT,
+		PRIMARY KEY (table_schema, table_name, ordinal_position)
+	)`, true, populateColumns},
+	{`CREATE TABLE information_schema.indexes (
+		table_schema TEXT,
+		table_name TEXT,
+		index_id INT,
+		seq_in_index INT,
+		index_name TEXT,
+		is_unique BIT(1),
+		column_name TEXT,
+		PRIMARY KEY (table_schema, table_name, index_id, seq_in_index)
+	)`, true, populateIndexes},
+	{`CREATE TABLE information_schema.views (
+		table_schema TEXT,
+		table_name TEXT,
+		view_definition TEXT,
+		PRIMARY KEY (table_schema, table_name)
+	)`, true, populateViews},
+	{`CREATE TABLE information_schema.schema_privileges (
+		table_schema TEXT,
+		grantee TEXT,
+		privilege_type TEXT,
+		PRIMARY KEY (table_schema, grantee, privilege_type)
+	)`, false, populateSchemaPrivileges},
+	{`CREATE TABLE information_schema.table_privileges (
+		table_schema TEXT,
+		table_name TEXT,
+		grantee TEXT,
+		privilege_type TEXT,
+		PRIMARY KEY (table_schema, table_name, grantee, privilege_type)
+	)`, true, populateTablePrivileges},
+}
+
+// virtualTables maps from the names of the virtual tables to their
+// definitions and virtualTablesByID from their IDs. The virtual tables are
+// assigned the IDs at the top of the ID space, which are never allocated to
+// user tables.
+var (
+	virtualTables     = map[string]*virtualTable{}
+	virtualTablesByID = map[uint32]*virtualTable{}
+)
+
+func init() {
+	for i, t := range informationSchema {
+		stmt, err := parser.Parse(t.schema)
+		if err != nil {
+			panic(err)
+		}
+		schema, err := makeSchema(stmt.(*parser.CreateTable))
+		if err != nil {
+			panic(err)
+		}
+		vt := &virtualTable{
+			desc:       structured.TableDescFromSchema(schema),
+			needTables: t.needTables,
+			populate:   t.populate,
+		}
+		vt.desc.ID = math.MaxUint32 - uint32(i)
+		if err := structured.ValidateTableDesc(vt.desc); err != nil {
+			panic(err)
+		}
+		virtualTables[stmt.(*parser.CreateTable).Name.Name] = vt
+		virtualTablesByID[vt.desc.ID] = vt
+	}
+}
+
+// isInformationSchema returns true if the database name refers to the
+// information_schema database.
+func isInformationSchema(database string) bool {
+	return strings.EqualFold(database, informationSchemaName)
+}
+
+// getVirtualTableDesc returns a copy of the descriptor of the virtual table.
+func getVirtualTableDesc(name *parser.TableName) (*structured.TableDescriptor, error) {
+	vt, ok := virtualTables[strings.ToLower(name.Name)]
+	if !ok {
+		return nil, fmt.Errorf("table \"%s\" does not exist", name)
+	}
+	desc := vt.desc
+	return &desc, nil
+}
+
+// catalogDatabase holds a database, its descriptor and the descriptors of
+// its tables in name order.
+type catalogDatabase struct {
+	name   string
+	id     uint32
+	desc   structured.DatabaseDescriptor
+	tables []catalogTable
+}
+
+type catalogTable struct {
+	name string
+	desc structured.TableDescriptor
+}
+
+// readCatalog reads the databases with their descriptors and, if readTables
+// is set, the descriptors of their tables. A non-empty database or table name restricts the catalog
+// to the database or table of that name.
+func readCatalog(db scanner, database, table string, readTables bool) ([]catalogDatabase, error) {
+	prefix := keys.MakeNameMetadataKey(structured.RootNamespaceID, "")
+	kvs, err := db.Scan(prefix, prefix.PrefixEnd(), 0)
+	if err != nil {
+		return nil, err
+	}
+	var dbs []catalogDatabase
+	for _, kv := range kvs {
+		name := string(bytes.TrimPrefix(kv.Key, prefix))
+		if database != "" && name != database {
+			continue
+		}
+		dbs = append(dbs, catalogDatabase{name: name, id: uint32(kv.ValueInt())})
+	}
+	if len(dbs) == 0 {
+		return dbs, nil
+	}
+
+	b := &client.Batch{}
+	for _, d := range dbs {
+		b.Get(keys.MakeDescMetadataKey(d.id))
+	}
+	if readTables {
+		for _, d := range dbs {
+			prefix := keys.MakeNameMetadataKey(d.id, "")
+			b.Scan(prefix, prefix.PrefixEnd(), 0)
+		}
+	}
+	if err := db.Run(b); err != nil {
+		return nil, err
+	}
+	for i := range dbs {
+		if err := b.Results[i].Rows[0].ValueProto(&dbs[i].desc); err != nil {
+			return nil, err
+		}
+	}
+	if !readTables {
+		return dbs, nil
+	}
+	descs := &client.Batch{}
+	for i := range dbs {
+		prefix := keys.MakeNameMetadataKey(dbs[i].id, "")
+		for _, kv := range b.Results[len(dbs)+i].Rows {
+			name := string(bytes.TrimPrefix(kv.Key, prefix))
+			if table != "" && name != table {
+				continue
+			}
+			dbs[i].tables = append(dbs[i].tables, catalogTable{name: name})
+			descs.Get(kv.ValueBytes())
+		}
+	}
+	if len(descs.Results) == 0 {
+		return dbs, nil
+	}
+	if err := db.Run(descs); err != nil {
+		return nil, err
+	}
+	n := 0
+	for i := range dbs {
+		for j := range dbs[i].tables {
+			if err := descs.Results[n].Rows[0].ValueProto(&dbs[i].tables[j].desc); err != nil {
+				return nil, err
+			}
+			n++
+		}
+	}
+	return dbs, nil
+}
+
+// filterCatalog returns the databases and tables of the catalog on which the
+// user holds any privilege. All of the tables of a database are kept if the
+// user holds a privilege on the database, and a database is kept if the user
+// holds a privilege on any of its tables.
+func filterCatalog(dbs []catalogDatabase, user string) []catalogDatabase {
+	var visible []catalogDatabase
+	for _, d := range dbs {
+		if d.desc.Privileges.CheckAny(user) {
+			visible = append(visible, d)
+			continue
+		}
+		var tables []catalogTable
+		for _, t := range d.tables {
+			if t.desc.Privileges.CheckAny(user) {
+				tables = append(tables, t)
+			}
+		}
+		if len(tables) > 0 {
+			d.tables = tables
+			visible = append(visible, d)
+		}
+	}
+	return visible
+}
+
+func populateDatabases(dbs []catalogDatabase, addRow func(vals ...driver.Value)) {
+	for _, d := range dbs {
+		addRow(d.name, int64(d.id))
+	}
+}
+
+func populateTables(dbs []catalogDatabase, addRow func(vals ...driver.Value)) {
+	for _, d := range dbs {
+		for _, t := range d.tables {
+			typ := "BASE TABLE"
+			if t.desc.IsView() {
+				typ = "VIEW"
+			}
+			addRow(d.name, t.name, typ, int64(t.desc.ID), int64(t.desc.Version))
+		}
+	}
+}
+
+func populateColumns(dbs []catalogDatabase, addRow func(vals ...driver.Value)) {
+	for _, d := range dbs {
+		for _, t := range d.tables {
+			for i, col := range t.desc.Columns {
+				if !col.IsPublic() {
+					continue
+				}
+				var def driver.Value
+				if col.DefaultExpr != nil {
+					def = *col.DefaultExpr
+				}
+				addRow(d.name, t.name, int64(i+1), col.Name, int64(col.ID),
+					col.Type.SQLString(), boolToInt(col.Nullable), def)
+			}
+		}
+	}
+}
+
+func populateIndexes(dbs []catalogDatabase, addRow func(vals ...driver.Value)) {
+	for _, d := range dbs {
+		for _, t := range d.tables {
+			// The schema only holds the public indexes.
+			var ids []uint32
+			for _, index := range t.desc.Indexes {
+				if index.IsPublic() {
+					ids = append(ids, index.ID)
+				}
+			}
+			schema := structured.TableSchemaFromDesc(t.desc)
+			for i, index := range schema.Indexes {
+				for j, col := range index.ColumnNames {
+					addRow(d.name, t.name, int64(ids[i]), int64(j+1),
+						index.Name, boolToInt(index.Unique), col)
+				}
+			}
+		}
+	}
+}
+
+func populateViews(dbs []catalogDatabase, addRow func(vals ...driver.Value)) {
+	for _, d := range dbs {
+		for _, t := range d.tables {
+			if t.desc.IsView() {
+				addRow(d.name, t.name, t.desc.ViewQuery)
+			}
+		}
+	}
+}
+
+func populateSchemaPrivileges(dbs []catalogDatabase, addRow func(vals ...driver.Value)) {
+	for _, d := range dbs {
+		addPrivilegeRows(d.desc.Privileges, func(user, priv string) {
+			addRow(d.name, user, priv)
+		})
+	}
+}
+
+func populateTablePrivileges(dbs []catalogDatabase, addRow func(vals ...driver.Value)) {
+	for _, d := range dbs {
+		for _, t := range d.tables {
+			addPrivilegeRows(t.desc.Privileges, func(user, priv string) {
+				addRow(d.name, t.name, user, priv)
+			})
+		}
+	}
+}
+
+// addPrivilegeRows calls addRow for each privilege granted to each user.
+func addPrivilegeRows(pd structured.PrivilegeDescriptor, addRow func(user, priv string)) {
+	for _, u := range pd.Users {
+		for _, name := range structured.Privilege(u.Privileges).Names() {
+			addRow(u.User, name)
+		}
+	}
+}
+
+// scan generates the rows of the virtual table within the span of its primary
+// index in primary key order, the same as a scan of the primary index of a
+// stored table. When the leading primary key columns are constrained to a
+// single database or table, only the descriptors of that database or table
+// are read.
+func (vt *virtualTable) scan(db scanner, p *scanPlan) ([]tableRow, error) {
+	// The leading primary key columns are the database name followed by the
+	// table name.
+	colMap := columnIndexMap(&vt.desc)
+	primaryIndex := vt.desc.Indexes[0]
+	prefix := encodeIndexKeyPrefix(vt.desc.ID, primaryIndex.ID)
+	var names [2]string
+	var remaining []byte
+	if p.exact > 0 {
+		remaining = p.span.start[len(prefix):]
+	}
+	for i := 0; i < p.exact && i < len(names); i++ {
+		var v driver.Value
+		var err error
+		col := vt.desc.Columns[colMap[primaryIndex.ColumnIDs[i]]]
+		if remaining, v, err = decodeTableKey(remaining, col); err != nil {
+			return nil, err
+		}
+		names[i], _ = v.(string)
+	}
+	// The tables are needed to list the databases holding a table on which
+	// the user holds a privilege.
+	superuser := isSuperuser(p.user)
+	dbs, err := readCatalog(db, names[0], names[1], vt.needTables || !superuser)
+	if err != nil {
+		return nil, err
+	}
+	if !superuser {
+		dbs = filterCatalog(dbs, p.user)
+	}
+
+	var tableRows []tableRow
+	var rowErr error
+	vt.populate(dbs, func(vals ...driver.Value) {
+		if rowErr != nil {
+			return
+		}
+		key, err := encodeIndexKey(primaryIndex, colMap, vals, prefix)
+		if err != nil {
+			rowErr = err
+			return
+		}
+		if k := proto.Key(key); k.Less(p.span.start) || !k.Less(p.span.end) {
+			return
+		}
+		tableRows = append(tableRows, tableRow{key: key, vals: vals})
+	})
+	if rowErr != nil {
+		return nil, rowErr
+	}
+	sort.Sort(tableRowsByKey(tableRows))
+	return tableRows, nil
+}
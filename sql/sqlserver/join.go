@@ -0,0 +1,699 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package sqlserver
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/sql/parser"
+	"github.com/cockroachdb/cockroach/structured"
+)
+
+// errStopIteration is returned by the function passed to joinPlan.run in
+// order to stop producing rows. It is not returned by run itself.
+var errStopIteration = errors.New("stop iteration")
+
+// joinType is the type of the join between a table of the FROM clause and
+// the tables preceding it.
+type joinType int
+
+const (
+	innerJoin joinType = iota
+	leftJoin
+)
+
+// joinStrategy is the algorithm used to find the rows of a table matching a
+// row of the tables preceding it in the FROM clause.
+type joinStrategy int
+
+const (
+	// Every row of the table is considered.
+	nestedLoopJoin joinStrategy = iota
+	// The rows of the table are hashed by the values of the join columns.
+	hashJoin
+	// An index of the table is scanned for the values of the join columns.
+	lookupJoin
+)
+
+// fromTable is a table of the FROM clause of a SELECT statement.
+type fromTable struct {
+	desc  *structured.TableDescriptor
+	alias string // The name the table is referenced by in the query.
+	// The source of the rows of a view, nil for a table.
+	view *viewSource
+	// The user of the session, for whom the rows of an information_schema
+	// table are generated.
+	user string
+	// How the rows of the table are joined to the rows of the preceding
+	// tables. Unused for the first table.
+	typ joinType
+	on  parser.Expr // The join condition, nil if every pair of rows matches.
+
+	// The following fields are set by planJoins.
+	strategy joinStrategy
+	// The conjuncts which only reference the table. They narrow the scan of
+	// the table and are evaluated for every row which is retrieved.
+	filter []parser.Expr
+	// The conjuncts of the join condition which are evaluated for every pair
+	// of rows.
+	cond []parser.Expr
+	// The equality conjuncts "<col> = <expr>" between a column of the table
+	// and an expression over the columns of the preceding tables. eqCols holds
+	// the positions of the columns within the table.
+	eqConds []parser.Expr
+	eqCols  []int
+	eqExprs []parser.Expr
+	// The plan used to scan the table, unless the table is joined using a
+	// lookup join.
+	plan *scanPlan
+	// The index scanned by a lookup join.
+	index *structured.IndexDescriptor
+
+	// The rows of a table joined using a hash or nested loop join, retrieved
+	// by joinPlan.run. For a hash join, hash maps the encoded values of the
+	// join columns to the positions of the rows.
+	rows []tableRow
+	hash map[string][]int
+}
+
+// joinPlan describes how the rows of the tables of the FROM clause are
+// retrieved and joined.
+type joinPlan struct {
+	tables []*fromTable
+	// The conjuncts of the WHERE clause which are evaluated for every joined
+	// row, i.e. those which could not be evaluated while retrieving the rows of
+	// a single table.
+	where []parser.Expr
+}
+
+// resolveFrom resolves the tables of the FROM clause of a SELECT statement in
+// the order they are joined. The tables of a list are cross joined. Joins are
+// evaluated from left to right, so the right side of a join must be a single
+// table.
+func (s *session) resolveFrom(from parser.TableExprs) ([]*fromTable, error) {
+	var tables []*fromTable
+	var add func(e parser.TableExpr, typ joinType, cond parser.JoinCond) error
+	add = func(e parser.TableExpr, typ joinType, cond parser.JoinCond) error {
+		switch t := e.(type) {
+		case *parser.AliasedTableExpr:
+			name, ok := t.Expr.(*parser.TableName)
+			if !ok {
+				return fmt.Errorf("unsupported FROM: %s", from)
+			}
+			desc, err := s.lookupTableDesc(name)
+			if err != nil {
+				return err
+			}
+			if err := s.checkTablePrivilege(name, desc, structured.SelectPrivilege); err != nil {
+				return err
+			}
+			var view *viewSource
+			if desc.IsView() {
+				if desc, view, err = s.expandView(desc); err != nil {
+					return err
+				}
+			}
+			alias := t.As
+			if alias == "" {
+				alias = name.Name
+			}
+			for _, o := range tables {
+				if strings.EqualFold(o.alias, alias) {
+					return fmt.Errorf("table name \"%s\" specified more than once", alias)
+				}
+			}
+			tables = append(tables, &fromTable{desc: desc, alias: alias, typ: typ, view: view,
+				user: s.user})
+			switch c := cond.(type) {
+			case *parser.OnJoinCond:
+				tables[len(tables)-1].on = c.Expr
+			case *parser.UsingJoinCond:
+				if tables[len(tables)-1].on, err = usingExpr(tables, c.Cols); err != nil {
+					return err
+				}
+			}
+			return nil
+
+		case *parser.ParenTableExpr:
+			return add(t.Expr, typ, cond)
+
+		case *parser.JoinTableExpr:
+			// A join on the right side of a cross join can be evaluated after
+			// the cross join, but not on the right side of any other join.
+			if typ != innerJoin || cond != nil {
+				return fmt.Errorf("unsupported JOIN: %s", e)
+			}
+			if err := add(t.LeftExpr, innerJoin, nil); err != nil {
+				return err
+			}
+			switch t.Join {
+			case "JOIN", "STRAIGHT_JOIN", "CROSS JOIN":
+				typ = innerJoin
+			case "LEFT JOIN":
+				typ = leftJoin
+			default:
+				return fmt.Errorf("unsupported JOIN: %s", e)
+			}
+			return add(t.RightExpr, typ, t.Cond)
+		}
+		return fmt.Errorf("unsupported FROM: %s", from)
+	}
+
+	for _, e := range from {
+		if err := add(e, innerJoin, nil); err != nil {
+			return nil, err
+		}
+	}
+	return tables, nil
+}
+
+// usingExpr returns the join condition equivalent to the USING clause of the
+// join between the last table and the tables preceding it: the named columns
+// of the last table are equal to the columns of the same name of the first
+// preceding table containing them.
+func usingExpr(tables []*fromTable, cols parser.Columns) (parser.Expr, error) {
+	right := tables[len(tables)-1]
+	var result parser.BoolExpr
+	for _, c := range cols {
+		nse, ok := c.(*parser.NonStarExpr)
+		if !ok {
+			return nil, fmt.Errorf("invalid USING column: %s", c)
+		}
+		name, ok := nse.Expr.(*parser.ColName)
+		if !ok {
+			return nil, fmt.Errorf("invalid USING column: %s", c)
+		}
+		if !hasColumn(right.desc, name.Name) {
+			return nil, fmt.Errorf("column \"%s\" specified in USING clause does not exist in right table", name.Name)
+		}
+		var left *fromTable
+		for _, t := range tables[:len(tables)-1] {
+			if hasColumn(t.desc, name.Name) {
+				left = t
+				break
+			}
+		}
+		if left == nil {
+			return nil, fmt.Errorf("column \"%s\" specified in USING clause does not exist in left table", name.Name)
+		}
+		eq := &parser.ComparisonExpr{
+			Operator: "=",
+			Left:     &parser.ColName{Name: name.Name, Qualifier: left.alias},
+			Right:    &parser.ColName{Name: name.Name, Qualifier: right.alias},
+		}
+		if result == nil {
+			result = eq
+		} else {
+			result = &parser.AndExpr{Op: "AND", Left: result, Right: eq}
+		}
+	}
+	return result, nil
+}
+
+// hasColumn returns true if the table has a column with the name.
+func hasColumn(desc *structured.TableDescriptor, name string) bool {
+	_, err := desc.FindColumnByName(strings.ToLower(name))
+	return err == nil
+}
+
+// joinEnv implements the env interface for a row produced by joining the rows
+// of several tables. The row of a table which did not match a LEFT JOIN is
+// nil, in which case the values of its columns are NULL.
+type joinEnv []*tableEnv
+
+func (e joinEnv) get(name *parser.ColName) (driver.Value, error) {
+	i, err := e.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return e[i].get(name)
+}
+
+// resolve returns the position of the table containing the referenced column.
+// An unqualified name must match a column of exactly one table.
+func (e joinEnv) resolve(name *parser.ColName) (int, error) {
+	found := -1
+	for i, t := range e {
+		if name.Qualifier != "" {
+			if strings.EqualFold(name.Qualifier, t.alias) {
+				return i, nil
+			}
+			continue
+		}
+		if hasColumn(t.desc, name.Name) {
+			if found != -1 {
+				return -1, fmt.Errorf("column reference \"%s\" is ambiguous", name.Name)
+			}
+			found = i
+		}
+	}
+	if found == -1 {
+		if name.Qualifier != "" {
+			return -1, fmt.Errorf("unknown table \"%s\" for column \"%s\"", name.Qualifier, name.Name)
+		}
+		return -1, fmt.Errorf("column \"%s\" does not exist", name.Name)
+	}
+	return found, nil
+}
+
+// tablesEnv returns the joinEnv used to resolve the columns of the tables
+// before any rows are retrieved.
+func tablesEnv(tables []*fromTable) joinEnv {
+	env := make(joinEnv, len(tables))
+	for i, t := range tables {
+		env[i] = &tableEnv{desc: t.desc, alias: t.alias}
+	}
+	return env
+}
+
+// exprTables returns the positions of the tables whose columns are referenced
+// by the expression. False is returned if the references cannot be
+// determined.
+func exprTables(tables joinEnv, e parser.Expr) (map[int]struct{}, bool) {
+	refs := map[int]struct{}{}
+	found := true
+	known := walkExpr(e, func(e parser.Expr) bool {
+		if n, ok := e.(*parser.ColName); ok {
+			i, err := tables.resolve(n)
+			if err != nil {
+				found = false
+				return false
+			}
+			refs[i] = struct{}{}
+		}
+		return true
+	})
+	return refs, known && found
+}
+
+// onlyTables returns true if every table in refs precedes the table at
+// position end.
+func onlyTables(refs map[int]struct{}, end int) bool {
+	for i := range refs {
+		if i >= end {
+			return false
+		}
+	}
+	return true
+}
+
+// planJoins chooses how the rows of each table of the FROM clause are
+// retrieved and joined to the rows of the tables preceding it, using the
+// conjuncts of the WHERE clause and of the join conditions:
+//
+//   - The conjuncts which only reference a single table narrow the scan of
+//     the table (see makeScanPlan). The conjuncts of the WHERE clause are
+//     not used for a table on the right side of a LEFT JOIN, as its rows
+//     which do not match the WHERE clause still match the join.
+//   - A table is joined using a lookup join if the first column of one of its
+//     indexes is equal to an expression over the columns of the preceding
+//     tables. The index is scanned for every row of the preceding tables.
+//   - Otherwise a table is joined using a hash join if any of its columns are
+//     equal to expressions over the columns of the preceding tables.
+//   - Otherwise every row of the table is considered for every row of the
+//     preceding tables.
+//
+// needed holds the IDs of the columns of the first table used by the
+// statement; nil indicates that all of the columns are needed.
+func planJoins(tables []*fromTable, where parser.Expr, needed map[uint32]struct{},
+	args []driver.Value, loc *time.Location) (*joinPlan, error) {
+	env := tablesEnv(tables)
+	p := &joinPlan{tables: tables}
+
+	// The conjuncts of the WHERE clause which can be used as join conditions.
+	var conds []parser.Expr
+	for _, c := range splitAndExpr(where, nil) {
+		refs, ok := exprTables(env, c)
+		switch {
+		case !ok:
+			p.where = append(p.where, c)
+		case len(refs) == 0:
+			tables[0].filter = append(tables[0].filter, c)
+		case len(refs) == 1 && !isOuter(tables, refs):
+			for i := range refs {
+				tables[i].filter = append(tables[i].filter, c)
+			}
+		default:
+			p.where = append(p.where, c)
+			conds = append(conds, c)
+		}
+	}
+
+	for i, t := range tables {
+		cols := needed
+		if i > 0 {
+			cols = nil
+			for _, c := range splitAndExpr(t.on, nil) {
+				refs, ok := exprTables(env[:i+1], c)
+				if ok && len(refs) == 1 && onlyTables(refs, i+1) && !onlyTables(refs, i) {
+					t.filter = append(t.filter, c)
+					continue
+				}
+				t.cond = append(t.cond, c)
+			}
+			candidates := append([]parser.Expr(nil), t.cond...)
+			if t.typ == innerJoin {
+				for _, c := range conds {
+					if refs, ok := exprTables(env, c); ok && onlyTables(refs, i+1) {
+						candidates = append(candidates, c)
+					}
+				}
+			}
+			for _, c := range candidates {
+				if col, expr, ok := joinEquality(env, i, c); ok {
+					t.eqConds = append(t.eqConds, c)
+					t.eqCols = append(t.eqCols, col)
+					t.eqExprs = append(t.eqExprs, expr)
+				}
+			}
+			t.strategy = chooseJoinStrategy(t)
+			if t.strategy == lookupJoin {
+				continue
+			}
+		}
+		var err error
+		if t.plan, err = makeScanPlan(t.desc, t.alias, t.filter, cols, nil, args, loc); err != nil {
+			return nil, err
+		}
+		t.plan.view = t.view
+		t.plan.user = t.user
+	}
+	return p, nil
+}
+
+// isOuter returns true if any of the tables in refs is on the right side of
+// a LEFT JOIN.
+func isOuter(tables []*fromTable, refs map[int]struct{}) bool {
+	for i := range refs {
+		if i > 0 && tables[i].typ == leftJoin {
+			return true
+		}
+	}
+	return false
+}
+
+// joinEquality returns the position of the column and the expression if the
+// conjunct is of the form "<col> = <expr>" (or "<expr> = <col>") where col is
+// a column of the table at position i and expr only references the columns
+// of the preceding tables.
+func joinEquality(env joinEnv, i int, c parser.Expr) (int, parser.Expr, bool) {
+	cmp, ok := c.(*parser.ComparisonExpr)
+	if !ok || cmp.Operator != "=" {
+		return 0, nil, false
+	}
+	for _, x := range []struct{ col, expr parser.Expr }{
+		{cmp.Left, cmp.Right},
+		{cmp.Right, cmp.Left},
+	} {
+		name, ok := x.col.(*parser.ColName)
+		if !ok {
+			continue
+		}
+		if t, err := env.resolve(name); err != nil || t != i {
+			continue
+		}
+		refs, ok := exprTables(env, x.expr)
+		if !ok || len(refs) == 0 || !onlyTables(refs, i) {
+			continue
+		}
+		col, err := env[i].desc.FindColumnByName(strings.ToLower(name.Name))
+		if err != nil {
+			continue
+		}
+		for j := range env[i].desc.Columns {
+			if env[i].desc.Columns[j].ID == col.ID {
+				return j, x.expr, true
+			}
+		}
+	}
+	return 0, nil, false
+}
+
+// chooseJoinStrategy chooses the strategy for joining the table to the
+// preceding tables, setting the index scanned by a lookup join. As with
+// makeScanPlan, a secondary index is only used if every one of its columns
+// rejects NULL.
+func chooseJoinStrategy(t *fromTable) joinStrategy {
+	if len(t.eqCols) == 0 {
+		return nestedLoopJoin
+	}
+	for i := range t.desc.Indexes {
+		index := &t.desc.Indexes[i]
+		if len(index.ColumnIDs) == 0 || !index.IsPublic() {
+			// The primary index of a view, or an index which is being
+			// backfilled.
+			continue
+		}
+		if i > 0 {
+			// See makeScanPlan.
+			conjuncts := append(append([]parser.Expr(nil), t.filter...), t.eqConds...)
+			if !rejectsNulls(t.desc, index, t.alias, conjuncts) {
+				continue
+			}
+		}
+		for _, j := range t.eqCols {
+			if t.desc.Columns[j].ID == index.ColumnIDs[0] {
+				t.index = index
+				return lookupJoin
+			}
+		}
+	}
+	return hashJoin
+}
+
+// run retrieves the rows of the tables and calls fn for every joined row
+// matching the join conditions and the WHERE clause. For every row of the
+// first table the matching rows of the next table are found, and so on, so
+// that the joined rows are produced one at a time. Only the rows of the
+// tables joined using hash or nested loop joins are retrieved up front. The
+// env passed to fn is a *queryEnv wrapping a *tableEnv if there is a single
+// table and a joinEnv otherwise.
+func (p *joinPlan) run(q *queryContext, fn func(e env) error) error {
+	db, args, loc := q.db, q.args, q.s.timeLocation()
+	for _, t := range p.tables[1:] {
+		if t.strategy == lookupJoin {
+			continue
+		}
+		var err error
+		if t.rows, err = filterRows(db, t.plan, t.alias, t.filter, args, loc); err != nil {
+			return err
+		}
+		if t.strategy == hashJoin {
+			t.hash = map[string][]int{}
+			for i := range t.rows {
+				vals := make([]driver.Value, len(t.eqCols))
+				for j, col := range t.eqCols {
+					vals[j] = t.rows[i].vals[col]
+				}
+				key, ok, err := encodeJoinKey(vals)
+				if err != nil {
+					return err
+				}
+				if ok {
+					t.hash[string(key)] = append(t.hash[string(key)], i)
+				}
+			}
+		}
+	}
+
+	first := p.tables[0]
+	tableRows, err := filterRows(db, first.plan, first.alias, first.filter, args, loc)
+	if err != nil {
+		return err
+	}
+	cur := make(joinEnv, len(p.tables))
+	for i := range tableRows {
+		cur[0] = &tableEnv{desc: first.desc, alias: first.alias, row: &tableRows[i]}
+		if err := p.join(q, 1, cur, fn); err != nil {
+			if err == errStopIteration {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// join finds the rows of the table at position i matching the rows of the
+// preceding tables in cur, recursing to join the following tables.
+func (p *joinPlan) join(q *queryContext, i int, cur joinEnv, fn func(e env) error) error {
+	if i == len(p.tables) {
+		var e env = cur[0]
+		if len(cur) > 1 {
+			e = append(joinEnv(nil), cur...)
+		}
+		e = q.env(e)
+		if ok, err := evalConjuncts(p.where, e, q.args, q.s.timeLocation()); err != nil || !ok {
+			return err
+		}
+		return fn(e)
+	}
+
+	t := p.tables[i]
+	tableRows, err := t.candidates(q.db, cur[:i], q.args, q.s.timeLocation())
+	if err != nil {
+		return err
+	}
+
+	matched := false
+	for j := range tableRows {
+		cur[i] = &tableEnv{desc: t.desc, alias: t.alias, row: &tableRows[j]}
+		ok, err := evalConjuncts(t.cond, q.env(cur[:i+1]), q.args, q.s.timeLocation())
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		matched = true
+		if err := p.join(q, i+1, cur, fn); err != nil {
+			return err
+		}
+	}
+	if !matched && t.typ == leftJoin {
+		cur[i] = &tableEnv{desc: t.desc, alias: t.alias}
+		return p.join(q, i+1, cur, fn)
+	}
+	return nil
+}
+
+// candidates returns the rows of the table which might match the row of the
+// preceding tables provided by outer.
+func (t *fromTable) candidates(db scanner, outer joinEnv, args []driver.Value, loc *time.Location) ([]tableRow, error) {
+	if t.strategy == nestedLoopJoin {
+		return t.rows, nil
+	}
+
+	vals := make([]driver.Value, len(t.eqExprs))
+	for i, e := range t.eqExprs {
+		v, err := evalExpr(e, outer, args, loc)
+		if err != nil {
+			return nil, err
+		}
+		if v == nil {
+			// NULL is not equal to any value.
+			return nil, nil
+		}
+		vals[i] = v
+	}
+
+	if t.strategy == lookupJoin {
+		conjuncts := append(append([]parser.Expr(nil), t.filter...), t.eqConds...)
+		plan, err := makeScanPlan(t.desc, t.alias, conjuncts, nil, outer, args, loc)
+		if err != nil {
+			return nil, err
+		}
+		plan.user = t.user
+		return filterRows(db, plan, t.alias, t.filter, args, loc)
+	}
+
+	// The values are converted to the types of the columns so that they
+	// hash the same as the column values. Values which cannot be converted
+	// are compared with every row.
+	for i, v := range vals {
+		var err error
+		if vals[i], err = checkColumnValue(t.desc.Columns[t.eqCols[i]], v); err != nil {
+			return t.rows, nil
+		}
+	}
+	key, _, err := encodeJoinKey(vals)
+	if err != nil {
+		return nil, err
+	}
+	matches := t.hash[string(key)]
+	tableRows := make([]tableRow, len(matches))
+	for i, j := range matches {
+		tableRows[i] = t.rows[j]
+	}
+	return tableRows, nil
+}
+
+// encodeJoinKey encodes the values of the join columns for hashing. False is
+// returned if any of the values is NULL.
+func encodeJoinKey(vals []driver.Value) ([]byte, bool, error) {
+	var key []byte
+	for _, v := range vals {
+		if v == nil {
+			return nil, false, nil
+		}
+		var err error
+		if key, err = encodeGroupKey(key, v); err != nil {
+			return nil, false, err
+		}
+	}
+	return key, true, nil
+}
+
+// explain returns the steps performed to retrieve and join the rows of the
+// tables as rows of EXPLAIN output.
+func (p *joinPlan) explain() []row {
+	var steps []row
+	for i, t := range p.tables {
+		if t.strategy != lookupJoin {
+			steps = append(steps, t.plan.explain()...)
+			if len(t.filter) > 0 {
+				steps = append(steps, row{"filter", formatConjuncts(t.filter)})
+			}
+		}
+		if i == 0 {
+			continue
+		}
+		join := "JOIN"
+		if t.typ == leftJoin {
+			join = "LEFT JOIN"
+		}
+		var typ, description string
+		switch t.strategy {
+		case lookupJoin:
+			typ = "lookup-join"
+			description = fmt.Sprintf("%s %s@%s", join, t.desc.Name, t.index.Name)
+		case hashJoin:
+			typ = "hash-join"
+			description = fmt.Sprintf("%s %s", join, t.desc.Name)
+		default:
+			typ = "nested-loop-join"
+			description = fmt.Sprintf("%s %s", join, t.desc.Name)
+		}
+		if len(t.cond) > 0 {
+			description += " ON " + formatConjuncts(t.cond)
+		}
+		steps = append(steps, row{typ, description})
+		if t.strategy == lookupJoin && len(t.filter) > 0 {
+			steps = append(steps, row{"filter", formatConjuncts(t.filter)})
+		}
+	}
+	if len(p.where) > 0 {
+		steps = append(steps, row{"filter", formatConjuncts(p.where)})
+	}
+	return steps
+}
+
+// formatConjuncts formats the conjuncts of an expression for EXPLAIN.
+func formatConjuncts(conjuncts []parser.Expr) string {
+	strs := make([]string, len(conjuncts))
+	for i, c := range conjuncts {
+		if _, ok := c.(*parser.OrExpr); ok {
+			strs[i] = fmt.Sprintf("(%s)", c)
+		} else {
+			strs[i] = fmt.Sprintf("%s", c)
+		}
+	}
+	return strings.Join(strs, " AND ")
+}
@@ -0,0 +1,102 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package sqlserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/sql/parser"
+	"github.com/cockroachdb/cockroach/structured"
+	"github.com/cockroachdb/cockroach/util/leaktest"
+)
+
+func TestPlanJoins(t *testing.T) {
+	defer leaktest.AfterTest(t)
+
+	makeDesc := func(sql string) *structured.TableDescriptor {
+		stmt, err := parser.Parse(sql)
+		if err != nil {
+			t.Fatal(err)
+		}
+		schema, err := makeSchema(stmt.(*parser.CreateTable))
+		if err != nil {
+			t.Fatal(err)
+		}
+		desc := structured.TableDescFromSchema(schema)
+		if err := structured.ValidateTableDesc(desc); err != nil {
+			t.Fatal(err)
+		}
+		return &desc
+	}
+	a := makeDesc(`CREATE TABLE a (k INT PRIMARY KEY, v INT)`)
+	b := makeDesc(`CREATE TABLE b (k INT PRIMARY KEY, w INT, x INT, INDEX foo (x))`)
+
+	testData := []struct {
+		typ      joinType
+		on       string
+		where    string
+		strategy joinStrategy
+		filters  [2]int // The number of filter conjuncts of each table.
+		cond     int    // The number of join condition conjuncts.
+		residual int    // The number of WHERE conjuncts evaluated for every joined row.
+	}{
+		{innerJoin, "", "", nestedLoopJoin, [2]int{0, 0}, 0, 0},
+		{innerJoin, "a.k = b.k", "", lookupJoin, [2]int{0, 0}, 1, 0},
+		{innerJoin, "a.v = b.x", "", lookupJoin, [2]int{0, 0}, 1, 0},
+		{innerJoin, "a.v = b.w", "", hashJoin, [2]int{0, 0}, 1, 0},
+		{innerJoin, "", "a.v = b.w AND v > 1 AND w > 2", hashJoin, [2]int{1, 1}, 0, 1},
+		{innerJoin, "a.v < b.w", "", nestedLoopJoin, [2]int{0, 0}, 1, 0},
+		{leftJoin, "a.v = b.w AND b.w > 2", "w > 3 AND v > 1", hashJoin, [2]int{1, 1}, 1, 1},
+		{leftJoin, "", "a.v = b.w", nestedLoopJoin, [2]int{0, 0}, 0, 1},
+	}
+	for i, d := range testData {
+		tables := []*fromTable{
+			{desc: a, alias: "a"},
+			{desc: b, alias: "b", typ: d.typ},
+		}
+		var err error
+		if d.on != "" {
+			if tables[1].on, err = parser.ParseExpr(d.on); err != nil {
+				t.Fatalf("%d: %v", i, err)
+			}
+		}
+		var where parser.Expr
+		if d.where != "" {
+			if where, err = parser.ParseExpr(d.where); err != nil {
+				t.Fatalf("%d: %v", i, err)
+			}
+		}
+		plan, err := planJoins(tables, where, nil, nil, time.UTC)
+		if err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+		if s := tables[1].strategy; s != d.strategy {
+			t.Errorf("%d: expected strategy %d, but found %d", i, d.strategy, s)
+		}
+		for j, n := range d.filters {
+			if len(tables[j].filter) != n {
+				t.Errorf("%d: expected %d filters of %s, but found %q", i, n, tables[j].alias, tables[j].filter)
+			}
+		}
+		if len(tables[1].cond) != d.cond {
+			t.Errorf("%d: expected %d join conditions, but found %q", i, d.cond, tables[1].cond)
+		}
+		if len(plan.where) != d.residual {
+			t.Errorf("%d: expected %d WHERE conjuncts, but found %q", i, d.residual, plan.where)
+		}
+	}
+}
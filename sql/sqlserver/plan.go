@@ -0,0 +1,514 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package sqlserver
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/client"
+	"github.com/cockroachdb/cockroach/proto"
+	"github.com/cockroachdb/cockroach/sql/parser"
+	"github.com/cockroachdb/cockroach/structured"
+	"github.com/cockroachdb/cockroach/util/log"
+)
+
+// scanPlan describes how the rows of a table which might match a WHERE
+// clause are retrieved: the index which is scanned, the span of the index
+// and whether the rows have to be looked up in the primary index using the
+// primary keys found in a secondary index. The WHERE clause must still be
+// evaluated for every row which is retrieved.
+type scanPlan struct {
+	desc  *structured.TableDescriptor
+	index *structured.IndexDescriptor
+	span  span
+	// The number of leading index columns constrained to a single value.
+	exact int
+	// Set if the index column following the exactly constrained columns is
+	// constrained to a range of values.
+	ranged bool
+	// The constraints used to narrow the span, for EXPLAIN.
+	constraints []string
+	// Set if the index is a secondary index which does not contain all of the
+	// columns needed by the statement.
+	indexJoin bool
+	// The source of the rows of a view, nil for a table.
+	view *viewSource
+	// The user of the session, for whom the rows of an information_schema
+	// table are generated.
+	user string
+}
+
+// makeScanPlan chooses the index to scan in order to retrieve the rows of the
+// table matching the conjuncts of the WHERE clause. The table is referenced by
+// alias in the conjuncts. The values of the columns of other tables, such as
+// the tables preceding the table in a join, are provided by outer, which may
+// be nil. needed holds the IDs of the columns whose values are used by the
+// statement; nil indicates that all of the columns are needed. The index is
+// chosen using the following rules, in order:
+//
+//   - Prefer an index for which every column of a unique index is constrained
+//     to a single value, as at most one row is retrieved.
+//   - Prefer the index with the most leading columns constrained to a single
+//     value.
+//   - Prefer an index with a range constraint on the column following those.
+//   - Prefer an index which contains all of the needed columns.
+//   - Prefer the primary index.
+//
+// A secondary index is only considered if one of its columns is constrained
+// and every one of its columns is rejected by a conjunct when NULL, because
+// the rows containing a NULL value in any of the indexed columns are not
+// present in it.
+func makeScanPlan(desc *structured.TableDescriptor, alias string, conjuncts []parser.Expr,
+	needed map[uint32]struct{}, outer env, args []driver.Value, loc *time.Location) (*scanPlan, error) {
+	var best *scanPlan
+	for i := range desc.Indexes {
+		if !desc.Indexes[i].IsPublic() {
+			continue
+		}
+		p, err := makeIndexScanPlan(desc, alias, &desc.Indexes[i], conjuncts, outer, args, loc)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			if p.exact == 0 && !p.ranged {
+				continue
+			}
+			if !rejectsNulls(desc, p.index, alias, conjuncts) {
+				continue
+			}
+			p.indexJoin = !indexCovers(desc, p.index, needed)
+		}
+		if best == nil || p.betterThan(best) {
+			best = p
+		}
+	}
+	return best, nil
+}
+
+// makeIndexScanPlan computes the span of the index that needs to be scanned
+// in order to find all of the rows matching the conjuncts of the WHERE
+// clause. The span is narrowed using constraints on a prefix of the index
+// columns: equality constraints on the leading columns, optionally followed by
+// a range constraint on the next column.
+func makeIndexScanPlan(desc *structured.TableDescriptor, alias string,
+	index *structured.IndexDescriptor, conjuncts []parser.Expr, outer env,
+	args []driver.Value, loc *time.Location) (*scanPlan, error) {
+	prefix := proto.Key(encodeIndexKeyPrefix(desc.ID, index.ID))
+	p := &scanPlan{
+		desc:  desc,
+		index: index,
+		span:  span{start: prefix, end: prefix.PrefixEnd()},
+	}
+
+	for _, id := range index.ColumnIDs {
+		col, err := desc.FindColumnByID(id)
+		if err != nil {
+			return nil, err
+		}
+
+		// Look for an equality constraint on the column, in which case the
+		// column value is appended to the prefix and we continue with the next
+		// index column.
+		if v, ok := findConstraint(conjuncts, alias, col, "=", outer, args, loc); ok {
+			if prefix, err = encodeTableKey(prefix, v); err != nil {
+				return nil, err
+			}
+			p.span = span{start: prefix, end: prefix.PrefixEnd()}
+			p.exact++
+			p.constraints = append(p.constraints, formatConstraint(col, "=", v))
+			continue
+		}
+
+		// Look for range constraints on the column.
+		if v, ok := findConstraint(conjuncts, alias, col, ">=", outer, args, loc); ok {
+			if p.span.start, err = encodeTableKey(append(proto.Key(nil), prefix...), v); err != nil {
+				return nil, err
+			}
+			p.ranged = true
+			p.constraints = append(p.constraints, formatConstraint(col, ">=", v))
+		} else if v, ok := findConstraint(conjuncts, alias, col, ">", outer, args, loc); ok {
+			k, err := encodeTableKey(append(proto.Key(nil), prefix...), v)
+			if err != nil {
+				return nil, err
+			}
+			p.span.start = proto.Key(k).PrefixEnd()
+			p.ranged = true
+			p.constraints = append(p.constraints, formatConstraint(col, ">", v))
+		}
+		if v, ok := findConstraint(conjuncts, alias, col, "<", outer, args, loc); ok {
+			if p.span.end, err = encodeTableKey(append(proto.Key(nil), prefix...), v); err != nil {
+				return nil, err
+			}
+			p.ranged = true
+			p.constraints = append(p.constraints, formatConstraint(col, "<", v))
+		} else if v, ok := findConstraint(conjuncts, alias, col, "<=", outer, args, loc); ok {
+			k, err := encodeTableKey(append(proto.Key(nil), prefix...), v)
+			if err != nil {
+				return nil, err
+			}
+			p.span.end = proto.Key(k).PrefixEnd()
+			p.ranged = true
+			p.constraints = append(p.constraints, formatConstraint(col, "<=", v))
+		}
+		break
+	}
+	return p, nil
+}
+
+// isPrimary returns true if the plan scans the primary index.
+func (p *scanPlan) isPrimary() bool {
+	return p.index.ID == p.desc.Indexes[0].ID
+}
+
+// isPointLookup returns true if every column of a unique index is constrained
+// to a single value.
+func (p *scanPlan) isPointLookup() bool {
+	return (p.index.Unique || p.isPrimary()) && p.exact == len(p.index.ColumnIDs)
+}
+
+// betterThan returns true if the plan is preferred over the other plan
+// according to the rules described by makeScanPlan.
+func (p *scanPlan) betterThan(o *scanPlan) bool {
+	if a, b := p.isPointLookup(), o.isPointLookup(); a != b {
+		return a
+	}
+	if p.exact != o.exact {
+		return p.exact > o.exact
+	}
+	if p.ranged != o.ranged {
+		return p.ranged
+	}
+	return !p.indexJoin && o.indexJoin
+}
+
+// indexCovers returns true if the secondary index contains the values of all
+// of the needed columns. Every entry of a secondary index contains the values
+// of the indexed columns in its key and the primary key of the row as its
+// value.
+func indexCovers(desc *structured.TableDescriptor, index *structured.IndexDescriptor,
+	needed map[uint32]struct{}) bool {
+	covers := func(id uint32) bool {
+		return index.ContainsColumnID(id) || desc.Indexes[0].ContainsColumnID(id)
+	}
+	if needed == nil {
+		for _, col := range desc.Columns {
+			if !covers(col.ID) {
+				return false
+			}
+		}
+		return true
+	}
+	for id := range needed {
+		if !covers(id) {
+			return false
+		}
+	}
+	return true
+}
+
+// scan retrieves the rows within the span of the index in primary key order.
+// If the index is a secondary index which contains all of the needed columns,
+// the values of the columns which are not part of the index are NULL.
+func (p *scanPlan) scan(db scanner) ([]tableRow, error) {
+	if !p.span.start.Less(p.span.end) {
+		// The constraints are contradictory.
+		return nil, nil
+	}
+	if vt, ok := virtualTablesByID[p.desc.ID]; ok {
+		return vt.scan(db, p)
+	}
+	if p.view != nil {
+		return p.view.scan(db, p)
+	}
+	if log.V(2) {
+		log.Infof("Scan %q - %q", p.span.start, p.span.end)
+	}
+	kvs, err := db.Scan(p.span.start, p.span.end, 0)
+	if err != nil {
+		return nil, err
+	}
+	if p.isPrimary() {
+		return decodeTableRows(p.desc, kvs)
+	}
+
+	var tableRows []tableRow
+	if !p.indexJoin {
+		if tableRows, err = decodeIndexRows(p.desc, p.index, kvs); err != nil {
+			return nil, err
+		}
+	} else if len(kvs) > 0 {
+		// Retrieve the rows from the primary index. The value of each index
+		// entry is the prefix of the keys of the row.
+		b := &client.Batch{}
+		for _, kv := range kvs {
+			primaryKey := proto.Key(kv.ValueBytes())
+			if log.V(2) {
+				log.Infof("Scan %q - %q", primaryKey, primaryKey.PrefixEnd())
+			}
+			b.Scan(primaryKey, primaryKey.PrefixEnd(), 0)
+		}
+		if err := db.Run(b); err != nil {
+			return nil, err
+		}
+		var rowKVs []client.KeyValue
+		for _, result := range b.Results {
+			rowKVs = append(rowKVs, result.Rows...)
+		}
+		if tableRows, err = decodeTableRows(p.desc, rowKVs); err != nil {
+			return nil, err
+		}
+	}
+
+	// Return the rows in primary key order, the same as a scan of the primary
+	// index.
+	sort.Sort(tableRowsByKey(tableRows))
+	return tableRows, nil
+}
+
+// explain returns the steps performed by the plan as rows of EXPLAIN output.
+func (p *scanPlan) explain() []row {
+	if p.view != nil {
+		return []row{{"view", p.desc.Name}}
+	}
+	description := fmt.Sprintf("%s@%s", p.desc.Name, p.index.Name)
+	if len(p.constraints) > 0 {
+		description += ": " + strings.Join(p.constraints, " AND ")
+	}
+	steps := []row{{"scan", description}}
+	if p.indexJoin {
+		steps = append(steps, row{"index-join", fmt.Sprintf("%s@%s", p.desc.Name, p.desc.Indexes[0].Name)})
+	}
+	return steps
+}
+
+// tableRowsByKey sorts table rows by primary key.
+type tableRowsByKey []tableRow
+
+func (r tableRowsByKey) Len() int {
+	return len(r)
+}
+
+func (r tableRowsByKey) Swap(i, j int) {
+	r[i], r[j] = r[j], r[i]
+}
+
+func (r tableRowsByKey) Less(i, j int) bool {
+	return bytes.Compare(r[i].key, r[j].key) < 0
+}
+
+// decodeIndexRows decodes the key/value pairs from a scan of a secondary
+// index into rows containing the values of the indexed columns and of the
+// primary key columns. The values of the remaining columns are NULL.
+func decodeIndexRows(desc *structured.TableDescriptor, index *structured.IndexDescriptor,
+	kvs []client.KeyValue) ([]tableRow, error) {
+	colMap := columnIndexMap(desc)
+	primaryIndex := desc.Indexes[0]
+	indexKey := encodeIndexKeyPrefix(desc.ID, index.ID)
+	primaryIndexKey := encodeIndexKeyPrefix(desc.ID, primaryIndex.ID)
+
+	tableRows := make([]tableRow, 0, len(kvs))
+	for _, kv := range kvs {
+		row := tableRow{
+			key:  kv.ValueBytes(),
+			vals: make([]driver.Value, len(desc.Columns)),
+		}
+		if err := decodeIndexKey(desc, *index, colMap, indexKey, kv.Key, row.vals); err != nil {
+			return nil, err
+		}
+		if err := decodeIndexKey(desc, primaryIndex, colMap, primaryIndexKey, row.key, row.vals); err != nil {
+			return nil, err
+		}
+		tableRows = append(tableRows, row)
+	}
+	return tableRows, nil
+}
+
+// decodeIndexKey decodes the values of the index columns from the key, storing
+// them in vals. colMap maps from column ID to the position of the column's
+// value within vals.
+func decodeIndexKey(desc *structured.TableDescriptor, index structured.IndexDescriptor,
+	colMap map[uint32]int, indexKey, key []byte, vals []driver.Value) error {
+	if !bytes.HasPrefix(key, indexKey) {
+		return fmt.Errorf("%s: invalid key prefix: %q", desc.Name, key)
+	}
+	remaining := key[len(indexKey):]
+	for _, id := range index.ColumnIDs {
+		i, ok := colMap[id]
+		if !ok {
+			return fmt.Errorf("missing column %d of index \"%s\"", id, index.Name)
+		}
+		var err error
+		if remaining, vals[i], err = decodeTableKey(remaining, desc.Columns[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitAndExpr appends the conjuncts of the expression to exprs.
+func splitAndExpr(e parser.Expr, exprs []parser.Expr) []parser.Expr {
+	switch t := e.(type) {
+	case nil:
+		return exprs
+	case *parser.AndExpr:
+		return splitAndExpr(t.Right, splitAndExpr(t.Left, exprs))
+	case *parser.ParenBoolExpr:
+		return splitAndExpr(t.Expr, exprs)
+	}
+	return append(exprs, e)
+}
+
+// findConstraint looks for a conjunct of the form "<col> <op> <constant>" (or
+// the equivalent "<constant> <op'> <col>"), returning the constant converted
+// to the column type. The bounds of "<col> BETWEEN <constant> AND <constant>"
+// are found as the ">=" and "<=" constraints. The constant may reference the
+// columns provided by outer.
+func findConstraint(conjuncts []parser.Expr, alias string, col *structured.ColumnDescriptor,
+	op string, outer env, args []driver.Value, loc *time.Location) (driver.Value, bool) {
+	// The operator to look for if the column appears on the right hand side.
+	flipped := map[string]string{
+		"=": "=", "<": ">", "<=": ">=", ">": "<", ">=": "<=",
+	}[op]
+
+	for _, e := range conjuncts {
+		var constExpr parser.Expr
+		switch c := e.(type) {
+		case *parser.ComparisonExpr:
+			if isColumn(c.Left, alias, col) && c.Operator == op {
+				constExpr = c.Right
+			} else if isColumn(c.Right, alias, col) && c.Operator == flipped {
+				constExpr = c.Left
+			}
+		case *parser.RangeCond:
+			if c.Operator != "BETWEEN" || !isColumn(c.Left, alias, col) {
+				break
+			}
+			switch op {
+			case ">=":
+				constExpr = c.From
+			case "<=":
+				constExpr = c.To
+			}
+		}
+		if constExpr == nil {
+			continue
+		}
+		v, err := evalExpr(constExpr, outer, args, loc)
+		if err != nil || v == nil {
+			continue
+		}
+		cv, err := checkColumnValue(*col, v)
+		if err != nil {
+			continue
+		}
+		// A value which is changed by the conversion to the column type, such
+		// as a time truncated to a DATE or a string which is not in the time
+		// zone of the session, cannot be used as a bound.
+		if c, err := compareValuesIn(cv, v, loc); err == nil && c != 0 {
+			continue
+		}
+		return cv, true
+	}
+	return nil, false
+}
+
+// rejectsNulls returns true if a row containing a NULL value in any of the
+// columns of the index cannot match the conjuncts: each column must appear
+// in a comparison other than "<=>", a BETWEEN or an IS NOT NULL check.
+func rejectsNulls(desc *structured.TableDescriptor, index *structured.IndexDescriptor,
+	alias string, conjuncts []parser.Expr) bool {
+	for _, id := range index.ColumnIDs {
+		col, err := desc.FindColumnByID(id)
+		if err != nil {
+			return false
+		}
+		if !rejectsNull(conjuncts, alias, col) {
+			return false
+		}
+	}
+	return true
+}
+
+// rejectsNull returns true if one of the conjuncts cannot be true when the
+// column is NULL.
+func rejectsNull(conjuncts []parser.Expr, alias string, col *structured.ColumnDescriptor) bool {
+	for _, e := range conjuncts {
+		switch c := e.(type) {
+		case *parser.ComparisonExpr:
+			if c.Operator != "<=>" && (isColumn(c.Left, alias, col) || isColumn(c.Right, alias, col)) {
+				return true
+			}
+		case *parser.RangeCond:
+			if c.Operator == "BETWEEN" && isColumn(c.Left, alias, col) {
+				return true
+			}
+		case *parser.NullCheck:
+			if c.Operator == "NOT NULL" && isColumn(c.Expr, alias, col) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isColumn returns true if the expression is a reference to the column of the
+// table referenced by alias.
+func isColumn(e parser.Expr, alias string, col *structured.ColumnDescriptor) bool {
+	n, ok := e.(*parser.ColName)
+	return ok && strings.ToLower(n.Name) == col.Name &&
+		(n.Qualifier == "" || strings.EqualFold(n.Qualifier, alias))
+}
+
+// formatConstraint formats a constraint on the column for EXPLAIN.
+func formatConstraint(col *structured.ColumnDescriptor, op string, v driver.Value) string {
+	var s string
+	switch t := v.(type) {
+	case string:
+		s = "'" + strings.Replace(t, "'", "''", -1) + "'"
+	case []byte:
+		s = fmt.Sprintf("x'%x'", t)
+	case time.Time:
+		s = "'" + t.Format("2006-01-02 15:04:05.999999999") + "'"
+	default:
+		s = fmt.Sprintf("%v", t)
+	}
+	return fmt.Sprintf("%s %s %s", col.Name, op, s)
+}
+
+// neededColumns adds the IDs of the columns referenced by the expression to
+// ids. False is returned if the expression contains a construct whose column
+// references cannot be determined, in which case all of the columns should be
+// considered needed.
+func neededColumns(desc *structured.TableDescriptor, e parser.Expr, ids map[uint32]struct{}) bool {
+	found := true
+	known := walkExpr(e, func(e parser.Expr) bool {
+		if n, ok := e.(*parser.ColName); ok {
+			col, err := desc.FindColumnByName(strings.ToLower(n.Name))
+			if err != nil {
+				found = false
+				return false
+			}
+			ids[col.ID] = struct{}{}
+		}
+		return true
+	})
+	return known && found
+}
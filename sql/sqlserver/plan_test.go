@@ -0,0 +1,114 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package sqlserver
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/sql/parser"
+	"github.com/cockroachdb/cockroach/structured"
+	"github.com/cockroachdb/cockroach/util/leaktest"
+)
+
+func TestMakeScanPlan(t *testing.T) {
+	defer leaktest.AfterTest(t)
+
+	stmt, err := parser.Parse(`
+CREATE TABLE t (
+  a INT,
+  b INT,
+  c INT,
+  d INT,
+  e INT,
+  PRIMARY KEY (a, b),
+  INDEX foo (c, d),
+  UNIQUE INDEX bar (d)
+)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema, err := makeSchema(stmt.(*parser.CreateTable))
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc := structured.TableDescFromSchema(schema)
+	if err := structured.ValidateTableDesc(desc); err != nil {
+		t.Fatal(err)
+	}
+
+	testData := []struct {
+		where       string
+		columns     []string // nil indicates all of the columns.
+		index       string
+		constraints []string
+		indexJoin   bool
+	}{
+		{"", nil, "primary", nil, false},
+		{"c > 1 AND d IS NOT NULL", nil, "foo", []string{"c > 1"}, true},
+		{"c > 1 AND d IS NOT NULL", []string{"a", "c"}, "foo", []string{"c > 1"}, false},
+		{"a = 1 AND b = 2 AND c = 3 AND d = 4", nil, "primary", []string{"a = 1", "b = 2"}, false},
+		{"c = 3 AND d = 4", nil, "bar", []string{"d = 4"}, true},
+		{"c = 3 AND d > 4", nil, "foo", []string{"c = 3", "d > 4"}, true},
+		{"a = 1 AND c = 3", nil, "primary", []string{"a = 1"}, false},
+		{"b = 2 AND c = 3 AND d IS NOT NULL", []string{"a", "b", "c"}, "foo", []string{"c = 3"}, false},
+		{"a > 1 AND c = 3 AND e = d", nil, "foo", []string{"c = 3"}, true},
+		{"a > 1 AND c BETWEEN 3 AND 5", nil, "primary", []string{"a > 1"}, false},
+		{"c BETWEEN 3 AND 5 AND d BETWEEN 1 AND 2", []string{"c", "d"}, "foo", []string{"c >= 3", "c <= 5"}, false},
+		// The rows with a NULL value in an indexed column are not present in a
+		// secondary index, so it cannot be used unless every one of its columns
+		// rejects NULL.
+		{"c > 1", nil, "primary", nil, false},
+		{"c = 3", []string{"a", "c"}, "primary", nil, false},
+		{"c = 3 AND d <=> 4", nil, "primary", nil, false},
+		{"c = 3 AND d IS NULL", nil, "primary", nil, false},
+		{"d IS NULL", nil, "primary", nil, false},
+		{"(b = 1 OR d = 1) AND 2 <= b", nil, "primary", nil, false},
+	}
+	for _, d := range testData {
+		var where parser.Expr
+		if d.where != "" {
+			if where, err = parser.ParseExpr(d.where); err != nil {
+				t.Fatalf("%s: %v", d.where, err)
+			}
+		}
+		var needed map[uint32]struct{}
+		if d.columns != nil {
+			needed = map[uint32]struct{}{}
+			for _, name := range d.columns {
+				col, err := desc.FindColumnByName(name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				needed[col.ID] = struct{}{}
+			}
+		}
+		plan, err := makeScanPlan(&desc, "t", splitAndExpr(where, nil), needed, nil, nil, time.UTC)
+		if err != nil {
+			t.Fatalf("%s: %v", d.where, err)
+		}
+		if plan.index.Name != d.index {
+			t.Errorf("%s: expected index %s, but found %s", d.where, d.index, plan.index.Name)
+		}
+		if !reflect.DeepEqual(d.constraints, plan.constraints) {
+			t.Errorf("%s: expected constraints %q, but found %q", d.where, d.constraints, plan.constraints)
+		}
+		if plan.indexJoin != d.indexJoin {
+			t.Errorf("%s: expected index join %t, but found %t", d.where, d.indexJoin, plan.indexJoin)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package sqlserver
+
+import (
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/client"
+	"github.com/cockroachdb/cockroach/keys"
+	"github.com/cockroachdb/cockroach/security"
+	"github.com/cockroachdb/cockroach/sql/parser"
+	"github.com/cockroachdb/cockroach/structured"
+)
+
+// Privileges are granted on databases and tables and stored in their
+// descriptors. A privilege granted on a database applies to all of the tables
+// of the database. The creator of a database or table is granted ALL on it,
+// which is required to change its schema, to drop it and to grant and revoke
+// privileges on it. Any user may create a database.
+//
+// The root and node users hold all privileges. The tables of the
+// information_schema database may be read by all users, but only list the
+// databases and tables on which the user holds a privilege. Describing a
+// table requires any privilege on the table or its database. Reading a view
+// requires the SELECT privilege on the view only, not on the tables it
+// references.
+
+// anyPrivilege is passed to checkTablePrivilege to require any privilege
+// rather than a specific one.
+const anyPrivilege structured.Privilege = 0
+
+// holdsPrivilege returns true if the user holds the privilege, or any
+// privilege for anyPrivilege.
+func holdsPrivilege(pd *structured.PrivilegeDescriptor, user string, priv structured.Privilege) bool {
+	if priv == anyPrivilege {
+		return pd.CheckAny(user)
+	}
+	return pd.Check(user, priv)
+}
+
+// isSuperuser returns true if the user holds all privileges on all databases
+// and tables.
+func isSuperuser(user string) bool {
+	return user == security.RootUser || user == security.NodeUser
+}
+
+// checkDatabasePrivilege returns an error unless the user of the session
+// holds the privilege on the database.
+func (s *session) checkDatabasePrivilege(desc *structured.DatabaseDescriptor,
+	priv structured.Privilege) error {
+	if isSuperuser(s.user) || desc.Privileges.Check(s.user, priv) {
+		return nil
+	}
+	return fmt.Errorf("user %s does not have %s privilege on database \"%s\"",
+		s.user, priv, desc.Name)
+}
+
+// checkTablePrivilege returns an error unless the user of the session holds
+// the privilege on the table of the normalized name, either granted on the
+// table or on its database. A statement reading from a snapshot is checked
+// against the privileges granted now rather than those granted as of the
+// snapshot, so that a revoked privilege cannot be used by reading the past;
+// the historical descriptor is only used to decode the rows.
+func (s *session) checkTablePrivilege(name *parser.TableName, desc *structured.TableDescriptor,
+	priv structured.Privilege) error {
+	if isSuperuser(s.user) {
+		return nil
+	}
+	if isInformationSchema(name.Qualifier) &&
+		(priv == structured.SelectPrivilege || priv == anyPrivilege) {
+		return nil
+	}
+	if s.snapshot != nil {
+		current := *s
+		current.snapshot = nil
+		currentDesc := &structured.TableDescriptor{}
+		if err := current.reader().GetProto(keys.MakeDescMetadataKey(desc.ID), currentDesc); err != nil {
+			return err
+		}
+		if currentDesc.ID == 0 {
+			return fmt.Errorf("table \"%s\" does not exist", name)
+		}
+		return current.checkTablePrivilege(name, currentDesc, priv)
+	}
+	if holdsPrivilege(&desc.Privileges, s.user, priv) {
+		return nil
+	}
+	dbDesc, err := s.getDatabaseDesc(name.Qualifier)
+	if err != nil {
+		return err
+	}
+	if holdsPrivilege(&dbDesc.Privileges, s.user, priv) {
+		return nil
+	}
+	if priv == anyPrivilege {
+		return fmt.Errorf("user %s does not have any privilege on table \"%s\"", s.user, name)
+	}
+	return fmt.Errorf("user %s does not have %s privilege on table \"%s\"", s.user, priv, name)
+}
+
+// Grant executes a GRANT statement.
+func (s *session) Grant(p *parser.Grant, args []driver.Value) (*rows, error) {
+	privs, err := makePrivileges(p.Privileges)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.changePrivileges(p.Targets, func(pd *structured.PrivilegeDescriptor) {
+		for _, user := range p.Grantees {
+			pd.Grant(user, privs)
+		}
+	}); err != nil {
+		return nil, err
+	}
+	return &rows{}, nil
+}
+
+// Revoke executes a REVOKE statement.
+func (s *session) Revoke(p *parser.Revoke, args []driver.Value) (*rows, error) {
+	privs, err := makePrivileges(p.Privileges)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.changePrivileges(p.Targets, func(pd *structured.PrivilegeDescriptor) {
+		for _, user := range p.Grantees {
+			pd.Revoke(user, privs)
+		}
+	}); err != nil {
+		return nil, err
+	}
+	return &rows{}, nil
+}
+
+// makePrivileges returns the set of the named privileges.
+func makePrivileges(names []string) (structured.Privilege, error) {
+	var privs structured.Privilege
+	for _, name := range names {
+		p, err := structured.PrivilegeFromName(name)
+		if err != nil {
+			return 0, err
+		}
+		privs |= p
+	}
+	return privs, nil
+}
+
+// changePrivileges modifies the privileges of each of the databases or tables
+// of the targets using fn. The user of the session must hold ALL on each of
+// the targets.
+func (s *session) changePrivileges(targets parser.TargetList,
+	fn func(pd *structured.PrivilegeDescriptor)) error {
+	for _, name := range targets.Databases {
+		if isInformationSchema(name) {
+			return fmt.Errorf("database \"%s\" is read-only", name)
+		}
+		desc, err := s.getDatabaseDesc(name)
+		if err != nil {
+			return err
+		}
+		if err := s.checkDatabasePrivilege(desc, structured.AllPrivilege); err != nil {
+			return err
+		}
+		id, dbName := desc.ID, desc.Name
+		err = s.db.Txn(func(txn *client.Txn) error {
+			descKey := keys.MakeDescMetadataKey(id)
+			if err := txn.GetProto(descKey, desc); err != nil {
+				return err
+			}
+			// The descriptor is missing for a database created before
+			// privileges were stored.
+			desc.ID, desc.Name = id, dbName
+			fn(&desc.Privileges)
+			b := &client.Batch{}
+			b.Put(descKey, desc)
+			return txn.Commit(b)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, name := range targets.Tables {
+		if err := s.normalizeTableName(name); err != nil {
+			return err
+		}
+		if isInformationSchema(name.Qualifier) {
+			return fmt.Errorf("table \"%s\" is read-only", name)
+		}
+		desc, err := s.readTableDesc(name)
+		if err != nil {
+			return err
+		}
+		if err := s.checkTablePrivilege(name, desc, structured.AllPrivilege); err != nil {
+			return err
+		}
+		if err := updateTableDesc(s.db, desc, func(txn *client.Txn, b *client.Batch) error {
+			fn(&desc.Privileges)
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package sqlserver
+
+import (
+	"sync"
+
+	"github.com/cockroachdb/cockroach/proto"
+	"github.com/cockroachdb/cockroach/sql/sqlwire"
+	"github.com/cockroachdb/cockroach/util/cache"
+	gogoproto "github.com/gogo/protobuf/proto"
+)
+
+// cmdIDKey is the key of a response within the responseCache. Command IDs
+// are chosen by the clients, so the responses of different users are kept
+// apart.
+type cmdIDKey struct {
+	user             string
+	wallTime, random int64
+}
+
+// cacheEntry is the response of a request within the responseCache. The done
+// channel is closed once the request has executed; resp is then the response
+// or nil if the request did not produce one.
+type cacheEntry struct {
+	done chan struct{}
+	resp *sqlwire.SQLResponse
+}
+
+// A responseCache provides replay protection for requests. It retains the
+// responses of recently executed requests, keyed by the user and the client
+// command ID of the request. A client which does not receive the response to
+// a request retries the request using the same command ID and is sent the
+// cached response instead of having the request executed a second time. A
+// retry which arrives while the request is still executing waits for its
+// response.
+//
+// The responses are held in the memory of the node which executed the
+// requests, and only the responses of the most recent requests are retained.
+// A retry is executed again if it is sent to another node, if the node
+// restarted, or if the response was evicted by those of later requests.
+// Executing requests are never evicted. The driver retries against the same
+// node for a bounded number of attempts, so a retry normally finds the
+// response.
+type responseCache struct {
+	mu       sync.Mutex
+	cache    *cache.UnorderedCache
+	inFlight map[cmdIDKey]*cacheEntry
+}
+
+// newResponseCache creates a responseCache which retains the responses of
+// the given number of requests.
+func newResponseCache(size int) *responseCache {
+	return &responseCache{
+		cache: cache.NewUnorderedCache(cache.Config{
+			Policy: cache.CacheLRU,
+			ShouldEvict: func(s int, key, value interface{}) bool {
+				return s > size
+			},
+		}),
+		inFlight: make(map[cmdIDKey]*cacheEntry),
+	}
+}
+
+// get copies the cached response for the command ID of the user into resp,
+// returning true, waiting for the response if the command is executing. If
+// there is no response, the command is recorded as executing and false is
+// returned; the caller must then execute the command and call add. Requests
+// without a command ID are never cached.
+func (rc *responseCache) get(user string, cmdID proto.ClientCmdID, resp *sqlwire.SQLResponse) bool {
+	if cmdID.IsEmpty() {
+		return false
+	}
+	key := cmdIDKey{user, cmdID.WallTime, cmdID.Random}
+	for {
+		rc.mu.Lock()
+		if v, ok := rc.cache.Get(key); ok {
+			rc.mu.Unlock()
+			gogoproto.Merge(resp, v.(*sqlwire.SQLResponse))
+			return true
+		}
+		e, ok := rc.inFlight[key]
+		if !ok {
+			rc.inFlight[key] = &cacheEntry{done: make(chan struct{})}
+			rc.mu.Unlock()
+			return false
+		}
+		rc.mu.Unlock()
+
+		<-e.done
+		if e.resp != nil {
+			gogoproto.Merge(resp, e.resp)
+			return true
+		}
+		// The command failed to execute without a response, so the retry
+		// executes it.
+	}
+}
+
+// add caches the response for the command ID of the user, waking the
+// requests waiting for it. A nil response is not cached, so that the command
+// is executed again when it is retried.
+func (rc *responseCache) add(user string, cmdID proto.ClientCmdID, resp *sqlwire.SQLResponse) {
+	if cmdID.IsEmpty() {
+		return
+	}
+	key := cmdIDKey{user, cmdID.WallTime, cmdID.Random}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	e, ok := rc.inFlight[key]
+	if ok {
+		delete(rc.inFlight, key)
+	} else {
+		e = &cacheEntry{done: make(chan struct{})}
+	}
+	if resp != nil {
+		e.resp = gogoproto.Clone(resp).(*sqlwire.SQLResponse)
+		rc.cache.Add(key, e.resp)
+	}
+	close(e.done)
+}
@@ -0,0 +1,160 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package sqlserver
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/proto"
+	"github.com/cockroachdb/cockroach/sql/sqlwire"
+	"github.com/cockroachdb/cockroach/util/leaktest"
+)
+
+func TestResponseCache(t *testing.T) {
+	defer leaktest.AfterTest(t)
+
+	rc := newResponseCache(2)
+	cmdIDs := []proto.ClientCmdID{
+		{WallTime: 1, Random: 1},
+		{WallTime: 1, Random: 2},
+		{WallTime: 2, Random: 1},
+	}
+	for i, cmdID := range cmdIDs {
+		var resp sqlwire.SQLResponse
+		if rc.get("root", cmdID, &resp) {
+			t.Fatalf("%d: unexpected cached response %s", i, &resp)
+		}
+		rc.add("root", cmdID, &sqlwire.SQLResponse{RowsAffected: int64(i)})
+	}
+
+	for i, cmdID := range cmdIDs[1:] {
+		var resp sqlwire.SQLResponse
+		if !rc.get("root", cmdID, &resp) {
+			t.Fatalf("%d: expected cached response", i)
+		}
+		if expected := (sqlwire.SQLResponse{RowsAffected: int64(i + 1)}); !reflect.DeepEqual(expected, resp) {
+			t.Errorf("%d: expected %s; got %s", i, &expected, &resp)
+		}
+	}
+	// The first response has been evicted.
+	var resp sqlwire.SQLResponse
+	if rc.get("root", cmdIDs[0], &resp) {
+		t.Errorf("expected response to have been evicted; got %s", &resp)
+	}
+	rc.add("root", cmdIDs[0], nil)
+
+	// The responses of a user are not returned to other users.
+	rc.add("root", cmdIDs[0], &sqlwire.SQLResponse{RowsAffected: 1})
+	if rc.get("other", cmdIDs[0], &resp) {
+		t.Errorf("expected no response for another user; got %s", &resp)
+	}
+	rc.add("other", cmdIDs[0], nil)
+
+	// Requests without a command ID are not cached.
+	rc.add("root", proto.ClientCmdID{}, &sqlwire.SQLResponse{})
+	if rc.get("root", proto.ClientCmdID{}, &resp) {
+		t.Errorf("expected no response for an empty command ID")
+	}
+}
+
+func TestResponseCacheInFlight(t *testing.T) {
+	defer leaktest.AfterTest(t)
+
+	rc := newResponseCache(10)
+	cmdID := proto.ClientCmdID{WallTime: 1, Random: 1}
+	if rc.get("root", cmdID, &sqlwire.SQLResponse{}) {
+		t.Fatal("unexpected cached response")
+	}
+
+	// A retry of a command which is executing waits for its response.
+	type result struct {
+		ok   bool
+		resp sqlwire.SQLResponse
+	}
+	results := make(chan result)
+	go func() {
+		var r result
+		r.ok = rc.get("root", cmdID, &r.resp)
+		results <- r
+	}()
+	select {
+	case r := <-results:
+		t.Fatalf("expected the retry to wait, but got %t, %s", r.ok, &r.resp)
+	case <-time.After(10 * time.Millisecond):
+	}
+	rc.add("root", cmdID, &sqlwire.SQLResponse{RowsAffected: 1})
+	if r := <-results; !r.ok || r.resp.RowsAffected != 1 {
+		t.Fatalf("expected the cached response, but got %t, %s", r.ok, &r.resp)
+	}
+
+	// A retry of a command which failed without a response executes it.
+	cmdID.Random = 2
+	if rc.get("root", cmdID, &sqlwire.SQLResponse{}) {
+		t.Fatal("unexpected cached response")
+	}
+	go func() {
+		var r result
+		r.ok = rc.get("root", cmdID, &r.resp)
+		results <- r
+	}()
+	rc.add("root", cmdID, nil)
+	if r := <-results; r.ok {
+		t.Fatalf("expected no response, but got %s", &r.resp)
+	}
+	rc.add("root", cmdID, &sqlwire.SQLResponse{})
+}
+
+// TestResponseCacheLimits verifies that executing commands are not evicted,
+// and that the responses are not retained beyond the cache of the node which
+// executed them.
+func TestResponseCacheLimits(t *testing.T) {
+	defer leaktest.AfterTest(t)
+
+	rc := newResponseCache(1)
+	cmdID := proto.ClientCmdID{WallTime: 1, Random: 1}
+	if rc.get("root", cmdID, &sqlwire.SQLResponse{}) {
+		t.Fatal("unexpected cached response")
+	}
+	// The responses of other commands do not evict the executing command.
+	for i := int64(2); i < 5; i++ {
+		other := proto.ClientCmdID{WallTime: 1, Random: i}
+		if rc.get("root", other, &sqlwire.SQLResponse{}) {
+			t.Fatalf("%d: unexpected cached response", i)
+		}
+		rc.add("root", other, &sqlwire.SQLResponse{RowsAffected: i})
+	}
+	results := make(chan bool)
+	go func() {
+		results <- rc.get("root", cmdID, &sqlwire.SQLResponse{})
+	}()
+	select {
+	case ok := <-results:
+		t.Fatalf("expected the retry to wait, but got %t", ok)
+	case <-time.After(10 * time.Millisecond):
+	}
+	rc.add("root", cmdID, &sqlwire.SQLResponse{RowsAffected: 1})
+	if !<-results {
+		t.Fatal("expected the cached response")
+	}
+
+	// A retry sent to another node, or to a restarted node, executes the
+	// command again.
+	if newResponseCache(1).get("root", cmdID, &sqlwire.SQLResponse{}) {
+		t.Error("unexpected cached response in another cache")
+	}
+}
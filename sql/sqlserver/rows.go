@@ -0,0 +1,71 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package sqlserver
+
+import (
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/sql/sqlwire"
+)
+
+type row []driver.Value
+
+// rows is the result of executing a statement.
+type rows struct {
+	columns      []string
+	rows         []row
+	rowsAffected int // The number of rows modified by the statement.
+}
+
+// newSingleColumnRows returns a rows structure initialized with a single
+// column of values using the specified column name and values. This is a
+// convenience routine used by operations which return only a single column.
+func newSingleColumnRows(column string, vals []string) *rows {
+	r := make([]row, len(vals))
+	for i, v := range vals {
+		r[i] = row{v}
+	}
+	return &rows{
+		columns: []string{column},
+		rows:    r,
+	}
+}
+
+// fillResponse sets the columns and results of the response. Statements which
+// do not return any columns report the number of rows they modified.
+func (r *rows) fillResponse(resp *sqlwire.SQLResponse) error {
+	resp.Columns = r.columns
+	resp.RowsAffected = int64(r.rowsAffected)
+	if r.columns == nil {
+		return nil
+	}
+	resp.RowsAffected = int64(len(r.rows))
+	resp.Results = make([]*sqlwire.Result, len(r.rows))
+	for i, vals := range r.rows {
+		result := &sqlwire.Result{Values: make([]*sqlwire.Datum, len(vals))}
+		for j, v := range vals {
+			d, err := sqlwire.MakeDatum(v)
+			if err != nil {
+				return fmt.Errorf("column \"%s\": %s", r.columns[j], err)
+			}
+			d.SetChecksum()
+			result.Values[j] = d
+		}
+		resp.Results[i] = result
+	}
+	return nil
+}
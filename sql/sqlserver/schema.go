@@ -0,0 +1,225 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+//
+// Author: Peter Mattis (peter@cockroachlabs.com)
+
+package sqlserver
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/cockroach/client"
+	"github.com/cockroachdb/cockroach/keys"
+	"github.com/cockroachdb/cockroach/sql/parser"
+	"github.com/cockroachdb/cockroach/structured"
+	"github.com/cockroachdb/cockroach/util/decimal"
+)
+
+func makeSchema(p *parser.CreateTable) (structured.TableSchema, error) {
+	s := structured.TableSchema{}
+	s.Name = p.Name.String()
+
+	for _, def := range p.Defs {
+		switch d := def.(type) {
+		case *parser.ColumnTableDef:
+			col, err := makeColumn(d)
+			if err != nil {
+				return s, err
+			}
+			s.Columns = append(s.Columns, col)
+
+			// Create any associated index.
+			if d.PrimaryKey || d.Unique {
+				index := structured.TableSchema_IndexByName{
+					Index: structured.Index{
+						Unique: true,
+					},
+					ColumnNames: []string{d.Name},
+				}
+				if d.PrimaryKey {
+					index.Name = "primary"
+				}
+				s.Indexes = append(s.Indexes, index)
+			}
+		case *parser.IndexTableDef:
+			index := structured.TableSchema_IndexByName{
+				Index: structured.Index{
+					Name:   d.Name,
+					Unique: d.Unique,
+				},
+				ColumnNames: d.Columns,
+			}
+			s.Indexes = append(s.Indexes, index)
+		default:
+			return s, fmt.Errorf("unsupported table def: %T", def)
+		}
+	}
+	return s, nil
+}
+
+// createTableString returns the CREATE TABLE statement creating a table with
+// the specified schema. The primary key and the other indexes are listed
+// after the columns.
+func createTableString(schema structured.TableSchema) string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "CREATE TABLE %s (", schema.Name)
+	for i, col := range schema.Columns {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		fmt.Fprintf(&buf, "\n  %s %s", col.Name, col.Type.SQLString())
+		if !col.Nullable {
+			buf.WriteString(" NOT NULL")
+		}
+		if col.DefaultExpr != nil {
+			fmt.Fprintf(&buf, " DEFAULT %s", *col.DefaultExpr)
+		}
+	}
+	for i, index := range schema.Indexes {
+		def := &parser.IndexTableDef{
+			Name:       index.Name,
+			PrimaryKey: i == 0,
+			Unique:     index.Unique,
+			Columns:    index.ColumnNames,
+		}
+		fmt.Fprintf(&buf, ",\n  %s", def)
+	}
+	buf.WriteString("\n)")
+	return buf.String()
+}
+
+func makeColumn(d *parser.ColumnTableDef) (structured.Column, error) {
+	col := structured.Column{
+		Name:     d.Name,
+		Nullable: (d.Nullable != parser.NotNull),
+	}
+	switch t := d.Type.(type) {
+	case *parser.BitType:
+		col.Type.Kind = structured.ColumnType_BIT
+		col.Type.Width = int32(t.N)
+	case *parser.IntType:
+		col.Type.Kind = structured.ColumnType_INT
+		col.Type.Width = int32(t.N)
+	case *parser.SerialType:
+		if d.Default != nil {
+			return col, fmt.Errorf("SERIAL column \"%s\" cannot have a default", d.Name)
+		}
+		col.Type.Kind = structured.ColumnType_INT
+		col.Nullable = false
+		expr := uniqueRowIDDefault
+		col.DefaultExpr = &expr
+	case *parser.FloatType:
+		col.Type.Kind = structured.ColumnType_FLOAT
+		col.Type.Width = int32(t.N)
+		col.Type.Precision = int32(t.Prec)
+	case *parser.DecimalType:
+		col.Type.Kind = structured.ColumnType_DECIMAL
+		col.Type.Width = int32(t.N)
+		col.Type.Precision = int32(t.Prec)
+		if t.Prec > decimal.MaxScale {
+			return col, fmt.Errorf("invalid precision for column \"%s\": %s",
+				d.Name, col.Type.SQLString())
+		}
+	case *parser.DateType:
+		col.Type.Kind = structured.ColumnType_DATE
+	case *parser.TimeType:
+		col.Type.Kind = structured.ColumnType_TIME
+	case *parser.DateTimeType:
+		col.Type.Kind = structured.ColumnType_DATETIME
+	case *parser.TimestampType:
+		col.Type.Kind = structured.ColumnType_TIMESTAMP
+	case *parser.CharType:
+		col.Type.Kind = structured.ColumnType_CHAR
+		col.Type.Width = int32(t.N)
+	case *parser.BinaryType:
+		col.Type.Kind = structured.ColumnType_BINARY
+		col.Type.Width = int32(t.N)
+	case *parser.TextType:
+		col.Type.Kind = structured.ColumnType_TEXT
+	case *parser.BlobType:
+		col.Type.Kind = structured.ColumnType_BLOB
+	case *parser.EnumType:
+		col.Type.Kind = structured.ColumnType_ENUM
+		col.Type.Vals = t.Vals
+	case *parser.SetType:
+		col.Type.Kind = structured.ColumnType_SET
+		col.Type.Vals = t.Vals
+	}
+
+	if d.Default != nil {
+		expr := fmt.Sprintf("%s", d.Default)
+		col.DefaultExpr = &expr
+	}
+	if col.DefaultExpr != nil {
+		// Verify that the default can be evaluated and is compatible with the
+		// column type.
+		desc := structured.ColumnDescriptor{Column: col}
+		if hasUniqueRowIDDefault(desc) {
+			if col.Type.Kind != structured.ColumnType_INT {
+				return col, fmt.Errorf("invalid default for column \"%s\": %s requires an INT column",
+					col.Name, uniqueRowIDDefault)
+			}
+		} else if _, err := evalDefaultExpr(desc); err != nil {
+			return col, err
+		}
+	}
+	return col, nil
+}
+
+// uniqueRowIDDefault is the DEFAULT expression of a SERIAL column. Unlike
+// other defaults, which are constant, a column with this default is assigned a
+// new value for every row from the row ID generator.
+const uniqueRowIDDefault = "UNIQUE_ROWID()"
+
+// hasUniqueRowIDDefault returns true if the values of the column default to
+// unique row IDs.
+func hasUniqueRowIDDefault(col structured.ColumnDescriptor) bool {
+	return col.DefaultExpr != nil && *col.DefaultExpr == uniqueRowIDDefault
+}
+
+// allocateRowIDs allocates n consecutive row IDs, returning the first. The
+// generator is incremented outside of any transaction so that concurrent
+// inserts do not conflict on it; the IDs allocated by a statement which fails
+// are not reused.
+func allocateRowIDs(db *client.DB, n int) (int64, error) {
+	ir, err := db.Inc(keys.RowIDGenerator, int64(n))
+	if err != nil {
+		return 0, err
+	}
+	return ir.ValueInt() - int64(n) + 1, nil
+}
+
+// evalDefaultExpr returns the value of the column's DEFAULT expression, or nil
+// if the column does not have a default. The values of a column whose default
+// is uniqueRowIDDefault are allocated using allocateRowIDs instead. The
+// default is part of the schema, so it is evaluated in UTC rather than in the
+// time zone of the session.
+func evalDefaultExpr(col structured.ColumnDescriptor) (driver.Value, error) {
+	if col.DefaultExpr == nil {
+		return nil, nil
+	}
+	expr, err := parser.ParseExpr(*col.DefaultExpr)
+	if err != nil {
+		return nil, err
+	}
+	v, err := evalConstExpr(expr, nil, time.UTC)
+	if err != nil {
+		return nil, fmt.Errorf("invalid default for column \"%s\": %s", col.Name, err)
+	}
+	return checkColumnValue(col, v)
+}
@@ -15,7 +15,7 @@
 //
 // Author: Peter Mattis (peter@cockroachlabs.com)
 
-package driver
+package sqlserver
 
 import (
 	"reflect"
@@ -109,6 +109,11 @@ func TestMakeSchemaColumns(t *testing.T) {
 			structured.ColumnType{Kind: structured.ColumnType_INT},
 			true,
 		},
+		{
+			"SERIAL",
+			structured.ColumnType{Kind: structured.ColumnType_INT},
+			false,
+		},
 	}
 	for i, d := range testData {
 		stmt, err := parser.Parse("CREATE TABLE test (a " + d.sqlType + ")")
@@ -200,3 +205,61 @@ func TestMakeSchemaIndexes(t *testing.T) {
 		}
 	}
 }
+
+func TestCreateTableString(t *testing.T) {
+	defer leaktest.AfterTest(t)
+
+	testData := []struct {
+		sql      string
+		expected string
+	}{
+		{
+			"CREATE TABLE t.a (k INT PRIMARY KEY, v CHAR(10))",
+			`CREATE TABLE t.a (
+  k INT,
+  v CHAR(10),
+  PRIMARY KEY (k)
+)`,
+		},
+		{
+			`CREATE TABLE t.b (a INT NOT NULL, b FLOAT(3,4) DEFAULT 1.5, c TEXT DEFAULT 'x',
+				e ENUM(x, y), PRIMARY KEY (a, b), UNIQUE INDEX foo (c), INDEX bar (e, a))`,
+			`CREATE TABLE t.b (
+  a INT NOT NULL,
+  b FLOAT(3,4) DEFAULT 1.5,
+  c TEXT DEFAULT 'x',
+  e ENUM(x,y),
+  PRIMARY KEY (a, b),
+  UNIQUE INDEX foo (c),
+  INDEX bar (e, a)
+)`,
+		},
+	}
+	for _, d := range testData {
+		stmt, err := parser.Parse(d.sql)
+		if err != nil {
+			t.Fatalf("%s: %v", d.sql, err)
+		}
+		schema, err := makeSchema(stmt.(*parser.CreateTable))
+		if err != nil {
+			t.Fatalf("%s: %v", d.sql, err)
+		}
+		s := createTableString(schema)
+		if s != d.expected {
+			t.Errorf("%s: expected\n%s\nbut found\n%s", d.sql, d.expected, s)
+		}
+
+		// The statement creates a table with the same schema.
+		stmt, err = parser.Parse(s)
+		if err != nil {
+			t.Fatalf("%s: %v", s, err)
+		}
+		schema2, err := makeSchema(stmt.(*parser.CreateTable))
+		if err != nil {
+			t.Fatalf("%s: %v", s, err)
+		}
+		if !reflect.DeepEqual(schema, schema2) {
+			t.Errorf("%s: expected %+v, but found %+v", s, schema, schema2)
+		}
+	}
+}
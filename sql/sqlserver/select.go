@@ -0,0 +1,679 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package sqlserver
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/client"
+	"github.com/cockroachdb/cockroach/proto"
+	"github.com/cockroachdb/cockroach/sql/parser"
+	"github.com/cockroachdb/cockroach/structured"
+)
+
+// tableRow holds the values of a single table row in the order of the
+// table's columns. NULL values are nil.
+type tableRow struct {
+	key  []byte // The primary key of the row.
+	vals []driver.Value
+}
+
+// tableEnv implements the env interface for a row of a table. A nil row
+// provides NULL values for all of the columns.
+type tableEnv struct {
+	desc  *structured.TableDescriptor
+	alias string // The name the table is referenced by in the query.
+	row   *tableRow
+}
+
+func (e *tableEnv) get(name *parser.ColName) (driver.Value, error) {
+	if name.Qualifier != "" && !strings.EqualFold(name.Qualifier, e.alias) {
+		return nil, fmt.Errorf("unknown table \"%s\" for column \"%s\"", name.Qualifier, name.Name)
+	}
+	n := strings.ToLower(name.Name)
+	for i, col := range e.desc.Columns {
+		if col.Name == n && col.IsPublic() {
+			if e.row == nil {
+				return nil, nil
+			}
+			return e.row.vals[i], nil
+		}
+	}
+	return nil, fmt.Errorf("column \"%s\" does not exist", name.Name)
+}
+
+// span is a half-open interval of keys [start, end).
+type span struct {
+	start, end proto.Key
+}
+
+// scanner is implemented by both client.DB and client.Txn.
+type scanner interface {
+	Scan(begin, end interface{}, maxRows int64) ([]client.KeyValue, error)
+	Run(b *client.Batch) error
+}
+
+// decodeTableRows decodes the key/value pairs from a scan of the table's
+// primary index into rows. Consecutive keys sharing the same primary key
+// belong to the same row.
+func decodeTableRows(desc *structured.TableDescriptor, kvs []client.KeyValue) ([]tableRow, error) {
+	primaryIndex := desc.Indexes[0]
+	primaryIndexKey := encodeIndexKeyPrefix(desc.ID, primaryIndex.ID)
+
+	// Construct a map from column ID to the position of the column within the
+	// table.
+	colIdx := map[uint32]int{}
+	for i, col := range desc.Columns {
+		colIdx[col.ID] = i
+	}
+
+	var rows []tableRow
+	for _, kv := range kvs {
+		if !bytes.HasPrefix(kv.Key, primaryIndexKey) {
+			return nil, fmt.Errorf("%s: invalid key prefix: %q", desc.Name, kv.Key)
+		}
+		remaining := kv.Key[len(primaryIndexKey):]
+		for _, id := range primaryIndex.ColumnIDs {
+			var err error
+			if remaining, _, err = decodeTableKey(remaining, desc.Columns[colIdx[id]]); err != nil {
+				return nil, err
+			}
+		}
+		primaryKey := kv.Key[:len(kv.Key)-len(remaining)]
+
+		if n := len(rows); n == 0 || !bytes.Equal(rows[n-1].key, primaryKey) {
+			rows = append(rows, tableRow{
+				key:  primaryKey,
+				vals: make([]driver.Value, len(desc.Columns)),
+			})
+		}
+		row := &rows[len(rows)-1]
+
+		_, colID := decodeColumnID(remaining)
+		i, ok := colIdx[colID]
+		if !ok {
+			// The column has been dropped.
+			continue
+		}
+		v, err := unmarshalColumnValue(desc.Columns[i], kv.ValueBytes())
+		if err != nil {
+			return nil, err
+		}
+		row.vals[i] = v
+	}
+	return rows, nil
+}
+
+// forEachRowBatch scans the primary index of the table in batches of
+// BackfillBatchSize rows, invoking fn on each batch within a separate
+// transaction. The table descriptor is re-read at the start of every
+// transaction so that fn operates on the current schema.
+func forEachRowBatch(db *client.DB, desc *structured.TableDescriptor,
+	fn func(txn *client.Txn, tableRows []tableRow) error) error {
+	prefix := proto.Key(encodeIndexKeyPrefix(desc.ID, desc.Indexes[0].ID))
+	start, end := prefix, prefix.PrefixEnd()
+
+	for start != nil {
+		var next proto.Key
+		err := db.Txn(func(txn *client.Txn) error {
+			next = nil
+			if err := refreshTableDesc(txn, desc); err != nil {
+				return err
+			}
+			var tableRows []tableRow
+			var err error
+			if tableRows, next, err = scanRowBatch(txn, desc, start, end, BackfillBatchSize); err != nil {
+				return err
+			}
+			return fn(txn, tableRows)
+		})
+		if err != nil {
+			return err
+		}
+		start = next
+	}
+	return nil
+}
+
+// scanRowBatch scans up to batchSize rows of the table's primary index within
+// the span [start, end), also returning the key at which the next batch
+// starts, or nil if there are no more rows.
+func scanRowBatch(db scanner, desc *structured.TableDescriptor, start, end proto.Key,
+	batchSize int) ([]tableRow, proto.Key, error) {
+	// A row is composed of at most one key per column, including the columns
+	// which have been dropped but whose data has not been deleted yet. Scanning
+	// one row more than the batch size guarantees that a full batch of rows
+	// precedes the last row, which might be incomplete.
+	maxKeys := int64((batchSize + 1) * int(desc.NextColumnID))
+	kvs, err := db.Scan(start, end, maxKeys)
+	if err != nil {
+		return nil, nil, err
+	}
+	tableRows, err := decodeTableRows(desc, kvs)
+	if err != nil {
+		return nil, nil, err
+	}
+	if int64(len(kvs)) < maxKeys {
+		return tableRows, nil, nil
+	}
+	// The last row might be incomplete. Leave it for the next batch.
+	last := len(tableRows) - 1
+	return tableRows[:last], proto.Key(tableRows[last].key), nil
+}
+
+// Select executes a SELECT statement or a UNION of SELECT statements. The
+// rows of the statement and of all of its subqueries are read within a single
+// transaction so that they are consistent with each other. A statement with
+// an AS OF SYSTEM TIME clause is instead read from a snapshot of the database
+// as of the specified timestamp, including the descriptors of its tables.
+func (s *session) Select(p parser.SelectStatement, args []driver.Value) (*rows, error) {
+	asOf, err := evalAsOf(p, args, s.timeLocation())
+	if err != nil {
+		return nil, err
+	}
+	if asOf != nil {
+		if s.txn != nil {
+			return nil, errAsOfInTxn
+		}
+		s.snapshot = s.db.ReadAt(*asOf)
+		defer func() { s.snapshot = nil }()
+		return (&queryContext{s: s, db: timeoutScanner{s.snapshot, s}, args: args}).query(p)
+	}
+
+	var r *rows
+	err = s.runInTxn(func(txn *client.Txn, _ *client.Batch) error {
+		var err error
+		r, err = (&queryContext{s: s, db: timeoutScanner{txn, s}, args: args}).query(p)
+		return err
+	})
+	return r, err
+}
+
+// execSelect executes a SELECT statement. The rows of the tables of the FROM
+// clause are retrieved and joined as described by planJoins, using the WHERE
+// clause to choose the indexes which are scanned. The matching rows are
+// grouped if the statement uses GROUP BY, HAVING or aggregate functions, then
+// ordered, projected onto the output columns, deduplicated by DISTINCT and
+// finally truncated by LIMIT. Unless the rows are grouped or ordered, they are
+// projected as they are produced and no more rows are produced than LIMIT
+// allows.
+func (q *queryContext) execSelect(p *parser.Select) (*rows, error) {
+	args, loc := q.args, q.s.timeLocation()
+	plan, err := q.s.planSelect(p, args)
+	if err != nil {
+		return nil, err
+	}
+	offset, count, err := evalLimit(p.Limit, args, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &rows{columns: make([]string, len(plan.outputs))}
+	for i, o := range plan.outputs {
+		r.columns[i] = o.name
+	}
+	desc := plan.join.tables[0].desc
+	seen := map[string]struct{}{}
+	project := func(e env) error {
+		vals := make(row, len(plan.outputs))
+		for j, o := range plan.outputs {
+			expr := o.expr
+			if expr == nil {
+				if te, ok := e.(*queryEnv).env.(*tableEnv); ok {
+					vals[j] = te.row.vals[o.col]
+					continue
+				}
+				expr = &parser.ColName{Name: desc.Columns[o.col].Name}
+			}
+			var err error
+			if vals[j], err = evalExpr(expr, e, args, loc); err != nil {
+				return err
+			}
+		}
+		if p.Distinct != "" {
+			key, err := encodeRowKey(vals)
+			if err != nil {
+				return err
+			}
+			if _, ok := seen[string(key)]; ok {
+				return nil
+			}
+			seen[string(key)] = struct{}{}
+		}
+		r.rows = append(r.rows, vals)
+		return nil
+	}
+
+	stream := !plan.grouped && p.OrderBy == nil
+	var envs []env
+	err = plan.join.run(q, func(e env) error {
+		if !stream {
+			envs = append(envs, e)
+			return nil
+		}
+		if err := project(e); err != nil {
+			return err
+		}
+		if count >= 0 && int64(len(r.rows)) >= offset+count {
+			return errStopIteration
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !stream {
+		if plan.grouped {
+			if envs, err = groupRows(envs, p.GroupBy, plan.aggs, args, loc); err != nil {
+				return nil, err
+			}
+			for i, e := range envs {
+				envs[i] = q.env(e)
+			}
+			if p.Having != nil {
+				filtered := envs[:0]
+				for _, e := range envs {
+					ok, err := evalBoolExpr(p.Having.Expr, e, args, loc)
+					if err != nil {
+						return nil, err
+					}
+					if ok {
+						filtered = append(filtered, e)
+					}
+				}
+				envs = filtered
+			}
+		}
+
+		// Order the rows. The rows are produced in primary key order of the
+		// joined tables so a stable sort preserves that order for rows with
+		// equal sort keys.
+		if p.OrderBy != nil {
+			swap := func(i, j int) { envs[i], envs[j] = envs[j], envs[i] }
+			envAt := func(i int) env { return envs[i] }
+			if err := sortByExprs(len(envs), envAt, swap, p.OrderBy, args, loc); err != nil {
+				return nil, err
+			}
+		}
+		for _, e := range envs {
+			if err := project(e); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	start, end := limitBounds(len(r.rows), offset, count)
+	r.rows = r.rows[start:end]
+	return r, nil
+}
+
+// selectPlan describes how a SELECT statement is executed.
+type selectPlan struct {
+	join    *joinPlan
+	outputs []selectOutput
+	aggs    []*parser.FuncExpr // The aggregate function calls.
+	grouped bool               // Set if the rows are grouped.
+}
+
+// planSelect resolves the tables and the output columns of a SELECT statement,
+// checks the types of its expressions and chooses how the rows of the tables
+// are retrieved and joined. The constant sub-expressions of the WHERE clause
+// and of the join conditions are folded.
+func (s *session) planSelect(p *parser.Select, args []driver.Value) (*selectPlan, error) {
+	tables, err := s.resolveFrom(p.From)
+	if err != nil {
+		return nil, err
+	}
+	outputs, err := selectOutputs(p, tables)
+	if err != nil {
+		return nil, err
+	}
+	aggs, grouped, err := selectAggregates(p, outputs)
+	if err != nil {
+		return nil, err
+	}
+	if err := typeCheckSelect(p, tables, outputs, args); err != nil {
+		return nil, err
+	}
+	loc := s.timeLocation()
+	var needed map[uint32]struct{}
+	if len(tables) == 1 {
+		needed = selectNeededColumns(tables[0].desc, outputs, p)
+	}
+	for _, t := range tables {
+		t.on = foldConstExpr(t.on, args, loc)
+	}
+	join, err := planJoins(tables, foldConstExpr(whereExpr(p.Where), args, loc), needed, args, loc)
+	if err != nil {
+		return nil, err
+	}
+	return &selectPlan{join: join, outputs: outputs, aggs: aggs, grouped: grouped}, nil
+}
+
+// whereExpr returns the expression of a WHERE clause, or nil if there is no
+// WHERE clause.
+func whereExpr(w *parser.Where) parser.Expr {
+	if w == nil {
+		return nil
+	}
+	return w.Expr
+}
+
+// selectNeededColumns returns the IDs of the columns used by a SELECT
+// statement, or nil if they cannot be determined.
+func selectNeededColumns(desc *structured.TableDescriptor, outputs []selectOutput,
+	p *parser.Select) map[uint32]struct{} {
+	ids := map[uint32]struct{}{}
+	exprs := []parser.Expr{whereExpr(p.Where), whereExpr(p.Having)}
+	for _, o := range outputs {
+		if o.expr == nil {
+			ids[desc.Columns[o.col].ID] = struct{}{}
+			continue
+		}
+		exprs = append(exprs, o.expr)
+	}
+	for _, e := range p.GroupBy {
+		exprs = append(exprs, e)
+	}
+	for _, o := range p.OrderBy {
+		exprs = append(exprs, o.Expr)
+	}
+	for _, e := range exprs {
+		if !neededColumns(desc, e, ids) {
+			return nil
+		}
+	}
+	return ids
+}
+
+// selectColumns returns the names of the output columns of a SELECT statement
+// without retrieving any rows. The columns of a UNION are those of its first
+// SELECT statement.
+func (s *session) selectColumns(stmt parser.SelectStatement) ([]string, error) {
+	for {
+		u, ok := stmt.(*parser.Union)
+		if !ok {
+			break
+		}
+		stmt = u.Left
+	}
+	p, ok := stmt.(*parser.Select)
+	if !ok {
+		return nil, fmt.Errorf("unsupported SELECT: %T %s", stmt, stmt)
+	}
+	tables, err := s.resolveFrom(p.From)
+	if err != nil {
+		return nil, err
+	}
+	outputs, err := selectOutputs(p, tables)
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]string, len(outputs))
+	for i, o := range outputs {
+		columns[i] = o.name
+	}
+	return columns, nil
+}
+
+// selectOutput is an output column of a SELECT statement.
+type selectOutput struct {
+	name string
+	expr parser.Expr // nil for a reference to a column of a single table.
+	col  int
+}
+
+// selectOutputs determines the output columns of a SELECT statement, expanding
+// "*" into the columns of the tables.
+func selectOutputs(p *parser.Select, tables []*fromTable) ([]selectOutput, error) {
+	var outputs []selectOutput
+	for _, expr := range p.Exprs {
+		switch t := expr.(type) {
+		case *parser.StarExpr:
+			found := false
+			for _, table := range tables {
+				if t.TableName != "" && !strings.EqualFold(t.TableName, table.alias) {
+					continue
+				}
+				found = true
+				for i, col := range table.desc.Columns {
+					if !col.IsPublic() {
+						continue
+					}
+					o := selectOutput{name: col.Name, col: i}
+					if len(tables) > 1 {
+						o.expr = &parser.ColName{Name: col.Name, Qualifier: table.alias}
+					}
+					outputs = append(outputs, o)
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("unknown table \"%s\"", t.TableName)
+			}
+		case *parser.NonStarExpr:
+			name := t.As
+			if name == "" {
+				if col, ok := t.Expr.(*parser.ColName); ok {
+					name = col.Name
+				} else {
+					name = fmt.Sprintf("%s", t.Expr)
+				}
+			}
+			outputs = append(outputs, selectOutput{name: name, expr: t.Expr})
+		}
+	}
+	return outputs, nil
+}
+
+// selectRows retrieves the rows of the table matching the WHERE clause using
+// the plan, ordered by the ORDER BY clause and truncated by the LIMIT clause.
+// A nil where expression matches every row.
+func selectRows(db scanner, plan *scanPlan, alias string, where parser.Expr,
+	orderBy parser.OrderBy, limit *parser.Limit, args []driver.Value, loc *time.Location) ([]tableRow, error) {
+	desc := plan.desc
+	tableRows, err := filterRows(db, plan, alias, splitAndExpr(where, nil), args, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Order the rows. The rows are retrieved in primary key order so a stable
+	// sort preserves that order for rows with equal sort keys.
+	if orderBy != nil {
+		if err := sortRows(desc, alias, tableRows, orderBy, args, loc); err != nil {
+			return nil, err
+		}
+	}
+
+	offset, count, err := evalLimit(limit, args, loc)
+	if err != nil {
+		return nil, err
+	}
+	start, end := limitBounds(len(tableRows), offset, count)
+	return tableRows[start:end], nil
+}
+
+// filterRows retrieves the rows of the table using the plan and returns the
+// rows matching all of the conjuncts of the WHERE clause.
+func filterRows(db scanner, plan *scanPlan, alias string,
+	conjuncts []parser.Expr, args []driver.Value, loc *time.Location) ([]tableRow, error) {
+	desc := plan.desc
+	tableRows, err := plan.scan(db)
+	if err != nil {
+		return nil, err
+	}
+	if len(conjuncts) == 0 {
+		return tableRows, nil
+	}
+
+	filtered := tableRows[:0]
+	for i := range tableRows {
+		e := &tableEnv{desc: desc, alias: alias, row: &tableRows[i]}
+		ok, err := evalConjuncts(conjuncts, e, args, loc)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			filtered = append(filtered, tableRows[i])
+		}
+	}
+	return filtered, nil
+}
+
+// evalConjuncts returns true if all of the conjuncts evaluate to true.
+func evalConjuncts(conjuncts []parser.Expr, env env, args []driver.Value, loc *time.Location) (bool, error) {
+	for _, c := range conjuncts {
+		if ok, err := evalBoolExpr(c, env, args, loc); err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// evalLimit evaluates the LIMIT clause, returning the offset and the maximum
+// number of rows. A limit of -1 indicates that there is no limit.
+func evalLimit(limit *parser.Limit, args []driver.Value, loc *time.Location) (int64, int64, error) {
+	if limit == nil {
+		return 0, -1, nil
+	}
+	var offset, count int64 = 0, -1
+	for _, x := range []struct {
+		expr parser.ValExpr
+		dest *int64
+	}{
+		{limit.Offset, &offset},
+		{limit.Rowcount, &count},
+	} {
+		if x.expr == nil {
+			continue
+		}
+		v, err := evalConstExpr(x.expr, args, loc)
+		if err != nil {
+			return 0, 0, err
+		}
+		i, ok := v.(int64)
+		if !ok || i < 0 {
+			return 0, 0, fmt.Errorf("invalid LIMIT value: %s", x.expr)
+		}
+		*x.dest = i
+	}
+	return offset, count, nil
+}
+
+// limitBounds returns the bounds [start, end) of the rows remaining after
+// skipping offset of n rows and retaining at most count rows. A count of -1
+// indicates that there is no limit.
+func limitBounds(n int, offset, count int64) (int, int) {
+	if offset >= int64(n) {
+		return n, n
+	}
+	start, end := int(offset), n
+	if count >= 0 && count < int64(end-start) {
+		end = start + int(count)
+	}
+	return start, end
+}
+
+// rowSorter sorts rows using the values of the ORDER BY expressions. The
+// rows themselves are swapped by the swap function.
+type rowSorter struct {
+	keys [][]driver.Value
+	desc []bool
+	swap func(i, j int)
+	err  error
+}
+
+func (s *rowSorter) Len() int {
+	return len(s.keys)
+}
+
+func (s *rowSorter) Swap(i, j int) {
+	s.swap(i, j)
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+}
+
+func (s *rowSorter) Less(i, j int) bool {
+	for k := range s.desc {
+		a, b := s.keys[i][k], s.keys[j][k]
+		// NULLs sort before all other values.
+		var c int
+		switch {
+		case a == nil && b == nil:
+			c = 0
+		case a == nil:
+			c = -1
+		case b == nil:
+			c = 1
+		default:
+			var err error
+			if c, err = compareValues(a, b); err != nil && s.err == nil {
+				s.err = err
+			}
+		}
+		if c == 0 {
+			continue
+		}
+		if s.desc[k] {
+			return c > 0
+		}
+		return c < 0
+	}
+	return false
+}
+
+// sortRows sorts the rows according to the ORDER BY clause.
+func sortRows(desc *structured.TableDescriptor, alias string, tableRows []tableRow,
+	orderBy parser.OrderBy, args []driver.Value, loc *time.Location) error {
+	envAt := func(i int) env {
+		return &tableEnv{desc: desc, alias: alias, row: &tableRows[i]}
+	}
+	swap := func(i, j int) {
+		tableRows[i], tableRows[j] = tableRows[j], tableRows[i]
+	}
+	return sortByExprs(len(tableRows), envAt, swap, orderBy, args, loc)
+}
+
+// sortByExprs stably sorts n rows according to the ORDER BY clause. The
+// ORDER BY expressions are evaluated using the env of each row returned by
+// envAt before any rows are swapped.
+func sortByExprs(n int, envAt func(i int) env, swap func(i, j int),
+	orderBy parser.OrderBy, args []driver.Value, loc *time.Location) error {
+	s := &rowSorter{
+		keys: make([][]driver.Value, n),
+		desc: make([]bool, len(orderBy)),
+		swap: swap,
+	}
+	for i, o := range orderBy {
+		s.desc[i] = o.Direction == " DESC"
+	}
+	for i := 0; i < n; i++ {
+		e := envAt(i)
+		s.keys[i] = make([]driver.Value, len(orderBy))
+		for j, o := range orderBy {
+			var err error
+			if s.keys[i][j], err = evalExpr(o.Expr, e, args, loc); err != nil {
+				return err
+			}
+		}
+	}
+	sort.Stable(s)
+	return s.err
+}
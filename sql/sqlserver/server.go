@@ -18,16 +18,27 @@
 package sqlserver
 
 import (
-	"errors"
+	"database/sql/driver"
 	"io/ioutil"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/cockroachdb/cockroach/base"
 	"github.com/cockroachdb/cockroach/client"
+	"github.com/cockroachdb/cockroach/proto"
+	"github.com/cockroachdb/cockroach/security"
+	"github.com/cockroachdb/cockroach/sql/parser"
 	"github.com/cockroachdb/cockroach/sql/sqlwire"
+	"github.com/cockroachdb/cockroach/structured"
 	"github.com/cockroachdb/cockroach/util"
+	gogoproto "github.com/gogo/protobuf/proto"
 )
 
+// responseCacheSize is the number of responses retained for replay
+// protection.
+const responseCacheSize = 1024
+
 var allowedEncodings = []util.EncodingType{util.JSONEncoding, util.ProtoEncoding}
 
 var allMethods = map[string]sqlwire.Method{
@@ -52,12 +63,18 @@ func createArgsAndReply(method string) (sqlwire.Request, sqlwire.Response) {
 // A Server provides an HTTP server endpoint serving the SQL API.
 // It accepts either JSON or serialized protobuf content types.
 type Server struct {
-	clientDB *client.DB
+	context   *base.Context
+	clientDB  *client.DB
+	respCache *responseCache
 }
 
 // NewServer allocates and returns a new Server.
-func NewServer(db *client.DB) *Server {
-	return &Server{clientDB: db}
+func NewServer(context *base.Context, db *client.DB) *Server {
+	return &Server{
+		context:   context,
+		clientDB:  db,
+		respCache: newResponseCache(responseCacheSize),
+	}
 }
 
 // ServeHTTP serves the SQL API by treating the request URL path
@@ -67,7 +84,8 @@ func NewServer(db *client.DB) *Server {
 // and JSON-encoded requests are supported. The response body is
 // encoded according to the request's Accept header, or if not
 // present, in the same format as the request's incoming Content-Type
-// header.
+// header. The client certificate of the request must belong to the user of
+// the request header.
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	method := r.URL.Path
 	if !strings.HasPrefix(method, sqlwire.Endpoint) {
@@ -92,6 +110,10 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	if err := security.AuthenticateUser(s.context.Insecure, r.TLS, args.Header().User); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
 
 	// Send the SQLRequest for SQL execution.
 	if httpStatus, err := s.execute(args, reply); err != nil {
@@ -109,6 +131,171 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	w.Write(body)
 }
 
+// execute executes the SQL request, returning an HTTP status code along with
+// an error if the request could not be executed.
 func (s *Server) execute(args sqlwire.Request, reply sqlwire.Response) (int, error) {
-	return http.StatusNotImplemented, errors.New("Not implemented")
+	if err := s.Execute(args.(*sqlwire.SQLRequest), reply.(*sqlwire.SQLResponse)); err != nil {
+		return http.StatusBadRequest, err
+	}
+	return http.StatusOK, nil
+}
+
+// Execute executes the commands of the request in order, stopping at the
+// first command which fails. The commands are executed as the user of the
+// request header, who must have been authenticated by the caller. The session
+// and the transaction in progress are decoded from the request header and
+// encoded into the response header so that a transaction can span several
+// requests. Errors encountered while
+// executing the commands are returned in the response header; an error is
+// returned only if the session state of the request cannot be decoded.
+func (s *Server) Execute(req *sqlwire.SQLRequest, resp *sqlwire.SQLResponse) error {
+	// A request which is retried by the client after its response was lost is
+	// answered using the cached response instead of being executed again.
+	user := req.Header().User
+	if s.respCache.get(user, req.CmdID, resp) {
+		return nil
+	}
+
+	sess, err := s.newSession(req.Header())
+	if err != nil {
+		s.respCache.add(user, req.CmdID, nil)
+		return err
+	}
+	for _, cmd := range req.Cmds {
+		resp.Reset()
+		if err := s.execCmd(sess, cmd, resp); err != nil {
+			resp.SetGoError(err)
+			break
+		}
+	}
+	if err := s.encodeSession(sess, resp.Header()); err != nil {
+		s.respCache.add(user, req.CmdID, nil)
+		return err
+	}
+
+	s.respCache.add(user, req.CmdID, resp)
+	return nil
+}
+
+// Describe returns the names of the result columns of the statement without
+// executing it, using the session state of the request header. Statements
+// which do not return any results have no columns.
+func (s *Server) Describe(h *sqlwire.SQLRequestHeader, stmt parser.Statement) ([]string, error) {
+	sess, err := s.newSession(h)
+	if err != nil {
+		return nil, err
+	}
+	return sess.describe(stmt)
+}
+
+// DescribeArgs infers the types of the numArgs arguments of the statement from
+// the columns the placeholders are compared with or assigned to, using the
+// session state of the request header. The type of an argument which cannot
+// be inferred is nil.
+func (s *Server) DescribeArgs(h *sqlwire.SQLRequestHeader, stmt parser.Statement,
+	numArgs int) ([]*structured.ColumnType, error) {
+	sess, err := s.newSession(h)
+	if err != nil {
+		return nil, err
+	}
+	return sess.inferArgTypes(stmt, numArgs)
+}
+
+// execCmd parses and executes a single command, storing its results in the
+// response.
+func (s *Server) execCmd(sess *session, cmd *sqlwire.SQLRequest_Cmd,
+	resp *sqlwire.SQLResponse) error {
+	stmt, err := parser.Parse(cmd.GetSql())
+	if err != nil {
+		return err
+	}
+	params := make([]driver.Value, len(cmd.Params))
+	for i, d := range cmd.Params {
+		if err := d.VerifyChecksum(); err != nil {
+			return err
+		}
+		if d.Decimalval != nil {
+			// Decimals are passed to the executor exactly rather than as the
+			// string returned by Value.
+			if params[i], err = d.DecimalValue(); err != nil {
+				return err
+			}
+			continue
+		}
+		if params[i], err = d.Value(); err != nil {
+			return err
+		}
+	}
+	r, err := sess.exec(stmt, params)
+	if err != nil {
+		return err
+	}
+	return r.fillResponse(resp)
+}
+
+// newSession creates a session using the state reflected back by the client
+// in the request header.
+func (s *Server) newSession(h *sqlwire.SQLRequestHeader) (*session, error) {
+	if h.User == "" {
+		return nil, util.Errorf("missing user")
+	}
+	sess := &session{db: s.clientDB, user: h.User}
+	if h.Session != nil {
+		var state sqlwire.Session
+		if err := gogoproto.Unmarshal(h.Session, &state); err != nil {
+			return nil, err
+		}
+		sess.database = state.Database
+		sess.isolation = state.DefaultIsolation
+		if state.TimeZone != "" {
+			loc, err := time.LoadLocation(state.TimeZone)
+			if err != nil {
+				return nil, err
+			}
+			sess.location = loc
+		}
+		sess.statementTimeout = time.Duration(state.StatementTimeout)
+	}
+	if h.Txn != nil {
+		var txn proto.Transaction
+		if err := gogoproto.Unmarshal(h.Txn, &txn); err != nil {
+			return nil, err
+		}
+		if txn.Status == proto.ABORTED {
+			sess.txnAborted = true
+		} else {
+			sess.txn = s.clientDB.NewTxn(&txn)
+		}
+	}
+	return sess, nil
+}
+
+// encodeSession stores the session state in the response header. The
+// transaction is only returned while a transaction is in progress. An aborted
+// transaction is returned with an ABORTED status.
+func (s *Server) encodeSession(sess *session, h *sqlwire.SQLResponseHeader) error {
+	var err error
+	state := &sqlwire.Session{
+		Database:         sess.database,
+		DefaultIsolation: sess.isolation,
+		StatementTimeout: int64(sess.statementTimeout),
+	}
+	if sess.location != nil {
+		state.TimeZone = sess.location.String()
+	}
+	if h.Settings, err = gogoproto.Marshal(state); err != nil {
+		return err
+	}
+	var txn proto.Transaction
+	switch {
+	case sess.txn != nil:
+		txn = sess.txn.Proto()
+	case sess.txnAborted:
+		txn.Status = proto.ABORTED
+	default:
+		h.Txn = nil
+		return nil
+	}
+	h.Txn, err = gogoproto.Marshal(&txn)
+	return err
 }
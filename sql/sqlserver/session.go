@@ -0,0 +1,1163 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+//
+// Author: Peter Mattis (peter@cockroachlabs.com)
+
+package sqlserver
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/client"
+	"github.com/cockroachdb/cockroach/keys"
+	"github.com/cockroachdb/cockroach/proto"
+	"github.com/cockroachdb/cockroach/sql/parser"
+	"github.com/cockroachdb/cockroach/structured"
+	"github.com/cockroachdb/cockroach/util/log"
+	gogoproto "github.com/gogo/protobuf/proto"
+)
+
+var (
+	errNoTransactionInProgress = errors.New("there is no transaction in progress")
+	errTransactionInProgress   = errors.New("there is already a transaction in progress")
+	errTransactionAborted      = errors.New("current transaction is aborted, commands ignored until end of transaction block")
+)
+
+// The result columns of the SHOW statements.
+var (
+	showColumnsColumns     = []string{"Field", "Type", "Null"}
+	showCreateTableColumns = []string{"Table", "Create Table"}
+	showDatabasesColumns   = []string{"database"}
+	showIndexColumns       = []string{"Table", "Name", "Unique", "Seq", "Column"}
+	showTablesColumns      = []string{"tables"}
+)
+
+// session holds the state of a SQL session: the session variables (see
+// sessionVars) and the transaction started by BEGIN, if any. The session state
+// is returned to the client in every response and reflected back by the client
+// in its next request.
+type session struct {
+	db *client.DB
+	// The authenticated user whose privileges are checked by the statements.
+	user     string
+	database string
+	// The isolation of the transactions started by the session.
+	isolation proto.IsolationType
+	// The time zone in which times are presented to the client and in which
+	// the times of a statement without a time zone are interpreted, nil for
+	// UTC.
+	location *time.Location
+	// The duration after which a statement is canceled, zero if statements
+	// are not canceled.
+	statementTimeout time.Duration
+	// The time at which the statement being executed is canceled, zero if
+	// there is no statement timeout.
+	deadline time.Time
+	// The transaction started by BEGIN, or nil if no transaction is in
+	// progress.
+	txn *client.Txn
+	// Set when a statement within the transaction has failed. The transaction
+	// has been rolled back and all statements are rejected until the
+	// transaction is ended using COMMIT or ROLLBACK.
+	txnAborted bool
+	// The snapshot read by the statement being executed if it has an AS OF
+	// SYSTEM TIME clause, nil otherwise.
+	snapshot *client.Snapshot
+}
+
+// kvReader is implemented by client.DB, client.Txn and client.Snapshot.
+type kvReader interface {
+	scanner
+	Get(key interface{}) (client.KeyValue, error)
+	GetProto(key interface{}, msg gogoproto.Message) error
+}
+
+// reader returns the transaction in progress or the database if there is no
+// transaction in progress. Statements read using the returned reader so that
+// statements within a transaction observe the writes of the transaction. A
+// historical read uses the snapshot it reads from, so that it observes the
+// descriptors which were current at the timestamp of the snapshot.
+func (s *session) reader() kvReader {
+	if s.snapshot != nil {
+		return s.snapshot
+	}
+	if s.txn != nil {
+		return s.txn
+	}
+	return s.db
+}
+
+// timeLocation returns the time zone of the session.
+func (s *session) timeLocation() *time.Location {
+	if s.location == nil {
+		return time.UTC
+	}
+	return s.location
+}
+
+// exec executes the statement, handling the statements which control the
+// transaction of the session. A statement which fails within a transaction
+// aborts the transaction.
+func (s *session) exec(stmt parser.Statement, args []driver.Value) (*rows, error) {
+	switch stmt.(type) {
+	case *parser.BeginTransaction:
+		if s.txn != nil || s.txnAborted {
+			return nil, errTransactionInProgress
+		}
+		s.txn = s.newTxn()
+		return &rows{}, nil
+
+	case *parser.CommitTransaction:
+		if s.txn == nil && !s.txnAborted {
+			return nil, errNoTransactionInProgress
+		}
+		txn, aborted := s.txn, s.txnAborted
+		s.txn, s.txnAborted = nil, false
+		if aborted {
+			return nil, errTransactionAborted
+		}
+		if err := txn.Commit(&client.Batch{}); err != nil {
+			abortTxn(txn)
+			return nil, err
+		}
+		return &rows{}, nil
+
+	case *parser.RollbackTransaction:
+		txn := s.txn
+		s.txn, s.txnAborted = nil, false
+		if txn != nil {
+			if err := txn.Rollback(); err != nil {
+				return nil, err
+			}
+		}
+		return &rows{}, nil
+	}
+
+	if s.txnAborted {
+		return nil, errTransactionAborted
+	}
+	s.deadline = time.Time{}
+	if s.statementTimeout != 0 {
+		s.deadline = time.Now().Add(s.statementTimeout)
+	}
+	stmt, args = s.localizeTimes(stmt, args)
+	r, err := s.query(stmt, args)
+	if err != nil && s.txn != nil {
+		abortTxn(s.txn)
+		s.txn, s.txnAborted = nil, true
+	}
+	return r, err
+}
+
+// describe returns the names of the result columns of the statement without
+// executing it. Statements which do not return any results have no columns.
+func (s *session) describe(stmt parser.Statement) ([]string, error) {
+	switch p := stmt.(type) {
+	case parser.SelectStatement:
+		if s.txnAborted {
+			return nil, errTransactionAborted
+		}
+		return s.selectColumns(p)
+	case *parser.Explain:
+		return explainColumns, nil
+	case *parser.Show:
+		return showColumns(p)
+	case *parser.ShowColumns:
+		return showColumnsColumns, nil
+	case *parser.ShowCreateTable:
+		return showCreateTableColumns, nil
+	case *parser.ShowDatabases:
+		return showDatabasesColumns, nil
+	case *parser.ShowIndex:
+		return showIndexColumns, nil
+	case *parser.ShowTables:
+		return showTablesColumns, nil
+	}
+	return nil, nil
+}
+
+// abortTxn rolls back the transaction, logging any error. The transaction is
+// eventually aborted by its coordinator if the rollback fails.
+func abortTxn(txn *client.Txn) {
+	if err := txn.Rollback(); err != nil {
+		log.Warningf("unable to abort transaction: %s", err)
+	}
+}
+
+// runInTxn runs fn within the transaction in progress or, if there is no
+// transaction in progress, within a new transaction using the isolation of the
+// session. The writes queued by fn on the batch are committed along with the
+// new transaction unless the statement has timed out.
+func (s *session) runInTxn(fn func(txn *client.Txn, b *client.Batch) error) error {
+	if s.txn != nil {
+		b := &client.Batch{}
+		if err := fn(s.txn, b); err != nil {
+			return err
+		}
+		if err := s.checkTimeout(); err != nil {
+			return err
+		}
+		return s.txn.Run(b)
+	}
+	return s.db.Txn(func(txn *client.Txn) error {
+		s.setIsolation(txn)
+		b := &client.Batch{}
+		if err := fn(txn, b); err != nil {
+			return err
+		}
+		if err := s.checkTimeout(); err != nil {
+			return err
+		}
+		return txn.Commit(b)
+	})
+}
+
+func (s *session) query(stmt parser.Statement, args []driver.Value) (*rows, error) {
+	if s.txn != nil {
+		switch stmt.(type) {
+		case *parser.AlterTable, *parser.AlterView, *parser.CreateDatabase,
+			*parser.CreateIndex, *parser.CreateTable, *parser.CreateView,
+			*parser.DropDatabase, *parser.DropIndex, *parser.DropTable,
+			*parser.DropView, *parser.Grant, *parser.Import, *parser.RenameTable,
+			*parser.Revoke, *parser.TruncateTable:
+			// Schema changes and imports are performed using transactions of
+			// their own.
+			return nil, fmt.Errorf("%s is not supported within a transaction", stmt)
+		}
+	}
+
+	switch p := stmt.(type) {
+	case *parser.AlterTable:
+		return s.AlterTable(p, args)
+	case *parser.AlterView:
+		return s.AlterView(p, args)
+	case *parser.CreateDatabase:
+		return s.CreateDatabase(p, args)
+	case *parser.CreateIndex:
+		return s.CreateIndex(p, args)
+	case *parser.CreateTable:
+		return s.CreateTable(p, args)
+	case *parser.CreateView:
+		return s.CreateView(p, args)
+	case *parser.Delete:
+		return s.Delete(p, args)
+	case *parser.DropDatabase:
+		return s.DropDatabase(p, args)
+	case *parser.DropIndex:
+		return s.DropIndex(p, args)
+	case *parser.DropTable:
+		return s.DropTable(p, args)
+	case *parser.DropView:
+		return s.DropView(p, args)
+	case *parser.Explain:
+		return s.Explain(p, args)
+	case *parser.Grant:
+		return s.Grant(p, args)
+	case *parser.Import:
+		return s.Import(p, args)
+	case *parser.Insert:
+		return s.Insert(p, args)
+	case *parser.RenameTable:
+		return s.RenameTable(p, args)
+	case *parser.Revoke:
+		return s.Revoke(p, args)
+	case *parser.Select:
+		return s.Select(p, args)
+	case *parser.Set:
+		return s.Set(p, args)
+	case *parser.Show:
+		return s.Show(p, args)
+	case *parser.ShowColumns:
+		return s.ShowColumns(p, args)
+	case *parser.ShowCreateTable:
+		return s.ShowCreateTable(p, args)
+	case *parser.ShowDatabases:
+		return s.ShowDatabases(p, args)
+	case *parser.ShowIndex:
+		return s.ShowIndex(p, args)
+	case *parser.ShowTables:
+		return s.ShowTables(p, args)
+	case *parser.TruncateTable:
+		return s.TruncateTable(p, args)
+	case *parser.Union:
+		return s.Select(p, args)
+	case *parser.Update:
+		return s.Update(p, args)
+	case *parser.Use:
+		return s.Use(p, args)
+	default:
+		return nil, fmt.Errorf("unknown statement type: %T", stmt)
+	}
+}
+
+func (s *session) CreateDatabase(p *parser.CreateDatabase, args []driver.Value) (*rows, error) {
+	if p.Name == "" {
+		return nil, fmt.Errorf("empty database name")
+	}
+
+	if isInformationSchema(p.Name) {
+		if p.IfNotExists {
+			return &rows{}, nil
+		}
+		return nil, fmt.Errorf("database \"%s\" already exists", p.Name)
+	}
+	nameKey := keys.MakeNameMetadataKey(structured.RootNamespaceID, strings.ToLower(p.Name))
+	if gr, err := s.db.Get(nameKey); err != nil {
+		return nil, err
+	} else if gr.Exists() {
+		if p.IfNotExists {
+			return &rows{}, nil
+		}
+		return nil, fmt.Errorf("database \"%s\" already exists", p.Name)
+	}
+	ir, err := s.db.Inc(keys.DescIDGenerator, 1)
+	if err != nil {
+		return nil, err
+	}
+	desc := structured.DatabaseDescriptor{
+		ID:         uint32(ir.ValueInt() - 1),
+		Name:       strings.ToLower(p.Name),
+		Privileges: structured.NewPrivilegeDescriptor(s.user),
+	}
+	err = s.db.Txn(func(txn *client.Txn) error {
+		b := &client.Batch{}
+		b.CPut(nameKey, desc.ID, nil)
+		b.Put(keys.MakeDescMetadataKey(desc.ID), &desc)
+		return txn.Commit(b)
+	})
+	if err != nil {
+		// TODO(pmattis): Need to handle if-not-exists here as well.
+		return nil, err
+	}
+	return &rows{}, nil
+}
+
+func (s *session) CreateTable(p *parser.CreateTable, args []driver.Value) (*rows, error) {
+	if err := s.normalizeTableName(p.Name); err != nil {
+		return nil, err
+	}
+
+	dbDesc, err := s.getDatabaseDesc(p.Name.Qualifier)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkDatabasePrivilege(dbDesc, structured.AllPrivilege); err != nil {
+		return nil, err
+	}
+
+	schema, err := makeSchema(p)
+	if err != nil {
+		return nil, err
+	}
+	desc := structured.TableDescFromSchema(schema)
+	desc.Privileges = structured.NewPrivilegeDescriptor(s.user)
+	if err := structured.ValidateTableDesc(desc); err != nil {
+		return nil, err
+	}
+
+	nameKey := keys.MakeNameMetadataKey(dbDesc.ID, p.Name.Name)
+
+	// This isn't strictly necessary as the conditional put below will fail if
+	// the key already exists, but it seems good to avoid the table ID allocation
+	// in most cases when the table already exists.
+	if gr, err := s.db.Get(nameKey); err != nil {
+		return nil, err
+	} else if gr.Exists() {
+		if p.IfNotExists {
+			return &rows{}, nil
+		}
+		return nil, fmt.Errorf("table \"%s\" already exists", p.Name.Name)
+	}
+
+	ir, err := s.db.Inc(keys.DescIDGenerator, 1)
+	if err != nil {
+		return nil, err
+	}
+	desc.ID = uint32(ir.ValueInt() - 1)
+
+	// TODO(pmattis): Be cognizant of error messages when this is ported to the
+	// server. The error currently returned below is likely going to be difficult
+	// to interpret.
+	err = s.db.Txn(func(txn *client.Txn) error {
+		descKey := keys.MakeDescMetadataKey(desc.ID)
+		b := &client.Batch{}
+		b.CPut(nameKey, descKey, nil)
+		b.Put(descKey, &desc)
+		return txn.Commit(b)
+	})
+	if err != nil {
+		// TODO(pmattis): Need to handle if-not-exists here as well.
+		return nil, err
+	}
+	return &rows{}, nil
+}
+
+func (s *session) Delete(p *parser.Delete, args []driver.Value) (*rows, error) {
+	desc, err := s.getTableDesc(p.Table, structured.DeletePrivilege)
+	if err != nil {
+		return nil, err
+	}
+
+	where := whereExpr(p.Where)
+	if err := typeCheckCond(where, joinEnv{{desc: desc, alias: p.Table.Name}}, args); err != nil {
+		return nil, err
+	}
+	loc := s.timeLocation()
+	where = foldConstExpr(where, args, loc)
+
+	var count int
+	err = s.runInTxn(func(txn *client.Txn, b *client.Batch) error {
+		if err := refreshTableDesc(txn, desc); err != nil {
+			return err
+		}
+		plan, err := makeScanPlan(desc, p.Table.Name, splitAndExpr(where, nil), nil, nil, args, loc)
+		if err != nil {
+			return err
+		}
+		tableRows, err := selectRows(txn, plan, p.Table.Name, where, p.OrderBy, p.Limit, args, loc)
+		if err != nil {
+			return err
+		}
+		for i := range tableRows {
+			if err := deleteRow(b, desc, tableRows[i]); err != nil {
+				return err
+			}
+		}
+		count = len(tableRows)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &rows{rowsAffected: count}, nil
+}
+
+func (s *session) DropDatabase(p *parser.DropDatabase, args []driver.Value) (*rows, error) {
+	if p.Name == "" {
+		return nil, fmt.Errorf("empty database name")
+	}
+	name := strings.ToLower(p.Name)
+
+	// Remove the database and all of its tables, including their data, in a
+	// single transaction so that the database disappears atomically and no
+	// data is left behind if the transaction fails. The database cannot be
+	// dropped while views of other databases depend on its tables.
+	err := s.db.Txn(func(txn *client.Txn) error {
+		nameKey := keys.MakeNameMetadataKey(structured.RootNamespaceID, name)
+		gr, err := txn.Get(nameKey)
+		if err != nil {
+			return err
+		}
+		if !gr.Exists() {
+			if p.IfExists {
+				return nil
+			}
+			return fmt.Errorf("database \"%s\" does not exist", p.Name)
+		}
+		dbDesc := structured.DatabaseDescriptor{}
+		dbDescKey := keys.MakeDescMetadataKey(uint32(gr.ValueInt()))
+		if err := txn.GetProto(dbDescKey, &dbDesc); err != nil {
+			return err
+		}
+		dbDesc.Name = name
+		if err := s.checkDatabasePrivilege(&dbDesc, structured.AllPrivilege); err != nil {
+			return err
+		}
+
+		prefix := keys.MakeNameMetadataKey(uint32(gr.ValueInt()), "")
+		sr, err := txn.Scan(prefix, prefix.PrefixEnd(), 0)
+		if err != nil {
+			return err
+		}
+		b := &client.Batch{}
+		descs := make([]structured.TableDescriptor, len(sr))
+		dropped := map[uint32]struct{}{}
+		for i, row := range sr {
+			descKey := row.ValueBytes()
+			if err := txn.GetProto(descKey, &descs[i]); err != nil {
+				return err
+			}
+			dropped[descs[i].ID] = struct{}{}
+			b.Del(row.Key, descKey)
+			delTableData(b, descs[i].ID)
+		}
+		for i := range descs {
+			if err := checkNoDependents(txn, &descs[i], "drop", dropped); err != nil {
+				return err
+			}
+			if err := removeDependencies(txn, &descs[i], dropped); err != nil {
+				return err
+			}
+		}
+		b.Del(nameKey, dbDescKey)
+		return txn.Commit(b)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if s.database == name {
+		s.database = ""
+	}
+	return &rows{}, nil
+}
+
+func (s *session) DropTable(p *parser.DropTable, args []driver.Value) (*rows, error) {
+	if _, err := s.dropTableDesc(p.Name, p.IfExists, false); err != nil {
+		return nil, err
+	}
+	return &rows{}, nil
+}
+
+// dropTableDesc removes the name, the descriptor and the data of a table or,
+// if view is set, the name and the descriptor of a view, in a single
+// transaction. Nil is returned if the table or view, or its database, does
+// not exist and ifExists is set.
+func (s *session) dropTableDesc(name *parser.TableName, ifExists, view bool) (*structured.TableDescriptor, error) {
+	if err := s.normalizeTableName(name); err != nil {
+		return nil, err
+	}
+	kind := "table"
+	if view {
+		kind = "view"
+	}
+
+	desc := structured.TableDescriptor{}
+	err := s.db.Txn(func(txn *client.Txn) error {
+		desc.Reset()
+		gr, err := txn.Get(keys.MakeNameMetadataKey(structured.RootNamespaceID, name.Qualifier))
+		if err != nil {
+			return err
+		}
+		if !gr.Exists() {
+			if ifExists {
+				return nil
+			}
+			return fmt.Errorf("database \"%s\" does not exist", name.Qualifier)
+		}
+		nameKey := keys.MakeNameMetadataKey(uint32(gr.ValueInt()), name.Name)
+		if gr, err = txn.Get(nameKey); err != nil {
+			return err
+		}
+		if !gr.Exists() {
+			if ifExists {
+				return nil
+			}
+			return fmt.Errorf("%s \"%s\" does not exist", kind, name)
+		}
+		descKey := gr.ValueBytes()
+		if err := txn.GetProto(descKey, &desc); err != nil {
+			return err
+		}
+		if desc.IsView() != view {
+			return fmt.Errorf("\"%s\" is not a %s", name, kind)
+		}
+		if err := s.checkTablePrivilege(name, &desc, structured.AllPrivilege); err != nil {
+			return err
+		}
+		if err := checkNoDependents(txn, &desc, "drop", nil); err != nil {
+			return err
+		}
+		b := &client.Batch{}
+		b.Del(nameKey, descKey)
+		if !view {
+			delTableData(b, desc.ID)
+		}
+		if err := removeDependencies(txn, &desc, nil); err != nil {
+			return err
+		}
+		return txn.Commit(b)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if desc.ID == 0 {
+		return nil, nil
+	}
+	return &desc, nil
+}
+
+func (s *session) Insert(p *parser.Insert, args []driver.Value) (*rows, error) {
+	desc, err := s.getTableDesc(p.Table, structured.InsertPrivilege)
+	if err != nil {
+		return nil, err
+	}
+
+	// Transform the values into a rows object. This expands SELECT statements
+	// or generates rows from the values contained within the query.
+	r, err := s.processInsertRows(p.Rows, args)
+	if err != nil {
+		return nil, err
+	}
+
+	// Expand the values into full table rows. The rows are recomputed below if
+	// the table's schema changes before the rows are written.
+	tableRows, err := makeInsertRows(desc, p.Columns, r.rows, s.allocateRowIDs)
+	if err != nil {
+		return nil, err
+	}
+	version := desc.Version
+
+	if err := s.runInTxn(func(txn *client.Txn, b *client.Batch) error {
+		if err := refreshTableDesc(txn, desc); err != nil {
+			return err
+		}
+		if desc.Version != version {
+			if tableRows, err = makeInsertRows(desc, p.Columns, r.rows, s.allocateRowIDs); err != nil {
+				return err
+			}
+			version = desc.Version
+		}
+		for _, vals := range tableRows {
+			if err := insertRow(b, desc, vals); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, convertBatchError(desc, err)
+	}
+
+	return &rows{rowsAffected: len(r.rows)}, nil
+}
+
+func (s *session) ShowColumns(p *parser.ShowColumns, args []driver.Value) (*rows, error) {
+	desc, err := s.lookupTableDesc(p.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkTablePrivilege(p.Name, desc, anyPrivilege); err != nil {
+		return nil, err
+	}
+	// TODO(pmattis): This output doesn't match up with MySQL. Should it?
+	return s.queryCatalog(showColumnsColumns, `
+SELECT column_name, data_type, is_nullable = 1 FROM information_schema.columns
+WHERE table_schema = $1 AND table_name = $2`, p.Name.Qualifier, p.Name.Name)
+}
+
+func (s *session) ShowCreateTable(p *parser.ShowCreateTable, args []driver.Value) (*rows, error) {
+	desc, err := s.lookupTableDesc(p.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkTablePrivilege(p.Name, desc, anyPrivilege); err != nil {
+		return nil, err
+	}
+	if desc.IsView() {
+		return &rows{
+			columns: showCreateTableColumns,
+			rows:    []row{{p.Name.Name, createViewString(p.Name, desc)}},
+		}, nil
+	}
+	schema := structured.TableSchemaFromDesc(*desc)
+	schema.Name = p.Name.String()
+	return &rows{
+		columns: showCreateTableColumns,
+		rows:    []row{{p.Name.Name, createTableString(schema)}},
+	}, nil
+}
+
+func (s *session) ShowDatabases(p *parser.ShowDatabases, args []driver.Value) (*rows, error) {
+	return s.queryCatalog(showDatabasesColumns, `
+SELECT database_name FROM information_schema.databases`)
+}
+
+func (s *session) ShowIndex(p *parser.ShowIndex, args []driver.Value) (*rows, error) {
+	desc, err := s.lookupTableDesc(p.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkTablePrivilege(p.Name, desc, anyPrivilege); err != nil {
+		return nil, err
+	}
+	// TODO(pmattis): This output doesn't match up with MySQL. Should it?
+	return s.queryCatalog(showIndexColumns, `
+SELECT table_name, index_name, is_unique = 1, seq_in_index, column_name
+FROM information_schema.indexes WHERE table_schema = $1 AND table_name = $2`,
+		p.Name.Qualifier, p.Name.Name)
+}
+
+func (s *session) ShowTables(p *parser.ShowTables, args []driver.Value) (*rows, error) {
+	if p.Name == "" {
+		if s.database == "" {
+			return nil, fmt.Errorf("no database specified")
+		}
+		p.Name = s.database
+	}
+	if _, err := s.lookupDatabase(p.Name); err != nil {
+		return nil, err
+	}
+	return s.queryCatalog(showTablesColumns, `
+SELECT table_name FROM information_schema.tables WHERE table_schema = $1`, p.Name)
+}
+
+// queryCatalog executes a SELECT statement querying the tables of the
+// information_schema database, naming the result columns as specified.
+func (s *session) queryCatalog(columns []string, query string, args ...driver.Value) (*rows, error) {
+	stmt, err := parser.Parse(query)
+	if err != nil {
+		return nil, err
+	}
+	r, err := s.Select(stmt.(*parser.Select), args)
+	if err != nil {
+		return nil, err
+	}
+	r.columns = columns
+	return r, nil
+}
+
+func (s *session) TruncateTable(p *parser.TruncateTable, args []driver.Value) (*rows, error) {
+	desc, err := s.getTableDesc(p.Name, structured.DeletePrivilege)
+	if err != nil {
+		return nil, err
+	}
+	// The data is deleted within the transaction which increments the version
+	// of the descriptor, so that writes to the table which read the
+	// descriptor are ordered with respect to the deletion: they either happen
+	// before it and are deleted, or happen after it and are kept.
+	err = updateTableDesc(s.db, desc, func(txn *client.Txn, b *client.Batch) error {
+		delTableData(b, desc.ID)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &rows{}, nil
+}
+
+func (s *session) Update(p *parser.Update, args []driver.Value) (*rows, error) {
+	desc, err := s.getTableDesc(p.Table, structured.UpdatePrivilege)
+	if err != nil {
+		return nil, err
+	}
+
+	// Determine which columns we're updating. The assignments are resolved
+	// again below if the table's schema changes before the rows are written.
+	exprs, err := makeUpdateExprs(desc, p.Exprs)
+	if err != nil {
+		return nil, err
+	}
+	version := desc.Version
+
+	where := whereExpr(p.Where)
+	env := joinEnv{{desc: desc, alias: p.Table.Name}}
+	for i, expr := range exprs {
+		t, err := typeCheckExpr(expr, env, args)
+		if err != nil {
+			return nil, err
+		}
+		if err := typeCheckAssignment(&desc.Columns[i], t); err != nil {
+			return nil, err
+		}
+	}
+	if err := typeCheckCond(where, env, args); err != nil {
+		return nil, err
+	}
+	loc := s.timeLocation()
+	where = foldConstExpr(where, args, loc)
+
+	var count int
+	err = s.runInTxn(func(txn *client.Txn, b *client.Batch) error {
+		if err := refreshTableDesc(txn, desc); err != nil {
+			return err
+		}
+		if desc.Version != version {
+			if exprs, err = makeUpdateExprs(desc, p.Exprs); err != nil {
+				return err
+			}
+			version = desc.Version
+		}
+		plan, err := makeScanPlan(desc, p.Table.Name, splitAndExpr(where, nil), nil, nil, args, loc)
+		if err != nil {
+			return err
+		}
+		tableRows, err := selectRows(txn, plan, p.Table.Name, where, p.OrderBy, p.Limit, args, loc)
+		if err != nil {
+			return err
+		}
+		for i := range tableRows {
+			// The new values are computed using the old values of the row.
+			e := &tableEnv{desc: desc, alias: p.Table.Name, row: &tableRows[i]}
+			newVals := make([]driver.Value, len(desc.Columns))
+			copy(newVals, tableRows[i].vals)
+			for j, expr := range exprs {
+				v, err := evalExpr(expr, e, args, loc)
+				if err != nil {
+					return err
+				}
+				if newVals[j], err = checkColumnValue(desc.Columns[j], v); err != nil {
+					return err
+				}
+			}
+			if err := fillWriteOnlyColumns(desc, newVals, s.allocateRowIDs); err != nil {
+				return err
+			}
+			if err := checkPrimaryKeyValues(desc, newVals); err != nil {
+				return err
+			}
+			if err := updateRow(b, desc, tableRows[i], newVals); err != nil {
+				return err
+			}
+		}
+		count = len(tableRows)
+		return nil
+	})
+	if err != nil {
+		return nil, convertBatchError(desc, err)
+	}
+	return &rows{rowsAffected: count}, nil
+}
+
+func (s *session) Use(p *parser.Use, args []driver.Value) (*rows, error) {
+	s.database = p.Name
+	return &rows{}, nil
+}
+
+// processColumns returns the column descriptors for the named columns. If no
+// columns are specified all of the public columns in the table are returned in
+// the order they were defined.
+func processColumns(desc *structured.TableDescriptor,
+	node parser.Columns) ([]structured.ColumnDescriptor, error) {
+	if node == nil {
+		var cols []structured.ColumnDescriptor
+		for _, col := range desc.Columns {
+			if col.IsPublic() {
+				cols = append(cols, col)
+			}
+		}
+		return cols, nil
+	}
+
+	cols := make([]structured.ColumnDescriptor, len(node))
+	for i, n := range node {
+		switch nt := n.(type) {
+		case *parser.StarExpr:
+			return processColumns(desc, nil)
+		case *parser.NonStarExpr:
+			switch et := nt.Expr.(type) {
+			case *parser.ColName:
+				col, err := desc.FindColumnByName(strings.ToLower(et.Name))
+				if err != nil {
+					return nil, err
+				}
+				cols[i] = *col
+			default:
+				return nil, fmt.Errorf("unexpected column expression: %T", nt.Expr)
+			}
+		default:
+			return nil, fmt.Errorf("unexpected column: %T", n)
+		}
+	}
+	return cols, nil
+}
+
+// processInsertRows transforms the rows of an INSERT statement into a rows
+// object.
+func (s *session) processInsertRows(node parser.InsertRows, args []driver.Value) (*rows, error) {
+	switch nt := node.(type) {
+	case parser.Values:
+		r := &rows{}
+		for _, tuple := range nt {
+			data, ok := tuple.(parser.ValTuple)
+			if !ok {
+				return nil, fmt.Errorf("unsupported tuple: %T", tuple)
+			}
+			var vals row
+			for _, val := range data {
+				d, err := evalConstExpr(val, args, s.timeLocation())
+				if err != nil {
+					return nil, err
+				}
+				vals = append(vals, d)
+			}
+			r.rows = append(r.rows, vals)
+		}
+		return r, nil
+	case parser.SelectStatement:
+		return s.Select(nt, args)
+	}
+	return nil, fmt.Errorf("unsupported node: %T", node)
+}
+
+// makeInsertRows expands the values of an INSERT statement into full table
+// rows ordered by the table's columns. Columns which are not specified,
+// including the write-only columns, are set to their default value and the
+// values are checked against the column types. The unique row IDs of SERIAL
+// columns are allocated using allocIDs.
+func makeInsertRows(desc *structured.TableDescriptor, node parser.Columns,
+	values []row, allocIDs func(n int) (int64, error)) ([][]driver.Value, error) {
+	// Determine which columns we're inserting into.
+	cols, err := processColumns(desc, node)
+	if err != nil {
+		return nil, err
+	}
+
+	// Construct a map from column ID to the index the value appears at within a
+	// row.
+	colMap := map[uint32]int{}
+	for i, col := range cols {
+		colMap[col.ID] = i
+	}
+
+	// Compute the values of the columns which are not specified. Verify that
+	// the columns that are part of the primary key and all of the non-nullable
+	// columns are either specified or have a default.
+	defaults := make([]driver.Value, len(desc.Columns))
+	var rowIDCols []int
+	for i, col := range desc.Columns {
+		if _, ok := colMap[col.ID]; ok {
+			continue
+		}
+		if hasUniqueRowIDDefault(col) {
+			rowIDCols = append(rowIDCols, i)
+			continue
+		}
+		if col.DefaultExpr == nil {
+			if desc.Indexes[0].ContainsColumnID(col.ID) {
+				return nil, fmt.Errorf("missing \"%s\" primary key column", col.Name)
+			}
+			if !col.Nullable {
+				return nil, fmt.Errorf("missing value for not-null column \"%s\"", col.Name)
+			}
+			continue
+		}
+		if defaults[i], err = evalDefaultExpr(col); err != nil {
+			return nil, err
+		}
+	}
+
+	tableRows := make([][]driver.Value, 0, len(values))
+	for _, r := range values {
+		if len(r) != len(cols) {
+			return nil, fmt.Errorf("INSERT has %d values but %d columns", len(r), len(cols))
+		}
+		vals := make([]driver.Value, len(desc.Columns))
+		for i, col := range desc.Columns {
+			j, ok := colMap[col.ID]
+			if !ok {
+				vals[i] = defaults[i]
+				continue
+			}
+			if vals[i], err = checkColumnValue(col, r[j]); err != nil {
+				return nil, err
+			}
+		}
+		tableRows = append(tableRows, vals)
+	}
+
+	if len(rowIDCols) > 0 && len(tableRows) > 0 {
+		id, err := allocIDs(len(rowIDCols) * len(tableRows))
+		if err != nil {
+			return nil, err
+		}
+		for _, vals := range tableRows {
+			for _, i := range rowIDCols {
+				vals[i] = id
+				id++
+			}
+		}
+	}
+	for _, vals := range tableRows {
+		if err := checkPrimaryKeyValues(desc, vals); err != nil {
+			return nil, err
+		}
+	}
+	return tableRows, nil
+}
+
+// allocateRowIDs allocates n consecutive unique row IDs, returning the first.
+func (s *session) allocateRowIDs(n int) (int64, error) {
+	return allocateRowIDs(s.db, n)
+}
+
+// makeUpdateExprs determines which columns are assigned by an UPDATE
+// statement. The returned map is from the position of the column within the
+// table to the expression for the column's new value.
+func makeUpdateExprs(desc *structured.TableDescriptor,
+	node parser.UpdateExprs) (map[int]parser.ValExpr, error) {
+	exprs := map[int]parser.ValExpr{}
+	for _, e := range node {
+		col, err := desc.FindColumnByName(strings.ToLower(e.Name.Name))
+		if err != nil {
+			return nil, err
+		}
+		for i := range desc.Columns {
+			if desc.Columns[i].ID == col.ID {
+				if _, ok := exprs[i]; ok {
+					return nil, fmt.Errorf("multiple assignments to same column \"%s\"", col.Name)
+				}
+				exprs[i] = e.Expr
+			}
+		}
+	}
+	return exprs, nil
+}
+
+// getTableDesc returns the descriptor of a table which is modified by a
+// statement requiring the privilege. The virtual tables of the
+// information_schema database are read-only and views cannot be modified.
+func (s *session) getTableDesc(name *parser.TableName,
+	priv structured.Privilege) (*structured.TableDescriptor, error) {
+	if err := s.normalizeTableName(name); err != nil {
+		return nil, err
+	}
+	if isInformationSchema(name.Qualifier) {
+		return nil, fmt.Errorf("table \"%s\" is read-only", name)
+	}
+	desc, err := s.readTableDesc(name)
+	if err != nil {
+		return nil, err
+	}
+	if desc.IsView() {
+		return nil, fmt.Errorf("cannot modify view \"%s\"", name)
+	}
+	if err := s.checkTablePrivilege(name, desc, priv); err != nil {
+		return nil, err
+	}
+	return desc, nil
+}
+
+// readTableDesc reads the descriptor of the table or view of the normalized
+// name.
+func (s *session) readTableDesc(name *parser.TableName) (*structured.TableDescriptor, error) {
+	dbID, err := s.lookupDatabase(name.Qualifier)
+	if err != nil {
+		return nil, err
+	}
+	gr, err := s.reader().Get(keys.MakeNameMetadataKey(dbID, name.Name))
+	if err != nil {
+		return nil, err
+	}
+	if !gr.Exists() {
+		return nil, fmt.Errorf("table \"%s\" does not exist", name)
+	}
+	descKey := gr.ValueBytes()
+	desc := structured.TableDescriptor{}
+	if err := s.reader().GetProto(descKey, &desc); err != nil {
+		return nil, err
+	}
+	if err := structured.ValidateTableDesc(desc); err != nil {
+		return nil, err
+	}
+	return &desc, nil
+}
+
+// lookupTableDesc returns the descriptor of a table which is read by a
+// statement, which might be a view or one of the virtual tables of the
+// information_schema database.
+func (s *session) lookupTableDesc(name *parser.TableName) (*structured.TableDescriptor, error) {
+	if err := s.normalizeTableName(name); err != nil {
+		return nil, err
+	}
+	if isInformationSchema(name.Qualifier) {
+		return getVirtualTableDesc(name)
+	}
+	return s.readTableDesc(name)
+}
+
+// delTableData adds the deletion of all of the data of the table, including
+// the data of all of its indexes, to the batch.
+//
+// TODO: Clear the ranges containing the table data directly instead
+// of deleting the keys one at a time.
+func delTableData(b *client.Batch, tableID uint32) {
+	prefix := keys.MakeTablePrefix(tableID)
+	if log.V(2) {
+		log.Infof("DelRange %q - %q", prefix, prefix.PrefixEnd())
+	}
+	b.DelRange(prefix, prefix.PrefixEnd())
+}
+
+// refreshTableDesc re-reads the table descriptor within the transaction.
+// Statements which modify the rows of a table read the descriptor within the
+// transaction performing the writes. This orders the writes with respect to
+// schema changes such as the addition of an index: the rows are either written
+// using the new descriptor or are visible to the index backfill.
+func refreshTableDesc(txn *client.Txn, desc *structured.TableDescriptor) error {
+	name := desc.Name
+	if err := txn.GetProto(keys.MakeDescMetadataKey(desc.ID), desc); err != nil {
+		return err
+	}
+	if desc.ID == 0 {
+		return fmt.Errorf("table \"%s\" does not exist", name)
+	}
+	return structured.ValidateTableDesc(*desc)
+}
+
+// updateTableDesc modifies the table descriptor within a transaction. The
+// descriptor is re-read, passed to fn for modification, validated and written
+// back with an incremented version. The batch is committed along with the
+// descriptor.
+func updateTableDesc(db *client.DB, desc *structured.TableDescriptor,
+	fn func(txn *client.Txn, b *client.Batch) error) error {
+	return db.Txn(func(txn *client.Txn) error {
+		if err := refreshTableDesc(txn, desc); err != nil {
+			return err
+		}
+		b := &client.Batch{}
+		if err := fn(txn, b); err != nil {
+			return err
+		}
+		desc.Version++
+		if err := structured.ValidateTableDesc(*desc); err != nil {
+			return err
+		}
+		b.Put(keys.MakeDescMetadataKey(desc.ID), desc)
+		return txn.Commit(b)
+	})
+}
+
+func (s *session) normalizeTableName(name *parser.TableName) error {
+	if name.Qualifier == "" {
+		if s.database == "" {
+			return fmt.Errorf("no database specified")
+		}
+		name.Qualifier = s.database
+	}
+	if name.Name == "" {
+		return fmt.Errorf("empty table name: %s", name.Name)
+	}
+	return nil
+}
+
+func (s *session) lookupDatabase(name string) (uint32, error) {
+	nameKey := keys.MakeNameMetadataKey(structured.RootNamespaceID, name)
+	gr, err := s.reader().Get(nameKey)
+	if err != nil {
+		return 0, err
+	} else if !gr.Exists() {
+		return 0, fmt.Errorf("database \"%s\" does not exist", name)
+	}
+	return uint32(gr.ValueInt()), nil
+}
+
+// getDatabaseDesc returns the descriptor of the database. A database created
+// before privileges were stored has no descriptor, in which case a descriptor
+// without privileges is returned.
+func (s *session) getDatabaseDesc(name string) (*structured.DatabaseDescriptor, error) {
+	id, err := s.lookupDatabase(name)
+	if err != nil {
+		return nil, err
+	}
+	desc := structured.DatabaseDescriptor{}
+	if err := s.reader().GetProto(keys.MakeDescMetadataKey(id), &desc); err != nil {
+		return nil, err
+	}
+	desc.ID, desc.Name = id, name
+	return &desc, nil
+}
@@ -0,0 +1,244 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package sqlserver
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/client"
+	"github.com/cockroachdb/cockroach/proto"
+	"github.com/cockroachdb/cockroach/sql/parser"
+)
+
+var errStatementTimeout = errors.New("statement canceled due to statement timeout")
+
+// The result columns of SHOW ALL.
+var showAllColumns = []string{"Variable", "Value"}
+
+// sessionVar is a variable of the session which can be set using SET and
+// shown using SHOW.
+type sessionVar struct {
+	name string
+	get  func(s *session) string
+	set  func(s *session, v driver.Value) error
+}
+
+// sessionVars are the variables of the session in the order they are listed
+// by SHOW ALL.
+var sessionVars = []sessionVar{
+	{
+		name: "database",
+		get: func(s *session) string {
+			return s.database
+		},
+		set: func(s *session, v driver.Value) error {
+			name, ok := v.(string)
+			if !ok {
+				return errInvalidValue("database", v)
+			}
+			s.database = name
+			return nil
+		},
+	},
+	{
+		name: "default_transaction_isolation",
+		get: func(s *session) string {
+			return s.isolation.String()
+		},
+		set: func(s *session, v driver.Value) error {
+			name, ok := v.(string)
+			if !ok {
+				return errInvalidValue("default_transaction_isolation", v)
+			}
+			isolation, ok := proto.IsolationType_value[strings.ToUpper(name)]
+			if !ok {
+				return errInvalidValue("default_transaction_isolation", v)
+			}
+			s.isolation = proto.IsolationType(isolation)
+			return nil
+		},
+	},
+	{
+		name: "time_zone",
+		get: func(s *session) string {
+			if s.location == nil {
+				return "UTC"
+			}
+			return s.location.String()
+		},
+		set: func(s *session, v driver.Value) error {
+			name, ok := v.(string)
+			if !ok {
+				return errInvalidValue("time_zone", v)
+			}
+			loc, err := time.LoadLocation(name)
+			if err != nil {
+				return fmt.Errorf("unknown time zone \"%s\"", name)
+			}
+			s.location = loc
+			return nil
+		},
+	},
+	{
+		name: "statement_timeout",
+		get: func(s *session) string {
+			if s.statementTimeout == 0 {
+				return "0"
+			}
+			return s.statementTimeout.String()
+		},
+		set: func(s *session, v driver.Value) error {
+			// A number is a duration in milliseconds.
+			var d time.Duration
+			switch t := v.(type) {
+			case int64:
+				d = time.Duration(t) * time.Millisecond
+			case string:
+				var err error
+				if d, err = time.ParseDuration(t); err != nil {
+					return errInvalidValue("statement_timeout", v)
+				}
+			default:
+				return errInvalidValue("statement_timeout", v)
+			}
+			if d < 0 {
+				return errInvalidValue("statement_timeout", v)
+			}
+			s.statementTimeout = d
+			return nil
+		},
+	},
+}
+
+func errInvalidValue(name string, v driver.Value) error {
+	return fmt.Errorf("invalid value for %s: %v", name, v)
+}
+
+// lookupSessionVar returns the session variable of the specified name.
+func lookupSessionVar(name string) (*sessionVar, error) {
+	for i := range sessionVars {
+		if sessionVars[i].name == name {
+			return &sessionVars[i], nil
+		}
+	}
+	return nil, fmt.Errorf("unknown variable: \"%s\"", name)
+}
+
+// Set executes a SET statement. The value of a variable is either a constant
+// expression or a name, such as the name of a database. Either all or none of
+// the variables are set.
+func (s *session) Set(p *parser.Set, args []driver.Value) (*rows, error) {
+	updated := *s
+	for _, e := range p.Exprs {
+		if e.Name.Qualifier != "" {
+			return nil, fmt.Errorf("unknown variable: \"%s\"", e.Name)
+		}
+		sv, err := lookupSessionVar(strings.ToLower(e.Name.Name))
+		if err != nil {
+			return nil, err
+		}
+		var v driver.Value
+		if name, ok := e.Expr.(*parser.ColName); ok && name.Qualifier == "" {
+			v = name.Name
+		} else if v, err = evalConstExpr(e.Expr, args, s.timeLocation()); err != nil {
+			return nil, err
+		}
+		if err := sv.set(&updated, v); err != nil {
+			return nil, err
+		}
+	}
+	*s = updated
+	return &rows{}, nil
+}
+
+// Show executes a SHOW statement of a session variable, or of all of the
+// session variables for SHOW ALL.
+func (s *session) Show(p *parser.Show, args []driver.Value) (*rows, error) {
+	if p.Name == "all" {
+		r := &rows{columns: showAllColumns}
+		for _, sv := range sessionVars {
+			r.rows = append(r.rows, row{sv.name, sv.get(s)})
+		}
+		return r, nil
+	}
+	sv, err := lookupSessionVar(p.Name)
+	if err != nil {
+		return nil, err
+	}
+	return newSingleColumnRows(sv.name, []string{sv.get(s)}), nil
+}
+
+// showColumns returns the result columns of the SHOW statement.
+func showColumns(p *parser.Show) ([]string, error) {
+	if p.Name == "all" {
+		return showAllColumns, nil
+	}
+	sv, err := lookupSessionVar(p.Name)
+	if err != nil {
+		return nil, err
+	}
+	return []string{sv.name}, nil
+}
+
+// newTxn returns a new transaction using the isolation of the session.
+func (s *session) newTxn() *client.Txn {
+	txn := s.db.NewTxn(nil)
+	s.setIsolation(txn)
+	return txn
+}
+
+// setIsolation sets the isolation of a transaction started by the session.
+func (s *session) setIsolation(txn *client.Txn) {
+	if s.isolation == proto.SNAPSHOT {
+		txn.SetSnapshotIsolation()
+	}
+}
+
+// checkTimeout returns an error if the statement being executed has exceeded
+// the statement timeout of the session. The timeout is checked before the
+// rows of a query are read and before the writes of a statement are
+// performed, so that the writes of a canceled statement are never committed.
+func (s *session) checkTimeout() error {
+	if !s.deadline.IsZero() && time.Now().After(s.deadline) {
+		return errStatementTimeout
+	}
+	return nil
+}
+
+// timeoutScanner is a scanner which fails once the statement being executed
+// by the session has exceeded the statement timeout.
+type timeoutScanner struct {
+	scanner
+	s *session
+}
+
+func (t timeoutScanner) Scan(begin, end interface{}, maxRows int64) ([]client.KeyValue, error) {
+	if err := t.s.checkTimeout(); err != nil {
+		return nil, err
+	}
+	return t.scanner.Scan(begin, end, maxRows)
+}
+
+func (t timeoutScanner) Run(b *client.Batch) error {
+	if err := t.s.checkTimeout(); err != nil {
+		return err
+	}
+	return t.scanner.Run(b)
+}
@@ -0,0 +1,164 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package sqlserver
+
+import (
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/sql/parser"
+)
+
+// queryContext holds the state of a query being executed. The rows of a
+// query and of all of its subqueries are read using the same transaction.
+type queryContext struct {
+	s    *session
+	db   scanner
+	args []driver.Value
+	// The row of the enclosing query for a subquery, nil for a top-level
+	// query. The columns of the enclosing query can be referenced if they are
+	// not columns of the tables of the subquery.
+	outer env
+	// Set if the query referenced a column of the enclosing query.
+	correlated bool
+	// The results of the uncorrelated subqueries of the query, which are only
+	// executed once.
+	results map[*parser.Subquery]*rows
+}
+
+// query executes a SELECT statement or a UNION of SELECT statements.
+func (q *queryContext) query(p parser.SelectStatement) (*rows, error) {
+	switch t := p.(type) {
+	case *parser.Select:
+		return q.execSelect(t)
+	case *parser.Union:
+		return q.execUnion(t)
+	}
+	return nil, fmt.Errorf("unsupported SELECT: %T %s", p, p)
+}
+
+// env returns the env used to evaluate the expressions of the query for the
+// row.
+func (q *queryContext) env(e env) env {
+	return &queryEnv{env: e, q: q}
+}
+
+// subquery returns the rows of the subquery for the row of the query.
+func (q *queryContext) subquery(sub *parser.Subquery, row env) (*rows, error) {
+	if r, ok := q.results[sub]; ok {
+		return r, nil
+	}
+	if hasAsOf(sub.Select) {
+		return nil, errAsOfSubquery
+	}
+	sq := &queryContext{s: q.s, db: q.db, args: q.args, outer: row}
+	r, err := sq.query(sub.Select)
+	if err != nil {
+		return nil, err
+	}
+	if !sq.correlated {
+		// The rows of the subquery do not depend on the row of the query.
+		if q.results == nil {
+			q.results = map[*parser.Subquery]*rows{}
+		}
+		q.results[sub] = r
+	}
+	return r, nil
+}
+
+// subqueryValues returns the values of the single column of the subquery for
+// the row of the query.
+func (q *queryContext) subqueryValues(sub *parser.Subquery, row env) ([]driver.Value, error) {
+	r, err := q.subquery(sub, row)
+	if err != nil {
+		return nil, err
+	}
+	if len(r.columns) != 1 {
+		return nil, fmt.Errorf("subquery must return only one column: %s", sub)
+	}
+	vals := make([]driver.Value, len(r.rows))
+	for i, v := range r.rows {
+		vals[i] = v[0]
+	}
+	return vals, nil
+}
+
+// queryEnv implements the env interface for a row of a query, providing the
+// values of the columns of the enclosing query and of subqueries.
+type queryEnv struct {
+	env
+	q *queryContext
+}
+
+func (e *queryEnv) get(name *parser.ColName) (driver.Value, error) {
+	v, err := e.env.get(name)
+	if err != nil && e.q.outer != nil {
+		if v, outerErr := e.q.outer.get(name); outerErr == nil {
+			e.q.correlated = true
+			return v, nil
+		}
+	}
+	return v, err
+}
+
+// substitute provides the values of the substitutions of the row and of the
+// subqueries.
+func (e *queryEnv) substitute(expr parser.Expr) (driver.Value, bool, error) {
+	if s, ok := e.env.(substituter); ok {
+		if v, ok, err := s.substitute(expr); ok || err != nil {
+			return v, ok, err
+		}
+	}
+
+	switch t := expr.(type) {
+	case *parser.Subquery:
+		vals, err := e.q.subqueryValues(t, e)
+		if err != nil {
+			return nil, false, err
+		}
+		switch len(vals) {
+		case 0:
+			return nil, true, nil
+		case 1:
+			return vals[0], true, nil
+		}
+		return nil, false, fmt.Errorf("more than one row returned by a subquery used as an expression: %s", t)
+
+	case *parser.ExistsExpr:
+		r, err := e.q.subquery(t.Subquery, e)
+		if err != nil {
+			return nil, false, err
+		}
+		return len(r.rows) > 0, true, nil
+
+	case *parser.ComparisonExpr:
+		sub, ok := t.Right.(*parser.Subquery)
+		if !ok || (t.Operator != "IN" && t.Operator != "NOT IN") {
+			break
+		}
+		left, err := evalExpr(t.Left, e, e.q.args, e.q.s.timeLocation())
+		if err != nil {
+			return nil, false, err
+		}
+		vals, err := e.q.subqueryValues(sub, e)
+		if err != nil {
+			return nil, false, err
+		}
+		v, err := evalIn(t.Operator, left, vals, e.q.s.timeLocation())
+		return v, true, err
+	}
+	return nil, false, nil
+}
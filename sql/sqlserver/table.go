@@ -0,0 +1,551 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package sqlserver
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/cockroachdb/cockroach/client"
+	"github.com/cockroachdb/cockroach/keys"
+	"github.com/cockroachdb/cockroach/proto"
+	"github.com/cockroachdb/cockroach/structured"
+	"github.com/cockroachdb/cockroach/util/decimal"
+	"github.com/cockroachdb/cockroach/util/encoding"
+	"github.com/cockroachdb/cockroach/util/log"
+)
+
+// The table data for a row is stored as one key per non-NULL column:
+//
+//   /table-<tableID>/<indexID>/<pk-col1>/.../<pk-colN>/<columnID> -> value
+//
+// The primary key column values are encoded using the order-preserving
+// encodings in util/encoding so that a scan of the primary index returns rows
+// in primary key order. The primary key columns are themselves stored as
+// columns which guarantees that every row has at least one key.
+//
+// Each secondary index contains one key per row:
+//
+//   /table-<tableID>/<indexID>/<idx-col1>/.../<idx-colN>[/<pk-col1>/.../<pk-colN>] -> primary key
+//
+// The value is the primary key of the row (the prefix of its column keys). The
+// primary key columns which are not part of the index are appended to the key
+// of a non-unique index so that rows with identical indexed values do not
+// collide. Unique index keys contain only the indexed values and are written
+// with a conditional put. Rows containing a NULL value for any of the indexed
+// columns are not added to the index. Note that this implies that a unique
+// index allows multiple rows with NULL values.
+
+// encodeIndexKeyPrefix returns the key prefix for all of the keys in the
+// specified index of the table.
+func encodeIndexKeyPrefix(tableID, indexID uint32) []byte {
+	var key []byte
+	key = append(key, keys.TableDataPrefix...)
+	key = encoding.EncodeUvarint(key, uint64(tableID))
+	key = encoding.EncodeUvarint(key, uint64(indexID))
+	return key
+}
+
+// encodeIndexKey encodes the values for the specified index columns, appending
+// them to the index key prefix. colMap maps from column ID to the position of
+// the column's value within values.
+func encodeIndexKey(index structured.IndexDescriptor, colMap map[uint32]int,
+	values []driver.Value, indexKey []byte) ([]byte, error) {
+	var key []byte
+	key = append(key, indexKey...)
+
+	for _, id := range index.ColumnIDs {
+		i, ok := colMap[id]
+		if !ok {
+			return nil, fmt.Errorf("missing column %d of index \"%s\"", id, index.Name)
+		}
+		var err error
+		if key, err = encodeTableKey(key, values[i]); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// encodeSecondaryIndexKey returns the key for the row with the specified
+// values within the secondary index. False is returned if the row is not
+// present in the index because one of the indexed values is NULL.
+func encodeSecondaryIndexKey(desc *structured.TableDescriptor, index structured.IndexDescriptor,
+	colMap map[uint32]int, values []driver.Value) ([]byte, bool, error) {
+	for _, id := range index.ColumnIDs {
+		if i, ok := colMap[id]; ok && values[i] == nil {
+			return nil, false, nil
+		}
+	}
+
+	key, err := encodeIndexKey(index, colMap, values, encodeIndexKeyPrefix(desc.ID, index.ID))
+	if err != nil {
+		return nil, false, err
+	}
+	if !index.Unique {
+		for _, id := range desc.Indexes[0].ColumnIDs {
+			if index.ContainsColumnID(id) {
+				continue
+			}
+			if key, err = encodeTableKey(key, values[colMap[id]]); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+	return key, true, nil
+}
+
+// putIndexEntry adds the operation for writing a secondary index entry to the
+// batch.
+func putIndexEntry(b *client.Batch, index structured.IndexDescriptor, key, primaryKey []byte) {
+	if log.V(2) {
+		log.Infof("Put %q -> %q", key, primaryKey)
+	}
+	if index.Unique {
+		b.CPut(key, primaryKey, nil)
+	} else {
+		b.Put(key, primaryKey)
+	}
+}
+
+// encodeColumnKey returns the key for the specified column in the row
+// identified by primaryKey.
+func encodeColumnKey(col structured.ColumnDescriptor, primaryKey []byte) []byte {
+	var key []byte
+	key = append(key, primaryKey...)
+	return encoding.EncodeUvarint(key, uint64(col.ID))
+}
+
+// encodeTableKey encodes a single element of a table key, appending the
+// encoded value to b. The value is expected to have been checked against the
+// column type by checkColumnValue.
+func encodeTableKey(b []byte, v driver.Value) ([]byte, error) {
+	switch t := v.(type) {
+	case bool:
+		if t {
+			return encoding.EncodeVarint(b, 1), nil
+		}
+		return encoding.EncodeVarint(b, 0), nil
+	case int64:
+		return encoding.EncodeVarint(b, t), nil
+	case float64:
+		return encoding.EncodeNumericFloat(b, t), nil
+	case *decimal.Decimal:
+		return encoding.EncodeNumericDecimal(b, t), nil
+	case string:
+		return encoding.EncodeBytes(b, []byte(t)), nil
+	case []byte:
+		return encoding.EncodeBytes(b, t), nil
+	case time.Time:
+		return encoding.EncodeTime(b, t), nil
+	case nil:
+		return nil, fmt.Errorf("unable to encode NULL key value")
+	}
+	return nil, fmt.Errorf("unable to encode key: %T", v)
+}
+
+// decodeTableKey decodes a single element of a table key from b for a column
+// of the specified type, returning the remaining (not yet decoded) bytes.
+func decodeTableKey(b []byte, col structured.ColumnDescriptor) ([]byte, driver.Value, error) {
+	switch col.Type.Kind {
+	case structured.ColumnType_BIT, structured.ColumnType_INT:
+		var i int64
+		b, i = encoding.DecodeVarint(b)
+		return b, i, nil
+	case structured.ColumnType_DATE, structured.ColumnType_TIME,
+		structured.ColumnType_DATETIME, structured.ColumnType_TIMESTAMP:
+		var t time.Time
+		b, t = encoding.DecodeTime(b)
+		return b, t, nil
+	case structured.ColumnType_FLOAT:
+		var f float64
+		b, f = encoding.DecodeNumericFloat(b)
+		return b, f, nil
+	case structured.ColumnType_DECIMAL:
+		// The key encoding does not preserve the scale of the value, which is
+		// restored from the column type.
+		var d *decimal.Decimal
+		b, d = encoding.DecodeNumericDecimal(b)
+		if col.Type.Precision > 0 {
+			d = d.Round(col.Type.Precision)
+		}
+		return b, d, nil
+	case structured.ColumnType_CHAR, structured.ColumnType_TEXT,
+		structured.ColumnType_ENUM, structured.ColumnType_SET:
+		var r []byte
+		b, r = encoding.DecodeBytes(b, nil)
+		return b, string(r), nil
+	case structured.ColumnType_BINARY, structured.ColumnType_BLOB:
+		var r []byte
+		b, r = encoding.DecodeBytes(b, nil)
+		return b, r, nil
+	}
+	return nil, nil, fmt.Errorf("unable to decode key for column \"%s\": %s",
+		col.Name, col.Type.Kind)
+}
+
+// checkPrimaryKeyValues verifies that the values of the primary key columns of
+// a row are not NULL. The primary key columns are implicitly NOT NULL.
+func checkPrimaryKeyValues(desc *structured.TableDescriptor, vals []driver.Value) error {
+	for i, col := range desc.Columns {
+		if vals[i] == nil && desc.Indexes[0].ContainsColumnID(col.ID) {
+			return fmt.Errorf("null value in column \"%s\" violates not-null constraint", col.Name)
+		}
+	}
+	return nil
+}
+
+// checkColumnValue verifies that the value is compatible with the column's
+// type and nullability, returning the value converted to the canonical
+// representation for the column type. A nil value represents NULL. The
+// returned value is stored using marshalColumnValue and decoded using
+// unmarshalColumnValue.
+func checkColumnValue(col structured.ColumnDescriptor, v driver.Value) (driver.Value, error) {
+	if v == nil {
+		if !col.Nullable {
+			return nil, fmt.Errorf("null value in column \"%s\" violates not-null constraint", col.Name)
+		}
+		return nil, nil
+	}
+
+	switch col.Type.Kind {
+	case structured.ColumnType_BIT, structured.ColumnType_INT:
+		switch t := v.(type) {
+		case int64:
+			return t, nil
+		case bool:
+			if t {
+				return int64(1), nil
+			}
+			return int64(0), nil
+		}
+
+	case structured.ColumnType_FLOAT:
+		switch t := v.(type) {
+		case float64:
+			return t, nil
+		case int64:
+			return float64(t), nil
+		case *decimal.Decimal:
+			return t.Float64(), nil
+		}
+
+	case structured.ColumnType_DECIMAL:
+		switch v.(type) {
+		case *decimal.Decimal, int64, float64, string:
+			d, err := toDecimal(v)
+			if err != nil {
+				return nil, fmt.Errorf("value %v is not valid for column \"%s\" of type %s",
+					v, col.Name, col.Type.SQLString())
+			}
+			return checkDecimalValue(col, d)
+		}
+
+	case structured.ColumnType_DATE, structured.ColumnType_TIME,
+		structured.ColumnType_DATETIME, structured.ColumnType_TIMESTAMP:
+		t, ok := toTime(v)
+		if !ok {
+			if s, isString := v.(string); isString {
+				return nil, fmt.Errorf("value %q is not valid for column \"%s\" of type %s",
+					s, col.Name, col.Type.SQLString())
+			}
+			break
+		}
+		switch col.Type.Kind {
+		case structured.ColumnType_DATE:
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		case structured.ColumnType_TIME:
+			t = time.Date(0, 1, 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+		}
+		return t, nil
+
+	case structured.ColumnType_CHAR, structured.ColumnType_TEXT:
+		switch t := v.(type) {
+		case string:
+			return t, nil
+		case []byte:
+			return string(t), nil
+		}
+
+	case structured.ColumnType_ENUM, structured.ColumnType_SET:
+		var s string
+		switch t := v.(type) {
+		case string:
+			s = t
+		case []byte:
+			s = string(t)
+		}
+		for _, val := range col.Type.Vals {
+			if s == val {
+				return s, nil
+			}
+		}
+		return nil, fmt.Errorf("value %q is not valid for column \"%s\" of type %s",
+			s, col.Name, col.Type.SQLString())
+
+	case structured.ColumnType_BINARY, structured.ColumnType_BLOB:
+		switch t := v.(type) {
+		case []byte:
+			return t, nil
+		case string:
+			return []byte(t), nil
+		}
+	}
+
+	return nil, fmt.Errorf("value type %T doesn't match type %s of column \"%s\"",
+		v, col.Type.SQLString(), col.Name)
+}
+
+// checkDecimalValue rounds the value of a DECIMAL column to the scale of the
+// column type, if specified, returning an error if the value has more digits
+// before the decimal point than the precision of the column type allows.
+func checkDecimalValue(col structured.ColumnDescriptor, d *decimal.Decimal) (driver.Value, error) {
+	if col.Type.Precision > 0 {
+		d = d.Round(col.Type.Precision)
+	}
+	if col.Type.Width > 0 && int32(d.IntDigits()) > col.Type.Width-col.Type.Precision {
+		return nil, fmt.Errorf("value %s is out of range for column \"%s\" of type %s",
+			d, col.Name, col.Type.SQLString())
+	}
+	return d, nil
+}
+
+// marshalColumnValue returns the representation of a value checked by
+// checkColumnValue which is passed to client.Batch.Put. Times are encoded
+// using encoding.EncodeTime and decimals are stored in decimal notation,
+// which preserves their scale; all other values are marshalled by the client.
+func marshalColumnValue(v driver.Value) interface{} {
+	switch t := v.(type) {
+	case time.Time:
+		return encoding.EncodeTime(nil, t)
+	case *decimal.Decimal:
+		return t.String()
+	}
+	return v
+}
+
+// unmarshalColumnValue decodes the stored value for the column.
+func unmarshalColumnValue(col structured.ColumnDescriptor, b []byte) (driver.Value, error) {
+	switch col.Type.Kind {
+	case structured.ColumnType_BIT, structured.ColumnType_INT:
+		if len(b) != 8 {
+			return nil, fmt.Errorf("column \"%s\": invalid integer value length: %d", col.Name, len(b))
+		}
+		_, u := encoding.DecodeUint64(b)
+		return int64(u), nil
+	case structured.ColumnType_DATE, structured.ColumnType_TIME,
+		structured.ColumnType_DATETIME, structured.ColumnType_TIMESTAMP:
+		rest, t := encoding.DecodeTime(b)
+		if len(rest) != 0 {
+			return nil, fmt.Errorf("column \"%s\": invalid time value", col.Name)
+		}
+		return t, nil
+	case structured.ColumnType_FLOAT:
+		if len(b) != 8 {
+			return nil, fmt.Errorf("column \"%s\": invalid float value length: %d", col.Name, len(b))
+		}
+		_, u := encoding.DecodeUint64(b)
+		return math.Float64frombits(u), nil
+	case structured.ColumnType_DECIMAL:
+		d, err := decimal.Parse(string(b))
+		if err != nil {
+			return nil, fmt.Errorf("column \"%s\": %s", col.Name, err)
+		}
+		return d, nil
+	case structured.ColumnType_CHAR, structured.ColumnType_TEXT,
+		structured.ColumnType_ENUM, structured.ColumnType_SET:
+		return string(b), nil
+	case structured.ColumnType_BINARY, structured.ColumnType_BLOB:
+		return b, nil
+	}
+	return nil, fmt.Errorf("unable to unmarshal value for column \"%s\": %s",
+		col.Name, col.Type.Kind)
+}
+
+// decodeColumnID decodes the column ID suffix of a column key.
+func decodeColumnID(key []byte) ([]byte, uint32) {
+	key, id := encoding.DecodeUvarint(key)
+	return key, uint32(id)
+}
+
+// columnIndexMap returns a map from column ID to the position of the column
+// within the table.
+func columnIndexMap(desc *structured.TableDescriptor) map[uint32]int {
+	colMap := make(map[uint32]int, len(desc.Columns))
+	for i, col := range desc.Columns {
+		colMap[col.ID] = i
+	}
+	return colMap
+}
+
+// insertRow adds the operations for writing a new row and its secondary index
+// entries to the batch. The values are ordered by the table's columns and must
+// have been checked by checkColumnValue. The primary key columns are written
+// with a conditional put in order to detect an existing row with the same
+// primary key.
+func insertRow(b *client.Batch, desc *structured.TableDescriptor, vals []driver.Value) error {
+	colMap := columnIndexMap(desc)
+	primaryIndex := desc.Indexes[0]
+	primaryKey, err := encodeIndexKey(primaryIndex, colMap, vals,
+		encodeIndexKeyPrefix(desc.ID, primaryIndex.ID))
+	if err != nil {
+		return err
+	}
+
+	for _, index := range desc.Indexes[1:] {
+		key, ok, err := encodeSecondaryIndexKey(desc, index, colMap, vals)
+		if err != nil {
+			return err
+		}
+		if ok {
+			putIndexEntry(b, index, key, primaryKey)
+		}
+	}
+
+	for i, col := range desc.Columns {
+		if vals[i] == nil {
+			continue
+		}
+		key := encodeColumnKey(col, primaryKey)
+		if log.V(2) {
+			log.Infof("Put %q -> %v", key, vals[i])
+		}
+		if primaryIndex.ContainsColumnID(col.ID) {
+			b.CPut(key, marshalColumnValue(vals[i]), nil)
+		} else {
+			b.Put(key, marshalColumnValue(vals[i]))
+		}
+	}
+	return nil
+}
+
+// updateRow adds the operations for rewriting an existing row with the new
+// values to the batch. If the primary key of the row changes the row is
+// deleted and reinserted, otherwise only the modified columns and secondary
+// index entries are written.
+func updateRow(b *client.Batch, desc *structured.TableDescriptor, row tableRow,
+	newVals []driver.Value) error {
+	colMap := columnIndexMap(desc)
+	primaryIndex := desc.Indexes[0]
+	primaryKey, err := encodeIndexKey(primaryIndex, colMap, newVals,
+		encodeIndexKeyPrefix(desc.ID, primaryIndex.ID))
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(primaryKey, row.key) {
+		if err := deleteRow(b, desc, row); err != nil {
+			return err
+		}
+		return insertRow(b, desc, newVals)
+	}
+
+	for _, index := range desc.Indexes[1:] {
+		oldKey, oldOK, err := encodeSecondaryIndexKey(desc, index, colMap, row.vals)
+		if err != nil {
+			return err
+		}
+		newKey, newOK, err := encodeSecondaryIndexKey(desc, index, colMap, newVals)
+		if err != nil {
+			return err
+		}
+		if oldOK == newOK && bytes.Equal(oldKey, newKey) {
+			continue
+		}
+		if oldOK {
+			if log.V(2) {
+				log.Infof("Del %q", oldKey)
+			}
+			b.Del(oldKey)
+		}
+		if newOK {
+			putIndexEntry(b, index, newKey, primaryKey)
+		}
+	}
+
+	for i, col := range desc.Columns {
+		if newVals[i] == nil {
+			if row.vals[i] != nil {
+				key := encodeColumnKey(col, primaryKey)
+				if log.V(2) {
+					log.Infof("Del %q", key)
+				}
+				b.Del(key)
+			}
+			continue
+		}
+		if row.vals[i] != nil {
+			if c, err := compareValues(row.vals[i], newVals[i]); err == nil && c == 0 {
+				continue
+			}
+		}
+		key := encodeColumnKey(col, primaryKey)
+		if log.V(2) {
+			log.Infof("Put %q -> %v", key, newVals[i])
+		}
+		b.Put(key, marshalColumnValue(newVals[i]))
+	}
+	return nil
+}
+
+// deleteRow adds the operations for deleting all of the keys of the row,
+// including its secondary index entries, to the batch.
+func deleteRow(b *client.Batch, desc *structured.TableDescriptor, row tableRow) error {
+	colMap := columnIndexMap(desc)
+	for _, index := range desc.Indexes[1:] {
+		key, ok, err := encodeSecondaryIndexKey(desc, index, colMap, row.vals)
+		if err != nil {
+			return err
+		}
+		if ok {
+			if log.V(2) {
+				log.Infof("Del %q", key)
+			}
+			b.Del(key)
+		}
+	}
+
+	// All of the column keys for the row share the primary key as a prefix.
+	start := proto.Key(row.key)
+	if log.V(2) {
+		log.Infof("DelRange %q - %q", start, start.PrefixEnd())
+	}
+	b.DelRange(start, start.PrefixEnd())
+	return nil
+}
+
+// convertBatchError translates the error returned when writing rows into a
+// more useful error.
+func convertBatchError(desc *structured.TableDescriptor, err error) error {
+	if _, ok := err.(*proto.ConditionFailedError); ok {
+		// TODO: The batch doesn't tell us which of the conditional puts
+		// failed, so we can only name the constraint if there is a single unique
+		// index.
+		var unique []string
+		for _, index := range desc.Indexes {
+			if index.Unique {
+				unique = append(unique, index.Name)
+			}
+		}
+		if len(unique) == 1 {
+			return fmt.Errorf("duplicate key value violates unique constraint \"%s\"", unique[0])
+		}
+		return fmt.Errorf("duplicate key value violates unique constraint")
+	}
+	return err
+}
@@ -0,0 +1,747 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package sqlserver
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/sql/parser"
+	"github.com/cockroachdb/cockroach/structured"
+	"github.com/cockroachdb/cockroach/util/decimal"
+)
+
+// exprType is the type of the values of an expression, as determined by
+// typeCheckExpr before the expression is evaluated.
+type exprType int
+
+const (
+	// The type is not known until the expression is evaluated, such as the
+	// type of NULL, of a placeholder without an argument or of a subquery.
+	// In the signature of a builtin function any type is accepted.
+	typeAny exprType = iota
+	typeBool
+	typeInt
+	typeFloat
+	// An exact decimal, represented as a *decimal.Decimal.
+	typeDecimal
+	typeString
+	typeBytes
+	// A date or a time, represented as a time.Time.
+	typeTime
+	// An int, a float or a decimal. Only used in the signatures of builtin
+	// functions.
+	typeNumeric
+)
+
+func (t exprType) String() string {
+	switch t {
+	case typeBool:
+		return "bool"
+	case typeInt:
+		return "int"
+	case typeFloat:
+		return "float"
+	case typeDecimal:
+		return "decimal"
+	case typeString:
+		return "string"
+	case typeBytes:
+		return "bytes"
+	case typeTime:
+		return "time"
+	case typeNumeric:
+		return "numeric"
+	}
+	return "any"
+}
+
+// isNumeric returns true if the values of the type are numbers.
+func (t exprType) isNumeric() bool {
+	return t == typeInt || t == typeFloat || t == typeDecimal || t == typeNumeric
+}
+
+// valueType returns the type of a value. The type of NULL is typeAny.
+func valueType(v driver.Value) exprType {
+	switch v.(type) {
+	case bool:
+		return typeBool
+	case int64:
+		return typeInt
+	case float64:
+		return typeFloat
+	case *decimal.Decimal:
+		return typeDecimal
+	case string:
+		return typeString
+	case []byte:
+		return typeBytes
+	case time.Time:
+		return typeTime
+	}
+	return typeAny
+}
+
+// columnType returns the type of the values of the column.
+func columnType(col *structured.ColumnDescriptor) exprType {
+	switch col.Type.Kind {
+	case structured.ColumnType_BIT, structured.ColumnType_INT:
+		return typeInt
+	case structured.ColumnType_DATE, structured.ColumnType_TIME,
+		structured.ColumnType_DATETIME, structured.ColumnType_TIMESTAMP:
+		return typeTime
+	case structured.ColumnType_FLOAT:
+		return typeFloat
+	case structured.ColumnType_DECIMAL:
+		return typeDecimal
+	case structured.ColumnType_CHAR, structured.ColumnType_TEXT,
+		structured.ColumnType_ENUM, structured.ColumnType_SET:
+		return typeString
+	case structured.ColumnType_BINARY, structured.ColumnType_BLOB:
+		return typeBytes
+	}
+	return typeAny
+}
+
+// comparable returns true if values of the types can be compared using
+// compareValues.
+func comparable(a, b exprType) bool {
+	switch {
+	case a == typeAny || b == typeAny || a == b:
+		return true
+	case a.isNumeric() && b.isNumeric():
+		return true
+	case (a == typeString || a == typeBytes) && (b == typeString || b == typeBytes):
+		return true
+	case (a == typeTime && b == typeString) || (a == typeString && b == typeTime):
+		// The string is parsed when the values are compared.
+		return true
+	}
+	return false
+}
+
+// commonType returns the type of an expression whose value is the value of
+// one of two expressions of the types a and b, such as the result of a CASE
+// expression. Integers are promoted to floats and numbers to decimals.
+func commonType(a, b exprType) (exprType, bool) {
+	switch {
+	case a == typeAny:
+		return b, true
+	case b == typeAny || a == b:
+		return a, true
+	case a == typeDecimal && b.isNumeric(), a.isNumeric() && b == typeDecimal:
+		return typeDecimal, true
+	case a.isNumeric() && b.isNumeric():
+		return typeFloat, true
+	case (a == typeString || a == typeBytes) && (b == typeString || b == typeBytes):
+		return typeString, true
+	}
+	return typeAny, false
+}
+
+// isTruthType returns true if the values of the type can be used as a
+// condition (see evalTruth).
+func isTruthType(t exprType) bool {
+	return t == typeAny || t == typeBool || t == typeInt
+}
+
+// typeCheckExpr determines the type of the expression, returning an error if
+// the types of the operands of an operator or of the arguments of a function
+// are not supported. The columns are resolved using the tables; a column
+// which cannot be resolved, such as a column of the enclosing query of a
+// subquery, is of typeAny and is reported when the expression is evaluated.
+func typeCheckExpr(e parser.Expr, tables joinEnv, args []driver.Value) (exprType, error) {
+	switch t := e.(type) {
+	case parser.StrVal:
+		return typeString, nil
+	case parser.BytesVal:
+		return typeBytes, nil
+	case parser.NumVal:
+		v, err := evalNumVal(t)
+		return valueType(v), err
+	case parser.ValArg:
+		// The arguments are not known when a statement is prepared.
+		v, _ := evalArg(t, args)
+		return valueType(v), nil
+	case *parser.NullVal:
+		return typeAny, nil
+
+	case *parser.ColName:
+		i, err := tables.resolve(t)
+		if err != nil {
+			return typeAny, nil
+		}
+		col, err := tables[i].desc.FindColumnByName(strings.ToLower(t.Name))
+		if err != nil {
+			return typeAny, nil
+		}
+		return columnType(col), nil
+
+	case *parser.ParenBoolExpr:
+		return typeCheckExpr(t.Expr, tables, args)
+
+	case parser.ValTuple:
+		if len(t) == 1 {
+			return typeCheckExpr(t[0], tables, args)
+		}
+
+	case *parser.AndExpr:
+		if err := typeCheckCond(t.Left, tables, args); err != nil {
+			return typeAny, err
+		}
+		return typeBool, typeCheckCond(t.Right, tables, args)
+
+	case *parser.OrExpr:
+		if err := typeCheckCond(t.Left, tables, args); err != nil {
+			return typeAny, err
+		}
+		return typeBool, typeCheckCond(t.Right, tables, args)
+
+	case *parser.NotExpr:
+		return typeBool, typeCheckCond(t.Expr, tables, args)
+
+	case *parser.ComparisonExpr:
+		return typeBool, typeCheckComparisonExpr(t, tables, args)
+
+	case *parser.RangeCond:
+		left, err := typeCheckExpr(t.Left, tables, args)
+		if err != nil {
+			return typeAny, err
+		}
+		for _, bound := range []parser.ValExpr{t.From, t.To} {
+			b, err := typeCheckExpr(bound, tables, args)
+			if err != nil {
+				return typeAny, err
+			}
+			if !comparable(left, b) {
+				return typeAny, fmt.Errorf("cannot compare %s and %s: %s", left, b, e)
+			}
+		}
+		return typeBool, nil
+
+	case *parser.NullCheck:
+		_, err := typeCheckExpr(t.Expr, tables, args)
+		return typeBool, err
+
+	case *parser.ExistsExpr:
+		return typeBool, nil
+
+	case *parser.UnaryExpr:
+		v, err := typeCheckExpr(t.Expr, tables, args)
+		if err != nil || v == typeAny {
+			return typeAny, err
+		}
+		if (t.Operator == '~' && v != typeInt) || !v.isNumeric() {
+			return typeAny, fmt.Errorf("unsupported unary operator: %c%s", t.Operator, v)
+		}
+		return v, nil
+
+	case *parser.BinaryExpr:
+		left, err := typeCheckExpr(t.Left, tables, args)
+		if err != nil {
+			return typeAny, err
+		}
+		right, err := typeCheckExpr(t.Right, tables, args)
+		if err != nil {
+			return typeAny, err
+		}
+		return binaryOpType(t.Operator, left, right)
+
+	case *parser.FuncExpr:
+		if isAggregate(t) {
+			return typeCheckAggregate(t, tables, args)
+		}
+		return typeCheckFuncExpr(t, tables, args)
+
+	case *parser.CaseExpr:
+		return typeCheckCaseExpr(t, tables, args)
+	}
+	return typeAny, nil
+}
+
+// typeCheckCond checks that the expression can be used as a condition, such
+// as the WHERE clause of a statement.
+func typeCheckCond(e parser.Expr, tables joinEnv, args []driver.Value) error {
+	if e == nil {
+		return nil
+	}
+	t, err := typeCheckExpr(e, tables, args)
+	if err != nil {
+		return err
+	}
+	if !isTruthType(t) {
+		return fmt.Errorf("expected boolean expression, but found %s: %s", t, e)
+	}
+	return nil
+}
+
+func typeCheckComparisonExpr(e *parser.ComparisonExpr, tables joinEnv,
+	args []driver.Value) error {
+	left, err := typeCheckExpr(e.Left, tables, args)
+	if err != nil {
+		return err
+	}
+
+	switch e.Operator {
+	case "IN", "NOT IN":
+		tuple, ok := e.Right.(parser.ValTuple)
+		if !ok {
+			// The values of a subquery are only known when it is executed.
+			return nil
+		}
+		for _, expr := range tuple {
+			v, err := typeCheckExpr(expr, tables, args)
+			if err != nil {
+				return err
+			}
+			if !comparable(left, v) {
+				return fmt.Errorf("cannot compare %s and %s: %s", left, v, e)
+			}
+		}
+		return nil
+	}
+
+	right, err := typeCheckExpr(e.Right, tables, args)
+	if err != nil {
+		return err
+	}
+	switch e.Operator {
+	case "LIKE", "NOT LIKE":
+		if right != typeAny && right != typeString {
+			return fmt.Errorf("LIKE pattern must be a string: %s", e)
+		}
+		if left != typeAny && left != typeString {
+			return fmt.Errorf("LIKE requires a string operand: %s", e)
+		}
+		return nil
+	}
+	if !comparable(left, right) {
+		return fmt.Errorf("cannot compare %s and %s: %s", left, right, e)
+	}
+	return nil
+}
+
+// binaryOpType returns the type of the result of the binary operator, which
+// follows evalBinaryOp.
+func binaryOpType(op byte, left, right exprType) (exprType, error) {
+	switch op {
+	case '&', '|', '^':
+		if (left == typeAny || left == typeInt) && (right == typeAny || right == typeInt) {
+			return typeInt, nil
+		}
+	default:
+		if (left == typeAny || left.isNumeric()) && (right == typeAny || right.isNumeric()) {
+			switch {
+			case left == typeAny || right == typeAny:
+				return typeAny, nil
+			case left == typeInt && right == typeInt:
+				return typeInt, nil
+			case left == typeDecimal || right == typeDecimal:
+				return typeDecimal, nil
+			}
+			return typeFloat, nil
+		}
+	}
+	return typeAny, fmt.Errorf("unsupported binary operator: %s %c %s", left, op, right)
+}
+
+// typeCheckAggregate determines the type of an aggregate function call (see
+// aggregate).
+func typeCheckAggregate(f *parser.FuncExpr, tables joinEnv, args []driver.Value) (exprType, error) {
+	if len(f.Exprs) != 1 {
+		return typeAny, fmt.Errorf("%s requires a single argument: %s", f.Name, f)
+	}
+	arg := typeAny
+	if nse, ok := f.Exprs[0].(*parser.NonStarExpr); ok {
+		var err error
+		if arg, err = typeCheckExpr(nse.Expr, tables, args); err != nil {
+			return typeAny, err
+		}
+	}
+	switch f.Name {
+	case "COUNT":
+		return typeInt, nil
+	case "SUM", "AVG":
+		if arg != typeAny && !arg.isNumeric() {
+			return typeAny, fmt.Errorf("%s requires a numeric argument: %s", f.Name, f)
+		}
+		if f.Name == "AVG" && arg != typeDecimal {
+			return typeFloat, nil
+		}
+	}
+	return arg, nil
+}
+
+func typeCheckCaseExpr(e *parser.CaseExpr, tables joinEnv, args []driver.Value) (exprType, error) {
+	operand := typeAny
+	if e.Expr != nil {
+		var err error
+		if operand, err = typeCheckExpr(e.Expr, tables, args); err != nil {
+			return typeAny, err
+		}
+	}
+
+	result := typeAny
+	vals := make([]parser.ValExpr, 0, len(e.Whens)+1)
+	for _, w := range e.Whens {
+		if e.Expr == nil {
+			if err := typeCheckCond(w.Cond, tables, args); err != nil {
+				return typeAny, err
+			}
+		} else {
+			c, err := typeCheckExpr(w.Cond, tables, args)
+			if err != nil {
+				return typeAny, err
+			}
+			if !comparable(operand, c) {
+				return typeAny, fmt.Errorf("cannot compare %s and %s: %s", operand, c, e)
+			}
+		}
+		vals = append(vals, w.Val)
+	}
+	if e.Else != nil {
+		vals = append(vals, e.Else)
+	}
+	for _, v := range vals {
+		t, err := typeCheckExpr(v, tables, args)
+		if err != nil {
+			return typeAny, err
+		}
+		c, ok := commonType(result, t)
+		if !ok {
+			return typeAny, fmt.Errorf("CASE types %s and %s cannot be matched: %s", result, t, e)
+		}
+		result = c
+	}
+	return result, nil
+}
+
+// typeCheckSelect checks the types of the expressions of a SELECT statement
+// before any rows are retrieved.
+func typeCheckSelect(p *parser.Select, tables []*fromTable, outputs []selectOutput,
+	args []driver.Value) error {
+	env := tablesEnv(tables)
+	for _, o := range outputs {
+		if o.expr == nil {
+			continue
+		}
+		if _, err := typeCheckExpr(o.expr, env, args); err != nil {
+			return err
+		}
+	}
+	conds := []parser.Expr{whereExpr(p.Where), whereExpr(p.Having)}
+	for _, t := range tables {
+		conds = append(conds, t.on)
+	}
+	for _, c := range conds {
+		if err := typeCheckCond(c, env, args); err != nil {
+			return err
+		}
+	}
+	var exprs []parser.Expr
+	for _, e := range p.GroupBy {
+		exprs = append(exprs, e)
+	}
+	for _, o := range p.OrderBy {
+		exprs = append(exprs, o.Expr)
+	}
+	for _, e := range exprs {
+		if _, err := typeCheckExpr(e, env, args); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// typeCheckAssignment checks that the values of an expression of the type can
+// be stored in the column (see checkColumnValue).
+func typeCheckAssignment(col *structured.ColumnDescriptor, t exprType) error {
+	ok := false
+	switch columnType(col) {
+	case typeInt:
+		ok = t == typeInt || t == typeBool
+	case typeTime:
+		ok = t == typeTime || t == typeString
+	case typeFloat:
+		ok = t.isNumeric()
+	case typeDecimal:
+		ok = t.isNumeric() || t == typeString
+	case typeString, typeBytes:
+		ok = t == typeString || t == typeBytes
+	}
+	if !ok && t != typeAny {
+		return fmt.Errorf("value type %s doesn't match type %s of column \"%s\"",
+			t, col.Type.SQLString(), col.Name)
+	}
+	return nil
+}
+
+// argTypes holds the types of the arguments of a statement inferred from the
+// columns the placeholders are compared with or assigned to. The type of an
+// argument which cannot be inferred is nil.
+type argTypes []*structured.ColumnType
+
+// inferArgTypes infers the types of the numArgs arguments of the statement.
+// The types allow a client which sends its arguments as untyped strings to
+// have them converted to the types expected by the statement.
+func (s *session) inferArgTypes(stmt parser.Statement, numArgs int) (argTypes, error) {
+	a := make(argTypes, numArgs)
+	if err := s.visitColumnValues(stmt, a.set); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// set sets the type of the argument referenced by the expression, if it is a
+// placeholder, to the type of the column. The first type inferred for an
+// argument is used.
+func (a argTypes) set(e *parser.ValExpr, col *structured.ColumnDescriptor) {
+	v, ok := (*e).(parser.ValArg)
+	if !ok {
+		return
+	}
+	i, err := argIndex(v)
+	if err != nil || i >= len(a) || a[i] != nil {
+		return
+	}
+	typ := col.Type
+	a[i] = &typ
+}
+
+// localizeTimes interprets the strings without a time zone which are
+// compared with or assigned to a DATETIME or TIMESTAMP column in the time
+// zone of the session. Dates and times of day do not depend on the time zone,
+// so the strings compared with or assigned to a DATE or TIME column are
+// interpreted in UTC. Such a string literal is replaced by the equivalent
+// time in UTC in a copy of the statement, leaving the statement as is, and
+// such a string argument is replaced by a time in the returned arguments. The
+// statement is left as is if its tables cannot be found, which is reported
+// when it is executed.
+func (s *session) localizeTimes(stmt parser.Statement, args []driver.Value) (parser.Statement, []driver.Value) {
+	if s.location == nil {
+		return stmt, args
+	}
+	var literals bool
+	var localized []driver.Value
+	_ = s.visitColumnValues(stmt, func(e *parser.ValExpr, col *structured.ColumnDescriptor) {
+		loc := s.columnLocation(col)
+		if loc == nil {
+			return
+		}
+		switch t := (*e).(type) {
+		case parser.StrVal:
+			if _, ok := toTimeIn(string(t), loc); ok {
+				literals = true
+			}
+		case parser.ValArg:
+			i, err := argIndex(t)
+			if err != nil || i >= len(args) {
+				return
+			}
+			if _, ok := args[i].(string); !ok {
+				return
+			}
+			if r, ok := toTimeIn(args[i], loc); ok {
+				if localized == nil {
+					localized = append([]driver.Value(nil), args...)
+				}
+				localized[i] = r
+			}
+		}
+	})
+	if localized != nil {
+		args = localized
+	}
+	if !literals {
+		return stmt, args
+	}
+	// The statement is copied by parsing it again, as is done for the query
+	// of a view.
+	cp, err := parser.Parse(stmt.String())
+	if err != nil {
+		return stmt, args
+	}
+	_ = s.visitColumnValues(cp, func(e *parser.ValExpr, col *structured.ColumnDescriptor) {
+		loc := s.columnLocation(col)
+		if loc == nil {
+			return
+		}
+		if t, ok := (*e).(parser.StrVal); ok {
+			if r, ok := toTimeIn(string(t), loc); ok {
+				*e = parser.StrVal(r.Format(time.RFC3339Nano))
+			}
+		}
+	})
+	return cp, args
+}
+
+// columnLocation returns the time zone in which the strings compared with or
+// assigned to the column are interpreted, or nil if the column does not hold
+// times.
+func (s *session) columnLocation(col *structured.ColumnDescriptor) *time.Location {
+	switch col.Type.Kind {
+	case structured.ColumnType_DATETIME, structured.ColumnType_TIMESTAMP:
+		return s.timeLocation()
+	case structured.ColumnType_DATE, structured.ColumnType_TIME:
+		return time.UTC
+	}
+	return nil
+}
+
+// visitColumnValues calls fn with the expressions of the statement which are
+// compared with or assigned to a column, such as the values of an INSERT or
+// UPDATE and "k = $1", "k IN ($1, $2)" or "k BETWEEN $1 AND $2" in a WHERE
+// clause. The values of a LIMIT clause are visited as the values of an INT
+// column. fn may replace the expression.
+func (s *session) visitColumnValues(stmt parser.Statement,
+	fn func(e *parser.ValExpr, col *structured.ColumnDescriptor)) error {
+	switch p := stmt.(type) {
+	case *parser.Insert:
+		desc, err := s.lookupTableDesc(p.Table)
+		if err != nil {
+			return err
+		}
+		cols, err := processColumns(desc, p.Columns)
+		if err != nil {
+			return err
+		}
+		switch rows := p.Rows.(type) {
+		case parser.Values:
+			for _, tuple := range rows {
+				if vals, ok := tuple.(parser.ValTuple); ok {
+					for i := range vals {
+						if i < len(cols) {
+							fn(&vals[i], &cols[i])
+						}
+					}
+				}
+			}
+		case parser.SelectStatement:
+			return s.visitSelectColumnValues(rows, fn)
+		}
+	case *parser.Update:
+		desc, err := s.lookupTableDesc(p.Table)
+		if err != nil {
+			return err
+		}
+		for _, e := range p.Exprs {
+			if col, err := desc.FindColumnByName(strings.ToLower(e.Name.Name)); err == nil {
+				fn(&e.Expr, col)
+			}
+		}
+		visitExprColumnValues(whereExpr(p.Where), joinEnv{{desc: desc, alias: p.Table.Name}}, fn)
+		visitLimitValues(p.Limit, fn)
+	case *parser.Delete:
+		desc, err := s.lookupTableDesc(p.Table)
+		if err != nil {
+			return err
+		}
+		visitExprColumnValues(whereExpr(p.Where), joinEnv{{desc: desc, alias: p.Table.Name}}, fn)
+		visitLimitValues(p.Limit, fn)
+	case parser.SelectStatement:
+		return s.visitSelectColumnValues(p, fn)
+	}
+	return nil
+}
+
+func (s *session) visitSelectColumnValues(stmt parser.SelectStatement,
+	fn func(e *parser.ValExpr, col *structured.ColumnDescriptor)) error {
+	switch p := stmt.(type) {
+	case *parser.Select:
+		tables, err := s.resolveFrom(p.From)
+		if err != nil {
+			return err
+		}
+		env := tablesEnv(tables)
+		visitExprColumnValues(whereExpr(p.Where), env, fn)
+		visitExprColumnValues(whereExpr(p.Having), env, fn)
+		visitLimitValues(p.Limit, fn)
+	case *parser.Union:
+		if err := s.visitSelectColumnValues(p.Left, fn); err != nil {
+			return err
+		}
+		return s.visitSelectColumnValues(p.Right, fn)
+	}
+	return nil
+}
+
+// visitExprColumnValues calls fn with the expressions which are compared with
+// a column of the tables by the expression.
+func visitExprColumnValues(e parser.Expr, tables joinEnv,
+	fn func(e *parser.ValExpr, col *structured.ColumnDescriptor)) {
+	walkExpr(e, func(e parser.Expr) bool {
+		switch t := e.(type) {
+		case *parser.ComparisonExpr:
+			if t.Operator == "LIKE" || t.Operator == "NOT LIKE" {
+				break
+			}
+			if col := findColumnExpr(tables, t.Left); col != nil {
+				if tuple, ok := t.Right.(parser.ValTuple); ok {
+					for i := range tuple {
+						fn(&tuple[i], col)
+					}
+				} else {
+					fn(&t.Right, col)
+				}
+			}
+			if col := findColumnExpr(tables, t.Right); col != nil {
+				fn(&t.Left, col)
+			}
+		case *parser.RangeCond:
+			if col := findColumnExpr(tables, t.Left); col != nil {
+				fn(&t.From, col)
+				fn(&t.To, col)
+			}
+		}
+		return true
+	})
+}
+
+// visitLimitValues calls fn with the values of the LIMIT clause, which are
+// integers.
+func visitLimitValues(l *parser.Limit, fn func(e *parser.ValExpr, col *structured.ColumnDescriptor)) {
+	if l == nil {
+		return
+	}
+	col := &structured.ColumnDescriptor{}
+	col.Type.Kind = structured.ColumnType_INT
+	if l.Offset != nil {
+		fn(&l.Offset, col)
+	}
+	if l.Rowcount != nil {
+		fn(&l.Rowcount, col)
+	}
+}
+
+// findColumnExpr returns the column referenced by the expression, or nil if
+// the expression is not a reference to a column of the tables.
+func findColumnExpr(tables joinEnv, e parser.Expr) *structured.ColumnDescriptor {
+	n, ok := e.(*parser.ColName)
+	if !ok {
+		return nil
+	}
+	i, err := tables.resolve(n)
+	if err != nil {
+		return nil
+	}
+	col, err := tables[i].desc.FindColumnByName(strings.ToLower(n.Name))
+	if err != nil {
+		return nil
+	}
+	return col
+}
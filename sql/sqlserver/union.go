@@ -0,0 +1,117 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package sqlserver
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/sql/parser"
+)
+
+// execUnion executes a UNION, INTERSECT or EXCEPT of two SELECT statements.
+// The result columns are named after the columns of the left statement. The
+// rows of the result are distinct unless UNION ALL is used, and are in the
+// order they are produced by the left statement followed by the right
+// statement.
+func (q *queryContext) execUnion(p *parser.Union) (*rows, error) {
+	left, err := q.query(p.Left)
+	if err != nil {
+		return nil, err
+	}
+	right, err := q.query(p.Right)
+	if err != nil {
+		return nil, err
+	}
+	if len(left.columns) != len(right.columns) {
+		return nil, fmt.Errorf("each %s query must have the same number of columns", p.Type)
+	}
+
+	r := &rows{columns: left.columns}
+	if p.Type == "UNION ALL" {
+		r.rows = append(append(r.rows, left.rows...), right.rows...)
+		return r, nil
+	}
+
+	rightKeys := map[string]struct{}{}
+	if p.Type != "UNION" {
+		for _, vals := range right.rows {
+			key, err := encodeRowKey(vals)
+			if err != nil {
+				return nil, err
+			}
+			rightKeys[string(key)] = struct{}{}
+		}
+	}
+
+	seen := map[string]struct{}{}
+	add := func(vals row, include func(key string) bool) error {
+		key, err := encodeRowKey(vals)
+		if err != nil {
+			return err
+		}
+		if _, ok := seen[string(key)]; ok || !include(string(key)) {
+			return nil
+		}
+		seen[string(key)] = struct{}{}
+		r.rows = append(r.rows, vals)
+		return nil
+	}
+
+	var include func(key string) bool
+	switch p.Type {
+	case "UNION":
+		include = func(string) bool { return true }
+	case "INTERSECT":
+		include = func(key string) bool {
+			_, ok := rightKeys[key]
+			return ok
+		}
+	case "EXCEPT", "MINUS":
+		include = func(key string) bool {
+			_, ok := rightKeys[key]
+			return !ok
+		}
+	default:
+		return nil, fmt.Errorf("unknown set operation: %s", p.Type)
+	}
+	for _, vals := range left.rows {
+		if err := add(vals, include); err != nil {
+			return nil, err
+		}
+	}
+	if p.Type == "UNION" {
+		for _, vals := range right.rows {
+			if err := add(vals, include); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return r, nil
+}
+
+// encodeRowKey encodes the values of the row such that two rows have the same
+// encoding only if all of their values are equal. NULL values are considered
+// equal to each other.
+func encodeRowKey(vals row) ([]byte, error) {
+	var key []byte
+	for _, v := range vals {
+		var err error
+		if key, err = encodeGroupKey(key, v); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
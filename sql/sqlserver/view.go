@@ -0,0 +1,543 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package sqlserver
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/client"
+	"github.com/cockroachdb/cockroach/keys"
+	"github.com/cockroachdb/cockroach/proto"
+	"github.com/cockroachdb/cockroach/security"
+	"github.com/cockroachdb/cockroach/sql/parser"
+	"github.com/cockroachdb/cockroach/structured"
+)
+
+// A view is stored as a table descriptor whose view_query holds its SELECT
+// statement. The descriptor lists the columns of the view but no indexes as
+// the rows of a view are not stored. When a view is referenced by the FROM
+// clause of a query, its statement is executed in place of a scan of the
+// table (see viewSource).
+//
+// The descriptor of a view holds the IDs of the tables and views referenced by
+// its statement in depends_on, and the view's ID is added to depended_on_by of
+// each of those. A table or view cannot be dropped or renamed while views
+// depend on it.
+
+// CreateView executes a CREATE VIEW statement.
+func (s *session) CreateView(p *parser.CreateView, args []driver.Value) (*rows, error) {
+	if err := s.normalizeTableName(p.Name); err != nil {
+		return nil, err
+	}
+	dbDesc, err := s.getDatabaseDesc(p.Name.Qualifier)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkDatabasePrivilege(dbDesc, structured.AllPrivilege); err != nil {
+		return nil, err
+	}
+	desc, err := s.makeViewDesc(p.Name, p.Columns, p.Select)
+	if err != nil {
+		return nil, err
+	}
+	desc.Privileges = structured.NewPrivilegeDescriptor(s.user)
+
+	nameKey := keys.MakeNameMetadataKey(dbDesc.ID, p.Name.Name)
+	if gr, err := s.db.Get(nameKey); err != nil {
+		return nil, err
+	} else if gr.Exists() {
+		return nil, fmt.Errorf("\"%s\" already exists", p.Name.Name)
+	}
+
+	ir, err := s.db.Inc(keys.DescIDGenerator, 1)
+	if err != nil {
+		return nil, err
+	}
+	desc.ID = uint32(ir.ValueInt() - 1)
+
+	err = s.db.Txn(func(txn *client.Txn) error {
+		for _, id := range desc.DependsOn {
+			if err := updateDependents(txn, id, func(ids []uint32) []uint32 {
+				return append(ids, desc.ID)
+			}); err != nil {
+				return err
+			}
+		}
+		descKey := keys.MakeDescMetadataKey(desc.ID)
+		b := &client.Batch{}
+		b.CPut(nameKey, descKey, nil)
+		b.Put(descKey, &desc)
+		return txn.Commit(b)
+	})
+	if err != nil {
+		if _, ok := err.(*proto.ConditionFailedError); ok {
+			return nil, fmt.Errorf("\"%s\" already exists", p.Name.Name)
+		}
+		return nil, err
+	}
+	return &rows{}, nil
+}
+
+// AlterView executes an ALTER VIEW statement, replacing the statement and the
+// columns of the view. The views depending on the view are not checked
+// against its new columns.
+func (s *session) AlterView(p *parser.AlterView, args []driver.Value) (*rows, error) {
+	desc, err := s.lookupTableDesc(p.Name)
+	if err != nil {
+		return nil, err
+	}
+	if !desc.IsView() {
+		return nil, fmt.Errorf("\"%s\" is not a view", p.Name)
+	}
+	if err := s.checkTablePrivilege(p.Name, desc, structured.AllPrivilege); err != nil {
+		return nil, err
+	}
+	newDesc, err := s.makeViewDesc(p.Name, p.Columns, p.Select)
+	if err != nil {
+		return nil, err
+	}
+
+	err = updateTableDesc(s.db, desc, func(txn *client.Txn, b *client.Batch) error {
+		if err := checkViewCycle(txn, desc, newDesc.DependsOn); err != nil {
+			return err
+		}
+		if err := removeDependencies(txn, desc, nil); err != nil {
+			return err
+		}
+		for _, id := range newDesc.DependsOn {
+			if err := updateDependents(txn, id, func(ids []uint32) []uint32 {
+				return append(ids, desc.ID)
+			}); err != nil {
+				return err
+			}
+		}
+		desc.Columns = newDesc.Columns
+		desc.NextColumnID = newDesc.NextColumnID
+		desc.ViewQuery = newDesc.ViewQuery
+		desc.DependsOn = newDesc.DependsOn
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &rows{}, nil
+}
+
+// DropView executes a DROP VIEW statement.
+func (s *session) DropView(p *parser.DropView, args []driver.Value) (*rows, error) {
+	if _, err := s.dropTableDesc(p.Name, p.IfExists, true); err != nil {
+		return nil, err
+	}
+	return &rows{}, nil
+}
+
+// makeViewDesc returns the descriptor of a view defined by the SELECT
+// statement, without an ID. The statement is planned in order to check it and
+// to determine the names and types of the columns of the view, which are
+// renamed by columns if it is not empty. The table names of the statement are
+// qualified by their databases and "*" is expanded into the columns of the
+// tables so that the statement stored in the descriptor is not affected by
+// the current database or by columns added to the tables later on.
+func (s *session) makeViewDesc(name *parser.TableName, columns []string,
+	stmt parser.SelectStatement) (structured.TableDescriptor, error) {
+	desc := structured.TableDescriptor{}
+	refs := &viewRefs{}
+	refs.selectStatement(stmt)
+	if refs.err != nil {
+		return desc, refs.err
+	}
+	seen := map[uint32]struct{}{}
+	for _, n := range refs.names {
+		d, err := s.lookupTableDesc(n)
+		if err != nil {
+			return desc, err
+		}
+		if isInformationSchema(n.Qualifier) {
+			continue
+		}
+		if _, ok := seen[d.ID]; !ok {
+			seen[d.ID] = struct{}{}
+			desc.DependsOn = append(desc.DependsOn, d.ID)
+		}
+	}
+
+	cols, err := s.planViewSelect(stmt)
+	if err != nil {
+		return desc, err
+	}
+	if len(columns) > 0 {
+		if len(columns) != len(cols) {
+			return desc, fmt.Errorf("view \"%s\" has %d column names but its query returns %d columns",
+				name, len(columns), len(cols))
+		}
+		for i := range cols {
+			cols[i].Name = columns[i]
+		}
+	}
+
+	desc.Name = name.String()
+	desc.Columns = cols
+	desc.NextColumnID = uint32(len(cols) + 1)
+	desc.ViewQuery = stmt.String()
+	if err := structured.ValidateTableDesc(desc); err != nil {
+		return desc, err
+	}
+	return desc, nil
+}
+
+// planViewSelect plans every SELECT statement of a view, expanding "*" into
+// the columns of the tables, and returns the columns of the view, which are
+// the output columns of the first SELECT statement.
+func (s *session) planViewSelect(stmt parser.SelectStatement) ([]structured.ColumnDescriptor, error) {
+	switch t := stmt.(type) {
+	case *parser.Union:
+		cols, err := s.planViewSelect(t.Left)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.planViewSelect(t.Right); err != nil {
+			return nil, err
+		}
+		return cols, nil
+
+	case *parser.Select:
+		if t.AsOf != nil {
+			return nil, errAsOfView
+		}
+		plan, err := s.planSelect(t, nil)
+		if err != nil {
+			return nil, err
+		}
+		tables := plan.join.tables
+		expandStars(t, tables)
+		env := tablesEnv(tables)
+		cols := make([]structured.ColumnDescriptor, len(plan.outputs))
+		for i, o := range plan.outputs {
+			cols[i] = structured.ColumnDescriptor{
+				ID:     uint32(i + 1),
+				Column: structured.Column{Name: o.name, Nullable: true},
+			}
+			if o.expr == nil {
+				cols[i].Type = tables[0].desc.Columns[o.col].Type
+				continue
+			}
+			if name, ok := o.expr.(*parser.ColName); ok {
+				j, err := env.resolve(name)
+				if err != nil {
+					return nil, err
+				}
+				col, err := tables[j].desc.FindColumnByName(strings.ToLower(name.Name))
+				if err != nil {
+					return nil, err
+				}
+				cols[i].Type = col.Type
+				continue
+			}
+			typ, err := typeCheckExpr(o.expr, env, nil)
+			if err != nil {
+				return nil, err
+			}
+			var ok bool
+			if cols[i].Type, ok = viewColumnType(typ); !ok {
+				return nil, fmt.Errorf("cannot determine the type of view column \"%s\"", o.name)
+			}
+		}
+		return cols, nil
+	}
+	return nil, fmt.Errorf("unsupported SELECT: %T %s", stmt, stmt)
+}
+
+// expandStars replaces every "*" of the output columns of the SELECT statement
+// by references to the columns of the tables.
+func expandStars(p *parser.Select, tables []*fromTable) {
+	var exprs parser.SelectExprs
+	for _, expr := range p.Exprs {
+		star, ok := expr.(*parser.StarExpr)
+		if !ok {
+			exprs = append(exprs, expr)
+			continue
+		}
+		for _, table := range tables {
+			if star.TableName != "" && !strings.EqualFold(star.TableName, table.alias) {
+				continue
+			}
+			for _, col := range table.desc.Columns {
+				if !col.IsPublic() {
+					continue
+				}
+				name := &parser.ColName{Name: col.Name}
+				if len(tables) > 1 {
+					name.Qualifier = table.alias
+				}
+				exprs = append(exprs, &parser.NonStarExpr{Expr: name})
+			}
+		}
+	}
+	p.Exprs = exprs
+}
+
+// viewColumnType returns the type of a view column whose values are computed
+// by an expression of the type. False is returned if the type is not known.
+func viewColumnType(t exprType) (structured.ColumnType, bool) {
+	switch t {
+	case typeBool:
+		return structured.ColumnType{Kind: structured.ColumnType_BIT, Width: 1}, true
+	case typeInt:
+		return structured.ColumnType{Kind: structured.ColumnType_INT}, true
+	case typeFloat, typeNumeric:
+		return structured.ColumnType{Kind: structured.ColumnType_FLOAT}, true
+	case typeDecimal:
+		return structured.ColumnType{Kind: structured.ColumnType_DECIMAL}, true
+	case typeString:
+		return structured.ColumnType{Kind: structured.ColumnType_TEXT}, true
+	case typeBytes:
+		return structured.ColumnType{Kind: structured.ColumnType_BLOB}, true
+	case typeTime:
+		return structured.ColumnType{Kind: structured.ColumnType_TIMESTAMP}, true
+	}
+	return structured.ColumnType{}, false
+}
+
+// viewRefs collects the names of the tables referenced by the FROM clauses of
+// a SELECT statement and of its subqueries. Placeholders are rejected as the
+// statement of a view is executed without arguments.
+type viewRefs struct {
+	names []*parser.TableName
+	err   error
+}
+
+func (r *viewRefs) selectStatement(stmt parser.SelectStatement) {
+	switch t := stmt.(type) {
+	case *parser.Union:
+		r.selectStatement(t.Left)
+		r.selectStatement(t.Right)
+	case *parser.Select:
+		for _, e := range t.From {
+			r.tableExpr(e)
+		}
+		for _, e := range t.Exprs {
+			if nse, ok := e.(*parser.NonStarExpr); ok {
+				r.expr(nse.Expr)
+			}
+		}
+		r.expr(whereExpr(t.Where))
+		r.expr(whereExpr(t.Having))
+		for _, e := range t.GroupBy {
+			r.expr(e)
+		}
+		for _, o := range t.OrderBy {
+			r.expr(o.Expr)
+		}
+		if t.Limit != nil {
+			r.expr(t.Limit.Offset)
+			r.expr(t.Limit.Rowcount)
+		}
+	}
+}
+
+func (r *viewRefs) tableExpr(e parser.TableExpr) {
+	switch t := e.(type) {
+	case *parser.AliasedTableExpr:
+		switch n := t.Expr.(type) {
+		case *parser.TableName:
+			r.names = append(r.names, n)
+		case *parser.Subquery:
+			r.selectStatement(n.Select)
+		}
+	case *parser.ParenTableExpr:
+		r.tableExpr(t.Expr)
+	case *parser.JoinTableExpr:
+		r.tableExpr(t.LeftExpr)
+		r.tableExpr(t.RightExpr)
+		if on, ok := t.Cond.(*parser.OnJoinCond); ok {
+			r.expr(on.Expr)
+		}
+	}
+}
+
+func (r *viewRefs) expr(e parser.Expr) {
+	walkExpr(e, func(e parser.Expr) bool {
+		switch t := e.(type) {
+		case *parser.Subquery:
+			r.selectStatement(t.Select)
+			return false
+		case *parser.ExistsExpr:
+			r.selectStatement(t.Subquery.Select)
+			return false
+		case parser.ValArg:
+			if r.err == nil {
+				r.err = fmt.Errorf("a view cannot contain placeholders: %s", t)
+			}
+		}
+		return true
+	})
+}
+
+// createViewString returns the CREATE VIEW statement creating the view.
+func createViewString(name *parser.TableName, desc *structured.TableDescriptor) string {
+	cols := make([]string, len(desc.Columns))
+	for i, col := range desc.Columns {
+		cols[i] = col.Name
+	}
+	return fmt.Sprintf("CREATE VIEW %s (%s) AS %s", name, strings.Join(cols, ", "), desc.ViewQuery)
+}
+
+// viewSource produces the rows of a view referenced by the FROM clause of a
+// query.
+type viewSource struct {
+	s    *session
+	stmt parser.SelectStatement
+}
+
+// expandView returns the descriptor used to plan the scan of the view and the
+// source of its rows. The descriptor is a copy of the view's descriptor with a
+// primary index without columns, so that the rows of the view are scanned
+// like those of a table (see scanPlan.scan).
+func (s *session) expandView(desc *structured.TableDescriptor) (*structured.TableDescriptor, *viewSource, error) {
+	stmt, err := parser.Parse(desc.ViewQuery)
+	if err != nil {
+		return nil, nil, err
+	}
+	sel, ok := stmt.(parser.SelectStatement)
+	if !ok {
+		return nil, nil, fmt.Errorf("view \"%s\" has an invalid query: %s", desc.Name, desc.ViewQuery)
+	}
+	planDesc := *desc
+	planDesc.Indexes = []structured.IndexDescriptor{
+		{ID: 1, Index: structured.Index{Name: "primary", Unique: true}},
+	}
+	planDesc.NextIndexID = 2
+	// The statement is executed without checking the privileges of the user
+	// on the tables it references.
+	viewSession := *s
+	viewSession.user = security.RootUser
+	return &planDesc, &viewSource{s: &viewSession, stmt: sel}, nil
+}
+
+// scan executes the statement of the view, returning its rows in the order
+// they are produced. The values are converted to the types of the columns of
+// the view and every row is given a key of its own within the span of the
+// primary index.
+func (v *viewSource) scan(db scanner, p *scanPlan) ([]tableRow, error) {
+	r, err := (&queryContext{s: v.s, db: db}).query(v.stmt)
+	if err != nil {
+		return nil, err
+	}
+	if len(r.columns) != len(p.desc.Columns) {
+		return nil, fmt.Errorf("view \"%s\" returns %d columns, expected %d",
+			p.desc.Name, len(r.columns), len(p.desc.Columns))
+	}
+	prefix := encodeIndexKeyPrefix(p.desc.ID, p.index.ID)
+	tableRows := make([]tableRow, len(r.rows))
+	for i, vals := range r.rows {
+		for j, col := range p.desc.Columns {
+			if vals[j], err = checkColumnValue(col, vals[j]); err != nil {
+				return nil, err
+			}
+		}
+		key, err := encodeTableKey(prefix, int64(i))
+		if err != nil {
+			return nil, err
+		}
+		tableRows[i] = tableRow{key: key, vals: vals}
+	}
+	return tableRows, nil
+}
+
+// checkNoDependents returns an error if views other than those in ignore
+// depend on the table or view, which prevents the operation.
+func checkNoDependents(txn *client.Txn, desc *structured.TableDescriptor, op string,
+	ignore map[uint32]struct{}) error {
+	for _, id := range desc.DependedOnBy {
+		if _, ok := ignore[id]; ok {
+			continue
+		}
+		view := structured.TableDescriptor{}
+		if err := txn.GetProto(keys.MakeDescMetadataKey(id), &view); err != nil {
+			return err
+		}
+		return fmt.Errorf("cannot %s \"%s\" because view \"%s\" depends on it",
+			op, desc.Name, view.Name)
+	}
+	return nil
+}
+
+// removeDependencies removes the view from the dependents of the tables and
+// views it depends on, other than those in ignore.
+func removeDependencies(txn *client.Txn, desc *structured.TableDescriptor,
+	ignore map[uint32]struct{}) error {
+	for _, id := range desc.DependsOn {
+		if _, ok := ignore[id]; ok {
+			continue
+		}
+		if err := updateDependents(txn, id, func(ids []uint32) []uint32 {
+			remaining := ids[:0]
+			for _, dep := range ids {
+				if dep != desc.ID {
+					remaining = append(remaining, dep)
+				}
+			}
+			return remaining
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateDependents modifies the IDs of the views depending on the table or
+// view with the ID. The descriptor is written immediately so that it is read
+// back by later modifications within the same transaction.
+func updateDependents(txn *client.Txn, id uint32, fn func(ids []uint32) []uint32) error {
+	descKey := keys.MakeDescMetadataKey(id)
+	desc := structured.TableDescriptor{}
+	if err := txn.GetProto(descKey, &desc); err != nil {
+		return err
+	}
+	if desc.ID == 0 {
+		return fmt.Errorf("table with ID %d does not exist", id)
+	}
+	desc.DependedOnBy = fn(desc.DependedOnBy)
+	desc.Version++
+	return txn.Put(descKey, &desc)
+}
+
+// checkViewCycle returns an error if the view is reachable from the tables and
+// views with the IDs by following their dependencies, which would make the
+// view depend on itself.
+func checkViewCycle(txn *client.Txn, desc *structured.TableDescriptor, deps []uint32) error {
+	pending := append([]uint32(nil), deps...)
+	seen := map[uint32]struct{}{}
+	for len(pending) > 0 {
+		id := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if id == desc.ID {
+			return fmt.Errorf("view \"%s\" cannot depend on itself", desc.Name)
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		d := structured.TableDescriptor{}
+		if err := txn.GetProto(keys.MakeDescMetadataKey(id), &d); err != nil {
+			return err
+		}
+		pending = append(pending, d.DependsOn...)
+	}
+	return nil
+}
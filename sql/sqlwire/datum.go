@@ -0,0 +1,169 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package sqlwire
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/cockroachdb/cockroach/util/decimal"
+	"github.com/cockroachdb/cockroach/util/encoding"
+)
+
+// MakeDatum converts a database/sql/driver.Value or a *decimal.Decimal into
+// a Datum. Times are transmitted as timestamps and a nil value is converted
+// into an explicit NULL.
+func MakeDatum(v driver.Value) (*Datum, error) {
+	d := &Datum{}
+	switch t := v.(type) {
+	case nil:
+		null := true
+		d.Nullval = &null
+	case bool:
+		d.Bval = &t
+	case int64:
+		d.Ival = &t
+	case float64:
+		d.Dval = &t
+	case []byte:
+		d.Blobval = t
+	case string:
+		d.Strval = &t
+	case time.Time:
+		d.Timeval = &Datum_Timestamp{
+			Sec:  t.Unix(),
+			Nsec: uint32(t.Nanosecond()),
+		}
+	case *decimal.Decimal:
+		s := t.String()
+		d.Decimalval = &s
+	default:
+		return nil, fmt.Errorf("unsupported value type %T", v)
+	}
+	return d, nil
+}
+
+// MakeDateDatum returns a Datum holding the date of t. The time of day and
+// location of t are ignored.
+func MakeDateDatum(t time.Time) *Datum {
+	days := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix() / secondsPerDay
+	return &Datum{Dateval: &days}
+}
+
+const secondsPerDay = 24 * 60 * 60
+
+// Value implements the database/sql/driver.Valuer interface. Dates and
+// timestamps are returned as a time.Time in UTC and decimals as a string in
+// decimal notation. Nil is returned for NULL and for a Datum without a value.
+func (d Datum) Value() (driver.Value, error) {
+	switch {
+	case d.Bval != nil:
+		return *d.Bval, nil
+	case d.Ival != nil:
+		return *d.Ival, nil
+	case d.Dval != nil:
+		return *d.Dval, nil
+	case d.Blobval != nil:
+		return d.Blobval, nil
+	case d.Strval != nil:
+		return *d.Strval, nil
+	case d.Dateval != nil:
+		return time.Unix(*d.Dateval*secondsPerDay, 0).UTC(), nil
+	case d.Timeval != nil:
+		return time.Unix(d.Timeval.Sec, int64(d.Timeval.Nsec)).UTC(), nil
+	case d.Decimalval != nil:
+		return *d.Decimalval, nil
+	}
+	return nil, nil
+}
+
+// DecimalValue returns the value of a decimal Datum. An error is returned if
+// the Datum does not hold a valid decimal.
+func (d Datum) DecimalValue() (*decimal.Decimal, error) {
+	if d.Decimalval == nil {
+		return nil, fmt.Errorf("datum is not a decimal: %v", &d)
+	}
+	return decimal.Parse(*d.Decimalval)
+}
+
+// IsNull returns true if the Datum represents NULL.
+func (d Datum) IsNull() bool {
+	v, _ := d.Value()
+	return v == nil
+}
+
+// Error implements the error interface.
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("invalid checksum (%d, expected %d) for datum %s", e.Actual, e.Expected, e.Datum)
+}
+
+// SetChecksum sets the checksum of the Datum to the checksum of its value,
+// replacing any existing checksum.
+func (d *Datum) SetChecksum() {
+	cksum := d.computeChecksum()
+	d.Checksum = &cksum
+}
+
+// VerifyChecksum returns a *ChecksumError if the Datum's checksum does not
+// match a newly-computed checksum of its value. If the Datum's checksum is
+// not set the verification is a noop.
+func (d *Datum) VerifyChecksum() error {
+	if d.Checksum == nil {
+		return nil
+	}
+	if cksum := d.computeChecksum(); cksum != *d.Checksum {
+		return &ChecksumError{Expected: *d.Checksum, Actual: cksum, Datum: d}
+	}
+	return nil
+}
+
+// computeChecksum computes the CRC-32 checksum of the value of the Datum as
+// described in the documentation of the checksum field.
+func (d *Datum) computeChecksum() uint32 {
+	// Each value is prefixed by the field number of its type, so that values
+	// of different types with the same encoding, such as the integer 0 and
+	// the date 1970-01-01, have different checksums.
+	var b []byte
+	switch {
+	case d.Bval != nil:
+		if *d.Bval {
+			b = []byte{1, 1}
+		} else {
+			b = []byte{1, 0}
+		}
+	case d.Ival != nil:
+		b = encoding.EncodeUint64([]byte{2}, uint64(*d.Ival))
+	case d.Dval != nil:
+		b = encoding.EncodeUint64([]byte{3}, math.Float64bits(*d.Dval))
+	case d.Blobval != nil:
+		b = append([]byte{4}, d.Blobval...)
+	case d.Strval != nil:
+		b = append([]byte{5}, *d.Strval...)
+	case d.Dateval != nil:
+		b = encoding.EncodeUint64([]byte{6}, uint64(*d.Dateval))
+	case d.Timeval != nil:
+		b = encoding.EncodeUint64([]byte{7}, uint64(d.Timeval.Sec))
+		b = encoding.EncodeUint32(b, d.Timeval.Nsec)
+	case d.Decimalval != nil:
+		b = append([]byte{8}, *d.Decimalval...)
+	}
+	c := encoding.NewCRC32Checksum(b)
+	sum := c.Sum32()
+	encoding.ReleaseCRC32Checksum(c)
+	return sum
+}
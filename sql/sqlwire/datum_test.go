@@ -0,0 +1,205 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package sqlwire
+
+import (
+	"database/sql/driver"
+	"hash/crc32"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/util/decimal"
+	"github.com/gogo/protobuf/proto"
+)
+
+// roundTrip marshals and unmarshals the datum, returning its value.
+func roundTrip(t *testing.T, d *Datum) driver.Value {
+	b, err := proto.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var d2 Datum
+	if err := proto.Unmarshal(b, &d2); err != nil {
+		t.Fatal(err)
+	}
+	v, err := d2.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestDatumRoundTrip(t *testing.T) {
+	testData := []struct {
+		value    driver.Value
+		expected driver.Value
+	}{
+		{nil, nil},
+		{true, true},
+		{int64(-3), int64(-3)},
+		{1.5, 1.5},
+		{[]byte("abc"), []byte("abc")},
+		{"héllo", "héllo"},
+		{time.Unix(-1, 999999999), time.Unix(-1, 999999999).UTC()},
+		{time.Date(2015, 6, 1, 12, 30, 0, 0, time.FixedZone("", 3600)),
+			time.Date(2015, 6, 1, 11, 30, 0, 0, time.UTC)},
+	}
+	for _, d := range testData {
+		datum, err := MakeDatum(d.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := roundTrip(t, datum); !reflect.DeepEqual(d.expected, v) {
+			t.Errorf("%v: expected %v (%T), but found %v (%T)", d.value, d.expected, d.expected, v, v)
+		}
+	}
+
+	if _, err := MakeDatum(int32(1)); err == nil {
+		t.Errorf("expected error for unsupported value type")
+	}
+}
+
+func TestDateDatum(t *testing.T) {
+	testData := []struct {
+		t        time.Time
+		days     int64
+		expected time.Time
+	}{
+		{time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC), 0,
+			time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2015, 6, 1, 23, 59, 0, 0, time.UTC), 16587,
+			time.Date(2015, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{time.Date(1969, 12, 31, 12, 0, 0, 0, time.UTC), -1,
+			time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, d := range testData {
+		datum := MakeDateDatum(d.t)
+		if days := datum.GetDateval(); days != d.days {
+			t.Errorf("%s: expected %d days, but found %d", d.t, d.days, days)
+		}
+		if v := roundTrip(t, datum); !reflect.DeepEqual(d.expected, v) {
+			t.Errorf("%s: expected %s, but found %v", d.t, d.expected, v)
+		}
+	}
+}
+
+func TestDecimalDatum(t *testing.T) {
+	for _, s := range []string{"0", "-12.345", "1.50", "1000000000000000000000.5"} {
+		dec, err := decimal.Parse(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		datum, err := MakeDatum(dec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := roundTrip(t, datum); v != s {
+			t.Errorf("expected %s, but found %v", s, v)
+		}
+		if d, err := datum.DecimalValue(); err != nil {
+			t.Error(err)
+		} else if d.String() != s {
+			t.Errorf("expected %s, but found %s", s, d)
+		}
+	}
+	for _, s := range []string{"", "abc", "1/3"} {
+		d := Datum{Decimalval: &s}
+		if _, err := d.DecimalValue(); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
+
+func TestDatumIsNull(t *testing.T) {
+	null, err := MakeDatum(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !null.GetNullval() {
+		t.Errorf("expected an explicit NULL")
+	}
+	empty := ""
+	for _, d := range []struct {
+		datum    Datum
+		expected bool
+	}{
+		{*null, true},
+		{Datum{}, true},
+		{Datum{Strval: &empty}, false},
+		{Datum{Blobval: []byte{}}, false},
+	} {
+		if isNull := d.datum.IsNull(); isNull != d.expected {
+			t.Errorf("%s: expected %t, but found %t", &d.datum, d.expected, isNull)
+		}
+	}
+}
+
+func TestDatumChecksum(t *testing.T) {
+	// Values of different types with the same encoding have different
+	// checksums.
+	values := []driver.Value{nil, true, false, int64(-3), 1.5, []byte("abc"), "héllo",
+		time.Unix(1433160000, 5), int64(0), 0.0, time.Unix(0, 0), "", []byte{}, "0",
+		decimal.NewFromInt(0)}
+	sums := map[uint32]driver.Value{}
+	for _, v := range values {
+		d, err := MakeDatum(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := d.VerifyChecksum(); err != nil {
+			t.Errorf("%v: expected no checksum verification, but found %v", v, err)
+		}
+		d.SetChecksum()
+		if prev, ok := sums[d.GetChecksum()]; ok {
+			t.Errorf("%v: checksum %d collides with %v", v, d.GetChecksum(), prev)
+		}
+		sums[d.GetChecksum()] = v
+
+		// The checksum survives a round trip.
+		b, err := proto.Marshal(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var d2 Datum
+		if err := proto.Unmarshal(b, &d2); err != nil {
+			t.Fatal(err)
+		}
+		if err := d2.VerifyChecksum(); err != nil {
+			t.Errorf("%v: %v", v, err)
+		}
+	}
+
+	// The integer checksum is the checksum of the field number followed by
+	// the 8 byte, big-endian value.
+	d, err := MakeDatum(int64(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.SetChecksum()
+	if expected := crc32.ChecksumIEEE([]byte{2, 0, 0, 0, 0, 0, 0, 0, 1}); d.GetChecksum() != expected {
+		t.Errorf("expected checksum %d, but found %d", expected, d.GetChecksum())
+	}
+
+	// Corrupting the value is detected.
+	*d.Ival = 2
+	err = d.VerifyChecksum()
+	if cErr, ok := err.(*ChecksumError); !ok {
+		t.Errorf("expected a checksum error, but found %v", err)
+	} else if cErr.Expected != d.GetChecksum() {
+		t.Errorf("expected checksum %d, but found %d", d.GetChecksum(), cErr.Expected)
+	}
+}
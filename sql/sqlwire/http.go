@@ -18,6 +18,7 @@
 package sqlwire
 
 import (
+	"github.com/cockroachdb/cockroach/proto"
 	gogoproto "github.com/gogo/protobuf/proto"
 )
 
@@ -63,3 +64,24 @@ func (*SQLRequest) CreateReply() Response {
 func (r *SQLResponseHeader) Header() *SQLResponseHeader {
 	return r
 }
+
+// GoError returns the non-nil error from the proto.Error union, or the
+// *ChecksumError if the error is a checksum mismatch.
+func (r *SQLResponseHeader) GoError() error {
+	if r.Error != nil && r.ChecksumError != nil {
+		return r.ChecksumError
+	}
+	h := proto.ResponseHeader{Error: r.Error}
+	return h.GoError()
+}
+
+// SetGoError converts the specified type into either one of the proto-
+// defined error types or into a Error for all other Go errors. A
+// *ChecksumError is additionally carried in its own field so that the
+// client can reconstruct it.
+func (r *SQLResponseHeader) SetGoError(err error) {
+	h := proto.ResponseHeader{}
+	h.SetGoError(err)
+	r.Error = h.Error
+	r.ChecksumError, _ = err.(*ChecksumError)
+}
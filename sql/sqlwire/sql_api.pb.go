@@ -12,15 +12,18 @@
 		SQLRequestHeader
 		SQLResponseHeader
 		Datum
+		ChecksumError
 		Result
 		SQLRequest
 		SQLResponse
+		Session
 */
 package sqlwire
 
 import proto "github.com/gogo/protobuf/proto"
 import math "math"
 import cockroach_proto3 "github.com/cockroachdb/cockroach/proto"
+import cockroach_proto1 "github.com/cockroachdb/cockroach/proto"
 import cockroach_proto2 "github.com/cockroachdb/cockroach/proto"
 
 // discarding unused import gogoproto "gogoproto/gogo.pb"
@@ -44,8 +47,11 @@ type SQLRequestHeader struct {
 	Txn []byte `protobuf:"bytes,2,opt,name=txn" json:"txn,omitempty"`
 	// CmdID is optionally specified for request idempotence
 	// (i.e. replay protection).
-	CmdID            cockroach_proto3.ClientCmdID `protobuf:"bytes,3,opt,name=cmd_id" json:"cmd_id"`
-	XXX_unrecognized []byte                       `json:"-"`
+	CmdID cockroach_proto3.ClientCmdID `protobuf:"bytes,3,opt,name=cmd_id" json:"cmd_id"`
+	// User is the user the statements are executed as. The server verifies
+	// that the client is authenticated as the user.
+	User             string `protobuf:"bytes,4,opt,name=user" json:"user"`
+	XXX_unrecognized []byte `json:"-"`
 }
 
 func (m *SQLRequestHeader) Reset()         { *m = SQLRequestHeader{} }
@@ -73,6 +79,13 @@ func (m *SQLRequestHeader) GetCmdID() cockroach_proto3.ClientCmdID {
 	return cockroach_proto3.ClientCmdID{}
 }
 
+func (m *SQLRequestHeader) GetUser() string {
+	if m != nil {
+		return m.User
+	}
+	return ""
+}
+
 // SQLResponseHeader is returned with every Cmd response.
 type SQLResponseHeader struct {
 	// Error is non-nil if an error occurred.
@@ -83,8 +96,11 @@ type SQLResponseHeader struct {
 	// Transaction message returned in a response; not to be interpreted by
 	// the recipient and reflected in a subsequent request. When not set,
 	// the subsequent request should not contain a transaction object.
-	Txn              []byte `protobuf:"bytes,3,opt,name=txn" json:"txn,omitempty"`
-	XXX_unrecognized []byte `json:"-"`
+	Txn []byte `protobuf:"bytes,3,opt,name=txn" json:"txn,omitempty"`
+	// Set in addition to error when the error is a checksum mismatch of one
+	// of the request parameters.
+	ChecksumError    *ChecksumError `protobuf:"bytes,4,opt,name=checksum_error" json:"checksum_error,omitempty"`
+	XXX_unrecognized []byte         `json:"-"`
 }
 
 func (m *SQLResponseHeader) Reset()         { *m = SQLResponseHeader{} }
@@ -112,17 +128,39 @@ func (m *SQLResponseHeader) GetTxn() []byte {
 	return nil
 }
 
+func (m *SQLResponseHeader) GetChecksumError() *ChecksumError {
+	if m != nil {
+		return m.ChecksumError
+	}
+	return nil
+}
+
 type Datum struct {
 	Bval    *bool    `protobuf:"varint,1,opt,name=bval" json:"bval,omitempty"`
 	Ival    *int64   `protobuf:"varint,2,opt,name=ival" json:"ival,omitempty"`
 	Dval    *float64 `protobuf:"fixed64,3,opt,name=dval" json:"dval,omitempty"`
 	Blobval []byte   `protobuf:"bytes,4,opt,name=blobval" json:"blobval,omitempty"`
-	// Checksum is a CRC-32-IEEE checksum of the value.
-	// If this is an integer value, then the value is interpreted as an 8
-	// byte, big-endian encoded value. This value is set by the client on
-	// updates to do end-to-end integrity verification. If the checksum is
-	// incorrect, the update operation will fail. If the client does not
-	// wish to use end-to-end checksumming, this value should be nil.
+	Strval  *string  `protobuf:"bytes,5,opt,name=strval" json:"strval,omitempty"`
+	// The number of days since the unix epoch.
+	Dateval *int64           `protobuf:"varint,6,opt,name=dateval" json:"dateval,omitempty"`
+	Timeval *Datum_Timestamp `protobuf:"bytes,7,opt,name=timeval" json:"timeval,omitempty"`
+	// An exact decimal value in its textual form, e.g. "-12.345".
+	Decimalval *string `protobuf:"bytes,8,opt,name=decimalval" json:"decimalval,omitempty"`
+	// Set to true for an explicit NULL value. A Datum without a value
+	// is also interpreted as NULL.
+	Nullval *bool `protobuf:"varint,10,opt,name=nullval" json:"nullval,omitempty"`
+	// Checksum is a CRC-32-IEEE checksum of the value prefixed by a
+	// single byte holding the field number of the value, so that values
+	// of different types have different checksums. Integer, float (IEEE
+	// 754 bits) and date values are interpreted as an 8 byte, big-endian
+	// encoded value, a boolean as a single byte and a timestamp as the 8
+	// byte seconds followed by the 4 byte nanoseconds. Bytes, string and
+	// decimal values are used directly and NULL has the checksum of no
+	// bytes, without a prefix. This value is set by the client on updates
+	// to do end-to-end integrity verification and by the server on
+	// results. If the checksum is incorrect, the update operation will
+	// fail. If the client does not wish to use end-to-end checksumming,
+	// this value should be nil.
 	Checksum         *uint32 `protobuf:"fixed32,9,opt,name=checksum" json:"checksum,omitempty"`
 	XXX_unrecognized []byte  `json:"-"`
 }
@@ -159,6 +197,41 @@ func (m *Datum) GetBlobval() []byte {
 	return nil
 }
 
+func (m *Datum) GetStrval() string {
+	if m != nil && m.Strval != nil {
+		return *m.Strval
+	}
+	return ""
+}
+
+func (m *Datum) GetDateval() int64 {
+	if m != nil && m.Dateval != nil {
+		return *m.Dateval
+	}
+	return 0
+}
+
+func (m *Datum) GetTimeval() *Datum_Timestamp {
+	if m != nil {
+		return m.Timeval
+	}
+	return nil
+}
+
+func (m *Datum) GetDecimalval() string {
+	if m != nil && m.Decimalval != nil {
+		return *m.Decimalval
+	}
+	return ""
+}
+
+func (m *Datum) GetNullval() bool {
+	if m != nil && m.Nullval != nil {
+		return *m.Nullval
+	}
+	return false
+}
+
 func (m *Datum) GetChecksum() uint32 {
 	if m != nil && m.Checksum != nil {
 		return *m.Checksum
@@ -166,6 +239,68 @@ func (m *Datum) GetChecksum() uint32 {
 	return 0
 }
 
+// Timestamp represents an absolute time devoid of a time zone.
+type Datum_Timestamp struct {
+	// The number of seconds since the unix epoch.
+	Sec int64 `protobuf:"varint,1,opt,name=sec" json:"sec"`
+	// The non-negative nanosecond offset within sec, in the range
+	// [0, 999999999].
+	Nsec             uint32 `protobuf:"varint,2,opt,name=nsec" json:"nsec"`
+	XXX_unrecognized []byte `json:"-"`
+}
+
+func (m *Datum_Timestamp) Reset()         { *m = Datum_Timestamp{} }
+func (m *Datum_Timestamp) String() string { return proto.CompactTextString(m) }
+func (*Datum_Timestamp) ProtoMessage()    {}
+
+func (m *Datum_Timestamp) GetSec() int64 {
+	if m != nil {
+		return m.Sec
+	}
+	return 0
+}
+
+func (m *Datum_Timestamp) GetNsec() uint32 {
+	if m != nil {
+		return m.Nsec
+	}
+	return 0
+}
+
+// A ChecksumError is returned when the checksum of a Datum does not match
+// the checksum computed from its value.
+type ChecksumError struct {
+	Expected         uint32 `protobuf:"fixed32,1,opt,name=expected" json:"expected"`
+	Actual           uint32 `protobuf:"fixed32,2,opt,name=actual" json:"actual"`
+	Datum            *Datum `protobuf:"bytes,3,opt,name=datum" json:"datum,omitempty"`
+	XXX_unrecognized []byte `json:"-"`
+}
+
+func (m *ChecksumError) Reset()         { *m = ChecksumError{} }
+func (m *ChecksumError) String() string { return proto.CompactTextString(m) }
+func (*ChecksumError) ProtoMessage()    {}
+
+func (m *ChecksumError) GetExpected() uint32 {
+	if m != nil {
+		return m.Expected
+	}
+	return 0
+}
+
+func (m *ChecksumError) GetActual() uint32 {
+	if m != nil {
+		return m.Actual
+	}
+	return 0
+}
+
+func (m *ChecksumError) GetDatum() *Datum {
+	if m != nil {
+		return m.Datum
+	}
+	return nil
+}
+
 // A Result is a collection of values representing a row
 // in a result view. A column value not present in a row
 // has Nil Bytes in the value.
@@ -238,8 +373,11 @@ type SQLResponse struct {
 	// columns must equal the number of Datum in each Result.
 	Columns []string `protobuf:"bytes,2,rep,name=columns" json:"columns,omitempty"`
 	// The result set for the last Cmd in the request.
-	Results          []*Result `protobuf:"bytes,3,rep,name=results" json:"results,omitempty"`
-	XXX_unrecognized []byte    `json:"-"`
+	Results []*Result `protobuf:"bytes,3,rep,name=results" json:"results,omitempty"`
+	// The number of rows modified by the last Cmd in the request. Only set
+	// for statements which do not return a result set.
+	RowsAffected     int64  `protobuf:"varint,4,opt,name=rows_affected" json:"rows_affected"`
+	XXX_unrecognized []byte `json:"-"`
 }
 
 func (m *SQLResponse) Reset()         { *m = SQLResponse{} }
@@ -260,6 +398,62 @@ func (m *SQLResponse) GetResults() []*Result {
 	return nil
 }
 
+func (m *SQLResponse) GetRowsAffected() int64 {
+	if m != nil {
+		return m.RowsAffected
+	}
+	return 0
+}
+
+// Session contains the state of a SQL session which is maintained across
+// requests. The server returns the marshaled session in the settings of the
+// response header and the client reflects it back in subsequent requests.
+type Session struct {
+	// The current database.
+	Database string `protobuf:"bytes,1,opt,name=database" json:"database"`
+	// The isolation of the transactions started by the session.
+	DefaultIsolation cockroach_proto1.IsolationType `protobuf:"varint,2,opt,name=default_isolation,enum=cockroach.proto.IsolationType" json:"default_isolation"`
+	// The name of the time zone in which times are presented to the client,
+	// as found in the IANA time zone database. UTC when empty.
+	TimeZone string `protobuf:"bytes,3,opt,name=time_zone" json:"time_zone"`
+	// The duration in nanoseconds after which a statement is canceled. Zero
+	// when statements are not canceled.
+	StatementTimeout int64  `protobuf:"varint,4,opt,name=statement_timeout" json:"statement_timeout"`
+	XXX_unrecognized []byte `json:"-"`
+}
+
+func (m *Session) Reset()         { *m = Session{} }
+func (m *Session) String() string { return proto.CompactTextString(m) }
+func (*Session) ProtoMessage()    {}
+
+func (m *Session) GetDatabase() string {
+	if m != nil {
+		return m.Database
+	}
+	return ""
+}
+
+func (m *Session) GetDefaultIsolation() cockroach_proto1.IsolationType {
+	if m != nil {
+		return m.DefaultIsolation
+	}
+	return cockroach_proto1.SERIALIZABLE
+}
+
+func (m *Session) GetTimeZone() string {
+	if m != nil {
+		return m.TimeZone
+	}
+	return ""
+}
+
+func (m *Session) GetStatementTimeout() int64 {
+	if m != nil {
+		return m.StatementTimeout
+	}
+	return 0
+}
+
 func init() {
 }
 func (m *SQLRequestHeader) Unmarshal(data []byte) error {
@@ -349,6 +543,28 @@ func (m *SQLRequestHeader) Unmarshal(data []byte) error {
 				return err
 			}
 			index = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field User", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			postIndex := index + int(stringLen)
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.User = string(data[index:postIndex])
+			index = postIndex
 		default:
 			var sizeOfWire int
 			for {
@@ -463,6 +679,33 @@ func (m *SQLResponseHeader) Unmarshal(data []byte) error {
 			}
 			m.Txn = append([]byte{}, data[index:postIndex]...)
 			index = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ChecksumError", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			postIndex := index + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.ChecksumError == nil {
+				m.ChecksumError = &ChecksumError{}
+			}
+			if err := m.ChecksumError.Unmarshal(data[index:postIndex]); err != nil {
+				return err
+			}
+			index = postIndex
 		default:
 			var sizeOfWire int
 			for {
@@ -582,6 +825,96 @@ func (m *Datum) Unmarshal(data []byte) error {
 			}
 			m.Blobval = append([]byte{}, data[index:postIndex]...)
 			index = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Strval", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			postIndex := index + int(stringLen)
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			s := string(data[index:postIndex])
+			m.Strval = &s
+			index = postIndex
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Dateval", wireType)
+			}
+			var v int64
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				v |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Dateval = &v
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Timeval", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			postIndex := index + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Timeval == nil {
+				m.Timeval = &Datum_Timestamp{}
+			}
+			if err := m.Timeval.Unmarshal(data[index:postIndex]); err != nil {
+				return err
+			}
+			index = postIndex
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Decimalval", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			postIndex := index + int(stringLen)
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			s := string(data[index:postIndex])
+			m.Decimalval = &s
+			index = postIndex
 		case 9:
 			if wireType != 5 {
 				return fmt.Errorf("proto: wrong wireType = %d for field Checksum", wireType)
@@ -596,6 +929,191 @@ func (m *Datum) Unmarshal(data []byte) error {
 			v |= uint32(data[index-2]) << 16
 			v |= uint32(data[index-1]) << 24
 			m.Checksum = &v
+		case 10:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Nullval", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			b := bool(v != 0)
+			m.Nullval = &b
+		default:
+			var sizeOfWire int
+			for {
+				sizeOfWire++
+				wire >>= 7
+				if wire == 0 {
+					break
+				}
+			}
+			index -= sizeOfWire
+			skippy, err := github_com_gogo_protobuf_proto.Skip(data[index:])
+			if err != nil {
+				return err
+			}
+			if (index + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, data[index:index+skippy]...)
+			index += skippy
+		}
+	}
+
+	return nil
+}
+func (m *Datum_Timestamp) Unmarshal(data []byte) error {
+	l := len(data)
+	index := 0
+	for index < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if index >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := data[index]
+			index++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Sec", wireType)
+			}
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				m.Sec |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Nsec", wireType)
+			}
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				m.Nsec |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			var sizeOfWire int
+			for {
+				sizeOfWire++
+				wire >>= 7
+				if wire == 0 {
+					break
+				}
+			}
+			index -= sizeOfWire
+			skippy, err := github_com_gogo_protobuf_proto.Skip(data[index:])
+			if err != nil {
+				return err
+			}
+			if (index + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, data[index:index+skippy]...)
+			index += skippy
+		}
+	}
+
+	return nil
+}
+func (m *ChecksumError) Unmarshal(data []byte) error {
+	l := len(data)
+	index := 0
+	for index < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if index >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := data[index]
+			index++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		switch fieldNum {
+		case 1:
+			if wireType != 5 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Expected", wireType)
+			}
+			if (index + 4) > l {
+				return io.ErrUnexpectedEOF
+			}
+			index += 4
+			m.Expected = uint32(data[index-4])
+			m.Expected |= uint32(data[index-3]) << 8
+			m.Expected |= uint32(data[index-2]) << 16
+			m.Expected |= uint32(data[index-1]) << 24
+		case 2:
+			if wireType != 5 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Actual", wireType)
+			}
+			if (index + 4) > l {
+				return io.ErrUnexpectedEOF
+			}
+			index += 4
+			m.Actual = uint32(data[index-4])
+			m.Actual |= uint32(data[index-3]) << 8
+			m.Actual |= uint32(data[index-2]) << 16
+			m.Actual |= uint32(data[index-1]) << 24
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Datum", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			postIndex := index + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Datum == nil {
+				m.Datum = &Datum{}
+			}
+			if err := m.Datum.Unmarshal(data[index:postIndex]); err != nil {
+				return err
+			}
+			index = postIndex
 		default:
 			var sizeOfWire int
 			for {
@@ -718,22 +1236,113 @@ func (m *SQLRequest) Unmarshal(data []byte) error {
 				}
 				b := data[index]
 				index++
-				msglen |= (int(b) & 0x7F) << shift
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			postIndex := index + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.SQLRequestHeader.Unmarshal(data[index:postIndex]); err != nil {
+				return err
+			}
+			index = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Cmds", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			postIndex := index + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Cmds = append(m.Cmds, &SQLRequest_Cmd{})
+			if err := m.Cmds[len(m.Cmds)-1].Unmarshal(data[index:postIndex]); err != nil {
+				return err
+			}
+			index = postIndex
+		default:
+			var sizeOfWire int
+			for {
+				sizeOfWire++
+				wire >>= 7
+				if wire == 0 {
+					break
+				}
+			}
+			index -= sizeOfWire
+			skippy, err := github_com_gogo_protobuf_proto.Skip(data[index:])
+			if err != nil {
+				return err
+			}
+			if (index + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, data[index:index+skippy]...)
+			index += skippy
+		}
+	}
+
+	return nil
+}
+func (m *SQLRequest_Cmd) Unmarshal(data []byte) error {
+	l := len(data)
+	index := 0
+	for index < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if index >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := data[index]
+			index++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Sql", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				stringLen |= (uint64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			postIndex := index + msglen
+			postIndex := index + int(stringLen)
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if err := m.SQLRequestHeader.Unmarshal(data[index:postIndex]); err != nil {
-				return err
-			}
+			s := string(data[index:postIndex])
+			m.Sql = &s
 			index = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Cmds", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Params", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -751,8 +1360,8 @@ func (m *SQLRequest) Unmarshal(data []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Cmds = append(m.Cmds, &SQLRequest_Cmd{})
-			if err := m.Cmds[len(m.Cmds)-1].Unmarshal(data[index:postIndex]); err != nil {
+			m.Params = append(m.Params, &Datum{})
+			if err := m.Params[len(m.Params)-1].Unmarshal(data[index:postIndex]); err != nil {
 				return err
 			}
 			index = postIndex
@@ -780,7 +1389,7 @@ func (m *SQLRequest) Unmarshal(data []byte) error {
 
 	return nil
 }
-func (m *SQLRequest_Cmd) Unmarshal(data []byte) error {
+func (m *SQLResponse) Unmarshal(data []byte) error {
 	l := len(data)
 	index := 0
 	for index < l {
@@ -801,7 +1410,31 @@ func (m *SQLRequest_Cmd) Unmarshal(data []byte) error {
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Sql", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field SQLResponseHeader", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			postIndex := index + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.SQLResponseHeader.Unmarshal(data[index:postIndex]); err != nil {
+				return err
+			}
+			index = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Columns", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -819,12 +1452,11 @@ func (m *SQLRequest_Cmd) Unmarshal(data []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			s := string(data[index:postIndex])
-			m.Sql = &s
+			m.Columns = append(m.Columns, string(data[index:postIndex]))
 			index = postIndex
-		case 2:
+		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Params", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Results", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -842,11 +1474,26 @@ func (m *SQLRequest_Cmd) Unmarshal(data []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Params = append(m.Params, &Datum{})
-			if err := m.Params[len(m.Params)-1].Unmarshal(data[index:postIndex]); err != nil {
+			m.Results = append(m.Results, &Result{})
+			if err := m.Results[len(m.Results)-1].Unmarshal(data[index:postIndex]); err != nil {
 				return err
 			}
 			index = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RowsAffected", wireType)
+			}
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				m.RowsAffected |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			var sizeOfWire int
 			for {
@@ -871,7 +1518,7 @@ func (m *SQLRequest_Cmd) Unmarshal(data []byte) error {
 
 	return nil
 }
-func (m *SQLResponse) Unmarshal(data []byte) error {
+func (m *Session) Unmarshal(data []byte) error {
 	l := len(data)
 	index := 0
 	for index < l {
@@ -892,31 +1539,44 @@ func (m *SQLResponse) Unmarshal(data []byte) error {
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field SQLResponseHeader", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Database", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if index >= l {
 					return io.ErrUnexpectedEOF
 				}
 				b := data[index]
 				index++
-				msglen |= (int(b) & 0x7F) << shift
+				stringLen |= (uint64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			postIndex := index + msglen
+			postIndex := index + int(stringLen)
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if err := m.SQLResponseHeader.Unmarshal(data[index:postIndex]); err != nil {
-				return err
-			}
+			m.Database = string(data[index:postIndex])
 			index = postIndex
 		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DefaultIsolation", wireType)
+			}
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				m.DefaultIsolation |= (cockroach_proto1.IsolationType(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Columns", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field TimeZone", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -934,33 +1594,23 @@ func (m *SQLResponse) Unmarshal(data []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Columns = append(m.Columns, string(data[index:postIndex]))
+			m.TimeZone = string(data[index:postIndex])
 			index = postIndex
-		case 3:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Results", wireType)
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StatementTimeout", wireType)
 			}
-			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if index >= l {
 					return io.ErrUnexpectedEOF
 				}
 				b := data[index]
 				index++
-				msglen |= (int(b) & 0x7F) << shift
+				m.StatementTimeout |= (int64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			postIndex := index + msglen
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.Results = append(m.Results, &Result{})
-			if err := m.Results[len(m.Results)-1].Unmarshal(data[index:postIndex]); err != nil {
-				return err
-			}
-			index = postIndex
 		default:
 			var sizeOfWire int
 			for {
@@ -998,9 +1648,24 @@ func (this *Datum) GetValue() interface{} {
 	if this.Blobval != nil {
 		return this.Blobval
 	}
+	if this.Strval != nil {
+		return this.Strval
+	}
+	if this.Dateval != nil {
+		return this.Dateval
+	}
+	if this.Timeval != nil {
+		return this.Timeval
+	}
+	if this.Decimalval != nil {
+		return this.Decimalval
+	}
 	if this.Checksum != nil {
 		return this.Checksum
 	}
+	if this.Nullval != nil {
+		return this.Nullval
+	}
 	return nil
 }
 
@@ -1014,6 +1679,10 @@ func (this *Datum) SetValue(value interface{}) bool {
 		this.Dval = vt
 	case []byte:
 		this.Blobval = vt
+	case *string:
+		this.Strval = vt
+	case *Datum_Timestamp:
+		this.Timeval = vt
 	case *uint32:
 		this.Checksum = vt
 	default:
@@ -1034,6 +1703,8 @@ func (m *SQLRequestHeader) Size() (n int) {
 	}
 	l = m.CmdID.Size()
 	n += 1 + l + sovSqlApi(uint64(l))
+	l = len(m.User)
+	n += 1 + l + sovSqlApi(uint64(l))
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -1055,6 +1726,10 @@ func (m *SQLResponseHeader) Size() (n int) {
 		l = len(m.Txn)
 		n += 1 + l + sovSqlApi(uint64(l))
 	}
+	if m.ChecksumError != nil {
+		l = m.ChecksumError.Size()
+		n += 1 + l + sovSqlApi(uint64(l))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -1077,9 +1752,53 @@ func (m *Datum) Size() (n int) {
 		l = len(m.Blobval)
 		n += 1 + l + sovSqlApi(uint64(l))
 	}
+	if m.Strval != nil {
+		l = len(*m.Strval)
+		n += 1 + l + sovSqlApi(uint64(l))
+	}
+	if m.Dateval != nil {
+		n += 1 + sovSqlApi(uint64(*m.Dateval))
+	}
+	if m.Timeval != nil {
+		l = m.Timeval.Size()
+		n += 1 + l + sovSqlApi(uint64(l))
+	}
+	if m.Decimalval != nil {
+		l = len(*m.Decimalval)
+		n += 1 + l + sovSqlApi(uint64(l))
+	}
 	if m.Checksum != nil {
 		n += 5
 	}
+	if m.Nullval != nil {
+		n += 2
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *Datum_Timestamp) Size() (n int) {
+	var l int
+	_ = l
+	n += 1 + sovSqlApi(uint64(m.Sec))
+	n += 1 + sovSqlApi(uint64(m.Nsec))
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ChecksumError) Size() (n int) {
+	var l int
+	_ = l
+	n += 5
+	n += 5
+	if m.Datum != nil {
+		l = m.Datum.Size()
+		n += 1 + l + sovSqlApi(uint64(l))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -1154,6 +1873,22 @@ func (m *SQLResponse) Size() (n int) {
 			n += 1 + l + sovSqlApi(uint64(l))
 		}
 	}
+	n += 1 + sovSqlApi(uint64(m.RowsAffected))
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *Session) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Database)
+	n += 1 + l + sovSqlApi(uint64(l))
+	n += 1 + sovSqlApi(uint64(m.DefaultIsolation))
+	l = len(m.TimeZone)
+	n += 1 + l + sovSqlApi(uint64(l))
+	n += 1 + sovSqlApi(uint64(m.StatementTimeout))
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -1208,6 +1943,10 @@ func (m *SQLRequestHeader) MarshalTo(data []byte) (n int, err error) {
 		return 0, err
 	}
 	i += n1
+	data[i] = 0x22
+	i++
+	i = encodeVarintSqlApi(data, i, uint64(len(m.User)))
+	i += copy(data[i:], m.User)
 	if m.XXX_unrecognized != nil {
 		i += copy(data[i:], m.XXX_unrecognized)
 	}
@@ -1251,6 +1990,16 @@ func (m *SQLResponseHeader) MarshalTo(data []byte) (n int, err error) {
 		i = encodeVarintSqlApi(data, i, uint64(len(m.Txn)))
 		i += copy(data[i:], m.Txn)
 	}
+	if m.ChecksumError != nil {
+		data[i] = 0x22
+		i++
+		i = encodeVarintSqlApi(data, i, uint64(m.ChecksumError.Size()))
+		n6, err := m.ChecksumError.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n6
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(data[i:], m.XXX_unrecognized)
 	}
@@ -1298,11 +2047,112 @@ func (m *Datum) MarshalTo(data []byte) (n int, err error) {
 		i = encodeVarintSqlApi(data, i, uint64(len(m.Blobval)))
 		i += copy(data[i:], m.Blobval)
 	}
+	if m.Strval != nil {
+		data[i] = 0x2a
+		i++
+		i = encodeVarintSqlApi(data, i, uint64(len(*m.Strval)))
+		i += copy(data[i:], *m.Strval)
+	}
+	if m.Dateval != nil {
+		data[i] = 0x30
+		i++
+		i = encodeVarintSqlApi(data, i, uint64(*m.Dateval))
+	}
+	if m.Timeval != nil {
+		data[i] = 0x3a
+		i++
+		i = encodeVarintSqlApi(data, i, uint64(m.Timeval.Size()))
+		n3, err := m.Timeval.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n3
+	}
+	if m.Decimalval != nil {
+		data[i] = 0x42
+		i++
+		i = encodeVarintSqlApi(data, i, uint64(len(*m.Decimalval)))
+		i += copy(data[i:], *m.Decimalval)
+	}
 	if m.Checksum != nil {
 		data[i] = 0x4d
 		i++
 		i = encodeFixed32SqlApi(data, i, uint32(*m.Checksum))
 	}
+	if m.Nullval != nil {
+		data[i] = 0x50
+		i++
+		if *m.Nullval {
+			data[i] = 1
+		} else {
+			data[i] = 0
+		}
+		i++
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(data[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *Datum_Timestamp) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *Datum_Timestamp) MarshalTo(data []byte) (n int, err error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	data[i] = 0x8
+	i++
+	i = encodeVarintSqlApi(data, i, uint64(m.Sec))
+	data[i] = 0x10
+	i++
+	i = encodeVarintSqlApi(data, i, uint64(m.Nsec))
+	if m.XXX_unrecognized != nil {
+		i += copy(data[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *ChecksumError) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *ChecksumError) MarshalTo(data []byte) (n int, err error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	data[i] = 0xd
+	i++
+	i = encodeFixed32SqlApi(data, i, uint32(m.Expected))
+	data[i] = 0x15
+	i++
+	i = encodeFixed32SqlApi(data, i, uint32(m.Actual))
+	if m.Datum != nil {
+		data[i] = 0x1a
+		i++
+		i = encodeVarintSqlApi(data, i, uint64(m.Datum.Size()))
+		n7, err := m.Datum.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n7
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(data[i:], m.XXX_unrecognized)
 	}
@@ -1360,11 +2210,11 @@ func (m *SQLRequest) MarshalTo(data []byte) (n int, err error) {
 	data[i] = 0xa
 	i++
 	i = encodeVarintSqlApi(data, i, uint64(m.SQLRequestHeader.Size()))
-	n3, err := m.SQLRequestHeader.MarshalTo(data[i:])
+	n4, err := m.SQLRequestHeader.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n3
+	i += n4
 	if len(m.Cmds) > 0 {
 		for _, msg := range m.Cmds {
 			data[i] = 0x12
@@ -1440,11 +2290,11 @@ func (m *SQLResponse) MarshalTo(data []byte) (n int, err error) {
 	data[i] = 0xa
 	i++
 	i = encodeVarintSqlApi(data, i, uint64(m.SQLResponseHeader.Size()))
-	n4, err := m.SQLResponseHeader.MarshalTo(data[i:])
+	n5, err := m.SQLResponseHeader.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n4
+	i += n5
 	if len(m.Columns) > 0 {
 		for _, s := range m.Columns {
 			data[i] = 0x12
@@ -1472,6 +2322,44 @@ func (m *SQLResponse) MarshalTo(data []byte) (n int, err error) {
 			i += n
 		}
 	}
+	data[i] = 0x20
+	i++
+	i = encodeVarintSqlApi(data, i, uint64(m.RowsAffected))
+	if m.XXX_unrecognized != nil {
+		i += copy(data[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *Session) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *Session) MarshalTo(data []byte) (n int, err error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	data[i] = 0xa
+	i++
+	i = encodeVarintSqlApi(data, i, uint64(len(m.Database)))
+	i += copy(data[i:], m.Database)
+	data[i] = 0x10
+	i++
+	i = encodeVarintSqlApi(data, i, uint64(m.DefaultIsolation))
+	data[i] = 0x1a
+	i++
+	i = encodeVarintSqlApi(data, i, uint64(len(m.TimeZone)))
+	i += copy(data[i:], m.TimeZone)
+	data[i] = 0x20
+	i++
+	i = encodeVarintSqlApi(data, i, uint64(m.StatementTimeout))
 	if m.XXX_unrecognized != nil {
 		i += copy(data[i:], m.XXX_unrecognized)
 	}
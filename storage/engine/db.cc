@@ -844,11 +844,13 @@ DBStatus ProcessDeltaKey(Getter* base, rocksdb::WBWIIterator* delta,
 }
 
 // This was cribbed from RocksDB and modified to support merge
-// records.
+// records. The delta iterator may contain several records for the
+// same key which are processed together by ProcessDelta().
 class BaseDeltaIterator : public rocksdb::Iterator {
  public:
   BaseDeltaIterator(rocksdb::Iterator* base_iterator, rocksdb::WBWIIterator* delta_iterator)
-      : current_at_base_(true),
+      : forward_(true),
+        current_at_base_(true),
         equal_keys_(false),
         status_(rocksdb::Status::OK()),
         base_iterator_(base_iterator),
@@ -866,18 +868,21 @@ class BaseDeltaIterator : public rocksdb::Iterator {
   }
 
   void SeekToFirst() override {
+    forward_ = true;
     base_iterator_->SeekToFirst();
     delta_iterator_->SeekToFirst();
     UpdateCurrent();
   }
 
   void SeekToLast() override {
+    forward_ = false;
     base_iterator_->SeekToLast();
     delta_iterator_->SeekToLast();
     UpdateCurrent();
   }
 
   void Seek(const rocksdb::Slice& k) override {
+    forward_ = true;
     base_iterator_->Seek(k);
     delta_iterator_->Seek(k);
     UpdateCurrent();
@@ -887,11 +892,20 @@ class BaseDeltaIterator : public rocksdb::Iterator {
     if (!Valid()) {
       status_ = rocksdb::Status::NotSupported("Next() on invalid iterator");
     }
+    if (!forward_ && Valid()) {
+      ChangeDirection();
+    }
     Advance();
   }
 
   void Prev() override {
-    status_ = rocksdb::Status::NotSupported("Prev() not supported");
+    if (!Valid()) {
+      status_ = rocksdb::Status::NotSupported("Prev() on invalid iterator");
+    }
+    if (forward_ && Valid()) {
+      ChangeDirection();
+    }
+    Advance();
   }
 
   rocksdb::Slice key() const override {
@@ -951,6 +965,42 @@ class BaseDeltaIterator : public rocksdb::Iterator {
 #endif
   }
 
+  // ChangeDirection reverses the direction of iteration while staying
+  // positioned at the current key. The iterator which is not at the
+  // current key is positioned at the key beyond the current key in the
+  // old direction and is moved to the key beyond it in the new
+  // direction. The subsequent Advance() then moves past the current
+  // key.
+  void ChangeDirection() {
+    forward_ = !forward_;
+    equal_keys_ = false;
+    if (!BaseValid()) {
+      assert(DeltaValid());
+      if (forward_) {
+        base_iterator_->SeekToFirst();
+      } else {
+        base_iterator_->SeekToLast();
+      }
+    } else if (!DeltaValid()) {
+      if (forward_) {
+        delta_iterator_->SeekToFirst();
+      } else {
+        delta_iterator_->SeekToLast();
+      }
+    } else if (current_at_base_) {
+      // Move delta from beyond base in the old direction to beyond
+      // base in the new direction.
+      AdvanceDelta();
+    } else {
+      // Move base from beyond delta in the old direction to beyond
+      // delta in the new direction.
+      AdvanceBase();
+    }
+    if (DeltaValid() && BaseValid() && Compare() == 0) {
+      equal_keys_ = true;
+    }
+  }
+
   void Advance() {
     if (equal_keys_) {
       assert(BaseValid() && DeltaValid());
@@ -968,15 +1018,30 @@ class BaseDeltaIterator : public rocksdb::Iterator {
     UpdateCurrent();
   }
 
+  // AdvanceDelta moves the delta iterator past all of the records for
+  // its current key in the direction of iteration.
   void AdvanceDelta() {
-    delta_iterator_->Next();
+    const std::string key = delta_iterator_->Entry().key.ToString();
+    do {
+      if (forward_) {
+        delta_iterator_->Next();
+      } else {
+        delta_iterator_->Prev();
+      }
+    } while (delta_iterator_->Valid() && delta_iterator_->Entry().key == key);
     ClearMerged();
   }
   bool ProcessDelta() {
     IteratorGetter base(equal_keys_ ? base_iterator_.get() : NULL);
+    const std::string key = delta_iterator_->Entry().key.ToString();
+    if (!forward_) {
+      // Iterating in reverse positions the delta iterator at the last
+      // record for the key, but the records need to be processed
+      // starting from the first one.
+      delta_iterator_->Seek(key);
+    }
     DBStatus status = ProcessDeltaKey(&base, delta_iterator_.get(),
-                                      delta_iterator_->Entry().key.ToString(),
-                                      &merged_);
+                                      key, &merged_);
     if (status.data != NULL) {
       status_ = rocksdb::Status::Corruption("unable to merge records");
       free(status.data);
@@ -995,7 +1060,11 @@ class BaseDeltaIterator : public rocksdb::Iterator {
     return merged_.data == NULL;
   }
   void AdvanceBase() {
-    base_iterator_->Next();
+    if (forward_) {
+      base_iterator_->Next();
+    } else {
+      base_iterator_->Prev();
+    }
   }
   bool BaseValid() const { return base_iterator_->Valid(); }
   bool DeltaValid() const { return delta_iterator_->Valid(); }
@@ -1024,7 +1093,9 @@ class BaseDeltaIterator : public rocksdb::Iterator {
         return;
       }
 
-      int compare = Compare();
+      // Iterating in reverse, delta is more advanced than base if its
+      // key is smaller.
+      int compare = (forward_ ? 1 : -1) * Compare();
       if (compare > 0) {   // delta less than base
         current_at_base_ = true;
         return;
@@ -1055,6 +1126,7 @@ class BaseDeltaIterator : public rocksdb::Iterator {
     }
   }
 
+  bool forward_;
   bool current_at_base_;
   bool equal_keys_;
   mutable rocksdb::Status status_;
@@ -1229,6 +1301,10 @@ void DBIterNext(DBIterator* iter) {
   iter->rep->Next();
 }
 
+void DBIterPrev(DBIterator* iter) {
+  iter->rep->Prev();
+}
+
 DBSlice DBIterKey(DBIterator* iter) {
   return ToDBSlice(iter->rep->key());
 }
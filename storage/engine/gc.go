@@ -41,6 +41,12 @@ func NewGarbageCollector(now proto.Timestamp, policy proto.GCPolicy) *GarbageCol
 	}
 }
 
+// Expiration returns the timestamp below which versions are garbage
+// collected.
+func (gc *GarbageCollector) Expiration() proto.Timestamp {
+	return gc.expiration
+}
+
 // Filter makes decisions about garbage collection based on the
 // garbage collection policy for batches of values for the same key.
 // Returns the timestamp including, and after which, all values should
@@ -916,8 +916,22 @@ func MVCCDeleteRange(engine Engine, ms *MVCCStats, key, endKey proto.Key, max in
 // scans.
 func MVCCScan(engine Engine, key, endKey proto.Key, max int64, timestamp proto.Timestamp,
 	consistent bool, txn *proto.Transaction) ([]proto.KeyValue, []proto.Intent, error) {
+	return mvccScanInternal(engine, key, endKey, max, timestamp, consistent, txn, false)
+}
+
+// MVCCReverseScan scans the key range specified by start key through
+// end key in descending key order up to some maximum number of
+// results. The results begin with the last key before end key. Specify
+// max=0 for unbounded scans.
+func MVCCReverseScan(engine Engine, key, endKey proto.Key, max int64, timestamp proto.Timestamp,
+	consistent bool, txn *proto.Transaction) ([]proto.KeyValue, []proto.Intent, error) {
+	return mvccScanInternal(engine, key, endKey, max, timestamp, consistent, txn, true)
+}
+
+func mvccScanInternal(engine Engine, key, endKey proto.Key, max int64, timestamp proto.Timestamp,
+	consistent bool, txn *proto.Transaction, reverse bool) ([]proto.KeyValue, []proto.Intent, error) {
 	res := []proto.KeyValue{}
-	intents, err := MVCCIterate(engine, key, endKey, timestamp, consistent, txn, func(kv proto.KeyValue) (bool, error) {
+	intents, err := mvccIterateInternal(engine, key, endKey, timestamp, consistent, txn, reverse, func(kv proto.KeyValue) (bool, error) {
 		res = append(res, kv)
 		if max != 0 && max == int64(len(res)) {
 			return true, nil
@@ -937,6 +951,20 @@ func MVCCScan(engine Engine, key, endKey proto.Key, max int64, timestamp proto.T
 // iteration stops and the error is propagated.
 func MVCCIterate(engine Engine, startKey, endKey proto.Key, timestamp proto.Timestamp,
 	consistent bool, txn *proto.Transaction, f func(proto.KeyValue) (bool, error)) ([]proto.Intent, error) {
+	return mvccIterateInternal(engine, startKey, endKey, timestamp, consistent, txn, false, f)
+}
+
+// MVCCReverseIterate iterates over the key range specified by start
+// and end keys in descending key order, starting with the last key
+// before end key. See MVCCIterate.
+func MVCCReverseIterate(engine Engine, startKey, endKey proto.Key, timestamp proto.Timestamp,
+	consistent bool, txn *proto.Transaction, f func(proto.KeyValue) (bool, error)) ([]proto.Intent, error) {
+	return mvccIterateInternal(engine, startKey, endKey, timestamp, consistent, txn, true, f)
+}
+
+func mvccIterateInternal(engine Engine, startKey, endKey proto.Key, timestamp proto.Timestamp,
+	consistent bool, txn *proto.Transaction, reverse bool,
+	f func(proto.KeyValue) (bool, error)) ([]proto.Intent, error) {
 	if !consistent && txn != nil {
 		return nil, util.Errorf("cannot allow inconsistent reads within a transaction")
 	}
@@ -952,6 +980,9 @@ func MVCCIterate(engine Engine, startKey, endKey proto.Key, timestamp proto.Time
 	encEndKey := mvccEncodeKey(buf.key[0:0], endKey)
 	keyBuf := encEndKey[len(encEndKey):]
 	encKey := mvccEncodeKey(keyBuf, startKey)
+	if reverse {
+		encKey = encEndKey
+	}
 
 	// Get a new iterator and define our getEarlierFunc using iter.Seek.
 	iter := engine.NewIterator()
@@ -977,19 +1008,46 @@ func MVCCIterate(engine Engine, startKey, endKey proto.Key, timestamp proto.Time
 	var wiErr error
 
 	for {
-		iter.Seek(encKey)
-		if !iter.Valid() {
-			if err := iter.Error(); err != nil {
-				return nil, err
+		var metaKey proto.EncodedKey
+		if !reverse {
+			iter.Seek(encKey)
+			if !iter.Valid() {
+				if err := iter.Error(); err != nil {
+					return nil, err
+				}
+				break
 			}
-			break
-		}
-		metaKey := iter.Key()
-		if bytes.Compare(metaKey, encEndKey) >= 0 {
-			if err := iter.Error(); err != nil {
-				return nil, err
+			metaKey = iter.Key()
+			if bytes.Compare(metaKey, encEndKey) >= 0 {
+				if err := iter.Error(); err != nil {
+					return nil, err
+				}
+				break
 			}
-			break
+		} else {
+			// Moving backward from encKey, the iterator arrives at the
+			// oldest version of the preceding key (or at its metadata if
+			// the value is inline). The metadata, which precedes all of
+			// the versions of the key, is found by seeking forward to it.
+			iter.SeekReverse(encKey)
+			if !iter.Valid() {
+				if err := iter.Error(); err != nil {
+					return nil, err
+				}
+				break
+			}
+			prevKey, _, _ := MVCCDecodeKey(iter.Key())
+			if prevKey.Less(startKey) {
+				break
+			}
+			iter.Seek(mvccEncodeKey(keyBuf, prevKey))
+			if !iter.Valid() {
+				if err := iter.Error(); err != nil {
+					return nil, err
+				}
+				return nil, util.Errorf("expected an MVCC metadata key for %q", prevKey)
+			}
+			metaKey = iter.Key()
 		}
 		key, _, isValue := MVCCDecodeKey(metaKey)
 		if isValue {
@@ -1023,7 +1081,11 @@ func MVCCIterate(engine Engine, startKey, endKey proto.Key, timestamp proto.Time
 				return nil, err
 			}
 		}
-		encKey = mvccEncodeKey(keyBuf, key.Next())
+		if reverse {
+			encKey = mvccEncodeKey(keyBuf, key)
+		} else {
+			encKey = mvccEncodeKey(keyBuf, key.Next())
+		}
 	}
 	return intents, wiErr
 }
@@ -1033,6 +1033,120 @@ func TestMVCCScanInconsistent(t *testing.T) {
 	}
 }
 
+// TestMVCCReverseScan verifies that a reverse scan returns the keys in
+// descending order and reads the newest visible version of each key,
+// skipping deleted keys and keys written after the read timestamp.
+func TestMVCCReverseScan(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	engine := createTestEngine()
+	defer engine.Close()
+
+	ts1 := makeTS(1, 0)
+	ts2 := makeTS(2, 0)
+	ts3 := makeTS(3, 0)
+	ts4 := makeTS(4, 0)
+	if err := MVCCPut(engine, nil, testKey1, ts1, value1, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := MVCCPut(engine, nil, testKey1, ts3, value2, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := MVCCPut(engine, nil, testKey2, ts1, value2, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := MVCCDelete(engine, nil, testKey2, ts2, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := MVCCPut(engine, nil, testKey3, ts1, value3, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := MVCCPut(engine, nil, testKey3, ts2, value4, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := MVCCPut(engine, nil, testKey4, ts4, value4, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		start, end proto.Key
+		max        int64
+		ts         proto.Timestamp
+		expKVs     []proto.KeyValue
+	}{
+		{proto.KeyMin, proto.KeyMax, 0, makeTS(5, 0), []proto.KeyValue{
+			{Key: testKey4, Value: proto.Value{Bytes: value4.Bytes, Timestamp: &ts4}},
+			{Key: testKey3, Value: proto.Value{Bytes: value4.Bytes, Timestamp: &ts2}},
+			{Key: testKey1, Value: proto.Value{Bytes: value2.Bytes, Timestamp: &ts3}},
+		}},
+		// Historical reads see the versions written at or before the timestamp.
+		{proto.KeyMin, proto.KeyMax, 0, ts1, []proto.KeyValue{
+			{Key: testKey3, Value: proto.Value{Bytes: value3.Bytes, Timestamp: &ts1}},
+			{Key: testKey2, Value: proto.Value{Bytes: value2.Bytes, Timestamp: &ts1}},
+			{Key: testKey1, Value: proto.Value{Bytes: value1.Bytes, Timestamp: &ts1}},
+		}},
+		// The start key is inclusive and the end key is exclusive.
+		{testKey1, testKey4, 0, makeTS(5, 0), []proto.KeyValue{
+			{Key: testKey3, Value: proto.Value{Bytes: value4.Bytes, Timestamp: &ts2}},
+			{Key: testKey1, Value: proto.Value{Bytes: value2.Bytes, Timestamp: &ts3}},
+		}},
+		{testKey1.Next(), testKey4, 0, makeTS(5, 0), []proto.KeyValue{
+			{Key: testKey3, Value: proto.Value{Bytes: value4.Bytes, Timestamp: &ts2}},
+		}},
+		// The maximum number of results are the last keys of the range.
+		{proto.KeyMin, proto.KeyMax, 2, makeTS(5, 0), []proto.KeyValue{
+			{Key: testKey4, Value: proto.Value{Bytes: value4.Bytes, Timestamp: &ts4}},
+			{Key: testKey3, Value: proto.Value{Bytes: value4.Bytes, Timestamp: &ts2}},
+		}},
+		{testKey2, testKey3, 0, makeTS(5, 0), []proto.KeyValue{}},
+	}
+	for i, test := range testCases {
+		kvs, _, err := MVCCReverseScan(engine, test.start, test.end, test.max, test.ts, true, nil)
+		if err != nil {
+			t.Fatalf("%d: %s", i, err)
+		}
+		if !reflect.DeepEqual(kvs, test.expKVs) {
+			t.Errorf("%d: expected key values equal %v != %v", i, kvs, test.expKVs)
+		}
+	}
+}
+
+// TestMVCCReverseScanWithIntents verifies that a reverse scan within a
+// transaction reads its own intents and that a reverse scan outside of
+// it reports the intents as write intent errors.
+func TestMVCCReverseScanWithIntents(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	engine := createTestEngine()
+	defer engine.Close()
+
+	if err := MVCCPut(engine, nil, testKey1, makeTS(1, 0), value1, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := MVCCPut(engine, nil, testKey2, makeTS(1, 0), value2, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := MVCCPut(engine, nil, testKey2, makeTS(2, 0), value3, txn1); err != nil {
+		t.Fatal(err)
+	}
+
+	kvs, _, err := MVCCReverseScan(engine, testKey1, testKey4, 0, makeTS(2, 0), true, txn1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kvs) != 2 ||
+		!bytes.Equal(kvs[0].Key, testKey2) ||
+		!bytes.Equal(kvs[1].Key, testKey1) ||
+		!bytes.Equal(kvs[0].Value.Bytes, value3.Bytes) ||
+		!bytes.Equal(kvs[1].Value.Bytes, value1.Bytes) {
+		t.Fatalf("unexpected key values %v", kvs)
+	}
+
+	_, _, err = MVCCReverseScan(engine, testKey1, testKey4, 0, makeTS(2, 0), true, nil)
+	if wiErr, ok := err.(*proto.WriteIntentError); !ok || len(wiErr.Intents) != 1 ||
+		!bytes.Equal(wiErr.Intents[0].Key, testKey2) {
+		t.Fatalf("expected write intent error on %s; got %v", testKey2, err)
+	}
+}
+
 func TestMVCCDeleteRange(t *testing.T) {
 	defer leaktest.AfterTest(t)
 	engine := createTestEngine()
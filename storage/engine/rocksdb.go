@@ -721,6 +721,21 @@ func (r *rocksDBIterator) Seek(key []byte) {
 	}
 }
 
+func (r *rocksDBIterator) SeekReverse(key []byte) {
+	if len(key) == 0 {
+		C.DBIterSeekToLast(r.iter)
+		return
+	}
+	// Seek to the first key >= key and step back to the key before
+	// it. If there is no such key, every key in the engine is < key.
+	C.DBIterSeek(r.iter, goToCSlice(key))
+	if r.Valid() {
+		C.DBIterPrev(r.iter)
+	} else if r.Error() == nil {
+		C.DBIterSeekToLast(r.iter)
+	}
+}
+
 func (r *rocksDBIterator) Valid() bool {
 	return C.DBIterValid(r.iter) == 1
 }
@@ -729,6 +744,10 @@ func (r *rocksDBIterator) Next() {
 	C.DBIterNext(r.iter)
 }
 
+func (r *rocksDBIterator) Prev() {
+	C.DBIterPrev(r.iter)
+}
+
 func (r *rocksDBIterator) Key() proto.EncodedKey {
 	// The data returned by rocksdb_iter_{key,value} is not meant to be
 	// freed by the client. It is a direct reference to the data managed
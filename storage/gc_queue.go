@@ -122,6 +122,18 @@ func (gcq *gcQueue) process(now proto.Timestamp, rng *Range) error {
 	gcMeta := proto.NewGCMetadata(now.WallTime)
 	gc := engine.NewGarbageCollector(now, policy)
 
+	// Versions older than the expiration may be garbage collected, so reads
+	// below it are rejected from now on. The threshold never moves backwards,
+	// even if the TTL of the policy has been increased since the last scan.
+	prevMeta, err := rng.GetGCMetadata()
+	if err != nil {
+		return err
+	}
+	gcMeta.Threshold = prevMeta.Threshold
+	if policy.TTLSeconds > 0 && gcMeta.Threshold.Less(gc.Expiration()) {
+		gcMeta.Threshold = gc.Expiration()
+	}
+
 	// Compute intent expiration (intent age at which we attempt to resolve).
 	intentExp := now
 	intentExp.WallTime -= intentAgeThreshold.Nanoseconds()
@@ -278,6 +278,11 @@ func TestGCQueueProcess(t *testing.T) {
 	if *gcMeta.OldestIntentNanos != ts4.WallTime {
 		t.Errorf("expected oldest intent nanos=%d; got %d", ts4.WallTime, gcMeta.OldestIntentNanos)
 	}
+	if expThreshold := makeTS(now-24*60*60*1E9, 0); !gcMeta.Threshold.Equal(expThreshold) {
+		t.Errorf("expected GC threshold %s; got %s", expThreshold, gcMeta.Threshold)
+	} else if threshold := tc.rng.getGCThreshold(); !threshold.Equal(expThreshold) {
+		t.Errorf("expected in-memory GC threshold %s; got %s", expThreshold, threshold)
+	}
 
 	// Verify that the last verification timestamp was updated as whole range was scanned.
 	ts, err := tc.rng.GetLastVerificationTimestamp()
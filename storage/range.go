@@ -130,6 +130,7 @@ var tsCacheMethods = [...]bool{
 	proto.ConditionalPut:             true,
 	proto.Increment:                  true,
 	proto.Scan:                       true,
+	proto.ReverseScan:                true,
 	proto.Delete:                     true,
 	proto.DeleteRange:                true,
 	proto.InternalResolveIntent:      true,
@@ -205,6 +206,9 @@ type Range struct {
 	configHashes map[int][]byte // Config map sha256 hashes @ last gossip
 	lease        unsafe.Pointer // Information for leader lease, updated atomically
 	llMu         sync.Mutex     // Synchronizes readers' requests for leader lease
+	// GC threshold of the range as a *proto.Timestamp, updated atomically
+	// when GC metadata is written so that reads need not load it.
+	gcThreshold unsafe.Pointer
 
 	sync.RWMutex                 // Protects the following fields:
 	cmdQ         *CommandQueue   // Enforce at most one command is running per key(s)
@@ -242,6 +246,12 @@ func NewRange(desc *proto.RangeDescriptor, rm rangeManager) (*Range, error) {
 	}
 	atomic.StorePointer(&r.lease, unsafe.Pointer(lease))
 
+	gcThreshold, err := loadGCThreshold(r.rm.Engine(), desc.RaftID)
+	if err != nil {
+		return nil, err
+	}
+	r.setGCThreshold(gcThreshold)
+
 	if r.stats, err = newRangeStats(desc.RaftID, rm.Engine()); err != nil {
 		return nil, err
 	}
@@ -460,15 +470,49 @@ func (r *Range) ContainsKeyRange(start, end proto.Key) bool {
 
 // GetGCMetadata reads the latest GC metadata for this range.
 func (r *Range) GetGCMetadata() (*proto.GCMetadata, error) {
-	key := keys.RangeGCMetadataKey(r.Desc().RaftID)
+	return loadGCMetadata(r.rm.Engine(), r.Desc().RaftID)
+}
+
+func loadGCMetadata(eng engine.Engine, raftID proto.RaftID) (*proto.GCMetadata, error) {
 	gcMeta := &proto.GCMetadata{}
-	_, err := engine.MVCCGetProto(r.rm.Engine(), key, proto.ZeroTimestamp, true, nil, gcMeta)
-	if err != nil {
+	if _, err := engine.MVCCGetProto(eng, keys.RangeGCMetadataKey(raftID), proto.ZeroTimestamp, true, nil, gcMeta); err != nil {
 		return nil, err
 	}
 	return gcMeta, nil
 }
 
+func loadGCThreshold(eng engine.Engine, raftID proto.RaftID) (proto.Timestamp, error) {
+	gcMeta, err := loadGCMetadata(eng, raftID)
+	if err != nil {
+		return proto.ZeroTimestamp, err
+	}
+	return gcMeta.Threshold, nil
+}
+
+// getGCThreshold returns the GC threshold of the range.
+func (r *Range) getGCThreshold() proto.Timestamp {
+	return *(*proto.Timestamp)(atomic.LoadPointer(&r.gcThreshold))
+}
+
+// setGCThreshold updates the in-memory GC threshold of the range. It must be
+// called whenever the GC metadata of the range is written.
+func (r *Range) setGCThreshold(threshold proto.Timestamp) {
+	atomic.StorePointer(&r.gcThreshold, unsafe.Pointer(&threshold))
+}
+
+// checkGCThreshold returns an error if the timestamp is below the GC
+// threshold of the range. Versions older than the threshold may have been
+// garbage collected, so a read at such a timestamp could return a result
+// which never existed. The threshold is cached in memory, so the check does
+// not read from the engine.
+func (r *Range) checkGCThreshold(timestamp proto.Timestamp) error {
+	if threshold := r.getGCThreshold(); timestamp.Less(threshold) {
+		return util.Errorf("read at %s is below the GC threshold %s of range %d",
+			timestamp, threshold, r.Desc().RaftID)
+	}
+	return nil
+}
+
 // GetLastVerificationTimestamp reads the timestamp at which the range's
 // data was last verified.
 func (r *Range) GetLastVerificationTimestamp() (proto.Timestamp, error) {
@@ -581,8 +625,13 @@ func (r *Range) addReadOnlyCmd(ctx context.Context, args proto.Request, reply pr
 			reply.Header().SetGoError(util.Error("cannot allow inconsistent reads within a transaction"))
 			return reply.Header().GoError()
 		}
+		// A read at the current time is never below the GC threshold, which
+		// is always in the past.
 		if header.Timestamp.Equal(proto.ZeroTimestamp) {
 			header.Timestamp = r.rm.Clock().Now()
+		} else if err := r.checkGCThreshold(header.Timestamp); err != nil {
+			reply.Header().SetGoError(err)
+			return err
 		}
 		intents, err := r.executeCmd(r.rm.Engine(), nil, args, reply)
 		if err == nil {
@@ -605,6 +654,14 @@ func (r *Range) addReadOnlyCmd(ctx context.Context, args proto.Request, reply pr
 		return err
 	}
 
+	// Reject the read if the versions it would observe may have been garbage
+	// collected.
+	if err := r.checkGCThreshold(header.Timestamp); err != nil {
+		r.endCmd(cmdKey, args, err, true /* readOnly */)
+		reply.Header().SetGoError(err)
+		return err
+	}
+
 	// Execute read-only command.
 	intents, err := r.executeCmd(r.rm.Engine(), nil, args, reply)
 
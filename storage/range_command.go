@@ -70,6 +70,8 @@ func (r *Range) executeCmd(batch engine.Engine, ms *engine.MVCCStats, args proto
 		r.DeleteRange(batch, ms, tArgs, reply.(*proto.DeleteRangeResponse))
 	case *proto.ScanRequest:
 		intents = r.Scan(batch, tArgs, reply.(*proto.ScanResponse))
+	case *proto.ReverseScanRequest:
+		intents = r.ReverseScan(batch, tArgs, reply.(*proto.ReverseScanResponse))
 	case *proto.EndTransactionRequest:
 		r.EndTransaction(batch, ms, tArgs, reply.(*proto.EndTransactionResponse))
 	case *proto.InternalRangeLookupRequest:
@@ -183,6 +185,16 @@ func (r *Range) Scan(batch engine.Engine, args *proto.ScanRequest, reply *proto.
 	return intents
 }
 
+// ReverseScan scans the key range specified by start key through end
+// key in descending key order up to some maximum number of results.
+// The rows are returned starting with the last key before end key.
+func (r *Range) ReverseScan(batch engine.Engine, args *proto.ReverseScanRequest, reply *proto.ReverseScanResponse) []proto.Intent {
+	kvs, intents, err := engine.MVCCReverseScan(batch, args.Key, args.EndKey, args.MaxResults, args.Timestamp, args.ReadConsistency == proto.CONSISTENT, args.Txn)
+	reply.Rows = kvs
+	reply.SetGoError(err)
+	return intents
+}
+
 // EndTransaction either commits or aborts (rolls back) an extant
 // transaction according to the args.Commit parameter.
 func (r *Range) EndTransaction(batch engine.Engine, ms *engine.MVCCStats, args *proto.EndTransactionRequest, reply *proto.EndTransactionResponse) {
@@ -326,9 +338,13 @@ func (r *Range) EndTransaction(batch engine.Engine, ms *engine.MVCCStats, args *
 // nodes can aggressively cache RangeDescriptors which are likely to be desired
 // by their current workload.
 func (r *Range) InternalRangeLookup(batch engine.Engine, args *proto.InternalRangeLookupRequest, reply *proto.InternalRangeLookupResponse) []proto.Intent {
-	if err := keys.ValidateRangeMetaKey(args.Key); err != nil {
-		reply.SetGoError(err)
-		return nil
+	// An inclusive lookup of KeyMax finds the last range, whose
+	// metadata key is Meta2KeyMax.
+	if !args.Inclusive || !args.Key.Equal(keys.Meta2KeyMax) {
+		if err := keys.ValidateRangeMetaKey(args.Key); err != nil {
+			reply.SetGoError(err)
+			return nil
+		}
 	}
 
 	rangeCount := int64(args.MaxRanges)
@@ -345,6 +361,12 @@ func (r *Range) InternalRangeLookup(batch engine.Engine, args *proto.InternalRan
 	// for both the requested key and the keys immediately afterwards, up to
 	// MaxRanges.
 	startKey, endKey := keys.MetaScanBounds(args.Key)
+	if args.Inclusive && !args.Key.Equal(proto.KeyMin) {
+		// The metadata key of a range is its end key, so the metadata
+		// key equal to args.Key belongs to the range which ends at the
+		// requested key.
+		startKey = args.Key
+	}
 	// Scan inconsistently. Any intents encountered are bundled up, but other-
 	// wise ignored.
 	kvs, intents, err := engine.MVCCScan(batch, startKey, endKey, rangeCount,
@@ -457,10 +479,17 @@ func (r *Range) InternalGC(batch engine.Engine, ms *engine.MVCCStats, args *prot
 		return
 	}
 
-	// Store the GC metadata for this range.
+	// Store the GC metadata for this range and update the in-memory GC
+	// threshold once it has been committed.
 	key := keys.RangeGCMetadataKey(r.Desc().RaftID)
-	err := engine.MVCCPutProto(batch, ms, key, proto.ZeroTimestamp, nil, &args.GCMeta)
-	reply.SetGoError(err)
+	if err := engine.MVCCPutProto(batch, ms, key, proto.ZeroTimestamp, nil, &args.GCMeta); err != nil {
+		reply.SetGoError(err)
+		return
+	}
+	threshold := args.GCMeta.Threshold
+	batch.Defer(func() {
+		r.setGCThreshold(threshold)
+	})
 }
 
 // InternalPushTxn resolves conflicts between concurrent txns (or
@@ -938,6 +967,9 @@ func (r *Range) splitTrigger(batch engine.Engine, split *proto.SplitTrigger) err
 	if err != nil {
 		return err
 	}
+	// The new range is loaded before the batch is committed, so it does not
+	// yet observe the copied GC metadata.
+	newRng.setGCThreshold(gcMeta.Threshold)
 
 	// Compute stats for new range.
 	iter = newRangeDataIterator(&split.NewDesc, batch)
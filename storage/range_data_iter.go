@@ -36,7 +36,7 @@ type keyRange struct {
 // all of the range's data.
 //
 // A rangeDataIterator provides the same API as an Engine iterator
-// with the exception of the Seek() method.
+// with the exception of the Seek() and SeekReverse() methods.
 type rangeDataIterator struct {
 	curIndex int
 	ranges   []keyRange
@@ -85,6 +85,12 @@ func (ri *rangeDataIterator) Seek(key []byte) {
 	ri.advance()
 }
 
+// SeekReverse seeks to the last key before the specified key.
+func (ri *rangeDataIterator) SeekReverse(key []byte) {
+	ri.iter.SeekReverse(key)
+	ri.retreat()
+}
+
 // Valid returns whether the underlying iterator is valid.
 func (ri *rangeDataIterator) Valid() bool {
 	return ri.iter.Valid()
@@ -97,6 +103,12 @@ func (ri *rangeDataIterator) Next() {
 	ri.advance()
 }
 
+// Prev moves the iteration back to the previous raw key value.
+func (ri *rangeDataIterator) Prev() {
+	ri.iter.Prev()
+	ri.retreat()
+}
+
 // Key returns the current Key for the iteration if valid.
 func (ri *rangeDataIterator) Key() proto.EncodedKey {
 	return ri.iter.Key()
@@ -136,3 +148,23 @@ func (ri *rangeDataIterator) advance() {
 		}
 	}
 }
+
+// retreat moves the iterator backward through the ranges until a
+// valid key is found or the iteration is done and the iterator
+// becomes invalid.
+func (ri *rangeDataIterator) retreat() {
+	for {
+		if !ri.iter.Valid() || !ri.iter.Key().Less(ri.ranges[ri.curIndex].start) {
+			return
+		}
+		ri.curIndex--
+		if ri.curIndex >= 0 {
+			ri.iter.SeekReverse(ri.ranges[ri.curIndex].end)
+		} else {
+			// Otherwise, seek to end to make iterator invalid.
+			ri.curIndex = 0
+			ri.iter.Seek(engine.MVCCKeyMax)
+			return
+		}
+	}
+}
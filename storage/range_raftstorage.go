@@ -386,6 +386,12 @@ func (r *Range) ApplySnapshot(snap raftpb.Snapshot) error {
 		return err
 	}
 
+	// Read the GC threshold.
+	gcThreshold, err := loadGCThreshold(batch, desc.RaftID)
+	if err != nil {
+		return err
+	}
+
 	// Copy range stats to new range.
 	oldStats := r.stats
 	r.stats, err = newRangeStats(desc.RaftID, batch)
@@ -415,11 +421,12 @@ func (r *Range) ApplySnapshot(snap raftpb.Snapshot) error {
 	atomic.StoreUint64(&r.lastIndex, snap.Metadata.Index)
 	atomic.StoreUint64(&r.appliedIndex, snap.Metadata.Index)
 
-	// Atomically update the descriptor and lease.
+	// Atomically update the descriptor, lease and GC threshold.
 	if err := r.setDesc(&desc); err != nil {
 		return err
 	}
 	atomic.StorePointer(&r.lease, unsafe.Pointer(lease))
+	r.setGCThreshold(gcThreshold)
 	return nil
 }
 
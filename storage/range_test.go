@@ -348,6 +348,61 @@ func TestRangeReadConsistency(t *testing.T) {
 	}
 }
 
+// TestRangeReadBelowGCThreshold verifies that both consistent and
+// inconsistent reads at timestamps below the GC threshold of the range
+// are rejected.
+func TestRangeReadBelowGCThreshold(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	tc := testContext{}
+	tc.Start(t)
+	defer tc.Stop()
+
+	tc.manualClock.Set(20)
+	gcArgs := &proto.InternalGCRequest{
+		RequestHeader: proto.RequestHeader{
+			Key:       proto.Key("a"),
+			EndKey:    proto.Key("a").Next(),
+			Timestamp: tc.clock.Now(),
+			RaftID:    tc.rng.Desc().RaftID,
+			Replica:   proto.Replica{StoreID: tc.store.StoreID()},
+		},
+		GCMeta: proto.GCMetadata{Threshold: proto.Timestamp{WallTime: 10}},
+	}
+	if err := tc.rng.AddCmd(tc.rng.context(), proto.Call{Args: gcArgs, Reply: &proto.InternalGCResponse{}}, true); err != nil {
+		t.Fatal(err)
+	}
+	// The GC threshold is updated in memory once the GC metadata has been
+	// committed.
+	if threshold := tc.rng.getGCThreshold(); !threshold.Equal(gcArgs.GCMeta.Threshold) {
+		t.Fatalf("expected GC threshold %s; got %s", gcArgs.GCMeta.Threshold, threshold)
+	}
+
+	testCases := []struct {
+		wallTime    int64
+		consistency proto.ReadConsistencyType
+		expErr      bool
+	}{
+		{5, proto.CONSISTENT, true},
+		{5, proto.INCONSISTENT, true},
+		{10, proto.CONSISTENT, false},
+		{15, proto.CONSISTENT, false},
+		{15, proto.INCONSISTENT, false},
+	}
+	for i, test := range testCases {
+		gArgs, gReply := getArgs(proto.Key("a"), 1, tc.store.StoreID())
+		gArgs.Timestamp = proto.Timestamp{WallTime: test.wallTime}
+		gArgs.ReadConsistency = test.consistency
+		err := tc.rng.AddCmd(tc.rng.context(), proto.Call{Args: gArgs, Reply: gReply}, true)
+		if test.expErr {
+			if err == nil || !strings.Contains(err.Error(), "below the GC threshold") {
+				t.Errorf("%d: expected GC threshold error; got %v", i, err)
+			}
+		} else if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+		}
+	}
+}
+
 func TestRangeRangeBoundsChecking(t *testing.T) {
 	defer leaktest.AfterTest(t)
 	tc := testContext{}
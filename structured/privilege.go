@@ -0,0 +1,146 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package structured
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// A Privilege is a set of privileges which may be granted to a user on a
+// database or a table.
+type Privilege uint32
+
+// The privileges which may be granted. ALL grants the other privileges as
+// well as the privilege to change the schema of the database or table and to
+// grant and revoke privileges on it.
+const (
+	SelectPrivilege Privilege = 1 << iota
+	InsertPrivilege
+	UpdatePrivilege
+	DeletePrivilege
+	AllPrivilege
+)
+
+// privilegeNames are the names of the privileges in the order they are
+// listed.
+var privilegeNames = []struct {
+	p    Privilege
+	name string
+}{
+	{AllPrivilege, "ALL"},
+	{SelectPrivilege, "SELECT"},
+	{InsertPrivilege, "INSERT"},
+	{UpdatePrivilege, "UPDATE"},
+	{DeletePrivilege, "DELETE"},
+}
+
+// PrivilegeFromName returns the privilege of the specified name.
+func PrivilegeFromName(name string) (Privilege, error) {
+	for _, n := range privilegeNames {
+		if strings.EqualFold(n.name, name) {
+			return n.p, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown privilege \"%s\"", name)
+}
+
+// Names returns the names of the privileges of the set.
+func (p Privilege) Names() []string {
+	if p&AllPrivilege != 0 {
+		return []string{"ALL"}
+	}
+	var names []string
+	for _, n := range privilegeNames {
+		if p&n.p != 0 {
+			names = append(names, n.name)
+		}
+	}
+	return names
+}
+
+func (p Privilege) String() string {
+	return strings.Join(p.Names(), ", ")
+}
+
+// NewPrivilegeDescriptor returns a descriptor granting ALL to the user, who is
+// the creator of the database or table.
+func NewPrivilegeDescriptor(user string) PrivilegeDescriptor {
+	return PrivilegeDescriptor{
+		Users: []UserPrivileges{{User: user, Privileges: uint32(AllPrivilege)}},
+	}
+}
+
+// find returns the index of the privileges of the user, or the index at which
+// they would be inserted.
+func (p *PrivilegeDescriptor) find(user string) (int, bool) {
+	i := sort.Search(len(p.Users), func(i int) bool {
+		return p.Users[i].User >= user
+	})
+	return i, i < len(p.Users) && p.Users[i].User == user
+}
+
+// Grant adds the privileges to those of the user.
+func (p *PrivilegeDescriptor) Grant(user string, privs Privilege) {
+	i, ok := p.find(user)
+	if !ok {
+		p.Users = append(p.Users, UserPrivileges{})
+		copy(p.Users[i+1:], p.Users[i:])
+		p.Users[i] = UserPrivileges{User: user}
+	}
+	p.Users[i].Privileges |= uint32(privs)
+}
+
+// Revoke removes the privileges from those of the user. Revoking a privilege
+// from a user holding ALL leaves the user with the remaining privileges which
+// may be granted individually.
+func (p *PrivilegeDescriptor) Revoke(user string, privs Privilege) {
+	i, ok := p.find(user)
+	if !ok {
+		return
+	}
+	cur := Privilege(p.Users[i].Privileges)
+	if cur&AllPrivilege != 0 && privs&AllPrivilege == 0 {
+		cur = SelectPrivilege | InsertPrivilege | UpdatePrivilege | DeletePrivilege
+	}
+	cur &^= privs
+	if privs&AllPrivilege != 0 {
+		cur = 0
+	}
+	if cur == 0 {
+		p.Users = append(p.Users[:i], p.Users[i+1:]...)
+		return
+	}
+	p.Users[i].Privileges = uint32(cur)
+}
+
+// Check returns true if the user holds the privilege, either granted
+// individually or as part of ALL.
+func (p *PrivilegeDescriptor) Check(user string, priv Privilege) bool {
+	i, ok := p.find(user)
+	if !ok {
+		return false
+	}
+	privs := Privilege(p.Users[i].Privileges)
+	return privs&AllPrivilege != 0 || privs&priv == priv
+}
+
+// CheckAny returns true if the user holds any privilege.
+func (p *PrivilegeDescriptor) CheckAny(user string) bool {
+	_, ok := p.find(user)
+	return ok
+}
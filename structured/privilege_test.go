@@ -0,0 +1,90 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package structured
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/util/leaktest"
+)
+
+func TestPrivilegeDescriptor(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	p := NewPrivilegeDescriptor("root")
+	p.Grant("foo", SelectPrivilege|InsertPrivilege)
+	p.Grant("bar", DeletePrivilege)
+	p.Grant("foo", UpdatePrivilege)
+
+	expected := []UserPrivileges{
+		{User: "bar", Privileges: uint32(DeletePrivilege)},
+		{User: "foo", Privileges: uint32(SelectPrivilege | InsertPrivilege | UpdatePrivilege)},
+		{User: "root", Privileges: uint32(AllPrivilege)},
+	}
+	if !reflect.DeepEqual(expected, p.Users) {
+		t.Fatalf("expected %+v, but found %+v", expected, p.Users)
+	}
+
+	testData := []struct {
+		user     string
+		priv     Privilege
+		expected bool
+	}{
+		{"foo", SelectPrivilege, true},
+		{"foo", DeletePrivilege, false},
+		{"foo", AllPrivilege, false},
+		{"bar", DeletePrivilege, true},
+		{"root", DeletePrivilege, true},
+		{"root", AllPrivilege, true},
+		{"baz", SelectPrivilege, false},
+	}
+	for _, d := range testData {
+		if r := p.Check(d.user, d.priv); r != d.expected {
+			t.Errorf("%s %s: expected %t, but found %t", d.user, d.priv, d.expected, r)
+		}
+	}
+	for user, expected := range map[string]bool{"foo": true, "bar": true, "baz": false} {
+		if r := p.CheckAny(user); r != expected {
+			t.Errorf("%s: expected %t, but found %t", user, expected, r)
+		}
+	}
+
+	// Revoking a privilege from ALL leaves the other privileges.
+	p.Revoke("root", InsertPrivilege)
+	if s := Privilege(p.Users[2].Privileges).String(); s != "SELECT, UPDATE, DELETE" {
+		t.Errorf("expected SELECT, UPDATE, DELETE, but found %s", s)
+	}
+	// Users without privileges are removed.
+	p.Revoke("bar", DeletePrivilege)
+	p.Revoke("foo", AllPrivilege)
+	p.Revoke("baz", AllPrivilege)
+	if len(p.Users) != 1 || p.Users[0].User != "root" {
+		t.Errorf("expected only root, but found %+v", p.Users)
+	}
+}
+
+func TestPrivilegeFromName(t *testing.T) {
+	defer leaktest.AfterTest(t)
+	if p, err := PrivilegeFromName("select"); err != nil || p != SelectPrivilege {
+		t.Errorf("expected SELECT, but found %s, %v", p, err)
+	}
+	if _, err := PrivilegeFromName("truncate"); err == nil {
+		t.Errorf("expected an error for an unknown privilege")
+	}
+	if s := (AllPrivilege | SelectPrivilege).String(); s != "ALL" {
+		t.Errorf("expected ALL, but found %s", s)
+	}
+}
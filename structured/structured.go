@@ -67,6 +67,10 @@ func ValidateTableDesc(desc TableDescriptor) error {
 		}
 		columnNames[column.Name] = struct{}{}
 
+		if column.DefaultExpr != nil && *column.DefaultExpr == "" {
+			return fmt.Errorf("column \"%s\" has an empty default expression", column.Name)
+		}
+
 		if other, ok := columnIDs[column.ID]; ok {
 			return fmt.Errorf("column \"%s\" duplicate ID of column \"%s\": %d",
 				column.Name, other, column.ID)
@@ -79,6 +83,14 @@ func ValidateTableDesc(desc TableDescriptor) error {
 		}
 	}
 
+	// A view does not store any data and has no indexes.
+	if desc.IsView() {
+		if len(desc.Indexes) != 0 {
+			return fmt.Errorf("view must not contain indexes")
+		}
+		return nil
+	}
+
 	// TODO(pmattis): Check that the indexes are unique. That is, no 2 indexes
 	// should contain identical sets of columns.
 
@@ -122,6 +134,24 @@ func ValidateTableDesc(desc TableDescriptor) error {
 	return nil
 }
 
+// IsView returns true if the descriptor describes a view rather than a
+// table.
+func (desc *TableDescriptor) IsView() bool {
+	return desc.ViewQuery != ""
+}
+
+// IsPublic returns true if the column is visible to statements. See
+// ColumnDescriptor_State.
+func (desc *ColumnDescriptor) IsPublic() bool {
+	return desc.State == ColumnDescriptor_PUBLIC
+}
+
+// IsPublic returns true if the index is used to read the table. See
+// IndexDescriptor_State.
+func (desc *IndexDescriptor) IsPublic() bool {
+	return desc.State == IndexDescriptor_PUBLIC
+}
+
 // TableDescFromSchema initializes a TableDescriptor from a TableSchema. The
 // TableSchema is expected to be valid. An invalid table schema will result in
 // an invalid table descriptor. Call ValidateTableDesc on the resulting
@@ -177,11 +207,17 @@ func TableSchemaFromDesc(desc TableDescriptor) TableSchema {
 
 	columnNamesByID := map[uint32]string{}
 	for _, column := range desc.Columns {
+		if !column.IsPublic() {
+			continue
+		}
 		schema.Columns = append(schema.Columns, column.Column)
 		columnNamesByID[column.ID] = column.Name
 	}
 
 	for _, index := range desc.Indexes {
+		if !index.IsPublic() {
+			continue
+		}
 		i := TableSchema_IndexByName{
 			Index: index.Index,
 		}
@@ -193,6 +229,58 @@ func TableSchemaFromDesc(desc TableDescriptor) TableSchema {
 	return schema
 }
 
+// FindColumnByName finds the column with specified name. Columns which are
+// not public are not found.
+func (desc *TableDescriptor) FindColumnByName(name string) (*ColumnDescriptor, error) {
+	for i, c := range desc.Columns {
+		if c.Name == name && c.IsPublic() {
+			return &desc.Columns[i], nil
+		}
+	}
+	return nil, fmt.Errorf("column \"%s\" does not exist", name)
+}
+
+// FindColumnByID finds the column with specified ID.
+func (desc *TableDescriptor) FindColumnByID(id uint32) (*ColumnDescriptor, error) {
+	for i, c := range desc.Columns {
+		if c.ID == id {
+			return &desc.Columns[i], nil
+		}
+	}
+	return nil, fmt.Errorf("column-id \"%d\" does not exist", id)
+}
+
+// FindIndexByName finds the index with specified name. Indexes which are not
+// public are not found.
+func (desc *TableDescriptor) FindIndexByName(name string) (*IndexDescriptor, error) {
+	for i, idx := range desc.Indexes {
+		if idx.Name == name && idx.IsPublic() {
+			return &desc.Indexes[i], nil
+		}
+	}
+	return nil, fmt.Errorf("index \"%s\" does not exist", name)
+}
+
+// FindIndexByID finds the index with specified ID.
+func (desc *TableDescriptor) FindIndexByID(id uint32) (*IndexDescriptor, error) {
+	for i, idx := range desc.Indexes {
+		if idx.ID == id {
+			return &desc.Indexes[i], nil
+		}
+	}
+	return nil, fmt.Errorf("index-id \"%d\" does not exist", id)
+}
+
+// ContainsColumnID returns true if the index contains the specified column.
+func (desc *IndexDescriptor) ContainsColumnID(id uint32) bool {
+	for _, columnID := range desc.ColumnIDs {
+		if columnID == id {
+			return true
+		}
+	}
+	return false
+}
+
 // SQLString returns the SQL string corresponding to the type.
 func (c *ColumnType) SQLString() string {
 	switch c.Kind {
@@ -17,6 +17,9 @@
 		ColumnDescriptor
 		IndexDescriptor
 		TableDescriptor
+		UserPrivileges
+		PrivilegeDescriptor
+		DatabaseDescriptor
 */
 package structured
 
@@ -104,6 +107,79 @@ func (x *ColumnType_Kind) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// A column being added to a table is WRITE_ONLY while the existing rows are
+// backfilled with its default value: the default value is written to new
+// rows but the column is not visible to statements. It becomes PUBLIC once
+// the backfill completes.
+type ColumnDescriptor_State int32
+
+const (
+	ColumnDescriptor_PUBLIC     ColumnDescriptor_State = 0
+	ColumnDescriptor_WRITE_ONLY ColumnDescriptor_State = 1
+)
+
+var ColumnDescriptor_State_name = map[int32]string{
+	0: "PUBLIC",
+	1: "WRITE_ONLY",
+}
+var ColumnDescriptor_State_value = map[string]int32{
+	"PUBLIC":     0,
+	"WRITE_ONLY": 1,
+}
+
+func (x ColumnDescriptor_State) Enum() *ColumnDescriptor_State {
+	p := new(ColumnDescriptor_State)
+	*p = x
+	return p
+}
+func (x ColumnDescriptor_State) String() string {
+	return proto.EnumName(ColumnDescriptor_State_name, int32(x))
+}
+func (x *ColumnDescriptor_State) UnmarshalJSON(data []byte) error {
+	value, err := proto.UnmarshalJSONEnum(ColumnDescriptor_State_value, data, "ColumnDescriptor_State")
+	if err != nil {
+		return err
+	}
+	*x = ColumnDescriptor_State(value)
+	return nil
+}
+
+// An index being added to a table is WRITE_ONLY while the existing rows are
+// backfilled: the entries of new rows are written to it but it is not used
+// to read the table. It becomes PUBLIC once the backfill completes.
+type IndexDescriptor_State int32
+
+const (
+	IndexDescriptor_PUBLIC     IndexDescriptor_State = 0
+	IndexDescriptor_WRITE_ONLY IndexDescriptor_State = 1
+)
+
+var IndexDescriptor_State_name = map[int32]string{
+	0: "PUBLIC",
+	1: "WRITE_ONLY",
+}
+var IndexDescriptor_State_value = map[string]int32{
+	"PUBLIC":     0,
+	"WRITE_ONLY": 1,
+}
+
+func (x IndexDescriptor_State) Enum() *IndexDescriptor_State {
+	p := new(IndexDescriptor_State)
+	*p = x
+	return p
+}
+func (x IndexDescriptor_State) String() string {
+	return proto.EnumName(IndexDescriptor_State_name, int32(x))
+}
+func (x *IndexDescriptor_State) UnmarshalJSON(data []byte) error {
+	value, err := proto.UnmarshalJSONEnum(IndexDescriptor_State_value, data, "IndexDescriptor_State")
+	if err != nil {
+		return err
+	}
+	*x = IndexDescriptor_State(value)
+	return nil
+}
+
 type Table struct {
 	Name             string `protobuf:"bytes,1,opt,name=name" json:"name"`
 	XXX_unrecognized []byte `json:"-"`
@@ -164,10 +240,13 @@ func (m *ColumnType) GetVals() []string {
 }
 
 type Column struct {
-	Name             string     `protobuf:"bytes,1,opt,name=name" json:"name"`
-	Type             ColumnType `protobuf:"bytes,2,opt,name=type" json:"type"`
-	Nullable         bool       `protobuf:"varint,3,opt,name=nullable" json:"nullable"`
-	XXX_unrecognized []byte     `json:"-"`
+	Name     string     `protobuf:"bytes,1,opt,name=name" json:"name"`
+	Type     ColumnType `protobuf:"bytes,2,opt,name=type" json:"type"`
+	Nullable bool       `protobuf:"varint,3,opt,name=nullable" json:"nullable"`
+	// The SQL text of the expression used to populate the column when no value
+	// is specified.
+	DefaultExpr      *string `protobuf:"bytes,4,opt,name=default_expr" json:"default_expr,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
 }
 
 func (m *Column) Reset()         { *m = Column{} }
@@ -195,6 +274,13 @@ func (m *Column) GetNullable() bool {
 	return false
 }
 
+func (m *Column) GetDefaultExpr() string {
+	if m != nil && m.DefaultExpr != nil {
+		return *m.DefaultExpr
+	}
+	return ""
+}
+
 type Index struct {
 	Name             string `protobuf:"bytes,1,opt,name=name" json:"name"`
 	Unique           bool   `protobuf:"varint,2,opt,name=unique" json:"unique"`
@@ -266,9 +352,10 @@ func (m *TableSchema_IndexByName) GetColumnNames() []string {
 }
 
 type ColumnDescriptor struct {
-	ID               uint32 `protobuf:"varint,1,opt,name=id" json:"id"`
+	ID               uint32                 `protobuf:"varint,1,opt,name=id" json:"id"`
 	Column           `protobuf:"bytes,2,opt,name=column,embedded=column" json:"column"`
-	XXX_unrecognized []byte `json:"-"`
+	State            ColumnDescriptor_State `protobuf:"varint,3,opt,name=state,enum=cockroach.structured.ColumnDescriptor_State" json:"state"`
+	XXX_unrecognized []byte                 `json:"-"`
 }
 
 func (m *ColumnDescriptor) Reset()         { *m = ColumnDescriptor{} }
@@ -282,14 +369,22 @@ func (m *ColumnDescriptor) GetID() uint32 {
 	return 0
 }
 
+func (m *ColumnDescriptor) GetState() ColumnDescriptor_State {
+	if m != nil {
+		return m.State
+	}
+	return ColumnDescriptor_PUBLIC
+}
+
 type IndexDescriptor struct {
 	ID    uint32 `protobuf:"varint,1,opt,name=id" json:"id"`
 	Index `protobuf:"bytes,2,opt,name=index,embedded=index" json:"index"`
 	// An ordered list of column ids of which the index is comprised. Each
 	// column_id refers to a column in the TableDescriptor's columns; special
 	// care is taken to update this when deleting columns.
-	ColumnIDs        []uint32 `protobuf:"varint,3,rep,name=column_ids" json:"column_ids,omitempty"`
-	XXX_unrecognized []byte   `json:"-"`
+	ColumnIDs        []uint32              `protobuf:"varint,3,rep,name=column_ids" json:"column_ids,omitempty"`
+	State            IndexDescriptor_State `protobuf:"varint,4,opt,name=state,enum=cockroach.structured.IndexDescriptor_State" json:"state"`
+	XXX_unrecognized []byte                `json:"-"`
 }
 
 func (m *IndexDescriptor) Reset()         { *m = IndexDescriptor{} }
@@ -310,6 +405,13 @@ func (m *IndexDescriptor) GetColumnIDs() []uint32 {
 	return nil
 }
 
+func (m *IndexDescriptor) GetState() IndexDescriptor_State {
+	if m != nil {
+		return m.State
+	}
+	return IndexDescriptor_PUBLIC
+}
+
 // A TableDescriptor represents a table and is stored in a structured metadata
 // key. The TableDescriptor has a globally-unique ID, while its member
 // {Column,Index}Descriptors have locally-unique IDs.
@@ -321,8 +423,21 @@ type TableDescriptor struct {
 	NextColumnID uint32            `protobuf:"varint,4,opt,name=next_column_id" json:"next_column_id"`
 	Indexes      []IndexDescriptor `protobuf:"bytes,5,rep,name=indexes" json:"indexes"`
 	// next_index_id is used to ensure that deleted index ids are not reused
-	NextIndexID      uint32 `protobuf:"varint,6,opt,name=next_index_id" json:"next_index_id"`
-	XXX_unrecognized []byte `json:"-"`
+	NextIndexID uint32 `protobuf:"varint,6,opt,name=next_index_id" json:"next_index_id"`
+	// version is incremented every time the descriptor is modified.
+	Version uint32 `protobuf:"varint,7,opt,name=version" json:"version"`
+	// view_query is the SELECT statement of a view, with its table names
+	// qualified by their databases. It is empty for a table.
+	ViewQuery string `protobuf:"bytes,8,opt,name=view_query" json:"view_query"`
+	// depends_on are the IDs of the tables and views referenced by the query of
+	// a view.
+	DependsOn []uint32 `protobuf:"varint,9,rep,name=depends_on" json:"depends_on,omitempty"`
+	// depended_on_by are the IDs of the views referencing the table or view,
+	// which prevent it from being dropped.
+	DependedOnBy []uint32 `protobuf:"varint,10,rep,name=depended_on_by" json:"depended_on_by,omitempty"`
+	// privileges are the privileges granted on the table or view.
+	Privileges       PrivilegeDescriptor `protobuf:"bytes,11,opt,name=privileges" json:"privileges"`
+	XXX_unrecognized []byte              `json:"-"`
 }
 
 func (m *TableDescriptor) Reset()         { *m = TableDescriptor{} }
@@ -364,8 +479,125 @@ func (m *TableDescriptor) GetNextIndexID() uint32 {
 	return 0
 }
 
+func (m *TableDescriptor) GetVersion() uint32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *TableDescriptor) GetViewQuery() string {
+	if m != nil {
+		return m.ViewQuery
+	}
+	return ""
+}
+
+func (m *TableDescriptor) GetDependsOn() []uint32 {
+	if m != nil {
+		return m.DependsOn
+	}
+	return nil
+}
+
+func (m *TableDescriptor) GetDependedOnBy() []uint32 {
+	if m != nil {
+		return m.DependedOnBy
+	}
+	return nil
+}
+
+func (m *TableDescriptor) GetPrivileges() PrivilegeDescriptor {
+	if m != nil {
+		return m.Privileges
+	}
+	return PrivilegeDescriptor{}
+}
+
+// UserPrivileges are the privileges granted to a single user.
+type UserPrivileges struct {
+	User string `protobuf:"bytes,1,opt,name=user" json:"user"`
+	// privileges is a bitfield of the granted privileges. See
+	// structured/privilege.go.
+	Privileges       uint32 `protobuf:"varint,2,opt,name=privileges" json:"privileges"`
+	XXX_unrecognized []byte `json:"-"`
+}
+
+func (m *UserPrivileges) Reset()         { *m = UserPrivileges{} }
+func (m *UserPrivileges) String() string { return proto.CompactTextString(m) }
+func (*UserPrivileges) ProtoMessage()    {}
+
+func (m *UserPrivileges) GetUser() string {
+	if m != nil {
+		return m.User
+	}
+	return ""
+}
+
+func (m *UserPrivileges) GetPrivileges() uint32 {
+	if m != nil {
+		return m.Privileges
+	}
+	return 0
+}
+
+// A PrivilegeDescriptor holds the privileges granted on a database or a
+// table, ordered by user.
+type PrivilegeDescriptor struct {
+	Users            []UserPrivileges `protobuf:"bytes,1,rep,name=users" json:"users"`
+	XXX_unrecognized []byte           `json:"-"`
+}
+
+func (m *PrivilegeDescriptor) Reset()         { *m = PrivilegeDescriptor{} }
+func (m *PrivilegeDescriptor) String() string { return proto.CompactTextString(m) }
+func (*PrivilegeDescriptor) ProtoMessage()    {}
+
+func (m *PrivilegeDescriptor) GetUsers() []UserPrivileges {
+	if m != nil {
+		return m.Users
+	}
+	return nil
+}
+
+// A DatabaseDescriptor represents a database and is stored in a structured
+// metadata key. The ID of the database is also stored under the name of the
+// database.
+type DatabaseDescriptor struct {
+	ID               uint32              `protobuf:"varint,1,opt,name=id" json:"id"`
+	Name             string              `protobuf:"bytes,2,opt,name=name" json:"name"`
+	Privileges       PrivilegeDescriptor `protobuf:"bytes,3,opt,name=privileges" json:"privileges"`
+	XXX_unrecognized []byte              `json:"-"`
+}
+
+func (m *DatabaseDescriptor) Reset()         { *m = DatabaseDescriptor{} }
+func (m *DatabaseDescriptor) String() string { return proto.CompactTextString(m) }
+func (*DatabaseDescriptor) ProtoMessage()    {}
+
+func (m *DatabaseDescriptor) GetID() uint32 {
+	if m != nil {
+		return m.ID
+	}
+	return 0
+}
+
+func (m *DatabaseDescriptor) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *DatabaseDescriptor) GetPrivileges() PrivilegeDescriptor {
+	if m != nil {
+		return m.Privileges
+	}
+	return PrivilegeDescriptor{}
+}
+
 func init() {
 	proto.RegisterEnum("cockroach.structured.ColumnType_Kind", ColumnType_Kind_name, ColumnType_Kind_value)
+	proto.RegisterEnum("cockroach.structured.ColumnDescriptor_State", ColumnDescriptor_State_name, ColumnDescriptor_State_value)
+	proto.RegisterEnum("cockroach.structured.IndexDescriptor_State", IndexDescriptor_State_name, IndexDescriptor_State_value)
 }
 func (m *Table) Unmarshal(data []byte) error {
 	l := len(data)
@@ -624,6 +856,29 @@ func (m *Column) Unmarshal(data []byte) error {
 				}
 			}
 			m.Nullable = bool(v != 0)
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DefaultExpr", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			postIndex := index + int(stringLen)
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			s := string(data[index:postIndex])
+			m.DefaultExpr = &s
+			index = postIndex
 		default:
 			var sizeOfWire int
 			for {
@@ -994,6 +1249,21 @@ func (m *ColumnDescriptor) Unmarshal(data []byte) error {
 				return err
 			}
 			index = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field State", wireType)
+			}
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				m.State |= (ColumnDescriptor_State(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			var sizeOfWire int
 			for {
@@ -1093,6 +1363,21 @@ func (m *IndexDescriptor) Unmarshal(data []byte) error {
 				}
 			}
 			m.ColumnIDs = append(m.ColumnIDs, v)
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field State", wireType)
+			}
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				m.State |= (IndexDescriptor_State(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			var sizeOfWire int
 			for {
@@ -1255,6 +1540,101 @@ func (m *TableDescriptor) Unmarshal(data []byte) error {
 					break
 				}
 			}
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Version", wireType)
+			}
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				m.Version |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ViewQuery", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			postIndex := index + int(stringLen)
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ViewQuery = string(data[index:postIndex])
+			index = postIndex
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DependsOn", wireType)
+			}
+			var v uint32
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				v |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.DependsOn = append(m.DependsOn, v)
+		case 10:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DependedOnBy", wireType)
+			}
+			var v uint32
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				v |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.DependedOnBy = append(m.DependedOnBy, v)
+		case 11:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Privileges", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			postIndex := index + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.Privileges.Unmarshal(data[index:postIndex]); err != nil {
+				return err
+			}
+			index = postIndex
 		default:
 			var sizeOfWire int
 			for {
@@ -1279,22 +1659,274 @@ func (m *TableDescriptor) Unmarshal(data []byte) error {
 
 	return nil
 }
-func (m *Table) Size() (n int) {
-	var l int
-	_ = l
-	l = len(m.Name)
-	n += 1 + l + sovStructured(uint64(l))
-	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
-	}
-	return n
-}
-
-func (m *ColumnType) Size() (n int) {
-	var l int
-	_ = l
-	n += 1 + sovStructured(uint64(m.Kind))
-	n += 1 + sovStructured(uint64(m.Width))
+func (m *UserPrivileges) Unmarshal(data []byte) error {
+	l := len(data)
+	index := 0
+	for index < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if index >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := data[index]
+			index++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field User", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			postIndex := index + int(stringLen)
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.User = string(data[index:postIndex])
+			index = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Privileges", wireType)
+			}
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				m.Privileges |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			var sizeOfWire int
+			for {
+				sizeOfWire++
+				wire >>= 7
+				if wire == 0 {
+					break
+				}
+			}
+			index -= sizeOfWire
+			skippy, err := github_com_gogo_protobuf_proto.Skip(data[index:])
+			if err != nil {
+				return err
+			}
+			if (index + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, data[index:index+skippy]...)
+			index += skippy
+		}
+	}
+
+	return nil
+}
+func (m *PrivilegeDescriptor) Unmarshal(data []byte) error {
+	l := len(data)
+	index := 0
+	for index < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if index >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := data[index]
+			index++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Users", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			postIndex := index + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Users = append(m.Users, UserPrivileges{})
+			if err := m.Users[len(m.Users)-1].Unmarshal(data[index:postIndex]); err != nil {
+				return err
+			}
+			index = postIndex
+		default:
+			var sizeOfWire int
+			for {
+				sizeOfWire++
+				wire >>= 7
+				if wire == 0 {
+					break
+				}
+			}
+			index -= sizeOfWire
+			skippy, err := github_com_gogo_protobuf_proto.Skip(data[index:])
+			if err != nil {
+				return err
+			}
+			if (index + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, data[index:index+skippy]...)
+			index += skippy
+		}
+	}
+
+	return nil
+}
+func (m *DatabaseDescriptor) Unmarshal(data []byte) error {
+	l := len(data)
+	index := 0
+	for index < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if index >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := data[index]
+			index++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ID", wireType)
+			}
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				m.ID |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			postIndex := index + int(stringLen)
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(data[index:postIndex])
+			index = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Privileges", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if index >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[index]
+				index++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			postIndex := index + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.Privileges.Unmarshal(data[index:postIndex]); err != nil {
+				return err
+			}
+			index = postIndex
+		default:
+			var sizeOfWire int
+			for {
+				sizeOfWire++
+				wire >>= 7
+				if wire == 0 {
+					break
+				}
+			}
+			index -= sizeOfWire
+			skippy, err := github_com_gogo_protobuf_proto.Skip(data[index:])
+			if err != nil {
+				return err
+			}
+			if (index + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, data[index:index+skippy]...)
+			index += skippy
+		}
+	}
+
+	return nil
+}
+func (m *Table) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Name)
+	n += 1 + l + sovStructured(uint64(l))
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ColumnType) Size() (n int) {
+	var l int
+	_ = l
+	n += 1 + sovStructured(uint64(m.Kind))
+	n += 1 + sovStructured(uint64(m.Width))
 	n += 1 + sovStructured(uint64(m.Precision))
 	if len(m.Vals) > 0 {
 		for _, s := range m.Vals {
@@ -1316,6 +1948,10 @@ func (m *Column) Size() (n int) {
 	l = m.Type.Size()
 	n += 1 + l + sovStructured(uint64(l))
 	n += 2
+	if m.DefaultExpr != nil {
+		l = len(*m.DefaultExpr)
+		n += 1 + l + sovStructured(uint64(l))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -1380,6 +2016,7 @@ func (m *ColumnDescriptor) Size() (n int) {
 	n += 1 + sovStructured(uint64(m.ID))
 	l = m.Column.Size()
 	n += 1 + l + sovStructured(uint64(l))
+	n += 1 + sovStructured(uint64(m.State))
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -1397,6 +2034,7 @@ func (m *IndexDescriptor) Size() (n int) {
 			n += 1 + sovStructured(uint64(e))
 		}
 	}
+	n += 1 + sovStructured(uint64(m.State))
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -1423,6 +2061,62 @@ func (m *TableDescriptor) Size() (n int) {
 		}
 	}
 	n += 1 + sovStructured(uint64(m.NextIndexID))
+	n += 1 + sovStructured(uint64(m.Version))
+	l = len(m.ViewQuery)
+	n += 1 + l + sovStructured(uint64(l))
+	if len(m.DependsOn) > 0 {
+		for _, e := range m.DependsOn {
+			n += 1 + sovStructured(uint64(e))
+		}
+	}
+	if len(m.DependedOnBy) > 0 {
+		for _, e := range m.DependedOnBy {
+			n += 1 + sovStructured(uint64(e))
+		}
+	}
+	l = m.Privileges.Size()
+	n += 1 + l + sovStructured(uint64(l))
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *UserPrivileges) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.User)
+	n += 1 + l + sovStructured(uint64(l))
+	n += 1 + sovStructured(uint64(m.Privileges))
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *PrivilegeDescriptor) Size() (n int) {
+	var l int
+	_ = l
+	if len(m.Users) > 0 {
+		for _, e := range m.Users {
+			l = e.Size()
+			n += 1 + l + sovStructured(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *DatabaseDescriptor) Size() (n int) {
+	var l int
+	_ = l
+	n += 1 + sovStructured(uint64(m.ID))
+	l = len(m.Name)
+	n += 1 + l + sovStructured(uint64(l))
+	l = m.Privileges.Size()
+	n += 1 + l + sovStructured(uint64(l))
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -1547,6 +2241,12 @@ func (m *Column) MarshalTo(data []byte) (n int, err error) {
 		data[i] = 0
 	}
 	i++
+	if m.DefaultExpr != nil {
+		data[i] = 0x22
+		i++
+		i = encodeVarintStructured(data, i, uint64(len(*m.DefaultExpr)))
+		i += copy(data[i:], *m.DefaultExpr)
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(data[i:], m.XXX_unrecognized)
 	}
@@ -1709,6 +2409,9 @@ func (m *ColumnDescriptor) MarshalTo(data []byte) (n int, err error) {
 		return 0, err
 	}
 	i += n4
+	data[i] = 0x18
+	i++
+	i = encodeVarintStructured(data, i, uint64(m.State))
 	if m.XXX_unrecognized != nil {
 		i += copy(data[i:], m.XXX_unrecognized)
 	}
@@ -1748,6 +2451,9 @@ func (m *IndexDescriptor) MarshalTo(data []byte) (n int, err error) {
 			i = encodeVarintStructured(data, i, uint64(num))
 		}
 	}
+	data[i] = 0x20
+	i++
+	i = encodeVarintStructured(data, i, uint64(m.State))
 	if m.XXX_unrecognized != nil {
 		i += copy(data[i:], m.XXX_unrecognized)
 	}
@@ -1810,6 +2516,132 @@ func (m *TableDescriptor) MarshalTo(data []byte) (n int, err error) {
 	data[i] = 0x30
 	i++
 	i = encodeVarintStructured(data, i, uint64(m.NextIndexID))
+	data[i] = 0x38
+	i++
+	i = encodeVarintStructured(data, i, uint64(m.Version))
+	data[i] = 0x42
+	i++
+	i = encodeVarintStructured(data, i, uint64(len(m.ViewQuery)))
+	i += copy(data[i:], m.ViewQuery)
+	if len(m.DependsOn) > 0 {
+		for _, num := range m.DependsOn {
+			data[i] = 0x48
+			i++
+			i = encodeVarintStructured(data, i, uint64(num))
+		}
+	}
+	if len(m.DependedOnBy) > 0 {
+		for _, num := range m.DependedOnBy {
+			data[i] = 0x50
+			i++
+			i = encodeVarintStructured(data, i, uint64(num))
+		}
+	}
+	data[i] = 0x5a
+	i++
+	i = encodeVarintStructured(data, i, uint64(m.Privileges.Size()))
+	n7, err := m.Privileges.MarshalTo(data[i:])
+	if err != nil {
+		return 0, err
+	}
+	i += n7
+	if m.XXX_unrecognized != nil {
+		i += copy(data[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *UserPrivileges) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *UserPrivileges) MarshalTo(data []byte) (n int, err error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	data[i] = 0xa
+	i++
+	i = encodeVarintStructured(data, i, uint64(len(m.User)))
+	i += copy(data[i:], m.User)
+	data[i] = 0x10
+	i++
+	i = encodeVarintStructured(data, i, uint64(m.Privileges))
+	if m.XXX_unrecognized != nil {
+		i += copy(data[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *PrivilegeDescriptor) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *PrivilegeDescriptor) MarshalTo(data []byte) (n int, err error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Users) > 0 {
+		for _, msg := range m.Users {
+			data[i] = 0xa
+			i++
+			i = encodeVarintStructured(data, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(data[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(data[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *DatabaseDescriptor) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *DatabaseDescriptor) MarshalTo(data []byte) (n int, err error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	data[i] = 0x8
+	i++
+	i = encodeVarintStructured(data, i, uint64(m.ID))
+	data[i] = 0x12
+	i++
+	i = encodeVarintStructured(data, i, uint64(len(m.Name)))
+	i += copy(data[i:], m.Name)
+	data[i] = 0x1a
+	i++
+	i = encodeVarintStructured(data, i, uint64(m.Privileges.Size()))
+	n8, err := m.Privileges.MarshalTo(data[i:])
+	if err != nil {
+		return 0, err
+	}
+	i += n8
 	if m.XXX_unrecognized != nil {
 		i += copy(data[i:], m.XXX_unrecognized)
 	}
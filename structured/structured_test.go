@@ -26,6 +26,7 @@ import (
 
 func TestValidateTableDesc(t *testing.T) {
 	defer leaktest.AfterTest(t)
+	emptyExpr := ""
 	testData := []struct {
 		err  string
 		desc TableDescriptor
@@ -70,6 +71,14 @@ func TestValidateTableDesc(t *testing.T) {
 				},
 				NextColumnID: 1,
 			}},
+		{`column "bar" has an empty default expression`,
+			TableDescriptor{
+				Table: Table{Name: "foo"},
+				Columns: []ColumnDescriptor{
+					{ID: 0, Column: Column{Name: "bar", DefaultExpr: &emptyExpr}},
+				},
+				NextColumnID: 1,
+			}},
 		{`empty index name`,
 			TableDescriptor{Table: Table{Name: "foo"},
 				Columns: []ColumnDescriptor{
@@ -126,6 +135,18 @@ func TestValidateTableDesc(t *testing.T) {
 				NextColumnID: 1,
 				NextIndexID:  1,
 			}},
+		{`view must not contain indexes`,
+			TableDescriptor{Table: Table{Name: "foo"},
+				Columns: []ColumnDescriptor{
+					{ID: 0, Column: Column{Name: "bar"}},
+				},
+				Indexes: []IndexDescriptor{
+					{ID: 0, Index: Index{Name: "bar"}, ColumnIDs: []uint32{0}},
+				},
+				NextColumnID: 1,
+				NextIndexID:  1,
+				ViewQuery:    "SELECT bar FROM t.foo",
+			}},
 	}
 	for i, d := range testData {
 		if err := ValidateTableDesc(d.desc); err == nil {
@@ -0,0 +1,341 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+// Package decimal implements exact decimal numbers, the values of DECIMAL
+// columns.
+package decimal
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MaxScale is the largest number of digits after the decimal point. The
+	// results of multiplications and divisions are rounded to at most
+	// MaxScale digits.
+	MaxScale = 1000
+	// MaxIntDigits is the largest number of digits before the decimal point
+	// of a parsed value. See IntDigits.
+	MaxIntDigits = 1000
+	// minQuoScale is the smallest number of digits after the decimal point of
+	// the result of a division.
+	minQuoScale = 16
+)
+
+var (
+	bigOne = big.NewInt(1)
+	bigTen = big.NewInt(10)
+)
+
+// A Decimal is the exact value unscaled * 10^-scale. The scale, which is
+// never negative, is the number of digits after the decimal point and is
+// preserved by String, so that 1.50 is not displayed as 1.5. A Decimal is
+// immutable; the arithmetic methods return a new Decimal.
+type Decimal struct {
+	unscaled big.Int
+	scale    int32
+}
+
+// New returns the decimal unscaled * 10^-scale. The scale must not be
+// negative.
+func New(unscaled *big.Int, scale int32) *Decimal {
+	if scale < 0 {
+		panic(fmt.Sprintf("negative scale: %d", scale))
+	}
+	d := &Decimal{scale: scale}
+	d.unscaled.Set(unscaled)
+	return d
+}
+
+// NewFromInt returns the decimal value of the integer.
+func NewFromInt(i int64) *Decimal {
+	d := &Decimal{}
+	d.unscaled.SetInt64(i)
+	return d
+}
+
+// NewFromFloat returns the decimal value of the shortest decimal
+// representation of the float which converts back to the same float, so
+// that 1.1 becomes exactly 1.1. NaN and infinities have no decimal value.
+func NewFromFloat(f float64) (*Decimal, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, fmt.Errorf("%g cannot be converted to a decimal", f)
+	}
+	return Parse(strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+// Parse parses a decimal in decimal notation, such as "-12.345", optionally
+// followed by an exponent, such as "1.2e3". An error is returned if the value
+// has more than MaxScale digits after or MaxIntDigits digits before the
+// decimal point.
+func Parse(s string) (*Decimal, error) {
+	str := s
+	var exp int64
+	if i := strings.IndexAny(str, "eE"); i >= 0 {
+		var err error
+		if exp, err = strconv.ParseInt(str[i+1:], 10, 32); err != nil {
+			return nil, fmt.Errorf("invalid decimal value: %q", s)
+		}
+		str = str[:i]
+	}
+	neg := false
+	if len(str) > 0 && (str[0] == '-' || str[0] == '+') {
+		neg = str[0] == '-'
+		str = str[1:]
+	}
+	intPart, fracPart := str, ""
+	if i := strings.IndexByte(str, '.'); i >= 0 {
+		intPart, fracPart = str[:i], str[i+1:]
+	}
+	digits := intPart + fracPart
+	if digits == "" || strings.TrimLeft(digits, "0123456789") != "" {
+		return nil, fmt.Errorf("invalid decimal value: %q", s)
+	}
+	scale := int64(len(fracPart)) - exp
+	if scale > MaxScale || int64(len(strings.TrimLeft(intPart, "0")))+exp > MaxIntDigits {
+		return nil, fmt.Errorf("decimal value out of range: %q", s)
+	}
+	d := &Decimal{}
+	d.unscaled.SetString(digits, 10)
+	if scale < 0 {
+		d.unscaled.Mul(&d.unscaled, pow10(-scale))
+		scale = 0
+	}
+	if neg {
+		d.unscaled.Neg(&d.unscaled)
+	}
+	d.scale = int32(scale)
+	if d.IntDigits() > MaxIntDigits {
+		return nil, fmt.Errorf("decimal value out of range: %q", s)
+	}
+	return d, nil
+}
+
+// pow10 returns 10^n.
+func pow10(n int64) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(n), nil)
+}
+
+// Unscaled returns the unscaled value of the decimal.
+func (d *Decimal) Unscaled() *big.Int {
+	return new(big.Int).Set(&d.unscaled)
+}
+
+// Scale returns the number of digits after the decimal point.
+func (d *Decimal) Scale() int32 {
+	return d.scale
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of the decimal.
+func (d *Decimal) Sign() int {
+	return d.unscaled.Sign()
+}
+
+// IntDigits returns the number of digits before the decimal point, not
+// counting leading zeros.
+func (d *Decimal) IntDigits() int {
+	if d.unscaled.Sign() == 0 {
+		return 0
+	}
+	n := len(new(big.Int).Abs(&d.unscaled).String()) - int(d.scale)
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// String returns the decimal in decimal notation with Scale digits after the
+// decimal point.
+func (d *Decimal) String() string {
+	s := new(big.Int).Abs(&d.unscaled).String()
+	if d.scale > 0 {
+		if n := int(d.scale) + 1 - len(s); n > 0 {
+			s = strings.Repeat("0", n) + s
+		}
+		s = s[:len(s)-int(d.scale)] + "." + s[len(s)-int(d.scale):]
+	}
+	if d.unscaled.Sign() < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+// Float64 returns the float nearest to the decimal.
+func (d *Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// align returns the unscaled values of x and y at their largest scale.
+func align(x, y *Decimal) (*big.Int, *big.Int, int32) {
+	switch {
+	case x.scale < y.scale:
+		xu := new(big.Int).Mul(&x.unscaled, pow10(int64(y.scale-x.scale)))
+		return xu, &y.unscaled, y.scale
+	case x.scale > y.scale:
+		yu := new(big.Int).Mul(&y.unscaled, pow10(int64(x.scale-y.scale)))
+		return &x.unscaled, yu, x.scale
+	}
+	return &x.unscaled, &y.unscaled, x.scale
+}
+
+// Cmp compares the values of d and o, returning -1, 0 or +1. The scale is
+// ignored: 1.5 and 1.50 are equal.
+func (d *Decimal) Cmp(o *Decimal) int {
+	du, ou, _ := align(d, o)
+	return du.Cmp(ou)
+}
+
+// Neg returns -d.
+func (d *Decimal) Neg() *Decimal {
+	r := &Decimal{scale: d.scale}
+	r.unscaled.Neg(&d.unscaled)
+	return r
+}
+
+// Abs returns the absolute value of d.
+func (d *Decimal) Abs() *Decimal {
+	r := &Decimal{scale: d.scale}
+	r.unscaled.Abs(&d.unscaled)
+	return r
+}
+
+// Add returns d + o, whose scale is the larger of the scales of d and o.
+func (d *Decimal) Add(o *Decimal) *Decimal {
+	du, ou, scale := align(d, o)
+	r := &Decimal{scale: scale}
+	r.unscaled.Add(du, ou)
+	return r
+}
+
+// Sub returns d - o, whose scale is the larger of the scales of d and o.
+func (d *Decimal) Sub(o *Decimal) *Decimal {
+	du, ou, scale := align(d, o)
+	r := &Decimal{scale: scale}
+	r.unscaled.Sub(du, ou)
+	return r
+}
+
+// Mul returns d * o, whose scale is the sum of the scales of d and o. The
+// result is rounded to MaxScale digits after the decimal point.
+func (d *Decimal) Mul(o *Decimal) *Decimal {
+	r := &Decimal{scale: d.scale + o.scale}
+	r.unscaled.Mul(&d.unscaled, &o.unscaled)
+	if r.scale > MaxScale {
+		return r.Round(MaxScale)
+	}
+	return r
+}
+
+// Quo returns d / o rounded half away from zero to the larger of the scales
+// of d and o, but to at least 16 and at most MaxScale digits after the
+// decimal point.
+func (d *Decimal) Quo(o *Decimal) (*Decimal, error) {
+	if o.unscaled.Sign() == 0 {
+		return nil, fmt.Errorf("division by zero")
+	}
+	scale := int32(minQuoScale)
+	if d.scale > scale {
+		scale = d.scale
+	}
+	if o.scale > scale {
+		scale = o.scale
+	}
+	// d/o = (d.unscaled * 10^(scale-d.scale+o.scale) / o.unscaled) * 10^-scale.
+	n := new(big.Int).Mul(&d.unscaled, pow10(int64(scale-d.scale+o.scale)))
+	r := &Decimal{scale: scale}
+	quoRound(&r.unscaled, n, &o.unscaled)
+	return r, nil
+}
+
+// Rem returns the remainder of d / o truncated to an integer, which has the
+// sign of d, and whose scale is the larger of the scales of d and o.
+func (d *Decimal) Rem(o *Decimal) (*Decimal, error) {
+	if o.unscaled.Sign() == 0 {
+		return nil, fmt.Errorf("division by zero")
+	}
+	du, ou, scale := align(d, o)
+	r := &Decimal{scale: scale}
+	r.unscaled.Rem(du, ou)
+	return r, nil
+}
+
+// Round returns d rounded half away from zero to scale digits after the
+// decimal point, padding d with zeros if it has fewer digits. A negative
+// scale rounds to the left of the decimal point: 123 rounded to a scale of
+// -1 is 120. The scale of the result is never negative and at most MaxScale.
+func (d *Decimal) Round(scale int32) *Decimal {
+	if scale > MaxScale {
+		scale = MaxScale
+	}
+	if scale < -2*MaxIntDigits {
+		scale = -2 * MaxIntDigits
+	}
+	if scale >= d.scale {
+		r := &Decimal{scale: scale}
+		r.unscaled.Mul(&d.unscaled, pow10(int64(scale-d.scale)))
+		return r
+	}
+	r := &Decimal{}
+	quoRound(&r.unscaled, &d.unscaled, pow10(int64(d.scale-scale)))
+	if scale < 0 {
+		r.unscaled.Mul(&r.unscaled, pow10(int64(-scale)))
+	} else {
+		r.scale = scale
+	}
+	return r
+}
+
+// Floor returns the largest integer not greater than d.
+func (d *Decimal) Floor() *Decimal {
+	return d.toInteger(-1)
+}
+
+// Ceil returns the smallest integer not less than d.
+func (d *Decimal) Ceil() *Decimal {
+	return d.toInteger(1)
+}
+
+// toInteger returns d rounded to an integer towards negative (dir < 0) or
+// positive (dir > 0) infinity.
+func (d *Decimal) toInteger(dir int) *Decimal {
+	r := &Decimal{}
+	var m big.Int
+	r.unscaled.QuoRem(&d.unscaled, pow10(int64(d.scale)), &m)
+	if m.Sign() == dir {
+		r.unscaled.Add(&r.unscaled, big.NewInt(int64(dir)))
+	}
+	return r
+}
+
+// quoRound sets z to n / m rounded half away from zero.
+func quoRound(z, n, m *big.Int) {
+	var r big.Int
+	z.QuoRem(n, m, &r)
+	// |r| >= |m| / 2 rounds away from zero.
+	r.Abs(&r)
+	r.Lsh(&r, 1)
+	if r.CmpAbs(m) >= 0 {
+		if n.Sign() != m.Sign() {
+			z.Sub(z, bigOne)
+		} else {
+			z.Add(z, bigOne)
+		}
+	}
+}
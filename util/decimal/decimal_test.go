@@ -0,0 +1,185 @@
+// Copyright 2015 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package decimal
+
+import (
+	"strings"
+	"testing"
+)
+
+func mustParse(t *testing.T, s string) *Decimal {
+	d, err := Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		s        string
+		expected string
+	}{
+		{"0", "0"},
+		{"-0", "0"},
+		{"0.00", "0.00"},
+		{"12.345", "12.345"},
+		{"-12.345", "-12.345"},
+		{"+1.50", "1.50"},
+		{".5", "0.5"},
+		{"5.", "5"},
+		{"-0.001", "-0.001"},
+		{"1000000000000000000000.5", "1000000000000000000000.5"},
+		{"1.2e3", "1200"},
+		{"1.25E-1", "0.125"},
+	}
+	for _, c := range testCases {
+		if s := mustParse(t, c.s).String(); s != c.expected {
+			t.Errorf("%s: expected %s, but found %s", c.s, c.expected, s)
+		}
+	}
+	for _, s := range []string{"", "-", ".", "abc", "1/3", "1.2.3", "1e", "0x10", "1_000",
+		"1e2000000000", "0." + strings.Repeat("1", MaxScale+1), "1" + strings.Repeat("0", MaxIntDigits)} {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
+
+func TestNewFromFloat(t *testing.T) {
+	testCases := []struct {
+		f        float64
+		expected string
+	}{
+		{0, "0"},
+		{1.1, "1.1"},
+		{-2.5, "-2.5"},
+		{1e20, "100000000000000000000"},
+		{1e-5, "0.00001"},
+	}
+	for _, c := range testCases {
+		d, err := NewFromFloat(c.f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := d.String(); s != c.expected {
+			t.Errorf("%g: expected %s, but found %s", c.f, c.expected, s)
+		}
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	testCases := []struct {
+		a, b                         string
+		add, sub, mul, quo, rem, cmp string
+	}{
+		{"1.1", "2.20", "3.30", "-1.10", "2.420", "0.5000000000000000", "1.10", "-1"},
+		{"1", "3", "4", "-2", "3", "0.3333333333333333", "1", "-1"},
+		{"2", "3", "5", "-1", "6", "0.6666666666666667", "2", "-1"},
+		{"-2", "3", "1", "-5", "-6", "-0.6666666666666667", "-2", "-1"},
+		{"7.5", "-2", "5.5", "9.5", "-15.0", "-3.7500000000000000", "1.5", "1"},
+		{"1.50", "1.5", "3.00", "0.00", "2.250", "1.0000000000000000", "0.00", "0"},
+	}
+	for _, c := range testCases {
+		a, b := mustParse(t, c.a), mustParse(t, c.b)
+		quo, err := a.Quo(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rem, err := a.Rem(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range []struct {
+			op       string
+			actual   string
+			expected string
+		}{
+			{"+", a.Add(b).String(), c.add},
+			{"-", a.Sub(b).String(), c.sub},
+			{"*", a.Mul(b).String(), c.mul},
+			{"/", quo.String(), c.quo},
+			{"%", rem.String(), c.rem},
+		} {
+			if r.actual != r.expected {
+				t.Errorf("%s %s %s: expected %s, but found %s", c.a, r.op, c.b, r.expected, r.actual)
+			}
+		}
+		if cmp := a.Cmp(b); cmp != int(mustParse(t, c.cmp).Sign()) {
+			t.Errorf("cmp(%s, %s): expected %s, but found %d", c.a, c.b, c.cmp, cmp)
+		}
+	}
+	zero := NewFromInt(0)
+	if _, err := NewFromInt(1).Quo(zero); err == nil {
+		t.Error("expected division by zero")
+	}
+	if _, err := NewFromInt(1).Rem(zero); err == nil {
+		t.Error("expected division by zero")
+	}
+}
+
+func TestRound(t *testing.T) {
+	testCases := []struct {
+		s        string
+		scale    int32
+		expected string
+	}{
+		{"1.25", 1, "1.3"},
+		{"-1.25", 1, "-1.3"},
+		{"1.249", 1, "1.2"},
+		{"1.5", 3, "1.500"},
+		{"2.5", 0, "3"},
+		{"-2.5", 0, "-3"},
+		{"125", -1, "130"},
+		{"-149", -2, "-100"},
+		{"0.4", -5, "0"},
+		{"1", MaxScale + 10, "1." + strings.Repeat("0", MaxScale)},
+	}
+	for _, c := range testCases {
+		if s := mustParse(t, c.s).Round(c.scale).String(); s != c.expected {
+			t.Errorf("round(%s, %d): expected %s, but found %s", c.s, c.scale, c.expected, s)
+		}
+	}
+}
+
+func TestFloorCeil(t *testing.T) {
+	testCases := []struct {
+		s           string
+		floor, ceil string
+	}{
+		{"0", "0", "0"},
+		{"1.5", "1", "2"},
+		{"-1.5", "-2", "-1"},
+		{"2.00", "2", "2"},
+		{"-0.01", "-1", "0"},
+	}
+	for _, c := range testCases {
+		d := mustParse(t, c.s)
+		if s := d.Floor().String(); s != c.floor {
+			t.Errorf("floor(%s): expected %s, but found %s", c.s, c.floor, s)
+		}
+		if s := d.Ceil().String(); s != c.ceil {
+			t.Errorf("ceil(%s): expected %s, but found %s", c.s, c.ceil, s)
+		}
+	}
+}
+
+func TestMulMaxScale(t *testing.T) {
+	d := mustParse(t, "0."+strings.Repeat("0", MaxScale-1)+"1")
+	if r := d.Mul(d); r.Scale() != MaxScale || r.Sign() != 0 {
+		t.Errorf("expected 0 with a scale of %d, but found %s", MaxScale, r)
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"math"
 	"reflect"
 	"sync"
+	"time"
 	"unsafe"
 
 	"github.com/cockroachdb/cockroach/util"
@@ -537,6 +538,40 @@ func DecodeBytesDecreasing(b []byte, r []byte) ([]byte, []byte) {
 	return b, r
 }
 
+// EncodeTime encodes a time value, appending it to the supplied buffer and
+// returning the final buffer. The encoding is guaranteed to be ordered such
+// that if t1.Before(t2) then bytes.Compare will order them the same way after
+// encoding. The time is encoded as the varint encoded number of seconds since
+// the unix epoch followed by the varint encoded nanoseconds within the
+// second. The location of the time is not encoded; decoded times are in UTC.
+func EncodeTime(b []byte, t time.Time) []byte {
+	b = EncodeVarint(b, t.Unix())
+	return EncodeVarint(b, int64(t.Nanosecond()))
+}
+
+// EncodeTimeDecreasing encodes a time value so that it sorts in reverse
+// order, from latest to earliest.
+func EncodeTimeDecreasing(b []byte, t time.Time) []byte {
+	b = EncodeVarintDecreasing(b, t.Unix())
+	return EncodeVarintDecreasing(b, int64(t.Nanosecond()))
+}
+
+// DecodeTime decodes a time value which was encoded using EncodeTime. The
+// remainder of the input buffer and the decoded time are returned.
+func DecodeTime(b []byte) ([]byte, time.Time) {
+	b, sec := DecodeVarint(b)
+	b, nsec := DecodeVarint(b)
+	return b, time.Unix(sec, nsec).UTC()
+}
+
+// DecodeTimeDecreasing decodes a time value which was encoded using
+// EncodeTimeDecreasing.
+func DecodeTimeDecreasing(b []byte) ([]byte, time.Time) {
+	b, sec := DecodeVarintDecreasing(b)
+	b, nsec := DecodeVarintDecreasing(b)
+	return b, time.Unix(sec, nsec).UTC()
+}
+
 func parseVerb(format string, i int) (verb byte, ascending bool, width int, newI int) {
 	if format[i] != '%' {
 		panic("invalid format string: " + format)
@@ -24,6 +24,7 @@ import (
 	"reflect"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/cockroachdb/cockroach/util"
 )
@@ -544,6 +545,49 @@ func TestEncodeDecodeBytesDecreasing(t *testing.T) {
 	}
 }
 
+func TestEncodeDecodeTime(t *testing.T) {
+	testCases := []time.Time{
+		time.Unix(-1<<40, 0),
+		time.Unix(-1, 0),
+		time.Unix(-1, 999999999),
+		time.Unix(0, 0),
+		time.Unix(0, 1),
+		time.Date(2015, 6, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2015, 6, 1, 12, 30, 0, 500, time.UTC),
+		time.Unix(1<<40, 0),
+	}
+	var lastEnc, lastEncDecreasing []byte
+	for i, c := range testCases {
+		enc := EncodeTime(nil, c)
+		encDecreasing := EncodeTimeDecreasing(nil, c)
+		if i > 0 {
+			if bytes.Compare(lastEnc, enc) >= 0 {
+				t.Errorf("%v: expected [% x] to be less than [% x]", c, lastEnc, enc)
+			}
+			if bytes.Compare(lastEncDecreasing, encDecreasing) <= 0 {
+				t.Errorf("%v: expected [% x] to be greater than [% x]",
+					c, lastEncDecreasing, encDecreasing)
+			}
+		}
+		lastEnc, lastEncDecreasing = enc, encDecreasing
+
+		remainder, dec := DecodeTime(append(enc, "remainder"...))
+		if !dec.Equal(c) {
+			t.Errorf("unexpected decoding mismatch for %v. got %v", c, dec)
+		}
+		if string(remainder) != "remainder" {
+			t.Errorf("unexpected remaining bytes: %v", remainder)
+		}
+		remainder, dec = DecodeTimeDecreasing(append(encDecreasing, "remainder"...))
+		if !dec.Equal(c) {
+			t.Errorf("unexpected decoding mismatch for %v. got %v", c, dec)
+		}
+		if string(remainder) != "remainder" {
+			t.Errorf("unexpected remaining bytes: %v", remainder)
+		}
+	}
+}
+
 func TestEncodeDecodeKey(t *testing.T) {
 	testCases := []struct {
 		format   string
@@ -26,7 +26,10 @@ import (
 	"bytes"
 	"fmt"
 	"math"
+	"math/big"
 	"strconv"
+
+	"github.com/cockroachdb/cockroach/util/decimal"
 )
 
 // Direct mappings or prefixes of encoded data dependent on the type.
@@ -307,6 +310,13 @@ func floatMandE(f float64) (int, []byte) {
 	b[0] = '0' // "0ddddd"
 	e10++
 
+	return digitsMandE(b, e10)
+}
+
+// digitsMandE computes and returns the mantissa M and exponent E of the
+// value 0.ddddd * 10^e10, where b holds the decimal digits "0ddddd" with a
+// leading 0 prepended. b is overwritten.
+func digitsMandE(b []byte, e10 int) (int, []byte) {
 	// Convert the power-10 exponent to a power of 100 exponent.
 	var e100 int
 	if e10 >= 0 {
@@ -387,6 +397,104 @@ func makeFloatFromMandE(negative bool, e int, m []byte) float64 {
 	return f
 }
 
+// EncodeNumericDecimal returns the resulting byte slice with the encoded
+// decimal appended to b. See the notes for EncodeNumericFloat for a complete
+// description. The encoding is comparable with the results of
+// EncodeNumericInt and EncodeNumericFloat but does not preserve the scale of
+// the decimal: 1.5 and 1.50 have the same encoding.
+func EncodeNumericDecimal(b []byte, d *decimal.Decimal) []byte {
+	if d.Sign() == 0 {
+		return append(b, orderedEncodingZero)
+	}
+	e, m := decimalMandE(d)
+	buf := make([]byte, len(m)+maxVarintSize+2)
+	switch {
+	case e < 0:
+		return append(b, encodeSmallNumber(d.Sign() < 0, e, m, buf)...)
+	case e >= 0 && e <= 10:
+		return append(b, encodeMediumNumber(d.Sign() < 0, e, m, buf)...)
+	default:
+		return append(b, encodeLargeNumber(d.Sign() < 0, e, m, buf)...)
+	}
+}
+
+// DecodeNumericDecimal returns the remaining byte slice after decoding and
+// the decoded decimal from buf. The decoded decimal has the smallest scale
+// which represents its value.
+func DecodeNumericDecimal(buf []byte) ([]byte, *decimal.Decimal) {
+	if buf[0] == orderedEncodingZero {
+		return buf[1:], decimal.NewFromInt(0)
+	}
+	idx := bytes.Index(buf, []byte{orderedEncodingTerminator})
+	switch {
+	case buf[0] == 0x08:
+		// Negative large.
+		e, m := decodeLargeNumber(true, buf[:idx+1])
+		return buf[idx+1:], makeDecimalFromMandE(true, e, m)
+	case buf[0] > 0x08 && buf[0] <= 0x13:
+		// Negative medium.
+		e, m := decodeMediumNumber(true, buf[:idx+1])
+		return buf[idx+1:], makeDecimalFromMandE(true, e, m)
+	case buf[0] == 0x14:
+		// Negative small.
+		e, m := decodeSmallNumber(true, buf[:idx+1])
+		return buf[idx+1:], makeDecimalFromMandE(true, e, m)
+	case buf[0] == 0x22:
+		// Positive large.
+		e, m := decodeLargeNumber(false, buf[:idx+1])
+		return buf[idx+1:], makeDecimalFromMandE(false, e, m)
+	case buf[0] >= 0x17 && buf[0] < 0x22:
+		// Positive medium.
+		e, m := decodeMediumNumber(false, buf[:idx+1])
+		return buf[idx+1:], makeDecimalFromMandE(false, e, m)
+	case buf[0] == 0x16:
+		// Positive small.
+		e, m := decodeSmallNumber(false, buf[:idx+1])
+		return buf[idx+1:], makeDecimalFromMandE(false, e, m)
+	default:
+		panic(fmt.Sprintf("unknown prefix of the encoded byte slice: %q", buf))
+	}
+}
+
+// decimalMandE computes and returns the mantissa M and exponent E for the
+// non-zero decimal d. See floatMandE.
+func decimalMandE(d *decimal.Decimal) (int, []byte) {
+	digits := new(big.Int).Abs(d.Unscaled()).String()
+	// d is 0.ddddd * 10^e10.
+	e10 := len(digits) - int(d.Scale())
+	b := make([]byte, 0, len(digits)+2)
+	b = append(b, '0')
+	b = append(b, bytes.TrimRight([]byte(digits), "0")...)
+	return digitsMandE(b, e10)
+}
+
+// makeDecimalFromMandE reconstructs the decimal from the mantissa M and
+// exponent E.
+func makeDecimalFromMandE(negative bool, e int, m []byte) *decimal.Decimal {
+	// The value is 0.dddd * 100^e.
+	digits := make([]byte, 0, len(m)*2)
+	for _, v := range m {
+		// The bytes are encoded as 2n+1, except for the last byte which is
+		// encoded as 2n+0.
+		t := int(v) / 2
+		digits = append(digits, byte(t/10)+'0', byte(t%10)+'0')
+	}
+	scale := len(digits) - 2*e
+	for scale > 0 && digits[len(digits)-1] == '0' {
+		digits = digits[:len(digits)-1]
+		scale--
+	}
+	u, _ := new(big.Int).SetString(string(digits), 10)
+	if scale < 0 {
+		u.Mul(u, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-scale)), nil))
+		scale = 0
+	}
+	if negative {
+		u.Neg(u)
+	}
+	return decimal.New(u, int32(scale))
+}
+
 func encodeSmallNumber(negative bool, e int, m []byte, buf []byte) []byte {
 	n := putUvarint(buf[1:], uint64(-e))
 	copy(buf[n+1:], m)
@@ -20,9 +20,12 @@ package encoding
 import (
 	"bytes"
 	"math"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/cockroachdb/cockroach/util"
+	"github.com/cockroachdb/cockroach/util/decimal"
 )
 
 func TestIntMandE(t *testing.T) {
@@ -232,6 +235,61 @@ func TestEncodeNumericFloat(t *testing.T) {
 	}
 }
 
+func TestEncodeNumericDecimal(t *testing.T) {
+	testCases := []string{
+		"-1" + strings.Repeat("0", 30),
+		"-123456789.5",
+		"-100",
+		"-1.5",
+		"-0.0001",
+		"0",
+		"0.00000000000000000001",
+		"0.0123",
+		"1.1",
+		"1.25",
+		"12.345",
+		"100",
+		"10000",
+		"12345678901234567890.123456789",
+		"1" + strings.Repeat("0", 50),
+	}
+	var prev []byte
+	for i, s := range testCases {
+		d, err := decimal.Parse(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		enc := EncodeNumericDecimal(nil, d)
+		if i > 0 && bytes.Compare(prev, enc) >= 0 {
+			t.Errorf("%s: expected [% x] to be less than [% x]", s, prev, enc)
+		}
+		prev = enc
+		rest, dec := DecodeNumericDecimal(append(enc, 'x'))
+		if dec.Cmp(d) != 0 || dec.String() != s {
+			t.Errorf("unexpected mismatch for %s. got %s", s, dec)
+		}
+		if !bytes.Equal(rest, []byte{'x'}) {
+			t.Errorf("%s: unexpected remainder [% x]", s, rest)
+		}
+		// The encoding of a decimal is comparable with the encoding of the
+		// equivalent float.
+		if f, _ := strconv.ParseFloat(s, 64); strconv.FormatFloat(f, 'f', -1, 64) == s {
+			if fenc := EncodeNumericFloat(nil, f); !bytes.Equal(fenc, enc) {
+				t.Errorf("%s: expected [% x], got [% x]", s, fenc, enc)
+			}
+		}
+	}
+
+	// The scale is not preserved.
+	d, err := decimal.Parse("1.500")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, dec := DecodeNumericDecimal(EncodeNumericDecimal(nil, d)); dec.String() != "1.5" {
+		t.Errorf("expected 1.5, got %s", dec)
+	}
+}
+
 func BenchmarkEncodeNumericInt(b *testing.B) {
 	rng, _ := util.NewPseudoRand()
 